@@ -0,0 +1,238 @@
+package account
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestDetectRateLimit(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		output  string
+		want    bool
+		resetAt time.Time
+	}{
+		{
+			name:   "no banner",
+			output: "⏺ Running tests...\n✓ all passed\n",
+		},
+		{
+			name:    "epoch reset",
+			output:  "working\nClaude AI usage limit reached|1773147600\n",
+			want:    true,
+			resetAt: time.Unix(1773147600, 0),
+		},
+		{
+			name:    "clock reset",
+			output:  "⎿  5-hour limit reached ∙ resets 3pm\n",
+			want:    true,
+			resetAt: time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "clock reset already passed today",
+			output:  "You've hit your limit · resets at 8:30am (UTC)\n",
+			want:    true,
+			resetAt: time.Date(2026, 3, 11, 8, 30, 0, 0, time.UTC),
+		},
+		{
+			name:    "api error without reset",
+			output:  `API Error: 429 {"type":"error","error":{"type":"rate_limit_error"}}`,
+			want:    true,
+			resetAt: now.Add(DefaultRateLimitCooldown),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectRateLimit(tt.output, now)
+			if (got != nil) != tt.want {
+				t.Fatalf("DetectRateLimit() = %+v, want banner %v", got, tt.want)
+			}
+			if got != nil && !got.ResetAt.Equal(tt.resetAt) {
+				t.Errorf("ResetAt = %v, want %v", got.ResetAt, tt.resetAt)
+			}
+		})
+	}
+}
+
+func TestDetectRateLimit_IgnoresOldBanner(t *testing.T) {
+	output := "Claude AI usage limit reached\n"
+	for i := 0; i < bannerLines; i++ {
+		output += "⏺ back at work\n"
+	}
+	if got := DetectRateLimit(output, time.Now()); got != nil {
+		t.Errorf("DetectRateLimit() = %+v, want nil for a scrolled-off banner", got)
+	}
+}
+
+func writeStats(t *testing.T, dir string, tokensByDate map[string]float64) {
+	t.Helper()
+	var stats statsCache
+	for date, tokens := range tokensByDate {
+		stats.DailyModelTokens = append(stats.DailyModelTokens, struct {
+			Date          string             `json:"date"`
+			TokensByModel map[string]float64 `json:"tokensByModel"`
+		}{date, map[string]float64{"claude-sonnet": tokens}})
+	}
+	data, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "stats-cache.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWeeklyTokens(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	writeStats(t, dir, map[string]float64{
+		"2026-03-10": 100,
+		"2026-03-04": 20,
+		"2026-03-03": 5000, // eight days ago
+	})
+
+	got, err := WeeklyTokens(dir, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 120 {
+		t.Errorf("WeeklyTokens() = %d, want 120", got)
+	}
+
+	if _, err := WeeklyTokens(t.TempDir(), now); err == nil {
+		t.Error("WeeklyTokens() without a stats cache: want error")
+	}
+}
+
+func TestRankAndPick(t *testing.T) {
+	town := t.TempDir()
+	today := time.Now().Format("2006-01-02")
+	dirs := map[string]string{}
+	for _, handle := range []string{"work", "personal", "spare"} {
+		dirs[handle] = filepath.Join(town, "accounts", handle)
+	}
+	writeStats(t, dirs["work"], map[string]float64{today: 900})
+	writeStats(t, dirs["personal"], map[string]float64{today: 100})
+	writeStats(t, dirs["spare"], map[string]float64{today: 0})
+
+	cfg := &config.AccountsConfig{
+		Version: 1,
+		Default: "work",
+		Rotate:  true,
+		Accounts: map[string]config.Account{
+			"work":     {ConfigDir: dirs["work"], WeeklyTokenLimit: 1000},
+			"personal": {ConfigDir: dirs["personal"], WeeklyTokenLimit: 1000},
+			"spare":    {ConfigDir: dirs["spare"], WeeklyTokenLimit: 1000},
+		},
+	}
+
+	if err := RecordRateLimit(town, "spare", time.Now().Add(time.Hour), "usage limit reached"); err != nil {
+		t.Fatal(err)
+	}
+	state, err := LoadState(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var order []string
+	for _, c := range Rank(cfg, state, time.Now()) {
+		order = append(order, c.Handle)
+	}
+	want := []string{"personal", "work", "spare"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("Rank() = %v, want %v", order, want)
+		}
+	}
+
+	if got, _ := Pick(town, cfg); got != "personal" {
+		t.Errorf("Pick() = %q, want personal", got)
+	}
+	if got, _ := Pick(town, cfg, "personal"); got != "work" {
+		t.Errorf("Pick(exclude personal) = %q, want work", got)
+	}
+	if got, _ := Pick(town, cfg, "personal", "work"); got != "" {
+		t.Errorf("Pick(exclude all but rate-limited) = %q, want none", got)
+	}
+}
+
+func TestStateRecords(t *testing.T) {
+	town := t.TempDir()
+	if err := RecordSpawn(town, "work", "gt-gastown-Toast"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordSpawn(town, "", "gt-gastown-Nux"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordMigration(town, "personal", "gt-gastown-Toast"); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := LoadState(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := state.Accounts["work"].Spawns; got != 1 {
+		t.Errorf("work spawns = %d, want 1", got)
+	}
+	if got := state.Accounts["personal"].Migrations; got != 1 {
+		t.Errorf("personal migrations = %d, want 1", got)
+	}
+	sa := state.Sessions["gt-gastown-Toast"]
+	if sa == nil || sa.Handle != "personal" || sa.MigratedAt.IsZero() {
+		t.Errorf("session account = %+v, want migrated to personal", sa)
+	}
+	if _, ok := state.Sessions["gt-gastown-Nux"]; ok {
+		t.Error("spawn without an account should not be recorded")
+	}
+}
+
+func TestCopySession(t *testing.T) {
+	from, to := t.TempDir(), t.TempDir()
+	workDir := "/home/me/gt/gastown/polecats/Toast/gastown"
+
+	if got := LatestSession(from, workDir); got != "" {
+		t.Errorf("LatestSession() on empty dir = %q", got)
+	}
+
+	dir := projectDir(from, workDir)
+	if filepath.Base(dir) != "-home-me-gt-gastown-polecats-Toast-gastown" {
+		t.Errorf("projectDir() = %s", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := filepath.Join(dir, "old.jsonl")
+	if err := os.WriteFile(old, []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(old, past, past); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.jsonl"), []byte(`{"type":"user"}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	id := LatestSession(from, workDir)
+	if id != "new" {
+		t.Fatalf("LatestSession() = %q, want new", id)
+	}
+	if err := CopySession(from, to, workDir, id); err != nil {
+		t.Fatal(err)
+	}
+	if got := LatestSession(to, workDir); got != "new" {
+		t.Errorf("LatestSession() after copy = %q, want new", got)
+	}
+}
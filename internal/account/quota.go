@@ -0,0 +1,193 @@
+package account
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// Usage is an account's token consumption over the last seven days.
+type Usage struct {
+	WeeklyTokens int64
+	Limit        int64 // weekly_token_limit from accounts.json, 0 if unset
+	Known        bool  // false when the stats cache couldn't be read
+}
+
+// Remaining returns the fraction of the weekly limit left, or -1 when the
+// account has no limit or its usage is unknown.
+func (u Usage) Remaining() float64 {
+	if !u.Known || u.Limit <= 0 {
+		return -1
+	}
+	left := 1 - float64(u.WeeklyTokens)/float64(u.Limit)
+	if left < 0 {
+		return 0
+	}
+	return left
+}
+
+// statsCache is the part of Claude Code's stats-cache.json we read.
+type statsCache struct {
+	DailyModelTokens []struct {
+		Date          string             `json:"date"`
+		TokensByModel map[string]float64 `json:"tokensByModel"`
+	} `json:"dailyModelTokens"`
+}
+
+// WeeklyTokens sums the tokens an account used in the seven days up to now,
+// from the stats cache Claude Code keeps in its config dir.
+func WeeklyTokens(configDir string, now time.Time) (int64, error) {
+	data, err := os.ReadFile(filepath.Join(configDir, "stats-cache.json")) //nolint:gosec // G304: config dir comes from accounts.json
+	if err != nil {
+		return 0, err
+	}
+	var stats statsCache
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return 0, fmt.Errorf("parsing stats cache: %w", err)
+	}
+
+	y, m, d := now.Date()
+	since := time.Date(y, m, d, 0, 0, 0, 0, now.Location()).AddDate(0, 0, -6)
+	var total float64
+	for _, day := range stats.DailyModelTokens {
+		date, err := time.ParseInLocation("2006-01-02", day.Date, now.Location())
+		if err != nil || date.Before(since) {
+			continue
+		}
+		for _, tokens := range day.TokensByModel {
+			total += tokens
+		}
+	}
+	return int64(total), nil
+}
+
+// Candidate is an account as ranked for a new session.
+type Candidate struct {
+	Handle string
+	Usage  Usage
+	Status *Status
+}
+
+// Rank orders accounts for a new session, best first:
+//  1. Accounts that aren't rate-limited, then rate-limited ones by reset time
+//  2. More quota left: the fraction of weekly_token_limit remaining, or for
+//     accounts without a limit, how little they used relative to the
+//     busiest such account
+//  3. The default account, then the least recently used
+func Rank(cfg *config.AccountsConfig, state *State, now time.Time) []Candidate {
+	candidates := make([]Candidate, 0, len(cfg.Accounts))
+	var maxTokens int64
+	for handle, acct := range cfg.Accounts {
+		c := Candidate{Handle: handle, Status: state.Accounts[handle]}
+		c.Usage.Limit = acct.WeeklyTokenLimit
+		if tokens, err := WeeklyTokens(acct.ResolvedConfigDir(), now); err == nil {
+			c.Usage.WeeklyTokens = tokens
+			c.Usage.Known = true
+		}
+		if c.Usage.Limit <= 0 && c.Usage.WeeklyTokens > maxTokens {
+			maxTokens = c.Usage.WeeklyTokens
+		}
+		candidates = append(candidates, c)
+	}
+
+	score := func(c Candidate) float64 {
+		if left := c.Usage.Remaining(); left >= 0 {
+			return left
+		}
+		if maxTokens == 0 {
+			return 1
+		}
+		return 1 - float64(c.Usage.WeeklyTokens)/float64(maxTokens+1)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		la, lb := a.Status.RateLimited(now), b.Status.RateLimited(now)
+		if la != lb {
+			return !la
+		}
+		if la {
+			return a.Status.RateLimitedUntil.Before(b.Status.RateLimitedUntil)
+		}
+		if sa, sb := score(a), score(b); sa != sb {
+			return sa > sb
+		}
+		if (a.Handle == cfg.Default) != (b.Handle == cfg.Default) {
+			return a.Handle == cfg.Default
+		}
+		var ua, ub time.Time
+		if a.Status != nil {
+			ua = a.Status.LastUsed
+		}
+		if b.Status != nil {
+			ub = b.Status.LastUsed
+		}
+		if !ua.Equal(ub) {
+			return ua.Before(ub)
+		}
+		return a.Handle < b.Handle
+	})
+	return candidates
+}
+
+// Pick returns the best account for a new session that isn't rate-limited
+// or excluded, or "" if there is none.
+func Pick(townRoot string, cfg *config.AccountsConfig, exclude ...string) (string, error) {
+	state, err := LoadState(townRoot)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	for _, c := range Rank(cfg, state, now) {
+		if c.Status.RateLimited(now) || contains(exclude, c.Handle) {
+			continue
+		}
+		return c.Handle, nil
+	}
+	return "", nil
+}
+
+// Resolve resolves the account for a new session like
+// config.ResolveAccountConfigDir (GT_ACCOUNT, then the --account flag, then
+// the default), except that when rotation is on and no account was asked
+// for, the account is picked by Pick.
+func Resolve(townRoot, accountFlag string) (configDir, handle string, err error) {
+	accountsPath := constants.MayorAccountsPath(townRoot)
+	cfg, loadErr := config.LoadAccountsConfig(accountsPath)
+	if loadErr != nil || !cfg.Rotate || len(cfg.Accounts) < 2 ||
+		accountFlag != "" || os.Getenv("GT_ACCOUNT") != "" {
+		return config.ResolveAccountConfigDir(accountsPath, accountFlag)
+	}
+
+	handle, err = Pick(townRoot, cfg)
+	if err != nil || handle == "" {
+		// Everything is rate-limited (or the state is unreadable): the
+		// default is as good as any
+		return config.ResolveAccountConfigDir(accountsPath, "")
+	}
+	return cfg.GetAccount(handle).ResolvedConfigDir(), handle, nil
+}
+
+// HandleForConfigDir returns the account whose config dir is dir, or "".
+func HandleForConfigDir(cfg *config.AccountsConfig, dir string) string {
+	for handle, acct := range cfg.Accounts {
+		if filepath.Clean(acct.ResolvedConfigDir()) == filepath.Clean(dir) {
+			return handle
+		}
+	}
+	return ""
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
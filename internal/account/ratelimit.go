@@ -0,0 +1,162 @@
+package account
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRateLimitCooldown is how long an account is considered
+// rate-limited when the banner doesn't say when the limit resets.
+const DefaultRateLimitCooldown = time.Hour
+
+// bannerLines is how many of the pane's last lines are searched for a
+// rate-limit banner; an older banner has been dealt with.
+const bannerLines = 15
+
+// rateLimitPatterns match the banners Claude Code shows when an account
+// hits its usage limits.
+var rateLimitPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)usage limit reached`),
+	regexp.MustCompile(`(?i)limit reached\W.{0,40}\bresets\b`),
+	regexp.MustCompile(`(?i)you've hit your (usage |session |weekly |\S+-hour )?limit`),
+	regexp.MustCompile(`(?i)rate_limit_error`),
+}
+
+var (
+	// "Claude AI usage limit reached|1760637600"
+	resetEpochPattern = regexp.MustCompile(`limit reached\|(\d{9,11})`)
+
+	// "resets 3pm", "resets at 3:30pm (Europe/Berlin)"
+	resetClockPattern = regexp.MustCompile(`(?i)resets(?: at)? (\d{1,2})(?::(\d{2}))?\s*(am|pm)(?:\s*\(([^)]+)\))?`)
+)
+
+// RateLimit is a rate-limit banner found in a pane.
+type RateLimit struct {
+	Banner  string    // The matching line
+	ResetAt time.Time // When the limit resets (estimated if not shown)
+}
+
+// DetectRateLimit looks for a rate-limit banner in the last lines of pane
+// output. Returns nil when there is none.
+func DetectRateLimit(output string, now time.Time) *RateLimit {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > bannerLines {
+		lines = lines[len(lines)-bannerLines:]
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		for _, pattern := range rateLimitPatterns {
+			if pattern.MatchString(line) {
+				return &RateLimit{Banner: line, ResetAt: parseReset(line, now)}
+			}
+		}
+	}
+	return nil
+}
+
+// parseReset returns when a banner says its limit resets, or now plus
+// DefaultRateLimitCooldown.
+func parseReset(banner string, now time.Time) time.Time {
+	if m := resetEpochPattern.FindStringSubmatch(banner); m != nil {
+		if secs, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+			if reset := time.Unix(secs, 0); reset.After(now) {
+				return reset
+			}
+		}
+	}
+
+	if m := resetClockPattern.FindStringSubmatch(banner); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour >= 1 && hour <= 12 && minute < 60 {
+			hour %= 12
+			if strings.EqualFold(m[3], "pm") {
+				hour += 12
+			}
+			loc := now.Location()
+			if m[4] != "" {
+				if l, err := time.LoadLocation(m[4]); err == nil {
+					loc = l
+				}
+			}
+			local := now.In(loc)
+			reset := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+			if !reset.After(now) {
+				reset = reset.AddDate(0, 0, 1)
+			}
+			return reset
+		}
+	}
+
+	return now.Add(DefaultRateLimitCooldown)
+}
+
+// projectDir returns where Claude Code keeps the transcripts of sessions
+// run in workDir: <config>/projects/<workDir with every non-alphanumeric
+// character replaced by '-'>.
+func projectDir(configDir, workDir string) string {
+	slug := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, workDir)
+	return filepath.Join(configDir, "projects", slug)
+}
+
+// LatestSession returns the ID of the most recent Claude Code session run
+// in workDir under an account's config dir, or "" if there is none.
+func LatestSession(configDir, workDir string) string {
+	entries, err := os.ReadDir(projectDir(configDir, workDir))
+	if err != nil {
+		return ""
+	}
+	var latest string
+	var latestMod time.Time
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".jsonl" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if latest == "" || info.ModTime().After(latestMod) {
+			latest = strings.TrimSuffix(entry.Name(), ".jsonl")
+			latestMod = info.ModTime()
+		}
+	}
+	return latest
+}
+
+// CopySession copies a Claude Code session transcript from one account's
+// config dir to another's, so the session can be resumed on the other
+// account.
+func CopySession(fromConfigDir, toConfigDir, workDir, sessionID string) error {
+	name := sessionID + ".jsonl"
+	src, err := os.Open(filepath.Join(projectDir(fromConfigDir, workDir), name)) //nolint:gosec // G304: config dirs come from accounts.json
+	if err != nil {
+		return fmt.Errorf("opening session transcript: %w", err)
+	}
+	defer src.Close()
+
+	destDir := projectDir(toConfigDir, workDir)
+	if err := os.MkdirAll(destDir, 0700); err != nil {
+		return fmt.Errorf("creating project dir: %w", err)
+	}
+	dest, err := os.OpenFile(filepath.Join(destDir, name), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("creating session transcript: %w", err)
+	}
+	if _, err := io.Copy(dest, src); err != nil {
+		_ = dest.Close()
+		return fmt.Errorf("copying session transcript: %w", err)
+	}
+	return dest.Close()
+}
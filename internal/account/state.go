@@ -0,0 +1,152 @@
+// Package account tracks quota and rate-limit state across the Claude Code
+// accounts registered in mayor/accounts.json, so that spawns can pick the
+// account with the most quota left and rate-limited sessions can be moved
+// to another account.
+package account
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// State is what Gas Town has observed about its accounts
+// (mayor/account-state.json).
+type State struct {
+	Accounts map[string]*Status `json:"accounts"`
+
+	// Sessions maps tmux sessions to the account they were last started
+	// or migrated on.
+	Sessions map[string]*SessionAccount `json:"sessions,omitempty"`
+}
+
+// Status is the observed state of one account.
+type Status struct {
+	// RateLimitedUntil is when the account's last rate limit resets.
+	RateLimitedUntil time.Time `json:"rate_limited_until,omitempty"`
+
+	// LastRateLimit is when a rate limit was last seen, and its banner.
+	LastRateLimit time.Time `json:"last_rate_limit,omitempty"`
+	Reason        string    `json:"reason,omitempty"`
+
+	// Spawns and Migrations count the sessions started on the account, and
+	// the rate-limited sessions moved onto it.
+	Spawns     int `json:"spawns,omitempty"`
+	Migrations int `json:"migrations,omitempty"`
+
+	// LastUsed is when a session was last started on the account.
+	LastUsed time.Time `json:"last_used,omitempty"`
+}
+
+// SessionAccount records which account a session runs on.
+type SessionAccount struct {
+	Handle     string    `json:"handle"`
+	MigratedAt time.Time `json:"migrated_at,omitempty"`
+}
+
+// RateLimited reports whether the account is rate-limited at now.
+func (s *Status) RateLimited(now time.Time) bool {
+	return s != nil && now.Before(s.RateLimitedUntil)
+}
+
+// StatePath returns the account state file of a town.
+func StatePath(townRoot string) string {
+	return filepath.Join(townRoot, constants.DirMayor, "account-state.json")
+}
+
+// LoadState reads a town's account state. A missing file is empty state.
+func LoadState(townRoot string) (*State, error) {
+	state := &State{}
+	data, err := os.ReadFile(StatePath(townRoot)) //nolint:gosec // G304: path is constructed from trusted townRoot
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading account state: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("parsing account state: %w", err)
+		}
+	}
+	if state.Accounts == nil {
+		state.Accounts = make(map[string]*Status)
+	}
+	if state.Sessions == nil {
+		state.Sessions = make(map[string]*SessionAccount)
+	}
+	return state, nil
+}
+
+// Get returns an account's status, creating it.
+func (s *State) Get(handle string) *Status {
+	st := s.Accounts[handle]
+	if st == nil {
+		st = &Status{}
+		s.Accounts[handle] = st
+	}
+	return st
+}
+
+// Update changes a town's account state under a file lock.
+func Update(townRoot string, fn func(*State)) error {
+	path := StatePath(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating mayor dir: %w", err)
+	}
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking account state: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	state, err := LoadState(townRoot)
+	if err != nil {
+		return err
+	}
+	fn(state)
+	return util.AtomicWriteJSON(path, state)
+}
+
+// RecordSpawn records a session started on an account.
+func RecordSpawn(townRoot, handle, sessionName string) error {
+	if handle == "" {
+		return nil
+	}
+	return Update(townRoot, func(s *State) {
+		st := s.Get(handle)
+		st.Spawns++
+		st.LastUsed = time.Now()
+		if sessionName != "" {
+			s.Sessions[sessionName] = &SessionAccount{Handle: handle}
+		}
+	})
+}
+
+// RecordRateLimit marks an account rate-limited until resetAt.
+func RecordRateLimit(townRoot, handle string, resetAt time.Time, reason string) error {
+	if handle == "" {
+		return nil
+	}
+	return Update(townRoot, func(s *State) {
+		st := s.Get(handle)
+		st.LastRateLimit = time.Now()
+		st.Reason = reason
+		if resetAt.After(st.RateLimitedUntil) {
+			st.RateLimitedUntil = resetAt
+		}
+	})
+}
+
+// RecordMigration records a session moved onto an account.
+func RecordMigration(townRoot, handle, sessionName string) error {
+	return Update(townRoot, func(s *State) {
+		st := s.Get(handle)
+		st.Migrations++
+		st.LastUsed = time.Now()
+		s.Sessions[sessionName] = &SessionAccount{Handle: handle, MigratedAt: time.Now()}
+	})
+}
@@ -36,11 +36,15 @@ type Issue struct {
 	ClosedAt    string   `json:"closed_at,omitempty"`
 	Parent      string   `json:"parent,omitempty"`
 	Assignee    string   `json:"assignee,omitempty"`
+	Owner       string   `json:"owner,omitempty"`
 	Children    []string `json:"children,omitempty"`
 	DependsOn   []string `json:"depends_on,omitempty"`
 	Blocks      []string `json:"blocks,omitempty"`
 	BlockedBy   []string `json:"blocked_by,omitempty"`
 	Labels      []string `json:"labels,omitempty"`
+	Ephemeral   bool     `json:"ephemeral,omitempty"` // Wisp: stored locally, not exported to JSONL
+	Pinned      bool     `json:"pinned,omitempty"`
+	DeferUntil  string   `json:"defer_until,omitempty"` // Hidden from ready work until this time
 
 	// Agent bead slots (type=agent only)
 	HookBead   string `json:"hook_bead,omitempty"`   // Current work attached to agent's hook
@@ -72,6 +76,7 @@ type ListOptions struct {
 	Status     string // "open", "closed", "all"
 	Type       string // Deprecated: use Label instead. "task", "bug", "feature", "epic"
 	Label      string // Label filter (e.g., "gt:agent", "gt:merge-request")
+	IssueType  string // issue_type filter (e.g., "message")
 	Priority   int    // 0-4, -1 for no filter
 	Parent     string // filter by parent ID
 	Assignee   string // filter by assignee (e.g., "gastown/Toast")
@@ -164,6 +169,16 @@ func (b *Beads) run(args ...string) ([]byte, error) {
 	return stdout.Bytes(), nil
 }
 
+// store returns the in-process reader for this wrapper's database.
+// Reads try it first and fall back to bd when it's unavailable.
+func (b *Beads) store() *Store {
+	beadsDir := b.beadsDir
+	if beadsDir == "" {
+		beadsDir = ResolveBeadsDir(b.workDir)
+	}
+	return OpenStore(beadsDir)
+}
+
 // Run executes a bd command and returns stdout.
 // This is a public wrapper around the internal run method for cases where
 // callers need to run arbitrary bd commands.
@@ -198,6 +213,10 @@ func (b *Beads) wrapError(err error, stderr string, args []string) error {
 
 // List returns issues matching the given options.
 func (b *Beads) List(opts ListOptions) ([]*Issue, error) {
+	if issues, err := b.store().List(opts); err == nil {
+		return issues, nil
+	}
+
 	args := []string{"list", "--json"}
 
 	if opts.Status != "" {
@@ -210,6 +229,9 @@ func (b *Beads) List(opts ListOptions) ([]*Issue, error) {
 		// Deprecated: convert type to label for backward compatibility
 		args = append(args, "--label=gt:"+opts.Type)
 	}
+	if opts.IssueType != "" {
+		args = append(args, "--type="+opts.IssueType)
+	}
 	if opts.Priority >= 0 {
 		args = append(args, fmt.Sprintf("--priority=%d", opts.Priority))
 	}
@@ -279,6 +301,10 @@ func (b *Beads) GetAssignedIssue(assignee string) (*Issue, error) {
 
 // Ready returns issues that are ready to work (not blocked).
 func (b *Beads) Ready() ([]*Issue, error) {
+	if issues, err := b.store().Ready("", 0); err == nil {
+		return issues, nil
+	}
+
 	out, err := b.run("ready", "--json")
 	if err != nil {
 		return nil, err
@@ -296,6 +322,10 @@ func (b *Beads) Ready() ([]*Issue, error) {
 // Uses bd ready --label flag for server-side filtering.
 // The issueType is converted to a gt:<type> label (e.g., "molecule" -> "gt:molecule").
 func (b *Beads) ReadyWithType(issueType string) ([]*Issue, error) {
+	if issues, err := b.store().Ready("gt:"+issueType, 100); err == nil {
+		return issues, nil
+	}
+
 	out, err := b.run("ready", "--json", "--label", "gt:"+issueType, "-n", "100")
 	if err != nil {
 		return nil, err
@@ -310,7 +340,12 @@ func (b *Beads) ReadyWithType(issueType string) ([]*Issue, error) {
 }
 
 // Show returns detailed information about an issue.
+// IDs not in the local database go to bd, which routes them by prefix.
 func (b *Beads) Show(id string) (*Issue, error) {
+	if issue, err := b.store().Show(id); err == nil {
+		return issue, nil
+	}
+
 	out, err := b.run("show", id, "--json")
 	if err != nil {
 		return nil, err
@@ -336,12 +371,28 @@ func (b *Beads) ShowMultiple(ids []string) (map[string]*Issue, error) {
 		return make(map[string]*Issue), nil
 	}
 
+	// Resolve what we can in-process; only the rest (e.g., other rigs'
+	// prefixes) needs bd
+	result, err := b.store().ShowMultiple(ids)
+	if err != nil {
+		result = make(map[string]*Issue, len(ids))
+	}
+	var missing []string
+	for _, id := range ids {
+		if _, ok := result[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
 	// bd show supports multiple IDs
-	args := append([]string{"show", "--json"}, ids...)
+	args := append([]string{"show", "--json"}, missing...)
 	out, err := b.run(args...)
 	if err != nil {
-		// If bd fails, return empty map (some IDs might not exist)
-		return make(map[string]*Issue), nil
+		// If bd fails, return what we have (some IDs might not exist)
+		return result, nil
 	}
 
 	var issues []*Issue
@@ -349,7 +400,6 @@ func (b *Beads) ShowMultiple(ids []string) (map[string]*Issue, error) {
 		return nil, fmt.Errorf("parsing bd show output: %w", err)
 	}
 
-	result := make(map[string]*Issue, len(issues))
 	for _, issue := range issues {
 		result[issue.ID] = issue
 	}
@@ -14,20 +14,21 @@ import (
 // EscalationFields holds structured fields for escalation beads.
 // These are stored as "key: value" lines in the description.
 type EscalationFields struct {
-	Severity           string // critical, high, medium, low
-	Reason             string // Why this was escalated
-	Source             string // Source identifier (e.g., plugin:rebuild-gt, patrol:deacon)
-	EscalatedBy        string // Agent address that escalated (e.g., "gastown/Toast")
-	EscalatedAt        string // ISO 8601 timestamp
-	AckedBy            string // Agent that acknowledged (empty if not acked)
-	AckedAt            string // When acknowledged (empty if not acked)
-	ClosedBy           string // Agent that closed (empty if not closed)
-	ClosedReason       string // Resolution reason (empty if not closed)
-	RelatedBead        string // Optional: related bead ID (task, bug, etc.)
-	OriginalSeverity   string // Original severity before any re-escalation
-	ReescalationCount  int    // Number of times this has been re-escalated
-	LastReescalatedAt  string // When last re-escalated (empty if never)
-	LastReescalatedBy  string // Who last re-escalated (empty if never)
+	Severity          string   // critical, high, medium, low
+	Reason            string   // Why this was escalated
+	Source            string   // Source identifier (e.g., plugin:rebuild-gt, patrol:deacon)
+	EscalatedBy       string   // Agent address that escalated (e.g., "gastown/Toast")
+	EscalatedAt       string   // ISO 8601 timestamp
+	AckedBy           string   // Agent that acknowledged (empty if not acked)
+	AckedAt           string   // When acknowledged (empty if not acked)
+	ClosedBy          string   // Agent that closed (empty if not closed)
+	ClosedReason      string   // Resolution reason (empty if not closed)
+	RelatedBead       string   // Optional: related bead ID (task, bug, etc.)
+	OriginalSeverity  string   // Original severity before any re-escalation
+	ReescalationCount int      // Number of times this has been re-escalated
+	LastReescalatedAt string   // When last re-escalated (empty if never)
+	LastReescalatedBy string   // Who last re-escalated (empty if never)
+	Deliveries        []string // External notification delivery records, oldest first
 }
 
 // EscalationState constants for bead status tracking.
@@ -104,6 +105,11 @@ func FormatEscalationDescription(title string, fields *EscalationFields) string
 		lines = append(lines, "last_reescalated_by: null")
 	}
 
+	// One line per delivery attempt outcome (email, webhook, exec, ...)
+	for _, d := range fields.Deliveries {
+		lines = append(lines, fmt.Sprintf("delivery: %s", d))
+	}
+
 	return strings.Join(lines, "\n")
 }
 
@@ -159,6 +165,10 @@ func ParseEscalationFields(description string) *EscalationFields {
 			fields.LastReescalatedAt = value
 		case "last_reescalated_by":
 			fields.LastReescalatedBy = value
+		case "delivery":
+			if value != "" {
+				fields.Deliveries = append(fields.Deliveries, value)
+			}
 		}
 	}
 
@@ -228,6 +238,29 @@ func (b *Beads) AckEscalation(id, ackedBy string) error {
 	})
 }
 
+// RecordEscalationDeliveries appends external notification delivery records
+// to an escalation bead so operators can see what was sent and what failed.
+func (b *Beads) RecordEscalationDeliveries(id string, deliveries []string) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	issue, err := b.Show(id)
+	if err != nil {
+		return err
+	}
+
+	if !HasLabel(issue, "gt:escalation") {
+		return fmt.Errorf("issue %s is not an escalation bead (missing gt:escalation label)", id)
+	}
+
+	fields := ParseEscalationFields(issue.Description)
+	fields.Deliveries = append(fields.Deliveries, deliveries...)
+	description := FormatEscalationDescription(issue.Title, fields)
+
+	return b.Update(id, UpdateOptions{Description: &description})
+}
+
 // CloseEscalation closes an escalation bead with a resolution reason.
 // Sets closed_by and closed_reason fields, closes the issue.
 func (b *Beads) CloseEscalation(id, closedBy, reason string) error {
@@ -0,0 +1,27 @@
+package beads
+
+import "testing"
+
+func TestEscalationFields_DeliveriesRoundTrip(t *testing.T) {
+	fields := &EscalationFields{
+		Severity:    "high",
+		EscalatedBy: "gastown/Toast",
+		Deliveries: []string{
+			"2026-01-11T19:00:02Z email:human to steve@example.com: delivered (attempts 1)",
+			"2026-01-11T19:00:09Z slack to slack: failed after 3 attempts: webhook returned 500",
+		},
+	}
+
+	parsed := ParseEscalationFields(FormatEscalationDescription("Plugin FAILED", fields))
+	if len(parsed.Deliveries) != 2 {
+		t.Fatalf("Deliveries = %v, want 2 records", parsed.Deliveries)
+	}
+	for i, want := range fields.Deliveries {
+		if parsed.Deliveries[i] != want {
+			t.Errorf("Deliveries[%d] = %q, want %q", i, parsed.Deliveries[i], want)
+		}
+	}
+	if parsed.Severity != "high" || parsed.EscalatedBy != "gastown/Toast" {
+		t.Errorf("other fields lost: %+v", parsed)
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 // TestNew verifies the constructor.
@@ -620,6 +622,43 @@ func TestMRFieldsRoundTrip(t *testing.T) {
 	}
 }
 
+// TestMRChecksRoundTrip tests storing check results alongside MR fields.
+func TestMRChecksRoundTrip(t *testing.T) {
+	checks := []MRCheck{
+		{Name: "build", Status: MRCheckPassed, Duration: 12300 * time.Millisecond},
+		{Name: "unit", Status: MRCheckFailed, Duration: 41 * time.Second, Detail: "exit status 1",
+			LogTail: []string{"--- FAIL: TestMerge (0.01s)", "", "target: main"}},
+		{Name: "web", Status: MRCheckSkipped, Detail: "no changes in internal/web/**"},
+	}
+	desc := "branch: polecat/Nux/gt-xyz\ntarget: integration/gt-epic\n\nSome notes"
+
+	withChecks := SetMRChecks(desc, checks)
+	if !strings.HasPrefix(withChecks, desc+"\n\ncheck build: passed in 12.3s\n") {
+		t.Errorf("SetMRChecks() = %q", withChecks)
+	}
+	if got := ParseMRChecks(withChecks); !reflect.DeepEqual(got, checks) {
+		t.Errorf("ParseMRChecks() =\n%+v\nwant\n%+v", got, checks)
+	}
+
+	// Log lines never leak into the MR fields
+	fields := ParseMRFields(&Issue{Description: withChecks})
+	if fields == nil || fields.Target != "integration/gt-epic" {
+		t.Errorf("ParseMRFields() = %+v", fields)
+	}
+	if got := SetMRFields(&Issue{Description: withChecks}, fields); ParseMRChecks(got) == nil {
+		t.Errorf("SetMRFields() dropped the checks: %q", got)
+	}
+
+	// Replacing and removing
+	rerun := SetMRChecks(withChecks, []MRCheck{{Name: "build", Status: MRCheckPassed}})
+	if got := ParseMRChecks(rerun); len(got) != 1 || got[0].Name != "build" {
+		t.Errorf("after rerun ParseMRChecks() = %+v", got)
+	}
+	if got := SetMRChecks(withChecks, nil); got != desc {
+		t.Errorf("SetMRChecks(nil) = %q, want %q", got, desc)
+	}
+}
+
 // TestParseMRFieldsFromDesignDoc tests the example from the design doc.
 func TestParseMRFieldsFromDesignDoc(t *testing.T) {
 	// Example from docs/merge-queue-design.md
@@ -4,6 +4,7 @@ package beads
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // Note: AgentFields, ParseAgentFields, FormatAgentDescription, and CreateAgentBead are in beads.go
@@ -421,6 +422,134 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 	return formatted + "\n\n" + strings.Join(otherLines, "\n")
 }
 
+// MR check statuses.
+const (
+	MRCheckPassed  = "passed"
+	MRCheckFailed  = "failed"
+	MRCheckTimeout = "timeout"
+	MRCheckSkipped = "skipped"
+)
+
+// MRCheck is the result of one pre-merge check run by the refinery. Checks
+// are stored in the MR description after the MR fields as
+//
+//	check unit: failed in 41s (exit status 1)
+//	| --- FAIL: TestMerge (0.01s)
+//	check web: skipped (no changes in internal/web/**)
+//
+// with the tail of a failed check's log on the "| " lines below it.
+type MRCheck struct {
+	Name     string
+	Status   string        // passed, failed, timeout or skipped
+	Duration time.Duration // How long the check ran (0 if skipped)
+	Detail   string        // Error or skip reason
+	LogTail  []string      // Last lines of output, for checks that didn't pass
+}
+
+// mrCheckPrefix starts a check line; mrCheckLogPrefix starts a log line.
+const (
+	mrCheckPrefix    = "check "
+	mrCheckLogPrefix = "|"
+)
+
+// ParseMRChecks extracts the pre-merge check results from an MR description.
+func ParseMRChecks(description string) []MRCheck {
+	var checks []MRCheck
+	inCheck := false
+	for _, line := range strings.Split(description, "\n") {
+		if inCheck && strings.HasPrefix(line, mrCheckLogPrefix) {
+			last := &checks[len(checks)-1]
+			logLine := strings.TrimPrefix(line, mrCheckLogPrefix)
+			last.LogTail = append(last.LogTail, strings.TrimPrefix(logLine, " "))
+			continue
+		}
+		check, ok := parseMRCheckLine(line)
+		if ok {
+			checks = append(checks, check)
+		}
+		inCheck = ok
+	}
+	return checks
+}
+
+// parseMRCheckLine parses a "check <name>: <status>..." line.
+func parseMRCheckLine(line string) (MRCheck, bool) {
+	rest, ok := strings.CutPrefix(line, mrCheckPrefix)
+	if !ok {
+		return MRCheck{}, false
+	}
+	name, value, ok := strings.Cut(rest, ": ")
+	if !ok || name == "" || strings.Contains(name, " ") {
+		return MRCheck{}, false
+	}
+	check := MRCheck{Name: name}
+	if v, detail, ok := strings.Cut(value, " ("); ok {
+		value = v
+		check.Detail = strings.TrimSuffix(detail, ")")
+	}
+	if v, dur, ok := strings.Cut(value, " in "); ok {
+		value = v
+		check.Duration, _ = time.ParseDuration(dur)
+	}
+	switch value {
+	case MRCheckPassed, MRCheckFailed, MRCheckTimeout, MRCheckSkipped:
+		check.Status = value
+		return check, true
+	}
+	return MRCheck{}, false
+}
+
+// FormatMRChecks formats check results for an MR description.
+func FormatMRChecks(checks []MRCheck) string {
+	var lines []string
+	for _, c := range checks {
+		line := mrCheckPrefix + c.Name + ": " + c.Status
+		if c.Duration > 0 {
+			line += " in " + c.Duration.Round(100*time.Millisecond).String()
+		}
+		if c.Detail != "" {
+			line += " (" + strings.ReplaceAll(c.Detail, "\n", " ") + ")"
+		}
+		lines = append(lines, line)
+		for _, l := range c.LogTail {
+			if l == "" {
+				lines = append(lines, mrCheckLogPrefix)
+			} else {
+				lines = append(lines, mrCheckLogPrefix+" "+l)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetMRChecks replaces the check results in an MR description, keeping
+// everything else. Nil checks remove them.
+func SetMRChecks(description string, checks []MRCheck) string {
+	var kept []string
+	inCheck := false
+	for _, line := range strings.Split(description, "\n") {
+		if inCheck && strings.HasPrefix(line, mrCheckLogPrefix) {
+			continue
+		}
+		_, inCheck = parseMRCheckLine(line)
+		if !inCheck {
+			kept = append(kept, line)
+		}
+	}
+	for len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+		kept = kept[:len(kept)-1]
+	}
+
+	formatted := FormatMRChecks(checks)
+	switch {
+	case formatted == "":
+		return strings.Join(kept, "\n")
+	case len(kept) == 0:
+		return formatted
+	}
+	return strings.Join(kept, "\n") + "\n\n" + formatted
+}
+
 // SynthesisFields holds structured fields for synthesis beads.
 // These fields track the synthesis step in a convoy workflow.
 type SynthesisFields struct {
@@ -0,0 +1,229 @@
+package beads
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// sqliteBusyTimeout bounds how long a read waits for a bd writer to
+// release its lock before failing (and falling back to the CLI).
+const sqliteBusyTimeout = 5 * time.Second
+
+// openSQLite opens a beads database read-only. The connection takes
+// SQLite's usual shared locks, so a live bd writer is never seen
+// mid-transaction, and committed WAL frames are read like any other client.
+func openSQLite(path string) (*sql.DB, error) {
+	query := url.Values{}
+	query.Set("mode", "ro")
+	query.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", sqliteBusyTimeout.Milliseconds()))
+	query.Add("_pragma", "query_only(1)")
+	dsn := (&url.URL{Scheme: "file", OmitHost: true, Path: path, RawQuery: query.Encode()}).String()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// loadSQLiteSnapshot reads the issues, labels and dependencies tables in
+// one read transaction, so the three are consistent with each other.
+// Columns are looked up by name, which tolerates the columns older and
+// newer bd versions add or lack.
+func loadSQLiteSnapshot(path string) (*storeSnapshot, error) {
+	db, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*sqliteBusyTimeout)
+	defer cancel()
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	tables := make(map[string]bool)
+	err = scanRows(ctx, tx, "SELECT name FROM sqlite_master WHERE type = 'table'", func(row sqliteRow) error {
+		tables[row.str("name")] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !tables["issues"] {
+		return nil, fmt.Errorf("%s has no issues table", path)
+	}
+
+	snap := &storeSnapshot{issues: make(map[string]*Issue)}
+	err = scanRows(ctx, tx, "SELECT * FROM issues", func(row sqliteRow) error {
+		if row.str("deleted_at") != "" || row.str("status") == "tombstone" {
+			return nil
+		}
+		issue := &Issue{
+			ID:          row.str("id"),
+			Title:       row.str("title"),
+			Description: row.str("description"),
+			Status:      row.str("status"),
+			Priority:    row.int("priority"),
+			Type:        row.str("issue_type"),
+			CreatedAt:   sqliteTime(row["created_at"]),
+			CreatedBy:   row.str("created_by"),
+			UpdatedAt:   sqliteTime(row["updated_at"]),
+			ClosedAt:    sqliteTime(row["closed_at"]),
+			Assignee:    row.str("assignee"),
+			Owner:       row.str("owner"),
+			HookBead:    row.str("hook_bead"),
+			RoleBead:    row.str("role_bead"),
+			AgentState:  row.str("agent_state"),
+			Ephemeral:   row.bool("ephemeral"),
+			Pinned:      row.bool("pinned"),
+			DeferUntil:  sqliteTime(row["defer_until"]),
+		}
+		if issue.ID != "" {
+			snap.issues[issue.ID] = issue
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if tables["labels"] {
+		err = scanRows(ctx, tx, "SELECT issue_id, label FROM labels", func(row sqliteRow) error {
+			if issue := snap.issues[row.str("issue_id")]; issue != nil {
+				issue.Labels = append(issue.Labels, row.str("label"))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if tables["dependencies"] {
+		err = scanRows(ctx, tx, "SELECT issue_id, depends_on_id, type FROM dependencies", func(row sqliteRow) error {
+			snap.deps = append(snap.deps, storeDep{
+				IssueID:     row.str("issue_id"),
+				DependsOnID: row.str("depends_on_id"),
+				Type:        row.str("type"),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, issue := range snap.issues {
+		sort.Strings(issue.Labels)
+	}
+	return snap, nil
+}
+
+// scanRows runs a query and calls fn with each row keyed by column name.
+func scanRows(ctx context.Context, tx *sql.Tx, query string, fn func(row sqliteRow) error) error {
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	values := make([]any, len(cols))
+	dest := make([]any, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		row := make(sqliteRow, len(cols))
+		for i, col := range cols {
+			row[col] = values[i]
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// sqliteRow is a table row keyed by column name.
+type sqliteRow map[string]any
+
+func (r sqliteRow) str(col string) string {
+	switch v := r[col].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return fmt.Sprint(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return ""
+}
+
+func (r sqliteRow) int(col string) int {
+	switch v := r[col].(type) {
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+func (r sqliteRow) bool(col string) bool {
+	return r.int(col) != 0
+}
+
+// sqliteTime normalizes a stored timestamp to RFC 3339. The driver parses
+// DATETIME columns; others hold text ("2006-01-02 15:04:05.999999999-07:00")
+// or unix seconds.
+func sqliteTime(v any) string {
+	switch t := v.(type) {
+	case time.Time:
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339Nano)
+	case int64:
+		if t == 0 {
+			return ""
+		}
+		return time.Unix(t, 0).UTC().Format(time.RFC3339)
+	case string:
+		if t == "" {
+			return ""
+		}
+		for _, layout := range []string{
+			time.RFC3339Nano,
+			"2006-01-02 15:04:05.999999999-07:00",
+			"2006-01-02 15:04:05.999999999Z07:00",
+			"2006-01-02 15:04:05.999999999",
+			"2006-01-02T15:04:05.999999999",
+			"2006-01-02",
+		} {
+			if parsed, err := time.Parse(layout, t); err == nil {
+				return parsed.Format(time.RFC3339Nano)
+			}
+		}
+		return t
+	}
+	return ""
+}
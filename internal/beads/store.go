@@ -0,0 +1,427 @@
+package beads
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStoreUnavailable indicates the beads store can't be read in-process
+// (no database, unsupported format, or native reads disabled). Callers fall
+// back to the bd CLI.
+var ErrStoreUnavailable = errors.New("beads store unavailable")
+
+// NativeReadsEnv disables in-process reads when set to "0", forcing every
+// read through the bd CLI.
+const NativeReadsEnv = "GT_BEADS_NATIVE"
+
+// Store reads issues directly from a beads database: the SQLite file (and
+// its WAL) when present, otherwise issues.jsonl (no-db mode). It is
+// read-only; all writes still go through bd.
+//
+// Stores are cached per beads directory and reload only when the
+// underlying files change, so hot paths like gt status and the dashboard
+// can issue many reads per refresh without forking bd.
+type Store struct {
+	beadsDir string
+
+	mu    sync.Mutex
+	stamp string
+	snap  *storeSnapshot
+}
+
+// storeSnapshot is a parsed, immutable copy of a beads database.
+type storeSnapshot struct {
+	issues map[string]*Issue // Labels populated; relations are derived per query
+	deps   []storeDep
+	from   map[string][]storeDep // issue → its dependencies
+	to     map[string][]storeDep // issue → its dependents
+}
+
+// storeDep is a row of the dependencies table.
+type storeDep struct {
+	IssueID     string `json:"issue_id"`
+	DependsOnID string `json:"depends_on_id"`
+	Type        string `json:"type"`
+}
+
+var (
+	storesMu sync.Mutex
+	stores   = make(map[string]*Store)
+)
+
+// OpenStore returns the shared in-process reader for a beads directory.
+func OpenStore(beadsDir string) *Store {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	if s, ok := stores[beadsDir]; ok {
+		return s
+	}
+	s := &Store{beadsDir: beadsDir}
+	stores[beadsDir] = s
+	return s
+}
+
+// databasePath returns the SQLite database in the beads directory, if any.
+func (s *Store) databasePath() string {
+	if _, err := os.Stat(filepath.Join(s.beadsDir, "beads.db")); err == nil {
+		return filepath.Join(s.beadsDir, "beads.db")
+	}
+	matches, _ := filepath.Glob(filepath.Join(s.beadsDir, "*.db"))
+	sort.Strings(matches)
+	for _, m := range matches {
+		if !strings.Contains(filepath.Base(m), "backup") {
+			return m
+		}
+	}
+	return ""
+}
+
+// fileStamp identifies the current version of the files a snapshot came from.
+func fileStamp(paths ...string) string {
+	var b strings.Builder
+	for _, p := range paths {
+		if fi, err := os.Stat(p); err == nil {
+			fmt.Fprintf(&b, "%s:%d:%d;", p, fi.Size(), fi.ModTime().UnixNano())
+		}
+	}
+	return b.String()
+}
+
+// snapshot returns the current parsed database, reloading it if the files changed.
+func (s *Store) snapshot() (*storeSnapshot, error) {
+	if os.Getenv(NativeReadsEnv) == "0" {
+		return nil, ErrStoreUnavailable
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dbPath := s.databasePath()
+	jsonlPath := filepath.Join(s.beadsDir, "issues.jsonl")
+	var stamp string
+	if dbPath != "" {
+		stamp = fileStamp(dbPath, dbPath+"-wal")
+	} else {
+		stamp = fileStamp(jsonlPath)
+	}
+	if stamp == "" {
+		return nil, ErrStoreUnavailable
+	}
+	if s.snap != nil && stamp == s.stamp {
+		return s.snap, nil
+	}
+
+	var snap *storeSnapshot
+	var err error
+	if dbPath != "" {
+		snap, err = loadSQLiteSnapshot(dbPath)
+	} else {
+		snap, err = loadJSONLSnapshot(jsonlPath)
+	}
+	if err != nil {
+		s.snap = nil
+		return nil, fmt.Errorf("%w: %v", ErrStoreUnavailable, err)
+	}
+	snap.index()
+	s.snap, s.stamp = snap, stamp
+	return snap, nil
+}
+
+func (snap *storeSnapshot) index() {
+	snap.from = make(map[string][]storeDep)
+	snap.to = make(map[string][]storeDep)
+	for _, d := range snap.deps {
+		snap.from[d.IssueID] = append(snap.from[d.IssueID], d)
+		snap.to[d.DependsOnID] = append(snap.to[d.DependsOnID], d)
+	}
+}
+
+// jsonlIssue is a line of issues.jsonl (bd's export format).
+type jsonlIssue struct {
+	Issue
+	Wisp          bool       `json:"wisp"`
+	DeferredUntil string     `json:"deferred_until"` // Older spelling of defer_until
+	Dependencies  []storeDep `json:"dependencies"`
+	DeletedAt     string     `json:"deleted_at"`
+}
+
+// loadJSONLSnapshot reads issues.jsonl, the store in no-db mode.
+func loadJSONLSnapshot(path string) (*storeSnapshot, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is the beads JSONL store
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	snap := &storeSnapshot{issues: make(map[string]*Issue)}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry jsonlIssue
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if entry.ID == "" || entry.DeletedAt != "" || entry.Status == "tombstone" {
+			continue
+		}
+		issue := entry.Issue
+		issue.Ephemeral = issue.Ephemeral || entry.Wisp
+		if issue.DeferUntil == "" {
+			issue.DeferUntil = entry.DeferredUntil
+		}
+		issue.Dependencies = nil
+		sort.Strings(issue.Labels)
+		snap.issues[issue.ID] = &issue
+		for _, d := range entry.Dependencies {
+			if d.IssueID == "" {
+				d.IssueID = issue.ID
+			}
+			snap.deps = append(snap.deps, d)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// parentOf returns the parent of an issue (its parent-child dependency).
+func (snap *storeSnapshot) parentOf(id string) string {
+	for _, d := range snap.from[id] {
+		if d.Type == "parent-child" {
+			return d.DependsOnID
+		}
+	}
+	return ""
+}
+
+// listIssue returns a copy of an issue with the fields bd list reports.
+func (snap *storeSnapshot) listIssue(base *Issue) *Issue {
+	issue := *base
+	issue.Labels = append([]string(nil), base.Labels...)
+	issue.Parent = snap.parentOf(base.ID)
+	issue.DependencyCount = len(snap.from[base.ID])
+	issue.DependentCount = len(snap.to[base.ID])
+	for _, d := range snap.from[base.ID] {
+		if d.Type != "blocks" {
+			continue
+		}
+		issue.DependsOn = append(issue.DependsOn, d.DependsOnID)
+		if dep := snap.issues[d.DependsOnID]; dep == nil || dep.Status != "closed" {
+			issue.BlockedBy = append(issue.BlockedBy, d.DependsOnID)
+		}
+	}
+	issue.BlockedByCount = len(issue.BlockedBy)
+	for _, d := range snap.to[base.ID] {
+		if d.Type == "blocks" {
+			issue.Blocks = append(issue.Blocks, d.IssueID)
+		}
+	}
+	return &issue
+}
+
+// showIssue adds the dependency details bd show reports.
+func (snap *storeSnapshot) showIssue(base *Issue) *Issue {
+	issue := snap.listIssue(base)
+	for _, d := range snap.from[base.ID] {
+		issue.Dependencies = append(issue.Dependencies, snap.issueDep(d.DependsOnID, d.Type))
+	}
+	for _, d := range snap.to[base.ID] {
+		issue.Dependents = append(issue.Dependents, snap.issueDep(d.IssueID, d.Type))
+		if d.Type == "parent-child" {
+			issue.Children = append(issue.Children, d.IssueID)
+		}
+	}
+	return issue
+}
+
+func (snap *storeSnapshot) issueDep(id, depType string) IssueDep {
+	dep := IssueDep{ID: id, DependencyType: depType}
+	if other := snap.issues[id]; other != nil {
+		dep.Title = other.Title
+		dep.Status = other.Status
+		dep.Priority = other.Priority
+		dep.Type = other.Type
+	}
+	return dep
+}
+
+// matches reports whether an issue passes the list filters, mirroring bd list.
+func (opts *ListOptions) matches(snap *storeSnapshot, issue *Issue) bool {
+	switch opts.Status {
+	case "":
+		if issue.Status == "closed" {
+			return false
+		}
+	case "all":
+	default:
+		if issue.Status != opts.Status {
+			return false
+		}
+	}
+	label := opts.Label
+	if label == "" && opts.Type != "" {
+		label = "gt:" + opts.Type
+	}
+	if label != "" && !HasLabel(issue, label) {
+		return false
+	}
+	if opts.IssueType != "" && issue.Type != opts.IssueType {
+		return false
+	}
+	if opts.Priority >= 0 && issue.Priority != opts.Priority {
+		return false
+	}
+	if opts.Parent != "" && snap.parentOf(issue.ID) != opts.Parent {
+		return false
+	}
+	if opts.Assignee != "" && issue.Assignee != opts.Assignee {
+		return false
+	}
+	if opts.NoAssignee && issue.Assignee != "" {
+		return false
+	}
+	return true
+}
+
+// List returns issues matching the options, ordered by priority then age.
+func (s *Store) List(opts ListOptions) ([]*Issue, error) {
+	snap, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	var issues []*Issue
+	for _, issue := range snap.issues {
+		if opts.matches(snap, issue) {
+			issues = append(issues, snap.listIssue(issue))
+		}
+	}
+	sortIssues(issues)
+	return issues, nil
+}
+
+// Issues returns every issue, including closed ones, with dependency details.
+func (s *Store) Issues() ([]*Issue, error) {
+	snap, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	issues := make([]*Issue, 0, len(snap.issues))
+	for _, issue := range snap.issues {
+		issues = append(issues, snap.showIssue(issue))
+	}
+	sortIssues(issues)
+	return issues, nil
+}
+
+// Show returns a single issue with its dependencies and dependents.
+func (s *Store) Show(id string) (*Issue, error) {
+	snap, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	issue := snap.issues[id]
+	if issue == nil {
+		return nil, ErrNotFound
+	}
+	return snap.showIssue(issue), nil
+}
+
+// ShowMultiple returns the issues found among ids, keyed by ID.
+func (s *Store) ShowMultiple(ids []string) (map[string]*Issue, error) {
+	snap, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]*Issue, len(ids))
+	for _, id := range ids {
+		if issue := snap.issues[id]; issue != nil {
+			result[id] = snap.showIssue(issue)
+		}
+	}
+	return result, nil
+}
+
+// Ready returns open issues with no open blockers, mirroring bd ready:
+// an issue is blocked by an unclosed "blocks" dependency, or by having a
+// blocked parent. Deferred issues are excluded until their defer time.
+// An empty label matches all issues; limit <= 0 means no limit.
+func (s *Store) Ready(label string, limit int) ([]*Issue, error) {
+	snap, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	blocked := make(map[string]bool)
+	var isBlocked func(id string, depth int) bool
+	isBlocked = func(id string, depth int) bool {
+		if b, ok := blocked[id]; ok || depth > 50 {
+			return b
+		}
+		blocked[id] = false // guard against dependency cycles
+		result := false
+		for _, d := range snap.from[id] {
+			switch d.Type {
+			case "blocks":
+				if dep := snap.issues[d.DependsOnID]; dep == nil || dep.Status != "closed" {
+					result = true
+				}
+			case "parent-child":
+				result = result || isBlocked(d.DependsOnID, depth+1)
+			}
+			if result {
+				break
+			}
+		}
+		blocked[id] = result
+		return result
+	}
+
+	now := time.Now()
+	var issues []*Issue
+	for _, issue := range snap.issues {
+		if issue.Status != "open" || (label != "" && !HasLabel(issue, label)) {
+			continue
+		}
+		if issue.DeferUntil != "" {
+			if t, err := time.Parse(time.RFC3339, issue.DeferUntil); err == nil && t.After(now) {
+				continue
+			}
+		}
+		if isBlocked(issue.ID, 0) {
+			continue
+		}
+		issues = append(issues, snap.listIssue(issue))
+	}
+	sortIssues(issues)
+	if limit > 0 && len(issues) > limit {
+		issues = issues[:limit]
+	}
+	return issues, nil
+}
+
+// sortIssues orders by priority, then creation time, then ID.
+func sortIssues(issues []*Issue) {
+	sort.Slice(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if a.CreatedAt != b.CreatedAt {
+			return a.CreatedAt < b.CreatedAt
+		}
+		return a.ID < b.ID
+	})
+}
@@ -0,0 +1,229 @@
+package beads
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// beadsSchema mirrors the parts of bd's SQLite schema the store reads,
+// including comments and a column added later by ALTER TABLE.
+const beadsSchema = `
+CREATE TABLE issues (
+    id TEXT PRIMARY KEY,
+    title TEXT NOT NULL, -- short summary
+    description TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open',
+    priority INTEGER NOT NULL DEFAULT 2 CHECK(priority >= 0 AND priority <= 4),
+    issue_type TEXT NOT NULL DEFAULT 'task',
+    assignee TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_at DATETIME,
+    /* agent slots */
+    hook_bead TEXT DEFAULT '',
+    ephemeral INTEGER DEFAULT 0
+);
+CREATE TABLE dependencies (
+    issue_id TEXT NOT NULL,
+    depends_on_id TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT 'blocks',
+    PRIMARY KEY (issue_id, depends_on_id)
+);
+CREATE TABLE labels (
+    issue_id TEXT NOT NULL,
+    label TEXT NOT NULL,
+    PRIMARY KEY (issue_id, label)
+);
+CREATE INDEX idx_issues_status ON issues(status);
+`
+
+// openWriter opens a beads database for writing, as bd does. Keeping it
+// open leaves committed transactions in the WAL.
+func openWriter(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func mustExec(t *testing.T, db interface {
+	Exec(string, ...any) (sql.Result, error)
+}, query string) {
+	t.Helper()
+	if _, err := db.Exec(query); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+}
+
+func TestStore_SQLite(t *testing.T) {
+	beadsDir := t.TempDir()
+	db := openWriter(t, filepath.Join(beadsDir, "beads.db"))
+	mustExec(t, db, "PRAGMA journal_mode=WAL;"+beadsSchema)
+	mustExec(t, db, "ALTER TABLE issues ADD COLUMN pinned INTEGER DEFAULT 0;")
+
+	long := strings.Repeat("long ", 2000)
+	var query strings.Builder
+	query.WriteString("BEGIN;")
+	fmt.Fprintf(&query, `INSERT INTO issues (id, title, description, status, priority, issue_type, created_at, updated_at) VALUES ('gt-epic', 'Epic', '%s', 'open', 1, 'epic', '2026-01-01 10:00:00.123-08:00', '2026-01-01 10:00:00-08:00');`, long)
+	query.WriteString(`INSERT INTO issues (id, title, status, priority, assignee, created_at, updated_at) VALUES ('gt-a', 'Blocker', 'in_progress', 0, 'gastown/Toast', '2026-01-02 00:00:00+00:00', '2026-01-02 00:00:00+00:00');`)
+	query.WriteString(`INSERT INTO issues (id, title, status, priority, created_at, updated_at) VALUES ('gt-b', 'Blocked', 'open', 2, '2026-01-03 00:00:00+00:00', '2026-01-03 00:00:00+00:00');`)
+	query.WriteString(`INSERT INTO issues (id, title, status, priority, created_at, updated_at, closed_at) VALUES ('gt-c', 'Done', 'closed', 2, '2026-01-04 00:00:00+00:00', '2026-01-04 00:00:00+00:00', '2026-01-05 00:00:00+00:00');`)
+	query.WriteString(`INSERT INTO issues (id, title, status, priority, created_at, updated_at, ephemeral) VALUES ('gt-wisp', 'Patrol step', 'open', 3, '2026-01-06 00:00:00+00:00', '2026-01-06 00:00:00+00:00', 1);`)
+	query.WriteString(`INSERT INTO issues (id, title, status, created_at, updated_at) VALUES ('gt-gone', 'Deleted', 'tombstone', '2026-01-06 00:00:00+00:00', '2026-01-06 00:00:00+00:00');`)
+	query.WriteString(`INSERT INTO dependencies VALUES ('gt-b', 'gt-a', 'blocks'), ('gt-a', 'gt-epic', 'parent-child'), ('gt-b', 'gt-epic', 'parent-child');`)
+	query.WriteString(`INSERT INTO labels VALUES ('gt-a', 'gt:task'), ('gt-wisp', 'gt:molecule');`)
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&query, `INSERT INTO issues (id, title, status, created_at, updated_at) VALUES ('gt-f%03d', 'Filler %d', 'closed', '2026-02-01 00:00:00+00:00', '2026-02-01 00:00:00+00:00');`, i, i)
+	}
+	query.WriteString("COMMIT;")
+	mustExec(t, db, query.String())
+
+	if _, err := os.Stat(filepath.Join(beadsDir, "beads.db-wal")); err != nil {
+		t.Fatalf("expected uncheckpointed WAL: %v", err)
+	}
+
+	store := OpenStore(beadsDir)
+
+	epic, err := store.Show("gt-epic")
+	if err != nil {
+		t.Fatalf("Show: %v", err)
+	}
+	if epic.Description != long || epic.Type != "epic" || epic.Priority != 1 {
+		t.Errorf("epic = %q (%d bytes), type %q", epic.Title, len(epic.Description), epic.Type)
+	}
+	if epic.CreatedAt != "2026-01-01T10:00:00.123-08:00" {
+		t.Errorf("CreatedAt = %q", epic.CreatedAt)
+	}
+	if len(epic.Children) != 2 || len(epic.Dependents) != 2 {
+		t.Errorf("epic children = %v, dependents = %+v", epic.Children, epic.Dependents)
+	}
+
+	b, err := store.Show("gt-b")
+	if err != nil {
+		t.Fatalf("Show(gt-b): %v", err)
+	}
+	if b.Parent != "gt-epic" || len(b.BlockedBy) != 1 || b.BlockedBy[0] != "gt-a" {
+		t.Errorf("gt-b parent = %q, blocked by %v", b.Parent, b.BlockedBy)
+	}
+	if len(b.Dependencies) != 2 || b.Dependencies[0].Title == "" {
+		t.Errorf("gt-b dependencies = %+v", b.Dependencies)
+	}
+
+	if _, err := store.Show("gt-gone"); err != ErrNotFound {
+		t.Errorf("Show(tombstone) error = %v, want ErrNotFound", err)
+	}
+
+	open, _ := store.List(ListOptions{Priority: -1})
+	if len(open) != 4 {
+		t.Errorf("default List = %d issues, want 4 non-closed", len(open))
+	}
+	all, _ := store.List(ListOptions{Status: "all", Priority: -1})
+	if len(all) != 305 {
+		t.Errorf("List(all) = %d issues, want 305", len(all))
+	}
+	assigned, _ := store.List(ListOptions{Status: "in_progress", Assignee: "gastown/Toast", Label: "gt:task", Priority: -1})
+	if len(assigned) != 1 || assigned[0].ID != "gt-a" || assigned[0].Parent != "gt-epic" {
+		t.Errorf("List(assignee) = %+v", assigned)
+	}
+	children, _ := store.List(ListOptions{Status: "all", Parent: "gt-epic", Priority: -1})
+	if len(children) != 2 {
+		t.Errorf("List(parent) = %d, want 2", len(children))
+	}
+	if p0, _ := store.List(ListOptions{Status: "all", Priority: 0}); len(p0) != 1 {
+		t.Errorf("List(priority 0) = %d, want 1", len(p0))
+	}
+
+	ready, _ := store.Ready("", 0)
+	var readyIDs []string
+	for _, issue := range ready {
+		readyIDs = append(readyIDs, issue.ID)
+	}
+	if strings.Join(readyIDs, ",") != "gt-epic,gt-wisp" {
+		t.Errorf("Ready = %v, want [gt-epic gt-wisp]", readyIDs)
+	}
+	if mols, _ := store.Ready("gt:molecule", 10); len(mols) != 1 || !mols[0].Ephemeral {
+		t.Errorf("Ready(gt:molecule) = %+v", mols)
+	}
+
+	// Writes are picked up on the next read
+	mustExec(t, db, "UPDATE issues SET status = 'closed' WHERE id = 'gt-a';")
+	ready, _ = store.Ready("", 0)
+	if len(ready) != 3 {
+		t.Errorf("Ready after closing blocker = %d, want 3", len(ready))
+	}
+
+	// A transaction bd hasn't committed yet isn't seen
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustExec(t, tx, "UPDATE issues SET title = 'Renamed' WHERE id = 'gt-b';")
+	if issue, err := store.Show("gt-b"); err != nil || issue.Title != "Blocked" {
+		t.Errorf("Show during a write = %+v, %v", issue, err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	// And writes survive a checkpoint into the main database file
+	mustExec(t, db, "PRAGMA wal_checkpoint(TRUNCATE);")
+	if issue, err := store.Show("gt-a"); err != nil || issue.Status != "closed" {
+		t.Errorf("Show after checkpoint = %+v, %v", issue, err)
+	}
+}
+
+func TestStore_JSONL(t *testing.T) {
+	beadsDir := t.TempDir()
+	lines := []string{
+		`{"id":"hq-1","title":"Mail","status":"open","priority":2,"issue_type":"message","assignee":"mayor/","labels":["from:gastown/Toast"],"created_at":"2026-01-01T00:00:00Z"}`,
+		`{"id":"hq-2","title":"Blocked","status":"open","priority":1,"issue_type":"task","dependencies":[{"issue_id":"hq-2","depends_on_id":"hq-3","type":"blocks"}]}`,
+		`{"id":"hq-3","title":"Later","status":"open","priority":1,"issue_type":"task","deferred_until":"2999-01-01T00:00:00Z"}`,
+	}
+	if err := os.WriteFile(filepath.Join(beadsDir, "issues.jsonl"), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := OpenStore(beadsDir)
+
+	msgs, err := store.List(ListOptions{IssueType: "message", Assignee: "mayor/", Status: "open", Priority: -1})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].Labels[0] != "from:gastown/Toast" {
+		t.Errorf("messages = %+v", msgs)
+	}
+
+	blocked, _ := store.Show("hq-2")
+	if len(blocked.BlockedBy) != 1 || blocked.Dependencies[0].Title != "Later" {
+		t.Errorf("hq-2 = %+v", blocked)
+	}
+	if ready, _ := store.Ready("", 0); len(ready) != 1 || ready[0].ID != "hq-1" {
+		t.Errorf("Ready = %+v, want only hq-1", ready)
+	}
+}
+
+func TestStore_Unavailable(t *testing.T) {
+	beadsDir := t.TempDir()
+	if _, err := OpenStore(beadsDir).List(ListOptions{}); err == nil {
+		t.Error("expected ErrStoreUnavailable for an empty beads dir")
+	}
+
+	if err := os.WriteFile(filepath.Join(beadsDir, "beads.db"), []byte("not a database"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenStore(beadsDir).Show("gt-1"); err == nil || err == ErrNotFound {
+		t.Errorf("Show on a corrupt db = %v, want store unavailable", err)
+	}
+
+	t.Setenv(NativeReadsEnv, "0")
+	if _, err := OpenStore(t.TempDir()).Ready("", 0); err != ErrStoreUnavailable {
+		t.Errorf("with %s=0: err = %v", NativeReadsEnv, err)
+	}
+}
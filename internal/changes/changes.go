@@ -0,0 +1,278 @@
+// Package changes provides the typed change stream the daemon publishes for
+// live consumers such as the web GUI.
+//
+// Changes are appended to <town>/daemon/changes.jsonl, each with a sequence
+// number one greater than the last. The sequence number is the resume token:
+// a consumer that reconnects passes the last sequence it saw to Since and gets
+// everything published after it, or a reset when the log has been trimmed
+// past that point and the consumer must reload its full view.
+package changes
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// Kind identifies what changed.
+type Kind string
+
+// Change kinds published by the daemon.
+const (
+	KindBeadUpdated Kind = "bead.updated" // Any issue created, updated or closed
+	KindAgentState  Kind = "agent.state"  // Agent bead state or tmux session changed
+	KindMRState     Kind = "mr.state"     // Merge-request bead changed
+	KindMailArrived Kind = "mail.arrived" // New message bead
+)
+
+// Change is one entry in the change stream.
+type Change struct {
+	Seq    uint64            `json:"seq"`
+	Time   time.Time         `json:"ts"`
+	Kind   Kind              `json:"kind"`
+	ID     string            `json:"id"`               // Bead ID, or agent address for session changes
+	Status string            `json:"status,omitempty"` // New status, agent state or MR status
+	Title  string            `json:"title,omitempty"`
+	Actor  string            `json:"actor,omitempty"` // Who made the change, if known
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// Token returns the resume token for this change.
+func (c Change) Token() string {
+	return FormatToken(c.Seq)
+}
+
+// FormatToken renders a sequence number as a resume token.
+func FormatToken(seq uint64) string {
+	return strconv.FormatUint(seq, 10)
+}
+
+// ParseToken parses a resume token. An empty token is sequence zero,
+// meaning "from the start of the retained log".
+func ParseToken(token string) (uint64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	seq, err := strconv.ParseUint(token, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid resume token %q", token)
+	}
+	return seq, nil
+}
+
+// File is the change log path relative to the town root.
+const File = "daemon/changes.jsonl"
+
+// Retention limits. When the log grows past MaxSize it is rewritten to keep
+// the newest KeepEntries changes. Variables so tests can shrink them.
+var (
+	MaxSize     int64 = 1 << 20
+	KeepEntries       = 1000
+)
+
+// tailChunk is how much of the log end lastSeq reads first.
+const tailChunk = 8 << 10
+
+// mutex serializes publishers within a process; the file lock covers
+// other processes.
+var mutex sync.Mutex
+
+// Path returns the change log path for a town.
+func Path(townRoot string) string {
+	return filepath.Join(townRoot, File)
+}
+
+// Publish appends a change to the town's log, assigning its sequence number
+// and, if unset, its timestamp. The stored change is returned.
+func Publish(townRoot string, c Change) (Change, error) {
+	path := Path(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return c, fmt.Errorf("creating daemon dir: %w", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return c, fmt.Errorf("locking change log: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644) //nolint:gosec // G302: change log is non-sensitive operational data
+	if err != nil {
+		return c, fmt.Errorf("opening change log: %w", err)
+	}
+	defer f.Close()
+
+	last, err := lastSeq(f)
+	if err != nil {
+		return c, err
+	}
+	c.Seq = last + 1
+	if c.Time.IsZero() {
+		c.Time = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return c, fmt.Errorf("marshaling change: %w", err)
+	}
+	data = append(data, '\n')
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		// Terminate a line left partial by a crashed writer
+		end := make([]byte, 1)
+		if _, err := f.ReadAt(end, info.Size()-1); err == nil && end[0] != '\n' {
+			data = append([]byte{'\n'}, data...)
+		}
+	}
+	if _, err := f.Write(data); err != nil {
+		return c, fmt.Errorf("writing change: %w", err)
+	}
+
+	if info, err := f.Stat(); err == nil && info.Size() > MaxSize {
+		// Best-effort: an oversized log is still a valid log
+		_ = trim(path)
+	}
+	return c, nil
+}
+
+// Since returns the retained changes with a sequence number greater than
+// after, oldest first. reset reports that changes after the token may have
+// been lost (trimmed, or the log was recreated), so the caller should reload
+// its full view before applying the returned changes.
+func Since(townRoot string, after uint64) (changes []Change, reset bool, err error) {
+	all, err := readAll(Path(townRoot))
+	if err != nil {
+		return nil, false, err
+	}
+	if after == 0 {
+		return all, false, nil
+	}
+	if len(all) == 0 {
+		return nil, true, nil
+	}
+	first, last := all[0].Seq, all[len(all)-1].Seq
+	if after > last || first > after+1 {
+		return all, true, nil
+	}
+	for i, c := range all {
+		if c.Seq > after {
+			return all[i:], false, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// Latest returns the sequence number of the newest change, or zero when the
+// log is empty.
+func Latest(townRoot string) (uint64, error) {
+	f, err := os.Open(Path(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer f.Close()
+	return lastSeq(f)
+}
+
+// lastSeq returns the sequence number of the last complete line in f. It
+// reads backwards from the end, doubling the window until a whole line that
+// parses is in it, so a long change can't hide the ones before it.
+func lastSeq(f *os.File) (uint64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	for window := int64(tailChunk); ; window *= 2 {
+		start := size - window
+		if start < 0 {
+			start = 0
+		}
+		buf := make([]byte, size-start)
+		if _, err := f.ReadAt(buf, start); err != nil && err != io.EOF {
+			return 0, fmt.Errorf("reading change log: %w", err)
+		}
+		lines := bytes.Split(bytes.TrimRight(buf, "\n"), []byte("\n"))
+		// The first line is cut off unless the window reaches the start
+		first := 1
+		if start == 0 {
+			first = 0
+		}
+		for i := len(lines) - 1; i >= first; i-- {
+			var c Change
+			if json.Unmarshal(lines[i], &c) == nil && c.Seq > 0 {
+				return c.Seq, nil
+			}
+		}
+		if start == 0 {
+			return 0, nil
+		}
+	}
+}
+
+// readAll parses every well-formed change in the log. A missing log is empty.
+func readAll(path string) ([]Change, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is constructed from town root
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening change log: %w", err)
+	}
+	defer f.Close()
+
+	var out []Change
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var c Change
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil || c.Seq == 0 {
+			continue // Skip malformed or partially written lines
+		}
+		out = append(out, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading change log: %w", err)
+	}
+	return out, nil
+}
+
+// trim rewrites the log keeping the newest KeepEntries changes. The caller
+// holds the file lock; readers see either the old or the new file.
+func trim(path string) error {
+	all, err := readAll(path)
+	if err != nil {
+		return err
+	}
+	if len(all) > KeepEntries {
+		all = all[len(all)-KeepEntries:]
+	}
+
+	var buf bytes.Buffer
+	for _, c := range all {
+		data, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil { //nolint:gosec // G306: change log is non-sensitive operational data
+		return err
+	}
+	return os.Rename(tmp, path)
+}
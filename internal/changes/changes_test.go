@@ -0,0 +1,148 @@
+package changes
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPublishAndSince(t *testing.T) {
+	town := t.TempDir()
+
+	for i := 1; i <= 3; i++ {
+		c, err := Publish(town, Change{Kind: KindBeadUpdated, ID: fmt.Sprintf("gt-%d", i)})
+		if err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+		if c.Seq != uint64(i) || c.Time.IsZero() {
+			t.Errorf("published change = %+v, want seq %d with a timestamp", c, i)
+		}
+	}
+
+	all, reset, err := Since(town, 0)
+	if err != nil || reset || len(all) != 3 {
+		t.Fatalf("Since(0) = %d changes, reset=%v, err=%v", len(all), reset, err)
+	}
+
+	tail, reset, _ := Since(town, 2)
+	if reset || len(tail) != 1 || tail[0].ID != "gt-3" {
+		t.Errorf("Since(2) = %+v, reset=%v", tail, reset)
+	}
+
+	none, reset, _ := Since(town, 3)
+	if reset || len(none) != 0 {
+		t.Errorf("Since(latest) = %+v, reset=%v", none, reset)
+	}
+
+	// A token from a previous log (ahead of it) forces a reset
+	if _, reset, _ := Since(town, 99); !reset {
+		t.Error("Since(99) should reset")
+	}
+
+	if latest, _ := Latest(town); latest != 3 {
+		t.Errorf("Latest = %d, want 3", latest)
+	}
+}
+
+func TestPublish_LongChangeKeepsNumbering(t *testing.T) {
+	town := t.TempDir()
+
+	if _, err := Publish(town, Change{Kind: KindBeadUpdated, ID: "gt-1"}); err != nil {
+		t.Fatal(err)
+	}
+	// Longer than the first window lastSeq reads
+	long := Change{Kind: KindBeadUpdated, ID: "gt-2", Title: strings.Repeat("x", 5*tailChunk)}
+	if c, err := Publish(town, long); err != nil || c.Seq != 2 {
+		t.Fatalf("Publish(long) = %d, %v", c.Seq, err)
+	}
+	if c, err := Publish(town, Change{Kind: KindBeadUpdated, ID: "gt-3"}); err != nil || c.Seq != 3 {
+		t.Fatalf("Publish after a long change = %d, %v, want seq 3", c.Seq, err)
+	}
+	if latest, _ := Latest(town); latest != 3 {
+		t.Errorf("Latest = %d, want 3", latest)
+	}
+
+	// A partial line after the long one is skipped, not taken for the end
+	f, err := os.OpenFile(Path(town), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"seq":4,"kind":"bead.up`)
+	_ = f.Close()
+	if latest, _ := Latest(town); latest != 3 {
+		t.Errorf("Latest with a torn last line = %d, want 3", latest)
+	}
+}
+
+func TestPublish_TrimsAndResets(t *testing.T) {
+	town := t.TempDir()
+	oldSize, oldKeep := MaxSize, KeepEntries
+	MaxSize, KeepEntries = 1, 2
+	defer func() { MaxSize, KeepEntries = oldSize, oldKeep }()
+
+	for i := 0; i < 5; i++ {
+		if _, err := Publish(town, Change{Kind: KindMailArrived, ID: "hq-msg"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, _, _ := Since(town, 0)
+	if len(all) != 2 || all[0].Seq != 4 || all[1].Seq != 5 {
+		t.Fatalf("retained = %+v, want seqs 4 and 5", all)
+	}
+
+	// Sequence numbers keep counting across a trim
+	c, _ := Publish(town, Change{Kind: KindMailArrived, ID: "hq-msg"})
+	if c.Seq != 6 {
+		t.Errorf("seq after trim = %d, want 6", c.Seq)
+	}
+
+	// Seq 1 was trimmed: resuming from it may have missed changes
+	if _, reset, _ := Since(town, 1); !reset {
+		t.Error("Since(trimmed) should reset")
+	}
+}
+
+func TestSince_SkipsMalformedAndMissing(t *testing.T) {
+	town := t.TempDir()
+	if got, reset, err := Since(town, 0); err != nil || reset || got != nil {
+		t.Errorf("missing log: %v %v %v", got, reset, err)
+	}
+	if _, reset, _ := Since(town, 5); !reset {
+		t.Error("resuming against a missing log should reset")
+	}
+
+	if _, err := Publish(town, Change{Kind: KindAgentState, ID: "gastown/witness", Status: "running"}); err != nil {
+		t.Fatal(err)
+	}
+	f, _ := os.OpenFile(Path(town), os.O_APPEND|os.O_WRONLY, 0644)
+	_, _ = f.WriteString("{\"seq\":2,\"kind\"")
+	_ = f.Close()
+
+	all, _, _ := Since(town, 0)
+	if len(all) != 1 {
+		t.Errorf("changes = %+v, want the partial line skipped", all)
+	}
+
+	// The next publish starts on a fresh line
+	c, err := Publish(town, Change{Kind: KindAgentState, ID: "gastown/witness", Status: "stopped"})
+	if err != nil || c.Seq != 2 {
+		t.Fatalf("Publish after partial line = %+v, %v", c, err)
+	}
+	if all, _, _ := Since(town, 1); len(all) != 1 || all[0].Status != "stopped" {
+		t.Errorf("Since(1) = %+v", all)
+	}
+}
+
+func TestParseToken(t *testing.T) {
+	if seq, err := ParseToken(""); err != nil || seq != 0 {
+		t.Errorf("ParseToken(\"\") = %d, %v", seq, err)
+	}
+	if seq, err := ParseToken(FormatToken(42)); err != nil || seq != 42 {
+		t.Errorf("round trip = %d, %v", seq, err)
+	}
+	if _, err := ParseToken("abc"); err == nil {
+		t.Error("expected error for non-numeric token")
+	}
+}
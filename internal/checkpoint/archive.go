@@ -0,0 +1,159 @@
+package checkpoint
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// PatchFilename is the WIP patch file name, next to an archived checkpoint.
+const PatchFilename = ".polecat-wip.patch"
+
+// patchExcludes are the paths left out of WIP patches: checkpoint files and
+// the beads redirect Gas Town provisions in every worktree.
+var patchExcludes = []string{Filename, PatchFilename, ".beads"}
+
+// ArchiveRoot returns the directory holding a rig's checkpoint archives.
+func ArchiveRoot(rigPath string) string {
+	return filepath.Join(rigPath, constants.DirRuntime, "checkpoints")
+}
+
+// ArchiveDir returns where a polecat's latest checkpoint is archived:
+// <rig>/.runtime/checkpoints/<polecat>. It lives outside the worktree so
+// the work can be restored to a new polecat when the worktree is lost.
+func ArchiveDir(rigPath, polecatName string) string {
+	return filepath.Join(ArchiveRoot(rigPath), polecatName)
+}
+
+// Archive saves a checkpoint and the WIP patch of its uncommitted changes
+// to a polecat's archive, replacing the previous one.
+func Archive(rigPath, polecatName string, cp *Checkpoint, patch []byte) error {
+	dir := ArchiveDir(rigPath, polecatName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating checkpoint archive: %w", err)
+	}
+
+	patchPath := filepath.Join(dir, PatchFilename)
+	cp.HasPatch = len(patch) > 0
+	if cp.HasPatch {
+		if err := os.WriteFile(patchPath, patch, 0600); err != nil {
+			return fmt.Errorf("writing WIP patch: %w", err)
+		}
+	} else if err := os.Remove(patchPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing WIP patch: %w", err)
+	}
+
+	return Write(dir, cp)
+}
+
+// LoadArchive returns a polecat's archived checkpoint and WIP patch.
+// Returns nil, nil, nil if nothing is archived.
+func LoadArchive(rigPath, polecatName string) (*Checkpoint, []byte, error) {
+	dir := ArchiveDir(rigPath, polecatName)
+	cp, err := Read(dir)
+	if err != nil || cp == nil {
+		return nil, nil, err
+	}
+	if !cp.HasPatch {
+		return cp, nil, nil
+	}
+
+	patch, err := os.ReadFile(filepath.Join(dir, PatchFilename)) //nolint:gosec // G304: path is constructed from trusted rigPath
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading WIP patch: %w", err)
+	}
+	return cp, patch, nil
+}
+
+// RemoveArchive deletes a polecat's archived checkpoint.
+func RemoveArchive(rigPath, polecatName string) error {
+	if err := os.RemoveAll(ArchiveDir(rigPath, polecatName)); err != nil {
+		return fmt.Errorf("removing checkpoint archive: %w", err)
+	}
+	return nil
+}
+
+// Snapshot captures a worktree for the archive: its git state, the molecule
+// context and notes of the agent's own checkpoint if it wrote one, and a
+// WIP patch of its uncommitted changes.
+func Snapshot(workDir string) (*Checkpoint, []byte, error) {
+	cp, err := Capture(workDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if own, err := Read(workDir); err == nil && own != nil {
+		cp.WithMolecule(own.MoleculeID, own.CurrentStep, own.StepTitle)
+		cp.WithHookedBead(own.HookedBead)
+		cp.WithNotes(own.Notes)
+	}
+
+	patch, err := CapturePatch(workDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cp, patch, nil
+}
+
+// CapturePatch returns a binary patch of the uncommitted changes in a
+// worktree against HEAD, untracked files included (ignored ones are not).
+// The patch is built through a temporary index, so neither the worktree
+// nor its index is touched. Returns nil when there is nothing uncommitted.
+func CapturePatch(workDir string) ([]byte, error) {
+	tmpDir, err := os.MkdirTemp("", "gt-checkpoint-")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp index: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	env := append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(tmpDir, "index"))
+
+	addArgs := []string{"add", "-A", "--", "."}
+	for _, path := range patchExcludes {
+		addArgs = append(addArgs, ":(exclude)"+path)
+	}
+	for _, args := range [][]string{{"read-tree", "HEAD"}, addArgs} {
+		if _, err := runGit(workDir, env, nil, args...); err != nil {
+			return nil, err
+		}
+	}
+
+	patch, err := runGit(workDir, env, nil, "diff", "--cached", "--binary", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(patch)) == 0 {
+		return nil, nil
+	}
+	return patch, nil
+}
+
+// ApplyPatch applies a WIP patch to a worktree checked out at the
+// checkpoint's LastCommit. The changes are left uncommitted.
+func ApplyPatch(workDir string, patch []byte) error {
+	if len(patch) == 0 {
+		return nil
+	}
+	_, err := runGit(workDir, nil, patch, "apply", "--binary", "--whitespace=nowarn", "-")
+	return err
+}
+
+// runGit runs a git command in dir, returning its stdout.
+func runGit(dir string, env []string, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,152 @@
+package checkpoint
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initRepo creates a git repo with one commit.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test"},
+	} {
+		git(t, dir, args...)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestCapturePatch_RestoresToCleanCheckout(t *testing.T) {
+	repo := initRepo(t)
+
+	// Nothing uncommitted: no patch
+	if patch, err := CapturePatch(repo); err != nil || patch != nil {
+		t.Fatalf("CapturePatch(clean) = %q, %v", patch, err)
+	}
+
+	// A modified file, a staged new file, an untracked file, and files
+	// that must stay out of the patch
+	files := map[string]string{
+		"main.go":         "package main\n\nfunc main() {}\n",
+		"staged.go":       "package main // staged\n",
+		"notes/todo.txt":  "untracked\n",
+		Filename:          "{}",
+		".beads/redirect": "../../.beads\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git(t, repo, "add", "staged.go")
+	statusBefore := git(t, repo, "status", "--porcelain")
+
+	patch, err := CapturePatch(repo)
+	if err != nil {
+		t.Fatalf("CapturePatch() error = %v", err)
+	}
+	if got := git(t, repo, "status", "--porcelain"); got != statusBefore {
+		t.Errorf("CapturePatch changed the index:\nbefore %q\nafter  %q", statusBefore, got)
+	}
+	for _, excluded := range []string{Filename, ".beads"} {
+		if strings.Contains(string(patch), excluded) {
+			t.Errorf("patch should not contain %s", excluded)
+		}
+	}
+
+	// Apply to a fresh checkout of the same commit
+	clone := filepath.Join(t.TempDir(), "clone")
+	git(t, repo, "worktree", "add", "-q", "--detach", clone, "HEAD")
+	if err := ApplyPatch(clone, patch); err != nil {
+		t.Fatalf("ApplyPatch() error = %v", err)
+	}
+	for _, name := range []string{"main.go", "staged.go", "notes/todo.txt"} {
+		got, err := os.ReadFile(filepath.Join(clone, filepath.FromSlash(name)))
+		if err != nil || string(got) != files[name] {
+			t.Errorf("%s = %q, %v; want %q", name, got, err, files[name])
+		}
+	}
+}
+
+func TestArchive_RoundTrip(t *testing.T) {
+	rigPath := t.TempDir()
+
+	if cp, patch, err := LoadArchive(rigPath, "Toast"); cp != nil || patch != nil || err != nil {
+		t.Fatalf("LoadArchive(empty) = %v, %q, %v", cp, patch, err)
+	}
+
+	cp := &Checkpoint{HookedBead: "gt-abc", Branch: "polecat/Toast"}
+	if err := Archive(rigPath, "Toast", cp, []byte("diff --git a/x b/x\n")); err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	loaded, patch, err := LoadArchive(rigPath, "Toast")
+	if err != nil || loaded == nil {
+		t.Fatalf("LoadArchive() = %v, %v", loaded, err)
+	}
+	if !loaded.HasPatch || loaded.HookedBead != "gt-abc" || string(patch) != "diff --git a/x b/x\n" {
+		t.Errorf("LoadArchive() = %+v, %q", loaded, patch)
+	}
+
+	// A later checkpoint without uncommitted work drops the old patch
+	if err := Archive(rigPath, "Toast", &Checkpoint{HookedBead: "gt-abc"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(ArchiveDir(rigPath, "Toast"), PatchFilename)); !os.IsNotExist(err) {
+		t.Errorf("stale WIP patch should be removed: %v", err)
+	}
+	if loaded, patch, _ := LoadArchive(rigPath, "Toast"); loaded.HasPatch || patch != nil {
+		t.Errorf("LoadArchive() after clean checkpoint = %+v, %q", loaded, patch)
+	}
+
+	if err := RemoveArchive(rigPath, "Toast"); err != nil {
+		t.Fatal(err)
+	}
+	if cp, _, _ := LoadArchive(rigPath, "Toast"); cp != nil {
+		t.Error("archive should be removed")
+	}
+}
+
+func TestSnapshot_KeepsAgentContext(t *testing.T) {
+	repo := initRepo(t)
+	if err := Write(repo, &Checkpoint{MoleculeID: "mol-1", CurrentStep: "step-2", Notes: "halfway"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cp, patch, err := Snapshot(repo)
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if cp.MoleculeID != "mol-1" || cp.CurrentStep != "step-2" || cp.Notes != "halfway" || cp.LastCommit == "" {
+		t.Errorf("Snapshot() = %+v", cp)
+	}
+	// The agent's checkpoint file is untracked but not work
+	if patch != nil {
+		t.Errorf("Snapshot() patch = %q, want none", patch)
+	}
+}
@@ -49,6 +49,14 @@ type Checkpoint struct {
 
 	// Notes contains optional context from the session.
 	Notes string `json:"notes,omitempty"`
+
+	// HasPatch is set when a WIP patch of the uncommitted changes was saved
+	// with the checkpoint (see Archive).
+	HasPatch bool `json:"has_patch,omitempty"`
+
+	// RestoredFrom is the polecat (rig/name) whose checkpoint this was
+	// restored from, when the work moved to a new polecat.
+	RestoredFrom string `json:"restored_from,omitempty"`
 }
 
 // Path returns the checkpoint file path for a given polecat directory.
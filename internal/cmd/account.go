@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/account"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/style"
@@ -18,6 +20,7 @@ var (
 	accountJSON        bool
 	accountEmail       string
 	accountDescription string
+	accountWeeklyLimit int64
 )
 
 var accountCmd = &cobra.Command{
@@ -34,7 +37,8 @@ Commands:
   gt account list              List registered accounts
   gt account add <handle>      Add a new account
   gt account default <handle>  Set the default account
-  gt account status            Show current account info`,
+  gt account status            Show current account info and usage
+  gt account rotate on|off     Pick accounts by remaining quota`,
 }
 
 var accountListCmd = &cobra.Command{
@@ -62,7 +66,8 @@ that directory to complete the login.
 Examples:
   gt account add work
   gt account add work --email steve@company.com
-  gt account add work --email steve@company.com --desc "Work account"`,
+  gt account add work --email steve@company.com --desc "Work account"
+  gt account add work --weekly-limit 50000000`,
 	Args: cobra.ExactArgs(1),
 	RunE: runAccountAdd,
 }
@@ -193,9 +198,10 @@ func runAccountAdd(cmd *cobra.Command, args []string) error {
 
 	// Add account
 	cfg.Accounts[handle] = config.Account{
-		Email:       accountEmail,
-		Description: accountDescription,
-		ConfigDir:   configDir,
+		Email:            accountEmail,
+		Description:      accountDescription,
+		ConfigDir:        configDir,
+		WeeklyTokenLimit: accountWeeklyLimit,
 	}
 
 	// If this is the first account, make it default
@@ -252,18 +258,49 @@ func runAccountDefault(cmd *cobra.Command, args []string) error {
 var accountStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show current account info",
-	Long: `Show which Claude Code account would be used for new sessions.
+	Long: `Show which Claude Code account would be used for new sessions,
+and how much each account has been used.
 
 Displays the currently resolved account based on:
 1. GT_ACCOUNT environment variable (highest priority)
-2. Default account from config
+2. Default account from config, or with rotation on, the account
+   with the most quota left
+
+For each account it shows the tokens used in the last seven days (from
+the stats cache in its config dir) against its weekly_token_limit, whether
+it is rate-limited and until when, and how many sessions were spawned on
+it or moved to it from a rate-limited account.
 
 Examples:
   gt account status           # Show current account
+  gt account status --json    # Per-account usage as JSON
   GT_ACCOUNT=work gt account status  # Show with env override`,
 	RunE: runAccountStatus,
 }
 
+var accountRotateCmd = &cobra.Command{
+	Use:   "rotate <on|off>",
+	Short: "Pick accounts by remaining quota",
+	Long: `Turn quota-aware account rotation on or off.
+
+With rotation on, new polecats are spawned on the account with the most
+quota left instead of the default: accounts that aren't rate-limited
+first, then by the fraction of weekly_token_limit left (or, without a
+limit, by the fewest tokens used this week). The daemon also watches
+polecat sessions for rate-limit banners and moves a limited session to
+another account, resuming its conversation there.
+
+Rate limits are recorded either way; rotation needs two or more accounts.
+GT_ACCOUNT and --account still pick an account explicitly.
+
+Examples:
+  gt account rotate on
+  gt account rotate off`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"on", "off"},
+	RunE:      runAccountRotate,
+}
+
 var accountSwitchCmd = &cobra.Command{
 	Use:   "switch <handle>",
 	Short: "Switch to a different account",
@@ -292,11 +329,15 @@ func runAccountStatus(cmd *cobra.Command, args []string) error {
 	accountsPath := constants.MayorAccountsPath(townRoot)
 
 	// Resolve account (empty flag since we want to show default resolution)
-	configDir, handle, err := config.ResolveAccountConfigDir(accountsPath, "")
+	configDir, handle, err := account.Resolve(townRoot, "")
 	if err != nil {
 		return fmt.Errorf("resolving account: %w", err)
 	}
 
+	if accountJSON {
+		return printAccountUsageJSON(townRoot, accountsPath)
+	}
+
 	if handle == "" {
 		fmt.Println("No account configured.")
 		fmt.Println("\nTo add an account:")
@@ -330,10 +371,138 @@ func runAccountStatus(cmd *cobra.Command, args []string) error {
 
 	if envAccount != "" {
 		fmt.Printf("\n%s\n", style.Dim.Render("(set via GT_ACCOUNT environment variable)"))
+	} else if cfg.Rotate && len(cfg.Accounts) > 1 {
+		fmt.Printf("\n%s\n", style.Dim.Render("(picked by rotation: most quota left)"))
 	} else if handle == cfg.Default {
 		fmt.Printf("\n%s\n", style.Dim.Render("(default account)"))
 	}
 
+	return printAccountUsage(townRoot, cfg)
+}
+
+// AccountUsageItem is an account's usage in status output.
+type AccountUsageItem struct {
+	Handle           string     `json:"handle"`
+	IsDefault        bool       `json:"is_default"`
+	WeeklyTokens     *int64     `json:"weekly_tokens,omitempty"`
+	WeeklyTokenLimit int64      `json:"weekly_token_limit,omitempty"`
+	RemainingPercent *float64   `json:"remaining_percent,omitempty"`
+	RateLimitedUntil *time.Time `json:"rate_limited_until,omitempty"`
+	RateLimitReason  string     `json:"rate_limit_reason,omitempty"`
+	Spawns           int        `json:"spawns"`
+	Migrations       int        `json:"migrations"`
+}
+
+// accountUsage lists the accounts in rotation order.
+func accountUsage(townRoot string, cfg *config.AccountsConfig) ([]AccountUsageItem, error) {
+	state, err := account.LoadState(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var items []AccountUsageItem
+	for _, c := range account.Rank(cfg, state, now) {
+		item := AccountUsageItem{
+			Handle:           c.Handle,
+			IsDefault:        c.Handle == cfg.Default,
+			WeeklyTokenLimit: c.Usage.Limit,
+		}
+		if c.Usage.Known {
+			tokens := c.Usage.WeeklyTokens
+			item.WeeklyTokens = &tokens
+		}
+		if left := c.Usage.Remaining(); left >= 0 {
+			pct := left * 100
+			item.RemainingPercent = &pct
+		}
+		if c.Status != nil {
+			if c.Status.RateLimited(now) {
+				until := c.Status.RateLimitedUntil
+				item.RateLimitedUntil = &until
+				item.RateLimitReason = c.Status.Reason
+			}
+			item.Spawns = c.Status.Spawns
+			item.Migrations = c.Status.Migrations
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func printAccountUsageJSON(townRoot, accountsPath string) error {
+	items := []AccountUsageItem{}
+	if cfg, err := config.LoadAccountsConfig(accountsPath); err == nil {
+		if items, err = accountUsage(townRoot, cfg); err != nil {
+			return err
+		}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(items)
+}
+
+func printAccountUsage(townRoot string, cfg *config.AccountsConfig) error {
+	items, err := accountUsage(townRoot, cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%s\n\n", style.Bold.Render("Usage (last 7 days)"))
+	for _, item := range items {
+		marker := "  "
+		if item.IsDefault {
+			marker = "* "
+		}
+		usage := style.Dim.Render("usage unknown")
+		if item.WeeklyTokens != nil {
+			usage = formatTokenCount(*item.WeeklyTokens) + " tokens"
+			if item.WeeklyTokenLimit > 0 {
+				usage += " / " + formatTokenCount(item.WeeklyTokenLimit)
+			}
+			if item.RemainingPercent != nil {
+				usage += fmt.Sprintf(" (%.0f%% left)", *item.RemainingPercent)
+			}
+		}
+		fmt.Printf("%s%-12s %s  %s\n", marker, item.Handle, usage,
+			style.Dim.Render(fmt.Sprintf("%d spawned, %d moved in", item.Spawns, item.Migrations)))
+		if item.RateLimitedUntil != nil {
+			fmt.Printf("    %s %s\n",
+				style.Warning.Render("rate-limited until "+item.RateLimitedUntil.Local().Format("Mon 15:04")),
+				style.Dim.Render(item.RateLimitReason))
+		}
+	}
+	return nil
+}
+
+func runAccountRotate(cmd *cobra.Command, args []string) error {
+	var rotate bool
+	switch args[0] {
+	case "on":
+		rotate = true
+	case "off":
+	default:
+		return fmt.Errorf("expected on or off, got %q", args[0])
+	}
+
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil {
+		return fmt.Errorf("finding town root: %w", err)
+	}
+	accountsPath := constants.MayorAccountsPath(townRoot)
+	cfg, err := config.LoadAccountsConfig(accountsPath)
+	if err != nil {
+		return fmt.Errorf("loading accounts config: %w", err)
+	}
+
+	cfg.Rotate = rotate
+	if err := config.SaveAccountsConfig(accountsPath, cfg); err != nil {
+		return fmt.Errorf("saving accounts config: %w", err)
+	}
+
+	fmt.Printf("Account rotation %s\n", args[0])
+	if rotate && len(cfg.Accounts) < 2 {
+		fmt.Println(style.Dim.Render("Add another account with 'gt account add' for rotation to take effect"))
+	}
 	return nil
 }
 
@@ -459,6 +628,9 @@ func init() {
 
 	accountAddCmd.Flags().StringVar(&accountEmail, "email", "", "Account email address")
 	accountAddCmd.Flags().StringVar(&accountDescription, "desc", "", "Account description")
+	accountAddCmd.Flags().Int64Var(&accountWeeklyLimit, "weekly-limit", 0, "Weekly token budget, for ranking accounts by quota left")
+
+	accountStatusCmd.Flags().BoolVar(&accountJSON, "json", false, "Output per-account usage as JSON")
 
 	// Add subcommands
 	accountCmd.AddCommand(accountListCmd)
@@ -466,6 +638,7 @@ func init() {
 	accountCmd.AddCommand(accountDefaultCmd)
 	accountCmd.AddCommand(accountStatusCmd)
 	accountCmd.AddCommand(accountSwitchCmd)
+	accountCmd.AddCommand(accountRotateCmd)
 
 	rootCmd.AddCommand(accountCmd)
 }
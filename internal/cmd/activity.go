@@ -32,6 +32,8 @@ var activityCmd = &cobra.Command{
 	Long: `Emit and view activity events for the Gas Town activity feed.
 
 Events are written to ~/gt/.events.jsonl and can be viewed with 'gt feed'.
+The log is rotated daily (or at 16 MiB) into gzip segments under
+~/gt/.events/, with an index.json used to skip segments when querying.
 
 Subcommands:
   emit    Emit an activity event`,
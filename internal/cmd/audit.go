@@ -403,36 +403,21 @@ func formatTownlogSummary(e townlog.Event) string {
 func collectFeedEvents(townRoot, actor string, since time.Time) ([]AuditEntry, error) {
 	var entries []AuditEntry
 
-	eventsPath := filepath.Join(townRoot, events.EventsFile)
-	file, err := os.Open(eventsPath)
+	// Since is applied by the query (and its segment index); actor matching
+	// is fuzzy, so it stays here.
+	all, err := events.Query(townRoot, events.Filter{Since: since})
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil // No events file yet
-		}
 		return nil, err
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		var e events.Event
-		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
-			continue // Skip malformed lines
-		}
 
+	for _, e := range all {
 		// Apply actor filter
 		if actor != "" && !matchesActor(e.Actor, actor) {
 			continue
 		}
 
-		// Parse timestamp
 		ts, _ := time.Parse(time.RFC3339, e.Timestamp)
 
-		// Apply since filter
-		if !since.IsZero() && ts.Before(since) {
-			continue
-		}
-
 		entries = append(entries, AuditEntry{
 			Timestamp: ts,
 			Source:    "events",
@@ -28,7 +28,9 @@ Checkpoint data includes:
 - Git branch and last commit
 - Timestamp
 
-Checkpoints are stored in .polecat-checkpoint.json in the polecat directory.`,
+Checkpoints are stored in .polecat-checkpoint.json in the polecat directory.
+The daemon also archives checkpoints of working polecats, with their
+uncommitted changes, outside the worktree: see gt checkpoint restore.`,
 }
 
 var checkpointWriteCmd = &cobra.Command{
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/account"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/checkpoint"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// Checkpoint restore flags
+var (
+	checkpointRestoreTo      string
+	checkpointRestoreNoStart bool
+	checkpointRestoreForce   bool
+)
+
+var checkpointRestoreCmd = &cobra.Command{
+	Use:   "restore <rig>/<polecat>",
+	Short: "Restore a crashed polecat's work to a new polecat",
+	Long: `Restore a polecat's last checkpoint to a new polecat.
+
+While a polecat works, the daemon archives a checkpoint of it every
+heartbeat interval (and once more when its session is found dead) in
+<rig>/.runtime/checkpoints/<polecat>/: the branch and last commit, the
+hooked bead and molecule step, and a WIP patch of its uncommitted changes.
+
+When the daemon can't restart a crashed polecat (its worktree is broken,
+its branch is wedged), restore moves the work to a new polecat:
+
+  1. Creates the new polecat's worktree at the checkpoint's last commit
+  2. Applies the WIP patch, leaving the changes uncommitted
+  3. Hooks the bead to the new polecat and clears the old one's hook
+  4. Writes the checkpoint into the new worktree, so gt prime shows the
+     new session what the old one was doing
+  5. Starts the new session (unless --no-start)
+
+Without an archived checkpoint, the old worktree is checkpointed now if it
+still exists. The old polecat is left alone; nuke it once the new one is
+running.
+
+Examples:
+  gt checkpoint restore gastown/Toast --to Nux
+  gt checkpoint restore gastown/Toast              # Allocate a name
+  gt checkpoint restore gastown/Toast --to Nux --no-start`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCheckpointRestore,
+}
+
+func init() {
+	checkpointRestoreCmd.Flags().StringVar(&checkpointRestoreTo, "to", "",
+		"Name of the new polecat (default: allocate one)")
+	checkpointRestoreCmd.Flags().BoolVar(&checkpointRestoreNoStart, "no-start", false,
+		"Create the new polecat without starting its session")
+	checkpointRestoreCmd.Flags().BoolVarP(&checkpointRestoreForce, "force", "f", false,
+		"Restore even if the old polecat's session is still running")
+
+	checkpointCmd.AddCommand(checkpointRestoreCmd)
+}
+
+func runCheckpointRestore(cmd *cobra.Command, args []string) error {
+	rigName, oldName, err := parseAddress(args[0])
+	if err != nil {
+		return err
+	}
+	mgr, r, err := getPolecatManager(rigName)
+	if err != nil {
+		return err
+	}
+	townRoot := filepath.Dir(r.Path)
+	t := tmux.NewTmux()
+	sessMgr := polecat.NewSessionManager(t, r)
+
+	if running, _ := sessMgr.IsRunning(oldName); running && !checkpointRestoreForce {
+		return fmt.Errorf("%s/%s is still running; use --force to restore anyway", rigName, oldName)
+	}
+
+	cp, patch, err := checkpoint.LoadArchive(r.Path, oldName)
+	if err != nil {
+		return err
+	}
+	if cp == nil {
+		old, getErr := mgr.Get(oldName)
+		if getErr != nil {
+			return fmt.Errorf("no checkpoint archived for %s/%s and no worktree to checkpoint", rigName, oldName)
+		}
+		if cp, patch, err = checkpoint.Snapshot(old.ClonePath); err != nil {
+			return fmt.Errorf("checkpointing %s/%s: %w", rigName, oldName, err)
+		}
+		if cp.HookedBead == "" {
+			cp.HookedBead = old.Issue
+		}
+	}
+	fmt.Printf("Checkpoint of %s/%s from %s ago: %s\n",
+		rigName, oldName, cp.Age().Round(time.Minute), cp.Summary())
+
+	newName := checkpointRestoreTo
+	if newName == "" {
+		if newName, err = mgr.AllocateName(); err != nil {
+			return fmt.Errorf("allocating polecat name: %w", err)
+		}
+	}
+	if newName == oldName {
+		return fmt.Errorf("--to must name a different polecat")
+	}
+
+	// 1. Worktree at the checkpoint's last commit
+	fmt.Printf("Creating polecat %s...\n", newName)
+	p, err := mgr.AddWithOptions(newName, polecat.AddOptions{
+		HookBead:   cp.HookedBead,
+		StartPoint: cp.LastCommit,
+	})
+	if err != nil {
+		return fmt.Errorf("creating polecat: %w", err)
+	}
+
+	// 2. Uncommitted work. A patch that no longer applies is kept in the
+	// polecat's home directory for the new session to apply by hand.
+	notes := fmt.Sprintf("Restored from %s/%s (checkpoint of %s).",
+		rigName, oldName, cp.Timestamp.Local().Format("2006-01-02 15:04"))
+	if len(patch) > 0 {
+		if err := checkpoint.ApplyPatch(p.ClonePath, patch); err != nil {
+			patchPath := filepath.Join(filepath.Dir(p.ClonePath), checkpoint.PatchFilename)
+			_ = os.WriteFile(patchPath, patch, 0600)
+			style.PrintWarning("WIP patch did not apply (%v); saved to %s", err, patchPath)
+			notes += fmt.Sprintf(" The previous session's uncommitted changes did not apply cleanly: review and apply %s.", patchPath)
+		} else {
+			fmt.Printf("%s Applied WIP patch (%d modified files)\n", style.Bold.Render("✓"), len(cp.ModifiedFiles))
+		}
+	}
+
+	// 3. Move the hook
+	if cp.HookedBead != "" {
+		newAgent := fmt.Sprintf("%s/polecats/%s", rigName, newName)
+		hookCmd := exec.Command("bd", "--no-daemon", "update", cp.HookedBead, "--status=hooked", "--assignee="+newAgent)
+		hookCmd.Dir = beads.ResolveHookDir(townRoot, cp.HookedBead, p.ClonePath)
+		hookCmd.Stderr = os.Stderr
+		if err := hookCmd.Run(); err != nil {
+			style.PrintWarning("could not hook %s to %s: %v", cp.HookedBead, newAgent, err)
+		} else {
+			fmt.Printf("%s Hooked %s to %s\n", style.Bold.Render("✓"), cp.HookedBead, newAgent)
+		}
+		if err := beads.New(r.Path).ClearHookBead(polecatBeadIDForRig(r, rigName, oldName)); err != nil {
+			style.PrintWarning("could not clear %s/%s's hook: %v", rigName, oldName, err)
+		}
+	}
+
+	// 4. Checkpoint for gt prime
+	restored := *cp
+	restored.Branch = p.Branch
+	restored.Timestamp = time.Now()
+	restored.SessionID = ""
+	restored.HasPatch = false
+	restored.RestoredFrom = rigName + "/" + oldName
+	if restored.Notes != "" {
+		notes = restored.Notes + "\n" + notes
+	}
+	restored.Notes = notes
+	if err := checkpoint.Write(p.ClonePath, &restored); err != nil {
+		style.PrintWarning("could not write checkpoint for %s: %v", newName, err)
+	}
+
+	// 5. Session
+	if !checkpointRestoreNoStart {
+		claudeConfigDir, accountHandle, err := account.Resolve(townRoot, "")
+		if err != nil {
+			return fmt.Errorf("resolving account: %w", err)
+		}
+		fmt.Printf("Starting session for %s/%s...\n", rigName, newName)
+		if err := sessMgr.Start(newName, polecat.SessionStartOptions{RuntimeConfigDir: claudeConfigDir}); err != nil {
+			return fmt.Errorf("starting session: %w", err)
+		}
+		_ = account.RecordSpawn(townRoot, accountHandle, sessMgr.SessionName(newName))
+	}
+
+	fmt.Printf("%s Restored %s/%s to %s/%s on %s\n",
+		style.Bold.Render("✓"), rigName, oldName, rigName, newName, p.Branch)
+	fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("When %s is running: gt polecat nuke %s/%s --force", newName, rigName, oldName)))
+	return nil
+}
@@ -307,6 +307,9 @@ func displayAgentConfig(name string, runtime *config.RuntimeConfig, preset *conf
 			fmt.Printf("Resume Style:  %s (%s)\n", preset.ResumeStyle, preset.ResumeFlag)
 		}
 		fmt.Printf("Supports Hooks: %v\n", preset.SupportsHooks)
+		if preset.Adapter != "" {
+			fmt.Printf("Adapter:       %s\n", preset.Adapter)
+		}
 	}
 }
 
@@ -17,6 +17,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/costs"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tui/convoy"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -64,6 +65,7 @@ var (
 	convoyMolecule     string
 	convoyNotify       string
 	convoyOwner        string
+	convoyBudget       string
 	convoyStatusJSON   bool
 	convoyListJSON     bool
 	convoyListStatus   string
@@ -127,12 +129,18 @@ The --owner flag specifies who requested the convoy (receives completion
 notification by default). If not specified, defaults to created_by.
 The --notify flag adds additional subscribers beyond the owner.
 
+The --budget flag caps what the convoy's work may cost over its lifetime,
+as recorded by 'gt costs record'. Patrols warn at 80% and escalate; at the
+cap, gt sling stops spawning polecats for beads the convoy tracks. Quote
+the amount so the shell does not expand it ('$40'), or leave off the $.
+
 Examples:
   gt convoy create "Deploy v2.0" gt-abc bd-xyz
   gt convoy create "Release prep" gt-abc --notify           # defaults to mayor/
   gt convoy create "Release prep" gt-abc --notify ops/      # notify ops/
   gt convoy create "Feature rollout" gt-a gt-b --owner mayor/ --notify ops/
-  gt convoy create "Feature rollout" gt-a gt-b gt-c --molecule mol-release`,
+  gt convoy create "Feature rollout" gt-a gt-b gt-c --molecule mol-release
+  gt convoy create "Auth rewrite" gt-a gt-b --budget '$40'`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runConvoyCreate,
 }
@@ -184,6 +192,9 @@ var convoyCheckCmd = &cobra.Command{
 This handles cross-rig convoy completion: convoys in town beads tracking issues
 in rig beads won't auto-close via bd close alone. This command bridges that gap.
 
+Convoys created by 'gt formula run' are also advanced: steps whose needs have
+all closed are slung to the convoy's rig.
+
 Can be run manually or by deacon patrol to ensure convoys close promptly.`,
 	RunE: runConvoyCheck,
 }
@@ -235,6 +246,7 @@ func init() {
 	convoyCreateCmd.Flags().StringVar(&convoyOwner, "owner", "", "Owner who requested convoy (gets completion notification)")
 	convoyCreateCmd.Flags().StringVar(&convoyNotify, "notify", "", "Additional address to notify on completion (default: mayor/ if flag used without value)")
 	convoyCreateCmd.Flags().Lookup("notify").NoOptDefVal = "mayor/"
+	convoyCreateCmd.Flags().StringVar(&convoyBudget, "budget", "", "Cost cap for the convoy's work in USD (e.g. '$40')")
 
 	// Status flags
 	convoyStatusCmd.Flags().BoolVar(&convoyStatusJSON, "json", false, "Output as JSON")
@@ -292,6 +304,14 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	var budget float64
+	if convoyBudget != "" {
+		var err error
+		if budget, err = costs.ParseAmount(convoyBudget); err != nil {
+			return err
+		}
+	}
+
 	townBeads, err := getTownBeadsDir()
 	if err != nil {
 		return err
@@ -308,6 +328,9 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 	if convoyMolecule != "" {
 		description += fmt.Sprintf("\nMolecule: %s", convoyMolecule)
 	}
+	if budget > 0 {
+		description += fmt.Sprintf("\nBudget: $%.2f", budget)
+	}
 
 	// Generate convoy ID with cv- prefix
 	convoyID := fmt.Sprintf("hq-cv-%s", generateShortID())
@@ -379,6 +402,9 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 	if convoyMolecule != "" {
 		fmt.Printf("  Molecule: %s\n", convoyMolecule)
 	}
+	if budget > 0 {
+		fmt.Printf("  Budget:   $%.2f\n", budget)
+	}
 
 	fmt.Printf("\n  %s\n", style.Dim.Render("Convoy auto-closes when all tracked issues complete"))
 
@@ -788,8 +814,9 @@ func checkAndCloseCompletedConvoys(townBeads string) ([]struct{ ID, Title string
 	}
 
 	var convoys []struct {
-		ID    string `json:"id"`
-		Title string `json:"title"`
+		ID          string `json:"id"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
 	}
 	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
 		return nil, fmt.Errorf("parsing convoy list: %w", err)
@@ -797,6 +824,11 @@ func checkAndCloseCompletedConvoys(townBeads string) ([]struct{ ID, Title string
 
 	// Check each convoy
 	for _, convoy := range convoys {
+		// Formula convoys sling steps whose needs have closed
+		if dispatched := advanceFormulaConvoy(townBeads, convoy.ID, convoy.Description); len(dispatched) > 0 {
+			fmt.Printf("%s Dispatched %d step(s) of %s: %s\n", style.Bold.Render("→"), len(dispatched), convoy.ID, strings.Join(dispatched, ", "))
+		}
+
 		tracked := getTrackedIssues(townBeads, convoy.ID)
 		if len(tracked) == 0 {
 			continue // No tracked issues, nothing to check
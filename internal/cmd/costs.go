@@ -2,19 +2,24 @@
 package cmd
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/costs"
+	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -22,15 +27,21 @@ import (
 
 var (
 	costsJSON    bool
+	costsCSV     bool
 	costsToday   bool
 	costsWeek    bool
+	costsSince   string
+	costsBy      string
 	costsByRole  bool
 	costsByRig   bool
+	costsLive    bool
 	costsVerbose bool
 
 	// Record subcommand flags
-	recordSession  string
-	recordWorkItem string
+	recordSession    string
+	recordWorkItem   string
+	recordTranscript string
+	recordRuntime    string
 
 	// Digest subcommand flags
 	digestYesterday bool
@@ -44,52 +55,61 @@ var (
 var costsCmd = &cobra.Command{
 	Use:     "costs",
 	GroupID: GroupDiag,
-	Short:   "Show costs for running Claude sessions [DISABLED]",
-	Long: `Display costs for Claude Code sessions in Gas Town.
+	Short:   "Show token usage and costs by rig, role, polecat, convoy or bead",
+	Long: `Display the Gas Town cost ledger.
 
-⚠️  COST TRACKING IS CURRENTLY DISABLED
+Every agent's Stop hook runs 'gt costs record', which reads the runtime's
+own transcript (Claude Code or Codex), prices the new input, output and
+cache tokens per model, and stores them as a session.ended wisp. Deacon
+patrol rolls each day's wisps into a permanent "Cost Report" digest bead.
+'gt costs' reads both and aggregates them.
 
-Claude Code displays costs in the TUI status bar, which cannot be captured
-via tmux. All sessions will show $0.00 until Claude Code exposes cost data
-through an API or environment variable.
+--since takes a duration (24h, 7d, 2w), a date (2026-01-07) or an RFC3339
+time; the default is the start of today. --by groups by one or more of:
+rig, role, polecat, convoy, bead, model, agent, day, session. Convoy costs
+are attributed through the beads each session worked on.
 
-What we need from Claude Code:
-  - Stop hook env var (e.g., $CLAUDE_SESSION_COST)
-  - Or queryable file/API endpoint
-
-See: GH#24, gt-7awfj
-
-The infrastructure remains in place and will work once cost data is available.
+Prices are list prices per million tokens; override or add models in
+settings/pricing.json at the town root. Models without a price are
+counted in tokens and flagged as unpriced.
 
 Examples:
-  gt costs              # Live costs from running sessions
-  gt costs --today      # Today's costs from wisps (not yet digested)
-  gt costs --week       # This week's costs from digest beads + today's wisps
-  gt costs --by-role    # Breakdown by role (polecat, witness, etc.)
-  gt costs --by-rig     # Breakdown by rig
-  gt costs --json       # Output as JSON
+  gt costs                          # Today's total
+  gt costs --since 7d --by convoy   # What each convoy cost this week
+  gt costs --by rig,role            # Today's costs per rig and role
+  gt costs --by polecat --since 24h # Per polecat, last 24 hours
+  gt costs --by model --json        # Token and cost totals per model as JSON
+  gt costs --since 2026-01-01 --by bead --csv > beads.csv
+  gt costs --live                   # Running sessions, from their transcripts
 
 Subcommands:
-  gt costs record       # Record session cost as ephemeral wisp (Stop hook)
+  gt costs record       # Record session usage as ephemeral wisp (Stop hook)
   gt costs digest       # Aggregate wisps into daily digest bead (Deacon patrol)`,
 	RunE: runCosts,
 }
 
 var costsRecordCmd = &cobra.Command{
 	Use:   "record",
-	Short: "Record session cost as an ephemeral wisp (called by Stop hook)",
-	Long: `Record the final cost of a session as an ephemeral wisp.
+	Short: "Record session token usage as an ephemeral wisp (called by Stop hook)",
+	Long: `Record the token usage of a session as an ephemeral wisp.
+
+This command is intended to be called from a Claude Code Stop hook, which
+passes the session's transcript path as JSON on stdin. The transcript is
+parsed for per-model input, output and cache tokens; only usage added
+since the previous record for the session is stored, so the hook can run
+after every turn. Without hook input the transcript is located from the
+working directory (--runtime selects claude or codex).
 
-This command is intended to be called from a Claude Code Stop hook.
-It captures the final cost from the tmux session and creates an ephemeral
-event that is NOT exported to JSONL (avoiding log-in-database pollution).
+The wisp is NOT exported to JSONL (avoiding log-in-database pollution) and
+is attributed to the bead on the agent's hook unless --work-item is given.
 
 Session cost wisps are aggregated daily by 'gt costs digest' into a single
 permanent "Cost Report YYYY-MM-DD" bead for audit purposes.
 
 Examples:
-  gt costs record --session gt-gastown-toast
-  gt costs record --session gt-gastown-toast --work-item gt-abc123`,
+  gt costs record                                  # From a Stop hook
+  gt costs record --session gt-gastown-toast --work-item gt-abc123
+  gt costs record --runtime codex --transcript ~/.codex/sessions/2026/01/07/rollout-x.jsonl`,
 	RunE: runCostsRecord,
 }
 
@@ -136,16 +156,22 @@ Examples:
 func init() {
 	rootCmd.AddCommand(costsCmd)
 	costsCmd.Flags().BoolVar(&costsJSON, "json", false, "Output as JSON")
-	costsCmd.Flags().BoolVar(&costsToday, "today", false, "Show today's total from session events")
-	costsCmd.Flags().BoolVar(&costsWeek, "week", false, "Show this week's total from session events")
-	costsCmd.Flags().BoolVar(&costsByRole, "by-role", false, "Show breakdown by role")
-	costsCmd.Flags().BoolVar(&costsByRig, "by-rig", false, "Show breakdown by rig")
+	costsCmd.Flags().BoolVar(&costsCSV, "csv", false, "Output as CSV")
+	costsCmd.Flags().StringVar(&costsSince, "since", "", "Start of the period: duration (24h, 7d), date (YYYY-MM-DD) or RFC3339 (default: today)")
+	costsCmd.Flags().StringVar(&costsBy, "by", "", "Group by rig, role, polecat, convoy, bead, model, agent, day or session (comma-separated)")
+	costsCmd.Flags().BoolVar(&costsToday, "today", false, "Show today's costs (same as --since today)")
+	costsCmd.Flags().BoolVar(&costsWeek, "week", false, "Show the last 7 days (same as --since 7d)")
+	costsCmd.Flags().BoolVar(&costsByRole, "by-role", false, "Show breakdown by role (same as --by role)")
+	costsCmd.Flags().BoolVar(&costsByRig, "by-rig", false, "Show breakdown by rig (same as --by rig)")
+	costsCmd.Flags().BoolVar(&costsLive, "live", false, "Show running sessions, read from their transcripts")
 	costsCmd.Flags().BoolVarP(&costsVerbose, "verbose", "v", false, "Show debug output for failures")
 
 	// Add record subcommand
 	costsCmd.AddCommand(costsRecordCmd)
 	costsRecordCmd.Flags().StringVar(&recordSession, "session", "", "Tmux session name to record")
-	costsRecordCmd.Flags().StringVar(&recordWorkItem, "work-item", "", "Work item ID (bead) for attribution")
+	costsRecordCmd.Flags().StringVar(&recordWorkItem, "work-item", "", "Work item ID (bead) for attribution (default: hooked bead)")
+	costsRecordCmd.Flags().StringVar(&recordTranscript, "transcript", "", "Transcript to parse (default: from hook input or working directory)")
+	costsRecordCmd.Flags().StringVar(&recordRuntime, "runtime", costs.RuntimeClaude, "Transcript format: claude or codex")
 
 	// Add digest subcommand
 	costsCmd.AddCommand(costsDigestCmd)
@@ -158,190 +184,349 @@ func init() {
 	costsMigrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Preview what would be migrated without making changes")
 }
 
-// SessionCost represents cost info for a single session.
+// SessionCost represents cost info for a single running session.
 type SessionCost struct {
-	Session string  `json:"session"`
-	Role    string  `json:"role"`
-	Rig     string  `json:"rig,omitempty"`
-	Worker  string  `json:"worker,omitempty"`
-	Cost    float64 `json:"cost_usd"`
-	Running bool    `json:"running"`
+	Session string      `json:"session"`
+	Role    string      `json:"role"`
+	Rig     string      `json:"rig,omitempty"`
+	Worker  string      `json:"worker,omitempty"`
+	Cost    float64     `json:"cost_usd"`
+	Tokens  costs.Usage `json:"tokens"`
+	Running bool        `json:"running"`
 }
 
 // CostEntry is a ledger entry for historical cost tracking.
-type CostEntry struct {
-	SessionID string    `json:"session_id"`
-	Role      string    `json:"role"`
-	Rig       string    `json:"rig,omitempty"`
-	Worker    string    `json:"worker,omitempty"`
-	CostUSD   float64   `json:"cost_usd"`
-	StartedAt time.Time `json:"started_at"`
-	EndedAt   time.Time `json:"ended_at"`
-	WorkItem  string    `json:"work_item,omitempty"`
-}
+type CostEntry = costs.Entry
 
 // CostsOutput is the JSON output structure.
 type CostsOutput struct {
 	Sessions []SessionCost      `json:"sessions,omitempty"`
 	Total    float64            `json:"total_usd"`
+	Tokens   *costs.Usage       `json:"tokens,omitempty"`
 	ByRole   map[string]float64 `json:"by_role,omitempty"`
 	ByRig    map[string]float64 `json:"by_rig,omitempty"`
 	Period   string             `json:"period,omitempty"`
+	Since    *time.Time         `json:"since,omitempty"`
+	By       []string           `json:"by,omitempty"`
+	Rows     []costs.Row        `json:"rows,omitempty"`
+	Entries  int                `json:"entries,omitempty"`
 }
 
-// costRegex matches cost patterns like "$1.23" or "$12.34"
-var costRegex = regexp.MustCompile(`\$(\d+\.\d{2})`)
-
 func runCosts(cmd *cobra.Command, args []string) error {
-	// If querying ledger, use ledger functions
-	if costsToday || costsWeek || costsByRole || costsByRig {
-		return runCostsFromLedger()
+	if costsJSON && costsCSV {
+		return fmt.Errorf("--json and --csv are mutually exclusive")
 	}
-
-	// Default: show live costs from running sessions
-	return runLiveCosts()
+	if costsLive {
+		return runLiveCosts()
+	}
+	return runCostsFromLedger()
 }
 
+// runLiveCosts shows the running sessions' usage so far, read from their
+// transcripts. It includes usage not yet recorded by a Stop hook.
 func runLiveCosts() error {
-	// Warn that cost tracking is disabled
-	fmt.Fprintf(os.Stderr, "%s Cost tracking is disabled - Claude Code does not expose session costs.\n",
-		style.Warning.Render("⚠"))
-	fmt.Fprintf(os.Stderr, "   All sessions will show $0.00. See: GH#24, gt-7awfj\n\n")
-
 	t := tmux.NewTmux()
 
-	// Get all tmux sessions
 	sessions, err := t.ListSessions()
 	if err != nil {
 		return fmt.Errorf("listing sessions: %w", err)
 	}
 
-	var costs []SessionCost
+	townRoot, _ := workspace.FindFromCwd()
+	pricing, err := costs.LoadPricing(townRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v (using default prices)\n", style.Warning.Render("⚠"), err)
+	}
+
+	var live []SessionCost
 	var total float64
 
 	for _, session := range sessions {
-		// Only process Gas Town sessions (start with "gt-")
-		if !strings.HasPrefix(session, constants.SessionPrefix) {
+		// Only process Gas Town sessions
+		if !strings.HasPrefix(session, constants.SessionPrefix) && !strings.HasPrefix(session, constants.HQSessionPrefix) {
 			continue
 		}
 
-		// Parse session name to get role/rig/worker
 		role, rig, worker := parseSessionName(session)
-
-		// Capture pane content
-		content, err := t.CapturePaneAll(session)
-		if err != nil {
-			continue // Skip sessions we can't capture
-		}
-
-		// Extract cost from content
-		cost := extractCost(content)
-
-		// Check if an agent appears to be running
-		running := t.IsAgentRunning(session)
-
-		costs = append(costs, SessionCost{
+		sc := SessionCost{
 			Session: session,
 			Role:    role,
 			Rig:     rig,
 			Worker:  worker,
-			Cost:    cost,
-			Running: running,
-		})
-		total += cost
+			Running: t.IsAgentRunning(session),
+		}
+
+		if usage, err := sessionTranscriptUsage(t, session); err == nil {
+			for _, u := range usage.Usages() {
+				usd, _ := pricing.Cost(u)
+				sc.Cost += usd
+				sc.Tokens.InputTokens += u.InputTokens
+				sc.Tokens.OutputTokens += u.OutputTokens
+				sc.Tokens.CacheCreationTokens += u.CacheCreationTokens
+				sc.Tokens.CacheReadTokens += u.CacheReadTokens
+			}
+		} else if costsVerbose {
+			fmt.Fprintf(os.Stderr, "[costs] %s: %v\n", session, err)
+		}
+
+		live = append(live, sc)
+		total += sc.Cost
 	}
 
-	// Sort by session name
-	sort.Slice(costs, func(i, j int) bool {
-		return costs[i].Session < costs[j].Session
+	sort.Slice(live, func(i, j int) bool {
+		return live[i].Session < live[j].Session
 	})
 
 	if costsJSON {
 		return outputCostsJSON(CostsOutput{
-			Sessions: costs,
+			Sessions: live,
 			Total:    total,
 		})
 	}
 
-	return outputCostsHuman(costs, total)
+	return outputCostsHuman(live, total)
 }
 
-func runCostsFromLedger() error {
-	// Warn that cost tracking is disabled
-	fmt.Fprintf(os.Stderr, "%s Cost tracking is disabled - Claude Code does not expose session costs.\n",
-		style.Warning.Render("⚠"))
-	fmt.Fprintf(os.Stderr, "   Historical data may show $0.00 for all sessions. See: GH#24, gt-7awfj\n\n")
+// sessionTranscriptUsage parses the latest session of the agent running in
+// a tmux session, asking Claude Code's adapter first and then the other
+// agents' adapters that can read usage.
+func sessionTranscriptUsage(t *tmux.Tmux, session string) (*costs.SessionUsage, error) {
+	workDir, err := t.GetPaneWorkDir(session)
+	if err != nil {
+		return nil, err
+	}
+	agents := config.ListAgentPresets()
+	sort.Slice(agents, func(i, j int) bool {
+		if (agents[i] == costs.RuntimeClaude) != (agents[j] == costs.RuntimeClaude) {
+			return agents[i] == costs.RuntimeClaude
+		}
+		return agents[i] < agents[j]
+	})
+	lastErr := fmt.Errorf("no transcripts for %s", workDir)
+	for _, agent := range agents {
+		usage, err := runtime.For(agent).ParseUsage(workDir)
+		if err == nil {
+			return usage, nil
+		}
+		if !errors.Is(err, runtime.ErrUnsupported) {
+			lastErr = err
+		}
+	}
+	return nil, lastErr
+}
 
+func runCostsFromLedger() error {
 	now := time.Now()
-	var entries []CostEntry
-	var err error
 
-	if costsToday {
-		// For today: query ephemeral wisps (not yet digested)
-		// This gives real-time view of today's costs
-		entries, err = querySessionCostWisps(now)
-		if err != nil {
-			return fmt.Errorf("querying session cost wisps: %w", err)
+	since, period, err := costsPeriod(now)
+	if err != nil {
+		return err
+	}
+	dims, err := costsDimensions()
+	if err != nil {
+		return err
+	}
+
+	// Digests cover days already rolled up; wisps cover the rest
+	entries, err := queryDigestBeadsSince(since)
+	if err != nil {
+		return fmt.Errorf("querying digest beads: %w", err)
+	}
+	wisps, err := querySessionCostWispsSince(since)
+	if err != nil {
+		return fmt.Errorf("querying session cost wisps: %w", err)
+	}
+	entries = costs.Dedupe(append(entries, wisps...))
+
+	inPeriod := entries[:0]
+	for _, e := range entries {
+		if !e.EndedAt.Before(since) {
+			inPeriod = append(inPeriod, e)
 		}
-	} else if costsWeek {
-		// For week: query digest beads (costs.digest events)
-		// These are the aggregated daily reports
-		entries, err = queryDigestBeads(7)
-		if err != nil {
-			return fmt.Errorf("querying digest beads: %w", err)
+	}
+	entries = inPeriod
+
+	var convoyOf func(string) string
+	for _, d := range dims {
+		if d == costs.ByConvoy {
+			convoyOf = loadConvoyIndex()
 		}
+	}
 
-		// Also include today's wisps (not yet digested)
-		todayWisps, _ := querySessionCostWisps(now)
-		entries = append(entries, todayWisps...)
-	} else {
-		// No time filter: query both digests and legacy session.ended events
-		// (for backwards compatibility during migration)
-		entries = querySessionEvents()
+	output := CostsOutput{Period: period, Since: &since, By: dims, Entries: len(entries)}
+	var tokens costs.Usage
+	for _, row := range costs.Aggregate(entries, nil, nil) {
+		output.Total = row.CostUSD
+		tokens = row.Tokens
+	}
+	output.Tokens = &tokens
+	if len(dims) > 0 {
+		output.Rows = costs.Aggregate(entries, dims, convoyOf)
+	}
+
+	// Legacy JSON maps for the --by-role / --by-rig flags
+	if costsByRole || costsByRig {
+		for _, e := range entries {
+			if costsByRole {
+				if output.ByRole == nil {
+					output.ByRole = make(map[string]float64)
+				}
+				output.ByRole[e.Role] += e.CostUSD
+			}
+			if costsByRig && e.Rig != "" {
+				if output.ByRig == nil {
+					output.ByRig = make(map[string]float64)
+				}
+				output.ByRig[e.Rig] += e.CostUSD
+			}
+		}
+	}
+
+	switch {
+	case costsJSON:
+		return outputCostsJSON(output)
+	case costsCSV:
+		return outputCostsCSV(output)
 	}
 
 	if len(entries) == 0 {
-		fmt.Println(style.Dim.Render("No cost data found. Costs are recorded when sessions end."))
+		fmt.Println(style.Dim.Render("No cost data found. Costs are recorded by the Stop hook after each turn."))
 		return nil
 	}
+	return outputLedgerHuman(output)
+}
 
-	// Calculate totals
-	var total float64
-	byRole := make(map[string]float64)
-	byRig := make(map[string]float64)
-
-	for _, entry := range entries {
-		total += entry.CostUSD
-		byRole[entry.Role] += entry.CostUSD
-		if entry.Rig != "" {
-			byRig[entry.Rig] += entry.CostUSD
+// costsPeriod resolves --since (or --today/--week) to a start time and a
+// label for output.
+func costsPeriod(now time.Time) (time.Time, string, error) {
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case costsSince != "":
+		since, err := parseCostsSince(costsSince, now)
+		if err != nil {
+			return time.Time{}, "", err
 		}
+		return since, "since " + costsSince, nil
+	case costsWeek:
+		return startOfDay.AddDate(0, 0, -7), "this week", nil
+	default:
+		return startOfDay, "today", nil
 	}
+}
 
-	// Build output
-	output := CostsOutput{
-		Total: total,
+// parseCostsSince parses a --since value: "today", a duration with d/w
+// units allowed (36h, 7d, 2w), a date, or an RFC3339 time.
+func parseCostsSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "today" {
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
 	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if n := len(s); n > 1 && (s[n-1] == 'd' || s[n-1] == 'w') {
+		if count, err := strconv.Atoi(s[:n-1]); err == nil && count >= 0 {
+			days := count
+			if s[n-1] == 'w' {
+				days *= 7
+			}
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a duration (24h, 7d, 2w), a date (YYYY-MM-DD) or RFC3339", s)
+}
 
-	if costsByRole {
-		output.ByRole = byRole
+// costsDimensions resolves --by plus the legacy --by-role/--by-rig flags.
+func costsDimensions() ([]string, error) {
+	dims, err := costs.ParseDimensions(costsBy)
+	if err != nil {
+		return nil, err
 	}
-	if costsByRig {
-		output.ByRig = byRig
+	has := func(d string) bool {
+		for _, x := range dims {
+			if x == d {
+				return true
+			}
+		}
+		return false
 	}
+	if costsByRig && !has(costs.ByRig) {
+		dims = append(dims, costs.ByRig)
+	}
+	if costsByRole && !has(costs.ByRole) {
+		dims = append(dims, costs.ByRole)
+	}
+	return dims, nil
+}
 
-	// Set period label
-	if costsToday {
-		output.Period = "today"
-	} else if costsWeek {
-		output.Period = "this week"
+// loadConvoyIndex maps beads to the convoy tracking them, for --by convoy.
+// A bead tracked by several convoys is attributed to the first by ID.
+func loadConvoyIndex() func(string) string {
+	_, tracked := loadConvoyTracking()
+	return func(bead string) string {
+		if ids := tracked[bead]; len(ids) > 0 {
+			return ids[0]
+		}
+		return ""
 	}
+}
 
-	if costsJSON {
-		return outputCostsJSON(output)
+// loadConvoyTracking reads the town's convoys, sorted by ID, and the convoys
+// tracking each bead.
+func loadConvoyTracking() ([]*beads.Issue, map[string][]string) {
+	tracked := make(map[string][]string)
+	townBeads, err := getTownBeadsDir()
+	if err != nil {
+		return nil, tracked
+	}
+	b := beads.NewWithBeadsDir(filepath.Dir(townBeads), townBeads)
+	convoys, err := b.List(beads.ListOptions{IssueType: "convoy", Status: "all", Priority: -1})
+	if err != nil || len(convoys) == 0 {
+		if err != nil && costsVerbose {
+			fmt.Fprintf(os.Stderr, "[costs] reading convoys: %v\n", err)
+		}
+		return nil, tracked
 	}
+	sort.Slice(convoys, func(i, j int) bool { return convoys[i].ID < convoys[j].ID })
 
-	return outputLedgerHuman(output, entries)
+	// bd list leaves out dependencies; one show fetches them for all convoys
+	ids := make([]string, len(convoys))
+	for i, c := range convoys {
+		ids[i] = c.ID
+	}
+	details, err := b.ShowMultiple(ids)
+	if err != nil {
+		if costsVerbose {
+			fmt.Fprintf(os.Stderr, "[costs] reading convoy dependencies: %v\n", err)
+		}
+		return convoys, tracked
+	}
+	for _, convoy := range convoys {
+		issue := details[convoy.ID]
+		if issue == nil {
+			continue
+		}
+		for _, dep := range issue.Dependencies {
+			if dep.DependencyType != "tracks" {
+				continue
+			}
+			id := dep.ID
+			// Handle external reference format: external:rig:issue-id
+			if strings.HasPrefix(id, "external:") {
+				if parts := strings.SplitN(id, ":", 3); len(parts) == 3 {
+					id = parts[2]
+				}
+			}
+			tracked[id] = append(tracked[id], issue.ID)
+		}
+	}
+	return convoys, tracked
 }
 
 // SessionEvent represents a session.ended event from beads.
@@ -361,9 +546,61 @@ type SessionPayload struct {
 	CostUSD   float64 `json:"cost_usd"`
 	SessionID string  `json:"session_id"`
 	Role      string  `json:"role"`
-	Rig       string  `json:"rig"`
-	Worker    string  `json:"worker"`
+	Rig       string  `json:"rig,omitempty"`
+	Worker    string  `json:"worker,omitempty"`
+	StartedAt string  `json:"started_at,omitempty"`
 	EndedAt   string  `json:"ended_at"`
+
+	// Token accounting (absent from payloads recorded before it existed)
+	Agent          string            `json:"agent,omitempty"`
+	Runtime        string            `json:"runtime,omitempty"`
+	RuntimeSession string            `json:"runtime_session,omitempty"`
+	Models         []costs.ModelCost `json:"models,omitempty"`
+}
+
+// costEntryFromEvent converts a session.ended event into a ledger entry.
+// ok is false for other events and malformed payloads.
+func costEntryFromEvent(event SessionEvent) (entry CostEntry, ok bool) {
+	if event.EventKind != "session.ended" {
+		return entry, false
+	}
+
+	var payload SessionPayload
+	if event.Payload != "" {
+		if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+			if costsVerbose {
+				fmt.Fprintf(os.Stderr, "[costs] payload unmarshal failed for event %s: %v\n", event.ID, err)
+			}
+			return entry, false
+		}
+	}
+
+	// Parse ended_at from payload, fall back to created_at
+	endedAt := event.CreatedAt
+	if payload.EndedAt != "" {
+		if parsed, err := time.Parse(time.RFC3339, payload.EndedAt); err == nil {
+			endedAt = parsed
+		}
+	}
+	var startedAt time.Time
+	if payload.StartedAt != "" {
+		startedAt, _ = time.Parse(time.RFC3339, payload.StartedAt)
+	}
+
+	return CostEntry{
+		SessionID:      payload.SessionID,
+		Role:           payload.Role,
+		Rig:            payload.Rig,
+		Worker:         payload.Worker,
+		CostUSD:        payload.CostUSD,
+		StartedAt:      startedAt,
+		EndedAt:        endedAt,
+		WorkItem:       event.Target,
+		Agent:          payload.Agent,
+		Runtime:        payload.Runtime,
+		RuntimeSession: payload.RuntimeSession,
+		Models:         payload.Models,
+	}, true
 }
 
 // EventListItem represents an event from bd list (minimal fields).
@@ -475,43 +712,24 @@ func querySessionEventsFromLocation(location string) ([]CostEntry, error) {
 
 	var entries []CostEntry
 	for _, event := range events {
-		// Filter for session.ended events only
-		if event.EventKind != "session.ended" {
-			continue
-		}
-
-		// Parse payload
-		var payload SessionPayload
-		if event.Payload != "" {
-			if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
-				continue // Skip malformed payloads
-			}
+		if entry, ok := costEntryFromEvent(event); ok {
+			entries = append(entries, entry)
 		}
-
-		// Parse ended_at from payload, fall back to created_at
-		endedAt := event.CreatedAt
-		if payload.EndedAt != "" {
-			if parsed, err := time.Parse(time.RFC3339, payload.EndedAt); err == nil {
-				endedAt = parsed
-			}
-		}
-
-		entries = append(entries, CostEntry{
-			SessionID: payload.SessionID,
-			Role:      payload.Role,
-			Rig:       payload.Rig,
-			Worker:    payload.Worker,
-			CostUSD:   payload.CostUSD,
-			EndedAt:   endedAt,
-			WorkItem:  event.Target,
-		})
 	}
 
 	return entries, nil
 }
 
-// queryDigestBeads queries costs.digest events from the past N days and extracts session entries.
-func queryDigestBeads(days int) ([]CostEntry, error) {
+// costsTownRoot returns the town root for running bd against the town
+// beads, or "" to let bd discover the database from cwd.
+func costsTownRoot() string {
+	townRoot, _ := workspace.FindFromCwd()
+	return townRoot
+}
+
+// queryDigestBeadsSince queries costs.digest events for days on or after
+// since and extracts their session entries.
+func queryDigestBeadsSince(since time.Time) ([]CostEntry, error) {
 	// Get list of event IDs
 	listArgs := []string{
 		"list",
@@ -521,7 +739,9 @@ func queryDigestBeads(days int) ([]CostEntry, error) {
 		"--json",
 	}
 
+	townRoot := costsTownRoot()
 	listCmd := exec.Command("bd", listArgs...)
+	listCmd.Dir = townRoot
 	listOutput, err := listCmd.Output()
 	if err != nil {
 		return nil, nil
@@ -543,6 +763,7 @@ func queryDigestBeads(days int) ([]CostEntry, error) {
 	}
 
 	showCmd := exec.Command("bd", showArgs...)
+	showCmd.Dir = townRoot
 	showOutput, err := showCmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("showing events: %w", err)
@@ -553,9 +774,8 @@ func queryDigestBeads(days int) ([]CostEntry, error) {
 		return nil, fmt.Errorf("parsing event details: %w", err)
 	}
 
-	// Calculate date range
-	now := time.Now()
-	cutoff := now.AddDate(0, 0, -days)
+	// A digest covers a whole day; keep the days that overlap the period
+	cutoff := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC)
 
 	var entries []CostEntry
 	for _, event := range events {
@@ -635,33 +855,14 @@ func parseSessionName(session string) (role, rig, worker string) {
 	return constants.RolePolecat, rig, worker
 }
 
-// extractCost finds the most recent cost value in pane content.
-// Claude Code displays cost in the format "$X.XX" in the status area.
-func extractCost(content string) float64 {
-	matches := costRegex.FindAllStringSubmatch(content, -1)
-	if len(matches) == 0 {
-		return 0.0
-	}
-
-	// Get the last (most recent) match
-	lastMatch := matches[len(matches)-1]
-	if len(lastMatch) < 2 {
-		return 0.0
-	}
-
-	var cost float64
-	_, _ = fmt.Sscanf(lastMatch[1], "%f", &cost)
-	return cost
-}
-
 func outputCostsJSON(output CostsOutput) error {
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
 	return enc.Encode(output)
 }
 
-func outputCostsHuman(costs []SessionCost, total float64) error {
-	if len(costs) == 0 {
+func outputCostsHuman(sessions []SessionCost, total float64) error {
+	if len(sessions) == 0 {
 		fmt.Println(style.Dim.Render("No Gas Town sessions found"))
 		return nil
 	}
@@ -669,12 +870,12 @@ func outputCostsHuman(costs []SessionCost, total float64) error {
 	fmt.Printf("\n%s Live Session Costs\n\n", style.Bold.Render("💰"))
 
 	// Print table header
-	fmt.Printf("%-25s %-10s %-15s %10s %8s\n",
-		"Session", "Role", "Rig/Worker", "Cost", "Status")
-	fmt.Println(strings.Repeat("─", 75))
+	fmt.Printf("%-25s %-10s %-15s %10s %10s %8s\n",
+		"Session", "Role", "Rig/Worker", "Tokens", "Cost", "Status")
+	fmt.Println(strings.Repeat("─", 86))
 
 	// Print each session
-	for _, c := range costs {
+	for _, c := range sessions {
 		statusIcon := style.Success.Render("●")
 		if !c.Running {
 			statusIcon = style.Dim.Render("○")
@@ -689,22 +890,23 @@ func outputCostsHuman(costs []SessionCost, total float64) error {
 			}
 		}
 
-		fmt.Printf("%-25s %-10s %-15s %10s %8s\n",
+		fmt.Printf("%-25s %-10s %-15s %10s %10s %8s\n",
 			c.Session,
 			c.Role,
 			rigWorker,
+			formatTokenCount(c.Tokens.Total()),
 			fmt.Sprintf("$%.2f", c.Cost),
 			statusIcon)
 	}
 
 	// Print total
-	fmt.Println(strings.Repeat("─", 75))
+	fmt.Println(strings.Repeat("─", 86))
 	fmt.Printf("%s %s\n", style.Bold.Render("Total:"), fmt.Sprintf("$%.2f", total))
 
 	return nil
 }
 
-func outputLedgerHuman(output CostsOutput, entries []CostEntry) error {
+func outputLedgerHuman(output CostsOutput) error {
 	periodStr := ""
 	if output.Period != "" {
 		periodStr = fmt.Sprintf(" (%s)", output.Period)
@@ -713,33 +915,105 @@ func outputLedgerHuman(output CostsOutput, entries []CostEntry) error {
 	fmt.Printf("\n%s Cost Summary%s\n\n", style.Bold.Render("📊"), periodStr)
 
 	// Total
-	fmt.Printf("%s $%.2f\n", style.Bold.Render("Total:"), output.Total)
-
-	// By role breakdown
-	if output.ByRole != nil && len(output.ByRole) > 0 {
-		fmt.Printf("\n%s\n", style.Bold.Render("By Role:"))
-		for role, cost := range output.ByRole {
-			icon := constants.RoleEmoji(role)
-			fmt.Printf("  %s %-12s $%.2f\n", icon, role, cost)
+	fmt.Printf("%s $%.2f", style.Bold.Render("Total:"), output.Total)
+	if output.Tokens != nil {
+		fmt.Printf("  %s", style.Dim.Render(formatTokenBreakdown(*output.Tokens)))
+	}
+	fmt.Println()
+
+	if len(output.Rows) > 0 {
+		header := strings.Join(output.By, " / ")
+		width := len(header)
+		for _, row := range output.Rows {
+			if l := len(row.Label()); l > width {
+				width = l
+			}
+		}
+		fmt.Printf("\n%-*s %10s %10s %8s\n", width, header, "Tokens", "Cost", "Sessions")
+		fmt.Println(strings.Repeat("─", width+31))
+		for _, row := range output.Rows {
+			cost := fmt.Sprintf("$%.2f", row.CostUSD)
+			if row.Unpriced {
+				cost += "*"
+			}
+			fmt.Printf("%-*s %10s %10s %8d\n", width, row.Label(), formatTokenCount(row.Tokens.Total()), cost, row.Sessions)
+		}
+		for _, row := range output.Rows {
+			if row.Unpriced {
+				fmt.Printf("\n%s\n", style.Dim.Render("* includes models without a price; add them to "+costs.PricingFile))
+				break
+			}
 		}
 	}
 
-	// By rig breakdown
-	if output.ByRig != nil && len(output.ByRig) > 0 {
-		fmt.Printf("\n%s\n", style.Bold.Render("By Rig:"))
-		for rig, cost := range output.ByRig {
-			fmt.Printf("  %-15s $%.2f\n", rig, cost)
+	// Entry count
+	fmt.Printf("\n%s %d entries\n", style.Dim.Render("Entries:"), output.Entries)
+
+	return nil
+}
+
+// outputCostsCSV writes the ledger aggregation as CSV, one row per group
+// (or a single total row without --by).
+func outputCostsCSV(output CostsOutput) error {
+	w := csv.NewWriter(os.Stdout)
+	header := append([]string{}, output.By...)
+	if len(header) == 0 {
+		header = []string{"period"}
+	}
+	header = append(header, "cost_usd", "input_tokens", "output_tokens",
+		"cache_creation_tokens", "cache_read_tokens", "sessions", "entries", "unpriced")
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	rows := output.Rows
+	if len(output.By) == 0 {
+		total := costs.Row{Key: []string{output.Period}, CostUSD: output.Total, Entries: output.Entries}
+		if output.Tokens != nil {
+			total.Tokens = *output.Tokens
+		}
+		rows = []costs.Row{total}
+	}
+	for _, row := range rows {
+		record := append([]string{}, row.Key...)
+		record = append(record,
+			strconv.FormatFloat(row.CostUSD, 'f', 4, 64),
+			strconv.FormatInt(row.Tokens.InputTokens, 10),
+			strconv.FormatInt(row.Tokens.OutputTokens, 10),
+			strconv.FormatInt(row.Tokens.CacheCreationTokens, 10),
+			strconv.FormatInt(row.Tokens.CacheReadTokens, 10),
+			strconv.Itoa(row.Sessions),
+			strconv.Itoa(row.Entries),
+			strconv.FormatBool(row.Unpriced),
+		)
+		if err := w.Write(record); err != nil {
+			return err
 		}
 	}
+	w.Flush()
+	return w.Error()
+}
 
-	// Session count
-	fmt.Printf("\n%s %d sessions\n", style.Dim.Render("Entries:"), len(entries))
+// formatTokenCount abbreviates a token count (1234567 -> 1.2M).
+func formatTokenCount(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return strconv.FormatInt(n, 10)
+	}
+}
 
-	return nil
+func formatTokenBreakdown(u costs.Usage) string {
+	return fmt.Sprintf("%s in, %s out, %s cache write, %s cache read",
+		formatTokenCount(u.InputTokens), formatTokenCount(u.OutputTokens),
+		formatTokenCount(u.CacheCreationTokens), formatTokenCount(u.CacheReadTokens))
 }
 
-// runCostsRecord captures the final cost from a session and records it as a bead event.
-// This is called by the Claude Code Stop hook.
+// runCostsRecord records the token usage a session added since its last
+// record as a session.ended wisp. This is called by the Claude Code Stop hook.
 func runCostsRecord(cmd *cobra.Command, args []string) error {
 	// Get session from flag or try to detect from environment
 	session := recordSession
@@ -758,17 +1032,48 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--session flag required (or set GT_SESSION env var, or GT_RIG/GT_ROLE)")
 	}
 
-	t := tmux.NewTmux()
+	// Find town root so bd can find the .beads database.
+	// The stop hook may run from a role subdirectory (e.g., mayor/) that
+	// doesn't have its own .beads, so we need to run bd from town root.
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil {
+		return fmt.Errorf("finding town root: %w", err)
+	}
+	if townRoot == "" {
+		return fmt.Errorf("not in a Gas Town workspace")
+	}
 
-	// Capture pane content
-	content, err := t.CapturePaneAll(session)
+	// Locate the transcript: flag, then hook input, then working directory
+	transcript := recordTranscript
+	if transcript == "" {
+		if input := readStdinJSON(); input != nil {
+			transcript = input.TranscriptPath
+		}
+	}
+	cwd, _ := os.Getwd()
+	if transcript == "" {
+		transcript, err = costs.FindTranscript(recordRuntime, cwd)
+		if err != nil {
+			return fmt.Errorf("locating transcript: %w", err)
+		}
+	}
+
+	usage, err := costs.ParseFile(recordRuntime, transcript)
 	if err != nil {
-		// Session may already be gone - that's OK, we'll record with zero cost
-		content = ""
+		return fmt.Errorf("parsing transcript: %w", err)
+	}
+	delta, err := costs.RecordDelta(townRoot, usage)
+	if err != nil {
+		return err
+	}
+	if len(delta.Models) == 0 {
+		return nil // Nothing new since the last record
 	}
 
-	// Extract cost
-	cost := extractCost(content)
+	pricing, err := costs.LoadPricing(townRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v (using default prices)\n", err)
+	}
 
 	// Parse session name
 	role, rig, worker := parseSessionName(session)
@@ -776,24 +1081,35 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 	// Build agent path for actor field
 	agentPath := buildAgentPath(role, rig, worker)
 
+	// Attribute to the hooked bead unless told otherwise
+	workItem := recordWorkItem
+	if workItem == "" {
+		if roleInfo, err := GetRoleWithContext(cwd, townRoot); err == nil {
+			workItem = detectHookedBead(cwd, roleInfo)
+		}
+	}
+
+	entry := CostEntry{Agent: agentPath}
+	entry.PriceUsage(pricing, delta.Usages())
+
 	// Build event title
 	title := fmt.Sprintf("Session ended: %s", session)
-	if recordWorkItem != "" {
-		title = fmt.Sprintf("Session: %s completed %s", session, recordWorkItem)
+	if workItem != "" {
+		title = fmt.Sprintf("Session: %s worked on %s", session, workItem)
 	}
 
 	// Build payload JSON
-	payload := map[string]interface{}{
-		"cost_usd":   cost,
-		"session_id": session,
-		"role":       role,
-		"ended_at":   time.Now().Format(time.RFC3339),
-	}
-	if rig != "" {
-		payload["rig"] = rig
-	}
-	if worker != "" {
-		payload["worker"] = worker
+	payload := SessionPayload{
+		CostUSD:        entry.CostUSD,
+		SessionID:      session,
+		Role:           role,
+		Rig:            rig,
+		Worker:         worker,
+		EndedAt:        time.Now().Format(time.RFC3339),
+		Agent:          agentPath,
+		Runtime:        usage.Runtime,
+		RuntimeSession: usage.SessionID,
+		Models:         entry.Models,
 	}
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
@@ -816,26 +1132,15 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 		"--silent",
 	}
 
-	// Add work item as event target if specified
-	if recordWorkItem != "" {
-		bdArgs = append(bdArgs, "--event-target="+recordWorkItem)
+	// Add work item as event target
+	if workItem != "" {
+		bdArgs = append(bdArgs, "--event-target="+workItem)
 	}
 
 	// NOTE: We intentionally don't use --rig flag here because it causes
 	// event fields (event_kind, actor, payload) to not be stored properly.
 	// The bd command will auto-detect the correct rig from cwd.
 
-	// Find town root so bd can find the .beads database.
-	// The stop hook may run from a role subdirectory (e.g., mayor/) that
-	// doesn't have its own .beads, so we need to run bd from town root.
-	townRoot, err := workspace.FindFromCwd()
-	if err != nil {
-		return fmt.Errorf("finding town root: %w", err)
-	}
-	if townRoot == "" {
-		return fmt.Errorf("not in a Gas Town workspace")
-	}
-
 	// Execute bd create from town root
 	bdCmd := exec.Command("bd", bdArgs...)
 	bdCmd.Dir = townRoot
@@ -856,14 +1161,12 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 		fmt.Fprintf(os.Stderr, "warning: could not auto-close session cost wisp %s: %v\n", wispID, closeErr)
 	}
 
-	// Output confirmation (silent if cost is zero and no work item)
-	if cost > 0 || recordWorkItem != "" {
-		fmt.Printf("%s Recorded $%.2f for %s (wisp: %s)", style.Success.Render("✓"), cost, session, wispID)
-		if recordWorkItem != "" {
-			fmt.Printf(" (work: %s)", recordWorkItem)
-		}
-		fmt.Println()
+	fmt.Printf("%s Recorded $%.2f (%s tokens) for %s (wisp: %s)", style.Success.Render("✓"),
+		entry.CostUSD, formatTokenCount(entry.Tokens().Total()), session, wispID)
+	if workItem != "" {
+		fmt.Printf(" (work: %s)", workItem)
 	}
+	fmt.Println()
 
 	return nil
 }
@@ -974,6 +1277,8 @@ type CostDigest struct {
 	Sessions     []CostEntry        `json:"sessions"`
 	ByRole       map[string]float64 `json:"by_role"`
 	ByRig        map[string]float64 `json:"by_rig,omitempty"`
+	ByModel      map[string]float64 `json:"by_model,omitempty"`
+	Tokens       costs.Usage        `json:"tokens"`
 }
 
 // WispListOutput represents the JSON output from bd mol wisp list.
@@ -1020,22 +1325,34 @@ func runCostsDigest(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Build digest
+	// Build digest. Stop hooks record every turn, so fold each session's
+	// wisps into one entry per bead to keep the digest payload small.
 	digest := CostDigest{
 		Date:     dateStr,
-		Sessions: wisps,
+		Sessions: costs.Compact(wisps),
 		ByRole:   make(map[string]float64),
 		ByRig:    make(map[string]float64),
+		ByModel:  make(map[string]float64),
 	}
 
-	for _, w := range wisps {
+	sessionIDs := make(map[string]bool)
+	for _, w := range digest.Sessions {
 		digest.TotalUSD += w.CostUSD
-		digest.SessionCount++
+		sessionIDs[w.SessionID] = true
 		digest.ByRole[w.Role] += w.CostUSD
 		if w.Rig != "" {
 			digest.ByRig[w.Rig] += w.CostUSD
 		}
+		for _, m := range w.Models {
+			digest.ByModel[m.Model] += m.CostUSD
+		}
+		tokens := w.Tokens()
+		digest.Tokens.InputTokens += tokens.InputTokens
+		digest.Tokens.OutputTokens += tokens.OutputTokens
+		digest.Tokens.CacheCreationTokens += tokens.CacheCreationTokens
+		digest.Tokens.CacheReadTokens += tokens.CacheReadTokens
 	}
+	digest.SessionCount = len(sessionIDs)
 
 	if digestDryRun {
 		fmt.Printf("%s [DRY RUN] Would create Cost Report %s:\n", style.Bold.Render("📊"), dateStr)
@@ -1066,6 +1383,11 @@ func runCostsDigest(cmd *cobra.Command, args []string) error {
 		fmt.Fprintf(os.Stderr, "warning: failed to delete some source wisps: %v\n", deleteErr)
 	}
 
+	// Forget delta state for sessions that have been idle for a week
+	if townRoot := costsTownRoot(); townRoot != "" {
+		costs.PruneState(townRoot, 7*24*time.Hour)
+	}
+
 	fmt.Printf("%s Created Cost Report %s (bead: %s)\n", style.Success.Render("✓"), dateStr, digestID)
 	fmt.Printf("  Total: $%.2f from %d sessions\n", digest.TotalUSD, digest.SessionCount)
 	if deletedCount > 0 {
@@ -1077,8 +1399,28 @@ func runCostsDigest(cmd *cobra.Command, args []string) error {
 
 // querySessionCostWisps queries ephemeral session.ended events for a target date.
 func querySessionCostWisps(targetDate time.Time) ([]CostEntry, error) {
+	targetDay := targetDate.Format("2006-01-02")
+	return querySessionCostWispsMatching(func(endedAt time.Time) bool {
+		return endedAt.Format("2006-01-02") == targetDay
+	})
+}
+
+// querySessionCostWispsSince queries ephemeral session.ended events that
+// ended at or after since.
+func querySessionCostWispsSince(since time.Time) ([]CostEntry, error) {
+	return querySessionCostWispsMatching(func(endedAt time.Time) bool {
+		return !endedAt.Before(since)
+	})
+}
+
+// querySessionCostWispsMatching queries ephemeral session.ended events whose
+// end time passes match.
+func querySessionCostWispsMatching(match func(endedAt time.Time) bool) ([]CostEntry, error) {
+	townRoot := costsTownRoot()
+
 	// List all wisps including closed ones
 	listCmd := exec.Command("bd", "mol", "wisp", "list", "--all", "--json")
+	listCmd.Dir = townRoot
 	listOutput, err := listCmd.Output()
 	if err != nil {
 		// No wisps database or command failed
@@ -1104,6 +1446,7 @@ func querySessionCostWisps(targetDate time.Time) ([]CostEntry, error) {
 	}
 
 	showCmd := exec.Command("bd", showArgs...)
+	showCmd.Dir = townRoot
 	showOutput, err := showCmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("showing wisps: %w", err)
@@ -1115,47 +1458,12 @@ func querySessionCostWisps(targetDate time.Time) ([]CostEntry, error) {
 	}
 
 	var sessionCostWisps []CostEntry
-	targetDay := targetDate.Format("2006-01-02")
-
 	for _, event := range events {
-		// Filter for session.ended events only
-		if event.EventKind != "session.ended" {
+		entry, ok := costEntryFromEvent(event)
+		if !ok || !match(entry.EndedAt) {
 			continue
 		}
-
-		// Parse payload
-		var payload SessionPayload
-		if event.Payload != "" {
-			if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
-				if costsVerbose {
-					fmt.Fprintf(os.Stderr, "[costs] payload unmarshal failed for event %s: %v\n", event.ID, err)
-				}
-				continue
-			}
-		}
-
-		// Parse ended_at and filter by target date
-		endedAt := event.CreatedAt
-		if payload.EndedAt != "" {
-			if parsed, err := time.Parse(time.RFC3339, payload.EndedAt); err == nil {
-				endedAt = parsed
-			}
-		}
-
-		// Check if this event is from the target date
-		if endedAt.Format("2006-01-02") != targetDay {
-			continue
-		}
-
-		sessionCostWisps = append(sessionCostWisps, CostEntry{
-			SessionID: payload.SessionID,
-			Role:      payload.Role,
-			Rig:       payload.Rig,
-			Worker:    payload.Worker,
-			CostUSD:   payload.CostUSD,
-			EndedAt:   endedAt,
-			WorkItem:  event.Target,
-		})
+		sessionCostWisps = append(sessionCostWisps, entry)
 	}
 
 	return sessionCostWisps, nil
@@ -1166,7 +1474,8 @@ func createCostDigestBead(digest CostDigest) (string, error) {
 	// Build description with aggregate data
 	var desc strings.Builder
 	desc.WriteString(fmt.Sprintf("Daily cost aggregate for %s.\n\n", digest.Date))
-	desc.WriteString(fmt.Sprintf("**Total:** $%.2f from %d sessions\n\n", digest.TotalUSD, digest.SessionCount))
+	desc.WriteString(fmt.Sprintf("**Total:** $%.2f from %d sessions\n", digest.TotalUSD, digest.SessionCount))
+	desc.WriteString(fmt.Sprintf("**Tokens:** %s\n\n", formatTokenBreakdown(digest.Tokens)))
 
 	if len(digest.ByRole) > 0 {
 		desc.WriteString("## By Role\n")
@@ -1195,6 +1504,19 @@ func createCostDigestBead(digest CostDigest) (string, error) {
 		desc.WriteString("\n")
 	}
 
+	if len(digest.ByModel) > 0 {
+		desc.WriteString("## By Model\n")
+		models := make([]string, 0, len(digest.ByModel))
+		for model := range digest.ByModel {
+			models = append(models, model)
+		}
+		sort.Strings(models)
+		for _, model := range models {
+			desc.WriteString(fmt.Sprintf("- %s: $%.2f\n", model, digest.ByModel[model]))
+		}
+		desc.WriteString("\n")
+	}
+
 	// Build payload JSON with full session details
 	payloadJSON, err := json.Marshal(digest)
 	if err != nil {
@@ -1213,7 +1535,9 @@ func createCostDigestBead(digest CostDigest) (string, error) {
 		"--silent",
 	}
 
+	townRoot := costsTownRoot()
 	bdCmd := exec.Command("bd", bdArgs...)
+	bdCmd.Dir = townRoot
 	output, err := bdCmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("creating digest bead: %w\nOutput: %s", err, string(output))
@@ -1223,6 +1547,7 @@ func createCostDigestBead(digest CostDigest) (string, error) {
 
 	// Auto-close the digest (it's an audit record, not work)
 	closeCmd := exec.Command("bd", "close", digestID, "--reason=daily cost digest")
+	closeCmd.Dir = townRoot
 	_ = closeCmd.Run() // Best effort
 
 	return digestID, nil
@@ -1230,8 +1555,11 @@ func createCostDigestBead(digest CostDigest) (string, error) {
 
 // deleteSessionCostWisps deletes ephemeral session.ended wisps for a target date.
 func deleteSessionCostWisps(targetDate time.Time) (int, error) {
+	townRoot := costsTownRoot()
+
 	// List all wisps
 	listCmd := exec.Command("bd", "mol", "wisp", "list", "--all", "--json")
+	listCmd.Dir = townRoot
 	listOutput, err := listCmd.Output()
 	if err != nil {
 		if costsVerbose {
@@ -1244,66 +1572,39 @@ func deleteSessionCostWisps(targetDate time.Time) (int, error) {
 	if err := json.Unmarshal(listOutput, &wispList); err != nil {
 		return 0, fmt.Errorf("parsing wisp list: %w", err)
 	}
+	if len(wispList.Wisps) == 0 {
+		return 0, nil
+	}
 
-	targetDay := targetDate.Format("2006-01-02")
-
-	// Collect all wisp IDs that match our criteria
-	var wispIDsToDelete []string
-
+	// Batch all wisp IDs into a single bd show call; Stop hooks record
+	// every turn, so there can be thousands of them
+	showArgs := []string{"show", "--json"}
 	for _, wisp := range wispList.Wisps {
-		// Get full wisp details to check if it's a session.ended event
-		showCmd := exec.Command("bd", "show", wisp.ID, "--json")
-		showOutput, err := showCmd.Output()
-		if err != nil {
-			if costsVerbose {
-				fmt.Fprintf(os.Stderr, "[costs] bd show failed for wisp %s: %v\n", wisp.ID, err)
-			}
-			continue
-		}
-
-		var events []SessionEvent
-		if err := json.Unmarshal(showOutput, &events); err != nil {
-			if costsVerbose {
-				fmt.Fprintf(os.Stderr, "[costs] JSON unmarshal failed for wisp %s: %v\n", wisp.ID, err)
-			}
-			continue
-		}
-
-		if len(events) == 0 {
-			continue
-		}
-
-		event := events[0]
-
-		// Only delete session.ended wisps
-		if event.EventKind != "session.ended" {
-			continue
-		}
+		showArgs = append(showArgs, wisp.ID)
+	}
+	showCmd := exec.Command("bd", showArgs...)
+	showCmd.Dir = townRoot
+	showOutput, err := showCmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("showing wisps: %w", err)
+	}
 
-		// Parse payload to get ended_at for date filtering
-		var payload SessionPayload
-		if event.Payload != "" {
-			if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
-				if costsVerbose {
-					fmt.Fprintf(os.Stderr, "[costs] payload unmarshal failed for wisp %s: %v\n", wisp.ID, err)
-				}
-				continue
-			}
-		}
+	var events []SessionEvent
+	if err := json.Unmarshal(showOutput, &events); err != nil {
+		return 0, fmt.Errorf("parsing wisp details: %w", err)
+	}
 
-		endedAt := event.CreatedAt
-		if payload.EndedAt != "" {
-			if parsed, err := time.Parse(time.RFC3339, payload.EndedAt); err == nil {
-				endedAt = parsed
-			}
-		}
+	targetDay := targetDate.Format("2006-01-02")
 
-		// Only delete wisps from the target date
-		if endedAt.Format("2006-01-02") != targetDay {
+	// Collect all wisp IDs that match our criteria
+	var wispIDsToDelete []string
+	for _, event := range events {
+		// Only delete session.ended wisps from the target date
+		entry, ok := costEntryFromEvent(event)
+		if !ok || entry.EndedAt.Format("2006-01-02") != targetDay {
 			continue
 		}
-
-		wispIDsToDelete = append(wispIDsToDelete, wisp.ID)
+		wispIDsToDelete = append(wispIDsToDelete, event.ID)
 	}
 
 	if len(wispIDsToDelete) == 0 {
@@ -1313,6 +1614,7 @@ func deleteSessionCostWisps(targetDate time.Time) (int, error) {
 	// Batch delete all wisps in a single subprocess call
 	burnArgs := append([]string{"mol", "burn", "--force"}, wispIDsToDelete...)
 	burnCmd := exec.Command("bd", burnArgs...)
+	burnCmd.Dir = townRoot
 	if burnErr := burnCmd.Run(); burnErr != nil {
 		return 0, fmt.Errorf("batch burn failed: %w", burnErr)
 	}
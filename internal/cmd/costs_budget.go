@@ -0,0 +1,363 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/costs"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	budgetRig     string
+	budgetEnforce bool
+	budgetJSON    bool
+)
+
+var costsBudgetCmd = &cobra.Command{
+	Use:   "budget",
+	Short: "Show convoy and rig budgets against recorded spend",
+	Long: `Show each budget, what has been spent against it, and its level.
+
+Budgets come from two places:
+  - Convoys created with 'gt convoy create --budget $40' (whole lifetime)
+  - A rig's settings/config.json "budget" block (per day, week or month):
+      "budget": {"limit_usd": 50, "period": "day", "warn_at": 0.8}
+
+A budget is "warn" once spend passes warn_at (default 80%) and "capped" at
+its limit. While capped, gt sling refuses to spawn polecats in the rig or
+for beads the convoy tracks (override with --ignore-budget).
+
+With --enforce (Witness and Deacon patrols), the levels are recorded and
+each budget that crossed into warn or capped since the last run is
+escalated through the normal escalation routes: warn as medium, capped as
+high. A budget escalates once per crossing, and again in its next period.
+
+Examples:
+  gt costs budget                          # All budgets
+  gt costs budget --rig gastown            # One rig's budget
+  gt costs budget --enforce                # Deacon patrol
+  gt costs budget --rig gastown --enforce  # Witness patrol`,
+	RunE: runCostsBudget,
+}
+
+func init() {
+	costsBudgetCmd.Flags().StringVar(&budgetRig, "rig", "", "Only the budget of this rig")
+	costsBudgetCmd.Flags().BoolVar(&budgetEnforce, "enforce", false, "Record levels and escalate budgets that crossed a threshold")
+	costsBudgetCmd.Flags().BoolVar(&budgetJSON, "json", false, "Output as JSON")
+	costsCmd.AddCommand(costsBudgetCmd)
+}
+
+// scopedBudget is a budget and the ledger entries it applies to.
+type scopedBudget struct {
+	costs.Budget
+	match   func(costs.Entry) bool
+	related string // Bead to attach escalations to (the convoy)
+}
+
+// townBudgets is every budget in the town, and the convoys tracking each
+// bead (for checking a bead against its convoys' budgets).
+type townBudgets struct {
+	budgets []scopedBudget
+	tracked map[string][]string
+}
+
+// loadTownBudgets reads rig budgets from rig settings and convoy budgets
+// from the Budget line of open convoys.
+func loadTownBudgets(townRoot string) *townBudgets {
+	tb := &townBudgets{}
+
+	rigsConfig, err := config.LoadRigsConfig(constants.MayorRigsPath(townRoot))
+	if err == nil {
+		names := make([]string, 0, len(rigsConfig.Rigs))
+		for name := range rigsConfig.Rigs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			settings, err := config.LoadRigSettings(config.RigSettingsPath(filepath.Join(townRoot, name)))
+			if err != nil || settings.Budget == nil {
+				continue
+			}
+			period := settings.Budget.Period
+			if period == "" {
+				period = costs.PeriodDay
+			}
+			rigName := name
+			tb.budgets = append(tb.budgets, scopedBudget{
+				Budget: costs.Budget{
+					Scope:    costs.RigScope(rigName),
+					LimitUSD: settings.Budget.LimitUSD,
+					WarnAt:   settings.Budget.WarnAt,
+					Period:   period,
+				},
+				match: func(e costs.Entry) bool { return e.Rig == rigName },
+			})
+		}
+	}
+
+	convoys, tracked := loadConvoyTracking()
+	tb.tracked = tracked
+	for _, convoy := range convoys {
+		if convoy.Status == "closed" {
+			continue
+		}
+		limit, ok := convoyBudgetFromDescription(convoy.Description)
+		if !ok {
+			continue
+		}
+		since, _ := time.Parse(time.RFC3339, convoy.CreatedAt)
+		convoyID := convoy.ID
+		tb.budgets = append(tb.budgets, scopedBudget{
+			Budget: costs.Budget{
+				Scope:    costs.ConvoyScope(convoyID),
+				LimitUSD: limit,
+				Period:   costs.PeriodTotal,
+				Since:    since,
+			},
+			match: func(e costs.Entry) bool {
+				for _, id := range tracked[e.WorkItem] {
+					if id == convoyID {
+						return true
+					}
+				}
+				return false
+			},
+			related: convoyID,
+		})
+	}
+	return tb
+}
+
+// convoyBudgetFromDescription reads the "Budget: $40.00" line written by
+// gt convoy create --budget.
+func convoyBudgetFromDescription(desc string) (float64, bool) {
+	for _, line := range strings.Split(desc, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), "Budget: "); ok {
+			if usd, err := costs.ParseAmount(v); err == nil {
+				return usd, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// evaluate checks the budgets selected by keep against the ledger. The
+// ledger is only read when some budget is selected.
+func (tb *townBudgets) evaluate(keep func(scope string) bool, now time.Time) ([]costs.BudgetStatus, map[string]scopedBudget, error) {
+	var selected []scopedBudget
+	earliest := now
+	for _, b := range tb.budgets {
+		if !keep(b.Scope) {
+			continue
+		}
+		selected = append(selected, b)
+		if start := b.Start(now); start.Before(earliest) {
+			earliest = start
+		}
+	}
+	if len(selected) == 0 {
+		return nil, nil, nil
+	}
+
+	entries, err := queryDigestBeadsSince(earliest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("querying digest beads: %w", err)
+	}
+	wisps, err := querySessionCostWispsSince(earliest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("querying session cost wisps: %w", err)
+	}
+	entries = costs.Dedupe(append(entries, wisps...))
+
+	statuses := make([]costs.BudgetStatus, 0, len(selected))
+	byScope := make(map[string]scopedBudget, len(selected))
+	for _, b := range selected {
+		statuses = append(statuses, b.Evaluate(entries, b.match, now))
+		byScope[b.Scope] = b
+	}
+	return statuses, byScope, nil
+}
+
+func runCostsBudget(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	keep := func(string) bool { return true }
+	if budgetRig != "" {
+		keep = func(scope string) bool { return scope == costs.RigScope(budgetRig) }
+	}
+
+	statuses, byScope, err := loadTownBudgets(townRoot).evaluate(keep, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if budgetEnforce {
+		if err := enforceBudgets(townRoot, statuses, byScope, budgetRig == ""); err != nil {
+			return err
+		}
+	}
+
+	if budgetJSON {
+		if statuses == nil {
+			statuses = []costs.BudgetStatus{}
+		}
+		out, _ := json.MarshalIndent(statuses, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(statuses) == 0 {
+		fmt.Println(style.Dim.Render("No budgets configured. Use 'gt convoy create --budget' or a rig's settings/config.json."))
+		return nil
+	}
+
+	fmt.Printf("%s\n\n", style.Bold.Render("Budgets"))
+	for _, s := range statuses {
+		level := s.Level
+		switch s.Level {
+		case costs.LevelCapped:
+			level = style.Error.Render("CAPPED")
+		case costs.LevelWarn:
+			level = style.Warning.Render("warn")
+		}
+		fmt.Printf("  %-28s $%8.2f / $%-8.2f %-6s %4.0f%%  %s\n",
+			s.Scope, s.SpentUSD, s.LimitUSD, budgetPeriodLabel(s.Period), s.Fraction()*100, level)
+	}
+	return nil
+}
+
+func budgetPeriodLabel(period string) string {
+	if period == costs.PeriodTotal {
+		return "total"
+	}
+	return "/" + period
+}
+
+// enforceBudgets records the evaluated levels and escalates each budget
+// that crossed a threshold since the last recorded run. replace drops
+// recorded scopes that were not evaluated (budgets that were removed).
+func enforceBudgets(townRoot string, statuses []costs.BudgetStatus, byScope map[string]scopedBudget, replace bool) error {
+	prev, err := costs.LoadBudgetState(townRoot)
+	if err != nil {
+		style.PrintWarning("%v (starting fresh)", err)
+	}
+
+	failed := make(map[string]bool)
+	if crossed := costs.Crossed(prev, statuses); len(crossed) > 0 {
+		escalationConfig, err := config.LoadOrCreateEscalationConfig(config.EscalationConfigPath(townRoot))
+		if err != nil {
+			return fmt.Errorf("loading escalation config: %w", err)
+		}
+		from := detectSender()
+		if from == "" {
+			from = "deacon"
+		}
+		for _, s := range crossed {
+			req := budgetEscalation(s, byScope[s.Scope].related)
+			req.From = from
+			sent, err := sendEscalation(townRoot, escalationConfig, req)
+			if err != nil {
+				style.PrintWarning("escalating %s: %v", s.Scope, err)
+				failed[s.Scope] = true
+				continue
+			}
+			fmt.Printf("%s Escalated %s budget (%s): %s\n", severityEmoji(req.Severity), s.Scope, s.Level, sent.Issue.ID)
+		}
+	}
+
+	next := make(map[string]costs.BudgetStatus, len(statuses))
+	if !replace {
+		for scope, s := range prev {
+			next[scope] = s
+		}
+	}
+	for _, s := range statuses {
+		if failed[s.Scope] {
+			// Keep the old level so the next patrol retries the escalation
+			if old, ok := prev[s.Scope]; ok {
+				next[s.Scope] = old
+			} else {
+				delete(next, s.Scope)
+			}
+			continue
+		}
+		next[s.Scope] = s
+	}
+	return costs.SaveBudgetState(townRoot, next)
+}
+
+// budgetEscalation describes a budget crossing as an escalation.
+func budgetEscalation(s costs.BudgetStatus, related string) escalationRequest {
+	period := "total"
+	if s.Period != costs.PeriodTotal {
+		period = "this " + s.Period
+	}
+	req := escalationRequest{
+		Severity:    config.SeverityMedium,
+		Description: fmt.Sprintf("Budget warning: %s has spent $%.2f of $%.2f (%s)", s.Scope, s.SpentUSD, s.LimitUSD, period),
+		Reason:      fmt.Sprintf("Spend passed %.0f%% of the budget. New polecats are still being spawned.", s.WarnAt*100),
+		Source:      "budget:" + s.Scope,
+		RelatedBead: related,
+	}
+	if s.Capped() {
+		req.Severity = config.SeverityHigh
+		req.Description = fmt.Sprintf("Budget exhausted: %s has spent $%.2f of $%.2f (%s)", s.Scope, s.SpentUSD, s.LimitUSD, period)
+		req.Reason = "gt sling will not spawn new polecats for it until the budget is raised or the period resets (override with --ignore-budget)."
+	}
+	return req
+}
+
+// spawnBudgets caches the town's budget levels for the life of the command,
+// so batch sling reads the ledger once.
+var spawnBudgets struct {
+	once     sync.Once
+	tb       *townBudgets
+	statuses map[string]costs.BudgetStatus
+	err      error
+}
+
+// checkSpawnBudget refuses a polecat spawn in rigName, or for beadID, when
+// the rig's budget or a budget of a convoy tracking the bead is capped.
+// Failing to read the ledger does not block work.
+func checkSpawnBudget(townRoot, rigName, beadID string) error {
+	spawnBudgets.once.Do(func() {
+		spawnBudgets.tb = loadTownBudgets(townRoot)
+		statuses, _, err := spawnBudgets.tb.evaluate(func(string) bool { return true }, time.Now())
+		spawnBudgets.err = err
+		spawnBudgets.statuses = make(map[string]costs.BudgetStatus, len(statuses))
+		for _, s := range statuses {
+			spawnBudgets.statuses[s.Scope] = s
+		}
+	})
+	if spawnBudgets.err != nil {
+		fmt.Fprintf(os.Stderr, "%s could not check budgets: %v\n", style.Dim.Render("Warning:"), spawnBudgets.err)
+		return nil
+	}
+
+	scopes := []string{costs.RigScope(rigName)}
+	if beadID != "" {
+		for _, convoyID := range spawnBudgets.tb.tracked[beadID] {
+			scopes = append(scopes, costs.ConvoyScope(convoyID))
+		}
+	}
+	for _, scope := range scopes {
+		if s, ok := spawnBudgets.statuses[scope]; ok && s.Capped() {
+			return fmt.Errorf("%s is over budget ($%.2f of $%.2f); raise the budget or use --ignore-budget", scope, s.SpentUSD, s.LimitUSD)
+		}
+	}
+	return nil
+}
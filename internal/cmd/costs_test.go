@@ -2,7 +2,12 @@ package cmd
 
 import (
 	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/costs"
 )
 
 func TestDeriveSessionName(t *testing.T) {
@@ -112,3 +117,82 @@ func TestDeriveSessionName(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCostsSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.Local)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"today", time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)},
+		{"24h", now.Add(-24 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"7d", now.AddDate(0, 0, -7)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)},
+		{"2026-03-01T08:00:00Z", time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseCostsSince(tt.in, now)
+		if err != nil {
+			t.Errorf("parseCostsSince(%q) error = %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseCostsSince(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"yesterday-ish", "-3d", "d"} {
+		if _, err := parseCostsSince(bad, now); err == nil {
+			t.Errorf("parseCostsSince(%q) should fail", bad)
+		}
+	}
+}
+
+func TestCostEntryFromEvent(t *testing.T) {
+	created := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	// Payload from before token accounting
+	legacy := SessionEvent{ID: "w-1", EventKind: "session.ended", CreatedAt: created, Target: "gt-1",
+		Payload: `{"cost_usd":1.5,"session_id":"gt-gastown-toast","role":"polecat","rig":"gastown","worker":"toast"}`}
+	entry, ok := costEntryFromEvent(legacy)
+	if !ok || entry.CostUSD != 1.5 || entry.WorkItem != "gt-1" || !entry.EndedAt.Equal(created) {
+		t.Errorf("legacy entry = %+v, %v", entry, ok)
+	}
+
+	current := SessionEvent{ID: "w-2", EventKind: "session.ended", CreatedAt: created,
+		Payload: `{"cost_usd":0.25,"session_id":"gt-gastown-toast","role":"polecat","ended_at":"2026-03-10T12:05:00Z",` +
+			`"runtime":"claude","runtime_session":"abc","models":[{"model":"claude-sonnet-4-5","input_tokens":10,"output_tokens":20,"cost_usd":0.25}]}`}
+	entry, ok = costEntryFromEvent(current)
+	if !ok || entry.RuntimeSession != "abc" || entry.Tokens().OutputTokens != 20 || entry.EndedAt.Minute() != 5 {
+		t.Errorf("entry = %+v, %v", entry, ok)
+	}
+
+	if _, ok := costEntryFromEvent(SessionEvent{EventKind: "costs.digest"}); ok {
+		t.Error("non-session events should be skipped")
+	}
+}
+
+func TestConvoyBudgetFromDescription(t *testing.T) {
+	desc := "Convoy tracking 2 issues\nOwner: mayor/\nBudget: $40.00"
+	if got, ok := convoyBudgetFromDescription(desc); !ok || got != 40 {
+		t.Errorf("convoyBudgetFromDescription = %v, %v; want 40, true", got, ok)
+	}
+	if _, ok := convoyBudgetFromDescription("Convoy tracking 1 issues\nOwner: mayor/"); ok {
+		t.Error("convoy without a Budget line should have no budget")
+	}
+}
+
+func TestBudgetEscalation(t *testing.T) {
+	st := costs.BudgetStatus{Scope: "convoy:hq-cv-abc", LimitUSD: 40, SpentUSD: 33, WarnAt: 0.8, Period: costs.PeriodTotal, Level: costs.LevelWarn}
+	req := budgetEscalation(st, "hq-cv-abc")
+	if req.Severity != config.SeverityMedium || req.RelatedBead != "hq-cv-abc" || req.Source != "budget:convoy:hq-cv-abc" {
+		t.Errorf("warn escalation = %+v", req)
+	}
+
+	st.SpentUSD, st.Level = 41, costs.LevelCapped
+	req = budgetEscalation(st, "hq-cv-abc")
+	if req.Severity != config.SeverityHigh || !strings.Contains(req.Description, "$41.00 of $40.00") {
+		t.Errorf("capped escalation = %+v", req)
+	}
+}
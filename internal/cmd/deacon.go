@@ -122,7 +122,7 @@ var deaconTriggerPendingCmd = &cobra.Command{
 
 ⚠️  BOOTSTRAP MODE ONLY - Uses regex detection (ZFC violation acceptable).
 
-This command uses WaitForRuntimeReady (regex) to detect when the runtime is ready.
+This command uses the agent's runtime adapter (pattern matching) to detect when it is ready.
 This is appropriate for daemon bootstrap when no AI is available.
 
 In steady-state, the Deacon should use AI-based observation instead:
@@ -418,7 +418,8 @@ func startDeaconSession(t *tmux.Tmux, sessionName, agentOverride string) error {
 	time.Sleep(constants.ShutdownNotifyDelay)
 
 	runtimeConfig := config.LoadRuntimeConfig("")
-	_ = runtime.RunStartupFallback(t, sessionName, "deacon", runtimeConfig)
+	adapter := runtime.ForRole("deacon", townRoot, "", agentOverride)
+	_ = runtime.RunStartupFallback(t, adapter, sessionName, "deacon", runtimeConfig)
 
 	// Inject startup nudge for predecessor discovery via /resume
 	if err := session.StartupNudge(t, sessionName, session.StartupNudgeConfig{
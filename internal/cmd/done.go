@@ -12,6 +12,7 @@ import (
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/protocol"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
@@ -274,6 +275,24 @@ func runDone(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("branch '%s' has 0 commits ahead of %s; nothing to merge\nMake and commit changes first, or use --status DEFERRED to exit without completing", branch, originDefault)
 		}
 
+		// Overlay files are local to this workspace and may hold rendered
+		// secrets: never push a branch that commits one, and remove them
+		// before the branch leaves the worktree
+		changed, err := g.ChangedFiles(originDefault, "HEAD")
+		if err != nil {
+			// Same fallback as the commits-ahead check
+			changed, err = g.ChangedFiles(defaultBranch, branch)
+			if err != nil {
+				return fmt.Errorf("checking branch for overlay files: %w", err)
+			}
+		}
+		if leaked := rig.CommittedOverlayFiles(cwd, changed); len(leaked) > 0 {
+			return fmt.Errorf("cannot complete: branch commits overlay files that may contain secrets: %s\nRemove them from the branch history (e.g. git rm --cached, then amend or rebase) and retry", strings.Join(leaked, ", "))
+		}
+		if err := rig.RemoveOverlay(cwd); err != nil {
+			style.PrintWarning("could not remove overlay files: %v", err)
+		}
+
 		// CRITICAL: Push branch BEFORE creating MR bead (hq-6dk53, hq-a4ksk)
 		// The MR bead triggers Refinery to process this branch. If the branch
 		// isn't pushed yet, Refinery finds nothing to merge. The worktree gets
@@ -404,9 +423,8 @@ func runDone(cmd *cobra.Command, args []string) error {
 	// Notify Witness about completion
 	// Use town-level beads for cross-agent mail
 	townRouter := mail.NewRouter(townRoot)
-	witnessAddr := fmt.Sprintf("%s/witness", rigName)
 
-	// Build notification body
+	// Build the body the dispatcher is notified with
 	var bodyLines []string
 	bodyLines = append(bodyLines, fmt.Sprintf("Exit: %s", exitType))
 	if issueID != "" {
@@ -420,12 +438,15 @@ func runDone(cmd *cobra.Command, args []string) error {
 	}
 	bodyLines = append(bodyLines, fmt.Sprintf("Branch: %s", branch))
 
-	doneNotification := &mail.Message{
-		To:      witnessAddr,
-		From:    sender,
-		Subject: fmt.Sprintf("POLECAT_DONE %s", polecatName),
-		Body:    strings.Join(bodyLines, "\n"),
-	}
+	doneNotification := protocol.NewPolecatDoneMessage(sender, protocol.PolecatDonePayload{
+		Polecat: polecatName,
+		Rig:     rigName,
+		Exit:    exitType,
+		Issue:   issueID,
+		MR:      mrID,
+		Gate:    doneGate,
+		Branch:  branch,
+	})
 
 	fmt.Printf("\nNotifying Witness...\n")
 	if err := townRouter.Send(doneNotification); err != nil {
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/escalation"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/style"
@@ -66,39 +68,99 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Create escalation bead
-	bd := beads.New(beads.ResolveBeadsDir(townRoot))
-	fields := &beads.EscalationFields{
+	sent, err := sendEscalation(townRoot, escalationConfig, escalationRequest{
 		Severity:    severity,
+		Description: description,
 		Reason:      escalateReason,
 		Source:      escalateSource,
-		EscalatedBy: agentID,
-		EscalatedAt: time.Now().Format(time.RFC3339),
 		RelatedBead: escalateRelatedBead,
+		From:        agentID,
+	})
+	if err != nil {
+		return err
+	}
+	issue, actions, targets := sent.Issue, sent.Actions, sent.Targets
+
+	// Output
+	if escalateJSON {
+		result := map[string]interface{}{
+			"id":       issue.ID,
+			"severity": severity,
+			"actions":  actions,
+			"targets":  targets,
+		}
+		if escalateSource != "" {
+			result["source"] = escalateSource
+		}
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		emoji := severityEmoji(severity)
+		fmt.Printf("%s Escalation created: %s\n", emoji, issue.ID)
+		fmt.Printf("  Severity: %s\n", severity)
+		if escalateSource != "" {
+			fmt.Printf("  Source: %s\n", escalateSource)
+		}
+		fmt.Printf("  Routed to: %s\n", strings.Join(targets, ", "))
+	}
+
+	return nil
+}
+
+// escalationRequest describes an escalation to create and route.
+type escalationRequest struct {
+	Severity    string
+	Description string
+	Reason      string
+	Source      string
+	RelatedBead string
+	From        string // Agent address of the sender
+}
+
+// escalationResult is what sendEscalation created and where it went.
+type escalationResult struct {
+	Issue   *beads.Issue
+	Actions []string
+	Targets []string
+}
+
+// sendEscalation creates the escalation bead, mails the targets routed for
+// its severity, runs external notification actions and logs it to the feed.
+// Used by gt escalate and by patrols that escalate on their own (budgets).
+func sendEscalation(townRoot string, escalationConfig *config.EscalationConfig, req escalationRequest) (*escalationResult, error) {
+	// Create escalation bead
+	bd := beads.New(beads.ResolveBeadsDir(townRoot))
+	fields := &beads.EscalationFields{
+		Severity:    req.Severity,
+		Reason:      req.Reason,
+		Source:      req.Source,
+		EscalatedBy: req.From,
+		EscalatedAt: time.Now().Format(time.RFC3339),
+		RelatedBead: req.RelatedBead,
 	}
 
-	issue, err := bd.CreateEscalationBead(description, fields)
+	issue, err := bd.CreateEscalationBead(req.Description, fields)
 	if err != nil {
-		return fmt.Errorf("creating escalation bead: %w", err)
+		return nil, fmt.Errorf("creating escalation bead: %w", err)
 	}
 
 	// Get routing actions for this severity
-	actions := escalationConfig.GetRouteForSeverity(severity)
+	actions := escalationConfig.GetRouteForSeverity(req.Severity)
 	targets := extractMailTargetsFromActions(actions)
 
 	// Send mail to each target (actions with "mail:" prefix)
 	router := mail.NewRouter(townRoot)
 	for _, target := range targets {
 		msg := &mail.Message{
-			From:    agentID,
+			From:    req.From,
 			To:      target,
-			Subject: fmt.Sprintf("[%s] %s", strings.ToUpper(severity), description),
-			Body:    formatEscalationMailBody(issue.ID, severity, escalateReason, agentID, escalateRelatedBead),
+			Subject: fmt.Sprintf("[%s] %s", strings.ToUpper(req.Severity), req.Description),
+			Body:    formatEscalationMailBody(issue.ID, req.Severity, req.Reason, req.From, req.RelatedBead),
 			Type:    mail.TypeTask,
 		}
 
 		// Set priority based on severity
-		switch severity {
+		switch req.Severity {
 		case config.SeverityCritical:
 			msg.Priority = mail.PriorityUrgent
 		case config.SeverityHigh:
@@ -114,42 +176,25 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Process external notification actions (email:, sms:, slack)
-	executeExternalActions(actions, escalationConfig, issue.ID, severity, description)
+	// Process external notification actions (email:, sms:, slack, webhook:, exec:)
+	executeExternalActions(bd, actions, escalationConfig, &escalation.Message{
+		ID:       issue.ID,
+		Severity: req.Severity,
+		Subject:  fmt.Sprintf("[%s] %s", strings.ToUpper(req.Severity), req.Description),
+		Body:     formatEscalationMailBody(issue.ID, req.Severity, req.Reason, req.From, req.RelatedBead),
+		From:     req.From,
+	})
 
 	// Log to activity feed
-	payload := events.EscalationPayload(issue.ID, agentID, strings.Join(targets, ","), description)
-	payload["severity"] = severity
+	payload := events.EscalationPayload(issue.ID, req.From, strings.Join(targets, ","), req.Description)
+	payload["severity"] = req.Severity
 	payload["actions"] = strings.Join(actions, ",")
-	if escalateSource != "" {
-		payload["source"] = escalateSource
-	}
-	_ = events.LogFeed(events.TypeEscalationSent, agentID, payload)
-
-	// Output
-	if escalateJSON {
-		result := map[string]interface{}{
-			"id":       issue.ID,
-			"severity": severity,
-			"actions":  actions,
-			"targets":  targets,
-		}
-		if escalateSource != "" {
-			result["source"] = escalateSource
-		}
-		out, _ := json.MarshalIndent(result, "", "  ")
-		fmt.Println(string(out))
-	} else {
-		emoji := severityEmoji(severity)
-		fmt.Printf("%s Escalation created: %s\n", emoji, issue.ID)
-		fmt.Printf("  Severity: %s\n", severity)
-		if escalateSource != "" {
-			fmt.Printf("  Source: %s\n", escalateSource)
-		}
-		fmt.Printf("  Routed to: %s\n", strings.Join(targets, ", "))
+	if req.Source != "" {
+		payload["source"] = req.Source
 	}
+	_ = events.LogFeed(events.TypeEscalationSent, req.From, payload)
 
-	return nil
+	return &escalationResult{Issue: issue, Actions: actions, Targets: targets}, nil
 }
 
 func runEscalateList(cmd *cobra.Command, args []string) error {
@@ -379,6 +424,15 @@ func runEscalateStale(cmd *cobra.Command, args []string) error {
 				}
 			}
 
+			// Re-escalation can reach channels the old severity didn't route to
+			executeExternalActions(bd, actions, escalationConfig, &escalation.Message{
+				ID:       result.ID,
+				Severity: result.NewSeverity,
+				Subject:  fmt.Sprintf("[%s→%s] Re-escalated: %s", strings.ToUpper(result.OldSeverity), strings.ToUpper(result.NewSeverity), result.Title),
+				Body:     formatReescalationMailBody(result, reescalatedBy),
+				From:     reescalatedBy,
+			})
+
 			// Log to activity feed
 			_ = events.LogFeed(events.TypeEscalationSent, reescalatedBy, map[string]interface{}{
 				"escalation_id":    result.ID,
@@ -538,36 +592,31 @@ func extractMailTargetsFromActions(actions []string) []string {
 	return targets
 }
 
-// executeExternalActions processes external notification actions (email:, sms:, slack).
-// For now, this logs warnings if contacts aren't configured - actual sending is future work.
-func executeExternalActions(actions []string, cfg *config.EscalationConfig, _, _, _ string) {
-	for _, action := range actions {
-		switch {
-		case strings.HasPrefix(action, "email:"):
-			if cfg.Contacts.HumanEmail == "" {
-				style.PrintWarning("email action '%s' skipped: contacts.human_email not configured in settings/escalation.json", action)
-			} else {
-				// TODO: Implement actual email sending
-				fmt.Printf("  📧 Would send email to %s (not yet implemented)\n", cfg.Contacts.HumanEmail)
-			}
+// executeExternalActions delivers external notification actions (email:, sms:,
+// slack, webhook:, exec:) and records each delivery on the escalation bead.
+func executeExternalActions(bd *beads.Beads, actions []string, cfg *config.EscalationConfig, msg *escalation.Message) {
+	deliveries := escalation.NewDispatcher(cfg).Dispatch(context.Background(), actions, msg)
 
-		case strings.HasPrefix(action, "sms:"):
-			if cfg.Contacts.HumanSMS == "" {
-				style.PrintWarning("sms action '%s' skipped: contacts.human_sms not configured in settings/escalation.json", action)
-			} else {
-				// TODO: Implement actual SMS sending
-				fmt.Printf("  📱 Would send SMS to %s (not yet implemented)\n", cfg.Contacts.HumanSMS)
-			}
+	var records []string
+	for _, d := range deliveries {
+		switch {
+		case d.Skipped():
+			style.PrintWarning("%s action skipped: %v in settings/escalation.json", d.Action, d.Err)
+			continue
+		case d.Err != nil:
+			style.PrintWarning("%s to %s failed after %d attempts: %v", d.Action, d.Target, d.Attempts, d.Err)
+		default:
+			fmt.Printf("  %s Notified %s via %s\n", style.Success.Render("✓"), d.Target, d.Action)
+		}
+		records = append(records, d.String())
+	}
 
-		case action == "slack":
-			if cfg.Contacts.SlackWebhook == "" {
-				style.PrintWarning("slack action skipped: contacts.slack_webhook not configured in settings/escalation.json")
-			} else {
-				// TODO: Implement actual Slack webhook posting
-				fmt.Printf("  💬 Would post to Slack (not yet implemented)\n")
-			}
+	if err := bd.RecordEscalationDeliveries(msg.ID, records); err != nil {
+		style.PrintWarning("could not record deliveries on %s: %v", msg.ID, err)
+	}
 
-		case action == "log":
+	for _, action := range actions {
+		if action == "log" {
 			// Log action always succeeds - writes to escalation log file
 			// TODO: Implement actual log file writing
 			fmt.Printf("  📝 Logged to escalation log\n")
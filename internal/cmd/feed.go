@@ -9,6 +9,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/tui/feed"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -25,6 +26,8 @@ var (
 	feedNoFollow bool
 	feedWindow   bool
 	feedPlain    bool
+	feedQuery    string
+	feedView     string
 )
 
 func init() {
@@ -39,6 +42,8 @@ func init() {
 	feedCmd.Flags().StringVar(&feedRig, "rig", "", "Run from specific rig's beads directory")
 	feedCmd.Flags().BoolVarP(&feedWindow, "window", "w", false, "Open in dedicated tmux window (creates 'feed' window)")
 	feedCmd.Flags().BoolVar(&feedPlain, "plain", false, "Use plain text output (bd activity) instead of TUI")
+	feedCmd.Flags().StringVar(&feedQuery, "query", "", "Start the TUI filtered by a query (e.g. \"rig:gastown type:merged\")")
+	feedCmd.Flags().StringVar(&feedView, "view", "", "Start the TUI with a saved view (see gt feed views)")
 }
 
 var feedCmd = &cobra.Command{
@@ -60,6 +65,26 @@ The feed combines multiple event sources:
 
 Use --plain for simple text output (wraps bd activity only).
 
+Filtering (TUI):
+  Press / or f to open the query bar. A query is a list of terms that must
+  all match; commas separate alternatives and a leading - negates a term:
+    rig:gastown        events in a rig
+    actor:refinery     events by actors whose address contains "refinery"
+    type:merged,done   events of the listed types
+    bead:gt-abc        events about beads whose ID starts with gt-abc
+    timeout            events whose text contains "timeout"
+  Esc clears the filter. Type @name in the query bar to load a saved view.
+
+Saved views:
+  Press s to save the current filter as a named view in the town settings,
+  and v to cycle through saved views. Manage them with gt feed views.
+
+Time travel:
+  Scroll past the oldest event in the feed panel, or press [, to load
+  earlier events from .events.jsonl (and its rotated segments), falling
+  back to the curated .feed.jsonl. Press ] to drop history and return to
+  the live feed.
+
 Tmux Integration:
   Use --window to open the feed in a dedicated tmux window named 'feed'.
   This creates a persistent window you can cycle to with C-b n/p.
@@ -86,6 +111,8 @@ Examples:
   gt feed --plain               # Plain text output (bd activity)
   gt feed --window              # Open in dedicated tmux window
   gt feed --since 1h            # Events from last hour
+  gt feed --query "actor:refinery -type:patrol_started"
+  gt feed --view my-convoys     # Start with a saved view
   gt feed --rig greenplace         # Use gastown rig's beads`,
 	RunE: runFeed,
 }
@@ -139,6 +166,9 @@ func runFeed(cmd *cobra.Command, args []string) error {
 	if useTUI {
 		return runFeedTUI(workDir)
 	}
+	if feedQuery != "" || feedView != "" {
+		return fmt.Errorf("--query and --view filter the TUI; use --type and --mol with --plain")
+	}
 
 	// Plain mode: exec bd activity directly
 	return runFeedDirect(workDir, bdArgs)
@@ -232,6 +262,25 @@ func runFeedTUI(workDir string) error {
 	m := feed.NewModel()
 	m.SetEventChannel(multiSource.Events())
 	m.SetTownRoot(townRoot)
+	m.SetHistoryLoader(feed.NewHistoryLoader(townRoot))
+
+	// Saved views and the starting filter
+	views, err := loadFeedViews(townRoot)
+	if err != nil {
+		style.PrintWarning("feed views unavailable: %v", err)
+	}
+	m.SetViews(views, func(name, query string) error {
+		return saveFeedView(townRoot, name, query)
+	})
+	if feedView != "" {
+		if err := m.ApplyView(feedView); err != nil {
+			return err
+		}
+	} else if feedQuery != "" {
+		if err := m.SetQuery(feedQuery); err != nil {
+			return fmt.Errorf("--query: %w", err)
+		}
+	}
 
 	// Run the TUI
 	p := tea.NewProgram(m, tea.WithAltScreen())
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tui/feed"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var feedViewsJSON bool
+
+var feedViewsCmd = &cobra.Command{
+	Use:   "views",
+	Short: "List saved feed views",
+	Long: `List the saved views of the feed TUI.
+
+A view is a named filter kept in the town settings (settings/config.json), so
+anyone in the town can open it with gt feed --view <name>, cycle through views
+with v in the TUI, or type @<name> in the query bar. Press s in the TUI to
+save the current filter as a view.
+
+Examples:
+  gt feed views
+  gt feed views save refinery actor:refinery
+  gt feed views save my-convoys type:sling,done actor:crew/joe
+  gt feed views remove refinery`,
+	Args: cobra.NoArgs,
+	RunE: runFeedViewsList,
+}
+
+var feedViewsSaveCmd = &cobra.Command{
+	Use:   "save <name> <query>...",
+	Short: "Save a filter as a named view",
+	Long: `Save a feed filter under a name, replacing any view of that name.
+
+Examples:
+  gt feed views save refinery actor:refinery
+  gt feed views save quiet -- -type:update,patrol_started`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runFeedViewsSave,
+}
+
+var feedViewsRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a saved view",
+	Long: `Remove a saved feed view.
+
+Examples:
+  gt feed views remove refinery`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFeedViewsRemove,
+}
+
+func init() {
+	feedViewsCmd.Flags().BoolVar(&feedViewsJSON, "json", false, "Output as JSON")
+
+	feedViewsCmd.AddCommand(feedViewsSaveCmd)
+	feedViewsCmd.AddCommand(feedViewsRemoveCmd)
+	feedCmd.AddCommand(feedViewsCmd)
+}
+
+// loadFeedViews returns the town's saved feed views.
+func loadFeedViews(townRoot string) (map[string]string, error) {
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading town settings: %w", err)
+	}
+	if settings.FeedViews == nil {
+		return map[string]string{}, nil
+	}
+	return settings.FeedViews, nil
+}
+
+// saveFeedView saves a feed view in the town settings. An empty query
+// removes the view.
+func saveFeedView(townRoot, name, query string) error {
+	if _, err := feed.ParseQuery(query); err != nil {
+		return err
+	}
+	settingsPath := config.TownSettingsPath(townRoot)
+	settings, err := config.LoadOrCreateTownSettings(settingsPath)
+	if err != nil {
+		return fmt.Errorf("loading town settings: %w", err)
+	}
+	if settings.FeedViews == nil {
+		settings.FeedViews = make(map[string]string)
+	}
+	if query == "" {
+		delete(settings.FeedViews, name)
+	} else {
+		settings.FeedViews[name] = query
+	}
+	return config.SaveTownSettings(settingsPath, settings)
+}
+
+func runFeedViewsList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	views, err := loadFeedViews(townRoot)
+	if err != nil {
+		return err
+	}
+
+	if feedViewsJSON {
+		out, _ := json.MarshalIndent(views, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+	if len(views) == 0 {
+		fmt.Println("No saved feed views. Save one with: gt feed views save <name> <query>")
+		return nil
+	}
+
+	names := make([]string, 0, len(views))
+	for name := range views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("Feed views (%d)", len(views))))
+	for _, name := range names {
+		fmt.Printf("  %-20s %s\n", name, style.Dim.Render(views[name]))
+	}
+	return nil
+}
+
+func runFeedViewsSave(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	name := args[0]
+	if strings.ContainsAny(name, " \t@") {
+		return fmt.Errorf("invalid view name %q", name)
+	}
+	query := strings.Join(args[1:], " ")
+	if err := saveFeedView(townRoot, name, query); err != nil {
+		return err
+	}
+	fmt.Printf("%s Saved feed view %s: %s\n", style.Bold.Render("✓"), name, query)
+	return nil
+}
+
+func runFeedViewsRemove(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	views, err := loadFeedViews(townRoot)
+	if err != nil {
+		return err
+	}
+	if _, ok := views[args[0]]; !ok {
+		return fmt.Errorf("no feed view %q", args[0])
+	}
+	if err := saveFeedView(townRoot, args[0], ""); err != nil {
+		return err
+	}
+	fmt.Printf("%s Removed feed view %s\n", style.Bold.Render("✓"), args[0])
+	return nil
+}
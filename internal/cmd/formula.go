@@ -13,6 +13,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/formula"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 	"golang.org/x/text/cases"
@@ -23,9 +24,11 @@ import (
 var (
 	formulaListJSON   bool
 	formulaShowJSON   bool
+	formulaLintJSON   bool
 	formulaRunPR      int
 	formulaRunRig     string
 	formulaRunDryRun  bool
+	formulaRunVars    []string
 	formulaCreateType string
 )
 
@@ -44,17 +47,22 @@ for ephemeral patrol cycles.
 Commands:
   list    List available formulas from all search paths
   show    Display formula details (steps, variables, composition)
+  lint    Check formulas for errors
   run     Execute a formula (pour and dispatch)
   create  Create a new formula template
 
 Search paths (in order):
   1. .beads/formulas/ (project)
   2. ~/.beads/formulas/ (user)
-  3. $GT_ROOT/.beads/formulas/ (orchestrator)
+  3. <town>/.beads/formulas/ (town; $GT_ROOT outside a workspace)
+
+A formula found earlier on the path shadows formulas of the same name
+further down; 'gt formula list' shows what is shadowed.
 
 Examples:
   gt formula list                    # List all formulas
   gt formula show shiny              # Show formula details
+  gt formula lint                    # Check every formula
   gt formula run shiny --pr=123      # Run formula on PR #123
   gt formula create my-workflow      # Create new formula template`,
 }
@@ -67,7 +75,10 @@ var formulaListCmd = &cobra.Command{
 Searches for formula files (.formula.toml, .formula.json) in:
   1. .beads/formulas/ (project)
   2. ~/.beads/formulas/ (user)
-  3. $GT_ROOT/.beads/formulas/ (orchestrator)
+  3. <town>/.beads/formulas/ (town; $GT_ROOT outside a workspace)
+
+Each formula is listed once, from the first directory it is found in,
+with the files of the same name it shadows.
 
 Examples:
   gt formula list            # List all formulas
@@ -81,27 +92,68 @@ var formulaShowCmd = &cobra.Command{
 	Long: `Display detailed information about a formula.
 
 Shows:
-  - Formula metadata (name, type, description)
+  - Formula metadata (name, type, description, source file)
   - Variables with defaults and constraints
-  - Steps with dependencies
-  - Composition rules (extends, aspects)
+  - Steps with dependencies, after composition is applied
+  - Composition rules (extends, expansions, aspects)
 
 Examples:
   gt formula show shiny
+  gt formula show shiny-enterprise       # Steps after expansion
   gt formula show rule-of-five --json`,
 	Args: cobra.ExactArgs(1),
 	RunE: runFormulaShow,
 }
 
+var formulaLintCmd = &cobra.Command{
+	Use:   "lint [name|path...]",
+	Short: "Check formulas for errors",
+	Long: `Check formulas for errors, reported with file and line number.
+
+Checks:
+  - TOML syntax and value types
+  - Required fields, duplicate and unknown step references, cycles
+  - Variable types, enums, patterns and defaults
+  - Composition: extends, expansions and aspects resolve and the
+    composed formula is valid
+  - {{variables}} used but not declared (warning)
+
+With no arguments, every formula file on the search path is checked,
+including shadowed ones. Exits non-zero if any error is found.
+
+Examples:
+  gt formula lint                                  # All formulas
+  gt formula lint shiny-enterprise                 # By name
+  gt formula lint .beads/formulas/my.formula.toml  # By path
+  gt formula lint --json`,
+	RunE: runFormulaLint,
+}
+
 var formulaRunCmd = &cobra.Command{
 	Use:   "run [name]",
 	Short: "Execute a formula",
-	Long: `Execute a formula by pouring it and dispatching work.
+	Long: `Execute a formula by creating its work as beads and dispatching it.
 
 This command:
   1. Looks up the formula by name (or uses default from rig config)
-  2. Pours it to create a molecule (or uses existing proto)
-  3. Dispatches the molecule to available workers
+  2. Creates a bead per leg, step, template or aspect, tracked by a convoy
+  3. Slings the work that is ready now to polecats in the rig
+
+How each formula type runs:
+  convoy     Every leg at once; the synthesis waits for the legs
+  workflow   Steps in dependency order: a step is slung once all of its
+             needs have closed
+  expansion  Template entries, instantiated with --var values, in the
+             order their needs give
+  aspect     Every aspect at once, then the synthesis (if defined)
+
+Steps that are not ready yet are slung by 'gt convoy check' as their
+needs close; the daemon runs it whenever a tracked bead closes.
+
+Variables are passed with --var name=value and fill {{name}} and {name}
+placeholders. Required variables without a default must be given. For
+expansion formulas, --var target=<bead> also fills {target.title} and
+{target.description} from the bead.
 
 For PR-based workflows, use --pr to specify the GitHub PR number.
 
@@ -109,16 +161,19 @@ If no formula name is provided, uses the default formula configured in
 the rig's settings/config.json under workflow.default_formula.
 
 Options:
-  --pr=N      Run formula on GitHub PR #N
-  --rig=NAME  Target specific rig (default: current or gastown)
-  --dry-run   Show what would happen without executing
+  --pr=N         Run formula on GitHub PR #N
+  --rig=NAME     Target specific rig (default: current or gastown)
+  --var KEY=VAL  Formula variable (repeatable)
+  --dry-run      Show what would happen without executing
 
 Examples:
   gt formula run shiny                    # Run formula in current rig
   gt formula run                          # Run default formula from rig config
   gt formula run shiny --pr=123           # Run on PR #123
   gt formula run security-audit --rig=beads  # Run in specific rig
-  gt formula run release --dry-run        # Preview execution`,
+  gt formula run release --dry-run        # Preview execution
+  gt formula run shiny --var feature="rate limiting"
+  gt formula run rule-of-five --var target=gt-abc12`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runFormulaRun,
 }
@@ -151,10 +206,14 @@ func init() {
 	// Show flags
 	formulaShowCmd.Flags().BoolVar(&formulaShowJSON, "json", false, "Output as JSON")
 
+	// Lint flags
+	formulaLintCmd.Flags().BoolVar(&formulaLintJSON, "json", false, "Output as JSON")
+
 	// Run flags
 	formulaRunCmd.Flags().IntVar(&formulaRunPR, "pr", 0, "GitHub PR number to run formula on")
 	formulaRunCmd.Flags().StringVar(&formulaRunRig, "rig", "", "Target rig (default: current or gastown)")
 	formulaRunCmd.Flags().BoolVar(&formulaRunDryRun, "dry-run", false, "Preview execution without running")
+	formulaRunCmd.Flags().StringArrayVar(&formulaRunVars, "var", nil, "Formula variable (key=value), can be repeated")
 
 	// Create flags
 	formulaCreateCmd.Flags().StringVar(&formulaCreateType, "type", "task", "Formula type: task, workflow, or patrol")
@@ -162,42 +221,18 @@ func init() {
 	// Add subcommands
 	formulaCmd.AddCommand(formulaListCmd)
 	formulaCmd.AddCommand(formulaShowCmd)
+	formulaCmd.AddCommand(formulaLintCmd)
 	formulaCmd.AddCommand(formulaRunCmd)
 	formulaCmd.AddCommand(formulaCreateCmd)
 
 	rootCmd.AddCommand(formulaCmd)
 }
 
-// runFormulaList delegates to bd formula list
-func runFormulaList(cmd *cobra.Command, args []string) error {
-	bdArgs := []string{"formula", "list"}
-	if formulaListJSON {
-		bdArgs = append(bdArgs, "--json")
-	}
-
-	bdCmd := exec.Command("bd", bdArgs...)
-	bdCmd.Stdout = os.Stdout
-	bdCmd.Stderr = os.Stderr
-	return bdCmd.Run()
-}
-
-// runFormulaShow delegates to bd formula show
-func runFormulaShow(cmd *cobra.Command, args []string) error {
-	formulaName := args[0]
-	bdArgs := []string{"formula", "show", formulaName}
-	if formulaShowJSON {
-		bdArgs = append(bdArgs, "--json")
-	}
-
-	bdCmd := exec.Command("bd", bdArgs...)
-	bdCmd.Stdout = os.Stdout
-	bdCmd.Stderr = os.Stderr
-	return bdCmd.Run()
-}
-
 // runFormulaRun executes a formula by spawning a convoy of polecats.
 // For convoy-type formulas, it creates a convoy bead, creates leg beads,
 // and slings each leg to a separate polecat with leg-specific prompts.
+// Other types are planned into step beads and slung as their needs close
+// (see executeFormulaPlan).
 func runFormulaRun(cmd *cobra.Command, args []string) error {
 	// Determine target rig first (needed for default formula lookup)
 	targetRig := formulaRunRig
@@ -245,42 +280,45 @@ func runFormulaRun(cmd *cobra.Command, args []string) error {
 		fmt.Printf("%s Using default formula: %s\n", style.Dim.Render("Note:"), formulaName)
 	}
 
-	// Find the formula file
-	formulaPath, err := findFormulaFile(formulaName)
+	// Load the formula, with its composition resolved
+	f, err := formulaLoader().Load(formulaName)
 	if err != nil {
-		return fmt.Errorf("finding formula: %w", err)
+		return fmt.Errorf("loading formula: %w", err)
 	}
 
-	// Parse the formula
-	f, err := parseFormulaFile(formulaPath)
-	if err != nil {
-		return fmt.Errorf("parsing formula: %w", err)
+	// Convoy formulas keep their leg-based execution
+	if f.Type == formula.TypeConvoy {
+		if formulaRunDryRun {
+			return dryRunFormula(f, formulaName, targetRig)
+		}
+		return executeConvoyFormula(f, formulaName, targetRig)
 	}
 
-	// Handle dry-run mode
-	if formulaRunDryRun {
-		return dryRunFormula(f, formulaName, targetRig)
+	// Workflow, expansion and aspect formulas run as a plan of steps
+	vars, err := parseFormulaVars(formulaRunVars)
+	if err != nil {
+		return err
+	}
+	if formulaRunPR > 0 {
+		if _, ok := vars["pr"]; !ok {
+			vars["pr"] = fmt.Sprintf("%d", formulaRunPR)
+		}
 	}
+	addTargetVars(vars)
 
-	// Currently only convoy formulas are supported for execution
-	if f.Type != "convoy" {
-		fmt.Printf("%s Formula type '%s' not yet supported for execution.\n",
-			style.Dim.Render("Note:"), f.Type)
-		fmt.Printf("Currently only 'convoy' formulas can be run.\n")
-		fmt.Printf("\nTo run '%s' manually:\n", formulaName)
-		fmt.Printf("  1. View formula:   gt formula show %s\n", formulaName)
-		fmt.Printf("  2. Cook to proto:  bd cook %s\n", formulaName)
-		fmt.Printf("  3. Pour molecule:  bd pour %s\n", formulaName)
-		fmt.Printf("  4. Sling to rig:   gt sling <mol-id> %s\n", targetRig)
-		return nil
+	plan, err := buildFormulaPlan(f, vars)
+	if err != nil {
+		return fmt.Errorf("formula %s: %w", formulaName, err)
 	}
 
-	// Execute convoy formula
-	return executeConvoyFormula(f, formulaName, targetRig)
+	if formulaRunDryRun {
+		return dryRunFormulaPlan(plan, targetRig)
+	}
+	return executeFormulaPlan(plan, targetRig)
 }
 
 // dryRunFormula shows what would happen without executing
-func dryRunFormula(f *formulaData, formulaName, targetRig string) error {
+func dryRunFormula(f *formula.Formula, formulaName, targetRig string) error {
 	fmt.Printf("%s Would execute formula:\n", style.Dim.Render("[dry-run]"))
 	fmt.Printf("  Formula: %s\n", style.Bold.Render(formulaName))
 	fmt.Printf("  Type:    %s\n", f.Type)
@@ -289,7 +327,7 @@ func dryRunFormula(f *formulaData, formulaName, targetRig string) error {
 		fmt.Printf("  PR:      #%d\n", formulaRunPR)
 	}
 
-	if f.Type == "convoy" && len(f.Legs) > 0 {
+	if f.Type == formula.TypeConvoy && len(f.Legs) > 0 {
 		fmt.Printf("\n  Legs (%d parallel):\n", len(f.Legs))
 		for _, leg := range f.Legs {
 			fmt.Printf("    • %s: %s\n", leg.ID, leg.Title)
@@ -304,7 +342,7 @@ func dryRunFormula(f *formulaData, formulaName, targetRig string) error {
 }
 
 // executeConvoyFormula spawns a convoy of polecats to execute a convoy formula
-func executeConvoyFormula(f *formulaData, formulaName, targetRig string) error {
+func executeConvoyFormula(f *formula.Formula, formulaName, targetRig string) error {
 	fmt.Printf("%s Executing convoy formula: %s\n\n",
 		style.Bold.Render("🚚"), formulaName)
 
@@ -492,235 +530,16 @@ func executeConvoyFormula(f *formulaData, formulaName, targetRig string) error {
 	return nil
 }
 
-// formulaData holds parsed formula information
-type formulaData struct {
-	Name        string
-	Description string
-	Type        string
-	Legs        []formulaLeg
-	Synthesis   *formulaSynthesis
-	Prompts     map[string]string
-}
-
-type formulaLeg struct {
-	ID          string
-	Title       string
-	Focus       string
-	Description string
-}
-
-type formulaSynthesis struct {
-	Title       string
-	Description string
-	DependsOn   []string
-}
-
-// findFormulaFile searches for a formula file by name
-func findFormulaFile(name string) (string, error) {
-	// Search paths in order
-	searchPaths := []string{}
-
-	// 1. Project .beads/formulas/
-	if cwd, err := os.Getwd(); err == nil {
-		searchPaths = append(searchPaths, filepath.Join(cwd, ".beads", "formulas"))
-	}
-
-	// 2. Town .beads/formulas/
-	if townRoot, err := workspace.FindFromCwd(); err == nil {
-		searchPaths = append(searchPaths, filepath.Join(townRoot, ".beads", "formulas"))
-	}
-
-	// 3. User ~/.beads/formulas/
-	if home, err := os.UserHomeDir(); err == nil {
-		searchPaths = append(searchPaths, filepath.Join(home, ".beads", "formulas"))
-	}
-
-	// Try each path with common extensions
-	extensions := []string{".formula.toml", ".formula.json"}
-	for _, basePath := range searchPaths {
-		for _, ext := range extensions {
-			path := filepath.Join(basePath, name+ext)
-			if _, err := os.Stat(path); err == nil {
-				return path, nil
-			}
-		}
-	}
-
-	return "", fmt.Errorf("formula '%s' not found in search paths", name)
-}
-
-// parseFormulaFile parses a formula file into formulaData
-func parseFormulaFile(path string) (*formulaData, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-
-	// Use simple TOML parsing for the fields we need
-	// (avoids importing the full formula package which might cause cycles)
-	f := &formulaData{
-		Prompts: make(map[string]string),
-	}
-
-	content := string(data)
-
-	// Parse formula name
-	if match := extractTOMLValue(content, "formula"); match != "" {
-		f.Name = match
-	}
-
-	// Parse description
-	if match := extractTOMLMultiline(content, "description"); match != "" {
-		f.Description = match
-	}
-
-	// Parse type
-	if match := extractTOMLValue(content, "type"); match != "" {
-		f.Type = match
-	}
-
-	// Parse legs (convoy formulas)
-	f.Legs = extractLegs(content)
-
-	// Parse synthesis
-	f.Synthesis = extractSynthesis(content)
-
-	// Parse prompts
-	f.Prompts = extractPrompts(content)
-
-	return f, nil
-}
-
-// extractTOMLValue extracts a simple quoted value from TOML
-func extractTOMLValue(content, key string) string {
-	// Match: key = "value" or key = 'value'
-	for _, line := range strings.Split(content, "\n") {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, key+" =") || strings.HasPrefix(line, key+"=") {
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				val := strings.TrimSpace(parts[1])
-				// Remove quotes
-				if len(val) >= 2 && (val[0] == '"' || val[0] == '\'') {
-					return val[1 : len(val)-1]
-				}
-				return val
-			}
-		}
-	}
-	return ""
-}
-
-// extractTOMLMultiline extracts a multiline string (""" ... """)
-func extractTOMLMultiline(content, key string) string {
-	// Look for key = """
-	keyPattern := key + ` = """`
-	idx := strings.Index(content, keyPattern)
-	if idx == -1 {
-		// Try single-line
-		return extractTOMLValue(content, key)
-	}
-
-	start := idx + len(keyPattern)
-	end := strings.Index(content[start:], `"""`)
-	if end == -1 {
-		return ""
-	}
-
-	return strings.TrimSpace(content[start : start+end])
-}
-
-// extractLegs parses [[legs]] sections from TOML
-func extractLegs(content string) []formulaLeg {
-	var legs []formulaLeg
-
-	// Split by [[legs]]
-	sections := strings.Split(content, "[[legs]]")
-	for i, section := range sections {
-		if i == 0 {
-			continue // Skip content before first [[legs]]
-		}
-
-		// Find where this section ends (next [[ or EOF)
-		endIdx := strings.Index(section, "[[")
-		if endIdx == -1 {
-			endIdx = len(section)
-		}
-		section = section[:endIdx]
-
-		leg := formulaLeg{
-			ID:          extractTOMLValue(section, "id"),
-			Title:       extractTOMLValue(section, "title"),
-			Focus:       extractTOMLValue(section, "focus"),
-			Description: extractTOMLMultiline(section, "description"),
-		}
-
-		if leg.ID != "" {
-			legs = append(legs, leg)
-		}
-	}
-
-	return legs
-}
-
-// extractSynthesis parses [synthesis] section from TOML
-func extractSynthesis(content string) *formulaSynthesis {
-	idx := strings.Index(content, "[synthesis]")
-	if idx == -1 {
-		return nil
-	}
-
-	section := content[idx:]
-	// Find where section ends
-	if endIdx := strings.Index(section[1:], "\n["); endIdx != -1 {
-		section = section[:endIdx+1]
-	}
-
-	syn := &formulaSynthesis{
-		Title:       extractTOMLValue(section, "title"),
-		Description: extractTOMLMultiline(section, "description"),
-	}
-
-	// Parse depends_on array
-	if depsLine := extractTOMLValue(section, "depends_on"); depsLine != "" {
-		// Simple array parsing: ["a", "b", "c"]
-		depsLine = strings.Trim(depsLine, "[]")
-		for _, dep := range strings.Split(depsLine, ",") {
-			dep = strings.Trim(strings.TrimSpace(dep), `"'`)
-			if dep != "" {
-				syn.DependsOn = append(syn.DependsOn, dep)
-			}
-		}
-	}
-
-	if syn.Title == "" && syn.Description == "" {
-		return nil
-	}
-
-	return syn
-}
-
-// extractPrompts parses [prompts] section from TOML
-func extractPrompts(content string) map[string]string {
-	prompts := make(map[string]string)
-
-	idx := strings.Index(content, "[prompts]")
-	if idx == -1 {
-		return prompts
-	}
-
-	section := content[idx:]
-	// Find where section ends
-	if endIdx := strings.Index(section[1:], "\n["); endIdx != -1 {
-		section = section[:endIdx+1]
-	}
-
-	// Extract base prompt
-	if base := extractTOMLMultiline(section, "base"); base != "" {
-		prompts["base"] = base
+// formulaLoader returns the formula loader for the current directory:
+// project formulas, then the user's, then the town's ($GT_ROOT outside a
+// workspace).
+func formulaLoader() *formula.Loader {
+	cwd, _ := os.Getwd()
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		townRoot = os.Getenv("GT_ROOT")
 	}
-
-	return prompts
+	return formula.NewLoader(cwd, townRoot)
 }
 
 // generateFormulaShortID generates a short random ID (5 lowercase chars)
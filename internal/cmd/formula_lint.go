@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// runFormulaLint checks formula files and prints findings as path:line.
+func runFormulaLint(cmd *cobra.Command, args []string) error {
+	loader := formulaLoader()
+	paths, err := formulaLintPaths(loader, args)
+	if err != nil {
+		return err
+	}
+
+	var findings []formula.Finding
+	errorCount, warningCount := 0, 0
+	for _, path := range paths {
+		for _, f := range loader.Lint(path) {
+			findings = append(findings, f)
+			if f.Severity == formula.SeverityError {
+				errorCount++
+			} else {
+				warningCount++
+			}
+		}
+	}
+
+	if formulaLintJSON {
+		if findings == nil {
+			findings = []formula.Finding{}
+		}
+		out, _ := json.MarshalIndent(findings, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		for _, f := range findings {
+			severity := style.Warning.Render(f.Severity)
+			if f.Severity == formula.SeverityError {
+				severity = style.Error.Render(f.Severity)
+			}
+			loc := f.Path
+			if f.Line > 0 {
+				loc = fmt.Sprintf("%s:%d", f.Path, f.Line)
+			}
+			fmt.Printf("%s: %s: %s\n", loc, severity, f.Message)
+		}
+		if errorCount+warningCount == 0 {
+			fmt.Printf("%s %d formula(s) OK\n", style.Bold.Render("✓"), len(paths))
+		} else {
+			fmt.Printf("\n%d formula(s) checked: %d error(s), %d warning(s)\n", len(paths), errorCount, warningCount)
+		}
+	}
+
+	if errorCount > 0 {
+		// The findings are the error report
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+// formulaLintPaths returns the files to lint: each argument as a path if
+// it is a file, otherwise as a formula name; with no arguments, every
+// formula file on the search path.
+func formulaLintPaths(loader *formula.Loader, args []string) ([]string, error) {
+	var paths []string
+	if len(args) == 0 {
+		for _, e := range loader.List() {
+			paths = append(paths, e.Path)
+			for _, sh := range e.Shadows {
+				paths = append(paths, sh.Path)
+			}
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no formulas found on the search path")
+		}
+		return paths, nil
+	}
+	for _, arg := range args {
+		if strings.ContainsRune(arg, os.PathSeparator) || strings.HasSuffix(arg, ".toml") || strings.HasSuffix(arg, ".json") {
+			if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+				paths = append(paths, arg)
+				continue
+			}
+		}
+		src, err := loader.Find(arg)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, src.Path)
+	}
+	return paths, nil
+}
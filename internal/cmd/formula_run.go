@@ -0,0 +1,486 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// synthesisStepID is the plan step that combines aspect results.
+const synthesisStepID = "synthesis"
+
+// formulaPlan is a workflow, expansion or aspect formula with its variables
+// applied: the steps to create as beads, in dependency order.
+type formulaPlan struct {
+	Name  string
+	Type  formula.FormulaType
+	Vars  map[string]string
+	Steps []planStep
+
+	// resolved is the formula after variable substitution; its ReadySteps
+	// decides what runs next.
+	resolved *formula.Formula
+}
+
+// planStep is one unit of work in a formula plan.
+type planStep struct {
+	ID          string
+	Title       string
+	Description string
+	Needs       []string
+}
+
+// parseFormulaVars parses --var key=value flags.
+func parseFormulaVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --var %q: want key=value", pair)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// buildFormulaPlan applies vars to a workflow, expansion or aspect formula
+// and orders its steps. Declared variables fall back to their defaults and
+// are checked against their types; a required variable without a value, or
+// a step ID that still has a placeholder, is an error.
+func buildFormulaPlan(f *formula.Formula, vars map[string]string) (*formulaPlan, error) {
+	resolvedVars, err := f.ResolveVars(vars)
+	if err != nil {
+		return nil, fmt.Errorf("%w\n(pass --var name=value)", err)
+	}
+
+	sub := func(s string) string { return formula.Substitute(s, resolvedVars) }
+	subAll := func(ids []string) []string {
+		out := make([]string, len(ids))
+		for i, id := range ids {
+			out[i] = sub(id)
+		}
+		return out
+	}
+
+	resolved := *f
+	resolved.Steps = nil
+	resolved.Template = nil
+	resolved.Aspects = nil
+	switch f.Type {
+	case formula.TypeWorkflow:
+		for _, s := range f.Steps {
+			resolved.Steps = append(resolved.Steps, formula.Step{
+				ID: sub(s.ID), Title: sub(s.Title), Description: sub(s.Description), Needs: subAll(s.Needs),
+			})
+		}
+	case formula.TypeExpansion:
+		for _, t := range f.Template {
+			resolved.Template = append(resolved.Template, formula.Template{
+				ID: sub(t.ID), Title: sub(t.Title), Description: sub(t.Description), Needs: subAll(t.Needs),
+			})
+		}
+	case formula.TypeAspect:
+		if len(f.Aspects) == 0 {
+			return nil, fmt.Errorf("%s only has advice; apply it to a workflow with [compose] aspects = [%q]", f.Name, f.Name)
+		}
+		for _, a := range f.Aspects {
+			resolved.Aspects = append(resolved.Aspects, formula.Aspect{
+				ID: sub(a.ID), Title: sub(a.Title), Focus: sub(a.Focus), Description: sub(a.Description),
+			})
+		}
+		if f.Synthesis != nil {
+			syn := *f.Synthesis
+			syn.Title, syn.Description, syn.DependsOn = sub(syn.Title), sub(syn.Description), subAll(syn.DependsOn)
+			resolved.Synthesis = &syn
+		}
+	default:
+		return nil, fmt.Errorf("formula type %q cannot be planned as steps", f.Type)
+	}
+
+	order, err := resolved.TopologicalSort()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &formulaPlan{Name: f.Name, Type: f.Type, Vars: resolvedVars, resolved: &resolved}
+	for _, id := range order {
+		if left := formula.Placeholders(id); len(left) > 0 {
+			return nil, fmt.Errorf("step %q needs variable(s) %s (pass --var name=value)", id, strings.Join(left, ", "))
+		}
+		step := planStep{ID: id}
+		switch f.Type {
+		case formula.TypeWorkflow:
+			s := resolved.GetStep(id)
+			step.Title, step.Description, step.Needs = s.Title, s.Description, s.Needs
+		case formula.TypeExpansion:
+			t := resolved.GetTemplate(id)
+			step.Title, step.Description, step.Needs = t.Title, t.Description, t.Needs
+		case formula.TypeAspect:
+			a := resolved.GetAspect(id)
+			step.Title, step.Description = a.Title, a.Description
+			if a.Focus != "" {
+				step.Description = fmt.Sprintf("Focus: %s\n\n%s", a.Focus, a.Description)
+			}
+		}
+		if step.Title == "" {
+			step.Title = id
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	// Aspects run in parallel; the synthesis waits for them
+	if f.Type == formula.TypeAspect && resolved.Synthesis != nil {
+		needs := resolved.Synthesis.DependsOn
+		if len(needs) == 0 {
+			needs = resolved.GetAllIDs()
+		}
+		desc := resolved.Synthesis.Description
+		if desc == "" {
+			desc = "Synthesize findings from all aspects into unified output"
+		}
+		title := resolved.Synthesis.Title
+		if title == "" {
+			title = "Synthesis"
+		}
+		plan.Steps = append(plan.Steps, planStep{ID: synthesisStepID, Title: title, Description: desc, Needs: needs})
+	}
+	return plan, nil
+}
+
+// Ready returns the steps whose needs are all completed and which are not
+// completed themselves, in plan order.
+func (p *formulaPlan) Ready(completed map[string]bool) []string {
+	ready := p.resolved.ReadySteps(completed)
+	if p.Type == formula.TypeAspect {
+		if syn := p.step(synthesisStepID); syn != nil && !completed[synthesisStepID] {
+			allMet := true
+			for _, need := range syn.Needs {
+				if !completed[need] {
+					allMet = false
+					break
+				}
+			}
+			if allMet {
+				ready = append(ready, synthesisStepID)
+			}
+		}
+	}
+	return ready
+}
+
+func (p *formulaPlan) step(id string) *planStep {
+	for i := range p.Steps {
+		if p.Steps[i].ID == id {
+			return &p.Steps[i]
+		}
+	}
+	return nil
+}
+
+// Formula convoys record how to resume themselves in their description,
+// alongside the Owner:/Notify: lines gt convoy uses.
+const (
+	formulaConvoyFormulaPrefix = "Formula: "
+	formulaConvoyRigPrefix     = "Rig: "
+	formulaConvoyVarPrefix     = "Var: "
+	formulaConvoyStepPrefix    = "Step: "
+)
+
+// formulaConvoyInfo is what a formula convoy's description records.
+type formulaConvoyInfo struct {
+	Formula string
+	Rig     string
+	Vars    map[string]string
+	Beads   map[string]string // step ID -> bead ID
+}
+
+// formatFormulaConvoyDescription writes the description of a formula convoy.
+func formatFormulaConvoyDescription(plan *formulaPlan, rig string, beadIDs map[string]string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Formula convoy: %s (%s, %d steps)\n\n", plan.Name, plan.Type, len(plan.Steps))
+	fmt.Fprintf(&b, "%s%s\n", formulaConvoyFormulaPrefix, plan.Name)
+	fmt.Fprintf(&b, "%s%s\n", formulaConvoyRigPrefix, rig)
+	keys := make([]string, 0, len(plan.Vars))
+	for k := range plan.Vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s%s=%s\n", formulaConvoyVarPrefix, k, strings.ReplaceAll(plan.Vars[k], "\n", " "))
+	}
+	for _, s := range plan.Steps {
+		if id, ok := beadIDs[s.ID]; ok {
+			fmt.Fprintf(&b, "%s%s %s\n", formulaConvoyStepPrefix, s.ID, id)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// parseFormulaConvoyDescription reads a formula convoy's description.
+// ok is false for convoys not created by gt formula run.
+func parseFormulaConvoyDescription(desc string) (info formulaConvoyInfo, ok bool) {
+	info.Vars = make(map[string]string)
+	info.Beads = make(map[string]string)
+	for _, line := range strings.Split(desc, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, formulaConvoyFormulaPrefix):
+			info.Formula = strings.TrimPrefix(line, formulaConvoyFormulaPrefix)
+		case strings.HasPrefix(line, formulaConvoyRigPrefix):
+			info.Rig = strings.TrimPrefix(line, formulaConvoyRigPrefix)
+		case strings.HasPrefix(line, formulaConvoyVarPrefix):
+			if k, v, found := strings.Cut(strings.TrimPrefix(line, formulaConvoyVarPrefix), "="); found {
+				info.Vars[k] = v
+			}
+		case strings.HasPrefix(line, formulaConvoyStepPrefix):
+			if fields := strings.Fields(strings.TrimPrefix(line, formulaConvoyStepPrefix)); len(fields) == 2 {
+				info.Beads[fields[0]] = fields[1]
+			}
+		}
+	}
+	return info, info.Formula != "" && info.Rig != "" && len(info.Beads) > 0
+}
+
+// loadFormulaPlan loads and plans a formula.
+func loadFormulaPlan(name string, vars map[string]string) (*formulaPlan, error) {
+	f, err := formulaLoader().Load(name)
+	if err != nil {
+		return nil, fmt.Errorf("loading formula %s: %w", name, err)
+	}
+	return buildFormulaPlan(f, vars)
+}
+
+// addTargetVars fills target.title and target.description from the bead
+// named by the target variable, for expansion formulas that refer to them.
+func addTargetVars(vars map[string]string) {
+	target := vars["target"]
+	if target == "" || !looksLikeIssueID(target) {
+		return
+	}
+	if _, ok := vars["target.title"]; ok {
+		return
+	}
+	out, err := exec.Command("bd", "--no-daemon", "show", target, "--json").Output() //nolint:gosec // G204: bd is a trusted internal tool
+	if err != nil {
+		return
+	}
+	var issues []beads.Issue
+	if json.Unmarshal(out, &issues) != nil || len(issues) == 0 {
+		return
+	}
+	vars["target.title"] = issues[0].Title
+	if _, ok := vars["target.description"]; !ok {
+		vars["target.description"] = issues[0].Description
+	}
+}
+
+// dryRunFormulaPlan shows the steps a formula would create and the first
+// wave that would be dispatched.
+func dryRunFormulaPlan(plan *formulaPlan, targetRig string) error {
+	fmt.Printf("%s Would execute formula:\n", style.Dim.Render("[dry-run]"))
+	fmt.Printf("  Formula: %s\n", style.Bold.Render(plan.Name))
+	fmt.Printf("  Type:    %s\n", plan.Type)
+	fmt.Printf("  Rig:     %s\n", targetRig)
+	if len(plan.Vars) > 0 {
+		keys := make([]string, 0, len(plan.Vars))
+		for k := range plan.Vars {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Printf("  Vars:\n")
+		for _, k := range keys {
+			fmt.Printf("    %s = %s\n", k, plan.Vars[k])
+		}
+	}
+
+	ready := make(map[string]bool)
+	for _, id := range plan.Ready(nil) {
+		ready[id] = true
+	}
+	fmt.Printf("\n  Steps (%d):\n", len(plan.Steps))
+	for _, s := range plan.Steps {
+		marker := style.Dim.Render("○")
+		if ready[s.ID] {
+			marker = "→"
+		}
+		line := fmt.Sprintf("    %s %s: %s", marker, s.ID, s.Title)
+		if len(s.Needs) > 0 {
+			line += style.Dim.Render(fmt.Sprintf(" (needs %s)", strings.Join(s.Needs, ", ")))
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("\n  %s\n", style.Dim.Render("→ dispatched immediately; the rest as their needs close"))
+	return nil
+}
+
+// executeFormulaPlan creates a convoy with a bead per step, wires the
+// step dependencies, and slings the steps that are ready now. The rest are
+// dispatched by advanceFormulaConvoy as their needs close.
+func executeFormulaPlan(plan *formulaPlan, targetRig string) error {
+	fmt.Printf("%s Executing %s formula: %s\n\n", style.Bold.Render("🚚"), plan.Type, plan.Name)
+
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil {
+		return fmt.Errorf("finding town root: %w", err)
+	}
+	townBeads := filepath.Join(townRoot, ".beads")
+
+	// Step beads first, so the convoy description can list them
+	beadIDs := make(map[string]string)
+	for _, s := range plan.Steps {
+		prefix := "hq-step-"
+		switch {
+		case s.ID == synthesisStepID && plan.Type == formula.TypeAspect:
+			prefix = "hq-syn-"
+		case plan.Type == formula.TypeAspect:
+			prefix = "hq-asp-"
+		}
+		beadID := prefix + generateFormulaShortID()
+		desc := fmt.Sprintf("%s\n\n---\nFormula: %s\nStep: %s", s.Description, plan.Name, s.ID)
+		if err := bdCreateTask(townBeads, beadID, s.Title, desc); err != nil {
+			return fmt.Errorf("creating bead for step %s: %w", s.ID, err)
+		}
+		beadIDs[s.ID] = beadID
+		fmt.Printf("  %s Created step: %s (%s)\n", style.Dim.Render("○"), s.ID, beadID)
+	}
+
+	// Blocking deps mirror the formula's needs
+	for _, s := range plan.Steps {
+		for _, need := range s.Needs {
+			depCmd := exec.Command("bd", "dep", "add", beadIDs[s.ID], beadIDs[need]) //nolint:gosec // G204: bd is a trusted internal tool
+			depCmd.Dir = townBeads
+			if err := depCmd.Run(); err != nil {
+				style.PrintWarning("couldn't add dependency %s -> %s: %v", s.ID, need, err)
+			}
+		}
+	}
+
+	convoyID := fmt.Sprintf("hq-cv-%s", generateFormulaShortID())
+	convoyTitle := fmt.Sprintf("%s (%s)", plan.Name, plan.Type)
+	if v := plan.Vars["target"]; v != "" {
+		convoyTitle = fmt.Sprintf("%s: %s", plan.Name, v)
+	}
+	createArgs := []string{
+		"create",
+		"--type=convoy",
+		"--id=" + convoyID,
+		"--title=" + convoyTitle,
+		"--description=" + formatFormulaConvoyDescription(plan, targetRig, beadIDs),
+	}
+	if beads.NeedsForceForID(convoyID) {
+		createArgs = append(createArgs, "--force")
+	}
+	createCmd := exec.Command("bd", createArgs...) //nolint:gosec // G204: bd is a trusted internal tool
+	createCmd.Dir = townBeads
+	createCmd.Stderr = os.Stderr
+	if err := createCmd.Run(); err != nil {
+		return fmt.Errorf("creating convoy bead: %w", err)
+	}
+	for _, s := range plan.Steps {
+		trackCmd := exec.Command("bd", "dep", "add", convoyID, beadIDs[s.ID], "--type=tracks") //nolint:gosec // G204: bd is a trusted internal tool
+		trackCmd.Dir = townBeads
+		if err := trackCmd.Run(); err != nil {
+			style.PrintWarning("couldn't track step %s: %v", s.ID, err)
+		}
+	}
+	fmt.Printf("%s Created convoy: %s\n", style.Bold.Render("✓"), convoyID)
+
+	fmt.Printf("\n%s Dispatching ready steps...\n\n", style.Bold.Render("→"))
+	dispatched := 0
+	for _, id := range plan.Ready(nil) {
+		if slingFormulaStep(townBeads, plan.step(id), beadIDs[id], targetRig) {
+			dispatched++
+		}
+	}
+
+	fmt.Printf("\n%s Formula dispatched!\n", style.Bold.Render("✓"))
+	fmt.Printf("  Convoy:  %s\n", convoyID)
+	fmt.Printf("  Steps:   %d created, %d dispatched\n", len(plan.Steps), dispatched)
+	if dispatched < len(plan.Steps) {
+		fmt.Printf("  %s\n", style.Dim.Render("Remaining steps are slung by 'gt convoy check' as their needs close"))
+	}
+	fmt.Printf("\n  Track progress: gt convoy status %s\n", convoyID)
+	return nil
+}
+
+// bdCreateTask creates a task bead with an explicit ID in the town beads.
+func bdCreateTask(townBeads, id, title, description string) error {
+	args := []string{"create", "--type=task", "--id=" + id, "--title=" + title, "--description=" + description}
+	if beads.NeedsForceForID(id) {
+		args = append(args, "--force")
+	}
+	cmd := exec.Command("bd", args...) //nolint:gosec // G204: bd is a trusted internal tool
+	cmd.Dir = townBeads
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w (%s)", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// slingFormulaStep slings a step bead to a fresh polecat in the rig.
+// Failures are recorded on the bead and reported, not returned: the next
+// convoy check retries the step.
+func slingFormulaStep(townBeads string, step *planStep, beadID, rig string) bool {
+	slingCmd := exec.Command("gt", "sling", beadID, rig, "-s", step.Title) //nolint:gosec // G204: gt is this binary
+	slingCmd.Stdout = os.Stdout
+	slingCmd.Stderr = os.Stderr
+	if err := slingCmd.Run(); err != nil {
+		fmt.Printf("%s Failed to sling step %s: %v\n", style.Dim.Render("Warning:"), step.ID, err)
+		commentCmd := exec.Command("bd", "comment", beadID, fmt.Sprintf("Failed to sling: %v", err)) //nolint:gosec // G204: bd is a trusted internal tool
+		commentCmd.Dir = townBeads
+		_ = commentCmd.Run()
+		return false
+	}
+	return true
+}
+
+// advanceFormulaConvoy slings the steps of a formula convoy whose needs have
+// closed since the last check. It re-reads the formula so step IDs and
+// needs match what gt formula run created. Returns the steps dispatched.
+func advanceFormulaConvoy(townBeads, convoyID, description string) []string {
+	info, ok := parseFormulaConvoyDescription(description)
+	if !ok {
+		return nil
+	}
+	plan, err := loadFormulaPlan(info.Formula, info.Vars)
+	if err != nil {
+		style.PrintWarning("convoy %s: %v", convoyID, err)
+		return nil
+	}
+
+	status := make(map[string]string)
+	for _, t := range getTrackedIssues(townBeads, convoyID) {
+		status[t.ID] = t.Status
+	}
+	completed := make(map[string]bool)
+	for stepID, beadID := range info.Beads {
+		if s := status[beadID]; s == "closed" || s == "tombstone" {
+			completed[stepID] = true
+		}
+	}
+
+	var dispatched []string
+	for _, id := range plan.Ready(completed) {
+		beadID, ok := info.Beads[id]
+		if !ok || status[beadID] != "open" {
+			continue // Already slung (hooked/in progress) or not ours
+		}
+		if slingFormulaStep(townBeads, plan.step(id), beadID, info.Rig) {
+			dispatched = append(dispatched, id)
+		}
+	}
+	return dispatched
+}
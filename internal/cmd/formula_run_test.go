@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/formula"
+)
+
+func mustParseFormula(t *testing.T, src string) *formula.Formula {
+	t.Helper()
+	f, err := formula.Parse([]byte(src))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return f
+}
+
+func planIDs(p *formulaPlan) []string {
+	var ids []string
+	for _, s := range p.Steps {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func TestBuildFormulaPlanWorkflow(t *testing.T) {
+	f := mustParseFormula(t, `
+formula = "shiny"
+type = "workflow"
+
+[[steps]]
+id = "test"
+title = "Test {{feature}}"
+needs = ["implement"]
+
+[[steps]]
+id = "design"
+title = "Design {{feature}}"
+
+[[steps]]
+id = "implement"
+title = "Implement {{feature}}"
+needs = ["design"]
+
+[vars.feature]
+required = true
+
+[vars.branch]
+default = "main"
+`)
+
+	if _, err := buildFormulaPlan(f, nil); err == nil || !strings.Contains(err.Error(), "feature") {
+		t.Fatalf("missing required var: err = %v", err)
+	}
+
+	plan, err := buildFormulaPlan(f, map[string]string{"feature": "auth"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := planIDs(plan), []string{"design", "implement", "test"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+	if plan.Steps[0].Title != "Design auth" {
+		t.Errorf("title = %q, want substituted", plan.Steps[0].Title)
+	}
+	if plan.Vars["branch"] != "main" {
+		t.Errorf("default var not applied: %v", plan.Vars)
+	}
+
+	if got := plan.Ready(nil); !reflect.DeepEqual(got, []string{"design"}) {
+		t.Errorf("Ready(nil) = %v", got)
+	}
+	if got := plan.Ready(map[string]bool{"design": true}); !reflect.DeepEqual(got, []string{"implement"}) {
+		t.Errorf("Ready(design) = %v", got)
+	}
+}
+
+func TestBuildFormulaPlanChecksVarTypes(t *testing.T) {
+	f := mustParseFormula(t, `
+formula = "release"
+type = "workflow"
+
+[[steps]]
+id = "bump"
+title = "Bump to {{version}}"
+
+[vars.version]
+required = true
+pattern = "[0-9]+\\.[0-9]+\\.[0-9]+"
+
+[vars.channel]
+enum = ["stable", "beta"]
+default = "stable"
+`)
+
+	if _, err := buildFormulaPlan(f, map[string]string{"version": "next"}); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Fatalf("bad version: err = %v", err)
+	}
+	if _, err := buildFormulaPlan(f, map[string]string{"version": "1.2.3", "channel": "nightly"}); err == nil || !strings.Contains(err.Error(), "channel") {
+		t.Fatalf("bad channel: err = %v", err)
+	}
+	plan, err := buildFormulaPlan(f, map[string]string{"version": "1.2.3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Steps[0].Title != "Bump to 1.2.3" || plan.Vars["channel"] != "stable" {
+		t.Errorf("plan = %+v", plan)
+	}
+}
+
+func TestBuildFormulaPlanExpansion(t *testing.T) {
+	f := mustParseFormula(t, `
+formula = "rule-of-five"
+type = "expansion"
+
+[[template]]
+id = "{target}.draft"
+title = "Draft: {target.title}"
+description = "Shell ${HOME} stays"
+
+[[template]]
+id = "{target}.refine"
+needs = ["{target}.draft"]
+title = "Refine"
+`)
+
+	if _, err := buildFormulaPlan(f, nil); err == nil || !strings.Contains(err.Error(), "target") {
+		t.Fatalf("unresolved step id: err = %v", err)
+	}
+
+	plan, err := buildFormulaPlan(f, map[string]string{"target": "gt-abc", "target.title": "Login"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := planIDs(plan), []string{"gt-abc.draft", "gt-abc.refine"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+	if plan.Steps[0].Title != "Draft: Login" || plan.Steps[0].Description != "Shell ${HOME} stays" {
+		t.Errorf("step = %+v", plan.Steps[0])
+	}
+	if got := plan.Ready(map[string]bool{"gt-abc.draft": true}); !reflect.DeepEqual(got, []string{"gt-abc.refine"}) {
+		t.Errorf("Ready(draft) = %v", got)
+	}
+}
+
+func TestBuildFormulaPlanAspect(t *testing.T) {
+	f := mustParseFormula(t, `
+formula = "audit"
+type = "aspect"
+
+[[aspects]]
+id = "auth"
+title = "Auth"
+focus = "sessions"
+
+[[aspects]]
+id = "deps"
+title = "Dependencies"
+
+[synthesis]
+title = "Audit report"
+`)
+	plan, err := buildFormulaPlan(f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := planIDs(plan), []string{"auth", "deps", synthesisStepID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("steps = %v, want %v", got, want)
+	}
+	if got := plan.Ready(nil); !reflect.DeepEqual(got, []string{"auth", "deps"}) {
+		t.Errorf("Ready(nil) = %v", got)
+	}
+	if got := plan.Ready(map[string]bool{"auth": true}); !reflect.DeepEqual(got, []string{"deps"}) {
+		t.Errorf("Ready(auth) = %v", got)
+	}
+	if got := plan.Ready(map[string]bool{"auth": true, "deps": true}); !reflect.DeepEqual(got, []string{synthesisStepID}) {
+		t.Errorf("Ready(all aspects) = %v", got)
+	}
+}
+
+func TestFormulaConvoyDescriptionRoundTrip(t *testing.T) {
+	plan := &formulaPlan{
+		Name:  "shiny",
+		Type:  formula.TypeWorkflow,
+		Vars:  map[string]string{"feature": "rate limiting"},
+		Steps: []planStep{{ID: "design"}, {ID: "implement", Needs: []string{"design"}}},
+	}
+	desc := formatFormulaConvoyDescription(plan, "gastown", map[string]string{"design": "hq-step-aaaaa", "implement": "hq-step-bbbbb"})
+
+	info, ok := parseFormulaConvoyDescription(desc)
+	if !ok {
+		t.Fatalf("not recognised as a formula convoy:\n%s", desc)
+	}
+	if info.Formula != "shiny" || info.Rig != "gastown" || info.Vars["feature"] != "rate limiting" {
+		t.Errorf("info = %+v", info)
+	}
+	if info.Beads["implement"] != "hq-step-bbbbb" || len(info.Beads) != 2 {
+		t.Errorf("beads = %v", info.Beads)
+	}
+
+	if _, ok := parseFormulaConvoyDescription("Convoy tracking 2 issues\nOwner: mayor/"); ok {
+		t.Error("plain convoy recognised as a formula convoy")
+	}
+}
+
+func TestParseFormulaVars(t *testing.T) {
+	vars, err := parseFormulaVars([]string{"feature=a=b", "empty="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["feature"] != "a=b" || vars["empty"] != "" {
+		t.Errorf("vars = %v", vars)
+	}
+	if _, err := parseFormulaVars([]string{"novalue"}); err == nil {
+		t.Error("expected error for missing '='")
+	}
+}
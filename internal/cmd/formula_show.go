@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// formulaListItem is a formula in gt formula list.
+type formulaListItem struct {
+	formula.Entry
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// runFormulaList lists the formulas on the search path and what they shadow.
+func runFormulaList(cmd *cobra.Command, args []string) error {
+	loader := formulaLoader()
+	entries := loader.List()
+
+	items := make([]formulaListItem, 0, len(entries))
+	for _, e := range entries {
+		item := formulaListItem{Entry: e}
+		if f, err := formula.DecodeFile(e.Path); err != nil {
+			item.Type = "invalid"
+			item.Error = err.Error()
+		} else {
+			item.Type = string(f.Type)
+			item.Description = f.Description
+		}
+		items = append(items, item)
+	}
+
+	if formulaListJSON {
+		out, _ := json.MarshalIndent(items, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(items) == 0 {
+		fmt.Println(style.Dim.Render("No formulas found. Searched:"))
+		for _, d := range loader.Dirs {
+			fmt.Printf("  %-8s %s\n", d.Layer, d.Dir)
+		}
+		return nil
+	}
+
+	fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("Formulas (%d)", len(items))))
+	for _, item := range items {
+		typ := item.Type
+		if item.Error != "" {
+			typ = style.Error.Render(typ)
+		}
+		fmt.Printf("  %-32s %-10s %-8s %s\n", item.Name, typ, item.Layer, style.Dim.Render(firstLine(item.Description, 60)))
+		for _, sh := range item.Shadows {
+			fmt.Printf("    %s\n", style.Dim.Render(fmt.Sprintf("shadows %s %s", sh.Layer, sh.Path)))
+		}
+	}
+	return nil
+}
+
+// firstLine returns the first line of s, cut to max runes.
+func firstLine(s string, max int) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	if r := []rune(s); len(r) > max {
+		return string(r[:max-3]) + "..."
+	}
+	return s
+}
+
+// formulaItem is a step, leg, template or aspect of a formula.
+type formulaItem struct {
+	ID    string   `json:"id"`
+	Title string   `json:"title,omitempty"`
+	Needs []string `json:"needs,omitempty"`
+}
+
+// formulaVarInfo is a declared variable or input of a formula.
+type formulaVarInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+}
+
+// formulaShowOutput is gt formula show --json.
+type formulaShowOutput struct {
+	formula.Source
+	Shadows     []formula.Source   `json:"shadows,omitempty"`
+	Type        string             `json:"type"`
+	Description string             `json:"description,omitempty"`
+	Extends     []string           `json:"extends,omitempty"`
+	Compose     *formula.Compose   `json:"compose,omitempty"`
+	Vars        []formulaVarInfo   `json:"vars,omitempty"`
+	Steps       []formulaItem      `json:"steps"`
+	Synthesis   *formula.Synthesis `json:"synthesis,omitempty"`
+}
+
+// formulaItems returns the units of work of a formula, in file order.
+func formulaItems(f *formula.Formula) []formulaItem {
+	var items []formulaItem
+	switch f.Type {
+	case formula.TypeWorkflow:
+		for _, s := range f.Steps {
+			items = append(items, formulaItem{ID: s.ID, Title: s.Title, Needs: s.Needs})
+		}
+	case formula.TypeExpansion:
+		for _, t := range f.Template {
+			items = append(items, formulaItem{ID: t.ID, Title: t.Title, Needs: t.Needs})
+		}
+	case formula.TypeConvoy:
+		for _, l := range f.Legs {
+			items = append(items, formulaItem{ID: l.ID, Title: l.Title})
+		}
+	case formula.TypeAspect:
+		for _, a := range f.Aspects {
+			items = append(items, formulaItem{ID: a.ID, Title: a.Title})
+		}
+	}
+	return items
+}
+
+// formulaVarInfos returns a formula's vars and inputs, sorted by name.
+func formulaVarInfos(f *formula.Formula) []formulaVarInfo {
+	var vars []formulaVarInfo
+	for name, v := range f.Vars {
+		vars = append(vars, formulaVarInfo{Name: name, Description: v.Description, Type: v.Type,
+			Required: v.Required, Default: v.Default, Enum: v.Enum, Pattern: v.Pattern})
+	}
+	for name, in := range f.Inputs {
+		vars = append(vars, formulaVarInfo{Name: name, Description: in.Description, Type: in.Type,
+			Required: in.Required, Default: in.Default})
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
+}
+
+// runFormulaShow shows a formula with its composition applied.
+func runFormulaShow(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	loader := formulaLoader()
+	sources := loader.Sources(name)
+	if len(sources) == 0 {
+		return fmt.Errorf("formula '%s' not found in search paths", name)
+	}
+	f, err := loader.LoadFile(sources[0].Path)
+	if err != nil {
+		return fmt.Errorf("loading formula %s: %w\n(run 'gt formula lint %s' for details)", name, err, name)
+	}
+	raw, err := formula.DecodeFile(sources[0].Path)
+	if err != nil {
+		return err
+	}
+
+	out := formulaShowOutput{
+		Source:      sources[0],
+		Shadows:     sources[1:],
+		Type:        string(f.Type),
+		Description: f.Description,
+		Extends:     raw.Extends,
+		Compose:     raw.Compose,
+		Vars:        formulaVarInfos(f),
+		Steps:       formulaItems(f),
+		Synthesis:   f.Synthesis,
+	}
+
+	if formulaShowJSON {
+		data, _ := json.MarshalIndent(out, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("%s %s\n", style.Bold.Render(f.Name), style.Dim.Render("("+out.Type+")"))
+	if out.Description != "" {
+		fmt.Printf("  %s\n", strings.ReplaceAll(strings.TrimSpace(out.Description), "\n", "\n  "))
+	}
+	fmt.Printf("\nSource: %s %s\n", out.Layer, out.Path)
+	for _, sh := range out.Shadows {
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("shadows %s %s", sh.Layer, sh.Path)))
+	}
+
+	if len(out.Extends) > 0 || out.Compose != nil {
+		fmt.Printf("\n%s\n", style.Bold.Render("Composition:"))
+		if len(out.Extends) > 0 {
+			fmt.Printf("  extends %s\n", strings.Join(out.Extends, ", "))
+		}
+		if out.Compose != nil {
+			for _, rule := range out.Compose.Expand {
+				fmt.Printf("  expands %s with %s\n", rule.Target, rule.With)
+			}
+			for _, a := range out.Compose.Aspects {
+				fmt.Printf("  applies aspect %s\n", a)
+			}
+		}
+	}
+
+	if len(out.Vars) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("Variables:"))
+		for _, v := range out.Vars {
+			var attrs []string
+			if v.Type != "" {
+				attrs = append(attrs, v.Type)
+			}
+			if v.Required {
+				attrs = append(attrs, "required")
+			}
+			if v.Default != "" {
+				attrs = append(attrs, "default="+v.Default)
+			}
+			if len(v.Enum) > 0 {
+				attrs = append(attrs, "one of "+strings.Join(v.Enum, "|"))
+			}
+			if v.Pattern != "" {
+				attrs = append(attrs, "matches "+v.Pattern)
+			}
+			fmt.Printf("  %-20s %s %s\n", v.Name, v.Description, style.Dim.Render(strings.Join(attrs, ", ")))
+		}
+	}
+
+	label := map[formula.FormulaType]string{
+		formula.TypeWorkflow:  "Steps",
+		formula.TypeExpansion: "Templates",
+		formula.TypeConvoy:    "Legs",
+		formula.TypeAspect:    "Aspects",
+	}[f.Type]
+	if len(out.Steps) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render(fmt.Sprintf("%s (%d):", label, len(out.Steps))))
+		for _, item := range out.Steps {
+			line := fmt.Sprintf("  %-32s %s", item.ID, item.Title)
+			if len(item.Needs) > 0 {
+				line += " " + style.Dim.Render("← "+strings.Join(item.Needs, ", "))
+			}
+			fmt.Println(line)
+		}
+	}
+	if out.Synthesis != nil {
+		fmt.Printf("\n%s %s\n", style.Bold.Render("Synthesis:"), out.Synthesis.Title)
+	}
+	if len(f.Advice) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render(fmt.Sprintf("Advice (%d):", len(f.Advice))))
+		for _, adv := range f.Advice {
+			target := adv.Target
+			if target == "" {
+				target = "pointcuts"
+			}
+			fmt.Printf("  around %s\n", target)
+		}
+	}
+	return nil
+}
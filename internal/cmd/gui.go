@@ -28,6 +28,10 @@ The GUI provides:
 - Command execution interface
 - Auto-refresh every 30 seconds
 
+Access is limited to localhost unless GT_WEB_ALLOW_REMOTE=1. Set
+GT_WEB_AUTH_TOKEN for a shared token, or add named users with roles
+(viewer, operator, admin) with 'gt gui user'.
+
 Example:
   gt gui              # Start on default port 8080
   gt gui --port 3000  # Start on port 3000
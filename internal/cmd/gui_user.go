@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/web"
+	"github.com/steveyegge/gastown/internal/workspace"
+	"golang.org/x/term"
+)
+
+var (
+	guiUserRole          string
+	guiUserPasswordStdin bool
+	guiUserListJSON      bool
+)
+
+var guiUserCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Manage web GUI users and roles",
+	Long: `Manage the named accounts that can sign in to the web GUI.
+
+Users are kept in an htpasswd-style file (mayor/web-users in the town, or
+$GT_WEB_USERS_FILE), one per line as name:bcrypt-hash:role. Once the file has
+a user, the GUI requires a login; GT_WEB_AUTH_TOKEN keeps working as an admin.
+
+Roles:
+  viewer    See every page, status view and stream
+  operator  Also send to terminals, run crew actions and commands, send mail
+  admin     Also change accounts, config and prompts
+
+Every state-changing request is recorded in the town's audit log with the
+user who made it. Changes to the file apply without restarting gt gui.`,
+	RunE: requireSubcommand,
+}
+
+var guiUserAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a user or change their password and role",
+	Long: `Add a web GUI user, or replace an existing user's password and role.
+
+The password is prompted for, or read from stdin with --password-stdin.
+
+Examples:
+  gt gui user add alice --role admin
+  gt gui user add bob                     # viewer
+  echo "$PW" | gt gui user add ci --role operator --password-stdin`,
+	Args: cobra.ExactArgs(1),
+	RunE: runGUIUserAdd,
+}
+
+var guiUserRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a user",
+	Long: `Remove a web GUI user. Their sessions end immediately.
+
+Examples:
+  gt gui user remove bob`,
+	Args: cobra.ExactArgs(1),
+	RunE: runGUIUserRemove,
+}
+
+var guiUserListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List users and their roles",
+	Long: `List web GUI users and their roles.
+
+Examples:
+  gt gui user list
+  gt gui user list --json`,
+	RunE: runGUIUserList,
+}
+
+func init() {
+	guiUserAddCmd.Flags().StringVar(&guiUserRole, "role", string(web.RoleViewer), "Role: viewer, operator or admin")
+	guiUserAddCmd.Flags().BoolVar(&guiUserPasswordStdin, "password-stdin", false, "Read the password from stdin")
+	guiUserListCmd.Flags().BoolVar(&guiUserListJSON, "json", false, "Output as JSON")
+
+	guiUserCmd.AddCommand(guiUserAddCmd)
+	guiUserCmd.AddCommand(guiUserRemoveCmd)
+	guiUserCmd.AddCommand(guiUserListCmd)
+	guiCmd.AddCommand(guiUserCmd)
+}
+
+// guiUsersFile returns the users file for the current town.
+func guiUsersFile() (string, error) {
+	if path := os.Getenv("GT_WEB_USERS_FILE"); path != "" {
+		return path, nil
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return "", fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	return web.UsersFile(townRoot), nil
+}
+
+func runGUIUserAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	role, err := web.ParseRole(guiUserRole)
+	if err != nil {
+		return err
+	}
+	path, err := guiUsersFile()
+	if err != nil {
+		return err
+	}
+
+	password, err := readNewPassword(name)
+	if err != nil {
+		return err
+	}
+	if err := web.SetUser(path, name, password, role); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s User %s saved with role %s\n", style.Bold.Render("✓"), name, role)
+	fmt.Printf("  %s\n", style.Dim.Render(path))
+	return nil
+}
+
+// readNewPassword reads a password from stdin or prompts for it twice.
+func readNewPassword(name string) (string, error) {
+	if guiUserPasswordStdin {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("reading password from stdin: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("no terminal to prompt for a password (use --password-stdin)")
+	}
+	fmt.Printf("Password for %s: ", name)
+	first, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	fmt.Print("Repeat password: ")
+	second, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	if string(first) != string(second) {
+		return "", fmt.Errorf("passwords do not match")
+	}
+	return string(first), nil
+}
+
+func runGUIUserRemove(cmd *cobra.Command, args []string) error {
+	path, err := guiUsersFile()
+	if err != nil {
+		return err
+	}
+	removed, err := web.RemoveUser(path, args[0])
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("no user %q in %s", args[0], path)
+	}
+	fmt.Printf("%s User %s removed\n", style.Bold.Render("✓"), args[0])
+	return nil
+}
+
+func runGUIUserList(cmd *cobra.Command, args []string) error {
+	path, err := guiUsersFile()
+	if err != nil {
+		return err
+	}
+	users, err := web.ListUsers(path)
+	if err != nil {
+		style.PrintWarning("%v", err)
+	}
+
+	if guiUserListJSON {
+		out, _ := json.MarshalIndent(users, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(users) == 0 {
+		fmt.Printf("No web users (%s). The GUI uses GT_WEB_AUTH_TOKEN or localhost access.\n", path)
+		return nil
+	}
+	fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("Web users (%d)", len(users))))
+	for _, u := range users {
+		fmt.Printf("  %-24s %s\n", u.Name, u.Role)
+	}
+	fmt.Printf("\n%s\n", style.Dim.Render(path))
+	return nil
+}
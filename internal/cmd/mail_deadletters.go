@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Dead-letters command flags
+var (
+	deadLettersJSON    bool
+	deadLettersRequeue string
+	deadLettersDrop    string
+)
+
+var mailDeadLettersCmd = &cobra.Command{
+	Use:   "dead-letters <rig>",
+	Short: "Show protocol messages no agent could handle",
+	Long: `Show the protocol messages a rig's witness or refinery couldn't handle.
+
+Protocol messages (POLECAT_DONE, MERGE_READY, MERGED, MERGE_FAILED,
+REWORK_REQUEST) carry a versioned envelope. A message whose envelope can't
+be parsed, or whose version the receiving agent has no handler for (e.g.
+sent by a newer gt), is moved to the rig's dead-letter queue in
+<rig>/.runtime/protocol instead of being retried forever or misread.
+
+Once the receiving agent has been upgraded, --requeue sends a message again.
+
+Examples:
+  gt mail dead-letters gastown
+  gt mail dead-letters gastown --json
+  gt mail dead-letters gastown --requeue msg-1a2b3c4d5e6f7a8b
+  gt mail dead-letters gastown --drop msg-1a2b3c4d5e6f7a8b`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMailDeadLetters,
+}
+
+func init() {
+	mailDeadLettersCmd.Flags().BoolVar(&deadLettersJSON, "json", false, "Output as JSON")
+	mailDeadLettersCmd.Flags().StringVar(&deadLettersRequeue, "requeue", "", "Send a dead letter to its recipient again")
+	mailDeadLettersCmd.Flags().StringVar(&deadLettersDrop, "drop", "", "Remove a dead letter without sending it")
+
+	mailCmd.AddCommand(mailDeadLettersCmd)
+}
+
+func runMailDeadLetters(cmd *cobra.Command, args []string) error {
+	townRoot, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+	queue := protocol.NewDeadLetterQueue(protocol.DeadLetterPath(r.Path))
+
+	switch {
+	case deadLettersRequeue != "":
+		letter, err := queue.Get(deadLettersRequeue)
+		if err != nil {
+			return err
+		}
+		if err := mail.NewRouter(townRoot).Send(letter.Message()); err != nil {
+			return fmt.Errorf("requeueing %s: %w", deadLettersRequeue, err)
+		}
+		if err := queue.Remove(deadLettersRequeue); err != nil {
+			return err
+		}
+		fmt.Printf("%s Requeued %s to %s\n", style.Bold.Render("✓"), deadLettersRequeue, letter.To)
+		return nil
+	case deadLettersDrop != "":
+		if err := queue.Remove(deadLettersDrop); err != nil {
+			return err
+		}
+		fmt.Printf("%s Dropped %s\n", style.Bold.Render("✓"), deadLettersDrop)
+		return nil
+	}
+
+	letters, err := queue.List()
+	if err != nil {
+		return err
+	}
+	if deadLettersJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(letters)
+	}
+	if len(letters) == 0 {
+		fmt.Printf("No dead letters in %s\n", args[0])
+		return nil
+	}
+	fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("Dead letters in %s (%d)", args[0], len(letters))))
+	for _, d := range letters {
+		fmt.Printf("  %s %s %s\n", style.Bold.Render(d.ID), d.Subject, style.Dim.Render(d.From+" → "+d.To))
+		fmt.Printf("    %s %s\n", style.Dim.Render(d.Received.Local().Format("2006-01-02 15:04")), style.Error.Render(d.Reason))
+	}
+	return nil
+}
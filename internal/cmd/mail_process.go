@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var mailProcessCmd = &cobra.Command{
+	Use:   "process [<rig>/witness|<rig>/refinery]",
+	Short: "Handle the protocol messages in a witness or refinery inbox",
+	Long: `Handle the protocol messages in a rig witness's or refinery's inbox.
+
+The witness handles POLECAT_DONE, MERGED, MERGE_FAILED and REWORK_REQUEST;
+the refinery handles MERGE_READY. Each message is handled once, however
+often it is delivered: the IDs of handled messages are kept in a ledger in
+<rig>/.runtime/protocol. The witness's handled messages are archived; the
+refinery keeps MERGE_READY until the branch is merged. A message that
+can't be parsed, or has a version this gt has no handler for, is moved to
+the dead-letter queue (see gt mail dead-letters) and archived. A message
+whose handler fails stays in the inbox for the next run.
+
+Other mail is left in the inbox to be read as usual.
+
+Without an address, the inbox of the current agent is processed.
+
+Examples:
+  gt mail process
+  gt mail process gastown/witness`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMailProcess,
+}
+
+func init() {
+	mailCmd.AddCommand(mailProcessCmd)
+}
+
+func runMailProcess(cmd *cobra.Command, args []string) error {
+	address := detectSender()
+	if len(args) > 0 {
+		address = args[0]
+	}
+	rigName, agent, ok := strings.Cut(strings.TrimSuffix(address, "/"), "/")
+	if !ok || (agent != "witness" && agent != "refinery") {
+		return fmt.Errorf("%s is not a witness or refinery address", address)
+	}
+
+	_, r, err := getRig(rigName)
+	if err != nil {
+		return err
+	}
+	mailbox, err := getMailbox(address)
+	if err != nil {
+		return err
+	}
+	return processProtocolInbox(r.Name, r.Path, agent, mailbox)
+}
+
+// processProtocolInbox runs an agent's inbox through its protocol registry
+// and reports what happened to each message.
+func processProtocolInbox(rigName, rigPath, agent string, mailbox *mail.Mailbox) error {
+	registry := protocol.NewRefineryRegistry(rigName, rigPath)
+	if agent == "witness" {
+		registry = protocol.NewWitnessRegistry(rigName, rigPath)
+	}
+
+	results, err := registry.ProcessInbox(mailbox, agent == "witness")
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Printf("No protocol messages for %s/%s\n", rigName, agent)
+		return nil
+	}
+
+	failed := 0
+	for _, res := range results {
+		switch {
+		case res.Err == nil:
+			fmt.Printf("%s %s\n", style.Bold.Render("✓"), res.Message.Subject)
+		case errors.Is(res.Err, protocol.ErrDeadLettered):
+			fmt.Printf("%s %s: %v\n", style.Warning.Render("⚠"), res.Message.Subject, res.Err)
+		default:
+			failed++
+			fmt.Printf("%s %s: %v\n", style.Error.Render("✗"), res.Message.Subject, res.Err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d protocol message(s) failed and stay in the inbox", failed)
+	}
+	return nil
+}
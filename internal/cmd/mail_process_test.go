@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
+	"github.com/steveyegge/gastown/internal/protocol/wire"
+)
+
+func TestProcessProtocolInbox_Witness(t *testing.T) {
+	townRoot := setupTestTownForCrewList(t, map[string][]string{"gastown": nil})
+	rigPath := filepath.Join(townRoot, "gastown")
+
+	// bd records the mail the witness sends
+	bin := t.TempDir()
+	bdLog := filepath.Join(bin, "bd.log")
+	writeScript(t, bin, "bd", "#!/bin/sh\necho \"$*\" >> "+bdLog+"\n")
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// A MERGE_FAILED delivered twice, a malformed MERGED and ordinary mail
+	failed := protocol.NewMergeFailedMessage("gastown", "nux", "polecat/nux", "gt-1", "main", "tests", "boom")
+	redelivered := *failed
+	redelivered.ID = "msg-redelivered"
+	malformed := &mail.Message{
+		ID:      "msg-malformed",
+		From:    "gastown/refinery",
+		To:      "gastown/witness",
+		Subject: "MERGED nux",
+		Body:    "Branch: polecat/nux\n\n" + wire.Marker + "\n{not json\n",
+	}
+	help := &mail.Message{ID: "msg-help", From: "gastown/nux", To: "gastown/witness", Subject: "HELP: stuck"}
+	mailbox := mail.NewMailbox(t.TempDir())
+	for _, msg := range []*mail.Message{failed, &redelivered, malformed, help} {
+		if err := mailbox.Append(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	if err := os.Chdir(townRoot); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = processProtocolInbox("gastown", rigPath, "witness", mailbox)
+	})
+	if err != nil {
+		t.Fatalf("processProtocolInbox() error = %v\n%s", err, out)
+	}
+
+	// The polecat is told about the failure once
+	data, _ := os.ReadFile(bdLog)
+	if got := strings.Count(string(data), "create Merge failed"); got != 1 {
+		t.Errorf("polecat notified %d times, want once; bd calls:\n%s", got, data)
+	}
+
+	remaining, err := mailbox.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 1 || remaining[0].ID != "msg-help" {
+		t.Errorf("inbox after processing = %+v, want only the HELP mail", remaining)
+	}
+
+	out = captureStdout(t, func() {
+		err = runMailDeadLetters(&cobra.Command{}, []string{"gastown"})
+	})
+	if err != nil {
+		t.Fatalf("runMailDeadLetters() error = %v", err)
+	}
+	if !strings.Contains(out, "msg-malformed") || !strings.Contains(out, "Dead letters in gastown (1)") {
+		t.Errorf("gt mail dead-letters output:\n%s", out)
+	}
+}
@@ -5,13 +5,13 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
 	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/style"
@@ -583,19 +583,13 @@ func runMqMerged(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	witnessMsg := &mail.Message{
-		From:    fmt.Sprintf("%s/refinery", rigName),
-		To:      witnessAddr,
-		Subject: fmt.Sprintf("MERGED %s", polecat),
-		Body: fmt.Sprintf(`Branch: %s
-Issue: %s
-Merged-At: %s
-Commit: %s`,
-			branch,
-			mr.ID,
-			time.Now().UTC().Format(time.RFC3339),
-			shortSHA),
+	target := ""
+	if mrFields != nil {
+		target = mrFields.Target
 	}
+	// The full merge commit lets the witness verify squash merges, where the
+	// polecat's own commits never reach the target branch
+	witnessMsg := protocol.NewMergedMessage(rigName, polecat, branch, mr.ID, target, commitSHA)
 	if err := router.Send(witnessMsg); err != nil {
 		fmt.Printf("  %s Failed to notify witness: %v\n", style.Bold.Render("⚠"), err)
 	} else {
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ flaky command flags
+var (
+	mqFlakyJSON   bool
+	mqFlakyForget string
+)
+
+var mqFlakyCmd = &cobra.Command{
+	Use:   "flaky <rig>",
+	Short: "Show the merge queue's flaky tests",
+	Long: `Show the tests that failed and then passed on retry in a rig's merge queue.
+
+The refinery records a flake when a check with a test report format
+(merge_queue.checks[].format) fails a test that passes when the check is
+retried. Checks without a format are recorded as a whole. Once a test has
+flaked merge_queue.flaky_threshold times (3 by default) the refinery files
+a bug bead for it; with quarantine_flaky its failures stop blocking merges
+until that bead is closed.
+
+Examples:
+  gt mq flaky gastown
+  gt mq flaky gastown --json
+  gt mq flaky gastown --forget "unit github.com/x/pkg.TestRace"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQFlaky,
+}
+
+func init() {
+	mqFlakyCmd.Flags().BoolVar(&mqFlakyJSON, "json", false, "Output as JSON")
+	mqFlakyCmd.Flags().StringVar(&mqFlakyForget, "forget", "", "Remove an entry from the ledger (by its key, see --json)")
+
+	mqCmd.AddCommand(mqFlakyCmd)
+}
+
+func runMQFlaky(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+
+	if mqFlakyForget != "" {
+		if err := refinery.ForgetFlakyTest(r.Path, mqFlakyForget); err != nil {
+			return err
+		}
+		fmt.Printf("%s Forgot flaky test %s\n", style.Bold.Render("✓"), mqFlakyForget)
+		return nil
+	}
+
+	ledger, err := refinery.LoadFlakyLedger(r.Path)
+	if err != nil {
+		return err
+	}
+
+	if mqFlakyJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(ledger.Tests)
+	}
+
+	tests := ledger.Sorted()
+	if len(tests) == 0 {
+		fmt.Printf("No flaky tests recorded in %s\n", args[0])
+		return nil
+	}
+	fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("Flaky tests in %s (%d)", args[0], len(tests))))
+	for _, t := range tests {
+		line := fmt.Sprintf("  %3d× %s %s", t.Count, t.Name(), style.Dim.Render("["+t.Check+"]"))
+		if t.Bead != "" {
+			line += " " + style.Warning.Render(t.Bead)
+		}
+		fmt.Println(line)
+		detail := "last seen " + t.LastSeen.Local().Format("2006-01-02 15:04")
+		if len(t.MRs) > 0 {
+			detail += ", MRs: " + strings.Join(t.MRs, ", ")
+		}
+		fmt.Printf("        %s\n", style.Dim.Render(detail))
+	}
+	return nil
+}
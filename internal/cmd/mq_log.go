@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// MQ log command flags
+var (
+	mqLogRig   string
+	mqLogCheck string
+	mqLogRun   string
+	mqLogAll   bool
+	mqLogList  bool
+)
+
+var mqLogCmd = &cobra.Command{
+	Use:   "log <mr-id>",
+	Short: "Show the full output of a merge request's checks",
+	Long: `Show the output the refinery saved when it ran a merge request's checks.
+
+The refinery keeps the full output of every check attempt under
+<rig>/.runtime/check-logs for merge_queue.log_retention (7 days by
+default). The MR bead and the MERGE_FAILED mail only carry an excerpt.
+
+By default the latest run's failed checks are shown (their final attempt),
+or every check when none failed. The rig is found from the MR's logs; use
+--rig when several rigs have logs for the same ID.
+
+Examples:
+  gt mq log gt-mr-abc123                    # Failed checks of the latest run
+  gt mq log gt-mr-abc123 --check unit       # Every attempt of one check
+  gt mq log gt-mr-abc123 --all              # Every check of the latest run
+  gt mq log gt-mr-abc123 --list             # List saved runs and logs
+  gt mq log gt-mr-abc123 --run 20260114T093012.345Z`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQLog,
+}
+
+func init() {
+	mqLogCmd.Flags().StringVar(&mqLogRig, "rig", "", "Rig the MR belongs to (default: search all rigs)")
+	mqLogCmd.Flags().StringVar(&mqLogCheck, "check", "", "Show every attempt of this check")
+	mqLogCmd.Flags().StringVar(&mqLogRun, "run", "", "Show this run instead of the latest (see --list)")
+	mqLogCmd.Flags().BoolVar(&mqLogAll, "all", false, "Show every check, not just failed ones")
+	mqLogCmd.Flags().BoolVar(&mqLogList, "list", false, "List saved runs instead of showing logs")
+
+	mqCmd.AddCommand(mqLogCmd)
+}
+
+func runMQLog(cmd *cobra.Command, args []string) error {
+	mrID := args[0]
+
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	runs, err := findCheckRuns(townRoot, mrID, mqLogRig)
+	if err != nil {
+		return err
+	}
+
+	if mqLogList {
+		for _, run := range runs {
+			fmt.Printf("%s  %s\n", style.Bold.Render(run.ID), style.Dim.Render(run.Time.Local().Format("2006-01-02 15:04:05")))
+			for _, log := range run.Logs {
+				fmt.Printf("  %s %s (attempt %d)\n", checkStatusIcon(log.Status), log.Check, log.Attempt)
+			}
+		}
+		return nil
+	}
+
+	run := runs[0]
+	if mqLogRun != "" {
+		found := false
+		for _, r := range runs {
+			if r.ID == mqLogRun {
+				run, found = r, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("no run %s for %s (see gt mq log %s --list)", mqLogRun, mrID, mrID)
+		}
+	}
+
+	logs := selectCheckLogs(run, mqLogCheck, mqLogAll)
+	if len(logs) == 0 {
+		if mqLogCheck != "" {
+			return fmt.Errorf("check %q did not run in %s", mqLogCheck, run.ID)
+		}
+		return fmt.Errorf("run %s has no logs", run.ID)
+	}
+	for i, log := range logs {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s %s\n", checkStatusIcon(log.Status),
+			style.Bold.Render(fmt.Sprintf("%s (attempt %d, %s)", log.Check, log.Attempt, log.Status)))
+		if err := printFile(os.Stdout, log.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findCheckRuns returns the saved check runs of an MR, looking in rigName
+// or in every rig of the town.
+func findCheckRuns(townRoot, mrID, rigName string) ([]refinery.CheckRun, error) {
+	var rigNames []string
+	if rigName != "" {
+		rigNames = []string{rigName}
+	} else {
+		rigsConfig, err := config.LoadRigsConfig(constants.MayorRigsPath(townRoot))
+		if err != nil {
+			return nil, fmt.Errorf("loading rigs config: %w", err)
+		}
+		for name := range rigsConfig.Rigs {
+			rigNames = append(rigNames, name)
+		}
+		sort.Strings(rigNames)
+	}
+
+	var found []refinery.CheckRun
+	var foundIn []string
+	for _, name := range rigNames {
+		runs, err := refinery.ListCheckRuns(filepath.Join(townRoot, name), mrID)
+		if err != nil {
+			return nil, fmt.Errorf("reading check logs in %s: %w", name, err)
+		}
+		if len(runs) > 0 {
+			found = runs
+			foundIn = append(foundIn, name)
+		}
+	}
+	switch {
+	case len(foundIn) == 0:
+		return nil, fmt.Errorf("no check logs for %s (logs are kept for merge_queue.log_retention)", mrID)
+	case len(foundIn) > 1:
+		return nil, fmt.Errorf("%s has check logs in several rigs (%v): use --rig", mrID, foundIn)
+	}
+	return found, nil
+}
+
+// selectCheckLogs picks the logs to show from a run: every attempt of one
+// check, or the final attempt of the failed checks (all checks with all,
+// or when none failed).
+func selectCheckLogs(run refinery.CheckRun, check string, all bool) []refinery.CheckLog {
+	if check != "" {
+		var logs []refinery.CheckLog
+		for _, log := range run.Logs {
+			if log.Check == check {
+				logs = append(logs, log)
+			}
+		}
+		return logs
+	}
+
+	last := run.LastAttempts()
+	if all {
+		return last
+	}
+	var failed []refinery.CheckLog
+	for _, log := range last {
+		if log.Status != beads.MRCheckPassed {
+			failed = append(failed, log)
+		}
+	}
+	if len(failed) == 0 {
+		return last
+	}
+	return failed
+}
+
+// printFile copies a file to w.
+func printFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
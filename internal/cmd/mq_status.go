@@ -34,11 +34,23 @@ type MRStatusOutput struct {
 	MergeCommit string `json:"merge_commit,omitempty"`
 	CloseReason string `json:"close_reason,omitempty"`
 
+	// Pre-merge check results from the last refinery run
+	Checks []MRCheckOutput `json:"checks,omitempty"`
+
 	// Dependencies
 	DependsOn []DependencyInfo `json:"depends_on,omitempty"`
 	Blocks    []DependencyInfo `json:"blocks,omitempty"`
 }
 
+// MRCheckOutput is the result of one pre-merge check.
+type MRCheckOutput struct {
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	Duration string   `json:"duration,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+	LogTail  []string `json:"log_tail,omitempty"`
+}
+
 // DependencyInfo represents a dependency or blocker.
 type DependencyInfo struct {
 	ID       string `json:"id"`
@@ -96,6 +108,13 @@ func runMqStatus(cmd *cobra.Command, args []string) error {
 		output.MergeCommit = mrFields.MergeCommit
 		output.CloseReason = mrFields.CloseReason
 	}
+	for _, c := range beads.ParseMRChecks(issue.Description) {
+		out := MRCheckOutput{Name: c.Name, Status: c.Status, Detail: c.Detail, LogTail: c.LogTail}
+		if c.Duration > 0 {
+			out.Duration = c.Duration.String()
+		}
+		output.Checks = append(output.Checks, out)
+	}
 
 	// Add dependency info from the issue's Dependencies field
 	for _, dep := range issue.Dependencies {
@@ -186,6 +205,31 @@ func printMqStatus(issue *beads.Issue, mrFields *beads.MRFields) error {
 		}
 	}
 
+	// Pre-merge checks, with the log tail of any that failed
+	if checks := beads.ParseMRChecks(issue.Description); len(checks) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("Checks"))
+		failed := false
+		for _, c := range checks {
+			line := fmt.Sprintf("   %s %-12s %s", checkStatusIcon(c.Status), c.Name, c.Status)
+			if c.Duration > 0 {
+				line += " " + style.Dim.Render(c.Duration.String())
+			}
+			if c.Detail != "" {
+				line += " " + style.Dim.Render("("+c.Detail+")")
+			}
+			fmt.Println(line)
+			for _, l := range c.LogTail {
+				fmt.Printf("       %s\n", style.Dim.Render(l))
+			}
+			if c.Status == beads.MRCheckFailed || c.Status == beads.MRCheckTimeout {
+				failed = true
+			}
+		}
+		if failed {
+			fmt.Printf("   %s\n", style.Dim.Render("Full log: gt mq log "+issue.ID))
+		}
+	}
+
 	// Dependencies (what this MR is waiting on)
 	if len(issue.Dependencies) > 0 {
 		fmt.Printf("\n%s\n", style.Bold.Render("Waiting On"))
@@ -239,6 +283,18 @@ func formatStatus(status string) string {
 	}
 }
 
+// checkStatusIcon returns an icon for a pre-merge check status.
+func checkStatusIcon(status string) string {
+	switch status {
+	case beads.MRCheckSkipped:
+		return style.Dim.Render("-")
+	case beads.MRCheckFailed, beads.MRCheckTimeout:
+		return style.Error.Render("✗")
+	default:
+		return style.Success.Render("✓")
+	}
+}
+
 // getStatusIcon returns an icon for the given status.
 func getStatusIcon(status string) string {
 	switch status {
@@ -330,6 +386,9 @@ func getDescriptionWithoutMRFields(description string) string {
 		"type":         true,
 	}
 
+	// Check results have their own section
+	description = beads.SetMRChecks(description, nil)
+
 	var lines []string
 	for _, line := range strings.Split(description, "\n") {
 		trimmed := strings.TrimSpace(line)
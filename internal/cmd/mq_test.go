@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
 )
 
 func TestAddIntegrationBranchField(t *testing.T) {
@@ -179,6 +182,11 @@ func TestGetDescriptionWithoutMRFields(t *testing.T) {
 			description: "Just a regular description\nWith multiple lines",
 			want:        "Just a regular description\nWith multiple lines",
 		},
+		{
+			name:        "check results",
+			description: "branch: polecat/Nux/gt-xyz\nSome custom notes\n\ncheck unit: failed in 3s (exit status 1)\n| --- FAIL: TestX",
+			want:        "Some custom notes",
+		},
 	}
 
 	for _, tt := range tests {
@@ -740,3 +748,41 @@ func TestPolecatCleanupTimeoutConstant(t *testing.T) {
 		t.Errorf("expectedMaxCleanupWait = %v, want 5m", expectedMaxCleanupWait)
 	}
 }
+
+func TestFindCheckRuns(t *testing.T) {
+	town := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(town, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	rigs := `{"version": 1, "rigs": {"gastown": {}, "beads": {}}}`
+	if err := os.WriteFile(filepath.Join(town, "mayor", "rigs.json"), []byte(rigs), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run := filepath.Join(refinery.CheckLogsDir(filepath.Join(town, "gastown")), "gt-mr1", "20260114T093012.345Z")
+	if err := os.MkdirAll(run, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"lint.1.passed.log", "unit.1.failed.log", "unit.2.failed.log"} {
+		if err := os.WriteFile(filepath.Join(run, name), []byte(name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := findCheckRuns(town, "gt-mr1", "")
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("findCheckRuns() = %v, %v", runs, err)
+	}
+	if _, err := findCheckRuns(town, "gt-mr2", ""); err == nil {
+		t.Error("expected an error for an MR without logs")
+	}
+
+	if logs := selectCheckLogs(runs[0], "", false); len(logs) != 1 || logs[0].Check != "unit" || logs[0].Attempt != 2 {
+		t.Errorf("failed logs = %+v", logs)
+	}
+	if logs := selectCheckLogs(runs[0], "", true); len(logs) != 2 {
+		t.Errorf("all logs = %+v", logs)
+	}
+	if logs := selectCheckLogs(runs[0], "unit", false); len(logs) != 2 || logs[0].Attempt != 1 {
+		t.Errorf("unit logs = %+v", logs)
+	}
+}
@@ -468,7 +468,12 @@ func outputCheckpointContext(ctx RoleContext) {
 	// Display checkpoint context
 	fmt.Println()
 	fmt.Printf("%s\n\n", style.Bold.Render("## 📌 Previous Session Checkpoint"))
-	fmt.Printf("A previous session left a checkpoint %s ago.\n\n", cp.Age().Round(time.Minute))
+	if cp.RestoredFrom != "" {
+		fmt.Printf("This work was restored from %s, whose session crashed. Its uncommitted\n", cp.RestoredFrom)
+		fmt.Printf("changes have been applied to this worktree; review them with git status.\n\n")
+	} else {
+		fmt.Printf("A previous session left a checkpoint %s ago.\n\n", cp.Age().Round(time.Minute))
+	}
 
 	if cp.StepTitle != "" {
 		fmt.Printf("  **Working on:** %s\n", cp.StepTitle)
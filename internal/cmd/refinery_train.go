@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Refinery train flags
+var (
+	refineryTrainMax int
+)
+
+var refineryTrainCmd = &cobra.Command{
+	Use:   "train [rig]",
+	Short: "Process ready MRs as a speculative merge train",
+	Long: `Process up to max_concurrent ready MRs as one speculative merge train.
+
+The top MRs by priority score (sharing the same target branch) are claimed
+and stacked into candidate merge commits, each in its own scratch worktree:
+
+  car 1 = target + MR1
+  car 2 = target + MR1 + MR2
+  car 3 = target + MR1 + MR2 + MR3
+
+The checks run once on the last car, which proves the whole train. If it
+fails, the train is bisected, one check run at a time, to find the first
+failing car, so a train of N cars costs at most 1 + log2(N) runs. The target
+is fast-forwarded to the longest passing prefix and pushed. The first failing MR is handled like a
+normal merge failure (witness notified, conflict task created); MRs stacked
+after it are released back to the queue for the next train.
+
+The train size comes from merge_queue.max_concurrent in the rig's config.json
+and can be overridden with --max. With a size of 1 nothing is done: the
+refinery patrol merges MRs one at a time. The patrol runs this command at
+the start of each merge, so a larger max_concurrent is all it takes to
+switch a rig to merge trains.
+
+Examples:
+  gt refinery train
+  gt refinery train greenplace --max 4`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefineryTrain,
+}
+
+func init() {
+	refineryTrainCmd.Flags().IntVar(&refineryTrainMax, "max", 0, "Train size (overrides merge_queue.max_concurrent)")
+
+	refineryCmd.AddCommand(refineryTrainCmd)
+}
+
+func runRefineryTrain(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+
+	_, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	if refineryTrainMax > 0 {
+		eng.Config().MaxConcurrent = refineryTrainMax
+	}
+
+	if eng.Config().MaxConcurrent <= 1 {
+		fmt.Printf("%s merge_queue.max_concurrent is 1 for '%s': process MRs one at a time\n", style.Dim.Render("ℹ"), rigName)
+		return nil
+	}
+
+	result, err := eng.RunTrain(context.Background(), getWorkerID())
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		fmt.Printf("%s No ready MRs for '%s'\n", style.Dim.Render("ℹ"), rigName)
+		return nil
+	}
+
+	for _, car := range result.Landed {
+		fmt.Printf("%s Merged %s (%s)\n", style.Bold.Render("✓"), car.MR.ID, car.MR.Branch)
+	}
+	for _, car := range result.Failed {
+		fmt.Printf("%s Failed %s (%s): %s\n", style.Error.Render("✗"), car.MR.ID, car.MR.Branch, car.Result.Error)
+	}
+	for _, mr := range result.Requeued {
+		fmt.Printf("%s Requeued %s (%s)\n", style.Dim.Render("↻"), mr.ID, mr.Branch)
+	}
+
+	fmt.Printf("\n%s Train into %s: %d landed, %d failed, %d requeued\n",
+		style.Bold.Render("🚂"), result.Target, len(result.Landed), len(result.Failed), len(result.Requeued))
+	return nil
+}
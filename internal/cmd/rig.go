@@ -62,9 +62,14 @@ The command also:
   - Creates ~/gt/plugins/ (town-level) if it doesn't exist
   - Creates <rig>/plugins/ (rig-level)
 
+With --machine, the rig is created in the town on that machine (via
+"gt rig add" over its connection) and registered here as a remote rig.
+Sessions, status and sling for the rig are then routed to that machine.
+
 Example:
   gt rig add gastown https://github.com/steveyegge/gastown
-  gt rig add my-project git@github.com:user/repo.git --prefix mp`,
+  gt rig add my-project git@github.com:user/repo.git --prefix mp
+  gt rig add bigbuild git@github.com:user/big.git --machine buildbox`,
 	Args: cobra.ExactArgs(2),
 	RunE: runRigAdd,
 }
@@ -258,6 +263,7 @@ var (
 	rigAddPrefix       string
 	rigAddLocalRepo    string
 	rigAddBranch       string
+	rigAddMachine      string
 	rigResetHandoff    bool
 	rigResetMail       bool
 	rigResetStale      bool
@@ -291,6 +297,7 @@ func init() {
 	rigAddCmd.Flags().StringVar(&rigAddPrefix, "prefix", "", "Beads issue prefix (default: derived from name)")
 	rigAddCmd.Flags().StringVar(&rigAddLocalRepo, "local-repo", "", "Local repo path to share git objects (optional)")
 	rigAddCmd.Flags().StringVar(&rigAddBranch, "branch", "", "Default branch name (default: auto-detected from remote)")
+	rigAddCmd.Flags().StringVar(&rigAddMachine, "machine", "", "Federation machine to host the rig (from mayor/machines.json)")
 
 	rigRemoveCmd.Flags().BoolVar(&rigRemoveConfirm, "confirm", false, "Confirm rig removal from registry")
 	rigListCmd.Flags().BoolVar(&rigListJSON, "json", false, "Output rig list in JSON")
@@ -346,6 +353,9 @@ func runRigAdd(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Creating rig %s...\n", style.Bold.Render(name))
 	fmt.Printf("  Repository: %s\n", gitURL)
+	if rigAddMachine != "" {
+		fmt.Printf("  Machine: %s\n", rigAddMachine)
+	}
 	if rigAddLocalRepo != "" {
 		fmt.Printf("  Local repo: %s\n", rigAddLocalRepo)
 	}
@@ -359,6 +369,7 @@ func runRigAdd(cmd *cobra.Command, args []string) error {
 		BeadsPrefix:   rigAddPrefix,
 		LocalRepo:     rigAddLocalRepo,
 		DefaultBranch: rigAddBranch,
+		Machine:       rigAddMachine,
 	})
 	if err != nil {
 		return fmt.Errorf("adding rig: %w", err)
@@ -369,6 +380,14 @@ func runRigAdd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("saving rigs config: %w", err)
 	}
 
+	// Remote rigs keep their beads, routes and identity bead on their own
+	// machine; the remote "gt rig add" already set those up.
+	if !newRig.Address().IsLocal() {
+		fmt.Printf("\n%s Rig created on %s in %.1fs\n", style.Success.Render("✓"), newRig.Machine, time.Since(startTime).Seconds())
+		fmt.Printf("  Path: %s:%s\n", newRig.Machine, newRig.Path)
+		return nil
+	}
+
 	// Add route to town-level routes.jsonl for prefix-based routing.
 	// Route points to the canonical beads location:
 	// - If source repo has .beads/ tracked in git, route to mayor/rig
@@ -4,9 +4,12 @@ import (
 	"fmt"
 
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/connection"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -29,8 +32,38 @@ func getRig(rigName string) (string, *rig.Rig, error) {
 	rigMgr := rig.NewManager(townRoot, rigsConfig, g)
 	r, err := rigMgr.GetRig(rigName)
 	if err != nil {
+		if rigMgr.RigExists(rigName) {
+			// Registered but unreachable (e.g., remote machine down)
+			return "", nil, fmt.Errorf("loading rig '%s': %w", rigName, err)
+		}
 		return "", nil, fmt.Errorf("rig '%s' not found", rigName)
 	}
 
 	return townRoot, r, nil
 }
+
+// rigRemote resolves the connection for a rig hosted on another machine.
+// Returns nil for local rigs.
+func rigRemote(townRoot string, r *rig.Rig) (*connection.Remote, error) {
+	if r.Address().IsLocal() {
+		return nil, nil
+	}
+	reg, err := connection.NewMachineRegistry(connection.RegistryPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading machine registry: %w", err)
+	}
+	return reg.Remote(r.Machine)
+}
+
+// newPolecatSessionManager returns a session manager for the rig, routed
+// through the rig's machine connection when the rig is remote.
+func newPolecatSessionManager(townRoot string, t *tmux.Tmux, r *rig.Rig) (*polecat.SessionManager, error) {
+	remote, err := rigRemote(townRoot, r)
+	if err != nil {
+		return nil, err
+	}
+	if remote != nil {
+		return polecat.NewRemoteSessionManager(remote, r), nil
+	}
+	return polecat.NewSessionManager(t, r), nil
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var rigSecretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage the secrets rendered into rig overlays",
+	Long: `Manage the secrets overlay templates render into polecat and crew workspaces.
+
+Overlay files in <rig>/.runtime/overlay/ ending in .tmpl are Go templates;
+{{secret "NAME"}} reads a secret from the rig's store, so .env files can be
+generated at spawn instead of kept in plaintext. Files that use a secret are
+written with mode 0600, kept out of git through info/exclude, and removed
+by gt done.
+
+Secrets are stored in <rig>/.runtime/secrets.enc, encrypted with the key in
+~/.config/gastown/secrets.key ($GT_SECRETS_KEY), which is created on first
+use. A rig can use age instead: when <rig>/.runtime/secrets.age exists (an
+age-encrypted NAME=value file) secrets are read from it with the identity
+in $GT_AGE_IDENTITY (default ~/.config/age/keys.txt), and managed with age.
+
+Examples:
+  gt rig secret set gastown DATABASE_URL          # Value read from stdin
+  gt rig secret list gastown
+  gt rig secret rm gastown DATABASE_URL
+
+  # .runtime/overlay/.env.tmpl
+  DATABASE_URL={{secret "DATABASE_URL"}}
+  WORKER={{.Name}}`,
+	RunE: requireSubcommand,
+}
+
+var rigSecretSetCmd = &cobra.Command{
+	Use:   "set <rig> <name> [value]",
+	Short: "Store a secret",
+	Long: `Store a secret in the rig's local store.
+
+The value is read from stdin when not given, which keeps it out of your
+shell history.
+
+Examples:
+  gt rig secret set gastown API_TOKEN < token.txt
+  echo -n "$TOKEN" | gt rig secret set gastown API_TOKEN`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: runRigSecretSet,
+}
+
+var rigSecretListCmd = &cobra.Command{
+	Use:   "list <rig>",
+	Short: "List secret names (not values)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRigSecretList,
+}
+
+var rigSecretRmCmd = &cobra.Command{
+	Use:   "rm <rig> <name>",
+	Short: "Remove a secret",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runRigSecretRm,
+}
+
+func init() {
+	rigCmd.AddCommand(rigSecretCmd)
+	rigSecretCmd.AddCommand(rigSecretSetCmd)
+	rigSecretCmd.AddCommand(rigSecretListCmd)
+	rigSecretCmd.AddCommand(rigSecretRmCmd)
+}
+
+// localSecretStore returns the local store of a rig, refusing rigs whose
+// secrets are managed with age.
+func localSecretStore(rigName string) (*rig.LocalSecretStore, error) {
+	_, r, err := getRig(rigName)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := rig.OpenSecretStore(r.Path).(*rig.LocalSecretStore); !ok {
+		return nil, fmt.Errorf("%s reads its secrets from .runtime/secrets.age: manage them with age", rigName)
+	}
+	return rig.NewLocalSecretStore(r.Path), nil
+}
+
+func runRigSecretSet(cmd *cobra.Command, args []string) error {
+	store, err := localSecretStore(args[0])
+	if err != nil {
+		return err
+	}
+
+	var value string
+	if len(args) == 3 {
+		value = args[2]
+	} else {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("reading secret from stdin: %w", err)
+		}
+		value = strings.TrimRight(string(data), "\r\n")
+	}
+
+	if err := store.Set(args[1], value); err != nil {
+		return err
+	}
+	fmt.Printf("%s Stored secret %s for %s\n", style.Bold.Render("✓"), args[1], args[0])
+	return nil
+}
+
+func runRigSecretList(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+	names, err := rig.OpenSecretStore(r.Path).Names()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Printf("No secrets stored for %s\n", args[0])
+		return nil
+	}
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+func runRigSecretRm(cmd *cobra.Command, args []string) error {
+	store, err := localSecretStore(args[0])
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(args[1]); err != nil {
+		return err
+	}
+	fmt.Printf("%s Removed secret %s from %s\n", style.Bold.Render("✓"), args[1], args[0])
+	return nil
+}
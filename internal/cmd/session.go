@@ -225,13 +225,16 @@ func parseAddress(addr string) (rigName, polecatName string, err error) {
 
 // getSessionManager creates a session manager for the given rig.
 func getSessionManager(rigName string) (*polecat.SessionManager, *rig.Rig, error) {
-	_, r, err := getRig(rigName)
+	townRoot, r, err := getRig(rigName)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	t := tmux.NewTmux()
-	polecatMgr := polecat.NewSessionManager(t, r)
+	polecatMgr, err := newPolecatSessionManager(townRoot, t, r)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	return polecatMgr, r, nil
 }
@@ -381,7 +384,10 @@ func runSessionList(cmd *cobra.Command, args []string) error {
 	var allSessions []SessionListItem
 
 	for _, r := range rigs {
-		polecatMgr := polecat.NewSessionManager(t, r)
+		polecatMgr, err := newPolecatSessionManager(townRoot, t, r)
+		if err != nil {
+			continue
+		}
 		infos, err := polecatMgr.List()
 		if err != nil {
 			continue
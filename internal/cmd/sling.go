@@ -50,6 +50,10 @@ Spawning Options (when target is a rig):
   gt sling gp-abc greenplace --create               # Create polecat if missing
   gt sling gp-abc greenplace --force                # Ignore unread mail
   gt sling gp-abc greenplace --account work         # Use specific Claude account
+  gt sling gp-abc greenplace --ignore-budget        # Spawn past an exhausted budget
+
+  New polecats are not spawned while the rig's budget, or the budget of a
+  convoy tracking the bead, is exhausted (see 'gt costs budget').
 
 Natural Language Args:
   gt sling gt-abc --args "patch release"
@@ -83,24 +87,25 @@ Batch Slinging:
 }
 
 var (
-	slingSubject  string
-	slingMessage  string
-	slingDryRun   bool
-	slingOnTarget string   // --on flag: target bead when slinging a formula
-	slingVars     []string // --var flag: formula variables (key=value)
-	slingArgs     string   // --args flag: natural language instructions for executor
-	slingIdle       time.Duration
-	slingSkipBusy   bool // Deprecated: skip busy targets (now default)
-	slingForceBusy  bool // Allow slinging to busy targets
+	slingSubject      string
+	slingMessage      string
+	slingDryRun       bool
+	slingOnTarget     string   // --on flag: target bead when slinging a formula
+	slingVars         []string // --var flag: formula variables (key=value)
+	slingArgs         string   // --args flag: natural language instructions for executor
+	slingIdle         time.Duration
+	slingSkipBusy     bool // Deprecated: skip busy targets (now default)
+	slingForceBusy    bool // Allow slinging to busy targets
 	slingAllowMissing bool // --allow-missing: allow slinging bead-like IDs that fail verification
 
 	// Flags migrated for polecat spawning (used by sling for work assignment)
-	slingCreate   bool   // --create: create polecat if it doesn't exist
-	slingForce    bool   // --force: force spawn even if polecat has unread mail
-	slingAccount  string // --account: Claude Code account handle to use
-	slingAgent    string // --agent: override runtime agent for this sling/spawn
-	slingNoConvoy bool   // --no-convoy: skip auto-convoy creation
-	slingSelf     bool   // --self: allow slinging to yourself
+	slingCreate       bool   // --create: create polecat if it doesn't exist
+	slingForce        bool   // --force: force spawn even if polecat has unread mail
+	slingAccount      string // --account: Claude Code account handle to use
+	slingAgent        string // --agent: override runtime agent for this sling/spawn
+	slingNoConvoy     bool   // --no-convoy: skip auto-convoy creation
+	slingIgnoreBudget bool   // --ignore-budget: spawn even when over budget
+	slingSelf         bool   // --self: allow slinging to yourself
 )
 
 func init() {
@@ -123,6 +128,7 @@ func init() {
 	slingCmd.Flags().StringVar(&slingAccount, "account", "", "Claude Code account handle to use")
 	slingCmd.Flags().StringVar(&slingAgent, "agent", "", "Override agent/runtime for this sling (e.g., claude, gemini, codex, or custom alias)")
 	slingCmd.Flags().BoolVar(&slingNoConvoy, "no-convoy", false, "Skip auto-convoy creation for single-issue sling")
+	slingCmd.Flags().BoolVar(&slingIgnoreBudget, "ignore-budget", false, "Spawn polecats even when the rig or convoy budget is exhausted")
 	slingCmd.Flags().BoolVar(&slingSelf, "self", false, "Confirm slinging to yourself (required when target resolves to current agent)")
 
 	rootCmd.AddCommand(slingCmd)
@@ -142,6 +148,23 @@ func runSling(cmd *cobra.Command, args []string) error {
 	}
 	townBeadsDir := filepath.Join(townRoot, ".beads")
 
+	// Remote rig target: the bead, hook and polecat all live in the town on
+	// the rig's machine, so hand the whole command over to it.
+	if len(args) > 1 {
+		remote, err := resolveRemoteTarget(townRoot, args[len(args)-1])
+		if err != nil {
+			return err
+		}
+		if remote != nil {
+			out, err := remote.GT(os.Args[1:]...)
+			fmt.Print(string(out))
+			if err != nil {
+				return fmt.Errorf("sling on %s: %w", remote.Name(), err)
+			}
+			return nil
+		}
+	}
+
 	// --var is only for standalone formula mode, not formula-on-bead mode
 	if slingOnTarget != "" && len(slingVars) > 0 {
 		return fmt.Errorf("--var cannot be used with --on (formula-on-bead mode doesn't support variables)")
@@ -250,11 +273,12 @@ func runSling(cmd *cobra.Command, args []string) error {
 				// Spawn a fresh polecat in the rig
 				fmt.Printf("Target is rig '%s', spawning fresh polecat...\n", rigName)
 				spawnOpts := SlingSpawnOptions{
-					Force:    slingForce,
-					Account:  slingAccount,
-					Create:   slingCreate,
-					HookBead: beadID, // Set atomically at spawn time
-					Agent:    slingAgent,
+					Force:        slingForce,
+					Account:      slingAccount,
+					Create:       slingCreate,
+					HookBead:     beadID, // Set atomically at spawn time
+					Agent:        slingAgent,
+					IgnoreBudget: slingIgnoreBudget,
 				}
 				spawnInfo, spawnErr := SpawnPolecatForSling(rigName, spawnOpts)
 				if spawnErr != nil {
@@ -281,11 +305,12 @@ func runSling(cmd *cobra.Command, args []string) error {
 						rigName := parts[0]
 						fmt.Printf("Target polecat has no active session, spawning fresh polecat in rig '%s'...\n", rigName)
 						spawnOpts := SlingSpawnOptions{
-							Force:    slingForce,
-							Account:  slingAccount,
-							Create:   slingCreate,
-							HookBead: beadID,
-							Agent:    slingAgent,
+							Force:        slingForce,
+							Account:      slingAccount,
+							Create:       slingCreate,
+							HookBead:     beadID,
+							Agent:        slingAgent,
+							IgnoreBudget: slingIgnoreBudget,
 						}
 						spawnInfo, spawnErr := SpawnPolecatForSling(rigName, spawnOpts)
 						if spawnErr != nil {
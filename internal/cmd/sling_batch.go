@@ -60,11 +60,12 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 
 		// Spawn a fresh polecat
 		spawnOpts := SlingSpawnOptions{
-			Force:    slingForce,
-			Account:  slingAccount,
-			Create:   slingCreate,
-			HookBead: beadID, // Set atomically at spawn time
-			Agent:    slingAgent,
+			Force:        slingForce,
+			Account:      slingAccount,
+			Create:       slingCreate,
+			HookBead:     beadID, // Set atomically at spawn time
+			Agent:        slingAgent,
+			IgnoreBudget: slingIgnoreBudget,
 		}
 		spawnInfo, err := SpawnPolecatForSling(rigName, spawnOpts)
 		if err != nil {
@@ -132,10 +132,11 @@ func runSlingFormula(args []string) error {
 				// Spawn a fresh polecat in the rig
 				fmt.Printf("Target is rig '%s', spawning fresh polecat...\n", rigName)
 				spawnOpts := SlingSpawnOptions{
-					Force:   slingForce,
-					Account: slingAccount,
-					Create:  slingCreate,
-					Agent:   slingAgent,
+					Force:        slingForce,
+					Account:      slingAccount,
+					Create:       slingCreate,
+					Agent:        slingAgent,
+					IgnoreBudget: slingIgnoreBudget,
 				}
 				spawnInfo, spawnErr := SpawnPolecatForSling(rigName, spawnOpts)
 				if spawnErr != nil {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -12,6 +13,8 @@ import (
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -312,8 +315,9 @@ func getSessionFromPane(pane string) string {
 }
 
 // ensureAgentReady waits for an agent to be ready before nudging an existing session.
-// Uses a pragmatic approach: wait for the pane to leave a shell, then (Claude-only)
-// accept the bypass permissions warning and give it a moment to finish initializing.
+// Uses a pragmatic approach: wait for the pane to leave a shell, then accept any
+// startup dialog the agent's adapter recognizes (e.g. Claude's bypass permissions
+// warning) and give it a moment to finish initializing.
 func ensureAgentReady(sessionName string) error {
 	t := tmux.NewTmux()
 
@@ -327,10 +331,9 @@ func ensureAgentReady(sessionName string) error {
 		return fmt.Errorf("waiting for agent to start: %w", err)
 	}
 
-	// Claude-only: accept bypass permissions warning if present
-	if t.IsClaudeRunning(sessionName) {
-		_ = t.AcceptBypassPermissionsWarning(sessionName)
+	_ = runtime.DismissDialogs(t, sessionAdapter(sessionName), sessionName)
 
+	if t.IsClaudeRunning(sessionName) {
 		// PRAGMATIC APPROACH: fixed delay rather than prompt detection.
 		// Claude startup takes ~5-8 seconds on typical machines.
 		time.Sleep(8 * time.Second)
@@ -341,6 +344,21 @@ func ensureAgentReady(sessionName string) error {
 	return nil
 }
 
+// sessionAdapter returns the adapter of the agent a tmux session runs, by
+// the role its name encodes.
+func sessionAdapter(sessionName string) runtime.Adapter {
+	townRoot, _ := workspace.FindFromCwd()
+	id, err := session.ParseSessionName(sessionName)
+	if err != nil || townRoot == "" {
+		return runtime.For("")
+	}
+	rigPath := ""
+	if id.Rig != "" {
+		rigPath = filepath.Join(townRoot, id.Rig)
+	}
+	return runtime.ForRole(string(id.Role), townRoot, rigPath, "")
+}
+
 // detectCloneRoot finds the root of the current git clone.
 func detectCloneRoot() (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
@@ -3,11 +3,42 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/connection"
+	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
 
+// resolveRemoteTarget returns the connection for a sling target whose rig
+// ("rig", "rig/polecat", "rig/crew/name", ...) is hosted on another machine.
+// Returns nil for local rigs and for non-rig targets like "mayor".
+func resolveRemoteTarget(townRoot, target string) (*connection.Remote, error) {
+	rigName := strings.SplitN(target, "/", 2)[0]
+	if rigName == "" {
+		return nil, nil
+	}
+	rigsConfig, err := config.LoadRigsConfig(constants.MayorRigsPath(townRoot))
+	if err != nil {
+		return nil, nil
+	}
+	entry, ok := rigsConfig.Rigs[rigName]
+	if !ok {
+		return nil, nil
+	}
+	addr := &connection.Address{Machine: entry.Machine, Rig: rigName}
+	if addr.IsLocal() {
+		return nil, nil
+	}
+	reg, err := connection.NewMachineRegistry(connection.RegistryPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading machine registry: %w", err)
+	}
+	return reg.Remote(entry.Machine)
+}
+
 // resolveTargetAgent converts a target spec to agent ID, pane, and hook root.
 func resolveTargetAgent(target string) (agentID string, pane string, hookRoot string, err error) {
 	// First resolve to session name
@@ -91,6 +91,7 @@ type AgentRuntime struct {
 // RigStatus represents status of a single rig.
 type RigStatus struct {
 	Name         string          `json:"name"`
+	Machine      string          `json:"machine,omitempty"` // Federation machine for remote rigs
 	Polecats     []string        `json:"polecats"`
 	PolecatCount int             `json:"polecat_count"`
 	Crews        []string        `json:"crews"`
@@ -230,6 +231,25 @@ func runStatusOnce(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("discovering rigs: %w", err)
 	}
 
+	// Remote rigs run their sessions on their own machine; merge those in so
+	// agent lookups below work the same for local and remote rigs.
+	remoteMachines := make(map[string]bool)
+	for _, r := range rigs {
+		if r.Address().IsLocal() || remoteMachines[r.Machine] {
+			continue
+		}
+		remoteMachines[r.Machine] = true
+		remote, err := mgr.Remote(r)
+		if err != nil {
+			continue
+		}
+		if sessions, err := remote.TmuxListSessions(); err == nil {
+			for _, s := range sessions {
+				allSessions[s] = true
+			}
+		}
+	}
+
 	// Pre-fetch agent beads across all rig-specific beads DBs.
 	allAgentBeads := make(map[string]*beads.Issue)
 	allHookBeads := make(map[string]*beads.Issue)
@@ -344,6 +364,7 @@ func runStatusOnce(_ *cobra.Command, _ []string) error {
 
 			rs := RigStatus{
 				Name:         r.Name,
+				Machine:      r.Machine,
 				Polecats:     r.Polecats,
 				PolecatCount: len(r.Polecats),
 				HasWitness:   r.HasWitness,
@@ -353,7 +374,11 @@ func runStatusOnce(_ *cobra.Command, _ []string) error {
 			// Count crew workers
 			crewGit := git.NewGit(r.Path)
 			crewMgr := crew.NewManager(r, crewGit)
-			if workers, err := crewMgr.List(); err == nil {
+			if !r.Address().IsLocal() {
+				// Crew clones live on the rig's machine; use the scan from loadRig
+				rs.Crews = r.Crew
+				rs.CrewCount = len(r.Crew)
+			} else if workers, err := crewMgr.List(); err == nil {
 				for _, w := range workers {
 					rs.Crews = append(rs.Crews, w.Name)
 				}
@@ -479,7 +504,11 @@ func outputStatusText(status TownStatus) error {
 	// Rigs
 	for _, r := range status.Rigs {
 		// Rig header with separator
-		fmt.Printf("─── %s ───────────────────────────────────────────\n\n", style.Bold.Render(r.Name+"/"))
+		if r.Machine != "" {
+			fmt.Printf("─── %s %s ──────────────────────────────────\n\n", style.Bold.Render(r.Name+"/"), style.Dim.Render("on "+r.Machine))
+		} else {
+			fmt.Printf("─── %s ───────────────────────────────────────────\n\n", style.Bold.Render(r.Name+"/"))
+		}
 
 		// Group agents by role
 		var witnesses, refineries, crews, polecats []AgentRuntime
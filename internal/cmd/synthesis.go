@@ -139,21 +139,9 @@ func runSynthesisStart(cmd *cobra.Command, args []string) error {
 	fmt.Printf("%s Checking synthesis readiness for %s...\n", style.Bold.Render("🔬"), convoyID)
 
 	// Load formula if specified
-	var f *formula.Formula
-	if meta.FormulaPath != "" {
-		f, err = formula.ParseFile(meta.FormulaPath)
-		if err != nil {
-			return fmt.Errorf("loading formula: %w", err)
-		}
-	} else if meta.Formula != "" {
-		// Try to find formula by name
-		formulaPath, findErr := findFormula(meta.Formula)
-		if findErr == nil {
-			f, err = formula.ParseFile(formulaPath)
-			if err != nil {
-				return fmt.Errorf("loading formula: %w", err)
-			}
-		}
+	f, err := loadConvoyFormula(meta)
+	if err != nil {
+		return fmt.Errorf("loading formula: %w", err)
 	}
 
 	// Check leg completion status
@@ -249,14 +237,7 @@ func runSynthesisStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load formula if available
-	var f *formula.Formula
-	if meta.FormulaPath != "" {
-		f, _ = formula.ParseFile(meta.FormulaPath)
-	} else if meta.Formula != "" {
-		if path, err := findFormula(meta.Formula); err == nil {
-			f, _ = formula.ParseFile(path)
-		}
-	}
+	f, _ := loadConvoyFormula(meta)
 
 	// Collect leg outputs
 	legOutputs, allComplete, err := collectLegOutputs(meta, f)
@@ -581,39 +562,19 @@ func slingSynthesis(beadID, targetRig string) error {
 	return slingCmd.Run()
 }
 
-// findFormula searches for a formula file by name.
-func findFormula(name string) (string, error) {
-	// Search paths
-	searchPaths := []string{
-		".beads/formulas",
-	}
-
-	// Add home directory formulas
-	if home, err := os.UserHomeDir(); err == nil {
-		searchPaths = append(searchPaths, filepath.Join(home, ".beads", "formulas"))
-	}
-
-	// Add GT_ROOT formulas if set
-	if gtRoot := os.Getenv("GT_ROOT"); gtRoot != "" {
-		searchPaths = append(searchPaths, filepath.Join(gtRoot, ".beads", "formulas"))
+// loadConvoyFormula loads the formula a convoy was created from, by path
+// or else by name. A formula that cannot be found is nil without error.
+func loadConvoyFormula(meta *ConvoyMeta) (*formula.Formula, error) {
+	loader := formulaLoader()
+	if meta.FormulaPath != "" {
+		return loader.LoadFile(meta.FormulaPath)
 	}
-
-	// Try each search path
-	for _, searchPath := range searchPaths {
-		// Try with .formula.toml extension
-		path := filepath.Join(searchPath, name+".formula.toml")
-		if _, err := os.Stat(path); err == nil {
-			return path, nil
-		}
-
-		// Try with .formula.json extension
-		path = filepath.Join(searchPath, name+".formula.json")
-		if _, err := os.Stat(path); err == nil {
-			return path, nil
+	if meta.Formula != "" {
+		if src, err := loader.Find(meta.Formula); err == nil {
+			return loader.LoadFile(src.Path)
 		}
 	}
-
-	return "", fmt.Errorf("formula '%s' not found", name)
+	return nil, nil
 }
 
 // CheckSynthesisReady checks if a convoy is ready for synthesis.
@@ -649,14 +610,7 @@ func TriggerSynthesisIfReady(convoyID, targetRig string) error {
 	}
 
 	// Load formula if available
-	var f *formula.Formula
-	if meta.FormulaPath != "" {
-		f, _ = formula.ParseFile(meta.FormulaPath)
-	} else if meta.Formula != "" {
-		if path, err := findFormula(meta.Formula); err == nil {
-			f, _ = formula.ParseFile(path)
-		}
-	}
+	f, _ := loadConvoyFormula(meta)
 
 	legOutputs, _, _ := collectLegOutputs(meta, f)
 	reviewID := meta.ReviewID
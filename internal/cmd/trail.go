@@ -10,6 +10,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -85,6 +86,9 @@ var trailHooksCmd = &cobra.Command{
 	Short: "Show recent hook activity",
 	Long: `Show recent hook activity (agents taking or dropping hooks).
 
+Hook history comes from hook/unhook events in the town event log,
+including rotated segments.
+
 Examples:
   gt trail hooks              # Recent hook activity
   gt trail hooks --since 1h   # Last hour of hook activity
@@ -329,22 +333,75 @@ func runTrailBeadsSimple(beadsDir string) error {
 	return beadsCmd.Run()
 }
 
+// HookEntry represents a hook or unhook event for output.
+type HookEntry struct {
+	Action  string    `json:"action"` // "hook" or "unhook"
+	Agent   string    `json:"agent"`
+	Bead    string    `json:"bead,omitempty"`
+	Time    time.Time `json:"time"`
+	TimeRel string    `json:"time_relative"`
+}
+
 func runTrailHooks(cmd *cobra.Command, args []string) error {
-	// For now, show current hooks status since we don't have hook history
-	// TODO: Implement hook activity log with HookEntry tracking
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	filter := events.Filter{
+		Types: []string{events.TypeHook, events.TypeUnhook},
+		Limit: trailLimit,
+	}
+	if trailSince != "" {
+		duration, err := parseDuration(trailSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since value: %w", err)
+		}
+		filter.Since = time.Now().Add(-duration)
+	}
+
+	hookEvents, err := events.Query(townRoot, filter)
+	if err != nil {
+		return fmt.Errorf("reading events: %w", err)
+	}
+
+	// Most recent first
+	hooks := make([]HookEntry, 0, len(hookEvents))
+	for i := len(hookEvents) - 1; i >= 0; i-- {
+		e := hookEvents[i]
+		ts, _ := time.Parse(time.RFC3339, e.Timestamp)
+		bead, _ := e.Payload["bead"].(string)
+		hooks = append(hooks, HookEntry{
+			Action:  e.Type,
+			Agent:   e.Actor,
+			Bead:    bead,
+			Time:    ts,
+			TimeRel: relativeTime(ts),
+		})
+	}
 
 	if trailJSON {
-		// Return empty array for now
-		fmt.Println("[]")
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(hooks)
+	}
+
+	if len(hooks) == 0 {
+		fmt.Println("No hook activity found")
 		return nil
 	}
 
 	fmt.Printf("%s\n\n", style.Bold.Render("Hook Activity"))
-	fmt.Printf("%s Hook activity tracking not yet implemented.\n", style.Dim.Render("Note:"))
-	fmt.Printf("%s Showing current hook status instead.\n\n", style.Dim.Render("     "))
+	for _, h := range hooks {
+		action := style.Success.Render("🪝 hooked  ")
+		if h.Action == events.TypeUnhook {
+			action = style.Dim.Render("   unhooked")
+		}
+		fmt.Printf("%s %s %s\n", action, h.Bead, style.Dim.Render(h.TimeRel))
+		fmt.Printf("    %s\n", h.Agent)
+	}
 
-	// Call the internal hook show function directly instead of spawning subprocess
-	return runHookShow(cmd, nil)
+	return nil
 }
 
 func findBeadsDir() (string, error) {
@@ -74,6 +74,11 @@ type AgentPresetInfo struct {
 
 	// NonInteractive contains settings for non-interactive mode.
 	NonInteractive *NonInteractiveConfig `json:"non_interactive,omitempty"`
+
+	// Adapter is an executable (absolute path or a name on PATH) that adapts
+	// the agent's CLI over JSON on stdin/stdout: readiness and state
+	// detection, prompt injection, usage and hooks. See runtime.ExternalAdapter.
+	Adapter string `json:"adapter,omitempty"`
 }
 
 // NonInteractiveConfig contains settings for running agents non-interactively.
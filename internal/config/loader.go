@@ -219,6 +219,30 @@ func validateRigSettings(c *RigSettings) error {
 			return err
 		}
 	}
+	if c.Budget != nil {
+		if err := validateBudgetConfig(c.Budget); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ErrInvalidBudget indicates a malformed rig budget.
+var ErrInvalidBudget = errors.New("invalid budget")
+
+// validateBudgetConfig validates a rig budget.
+func validateBudgetConfig(c *BudgetConfig) error {
+	if c.LimitUSD <= 0 {
+		return fmt.Errorf("%w: limit_usd must be positive", ErrInvalidBudget)
+	}
+	switch c.Period {
+	case "", "day", "week", "month":
+	default:
+		return fmt.Errorf("%w: period must be day, week or month, got '%s'", ErrInvalidBudget, c.Period)
+	}
+	if c.WarnAt < 0 || c.WarnAt > 1 {
+		return fmt.Errorf("%w: warn_at must be between 0 and 1", ErrInvalidBudget)
+	}
 	return nil
 }
 
@@ -603,6 +627,11 @@ func (c *AccountsConfig) GetAccount(handle string) *Account {
 	return nil
 }
 
+// ResolvedConfigDir returns the account's CLAUDE_CONFIG_DIR with ~ expanded.
+func (a *Account) ResolvedConfigDir() string {
+	return expandPath(a.ConfigDir)
+}
+
 // GetDefaultAccount returns the default account, or nil if not set.
 func (c *AccountsConfig) GetDefaultAccount() *Account {
 	if c.Default == "" {
@@ -1840,6 +1869,30 @@ func validateEscalationConfig(c *EscalationConfig) error {
 		return fmt.Errorf("%w: max_reescalations must be non-negative", ErrMissingField)
 	}
 
+	// Validate notifiers
+	n := &c.Notifiers
+	if n.RetryDelay != "" {
+		if _, err := time.ParseDuration(n.RetryDelay); err != nil {
+			return fmt.Errorf("invalid notifiers.retry_delay: %w", err)
+		}
+	}
+	if n.SMTP != nil && (n.SMTP.Host == "" || n.SMTP.From == "") {
+		return fmt.Errorf("%w: notifiers.smtp requires host and from", ErrMissingField)
+	}
+	if n.SMS != nil && len(n.SMS.Command) == 0 {
+		return fmt.Errorf("%w: notifiers.sms requires command", ErrMissingField)
+	}
+	for name, wh := range n.Webhooks {
+		if wh.URL == "" {
+			return fmt.Errorf("%w: notifiers.webhooks.%s requires url", ErrMissingField, name)
+		}
+	}
+	for name, cmd := range n.Commands {
+		if len(cmd.Command) == 0 {
+			return fmt.Errorf("%w: notifiers.commands.%s requires command", ErrMissingField, name)
+		}
+	}
+
 	return nil
 }
 
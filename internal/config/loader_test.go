@@ -334,6 +334,33 @@ func TestRigSettingsValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid budget",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Budget:  &BudgetConfig{LimitUSD: 50, Period: "week", WarnAt: 0.9},
+			},
+			wantErr: false,
+		},
+		{
+			name: "budget without limit",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Budget:  &BudgetConfig{Period: "day"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid budget period",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Budget:  &BudgetConfig{LimitUSD: 50, Period: "fortnight"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -75,6 +75,11 @@ type TownSettings struct {
 	// Agent addresses like "gastown/crew/jack" become "gastown.crew.jack@{domain}".
 	// Default: "gastown.local"
 	AgentEmailDomain string `json:"agent_email_domain,omitempty"`
+
+	// FeedViews are saved gt feed filters, keyed by view name.
+	// Values use the feed's query syntax (see gt feed --help).
+	// Example: {"refinery": "actor:refinery", "my-convoys": "type:sling,done actor:crew/joe"}
+	FeedViews map[string]string `json:"feed_views,omitempty"`
 }
 
 // NewTownSettings creates a new TownSettings with defaults.
@@ -218,6 +223,10 @@ type RigEntry struct {
 	LocalRepo   string       `json:"local_repo,omitempty"`
 	AddedAt     time.Time    `json:"added_at"`
 	BeadsConfig *BeadsConfig `json:"beads,omitempty"`
+
+	// Machine is the federation machine hosting this rig (see
+	// connection.MachineRegistry). Empty or "local" means this machine.
+	Machine string `json:"machine,omitempty"`
 }
 
 // BeadsConfig represents beads configuration for a rig.
@@ -268,6 +277,7 @@ type RigSettings struct {
 	Crew       *CrewConfig       `json:"crew,omitempty"`        // crew startup settings
 	Workflow   *WorkflowConfig   `json:"workflow,omitempty"`    // workflow settings
 	Runtime    *RuntimeConfig    `json:"runtime,omitempty"`     // LLM runtime settings (deprecated: use Agent)
+	Budget     *BudgetConfig     `json:"budget,omitempty"`      // spending limit enforced by patrols and gt sling
 
 	// Agent selects which agent preset to use for this rig.
 	// Can be a built-in preset ("claude", "gemini", "codex", "cursor", "auggie", "amp")
@@ -302,6 +312,22 @@ type RigSettings struct {
 	SystemPrompts map[string]string `json:"system_prompts,omitempty"`
 }
 
+// BudgetConfig is a spending limit for a rig, checked against the cost
+// ledger. Patrols warn when spend passes WarnAt and escalate; gt sling stops
+// spawning polecats once the limit is reached.
+type BudgetConfig struct {
+	// LimitUSD is the most the rig may spend per period, in US dollars.
+	LimitUSD float64 `json:"limit_usd"`
+
+	// Period is when the budget resets: "day", "week" or "month".
+	// Default: "day".
+	Period string `json:"period,omitempty"`
+
+	// WarnAt is the fraction of LimitUSD at which to warn (0 < warn_at <= 1).
+	// Default: 0.8.
+	WarnAt float64 `json:"warn_at,omitempty"`
+}
+
 // CrewConfig represents crew workspace settings for a rig.
 type CrewConfig struct {
 	// Startup is a natural language instruction for which crew to start on boot.
@@ -819,6 +845,11 @@ type AccountsConfig struct {
 	Version  int                `json:"version"`  // schema version
 	Accounts map[string]Account `json:"accounts"` // handle -> account details
 	Default  string             `json:"default"`  // default account handle
+
+	// Rotate makes spawns pick the account with the most quota left instead
+	// of the default, and moves rate-limited polecat sessions to another
+	// account (see internal/account).
+	Rotate bool `json:"rotate,omitempty"`
 }
 
 // Account represents a single Claude Code account.
@@ -826,6 +857,10 @@ type Account struct {
 	Email       string `json:"email"`                 // account email
 	Description string `json:"description,omitempty"` // human description
 	ConfigDir   string `json:"config_dir"`            // path to CLAUDE_CONFIG_DIR
+
+	// WeeklyTokenLimit is the account's weekly token budget, for ranking
+	// accounts by remaining quota (0 = unknown).
+	WeeklyTokenLimit int64 `json:"weekly_token_limit,omitempty"`
 }
 
 // CurrentAccountsVersion is the current schema version for AccountsConfig.
@@ -911,14 +946,21 @@ type EscalationConfig struct {
 	//   - "bead"        → Create escalation bead (always first, implicit)
 	//   - "mail:<target>" → Send gt mail to target (e.g., "mail:mayor")
 	//   - "email:human" → Send email to contacts.human_email
+	//   - "email:<addr>" → Send email to a literal address
 	//   - "sms:human"   → Send SMS to contacts.human_sms
 	//   - "slack"       → Post to contacts.slack_webhook
+	//   - "webhook:<name>" → Post to notifiers.webhooks[name]
+	//   - "exec:<name>" → Run notifiers.commands[name]
 	//   - "log"         → Write to escalation log file
 	Routes map[string][]string `json:"routes"`
 
 	// Contacts contains contact information for external notification actions.
 	Contacts EscalationContacts `json:"contacts"`
 
+	// Notifiers configures how external actions are delivered.
+	// Actions whose notifier isn't configured are skipped with a warning.
+	Notifiers EscalationNotifiers `json:"notifiers,omitempty"`
+
 	// StaleThreshold is how long before an unacknowledged escalation
 	// is considered stale and gets re-escalated.
 	// Format: Go duration string (e.g., "4h", "30m", "24h")
@@ -937,6 +979,75 @@ type EscalationContacts struct {
 	SlackWebhook string `json:"slack_webhook,omitempty"` // webhook URL for slack action
 }
 
+// EscalationNotifiers configures delivery for external escalation actions.
+type EscalationNotifiers struct {
+	// SMTP delivers email: actions.
+	SMTP *SMTPConfig `json:"smtp,omitempty"`
+
+	// SMS is the command that delivers sms: actions (e.g., an SMS gateway CLI).
+	SMS *ExecNotifierConfig `json:"sms,omitempty"`
+
+	// Webhooks are named JSON webhooks for webhook:<name> actions.
+	// The slack action uses contacts.slack_webhook with the same payload.
+	Webhooks map[string]WebhookConfig `json:"webhooks,omitempty"`
+
+	// Commands are named commands for exec:<name> actions.
+	Commands map[string]ExecNotifierConfig `json:"commands,omitempty"`
+
+	// Attempts is how many times a failed delivery is tried. Default: 3.
+	Attempts int `json:"attempts,omitempty"`
+
+	// RetryDelay is the delay before the first retry, doubling after each
+	// attempt. Format: Go duration string. Default: "2s".
+	RetryDelay string `json:"retry_delay,omitempty"`
+}
+
+// SMTPConfig configures the SMTP email notifier.
+type SMTPConfig struct {
+	Host     string `json:"host"`               // SMTP server host
+	Port     int    `json:"port,omitempty"`     // default 587
+	From     string `json:"from"`               // envelope and header sender
+	Username string `json:"username,omitempty"` // enables PLAIN auth
+	// PasswordEnv names the environment variable holding the SMTP password,
+	// so the secret never lives in settings/escalation.json.
+	PasswordEnv string `json:"password_env,omitempty"`
+}
+
+// WebhookConfig configures a JSON webhook notifier. The payload carries both
+// "text" (Slack, Mattermost) and "content" (Discord) fields.
+type WebhookConfig struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// ExecNotifierConfig configures a command notifier. "{to}" in Command is
+// replaced by the recipient; the message body is passed on stdin and
+// escalation details in GT_ESCALATION_* environment variables.
+type ExecNotifierConfig struct {
+	Command []string `json:"command"`
+	Timeout string   `json:"timeout,omitempty"` // default "30s"
+}
+
+// GetAttempts returns how many delivery attempts to make per action.
+func (n *EscalationNotifiers) GetAttempts() int {
+	if n.Attempts <= 0 {
+		return 3
+	}
+	return n.Attempts
+}
+
+// GetRetryDelay returns the delay before the first retry.
+func (n *EscalationNotifiers) GetRetryDelay() time.Duration {
+	if n.RetryDelay == "" {
+		return 2 * time.Second
+	}
+	d, err := time.ParseDuration(n.RetryDelay)
+	if err != nil {
+		return 2 * time.Second
+	}
+	return d
+}
+
 // CurrentEscalationVersion is the current schema version for EscalationConfig.
 const CurrentEscalationVersion = 1
 
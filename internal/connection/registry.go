@@ -9,6 +9,11 @@ import (
 	"sync"
 )
 
+// RegistryPath returns the location of a town's machine registry.
+func RegistryPath(townRoot string) string {
+	return filepath.Join(townRoot, "mayor", "machines.json")
+}
+
 // Machine represents a managed machine in the federation.
 type Machine struct {
 	Name     string `json:"name"`
@@ -176,13 +181,29 @@ func (r *MachineRegistry) Connection(name string) (Connection, error) {
 	case "local":
 		return NewLocalConnection(), nil
 	case "ssh":
-		// SSH connection not yet implemented
-		return nil, fmt.Errorf("ssh connections not yet implemented")
+		return NewSSHConnection(m.Name, SSHConfigFromMachine(m)), nil
 	default:
 		return nil, fmt.Errorf("unknown machine type: %s", m.Type)
 	}
 }
 
+// Remote returns a Remote for the named machine, for delegating gt commands
+// to the town hosted there.
+func (r *MachineRegistry) Remote(name string) (*Remote, error) {
+	m, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if m.TownPath == "" {
+		return nil, fmt.Errorf("machine %s has no town_path configured", name)
+	}
+	conn, err := r.Connection(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Remote{Connection: conn, TownPath: m.TownPath}, nil
+}
+
 // LocalConnection returns the local connection.
 // This is a convenience method for the common case.
 func (r *MachineRegistry) LocalConnection() *LocalConnection {
@@ -0,0 +1,24 @@
+package connection
+
+import "path/filepath"
+
+// Remote is a Connection to a machine hosting its own town.
+// Rig lifecycle on that machine (spawning polecats, starting the witness,
+// slinging work) is delegated to the gt binary installed there, so the local
+// town only needs the connection to observe and steer it.
+type Remote struct {
+	Connection
+
+	// TownPath is the town root on the remote machine.
+	TownPath string
+}
+
+// GT runs a gt command from the remote town root and returns its combined output.
+func (r *Remote) GT(args ...string) ([]byte, error) {
+	return r.ExecDir(r.TownPath, "gt", args...)
+}
+
+// RigPath returns the path of a rig on the remote machine.
+func (r *Remote) RigPath(rigName string) string {
+	return filepath.Join(r.TownPath, rigName)
+}
@@ -0,0 +1,554 @@
+package connection
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// DefaultSSHTimeout is the dial timeout for SSH connections.
+const DefaultSSHTimeout = 10 * time.Second
+
+// SSHConfig configures an SSHConnection.
+type SSHConfig struct {
+	// Host is the remote address as "user@host" or "user@host:port".
+	// The port defaults to 22 and the user to $USER.
+	Host string
+
+	// KeyPath is the private key used for public key authentication.
+	// Defaults to ~/.ssh/id_ed25519, then ~/.ssh/id_rsa.
+	KeyPath string
+
+	// KnownHostsPath is the known_hosts file used to verify the server.
+	// Defaults to ~/.ssh/known_hosts. Ignored if HostKeyCallback is set.
+	KnownHostsPath string
+
+	// HostKeyCallback overrides known_hosts verification (e.g., for tests
+	// that pin a server key with ssh.FixedHostKey).
+	HostKeyCallback ssh.HostKeyCallback
+
+	// Timeout is the dial timeout. Defaults to DefaultSSHTimeout.
+	Timeout time.Duration
+}
+
+// SSHConnection implements Connection for a remote machine over SSH.
+// File operations and tmux commands run through the remote shell, so the
+// remote side only needs a POSIX sh, coreutils and tmux - no sftp subsystem.
+//
+// The underlying SSH client is dialed lazily and redialed if it drops.
+type SSHConnection struct {
+	name   string
+	config SSHConfig
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+// NewSSHConnection creates a connection to a remote machine.
+// No network activity happens until the first operation.
+func NewSSHConnection(name string, cfg SSHConfig) *SSHConnection {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultSSHTimeout
+	}
+	return &SSHConnection{name: name, config: cfg}
+}
+
+// SSHConfigFromMachine builds an SSHConfig from a registry machine entry.
+func SSHConfigFromMachine(m *Machine) SSHConfig {
+	return SSHConfig{
+		Host:    m.Host,
+		KeyPath: m.KeyPath,
+	}
+}
+
+// Name returns the machine name.
+func (c *SSHConnection) Name() string {
+	return c.name
+}
+
+// IsLocal returns false for SSH connections.
+func (c *SSHConnection) IsLocal() bool {
+	return false
+}
+
+// Close closes the underlying SSH client, if connected.
+func (c *SSHConnection) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client == nil {
+		return nil
+	}
+	err := c.client.Close()
+	c.client = nil
+	return err
+}
+
+// dial returns a connected SSH client, dialing if necessary.
+func (c *SSHConnection) dial() (*ssh.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client != nil {
+		return c.client, nil
+	}
+
+	user, addr := splitSSHHost(c.config.Host)
+
+	signer, err := loadSigner(c.config.KeyPath)
+	if err != nil {
+		return nil, &ConnectionError{Op: "connect", Machine: c.name, Err: err}
+	}
+
+	hostKeyCallback := c.config.HostKeyCallback
+	if hostKeyCallback == nil {
+		path := c.config.KnownHostsPath
+		if path == "" {
+			home, _ := os.UserHomeDir()
+			path = filepath.Join(home, ".ssh", "known_hosts")
+		}
+		hostKeyCallback, err = knownhosts.New(path)
+		if err != nil {
+			return nil, &ConnectionError{Op: "connect", Machine: c.name, Err: fmt.Errorf("loading known hosts: %w", err)}
+		}
+	}
+
+	client, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         c.config.Timeout,
+	})
+	if err != nil {
+		return nil, &ConnectionError{Op: "connect", Machine: c.name, Err: err}
+	}
+
+	c.client = client
+	return client, nil
+}
+
+// run executes a shell command line on the remote machine.
+// stdout and stderr are returned separately; err is an *ssh.ExitError for a
+// non-zero exit status.
+func (c *SSHConnection) run(cmdline string, stdin io.Reader) (stdout, stderr []byte, err error) {
+	client, err := c.dial()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		// The connection may have dropped; redial once.
+		_ = c.Close()
+		if client, err = c.dial(); err != nil {
+			return nil, nil, err
+		}
+		if session, err = client.NewSession(); err != nil {
+			return nil, nil, &ConnectionError{Op: "exec", Machine: c.name, Err: err}
+		}
+	}
+	defer session.Close()
+
+	var outBuf, errBuf bytes.Buffer
+	session.Stdout = &outBuf
+	session.Stderr = &errBuf
+	session.Stdin = stdin
+
+	err = session.Run(cmdline)
+	var exitErr *ssh.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		err = &ConnectionError{Op: "exec", Machine: c.name, Err: err}
+	}
+	return outBuf.Bytes(), errBuf.Bytes(), err
+}
+
+// runCombined executes a command line and returns combined output,
+// matching exec.Cmd.CombinedOutput semantics of LocalConnection.
+func (c *SSHConnection) runCombined(cmdline string) ([]byte, error) {
+	stdout, stderr, err := c.run(cmdline, nil)
+	return append(stdout, stderr...), err
+}
+
+// fileError maps a failed remote file operation onto the package's error
+// types, using stderr since only the exit status crosses the wire.
+func fileError(path, op string, stderr []byte, err error) error {
+	msg := string(stderr)
+	switch {
+	case strings.Contains(msg, "No such file"):
+		return &NotFoundError{Path: path}
+	case strings.Contains(msg, "Permission denied"):
+		return &PermissionError{Path: path, Op: op}
+	}
+	if msg = strings.TrimSpace(msg); msg != "" {
+		return fmt.Errorf("%s %s: %s", op, path, msg)
+	}
+	return err
+}
+
+// ReadFile reads the named file.
+func (c *SSHConnection) ReadFile(path string) ([]byte, error) {
+	stdout, stderr, err := c.run("cat -- "+shellQuote(path), nil)
+	if err != nil {
+		return nil, fileError(path, "read", stderr, err)
+	}
+	return stdout, nil
+}
+
+// WriteFile writes data to the named file.
+func (c *SSHConnection) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	q := shellQuote(path)
+	cmdline := fmt.Sprintf("cat > %s && chmod %o %s", q, perm.Perm(), q)
+	if _, stderr, err := c.run(cmdline, bytes.NewReader(data)); err != nil {
+		return fileError(path, "write", stderr, err)
+	}
+	return nil
+}
+
+// MkdirAll creates a directory and all parent directories.
+func (c *SSHConnection) MkdirAll(path string, perm fs.FileMode) error {
+	cmdline := fmt.Sprintf("mkdir -p -m %o -- %s", perm.Perm(), shellQuote(path))
+	if _, stderr, err := c.run(cmdline, nil); err != nil {
+		return fileError(path, "mkdir", stderr, err)
+	}
+	return nil
+}
+
+// Remove removes the named file or empty directory.
+func (c *SSHConnection) Remove(path string) error {
+	q := shellQuote(path)
+	cmdline := fmt.Sprintf("if [ -d %s ] && [ ! -L %s ]; then rmdir -- %s; else rm -f -- %s; fi", q, q, q, q)
+	if _, stderr, err := c.run(cmdline, nil); err != nil {
+		return fileError(path, "remove", stderr, err)
+	}
+	return nil
+}
+
+// RemoveAll removes the named file or directory and any children.
+func (c *SSHConnection) RemoveAll(path string) error {
+	if _, stderr, err := c.run("rm -rf -- "+shellQuote(path), nil); err != nil {
+		return fileError(path, "remove", stderr, err)
+	}
+	return nil
+}
+
+// Stat returns file info for the named file.
+// Tries GNU stat first, then BSD stat, both printing the raw st_mode in hex.
+func (c *SSHConnection) Stat(path string) (FileInfo, error) {
+	q := shellQuote(path)
+	cmdline := fmt.Sprintf("stat -c '%%s %%f %%Y' -- %s 2>/dev/null || stat -f '%%z %%Xp %%m' -- %s", q, q)
+	stdout, stderr, err := c.run(cmdline, nil)
+	if err != nil {
+		return nil, fileError(path, "stat", stderr, err)
+	}
+	return parseStatOutput(filepath.Base(path), string(stdout))
+}
+
+// parseStatOutput parses "<size> <hex st_mode> <unix mtime>".
+func parseStatOutput(name, out string) (BasicFileInfo, error) {
+	fields := strings.Fields(out)
+	if len(fields) != 3 {
+		return BasicFileInfo{}, fmt.Errorf("unexpected stat output: %q", out)
+	}
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return BasicFileInfo{}, fmt.Errorf("parsing size %q: %w", fields[0], err)
+	}
+	rawMode, err := strconv.ParseUint(fields[1], 16, 32)
+	if err != nil {
+		return BasicFileInfo{}, fmt.Errorf("parsing mode %q: %w", fields[1], err)
+	}
+	mtime, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return BasicFileInfo{}, fmt.Errorf("parsing mtime %q: %w", fields[2], err)
+	}
+
+	mode := unixModeToFileMode(uint32(rawMode))
+	return BasicFileInfo{
+		FileName:    name,
+		FileSize:    size,
+		FileMode:    mode,
+		FileModTime: time.Unix(mtime, 0),
+		FileIsDir:   mode.IsDir(),
+	}, nil
+}
+
+// unixModeToFileMode converts a raw st_mode to fs.FileMode.
+func unixModeToFileMode(m uint32) fs.FileMode {
+	mode := fs.FileMode(m & 0o777)
+	switch m & 0o170000 {
+	case 0o040000:
+		mode |= fs.ModeDir
+	case 0o120000:
+		mode |= fs.ModeSymlink
+	case 0o010000:
+		mode |= fs.ModeNamedPipe
+	case 0o140000:
+		mode |= fs.ModeSocket
+	case 0o020000:
+		mode |= fs.ModeDevice | fs.ModeCharDevice
+	case 0o060000:
+		mode |= fs.ModeDevice
+	}
+	if m&0o4000 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if m&0o2000 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if m&0o1000 != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode
+}
+
+// Glob returns the names of all files matching the pattern.
+// Expansion happens in the remote shell; results are sorted like filepath.Glob.
+func (c *SSHConnection) Glob(pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	cmdline := fmt.Sprintf(`for f in %s; do [ -e "$f" ] || [ -L "$f" ] && printf '%%s\n' "$f"; done; true`, globQuote(pattern))
+	stdout, _, err := c.run(cmdline, nil)
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, line := range strings.Split(string(stdout), "\n") {
+		if line != "" {
+			matches = append(matches, line)
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// Exists returns true if the path exists.
+func (c *SSHConnection) Exists(path string) (bool, error) {
+	_, _, err := c.run("test -e "+shellQuote(path), nil)
+	if err != nil {
+		var exitErr *ssh.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitStatus() == 1 {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Exec runs a command and returns its combined output.
+func (c *SSHConnection) Exec(cmd string, args ...string) ([]byte, error) {
+	return c.runCombined(shellJoin(cmd, args...))
+}
+
+// ExecDir runs a command in the specified directory.
+func (c *SSHConnection) ExecDir(dir, cmd string, args ...string) ([]byte, error) {
+	return c.runCombined("cd " + shellQuote(dir) + " && " + shellJoin(cmd, args...))
+}
+
+// ExecEnv runs a command with additional environment variables.
+// Variables are passed via env(1) rather than SSH "env" requests, which
+// most servers reject unless AcceptEnv is configured.
+func (c *SSHConnection) ExecEnv(env map[string]string, cmd string, args ...string) ([]byte, error) {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := []string{"env"}
+	for _, k := range keys {
+		parts = append(parts, shellQuote(k+"="+env[k]))
+	}
+	return c.runCombined(strings.Join(parts, " ") + " " + shellJoin(cmd, args...))
+}
+
+// tmux runs a tmux subcommand on the remote machine.
+// Error classification mirrors the local tmux wrapper.
+func (c *SSHConnection) tmux(args ...string) (string, error) {
+	stdout, stderr, err := c.run(shellJoin("tmux", args...), nil)
+	if err != nil {
+		var exitErr *ssh.ExitError
+		if !errors.As(err, &exitErr) {
+			return "", err
+		}
+		msg := strings.TrimSpace(string(stderr))
+		if msg == "" {
+			return "", fmt.Errorf("tmux %s: %w", args[0], err)
+		}
+		return "", fmt.Errorf("tmux %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+// isTmuxNoSession reports whether a tmux error means "nothing there".
+func isTmuxNoSession(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "no server running") ||
+		strings.Contains(msg, "error connecting to") ||
+		strings.Contains(msg, "session not found") ||
+		strings.Contains(msg, "can't find session")
+}
+
+// TmuxNewSession creates a new tmux session.
+func (c *SSHConnection) TmuxNewSession(name, dir string) error {
+	args := []string{"new-session", "-d", "-s", name}
+	if dir != "" {
+		args = append(args, "-c", dir)
+	}
+	_, err := c.tmux(args...)
+	return err
+}
+
+// TmuxKillSession terminates a tmux session.
+func (c *SSHConnection) TmuxKillSession(name string) error {
+	_, err := c.tmux("kill-session", "-t", name)
+	return err
+}
+
+// TmuxSendKeys sends keys to a tmux session followed by Enter,
+// matching the local tmux.SendKeys behavior.
+func (c *SSHConnection) TmuxSendKeys(session, keys string) error {
+	if _, err := c.tmux("send-keys", "-t", session, "-l", keys); err != nil {
+		return err
+	}
+	// The round trip to the remote host is longer than the local debounce,
+	// so Enter can be sent straight away.
+	_, err := c.tmux("send-keys", "-t", session, "Enter")
+	return err
+}
+
+// TmuxCapturePane captures the last N lines from a tmux pane.
+func (c *SSHConnection) TmuxCapturePane(session string, lines int) (string, error) {
+	return c.tmux("capture-pane", "-p", "-t", session, "-S", fmt.Sprintf("-%d", lines))
+}
+
+// TmuxHasSession returns true if the session exists.
+func (c *SSHConnection) TmuxHasSession(name string) (bool, error) {
+	if _, err := c.tmux("has-session", "-t", "="+name); err != nil {
+		var connErr *ConnectionError
+		if errors.As(err, &connErr) {
+			return false, err
+		}
+		if isTmuxNoSession(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// TmuxListSessions returns all tmux session names.
+func (c *SSHConnection) TmuxListSessions() ([]string, error) {
+	out, err := c.tmux("list-sessions", "-F", "#{session_name}")
+	if err != nil {
+		var connErr *ConnectionError
+		if !errors.As(err, &connErr) && isTmuxNoSession(err) {
+			return nil, nil // No server = no sessions
+		}
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// splitSSHHost splits "user@host[:port]" into a user and a dialable address.
+func splitSSHHost(host string) (user, addr string) {
+	user = os.Getenv("USER")
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		user, host = host[:i], host[i+1:]
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), "22")
+	}
+	return user, host
+}
+
+// loadSigner reads a private key, falling back to the default key locations.
+func loadSigner(keyPath string) (ssh.Signer, error) {
+	candidates := []string{keyPath}
+	if keyPath == "" {
+		home, _ := os.UserHomeDir()
+		candidates = []string{
+			filepath.Join(home, ".ssh", "id_ed25519"),
+			filepath.Join(home, ".ssh", "id_rsa"),
+		}
+	}
+
+	var lastErr error
+	for _, path := range candidates {
+		data, err := os.ReadFile(path) //nolint:gosec // G304: key path is from machine registry config
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing key %s: %w", path, err)
+		}
+		return signer, nil
+	}
+	return nil, fmt.Errorf("loading ssh key: %w", lastErr)
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:@%+,", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellJoin quotes a command and its arguments into one command line.
+func shellJoin(cmd string, args ...string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, shellQuote(cmd))
+	for _, a := range args {
+		parts = append(parts, shellQuote(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+// globQuote escapes everything in pattern except the glob metacharacters
+// * ? [ ] so the remote shell expands it like filepath.Glob would.
+func globQuote(pattern string) string {
+	var b strings.Builder
+	for _, r := range pattern {
+		switch {
+		case strings.ContainsRune("*?[]", r):
+			b.WriteRune(r)
+		case r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./", r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Verify SSHConnection implements Connection.
+var _ Connection = (*SSHConnection)(nil)
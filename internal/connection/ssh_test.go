@@ -0,0 +1,298 @@
+package connection
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/steveyegge/gastown/internal/sshd"
+)
+
+// startTestSSHD starts an internal/sshd server in ExecShell mode and returns
+// a connection to it, authenticated with a freshly generated key.
+func startTestSSHD(t *testing.T) *SSHConnection {
+	t.Helper()
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	dir := t.TempDir()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	hostKeyPath := filepath.Join(dir, "host_key")
+	srv, err := sshd.New(sshd.Config{
+		Port:           0,
+		AgentName:      "buildbox",
+		AgentRole:      "test",
+		WorkDir:        dir,
+		HostKeyPath:    hostKeyPath,
+		AuthorizedKeys: []string{string(ssh.MarshalAuthorizedKey(sshPub))},
+		ExecShell:      true,
+	})
+	if err != nil {
+		t.Fatalf("sshd.New: %v", err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("sshd.Start: %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Stop() })
+
+	hostKeyData, err := os.ReadFile(hostKeyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostSigner, err := ssh.ParsePrivateKey(hostKeyData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := NewSSHConnection("buildbox", SSHConfig{
+		Host:            fmt.Sprintf("tester@127.0.0.1:%d", srv.Port()),
+		KeyPath:         keyPath,
+		HostKeyCallback: ssh.FixedHostKey(hostSigner.PublicKey()),
+		Timeout:         5 * time.Second,
+	})
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestSSHConnection_Identity(t *testing.T) {
+	conn := NewSSHConnection("buildbox", SSHConfig{Host: "me@example.com"})
+	if conn.Name() != "buildbox" {
+		t.Errorf("Name() = %q, want buildbox", conn.Name())
+	}
+	if conn.IsLocal() {
+		t.Error("IsLocal() = true, want false")
+	}
+}
+
+func TestSSHConnection_FileOps(t *testing.T) {
+	conn := startTestSSHD(t)
+	root := t.TempDir()
+	dir := filepath.Join(root, "a dir", "nested")
+	file := filepath.Join(dir, "it's.txt")
+
+	if err := conn.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := conn.WriteFile(file, []byte("hello\nworld\n"), 0640); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	data, err := conn.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "hello\nworld\n" {
+		t.Errorf("ReadFile = %q", data)
+	}
+
+	fi, err := conn.Stat(file)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if fi.Name() != "it's.txt" || fi.Size() != 12 || fi.IsDir() || fi.Mode().Perm() != 0640 {
+		t.Errorf("Stat = %+v", fi)
+	}
+	if fi, err := conn.Stat(dir); err != nil || !fi.IsDir() {
+		t.Errorf("Stat(dir) = %+v, %v; want a directory", fi, err)
+	}
+
+	if ok, err := conn.Exists(file); err != nil || !ok {
+		t.Errorf("Exists(file) = %v, %v", ok, err)
+	}
+	if ok, err := conn.Exists(filepath.Join(root, "nope")); err != nil || ok {
+		t.Errorf("Exists(missing) = %v, %v", ok, err)
+	}
+
+	_, err = conn.ReadFile(filepath.Join(root, "nope"))
+	var nf *NotFoundError
+	if !errors.As(err, &nf) {
+		t.Errorf("ReadFile(missing) error = %v, want NotFoundError", err)
+	}
+
+	matches, err := conn.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	if len(matches) != 1 || matches[0] != file {
+		t.Errorf("Glob = %v, want [%s]", matches, file)
+	}
+	if matches, err := conn.Glob(filepath.Join(dir, "*.go")); err != nil || len(matches) != 0 {
+		t.Errorf("Glob(no match) = %v, %v", matches, err)
+	}
+
+	if err := conn.Remove(file); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := conn.Remove(file); err != nil {
+		t.Errorf("Remove(missing) should be a no-op, got %v", err)
+	}
+	if err := conn.RemoveAll(filepath.Join(root, "a dir")); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "a dir")); !os.IsNotExist(err) {
+		t.Errorf("expected directory removed, stat err = %v", err)
+	}
+}
+
+func TestSSHConnection_Exec(t *testing.T) {
+	conn := startTestSSHD(t)
+	dir := t.TempDir()
+
+	out, err := conn.Exec("echo", "hello world", "$HOME")
+	if err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "hello world $HOME" {
+		t.Errorf("Exec output = %q, want args passed literally", got)
+	}
+
+	out, err = conn.ExecDir(dir, "pwd")
+	if err != nil {
+		t.Fatalf("ExecDir: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != dir {
+		t.Errorf("ExecDir pwd = %q, want %q", got, dir)
+	}
+
+	out, err = conn.ExecEnv(map[string]string{"GT_TEST_VAR": "a b"}, "sh", "-c", "echo $GT_TEST_VAR")
+	if err != nil {
+		t.Fatalf("ExecEnv: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "a b" {
+		t.Errorf("ExecEnv output = %q, want %q", got, "a b")
+	}
+
+	_, err = conn.Exec("sh", "-c", "exit 3")
+	var exitErr *ssh.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitStatus() != 3 {
+		t.Errorf("Exec exit status error = %v, want exit 3", err)
+	}
+}
+
+func TestSSHConnection_Tmux(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+	conn := startTestSSHD(t)
+	name := fmt.Sprintf("gt-ssh-test-%d", time.Now().UnixNano())
+
+	if ok, err := conn.TmuxHasSession(name); err != nil || ok {
+		t.Fatalf("TmuxHasSession before create = %v, %v", ok, err)
+	}
+	if err := conn.TmuxNewSession(name, t.TempDir()); err != nil {
+		t.Fatalf("TmuxNewSession: %v", err)
+	}
+	defer func() { _ = conn.TmuxKillSession(name) }()
+
+	if ok, err := conn.TmuxHasSession(name); err != nil || !ok {
+		t.Errorf("TmuxHasSession after create = %v, %v", ok, err)
+	}
+	sessions, err := conn.TmuxListSessions()
+	if err != nil {
+		t.Fatalf("TmuxListSessions: %v", err)
+	}
+	found := false
+	for _, s := range sessions {
+		found = found || s == name
+	}
+	if !found {
+		t.Errorf("TmuxListSessions = %v, missing %s", sessions, name)
+	}
+
+	if err := conn.TmuxSendKeys(name, "echo ssh-marker-$((40+2))"); err != nil {
+		t.Fatalf("TmuxSendKeys: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		pane, err := conn.TmuxCapturePane(name, 20)
+		if err != nil {
+			t.Fatalf("TmuxCapturePane: %v", err)
+		}
+		if strings.Contains(pane, "ssh-marker-42") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("command output not seen in pane:\n%s", pane)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if err := conn.TmuxKillSession(name); err != nil {
+		t.Errorf("TmuxKillSession: %v", err)
+	}
+}
+
+func TestSplitSSHHost(t *testing.T) {
+	t.Setenv("USER", "fallback")
+	tests := []struct {
+		in, user, addr string
+	}{
+		{"alice@build.example.com", "alice", "build.example.com:22"},
+		{"alice@build.example.com:2222", "alice", "build.example.com:2222"},
+		{"build.example.com", "fallback", "build.example.com:22"},
+		{"bob@[::1]:2200", "bob", "[::1]:2200"},
+	}
+	for _, tt := range tests {
+		user, addr := splitSSHHost(tt.in)
+		if user != tt.user || addr != tt.addr {
+			t.Errorf("splitSSHHost(%q) = %q, %q; want %q, %q", tt.in, user, addr, tt.user, tt.addr)
+		}
+	}
+}
+
+func TestParseStatOutput(t *testing.T) {
+	fi, err := parseStatOutput("dir", "4096 41ed 1700000000\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fi.IsDir() || fi.Mode().Perm() != 0755 || fi.Size() != 4096 || fi.ModTime().Unix() != 1700000000 {
+		t.Errorf("parseStatOutput = %+v", fi)
+	}
+	if _, err := parseStatOutput("x", "garbage"); err == nil {
+		t.Error("expected error for malformed stat output")
+	}
+}
+
+func TestMachineRegistry_SSHConnection(t *testing.T) {
+	reg, err := NewMachineRegistry(filepath.Join(t.TempDir(), "machines.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Add(&Machine{Name: "buildbox", Type: "ssh", Host: "me@buildbox", KeyPath: "/nonexistent"}); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := reg.Connection("buildbox")
+	if err != nil {
+		t.Fatalf("Connection: %v", err)
+	}
+	if conn.IsLocal() || conn.Name() != "buildbox" {
+		t.Errorf("Connection(buildbox) = %s (local=%v)", conn.Name(), conn.IsLocal())
+	}
+}
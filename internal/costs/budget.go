@@ -0,0 +1,206 @@
+package costs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// Budget periods. A convoy budget covers the convoy's whole life; a rig
+// budget resets at the start of each day, week (Monday) or month.
+const (
+	PeriodTotal = "total"
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// DefaultWarnAt is the fraction of a budget at which patrols warn.
+const DefaultWarnAt = 0.8
+
+// Budget levels, in increasing order of severity.
+const (
+	LevelOK     = "ok"
+	LevelWarn   = "warn"
+	LevelCapped = "capped"
+)
+
+// Budget is a spending limit on one scope.
+type Budget struct {
+	// Scope names what the budget covers: "convoy:<id>" or "rig:<name>".
+	Scope    string
+	LimitUSD float64
+	// WarnAt is the fraction of LimitUSD that triggers a warning (0 means DefaultWarnAt).
+	WarnAt float64
+	Period string
+	// Since is when a PeriodTotal budget started counting (convoy creation).
+	Since time.Time
+}
+
+// ConvoyScope and RigScope build budget scope names.
+func ConvoyScope(id string) string { return "convoy:" + id }
+func RigScope(name string) string  { return "rig:" + name }
+
+// Start returns when the budget's current period began.
+func (b Budget) Start(now time.Time) time.Time {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch b.Period {
+	case PeriodDay:
+		return day
+	case PeriodWeek:
+		offset := (int(day.Weekday()) + 6) % 7 // Days since Monday
+		return day.AddDate(0, 0, -offset)
+	case PeriodMonth:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	default:
+		return b.Since
+	}
+}
+
+func (b Budget) warnAt() float64 {
+	if b.WarnAt <= 0 || b.WarnAt > 1 {
+		return DefaultWarnAt
+	}
+	return b.WarnAt
+}
+
+// BudgetStatus is a budget evaluated against the ledger.
+type BudgetStatus struct {
+	Scope    string    `json:"scope"`
+	LimitUSD float64   `json:"limit_usd"`
+	SpentUSD float64   `json:"spent_usd"`
+	WarnAt   float64   `json:"warn_at"`
+	Period   string    `json:"period"`
+	Since    time.Time `json:"since"`
+	Level    string    `json:"level"`
+}
+
+// Fraction returns spend as a fraction of the limit.
+func (s BudgetStatus) Fraction() float64 {
+	if s.LimitUSD <= 0 {
+		return 0
+	}
+	return s.SpentUSD / s.LimitUSD
+}
+
+// Capped reports whether the budget is exhausted.
+func (s BudgetStatus) Capped() bool {
+	return s.Level == LevelCapped
+}
+
+// Evaluate sums the entries that belong to the budget's scope (match) and
+// ended in its current period.
+func (b Budget) Evaluate(entries []Entry, match func(Entry) bool, now time.Time) BudgetStatus {
+	since := b.Start(now)
+	st := BudgetStatus{
+		Scope:    b.Scope,
+		LimitUSD: b.LimitUSD,
+		WarnAt:   b.warnAt(),
+		Period:   b.Period,
+		Since:    since,
+	}
+	for _, e := range entries {
+		if e.EndedAt.Before(since) || !match(e) {
+			continue
+		}
+		st.SpentUSD += e.CostUSD
+	}
+	switch {
+	case st.SpentUSD >= b.LimitUSD:
+		st.Level = LevelCapped
+	case st.SpentUSD >= b.LimitUSD*st.WarnAt:
+		st.Level = LevelWarn
+	default:
+		st.Level = LevelOK
+	}
+	return st
+}
+
+// ParseAmount parses a dollar amount such as "$40", "40" or "12.50".
+func ParseAmount(s string) (float64, error) {
+	v := strings.TrimPrefix(strings.TrimSpace(s), "$")
+	usd, err := strconv.ParseFloat(v, 64)
+	if err != nil || usd <= 0 {
+		return 0, fmt.Errorf("invalid budget %q: want a positive dollar amount like $40", s)
+	}
+	return usd, nil
+}
+
+// budgetStatePath records the level each budget last reached, so patrols
+// escalate once per crossing rather than on every cycle.
+func budgetStatePath(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "budgets.json")
+}
+
+// LoadBudgetState returns the last recorded status per scope.
+// A missing file is an empty state.
+func LoadBudgetState(townRoot string) (map[string]BudgetStatus, error) {
+	state := make(map[string]BudgetStatus)
+	data, err := os.ReadFile(budgetStatePath(townRoot)) //nolint:gosec // G304: path is constructed from town root
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return state, fmt.Errorf("reading budget state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return make(map[string]BudgetStatus), fmt.Errorf("parsing budget state: %w", err)
+	}
+	return state, nil
+}
+
+// SaveBudgetState writes the recorded status per scope.
+func SaveBudgetState(townRoot string, state map[string]BudgetStatus) error {
+	path := budgetStatePath(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating runtime dir: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil { //nolint:gosec // G306: not sensitive
+		return fmt.Errorf("writing budget state: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func levelRank(level string) int {
+	switch level {
+	case LevelWarn:
+		return 1
+	case LevelCapped:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Crossed returns the statuses whose level rose since the recorded state,
+// sorted by scope. A budget that starts a new period, or was raised, drops
+// back to ok and will be reported again when it next crosses.
+func Crossed(prev map[string]BudgetStatus, cur []BudgetStatus) []BudgetStatus {
+	var out []BudgetStatus
+	for _, s := range cur {
+		last, ok := prev[s.Scope]
+		if ok && !last.Since.Equal(s.Since) {
+			ok = false // New period
+		}
+		if !ok {
+			last = BudgetStatus{Level: LevelOK}
+		}
+		if levelRank(s.Level) > levelRank(last.Level) {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Scope < out[j].Scope })
+	return out
+}
@@ -0,0 +1,358 @@
+package costs
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const claudeTranscript = `{"type":"user","sessionId":"abc-123","message":{"role":"user","content":"hi"}}
+{"type":"assistant","sessionId":"abc-123","message":{"id":"msg_1","model":"claude-sonnet-4-5-20250929","usage":{"input_tokens":10,"output_tokens":1,"cache_creation_input_tokens":100,"cache_read_input_tokens":0}}}
+{"type":"assistant","sessionId":"abc-123","message":{"id":"msg_1","model":"claude-sonnet-4-5-20250929","usage":{"input_tokens":10,"output_tokens":50,"cache_creation_input_tokens":100,"cache_read_input_tokens":0}}}
+{"type":"assistant","sessionId":"abc-123","message":{"id":"msg_2","model":"claude-haiku-4-5-20251001","usage":{"input_tokens":5,"output_tokens":5,"cache_read_input_tokens":1000}}}
+{"type":"assistant","sessionId":"abc-123","message":{"id":"msg_3","model":"<synthetic>","usage":{"input_tokens":0,"output_tokens":0}}}
+{"type":"assistant","sessionId":"abc-123","message":{"id":"msg_4","model":"claude-sonnet-4-5-20250929","usage":{"input_tokens":3,"output_tokens":7,"cache_read_input_tokens":100}}}
+{"type":"assistant","sessi`
+
+func TestParseClaudeTranscript(t *testing.T) {
+	su, err := ParseClaudeTranscript(strings.NewReader(claudeTranscript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if su.SessionID != "abc-123" || su.Runtime != RuntimeClaude {
+		t.Errorf("session = %q/%q", su.Runtime, su.SessionID)
+	}
+	if len(su.Models) != 2 {
+		t.Fatalf("models = %+v, want sonnet and haiku only", su.Models)
+	}
+	sonnet := su.Models["claude-sonnet-4-5-20250929"]
+	want := Usage{Model: "claude-sonnet-4-5-20250929", InputTokens: 13, OutputTokens: 57, CacheCreationTokens: 100, CacheReadTokens: 100}
+	if sonnet != want {
+		t.Errorf("sonnet = %+v, want %+v (streamed message counted once)", sonnet, want)
+	}
+	if haiku := su.Models["claude-haiku-4-5-20251001"]; haiku.CacheReadTokens != 1000 {
+		t.Errorf("haiku = %+v", haiku)
+	}
+}
+
+const codexSession = `{"type":"session_meta","payload":{"id":"0199-codex","cwd":"/town/gastown/polecats/toast"}}
+{"type":"turn_context","payload":{"model":"gpt-5-codex"}}
+{"type":"event_msg","payload":{"type":"token_count","info":null}}
+{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":1000,"cached_input_tokens":400,"output_tokens":100}}}}
+{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":1000,"cached_input_tokens":400,"output_tokens":100}}}}
+{"type":"turn_context","payload":{"model":"gpt-5-mini"}}
+{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":1500,"cached_input_tokens":600,"output_tokens":130}}}}
+`
+
+func TestParseCodexSession(t *testing.T) {
+	su, err := ParseCodexSession(strings.NewReader(codexSession))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if su.SessionID != "0199-codex" {
+		t.Errorf("SessionID = %q", su.SessionID)
+	}
+	codex := su.Models["gpt-5-codex"]
+	if codex.InputTokens != 600 || codex.CacheReadTokens != 400 || codex.OutputTokens != 100 {
+		t.Errorf("gpt-5-codex = %+v (repeated totals must not double count)", codex)
+	}
+	mini := su.Models["gpt-5-mini"]
+	if mini.InputTokens != 300 || mini.CacheReadTokens != 200 || mini.OutputTokens != 30 {
+		t.Errorf("gpt-5-mini = %+v, want the growth after the model switch", mini)
+	}
+}
+
+func TestFindTranscripts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", filepath.Join(home, "acct"))
+	t.Setenv("CODEX_HOME", "")
+
+	workDir := "/town/gastown/polecats/toast"
+	projectDir := filepath.Join(home, "acct", "projects", "-town-gastown-polecats-toast")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	older := filepath.Join(projectDir, "old.jsonl")
+	newer := filepath.Join(projectDir, "new.jsonl")
+	for _, p := range []string{older, newer} {
+		if err := os.WriteFile(p, []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	past := time.Now().Add(-time.Hour)
+	_ = os.Chtimes(older, past, past)
+
+	if got, err := FindTranscript(RuntimeClaude, workDir); err != nil || got != newer {
+		t.Errorf("claude transcript = %q, %v; want %q", got, err, newer)
+	}
+
+	codexDir := filepath.Join(home, ".codex", "sessions", "2026", "01", "02")
+	if err := os.MkdirAll(codexDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	mine := filepath.Join(codexDir, "rollout-a.jsonl")
+	other := filepath.Join(codexDir, "rollout-b.jsonl")
+	_ = os.WriteFile(mine, []byte(codexSession), 0644)
+	_ = os.WriteFile(other, []byte(`{"type":"session_meta","payload":{"id":"x","cwd":"/elsewhere"}}`+"\n"), 0644)
+
+	if got, err := FindTranscript(RuntimeCodex, workDir); err != nil || got != mine {
+		t.Errorf("codex session = %q, %v; want %q", got, err, mine)
+	}
+	if _, err := FindTranscript(RuntimeCodex, "/nowhere"); err == nil {
+		t.Error("expected error for a directory without sessions")
+	}
+}
+
+func TestPricing(t *testing.T) {
+	town := t.TempDir()
+	p, err := LoadPricing(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Longest prefix: opus-4-5 is priced differently from opus-4
+	opus45, _ := p.Cost(Usage{Model: "claude-opus-4-5-20251101", InputTokens: 1_000_000})
+	opus41, _ := p.Cost(Usage{Model: "claude-opus-4-1-20250805", InputTokens: 1_000_000})
+	if opus45 != 5 || opus41 != 15 {
+		t.Errorf("opus input prices = %v, %v; want 5, 15", opus45, opus41)
+	}
+
+	usd, known := p.Cost(Usage{Model: "claude-sonnet-4-5", InputTokens: 1000, OutputTokens: 1000, CacheCreationTokens: 1000, CacheReadTokens: 1000})
+	if !known || math.Abs(usd-(0.003+0.015+0.00375+0.0003)) > 1e-9 {
+		t.Errorf("sonnet cost = %v, %v", usd, known)
+	}
+	if _, known := p.Cost(Usage{Model: "mystery-1", InputTokens: 1}); known {
+		t.Error("unknown model should be reported as unpriced")
+	}
+
+	if err := os.MkdirAll(filepath.Join(town, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	override := `{"mystery": {"input": 2, "output": 4}, "claude-sonnet-4": {"input": 1, "output": 1}}`
+	if err := os.WriteFile(filepath.Join(town, PricingFile), []byte(override), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err = LoadPricing(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usd, known := p.Cost(Usage{Model: "mystery-1", OutputTokens: 1_000_000}); !known || usd != 4 {
+		t.Errorf("override cost = %v, %v", usd, known)
+	}
+	if usd, _ := p.Cost(Usage{Model: "claude-sonnet-4-5", InputTokens: 1_000_000}); usd != 1 {
+		t.Errorf("overridden sonnet = %v", usd)
+	}
+}
+
+func TestRecordDelta(t *testing.T) {
+	town := t.TempDir()
+	su := newSessionUsage(RuntimeClaude, "abc")
+	su.add(Usage{Model: "m", InputTokens: 100, OutputTokens: 10})
+
+	delta, err := RecordDelta(town, su)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := delta.Models["m"]; got.InputTokens != 100 || got.OutputTokens != 10 {
+		t.Errorf("first delta = %+v", got)
+	}
+
+	su.add(Usage{Model: "m", InputTokens: 5})
+	su.add(Usage{Model: "n", OutputTokens: 7})
+	delta, err = RecordDelta(town, su)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := delta.Models["m"]; got.InputTokens != 5 || got.OutputTokens != 0 {
+		t.Errorf("second delta m = %+v", got)
+	}
+	if got := delta.Models["n"]; got.OutputTokens != 7 {
+		t.Errorf("second delta n = %+v", got)
+	}
+
+	delta, err = RecordDelta(town, su)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delta.Models) != 0 {
+		t.Errorf("unchanged transcript delta = %+v, want empty", delta.Models)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	p, _ := LoadPricing("")
+	mk := func(session, rig, role, worker, bead string, usage ...Usage) Entry {
+		e := Entry{SessionID: session, Rig: rig, Role: role, Worker: worker, WorkItem: bead,
+			EndedAt: time.Date(2026, 1, 2, 12, 0, 0, 0, time.Local)}
+		e.PriceUsage(p, usage)
+		return e
+	}
+	entries := []Entry{
+		mk("gt-gastown-toast", "gastown", "polecat", "toast", "gt-1",
+			Usage{Model: "claude-sonnet-4-5", OutputTokens: 1_000_000},
+			Usage{Model: "claude-haiku-4-5", OutputTokens: 1_000_000}),
+		mk("gt-gastown-toast", "gastown", "polecat", "toast", "gt-1",
+			Usage{Model: "claude-sonnet-4-5", OutputTokens: 1_000_000}),
+		mk("gt-beads-nux", "beads", "polecat", "nux", "bd-9",
+			Usage{Model: "claude-sonnet-4-5", OutputTokens: 2_000_000}),
+		mk("hq-mayor", "", "mayor", "", "", Usage{Model: "mystery", OutputTokens: 10}),
+	}
+	convoyOf := func(bead string) string {
+		if bead == "gt-1" || bead == "bd-9" {
+			return "hq-cv-abc"
+		}
+		return ""
+	}
+
+	rows := Aggregate(entries, []string{ByConvoy}, convoyOf)
+	if len(rows) != 2 || rows[0].Key[0] != "hq-cv-abc" || rows[0].CostUSD != 65 || rows[0].Sessions != 2 {
+		t.Fatalf("by convoy = %+v", rows)
+	}
+	if rows[1].Key[0] != NoValue || !rows[1].Unpriced {
+		t.Errorf("mayor row = %+v, want (none) and unpriced", rows[1])
+	}
+
+	rows = Aggregate(entries, []string{ByPolecat, ByModel}, convoyOf)
+	got := make(map[string]float64)
+	for _, r := range rows {
+		got[r.Label()] = r.CostUSD
+	}
+	if got["gastown/toast / claude-sonnet-4-5"] != 30 || got["gastown/toast / claude-haiku-4-5"] != 5 || got["beads/nux / claude-sonnet-4-5"] != 30 {
+		t.Errorf("by polecat,model = %v", got)
+	}
+
+	if _, err := ParseDimensions("rigs, convoy"); err != nil {
+		t.Errorf("ParseDimensions plural: %v", err)
+	}
+	if _, err := ParseDimensions("planet"); err == nil {
+		t.Error("expected error for unknown dimension")
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	at := time.Now()
+	entries := []Entry{
+		{SessionID: "a", EndedAt: at, CostUSD: 1},
+		{SessionID: "a", EndedAt: at, CostUSD: 1},
+		{SessionID: "a", EndedAt: at.Add(time.Second), CostUSD: 1},
+	}
+	if got := Dedupe(entries); len(got) != 2 {
+		t.Errorf("Dedupe kept %d entries, want 2", len(got))
+	}
+}
+
+func TestCompact(t *testing.T) {
+	t0 := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	turn := func(at time.Time, bead string, usd float64, model string, out int64) Entry {
+		return Entry{SessionID: "gt-gastown-toast", RuntimeSession: "abc", WorkItem: bead, EndedAt: at, CostUSD: usd,
+			Models: []ModelCost{{Usage: Usage{Model: model, OutputTokens: out}, CostUSD: usd}}}
+	}
+	got := Compact([]Entry{
+		turn(t0, "gt-1", 1, "m1", 10),
+		turn(t0.Add(time.Minute), "gt-1", 2, "m1", 20),
+		turn(t0.Add(2*time.Minute), "gt-1", 4, "m2", 40),
+		turn(t0.Add(3*time.Minute), "gt-2", 8, "m1", 80),
+	})
+	if len(got) != 2 {
+		t.Fatalf("Compact = %d entries, want one per bead", len(got))
+	}
+	first := got[0]
+	if first.CostUSD != 7 || len(first.Models) != 2 || first.Models[0].OutputTokens != 30 {
+		t.Errorf("compacted entry = %+v", first)
+	}
+	if !first.StartedAt.Equal(t0) || !first.EndedAt.Equal(t0.Add(2*time.Minute)) {
+		t.Errorf("span = %v - %v", first.StartedAt, first.EndedAt)
+	}
+}
+
+func TestBudgetEvaluate(t *testing.T) {
+	now := time.Date(2026, 1, 8, 15, 0, 0, 0, time.UTC) // Thursday
+	entries := []Entry{
+		{Rig: "gastown", EndedAt: now.Add(-time.Hour), CostUSD: 30},
+		{Rig: "gastown", EndedAt: now.AddDate(0, 0, -2), CostUSD: 15}, // Tuesday
+		{Rig: "beads", EndedAt: now.Add(-time.Hour), CostUSD: 100},
+	}
+	inGastown := func(e Entry) bool { return e.Rig == "gastown" }
+
+	tests := []struct {
+		budget Budget
+		spent  float64
+		level  string
+	}{
+		{Budget{LimitUSD: 100, Period: PeriodDay}, 30, LevelOK},
+		{Budget{LimitUSD: 50, Period: PeriodWeek}, 45, LevelWarn},
+		{Budget{LimitUSD: 50, Period: PeriodWeek, WarnAt: 0.95}, 45, LevelOK},
+		{Budget{LimitUSD: 40, Period: PeriodMonth}, 45, LevelCapped},
+		{Budget{LimitUSD: 40, Period: PeriodTotal, Since: now.AddDate(0, 0, -1)}, 30, LevelOK},
+	}
+	for _, tt := range tests {
+		st := tt.budget.Evaluate(entries, inGastown, now)
+		if math.Abs(st.SpentUSD-tt.spent) > 1e-9 || st.Level != tt.level {
+			t.Errorf("%s budget $%.0f: spent %.2f level %s, want %.2f %s",
+				tt.budget.Period, tt.budget.LimitUSD, st.SpentUSD, st.Level, tt.spent, tt.level)
+		}
+	}
+
+	week := Budget{Period: PeriodWeek}.Start(now)
+	if want := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC); !week.Equal(want) {
+		t.Errorf("week start = %v, want Monday %v", week, want)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	for in, want := range map[string]float64{"$40": 40, "40": 40, " 12.50 ": 12.5} {
+		got, err := ParseAmount(in)
+		if err != nil || got != want {
+			t.Errorf("ParseAmount(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "$", "forty", "-5", "0"} {
+		if _, err := ParseAmount(in); err == nil {
+			t.Errorf("ParseAmount(%q) should fail", in)
+		}
+	}
+}
+
+func TestBudgetStateCrossed(t *testing.T) {
+	townRoot := t.TempDir()
+	day := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	prev, err := LoadBudgetState(townRoot)
+	if err != nil || len(prev) != 0 {
+		t.Fatalf("LoadBudgetState on empty town = %v, %v", prev, err)
+	}
+
+	cur := []BudgetStatus{
+		{Scope: RigScope("gastown"), Since: day, Level: LevelWarn},
+		{Scope: ConvoyScope("hq-cv-abc"), Since: day, Level: LevelOK},
+	}
+	if got := Crossed(prev, cur); len(got) != 1 || got[0].Scope != "rig:gastown" {
+		t.Fatalf("first crossing = %+v, want rig:gastown only", got)
+	}
+	if err := SaveBudgetState(townRoot, map[string]BudgetStatus{cur[0].Scope: cur[0], cur[1].Scope: cur[1]}); err != nil {
+		t.Fatal(err)
+	}
+	prev, err = LoadBudgetState(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same level again: nothing new
+	if got := Crossed(prev, cur); len(got) != 0 {
+		t.Errorf("repeat crossing = %+v, want none", got)
+	}
+	// warn -> capped escalates
+	cur[0].Level = LevelCapped
+	if got := Crossed(prev, cur); len(got) != 1 || got[0].Level != LevelCapped {
+		t.Errorf("warn->capped = %+v, want one capped", got)
+	}
+	// A new period starts from ok
+	cur[0] = BudgetStatus{Scope: RigScope("gastown"), Since: day.AddDate(0, 0, 1), Level: LevelWarn}
+	if got := Crossed(prev, cur); len(got) != 1 {
+		t.Errorf("new period crossing = %+v, want one", got)
+	}
+}
@@ -0,0 +1,345 @@
+package costs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// ModelCost is one model's share of a ledger entry.
+type ModelCost struct {
+	Usage
+	CostUSD  float64 `json:"cost_usd"`
+	Unpriced bool    `json:"unpriced,omitempty"`
+}
+
+// Entry is one record in the cost ledger: the usage one agent session
+// accrued since its previous record. It is the payload of a session.ended
+// wisp and the element of a daily digest.
+type Entry struct {
+	SessionID string    `json:"session_id"`
+	Role      string    `json:"role"`
+	Rig       string    `json:"rig,omitempty"`
+	Worker    string    `json:"worker,omitempty"`
+	CostUSD   float64   `json:"cost_usd"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	WorkItem  string    `json:"work_item,omitempty"`
+
+	// Agent is the agent address (e.g. gastown/polecats/toast).
+	Agent string `json:"agent,omitempty"`
+	// Runtime and RuntimeSession identify the transcript the usage came from.
+	Runtime        string `json:"runtime,omitempty"`
+	RuntimeSession string `json:"runtime_session,omitempty"`
+	// Models breaks the entry down per model. Entries recorded before
+	// token accounting have none.
+	Models []ModelCost `json:"models,omitempty"`
+}
+
+// Tokens returns the entry's usage summed across models.
+func (e Entry) Tokens() Usage {
+	var total Usage
+	for _, m := range e.Models {
+		total.add(m.Usage)
+	}
+	return total
+}
+
+// PriceUsage fills Models and CostUSD from per-model usage.
+func (e *Entry) PriceUsage(p Pricing, usage []Usage) {
+	e.Models = e.Models[:0]
+	e.CostUSD = 0
+	for _, u := range usage {
+		usd, known := p.Cost(u)
+		e.Models = append(e.Models, ModelCost{Usage: u, CostUSD: usd, Unpriced: !known})
+		e.CostUSD += usd
+	}
+}
+
+// Dedupe drops entries recorded twice (a wisp whose digest was written but
+// which failed to burn shows up in both), keeping the first.
+func Dedupe(entries []Entry) []Entry {
+	seen := make(map[string]bool, len(entries))
+	out := entries[:0:0]
+	for _, e := range entries {
+		key := e.SessionID + "|" + e.RuntimeSession + "|" + e.EndedAt.UTC().Format(time.RFC3339Nano)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, e)
+	}
+	return out
+}
+
+// Dimensions gt costs can group by.
+const (
+	ByRig     = "rig"
+	ByRole    = "role"
+	ByPolecat = "polecat"
+	ByConvoy  = "convoy"
+	ByBead    = "bead"
+	ByModel   = "model"
+	ByAgent   = "agent"
+	ByDay     = "day"
+	BySession = "session"
+)
+
+// Dimensions lists the valid grouping dimensions.
+var Dimensions = []string{ByRig, ByRole, ByPolecat, ByConvoy, ByBead, ByModel, ByAgent, ByDay, BySession}
+
+// NoValue labels entries that have nothing for a dimension (a mayor
+// session has no rig, a patrol has no bead).
+const NoValue = "(none)"
+
+// ParseDimensions parses a comma-separated --by value.
+func ParseDimensions(s string) ([]string, error) {
+	var dims []string
+	for _, d := range strings.Split(s, ",") {
+		d = strings.TrimSpace(strings.ToLower(d))
+		if d == "" {
+			continue
+		}
+		d = strings.TrimSuffix(d, "s") // Accept plurals: rigs, roles, polecats
+		valid := false
+		for _, known := range Dimensions {
+			if d == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown dimension %q (valid: %s)", d, strings.Join(Dimensions, ", "))
+		}
+		dims = append(dims, d)
+	}
+	return dims, nil
+}
+
+// Row is one group in an aggregation.
+type Row struct {
+	Key      []string `json:"key"`
+	CostUSD  float64  `json:"cost_usd"`
+	Tokens   Usage    `json:"tokens"`
+	Sessions int      `json:"sessions"`
+	Entries  int      `json:"entries"`
+	Unpriced bool     `json:"unpriced,omitempty"`
+}
+
+// Label joins the row key for display.
+func (r Row) Label() string {
+	return strings.Join(r.Key, " / ")
+}
+
+// Aggregate groups entries by the given dimensions, most expensive first.
+// convoyOf maps a bead to the convoy tracking it; it may be nil. Grouping
+// by model splits each entry into its per-model parts.
+func Aggregate(entries []Entry, dims []string, convoyOf func(bead string) string) []Row {
+	type acc struct {
+		row      Row
+		sessions map[string]bool
+	}
+	groups := make(map[string]*acc)
+	var order []string
+
+	byModel := false
+	for _, d := range dims {
+		if d == ByModel {
+			byModel = true
+		}
+	}
+
+	for _, e := range entries {
+		whole := ModelCost{Usage: e.Tokens(), CostUSD: e.CostUSD}
+		for _, m := range e.Models {
+			whole.Unpriced = whole.Unpriced || m.Unpriced
+		}
+		parts := []ModelCost{whole}
+		if byModel && len(e.Models) > 0 {
+			parts = e.Models
+		}
+		for _, part := range parts {
+			key := make([]string, len(dims))
+			for i, d := range dims {
+				key[i] = dimensionValue(e, part, d, convoyOf)
+			}
+			k := strings.Join(key, "\x00")
+			a := groups[k]
+			if a == nil {
+				a = &acc{row: Row{Key: key}, sessions: make(map[string]bool)}
+				groups[k] = a
+				order = append(order, k)
+			}
+			a.row.CostUSD += part.CostUSD
+			a.row.Tokens.add(part.Usage)
+			a.row.Entries++
+			a.row.Unpriced = a.row.Unpriced || part.Unpriced
+			a.sessions[e.SessionID] = true
+		}
+	}
+
+	rows := make([]Row, 0, len(order))
+	for _, k := range order {
+		a := groups[k]
+		a.row.Sessions = len(a.sessions)
+		rows = append(rows, a.row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].CostUSD != rows[j].CostUSD {
+			return rows[i].CostUSD > rows[j].CostUSD
+		}
+		return rows[i].Label() < rows[j].Label()
+	})
+	return rows
+}
+
+func dimensionValue(e Entry, part ModelCost, dim string, convoyOf func(string) string) string {
+	v := ""
+	switch dim {
+	case ByRig:
+		v = e.Rig
+	case ByRole:
+		v = e.Role
+	case ByPolecat:
+		if e.Role == constants.RolePolecat && e.Worker != "" {
+			v = e.Worker
+			if e.Rig != "" {
+				v = e.Rig + "/" + e.Worker
+			}
+		}
+	case ByConvoy:
+		if convoyOf != nil && e.WorkItem != "" {
+			v = convoyOf(e.WorkItem)
+		}
+	case ByBead:
+		v = e.WorkItem
+	case ByModel:
+		v = part.Model
+	case ByAgent:
+		v = e.Agent
+	case ByDay:
+		if !e.EndedAt.IsZero() {
+			v = e.EndedAt.Local().Format("2006-01-02")
+		}
+	case BySession:
+		v = e.SessionID
+	}
+	if v == "" {
+		return NoValue
+	}
+	return v
+}
+
+// stateDir holds what has already been recorded per runtime session.
+func stateDir(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "costs")
+}
+
+func statePath(townRoot string, su *SessionUsage) string {
+	name := nonAlnum.ReplaceAllString(su.Runtime+"-"+su.SessionID, "_")
+	return filepath.Join(stateDir(townRoot), name+".json")
+}
+
+// RecordDelta returns the usage in cur that has not been recorded yet and
+// marks cur as recorded. Stop hooks fire on every turn with the full
+// transcript, so this is what keeps each token in exactly one entry.
+func RecordDelta(townRoot string, cur *SessionUsage) (*SessionUsage, error) {
+	path := statePath(townRoot, cur)
+
+	prev := newSessionUsage(cur.Runtime, cur.SessionID)
+	if data, err := os.ReadFile(path); err == nil { //nolint:gosec // G304: path is constructed from town root
+		if err := json.Unmarshal(data, prev); err != nil || prev.Models == nil {
+			prev = newSessionUsage(cur.Runtime, cur.SessionID)
+		}
+	}
+
+	delta := newSessionUsage(cur.Runtime, cur.SessionID)
+	for model, u := range cur.Models {
+		delta.add(u.sub(prev.Models[model]))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating cost state dir: %w", err)
+	}
+	data, err := json.Marshal(cur)
+	if err != nil {
+		return nil, err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil { //nolint:gosec // G306: not sensitive
+		return nil, fmt.Errorf("writing cost state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, fmt.Errorf("writing cost state: %w", err)
+	}
+	return delta, nil
+}
+
+// PruneState removes per-session state untouched for longer than maxAge,
+// for sessions that ended long ago.
+func PruneState(townRoot string, maxAge time.Duration) int {
+	entries, err := os.ReadDir(stateDir(townRoot))
+	if err != nil {
+		return 0
+	}
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if os.Remove(filepath.Join(stateDir(townRoot), e.Name())) == nil {
+			removed++
+		}
+	}
+	return removed
+}
+
+// Compact folds entries for the same session, runtime session and bead
+// into one, summing usage per model. The result keeps the earliest start
+// and latest end. Used to keep daily digests small.
+func Compact(entries []Entry) []Entry {
+	index := make(map[string]int)
+	var out []Entry
+	for _, e := range entries {
+		key := strings.Join([]string{e.SessionID, e.RuntimeSession, e.WorkItem, e.Agent}, "|")
+		i, ok := index[key]
+		if !ok {
+			e.Models = append([]ModelCost(nil), e.Models...)
+			if e.StartedAt.IsZero() {
+				e.StartedAt = e.EndedAt
+			}
+			index[key] = len(out)
+			out = append(out, e)
+			continue
+		}
+		c := &out[i]
+		c.CostUSD += e.CostUSD
+		if !e.StartedAt.IsZero() && e.StartedAt.Before(c.StartedAt) {
+			c.StartedAt = e.StartedAt
+		}
+		if e.EndedAt.After(c.EndedAt) {
+			c.EndedAt = e.EndedAt
+		}
+	models:
+		for _, m := range e.Models {
+			for j := range c.Models {
+				if c.Models[j].Model == m.Model {
+					c.Models[j].add(m.Usage)
+					c.Models[j].CostUSD += m.CostUSD
+					c.Models[j].Unpriced = c.Models[j].Unpriced || m.Unpriced
+					continue models
+				}
+			}
+			c.Models = append(c.Models, m)
+		}
+	}
+	return out
+}
@@ -0,0 +1,291 @@
+package costs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Runtimes whose transcripts can be parsed.
+const (
+	RuntimeClaude = "claude"
+	RuntimeCodex  = "codex"
+)
+
+// maxTranscriptLine bounds a single transcript line; tool results can be large.
+const maxTranscriptLine = 16 * 1024 * 1024
+
+// claudeLine is the subset of a Claude Code transcript line we need.
+type claudeLine struct {
+	Type      string `json:"type"`
+	SessionID string `json:"sessionId"`
+	Message   struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage *struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+// ParseClaudeTranscript sums token usage in a Claude Code transcript (the
+// JSONL file named by transcript_path in hook input). A message streamed
+// over several lines repeats its id; only its last usage counts.
+func ParseClaudeTranscript(r io.Reader) (*SessionUsage, error) {
+	su := newSessionUsage(RuntimeClaude, "")
+	byMessage := make(map[string]Usage)
+	var order []string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxTranscriptLine)
+	anon := 0
+	for scanner.Scan() {
+		var line claudeLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue // Partial trailing line or a line type we don't model
+		}
+		if su.SessionID == "" {
+			su.SessionID = line.SessionID
+		}
+		if line.Type != "assistant" || line.Message.Usage == nil {
+			continue
+		}
+		model := line.Message.Model
+		if model == "" || model == "<synthetic>" {
+			continue // Locally generated, not billed
+		}
+		id := line.Message.ID
+		if id == "" {
+			anon++
+			id = fmt.Sprintf("#%d", anon)
+		}
+		if _, seen := byMessage[id]; !seen {
+			order = append(order, id)
+		}
+		u := line.Message.Usage
+		byMessage[id] = Usage{
+			Model:               model,
+			InputTokens:         u.InputTokens,
+			OutputTokens:        u.OutputTokens,
+			CacheCreationTokens: u.CacheCreationInputTokens,
+			CacheReadTokens:     u.CacheReadInputTokens,
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading transcript: %w", err)
+	}
+	for _, id := range order {
+		su.add(byMessage[id])
+	}
+	return su, nil
+}
+
+// codexLine is the subset of a Codex session log line we need.
+type codexLine struct {
+	Type    string `json:"type"`
+	Payload struct {
+		Type  string `json:"type"`
+		ID    string `json:"id"`
+		Cwd   string `json:"cwd"`
+		Model string `json:"model"`
+		Info  *struct {
+			TotalTokenUsage struct {
+				InputTokens       int64 `json:"input_tokens"`
+				CachedInputTokens int64 `json:"cached_input_tokens"`
+				OutputTokens      int64 `json:"output_tokens"`
+			} `json:"total_token_usage"`
+		} `json:"info"`
+	} `json:"payload"`
+}
+
+// ParseCodexSession sums token usage in a Codex session log
+// (~/.codex/sessions/YYYY/MM/DD/*.jsonl). Codex reports running totals, so
+// each token_count is charged as the growth since the previous one, to the
+// model in effect at the time. Cached input is part of Codex's input count
+// and is split out as cache reads.
+func ParseCodexSession(r io.Reader) (*SessionUsage, error) {
+	su := newSessionUsage(RuntimeCodex, "")
+	model := ""
+	var prev Usage
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxTranscriptLine)
+	for scanner.Scan() {
+		var line codexLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+		switch {
+		case line.Type == "session_meta":
+			if su.SessionID == "" {
+				su.SessionID = line.Payload.ID
+			}
+		case line.Type == "turn_context":
+			if line.Payload.Model != "" {
+				model = line.Payload.Model
+			}
+		case line.Type == "event_msg" && line.Payload.Type == "token_count" && line.Payload.Info != nil:
+			t := line.Payload.Info.TotalTokenUsage
+			total := Usage{
+				InputTokens:     t.InputTokens - t.CachedInputTokens,
+				OutputTokens:    t.OutputTokens,
+				CacheReadTokens: t.CachedInputTokens,
+			}
+			delta := total.sub(prev)
+			delta.Model = model
+			if delta.Model == "" {
+				delta.Model = "unknown"
+			}
+			su.add(delta)
+			prev = total
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading session log: %w", err)
+	}
+	return su, nil
+}
+
+// ParseFile parses a transcript for the given runtime.
+func ParseFile(runtime, path string) (*SessionUsage, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: transcript path comes from the runtime
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var su *SessionUsage
+	switch runtime {
+	case RuntimeClaude, "":
+		su, err = ParseClaudeTranscript(f)
+	case RuntimeCodex:
+		su, err = ParseCodexSession(f)
+	default:
+		return nil, fmt.Errorf("unsupported runtime %q (supported: %s, %s)", runtime, RuntimeClaude, RuntimeCodex)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if su.SessionID == "" {
+		su.SessionID = strings.TrimSuffix(filepath.Base(path), ".jsonl")
+	}
+	return su, nil
+}
+
+var nonAlnum = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// claudeConfigDir returns the Claude config dir, honouring CLAUDE_CONFIG_DIR
+// (set per account by gt account).
+func claudeConfigDir() string {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".claude")
+}
+
+// FindClaudeTranscript returns the most recently written transcript for a
+// working directory. Claude Code keeps them under projects/<slug>, where the
+// slug is the absolute path with every non-alphanumeric rune replaced by '-'.
+func FindClaudeTranscript(workDir string) (string, error) {
+	dir := claudeConfigDir()
+	if dir == "" {
+		return "", fmt.Errorf("claude config dir not found")
+	}
+	projectDir := filepath.Join(dir, "projects", nonAlnum.ReplaceAllString(workDir, "-"))
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		return "", fmt.Errorf("no claude transcripts for %s: %w", workDir, err)
+	}
+	var latest string
+	var latestMod time.Time
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".jsonl") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if latest == "" || info.ModTime().After(latestMod) {
+			latest = filepath.Join(projectDir, e.Name())
+			latestMod = info.ModTime()
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no claude transcripts for %s", workDir)
+	}
+	return latest, nil
+}
+
+// FindCodexSession returns the most recently written Codex session log
+// whose session_meta cwd is workDir.
+func FindCodexSession(workDir string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	root := filepath.Join(home, ".codex", "sessions")
+	if codexHome := os.Getenv("CODEX_HOME"); codexHome != "" {
+		root = filepath.Join(codexHome, "sessions")
+	}
+
+	var latest string
+	var latestMod time.Time
+	_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".jsonl") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || (latest != "" && !info.ModTime().After(latestMod)) {
+			return nil
+		}
+		if codexSessionCwd(path) == workDir {
+			latest, latestMod = path, info.ModTime()
+		}
+		return nil
+	})
+	if latest == "" {
+		return "", fmt.Errorf("no codex sessions for %s", workDir)
+	}
+	return latest, nil
+}
+
+// codexSessionCwd reads the cwd from a session log's session_meta line,
+// which Codex writes first.
+func codexSessionCwd(path string) string {
+	f, err := os.Open(path) //nolint:gosec // G304: path found under the codex sessions dir
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxTranscriptLine)
+	for i := 0; i < 5 && scanner.Scan(); i++ {
+		var line codexLine
+		if json.Unmarshal(scanner.Bytes(), &line) == nil && line.Type == "session_meta" {
+			return line.Payload.Cwd
+		}
+	}
+	return ""
+}
+
+// FindTranscript locates the current transcript for a runtime and directory.
+func FindTranscript(runtime, workDir string) (string, error) {
+	if runtime == RuntimeCodex {
+		return FindCodexSession(workDir)
+	}
+	return FindClaudeTranscript(workDir)
+}
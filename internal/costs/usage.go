@@ -0,0 +1,177 @@
+// Package costs parses runtime transcripts into token usage, prices it, and
+// aggregates the session cost ledger that gt costs reads.
+//
+// Usage comes from the runtime's own records (Claude Code transcripts, Codex
+// session logs) rather than anything rendered in the pane. Each gt costs
+// record call stores the usage since the previous call for that session as
+// a session.ended wisp, so entries can simply be summed.
+package costs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Usage is token usage for one model.
+type Usage struct {
+	Model               string `json:"model"`
+	InputTokens         int64  `json:"input_tokens"`
+	OutputTokens        int64  `json:"output_tokens"`
+	CacheCreationTokens int64  `json:"cache_creation_tokens,omitempty"`
+	CacheReadTokens     int64  `json:"cache_read_tokens,omitempty"`
+}
+
+// Total returns all tokens counted in u.
+func (u Usage) Total() int64 {
+	return u.InputTokens + u.OutputTokens + u.CacheCreationTokens + u.CacheReadTokens
+}
+
+// IsZero reports whether u has no tokens.
+func (u Usage) IsZero() bool {
+	return u.Total() == 0
+}
+
+func (u *Usage) add(o Usage) {
+	u.InputTokens += o.InputTokens
+	u.OutputTokens += o.OutputTokens
+	u.CacheCreationTokens += o.CacheCreationTokens
+	u.CacheReadTokens += o.CacheReadTokens
+}
+
+// sub returns u - o, clamped at zero per field (a rewritten transcript can
+// report less than was already recorded).
+func (u Usage) sub(o Usage) Usage {
+	clamp := func(a, b int64) int64 {
+		if a < b {
+			return 0
+		}
+		return a - b
+	}
+	return Usage{
+		Model:               u.Model,
+		InputTokens:         clamp(u.InputTokens, o.InputTokens),
+		OutputTokens:        clamp(u.OutputTokens, o.OutputTokens),
+		CacheCreationTokens: clamp(u.CacheCreationTokens, o.CacheCreationTokens),
+		CacheReadTokens:     clamp(u.CacheReadTokens, o.CacheReadTokens),
+	}
+}
+
+// SessionUsage is cumulative usage for one runtime session, per model.
+type SessionUsage struct {
+	Runtime   string           `json:"runtime"`
+	SessionID string           `json:"session_id"`
+	Models    map[string]Usage `json:"models"`
+}
+
+func newSessionUsage(runtime, sessionID string) *SessionUsage {
+	return &SessionUsage{Runtime: runtime, SessionID: sessionID, Models: make(map[string]Usage)}
+}
+
+func (s *SessionUsage) add(u Usage) {
+	if u.IsZero() {
+		return
+	}
+	cur := s.Models[u.Model]
+	cur.Model = u.Model
+	cur.add(u)
+	s.Models[u.Model] = cur
+}
+
+// Usages returns per-model usage sorted by model name.
+func (s *SessionUsage) Usages() []Usage {
+	out := make([]Usage, 0, len(s.Models))
+	for _, u := range s.Models {
+		out = append(out, u)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Model < out[j].Model })
+	return out
+}
+
+// Price is USD per million tokens.
+type Price struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheWrite float64 `json:"cache_write"`
+	CacheRead  float64 `json:"cache_read"`
+}
+
+// DefaultPrices are list prices keyed by model-name prefix; the longest
+// matching prefix wins. Towns override or extend them in settings/pricing.json.
+var DefaultPrices = map[string]Price{
+	"claude-opus-4-5":   {Input: 5, Output: 25, CacheWrite: 6.25, CacheRead: 0.50},
+	"claude-opus-4":     {Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.50},
+	"claude-sonnet-4":   {Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30},
+	"claude-3-7-sonnet": {Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30},
+	"claude-3-5-sonnet": {Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30},
+	"claude-haiku-4-5":  {Input: 1, Output: 5, CacheWrite: 1.25, CacheRead: 0.10},
+	"claude-3-5-haiku":  {Input: 0.80, Output: 4, CacheWrite: 1, CacheRead: 0.08},
+	"gpt-5-codex":       {Input: 1.25, Output: 10, CacheRead: 0.125},
+	"gpt-5-mini":        {Input: 0.25, Output: 2, CacheRead: 0.025},
+	"gpt-5":             {Input: 1.25, Output: 10, CacheRead: 0.125},
+}
+
+// PricingFile is the town-level price override file, relative to the town root.
+const PricingFile = "settings/pricing.json"
+
+// Pricing maps model-name prefixes to prices.
+type Pricing map[string]Price
+
+// LoadPricing returns DefaultPrices merged with the town's overrides.
+// A missing override file is not an error.
+func LoadPricing(townRoot string) (Pricing, error) {
+	p := make(Pricing, len(DefaultPrices))
+	for k, v := range DefaultPrices {
+		p[k] = v
+	}
+	if townRoot == "" {
+		return p, nil
+	}
+	data, err := os.ReadFile(filepath.Join(townRoot, PricingFile)) //nolint:gosec // G304: path is constructed from town root
+	if err != nil {
+		if os.IsNotExist(err) {
+			return p, nil
+		}
+		return p, fmt.Errorf("reading pricing: %w", err)
+	}
+	var overrides map[string]Price
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return p, fmt.Errorf("parsing %s: %w", PricingFile, err)
+	}
+	for k, v := range overrides {
+		p[k] = v
+	}
+	return p, nil
+}
+
+// Lookup returns the price for a model by longest matching prefix.
+func (p Pricing) Lookup(model string) (Price, bool) {
+	best := ""
+	for prefix := range p {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return p[best], true
+}
+
+// Cost prices u. known is false when the model has no price, in which case
+// the cost is zero and callers should flag it rather than hide it.
+func (p Pricing) Cost(u Usage) (usd float64, known bool) {
+	price, ok := p.Lookup(u.Model)
+	if !ok {
+		return 0, false
+	}
+	const perToken = 1.0 / 1_000_000
+	usd = float64(u.InputTokens)*price.Input*perToken +
+		float64(u.OutputTokens)*price.Output*perToken +
+		float64(u.CacheCreationTokens)*price.CacheWrite*perToken +
+		float64(u.CacheReadTokens)*price.CacheRead*perToken
+	return usd, true
+}
@@ -181,9 +181,9 @@ func (m *Manager) Add(name string, createBranch bool) (*CrewWorker, error) {
 		fmt.Printf("Warning: could not provision PRIME.md: %v\n", err)
 	}
 
-	// Copy overlay files from .runtime/overlay/ to crew root.
+	// Apply overlay files from .runtime/overlay/ to crew root.
 	// This allows services to have .env and other config files at their root.
-	if err := rig.CopyOverlay(m.rig.Path, crewPath); err != nil {
+	if err := rig.ApplyOverlay(m.rig.Path, crewPath, rig.OverlayScope{Role: "crew", Name: name}); err != nil {
 		// Non-fatal - log warning but continue
 		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
 	}
@@ -0,0 +1,140 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/account"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/runtime"
+)
+
+// migrationCooldown keeps a session that was just moved to another account
+// from being moved again while the old banner may still be on screen.
+const migrationCooldown = 10 * time.Minute
+
+// checkPolecatRateLimit looks for a rate-limit banner in a live polecat
+// session. The account it ran on is marked rate-limited until the limit
+// resets, so spawns avoid it, and with rotation on (accounts.json "rotate")
+// the session is moved to the account with the most quota left.
+func (d *Daemon) checkPolecatRateLimit(rigName, polecatName, sessionName string) {
+	townRoot := d.config.TownRoot
+	cfg, err := config.LoadAccountsConfig(constants.MayorAccountsPath(townRoot))
+	if err != nil || len(cfg.Accounts) == 0 {
+		return
+	}
+	state, err := account.LoadState(townRoot)
+	if err != nil {
+		return
+	}
+	if sa := state.Sessions[sessionName]; sa != nil && time.Since(sa.MigratedAt) < migrationCooldown {
+		return
+	}
+
+	rigPath := filepath.Join(townRoot, rigName)
+	adapter := runtime.ForRole("polecat", townRoot, rigPath, "")
+	obs, err := runtime.ObserveSession(d.tmux, adapter, sessionName)
+	if err != nil || obs.State != runtime.StateRateLimited {
+		return
+	}
+	if obs.ResetAt.IsZero() {
+		obs.ResetAt = time.Now().Add(account.DefaultRateLimitCooldown)
+	}
+
+	// Sessions started without an account run on ~/.claude, which
+	// gt account switch points at the default account
+	fromDir, _ := d.tmux.GetEnvironment(sessionName, "CLAUDE_CONFIG_DIR")
+	from := cfg.Default
+	if fromDir != "" {
+		from = account.HandleForConfigDir(cfg, fromDir)
+	} else if home, err := os.UserHomeDir(); err == nil {
+		fromDir = filepath.Join(home, ".claude")
+	}
+
+	if from == "" {
+		// Not an account of accounts.json: nothing to mark, but the
+		// session can still move to one that has quota
+		d.logger.Printf("RATE LIMIT: polecat %s/%s on unmanaged config dir %s until %s: %s",
+			rigName, polecatName, fromDir, obs.ResetAt.Format(time.RFC3339), obs.Detail)
+	} else {
+		d.logger.Printf("RATE LIMIT: polecat %s/%s on account %q until %s: %s",
+			rigName, polecatName, from, obs.ResetAt.Format(time.RFC3339), obs.Detail)
+		if err := account.RecordRateLimit(townRoot, from, obs.ResetAt, obs.Detail); err != nil {
+			d.logger.Printf("Warning: recording rate limit: %v", err)
+		}
+	}
+
+	if !cfg.Rotate || len(cfg.Accounts) < 2 {
+		return
+	}
+	to, err := account.Pick(townRoot, cfg, from)
+	if err != nil || to == "" {
+		d.logger.Printf("No account with quota left for %s/%s; leaving it rate-limited", rigName, polecatName)
+		return
+	}
+	if err := d.migratePolecatSession(adapter, rigName, polecatName, sessionName, fromDir, to, cfg); err != nil {
+		d.logger.Printf("Error moving %s/%s to account %q: %v", rigName, polecatName, to, err)
+		return
+	}
+	d.logger.Printf("Moved polecat %s/%s from account %q to %q", rigName, polecatName, from, to)
+}
+
+// migratePolecatSession restarts a polecat's agent in place on another
+// account. Claude Code keeps its conversations under the account's config
+// dir, so its latest one is copied to the new account's and resumed, and
+// the session picks up where it stopped; other agents start fresh and
+// recover through gt prime.
+func (d *Daemon) migratePolecatSession(adapter runtime.Adapter, rigName, polecatName, sessionName, fromDir, to string, cfg *config.AccountsConfig) error {
+	townRoot := d.config.TownRoot
+	rigPath := filepath.Join(townRoot, rigName)
+	workDir := polecatWorkDir(rigPath, rigName, polecatName)
+	toDir := cfg.GetAccount(to).ResolvedConfigDir()
+
+	envVars := config.AgentEnv(config.AgentEnvConfig{
+		Role:             "polecat",
+		Rig:              rigName,
+		AgentName:        polecatName,
+		TownRoot:         townRoot,
+		RuntimeConfigDir: toDir,
+		BeadsNoDaemon:    true,
+	})
+	startCmd := config.BuildStartupCommand(envVars, rigPath, "")
+
+	id := ""
+	if adapter.Name() == string(config.AgentClaude) {
+		id = account.LatestSession(fromDir, workDir)
+	}
+	if id != "" {
+		rc := config.ResolveRoleAgentConfig("polecat", townRoot, rigPath)
+		if resumeCmd := adapter.ResumeCommand(rc, id); resumeCmd != "" {
+			if err := account.CopySession(fromDir, toDir, workDir, id); err != nil {
+				d.logger.Printf("Warning: %v; starting a fresh session", err)
+			} else {
+				if rc.Session != nil && rc.Session.SessionIDEnv != "" {
+					envVars["GT_SESSION_ID_ENV"] = rc.Session.SessionIDEnv
+				}
+				startCmd = config.PrependEnv(resumeCmd, envVars)
+			}
+		}
+	}
+
+	for k, v := range envVars {
+		_ = d.tmux.SetEnvironment(sessionName, k, v)
+	}
+	_ = d.tmux.ClearHistory(sessionName)
+	if err := d.tmux.RespawnPane(sessionName, startCmd); err != nil {
+		return fmt.Errorf("respawning pane: %w", err)
+	}
+	if err := account.RecordMigration(townRoot, to, sessionName); err != nil {
+		d.logger.Printf("Warning: recording migration: %v", err)
+	}
+
+	if err := d.tmux.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
+		// Non-fatal - the agent might still start
+	}
+	_ = runtime.DismissDialogs(d.tmux, adapter, sessionName)
+	return nil
+}
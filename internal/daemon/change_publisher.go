@@ -0,0 +1,137 @@
+package daemon
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/changes"
+	"github.com/steveyegge/gastown/internal/session"
+)
+
+// ChangePublisher turns bd activity and tmux session churn into the typed
+// change stream (see internal/changes) that the web GUI follows, so live
+// views don't have to poll bd and tmux themselves.
+type ChangePublisher struct {
+	townRoot string
+	logger   func(format string, args ...interface{})
+
+	// lookup resolves an issue for classification. Defaults to beads Show,
+	// which reads the store in-process when it can.
+	lookup func(id string) (*beads.Issue, error)
+
+	mu       sync.Mutex
+	sessions map[string]string // session name -> agent address, nil until seeded
+}
+
+// NewChangePublisher creates a change publisher for a town.
+func NewChangePublisher(townRoot string, logger func(format string, args ...interface{})) *ChangePublisher {
+	bd := beads.New(townRoot)
+	return &ChangePublisher{
+		townRoot: townRoot,
+		logger:   logger,
+		lookup:   bd.Show,
+	}
+}
+
+// HandleActivity publishes the change described by one bd activity event.
+// The issue is looked up to decide whether it is mail, a merge request or an
+// agent bead; when that fails the change is published as a plain bead update.
+func (p *ChangePublisher) HandleActivity(event bdActivityEvent) {
+	if event.IssueID == "" {
+		return
+	}
+
+	c := changes.Change{
+		Kind:   changes.KindBeadUpdated,
+		ID:     event.IssueID,
+		Status: event.NewStatus,
+		Actor:  event.Actor,
+		Fields: map[string]string{"event": event.Type},
+	}
+	if event.OldStatus != "" {
+		c.Fields["old_status"] = event.OldStatus
+	}
+
+	if issue, err := p.lookup(event.IssueID); err == nil && issue != nil {
+		c.Title = issue.Title
+		if c.Status == "" {
+			c.Status = issue.Status
+		}
+		switch {
+		case isIssueKind(issue, "merge-request"):
+			c.Kind = changes.KindMRState
+			if fields := beads.ParseMRFields(issue); fields != nil {
+				c.Fields["branch"] = fields.Branch
+				c.Fields["worker"] = fields.Worker
+			}
+		case isIssueKind(issue, "message"):
+			if event.Type == "create" {
+				c.Kind = changes.KindMailArrived
+				c.Fields["to"] = issue.Assignee
+				for _, label := range issue.Labels {
+					if strings.HasPrefix(label, "from:") {
+						c.Fields["from"] = strings.TrimPrefix(label, "from:")
+					}
+				}
+			}
+		case isIssueKind(issue, "agent"):
+			c.Kind = changes.KindAgentState
+			if issue.AgentState != "" {
+				c.Status = issue.AgentState
+			}
+			if issue.HookBead != "" {
+				c.Fields["hook_bead"] = issue.HookBead
+			}
+		}
+	}
+
+	p.publish(c)
+}
+
+// CheckSessions diffs the running agent sessions against the previous call
+// and publishes an agent.state change for each one that started or stopped.
+// The first call only records the baseline.
+func (p *ChangePublisher) CheckSessions(names []string) {
+	current := make(map[string]string, len(names))
+	for _, name := range names {
+		identity, err := session.ParseSessionName(name)
+		if err != nil {
+			continue // Not a Gas Town agent session
+		}
+		current[name] = identity.Address()
+	}
+
+	p.mu.Lock()
+	previous := p.sessions
+	p.sessions = current
+	p.mu.Unlock()
+
+	if previous == nil {
+		return
+	}
+	for name, addr := range current {
+		if _, ok := previous[name]; !ok {
+			p.publish(changes.Change{Kind: changes.KindAgentState, ID: addr, Status: "running",
+				Fields: map[string]string{"session": name}})
+		}
+	}
+	for name, addr := range previous {
+		if _, ok := current[name]; !ok {
+			p.publish(changes.Change{Kind: changes.KindAgentState, ID: addr, Status: "stopped",
+				Fields: map[string]string{"session": name}})
+		}
+	}
+}
+
+func (p *ChangePublisher) publish(c changes.Change) {
+	if _, err := changes.Publish(p.townRoot, c); err != nil {
+		p.logger("change publisher: %v", err)
+	}
+}
+
+// isIssueKind reports whether an issue is of the given Gas Town kind, by
+// issue type or by its gt:<kind> label.
+func isIssueKind(issue *beads.Issue, kind string) bool {
+	return issue.Type == kind || beads.HasLabel(issue, "gt:"+kind)
+}
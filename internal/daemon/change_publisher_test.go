@@ -0,0 +1,94 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/changes"
+)
+
+func newTestPublisher(t *testing.T, issues map[string]*beads.Issue) (*ChangePublisher, string) {
+	t.Helper()
+	town := t.TempDir()
+	p := NewChangePublisher(town, func(string, ...interface{}) {})
+	p.lookup = func(id string) (*beads.Issue, error) {
+		if issue, ok := issues[id]; ok {
+			return issue, nil
+		}
+		return nil, beads.ErrNotFound
+	}
+	return p, town
+}
+
+func TestChangePublisher_ClassifiesActivity(t *testing.T) {
+	p, town := newTestPublisher(t, map[string]*beads.Issue{
+		"gt-mr1":  {ID: "gt-mr1", Title: "Merge Toast", Status: "open", Labels: []string{"gt:merge-request"}, Description: "branch: polecat/Toast/gt-1\nworker: Toast"},
+		"hq-msg1": {ID: "hq-msg1", Title: "Hello", Status: "open", Type: "message", Assignee: "mayor/", Labels: []string{"from:gastown/witness"}},
+		"gt-agt":  {ID: "gt-agt", Status: "open", Type: "agent", AgentState: "working", HookBead: "gt-1"},
+		"gt-1":    {ID: "gt-1", Title: "Fix it", Status: "in_progress", Type: "task"},
+	})
+
+	p.HandleActivity(bdActivityEvent{Type: "update", IssueID: "gt-mr1"})
+	p.HandleActivity(bdActivityEvent{Type: "create", IssueID: "hq-msg1"})
+	p.HandleActivity(bdActivityEvent{Type: "update", IssueID: "hq-msg1"})
+	p.HandleActivity(bdActivityEvent{Type: "update", IssueID: "gt-agt"})
+	p.HandleActivity(bdActivityEvent{Type: "status", IssueID: "gt-1", OldStatus: "open", NewStatus: "in_progress", Actor: "gastown/Toast"})
+	p.HandleActivity(bdActivityEvent{Type: "create", IssueID: "gt-unknown"})
+	p.HandleActivity(bdActivityEvent{Type: "create"}) // no issue: ignored
+
+	got, _, err := changes.Since(town, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		kind   changes.Kind
+		id     string
+		status string
+	}{
+		{changes.KindMRState, "gt-mr1", "open"},
+		{changes.KindMailArrived, "hq-msg1", "open"},
+		{changes.KindBeadUpdated, "hq-msg1", "open"},
+		{changes.KindAgentState, "gt-agt", "working"},
+		{changes.KindBeadUpdated, "gt-1", "in_progress"},
+		{changes.KindBeadUpdated, "gt-unknown", ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("published %d changes, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Kind != w.kind || got[i].ID != w.id || got[i].Status != w.status {
+			t.Errorf("change %d = %s %s %q, want %s %s %q", i, got[i].Kind, got[i].ID, got[i].Status, w.kind, w.id, w.status)
+		}
+	}
+	if got[0].Fields["branch"] != "polecat/Toast/gt-1" {
+		t.Errorf("MR fields = %v", got[0].Fields)
+	}
+	if got[1].Fields["from"] != "gastown/witness" || got[1].Fields["to"] != "mayor/" {
+		t.Errorf("mail fields = %v", got[1].Fields)
+	}
+	if got[4].Actor != "gastown/Toast" || got[4].Fields["old_status"] != "open" {
+		t.Errorf("status change = %+v", got[4])
+	}
+}
+
+func TestChangePublisher_CheckSessions(t *testing.T) {
+	p, town := newTestPublisher(t, nil)
+
+	// First call seeds the baseline without publishing
+	p.CheckSessions([]string{"hq-mayor", "gt-gastown-witness", "scratch"})
+	if got, _, _ := changes.Since(town, 0); len(got) != 0 {
+		t.Fatalf("baseline published %+v", got)
+	}
+
+	p.CheckSessions([]string{"hq-mayor", "gt-gastown-Toast", "scratch"})
+	got, _, _ := changes.Since(town, 0)
+	if len(got) != 2 {
+		t.Fatalf("changes = %+v, want one start and one stop", got)
+	}
+	if got[0].ID != "gastown/polecats/Toast" || got[0].Status != "running" {
+		t.Errorf("start = %+v", got[0])
+	}
+	if got[1].ID != "gastown/witness" || got[1].Status != "stopped" {
+		t.Errorf("stop = %+v", got[1])
+	}
+}
@@ -0,0 +1,108 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/checkpoint"
+)
+
+const (
+	// defaultCheckpointInterval is how often a working polecat is
+	// checkpointed when mayor/daemon.json doesn't set an interval.
+	defaultCheckpointInterval = 10 * time.Minute
+
+	// checkpointArchiveRetention is how long the archive of a polecat that
+	// no longer exists is kept for gt checkpoint restore.
+	checkpointArchiveRetention = 24 * time.Hour
+)
+
+// checkpointInterval returns how often working polecats are checkpointed.
+func checkpointInterval(config *DaemonPatrolConfig) time.Duration {
+	if config != nil && config.Patrols != nil && config.Patrols.Checkpoints != nil {
+		if d, err := time.ParseDuration(config.Patrols.Checkpoints.Interval); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultCheckpointInterval
+}
+
+// checkpointPolecat archives a polecat's state (git state, hooked bead and
+// a WIP patch of its uncommitted changes) so its work can be restored to a
+// new polecat if it crashes beyond repair. Unless force is set, polecats
+// checkpointed within the interval, and polecats without hooked work, are
+// skipped. Discovered from the archive itself, so daemon restarts don't
+// reset the schedule.
+func (d *Daemon) checkpointPolecat(rigName, polecatName string, force bool) {
+	if !IsPatrolEnabled(d.patrolConfig, "checkpoints") {
+		return
+	}
+	rigPath := filepath.Join(d.config.TownRoot, rigName)
+
+	if !force {
+		if cp, _, err := checkpoint.LoadArchive(rigPath, polecatName); err == nil && cp != nil &&
+			!cp.IsStale(checkpointInterval(d.patrolConfig)) {
+			return
+		}
+	}
+
+	workDir := polecatWorkDir(rigPath, rigName, polecatName)
+
+	prefix := beads.GetPrefixForRig(d.config.TownRoot, rigName)
+	info, err := d.getAgentBeadInfo(beads.PolecatBeadIDWithPrefix(prefix, rigName, polecatName))
+	if err != nil || info.HookBead == "" {
+		return
+	}
+
+	cp, patch, err := checkpoint.Snapshot(workDir)
+	if err != nil {
+		d.logger.Printf("Warning: checkpointing %s/%s: %v", rigName, polecatName, err)
+		return
+	}
+	cp.WithHookedBead(info.HookBead)
+	cp.SessionID = "daemon"
+	if err := checkpoint.Archive(rigPath, polecatName, cp, patch); err != nil {
+		d.logger.Printf("Warning: archiving checkpoint of %s/%s: %v", rigName, polecatName, err)
+	}
+}
+
+// polecatWorkDir returns a polecat's worktree, in the new layout
+// (polecats/<name>/<rig>/) or the old one (polecats/<name>/), as
+// restartPolecatSession resolves it.
+func polecatWorkDir(rigPath, rigName, polecatName string) string {
+	workDir := filepath.Join(rigPath, "polecats", polecatName, rigName)
+	if _, err := os.Stat(workDir); os.IsNotExist(err) {
+		workDir = filepath.Join(rigPath, "polecats", polecatName)
+	}
+	return workDir
+}
+
+// pruneCheckpointArchives removes the archives of polecats that no longer
+// exist once they are past retention.
+func (d *Daemon) pruneCheckpointArchives(rigName string, polecats []string) {
+	rigPath := filepath.Join(d.config.TownRoot, rigName)
+	entries, err := os.ReadDir(checkpoint.ArchiveRoot(rigPath))
+	if err != nil {
+		return
+	}
+
+	live := make(map[string]bool, len(polecats))
+	for _, name := range polecats {
+		live[name] = true
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || live[name] {
+			continue
+		}
+		cp, _, err := checkpoint.LoadArchive(rigPath, name)
+		if err == nil && cp != nil && !cp.IsStale(checkpointArchiveRetention) {
+			continue
+		}
+		if err := checkpoint.RemoveArchive(rigPath, name); err != nil {
+			d.logger.Printf("Warning: %v", err)
+		}
+	}
+}
@@ -22,6 +22,13 @@ type ConvoyWatcher struct {
 	cancel   context.CancelFunc
 	wg       sync.WaitGroup
 	logger   func(format string, args ...interface{})
+
+	// onActivity, if set, sees every parsed activity event before the
+	// close filter (used to feed the change stream).
+	onActivity func(event bdActivityEvent)
+
+	// onConvoyLanded, if set, is called when a convoy itself closes.
+	onConvoyLanded func(convoyID string)
 }
 
 // bdActivityEvent represents an event from bd activity --json.
@@ -33,6 +40,7 @@ type bdActivityEvent struct {
 	Message   string `json:"message"`
 	OldStatus string `json:"old_status,omitempty"`
 	NewStatus string `json:"new_status,omitempty"`
+	Actor     string `json:"actor,omitempty"`
 }
 
 // NewConvoyWatcher creates a new convoy watcher.
@@ -129,6 +137,10 @@ func (w *ConvoyWatcher) processLine(line string) {
 		return // Skip malformed lines
 	}
 
+	if w.onActivity != nil {
+		w.onActivity(event)
+	}
+
 	// Only interested in status changes to closed
 	if event.Type != "status" || event.NewStatus != "closed" {
 		return
@@ -136,6 +148,11 @@ func (w *ConvoyWatcher) processLine(line string) {
 
 	w.logger("convoy watcher: detected close of %s", event.IssueID)
 
+	if w.onConvoyLanded != nil && w.isConvoy(event.IssueID) {
+		w.logger("convoy watcher: convoy %s landed", event.IssueID)
+		w.onConvoyLanded(event.IssueID)
+	}
+
 	// Check if this issue is tracked by any convoy
 	convoyIDs := w.getTrackingConvoys(event.IssueID)
 	if len(convoyIDs) == 0 {
@@ -189,6 +206,28 @@ func (w *ConvoyWatcher) getTrackingConvoys(issueID string) []string {
 	return convoyIDs
 }
 
+// isConvoy reports whether an issue is a convoy.
+func (w *ConvoyWatcher) isConvoy(issueID string) bool {
+	dbPath := filepath.Join(w.townRoot, ".beads", "beads.db")
+	query := fmt.Sprintf(`SELECT issue_type FROM issues WHERE id = '%s'`,
+		strings.ReplaceAll(issueID, "'", "''"))
+
+	queryCmd := exec.Command("sqlite3", "-json", dbPath, query)
+	var stdout bytes.Buffer
+	queryCmd.Stdout = &stdout
+	if err := queryCmd.Run(); err != nil {
+		return false
+	}
+
+	var results []struct {
+		IssueType string `json:"issue_type"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil || len(results) == 0 {
+		return false
+	}
+	return results[0].IssueType == "convoy"
+}
+
 // checkConvoyCompletion checks if all issues tracked by a convoy are closed.
 // If so, runs gt convoy check to close the convoy.
 func (w *ConvoyWatcher) checkConvoyCompletion(convoyID string) {
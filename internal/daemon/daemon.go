@@ -20,13 +20,16 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/boot"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/connection"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/deacon"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/feed"
+	"github.com/steveyegge/gastown/internal/plugin"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/patrol"
@@ -48,6 +51,12 @@ type Daemon struct {
 	cancel       context.CancelFunc
 	curator      *feed.Curator
 	convoyWatcher *ConvoyWatcher
+	changes       *ChangePublisher
+	plugins       *plugin.Scheduler
+
+	// dockedRigs is the set of docked rigs as of the last heartbeat, for
+	// firing rig-docked plugin events. Only accessed from the heartbeat loop.
+	dockedRigs map[string]bool
 
 	// Mass death detection: track recent session deaths
 	deathsMu     sync.Mutex
@@ -170,8 +179,23 @@ func (d *Daemon) Run() error {
 		d.logger.Println("Feed curator started")
 	}
 
+	// Plugin gates are evaluated each heartbeat; startup opens event gates
+	// waiting for it
+	if IsPatrolEnabled(d.patrolConfig, "plugins") {
+		d.plugins = d.newPluginScheduler()
+		d.plugins.Fire(plugin.Event{Name: plugin.EventStartup})
+	}
+
 	// Start convoy watcher for event-driven convoy completion
 	d.convoyWatcher = NewConvoyWatcher(d.config.TownRoot, d.logger.Printf)
+	// The same activity stream feeds the typed change stream for the web GUI
+	d.changes = NewChangePublisher(d.config.TownRoot, d.logger.Printf)
+	d.convoyWatcher.onActivity = d.changes.HandleActivity
+	if d.plugins != nil {
+		d.convoyWatcher.onConvoyLanded = func(convoyID string) {
+			d.plugins.Fire(plugin.Event{Name: plugin.EventConvoyLanded, Subject: convoyID})
+		}
+	}
 	if err := d.convoyWatcher.Start(); err != nil {
 		d.logger.Printf("Warning: failed to start convoy watcher: %v", err)
 	} else {
@@ -277,7 +301,7 @@ func (d *Daemon) heartbeat(state *State) {
 
 	// 6. Trigger pending polecat spawns (bootstrap mode - ZFC violation acceptable)
 	// This ensures polecats get nudged even when Deacon isn't in a patrol cycle.
-	// Uses regex-based WaitForRuntimeReady, which is acceptable for daemon bootstrap.
+	// Uses the agents' pattern-based readiness checks, which is acceptable for daemon bootstrap.
 	d.triggerPendingSpawns()
 
 	// 7. Process lifecycle requests
@@ -303,6 +327,16 @@ func (d *Daemon) heartbeat(state *State) {
 	// 13. Check bd daemon health and restart if needed
 	d.checkBdDaemonHealth()
 
+	// 14. Rotate the events log and drop segments past retention
+	d.compactEventsLog()
+
+	// 15. Publish agent session starts/stops to the change stream
+	d.publishSessionChanges()
+
+	// 16. Evaluate plugin gates and dispatch due plugins to idle dogs
+	// (off when the plugins patrol is disabled in mayor/daemon.json)
+	d.runPluginScheduler()
+
 	// Update state
 	state.LastHeartbeat = time.Now()
 	state.HeartbeatCount++
@@ -492,6 +526,13 @@ func (d *Daemon) ensureWitnessRunning(rigName string) {
 		return
 	}
 
+	// Remote rigs: check the session over the connection and let the rig's
+	// machine start it (and attach its patrol molecule) itself.
+	if remote := d.rigRemote(rigName); remote != nil {
+		d.ensureRemoteAgentRunning(remote, rigName, "witness", session.WitnessSessionName(rigName))
+		return
+	}
+
 	// Manager.Start() handles: zombie detection, session creation, env vars, theming,
 	// startup readiness waits, and crucially - startup/propulsion nudges (GUPP).
 	// It returns ErrAlreadyRunning if Claude is already running in tmux.
@@ -539,6 +580,11 @@ func (d *Daemon) ensureRefineryRunning(rigName string) {
 		return
 	}
 
+	if remote := d.rigRemote(rigName); remote != nil {
+		d.ensureRemoteAgentRunning(remote, rigName, "refinery", session.RefinerySessionName(rigName))
+		return
+	}
+
 	// Manager.Start() handles: zombie detection, session creation, env vars, theming,
 	// WaitForClaudeReady, and crucially - startup/propulsion nudges (GUPP).
 	// It returns ErrAlreadyRunning if Claude is already running in tmux.
@@ -561,6 +607,82 @@ func (d *Daemon) ensureRefineryRunning(rigName string) {
 	d.logger.Printf("Refinery session for %s started successfully", rigName)
 }
 
+// rigRemote returns the connection for a rig hosted on another machine,
+// or nil if the rig is local (or its machine can't be resolved, which is logged).
+func (d *Daemon) rigRemote(rigName string) *connection.Remote {
+	rigsConfig, err := config.LoadRigsConfig(constants.MayorRigsPath(d.config.TownRoot))
+	if err != nil {
+		return nil
+	}
+	entry, ok := rigsConfig.Rigs[rigName]
+	if !ok {
+		return nil
+	}
+	addr := &connection.Address{Machine: entry.Machine, Rig: rigName}
+	if addr.IsLocal() {
+		return nil
+	}
+
+	reg, err := connection.NewMachineRegistry(connection.RegistryPath(d.config.TownRoot))
+	if err != nil {
+		d.logger.Printf("Error loading machine registry for %s: %v", rigName, err)
+		return nil
+	}
+	remote, err := reg.Remote(entry.Machine)
+	if err != nil {
+		d.logger.Printf("Error resolving machine %s for %s: %v", entry.Machine, rigName, err)
+		return nil
+	}
+	return remote
+}
+
+// ensureRemoteAgentRunning starts a rig agent (witness or refinery) on the
+// rig's machine if its tmux session isn't there.
+func (d *Daemon) ensureRemoteAgentRunning(remote *connection.Remote, rigName, role, sessionName string) {
+	running, err := remote.TmuxHasSession(sessionName)
+	if err != nil {
+		d.logger.Printf("Error checking %s for %s on %s: %v", role, rigName, remote.Name(), err)
+		return
+	}
+	if running {
+		d.logger.Printf("Remote %s for %s already running on %s", role, rigName, remote.Name())
+		return
+	}
+
+	if out, err := remote.GT(role, "start", rigName); err != nil {
+		d.logger.Printf("Error starting %s for %s on %s: %v: %s", role, rigName, remote.Name(), err, strings.TrimSpace(string(out)))
+		return
+	}
+	d.logger.Printf("Remote %s session for %s started on %s", role, rigName, remote.Name())
+}
+
+// compactEventsLog rotates .events.jsonl if it is due and prunes old segments.
+// Writers rotate on append too; this covers towns that go quiet.
+func (d *Daemon) compactEventsLog() {
+	removed, err := events.Compact(d.config.TownRoot, events.DefaultRetention)
+	if err != nil {
+		d.logger.Printf("Warning: events log compaction failed: %v", err)
+		return
+	}
+	if removed > 0 {
+		d.logger.Printf("Removed %d expired events segment(s)", removed)
+	}
+}
+
+// publishSessionChanges feeds the current tmux sessions to the change
+// publisher, which emits agent.state changes for sessions that came or went.
+func (d *Daemon) publishSessionChanges() {
+	if d.changes == nil {
+		return
+	}
+	sessions, err := d.tmux.ListSessions()
+	if err != nil {
+		d.logger.Printf("Warning: listing sessions for change stream: %v", err)
+		return
+	}
+	d.changes.CheckSessions(sessions)
+}
+
 // getKnownRigs returns list of registered rig names.
 func (d *Daemon) getKnownRigs() []string {
 	rigsPath := filepath.Join(d.config.TownRoot, "mayor", "rigs.json")
@@ -622,7 +744,7 @@ func (d *Daemon) isRigOperational(rigName string) (bool, string) {
 }
 
 // triggerPendingSpawns polls pending polecat spawns and triggers those that are ready.
-// This is bootstrap mode - uses regex-based WaitForRuntimeReady which is acceptable
+// This is bootstrap mode - uses pattern-based runtime.WaitReady which is acceptable
 // for daemon operations when no AI agent is guaranteed to be running.
 // The timeout is short (2s) to avoid blocking the heartbeat.
 func (d *Daemon) triggerPendingSpawns() {
@@ -641,7 +763,7 @@ func (d *Daemon) triggerPendingSpawns() {
 
 	d.logger.Printf("Found %d pending spawn(s), attempting to trigger...", len(pending))
 
-	// Trigger pending spawns (uses WaitForRuntimeReady with short timeout)
+	// Trigger pending spawns (uses runtime.WaitReady with short timeout)
 	results, err := polecat.TriggerPendingSpawns(d.config.TownRoot, triggerTimeout)
 	if err != nil {
 		d.logger.Printf("Error triggering spawns: %v", err)
@@ -806,6 +928,7 @@ func (d *Daemon) checkRigPolecatHealth(rigName string) {
 	for _, polecatName := range polecats {
 		d.checkPolecatHealth(rigName, polecatName)
 	}
+	d.pruneCheckpointArchives(rigName, polecats)
 }
 
 func listPolecatWorktrees(polecatsDir string) ([]string, error) {
@@ -843,7 +966,10 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	}
 
 	if sessionAlive {
-		// Session is alive - nothing to do
+		// Session is alive - move it off a rate-limited account, and
+		// checkpoint its work if it's due
+		d.checkPolecatRateLimit(rigName, polecatName, sessionName)
+		d.checkpointPolecat(rigName, polecatName, false)
 		return
 	}
 
@@ -872,6 +998,10 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	// Track this death for mass death detection
 	d.recordSessionDeath(sessionName)
 
+	// Capture the worktree as the session left it, in case the restart
+	// fails and the work has to be restored to a new polecat
+	d.checkpointPolecat(rigName, polecatName, true)
+
 	// Auto-restart the polecat
 	if err := d.restartPolecatSession(rigName, polecatName, sessionName); err != nil {
 		d.logger.Printf("Error restarting polecat %s/%s: %v", rigName, polecatName, err)
@@ -994,12 +1124,12 @@ func (d *Daemon) restartPolecatSession(rigName, polecatName, sessionName string)
 		return fmt.Errorf("sending startup command: %w", err)
 	}
 
-	// Wait for Claude to start, then accept bypass permissions warning if it appears.
-	// This ensures automated restarts aren't blocked by the warning dialog.
+	// Wait for the agent to start, then accept startup dialogs (e.g. the bypass
+	// permissions warning) so automated restarts aren't blocked by them.
 	if err := d.tmux.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
 		// Non-fatal - Claude might still start
 	}
-	_ = d.tmux.AcceptBypassPermissionsWarning(sessionName)
+	_ = runtime.DismissDialogs(d.tmux, runtime.ForRole("polecat", d.config.TownRoot, rigPath, ""), sessionName)
 
 	return nil
 }
@@ -1013,8 +1143,11 @@ func (d *Daemon) notifyWitnessOfCrashedPolecat(rigName, polecatName, hookBead st
 hook_bead: %s
 restart_error: %v
 
-Manual intervention may be required.`,
-		polecatName, hookBead, restartErr)
+Manual intervention may be required. If the polecat can't be repaired,
+move its work to a new polecat from its last checkpoint:
+
+  gt checkpoint restore %s/%s`,
+		polecatName, hookBead, restartErr, rigName, polecatName)
 
 	cmd := exec.Command("gt", "mail", "send", witnessAddr, "-s", subject, "-m", body) //nolint:gosec // G204: args are constructed internally
 	cmd.Dir = d.config.TownRoot
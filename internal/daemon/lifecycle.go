@@ -14,6 +14,7 @@ import (
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
@@ -383,12 +384,17 @@ func (d *Daemon) restartSession(sessionName, identity string) error {
 		return fmt.Errorf("sending startup command: %w", err)
 	}
 
-	// Wait for Claude to start, then accept bypass permissions warning if it appears.
-	// This ensures automated role starts aren't blocked by the warning dialog.
+	// Wait for the agent to start, then accept startup dialogs (e.g. the bypass
+	// permissions warning) so automated role starts aren't blocked by them.
 	if err := d.tmux.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
 		// Non-fatal - Claude might still start
 	}
-	_ = d.tmux.AcceptBypassPermissionsWarning(sessionName)
+	rigPath := ""
+	if parsed.RigName != "" {
+		rigPath = filepath.Join(d.config.TownRoot, parsed.RigName)
+	}
+	adapter := runtime.ForRole(parsed.RoleType, d.config.TownRoot, rigPath, "")
+	_ = runtime.DismissDialogs(d.tmux, adapter, sessionName)
 	time.Sleep(constants.ShutdownNotifyDelay)
 
 	// GUPP: Gas Town Universal Propulsion Principle
@@ -402,10 +408,10 @@ func (d *Daemon) restartSession(sessionName, identity string) error {
 
 	// Send propulsion nudge to trigger autonomous execution.
 	// Wait for beacon to be fully processed (needs to be separate prompt)
-	if err := d.tmux.WaitForClaudeUI(sessionName, 30*time.Second); err != nil {
-		return fmt.Errorf("waiting for Claude UI: %w", err)
+	if err := runtime.WaitReady(d.tmux, adapter, sessionName, 30*time.Second); err != nil {
+		return fmt.Errorf("waiting for agent UI: %w", err)
 	}
-	_ = d.tmux.NudgeSession(sessionName, session.PropulsionNudgeForRole(parsed.RoleType, workDir)) // Non-fatal
+	_ = adapter.InjectPrompt(d.tmux, sessionName, session.PropulsionNudgeForRole(parsed.RoleType, workDir)) // Non-fatal
 
 	return nil
 }
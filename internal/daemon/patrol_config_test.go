@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadPatrolConfig(t *testing.T) {
@@ -51,3 +52,26 @@ func TestIsPatrolEnabled_NilConfig(t *testing.T) {
 		t.Error("expected default to be enabled")
 	}
 }
+
+func TestCheckpointInterval(t *testing.T) {
+	if got := checkpointInterval(nil); got != defaultCheckpointInterval {
+		t.Errorf("checkpointInterval(nil) = %v, want %v", got, defaultCheckpointInterval)
+	}
+
+	config := &DaemonPatrolConfig{Patrols: &PatrolsConfig{
+		Checkpoints: &PatrolConfig{Enabled: true, Interval: "5m"},
+	}}
+	if got := checkpointInterval(config); got != 5*time.Minute {
+		t.Errorf("checkpointInterval() = %v, want 5m", got)
+	}
+
+	config.Patrols.Checkpoints.Interval = "soon"
+	if got := checkpointInterval(config); got != defaultCheckpointInterval {
+		t.Errorf("checkpointInterval(invalid) = %v, want default", got)
+	}
+
+	config.Patrols.Checkpoints.Enabled = false
+	if IsPatrolEnabled(config, "checkpoints") {
+		t.Error("expected checkpoints to be disabled")
+	}
+}
@@ -0,0 +1,110 @@
+package daemon
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/plugin"
+)
+
+// rigDockedLabel marks a docked rig's identity bead (see gt rig dock).
+const rigDockedLabel = "status:docked"
+
+// newPluginScheduler creates the scheduler that evaluates plugin gates on
+// each heartbeat. Plugins are rediscovered every tick so new plugins and
+// rigs are picked up without a restart.
+func (d *Daemon) newPluginScheduler() *plugin.Scheduler {
+	discover := func() ([]*plugin.Plugin, error) {
+		return plugin.NewScanner(d.config.TownRoot, d.getKnownRigs()).DiscoverAll()
+	}
+	return plugin.NewScheduler(d.config.TownRoot, discover, d.dispatchPlugin)
+}
+
+// dispatchPlugin hands a due plugin to an idle dog through gt dog dispatch,
+// the same path the Deacon uses, so the dog gets the usual plugin mail and
+// work assignment. It fails, leaving the plugin due, when no dog is idle.
+func (d *Daemon) dispatchPlugin(p *plugin.Plugin) error {
+	args := []string{"dog", "dispatch", "--plugin", p.Name}
+	if p.RigName != "" {
+		args = append(args, "--rig", p.RigName)
+	}
+	cmd := exec.Command("gt", args...) //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = d.config.TownRoot
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return errors.New(msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// runPluginScheduler evaluates plugin gates and dispatches due plugins.
+func (d *Daemon) runPluginScheduler() {
+	if d.plugins == nil {
+		return
+	}
+	if d.plugins.Watches(plugin.EventRigDocked) {
+		d.detectDockedRigs()
+	}
+
+	decisions, err := d.plugins.Tick(d.ctx)
+	if err != nil {
+		d.logger.Printf("Warning: plugin scheduler: %v", err)
+	}
+	for _, dec := range decisions {
+		switch {
+		case dec.Dispatched:
+			d.logger.Printf("Plugin %s dispatched to a dog (%s)", dec.Plugin, dec.Reason)
+		case dec.Reason != "":
+			d.logger.Printf("Plugin %s is due (%s) but dispatch failed: %v", dec.Plugin, dec.Reason, dec.Err)
+		default:
+			d.logger.Printf("Warning: plugin %s gate: %v", dec.Plugin, dec.Err)
+		}
+	}
+}
+
+// detectDockedRigs fires rig-docked for each rig that has become docked
+// since the previous heartbeat. The first call only records the baseline,
+// so rigs that were already docked when the daemon started don't fire.
+func (d *Daemon) detectDockedRigs() {
+	docked := make(map[string]bool)
+	for _, rigName := range d.getKnownRigs() {
+		if d.isRigDocked(rigName) {
+			docked[rigName] = true
+		}
+	}
+
+	previous := d.dockedRigs
+	d.dockedRigs = docked
+	if previous == nil {
+		return
+	}
+	for rigName := range docked {
+		if !previous[rigName] {
+			d.logger.Printf("Rig %s docked, firing %s", rigName, plugin.EventRigDocked)
+			d.plugins.Fire(plugin.Event{Name: plugin.EventRigDocked, Rig: rigName, Subject: rigName})
+		}
+	}
+}
+
+// isRigDocked reports whether a rig's identity bead carries the docked label.
+func (d *Daemon) isRigDocked(rigName string) bool {
+	beadsPath := filepath.Join(d.config.TownRoot, rigName, "mayor", "rig")
+	if _, err := os.Stat(beadsPath); err != nil {
+		beadsPath = filepath.Join(d.config.TownRoot, rigName)
+	}
+	prefix := beads.GetPrefixForRig(d.config.TownRoot, rigName)
+	rigBead, err := beads.New(beadsPath).Show(beads.RigBeadIDWithPrefix(prefix, rigName))
+	if err != nil || rigBead == nil {
+		return false
+	}
+	return beads.HasLabel(rigBead, rigDockedLabel)
+}
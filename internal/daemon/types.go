@@ -101,7 +101,7 @@ type PatrolConfig struct {
 	// Enabled controls whether this patrol runs during heartbeat.
 	Enabled bool `json:"enabled"`
 
-	// Interval is how often to run this patrol (not used yet).
+	// Interval is how often to run this patrol (used by checkpoints).
 	Interval string `json:"interval,omitempty"`
 
 	// Agent is the agent type for this patrol (not used yet).
@@ -113,6 +113,11 @@ type PatrolsConfig struct {
 	Refinery *PatrolConfig `json:"refinery,omitempty"`
 	Witness  *PatrolConfig `json:"witness,omitempty"`
 	Deacon   *PatrolConfig `json:"deacon,omitempty"`
+	Plugins  *PatrolConfig `json:"plugins,omitempty"`
+
+	// Checkpoints archives working polecats' state for gt checkpoint
+	// restore, every Interval (default 10m).
+	Checkpoints *PatrolConfig `json:"checkpoints,omitempty"`
 }
 
 // DaemonPatrolConfig is the structure of mayor/daemon.json.
@@ -164,6 +169,14 @@ func IsPatrolEnabled(config *DaemonPatrolConfig, patrol string) bool {
 		if config.Patrols.Deacon != nil {
 			return config.Patrols.Deacon.Enabled
 		}
+	case "plugins":
+		if config.Patrols.Plugins != nil {
+			return config.Patrols.Plugins.Enabled
+		}
+	case "checkpoints":
+		if config.Patrols.Checkpoints != nil {
+			return config.Patrols.Checkpoints.Enabled
+		}
 	}
 	return true // Default: enabled
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/steveyegge/gastown/internal/claude"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
@@ -113,8 +114,9 @@ func (m *Manager) Start(agentOverride string) error {
 		return fmt.Errorf("waiting for deacon to start: %w", err)
 	}
 
-	// Accept bypass permissions warning dialog if it appears.
-	_ = t.AcceptBypassPermissionsWarning(sessionID)
+	// Accept startup dialogs (e.g. the bypass permissions warning) if they appear.
+	adapter := runtime.ForRole("deacon", m.townRoot, "", agentOverride)
+	_ = runtime.DismissDialogs(t, adapter, sessionID)
 
 	time.Sleep(constants.ShutdownNotifyDelay)
 
@@ -127,13 +129,13 @@ func (m *Manager) Start(agentOverride string) error {
 
 	// GUPP: Gas Town Universal Propulsion Principle
 	// Send the propulsion nudge to trigger autonomous patrol execution.
-	// Wait for Claude UI to be ready, but send nudge regardless (best-effort).
-	// Claude may still be initializing, but the nudge will queue in the input buffer.
-	if err := t.WaitForClaudeUI(sessionID, 60*time.Second); err != nil {
+	// Wait for the agent to be ready, but send nudge regardless (best-effort).
+	// It may still be initializing, but the nudge will queue in the input buffer.
+	if err := runtime.WaitReady(t, adapter, sessionID, 60*time.Second); err != nil {
 		// Log warning but don't fail - the nudge may still work
 		fmt.Fprintf(os.Stderr, "warning: %v (sending nudge anyway)\n", err)
 	}
-	_ = t.NudgeSession(sessionID, session.PropulsionNudgeForRole("deacon", deaconDir)) // Non-fatal
+	_ = adapter.InjectPrompt(t, sessionID, session.PropulsionNudgeForRole("deacon", deaconDir)) // Non-fatal
 
 	return nil
 }
@@ -0,0 +1,114 @@
+package escalation
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// emailTimeout bounds a single SMTP session, from dial to QUIT.
+const emailTimeout = 30 * time.Second
+
+// EmailNotifier sends plain-text email through an SMTP server.
+type EmailNotifier struct {
+	Config config.SMTPConfig
+}
+
+// Notify sends msg to the given email address.
+func (e *EmailNotifier) Notify(ctx context.Context, to string, msg *Message) error {
+	port := e.Config.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(e.Config.Host, strconv.Itoa(port))
+
+	ctx, cancel := context.WithTimeout(ctx, emailTimeout)
+	defer cancel()
+	if err := e.send(ctx, addr, to, e.format(to, msg)); err != nil {
+		return fmt.Errorf("smtp %s: %w", addr, err)
+	}
+	return nil
+}
+
+// send runs the SMTP session smtp.SendMail would, on a connection that
+// can't outlive ctx: a server that stops answering fails the attempt, so
+// the dispatcher's retries still run.
+func (e *EmailNotifier) send(ctx context.Context, addr, to string, data []byte) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	// Cancellation before the deadline unblocks reads and writes too
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, e.Config.Host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: e.Config.Host}); err != nil {
+			return err
+		}
+	}
+	if e.Config.Username != "" {
+		auth := smtp.PlainAuth("", e.Config.Username, os.Getenv(e.Config.PasswordEnv), e.Config.Host)
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.Config.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// format builds the RFC 5322 message. The subject is Q-encoded when it
+// isn't plain ASCII, as headers can't carry raw UTF-8.
+func (e *EmailNotifier) format(to string, msg *Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.Config.From)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", oneLine(msg.Subject)))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	if msg.ID != "" {
+		fmt.Fprintf(&b, "X-Gastown-Escalation: %s\r\n", msg.ID)
+	}
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,69 @@
+package escalation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// defaultExecTimeout bounds a notifier command when no timeout is configured.
+const defaultExecTimeout = 30 * time.Second
+
+// ExecNotifier runs a command to deliver a message, e.g. an SMS gateway CLI.
+// "{to}" in the command is replaced by the recipient, the body is written
+// to stdin, and details are exported as GT_NOTIFY_* / GT_ESCALATION_* env vars.
+type ExecNotifier struct {
+	Config config.ExecNotifierConfig
+}
+
+// Notify runs the configured command for the given recipient.
+func (e *ExecNotifier) Notify(ctx context.Context, to string, msg *Message) error {
+	if len(e.Config.Command) == 0 {
+		return errors.New("empty notifier command")
+	}
+
+	timeout := defaultExecTimeout
+	if e.Config.Timeout != "" {
+		if d, err := time.ParseDuration(e.Config.Timeout); err == nil {
+			timeout = d
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args := make([]string, len(e.Config.Command))
+	for i, a := range e.Config.Command {
+		args[i] = strings.ReplaceAll(a, "{to}", to)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec // G204: command comes from town escalation config
+	cmd.Stdin = strings.NewReader(msg.Body)
+	cmd.Env = append(os.Environ(),
+		"GT_NOTIFY_TO="+to,
+		"GT_NOTIFY_SUBJECT="+msg.Subject,
+		"GT_ESCALATION_ID="+msg.ID,
+		"GT_ESCALATION_SEVERITY="+msg.Severity,
+		"GT_ESCALATION_FROM="+msg.From,
+	)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%s timed out after %s", args[0], timeout)
+		}
+		if output := strings.TrimSpace(out.String()); output != "" {
+			return fmt.Errorf("%s: %w: %s", args[0], err, output)
+		}
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
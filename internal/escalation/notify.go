@@ -0,0 +1,186 @@
+// Package escalation delivers escalation notifications to channels outside
+// Gas Town: SMTP email, JSON webhooks (Slack, Discord, Mattermost) and
+// arbitrary commands such as SMS gateway CLIs.
+package escalation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// ErrNotConfigured indicates an action whose notifier or contact is missing
+// from settings/escalation.json.
+var ErrNotConfigured = errors.New("notifier not configured")
+
+// Message is the notification content shared by all notifiers.
+type Message struct {
+	ID       string // Escalation bead ID
+	Severity string
+	Subject  string
+	Body     string
+	From     string // Escalating agent address
+}
+
+// Notifier delivers a message to a single recipient.
+type Notifier interface {
+	Notify(ctx context.Context, to string, msg *Message) error
+}
+
+// Delivery records the outcome of one external action.
+type Delivery struct {
+	Action   string // Route action, e.g. "email:human"
+	Target   string // Resolved recipient (address, number, webhook name)
+	Attempts int
+	Err      error
+	At       time.Time
+}
+
+// Skipped reports whether the action was not attempted because it isn't configured.
+func (d *Delivery) Skipped() bool {
+	return errors.Is(d.Err, ErrNotConfigured)
+}
+
+// String formats the delivery as a single-line record for the escalation bead.
+func (d *Delivery) String() string {
+	status := fmt.Sprintf("delivered (attempts %d)", d.Attempts)
+	if d.Err != nil {
+		status = fmt.Sprintf("failed after %d attempts: %s", d.Attempts, oneLine(d.Err.Error()))
+	}
+	return fmt.Sprintf("%s %s to %s: %s", d.At.UTC().Format(time.RFC3339), d.Action, d.Target, status)
+}
+
+// Dispatcher routes escalation actions to notifiers with retry.
+type Dispatcher struct {
+	cfg *config.EscalationConfig
+}
+
+// NewDispatcher creates a dispatcher for the given escalation config.
+func NewDispatcher(cfg *config.EscalationConfig) *Dispatcher {
+	return &Dispatcher{cfg: cfg}
+}
+
+// IsExternal reports whether an action is delivered outside Gas Town.
+// Internal actions (bead, mail:, log) are handled by the caller.
+func IsExternal(action string) bool {
+	return strings.HasPrefix(action, "email:") ||
+		strings.HasPrefix(action, "sms:") ||
+		strings.HasPrefix(action, "webhook:") ||
+		strings.HasPrefix(action, "exec:") ||
+		action == "slack"
+}
+
+// Dispatch delivers msg for every external action and returns one Delivery
+// per action. Failed deliveries are retried with exponential backoff.
+func (d *Dispatcher) Dispatch(ctx context.Context, actions []string, msg *Message) []Delivery {
+	var deliveries []Delivery
+	for _, action := range actions {
+		if !IsExternal(action) {
+			continue
+		}
+		delivery := Delivery{Action: action}
+		notifier, target, err := d.resolve(action)
+		if err != nil {
+			delivery.Err = err
+		} else {
+			delivery.Target = target
+			delivery.Attempts, delivery.Err = d.deliver(ctx, notifier, target, msg)
+		}
+		delivery.At = time.Now()
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries
+}
+
+// deliver calls the notifier until it succeeds or attempts run out.
+func (d *Dispatcher) deliver(ctx context.Context, n Notifier, to string, msg *Message) (int, error) {
+	attempts := d.cfg.Notifiers.GetAttempts()
+	delay := d.cfg.Notifiers.GetRetryDelay()
+
+	var err error
+	for i := 1; i <= attempts; i++ {
+		if err = n.Notify(ctx, to, msg); err == nil {
+			return i, nil
+		}
+		if i == attempts {
+			return i, err
+		}
+		select {
+		case <-ctx.Done():
+			return i, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	return attempts, err
+}
+
+// resolve maps an action to its notifier and recipient.
+func (d *Dispatcher) resolve(action string) (Notifier, string, error) {
+	n := &d.cfg.Notifiers
+	kind, arg, _ := strings.Cut(action, ":")
+
+	switch kind {
+	case "email":
+		to := arg
+		if to == "human" {
+			to = d.cfg.Contacts.HumanEmail
+			if to == "" {
+				return nil, "", fmt.Errorf("%w: contacts.human_email", ErrNotConfigured)
+			}
+			if !strings.Contains(to, "@") {
+				return nil, "", fmt.Errorf("%w: contacts.human_email %q is not an email address", ErrNotConfigured, to)
+			}
+		} else if !strings.Contains(to, "@") {
+			return nil, "", fmt.Errorf("%w: %q in action %s is not an email address", ErrNotConfigured, to, action)
+		}
+		if n.SMTP == nil {
+			return nil, "", fmt.Errorf("%w: notifiers.smtp", ErrNotConfigured)
+		}
+		return &EmailNotifier{Config: *n.SMTP}, to, nil
+
+	case "sms":
+		to := arg
+		if to == "human" {
+			to = d.cfg.Contacts.HumanSMS
+		}
+		if to == "" {
+			return nil, "", fmt.Errorf("%w: contacts.human_sms", ErrNotConfigured)
+		}
+		if n.SMS == nil {
+			return nil, "", fmt.Errorf("%w: notifiers.sms", ErrNotConfigured)
+		}
+		return &ExecNotifier{Config: *n.SMS}, to, nil
+
+	case "slack":
+		if d.cfg.Contacts.SlackWebhook == "" {
+			return nil, "", fmt.Errorf("%w: contacts.slack_webhook", ErrNotConfigured)
+		}
+		return &WebhookNotifier{Config: config.WebhookConfig{URL: d.cfg.Contacts.SlackWebhook}}, "slack", nil
+
+	case "webhook":
+		wh, ok := n.Webhooks[arg]
+		if !ok {
+			return nil, "", fmt.Errorf("%w: notifiers.webhooks.%s", ErrNotConfigured, arg)
+		}
+		return &WebhookNotifier{Config: wh}, arg, nil
+
+	case "exec":
+		cmd, ok := n.Commands[arg]
+		if !ok {
+			return nil, "", fmt.Errorf("%w: notifiers.commands.%s", ErrNotConfigured, arg)
+		}
+		return &ExecNotifier{Config: cmd}, arg, nil
+	}
+
+	return nil, "", fmt.Errorf("unknown external action %q", action)
+}
+
+// oneLine collapses whitespace so an error fits on one description line.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,287 @@
+package escalation
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+var testMsg = &Message{
+	ID:       "hq-esc1",
+	Severity: config.SeverityCritical,
+	Subject:  "[CRITICAL] refinery stuck",
+	Body:     "Escalation ID: hq-esc1\nSeverity: critical",
+	From:     "gastown/Toast",
+}
+
+// smtpStub accepts a single SMTP session and returns the DATA payload.
+func smtpStub(t *testing.T) (host string, port int, data <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	ch := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+
+		reply("220 stub ESMTP")
+		var body strings.Builder
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					ch <- body.String()
+					reply("250 queued")
+					continue
+				}
+				body.WriteString(line)
+				continue
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 stub")
+			case cmd == "DATA":
+				inData = true
+				reply("354 go ahead")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, ch
+}
+
+func testConfig() *config.EscalationConfig {
+	cfg := config.NewEscalationConfig()
+	cfg.Notifiers.RetryDelay = "1ms"
+	return cfg
+}
+
+func TestDispatch_Email(t *testing.T) {
+	host, port, data := smtpStub(t)
+	cfg := testConfig()
+	cfg.Contacts.HumanEmail = "overseer@example.com"
+	cfg.Notifiers.SMTP = &config.SMTPConfig{Host: host, Port: port, From: "gastown@example.com"}
+
+	deliveries := NewDispatcher(cfg).Dispatch(context.Background(), []string{"bead", "mail:mayor", "email:human"}, testMsg)
+	if len(deliveries) != 1 {
+		t.Fatalf("deliveries = %+v, want only the email action", deliveries)
+	}
+	if d := deliveries[0]; d.Err != nil || d.Target != "overseer@example.com" || d.Attempts != 1 {
+		t.Fatalf("delivery = %+v", d)
+	}
+
+	got := <-data
+	for _, want := range []string{"To: overseer@example.com", "Subject: [CRITICAL] refinery stuck", "X-Gastown-Escalation: hq-esc1", "Severity: critical"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("message missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestEmailNotifier_EncodesSubject(t *testing.T) {
+	host, port, data := smtpStub(t)
+	n := &EmailNotifier{Config: config.SMTPConfig{Host: host, Port: port, From: "gastown@example.com"}}
+
+	msg := &Message{Subject: "Réfinery stuck ⚠", Body: "body"}
+	if err := n.Notify(context.Background(), "overseer@example.com", msg); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	got := <-data
+	if !strings.Contains(got, "Subject: =?utf-8?q?") || strings.Contains(got, "Réfinery") {
+		t.Errorf("subject not Q-encoded:\n%s", got)
+	}
+}
+
+func TestEmailNotifier_HungServer(t *testing.T) {
+	// Accepts the connection but never greets
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	n := &EmailNotifier{Config: config.SMTPConfig{Host: addr.IP.String(), Port: addr.Port, From: "gastown@example.com"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := n.Notify(ctx, "overseer@example.com", testMsg); err == nil {
+		t.Fatal("Notify() succeeded against a server that never answers")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Notify() took %v, want it bounded by the context", elapsed)
+	}
+}
+
+func TestDispatch_WebhookRetries(t *testing.T) {
+	var calls atomic.Int32
+	var payload WebhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("missing configured header, got %v", r.Header)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer srv.Close()
+
+	cfg := testConfig()
+	cfg.Notifiers.Webhooks = map[string]config.WebhookConfig{
+		"ops": {URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer tok"}},
+	}
+
+	deliveries := NewDispatcher(cfg).Dispatch(context.Background(), []string{"webhook:ops"}, testMsg)
+	if d := deliveries[0]; d.Err != nil || d.Attempts != 2 {
+		t.Fatalf("delivery = %+v, want success on second attempt", d)
+	}
+	if !strings.HasPrefix(payload.Text, testMsg.Subject) || payload.Content != payload.Text {
+		t.Errorf("payload text/content = %q / %q", payload.Text, payload.Content)
+	}
+	if payload.Escalation.ID != "hq-esc1" || payload.Escalation.Severity != "critical" {
+		t.Errorf("payload escalation = %+v", payload.Escalation)
+	}
+}
+
+func TestWebhookNotifier_TruncatesOnRuneBoundary(t *testing.T) {
+	var payload WebhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer srv.Close()
+
+	msg := *testMsg
+	msg.Body = strings.Repeat("é", discordContentLimit)
+	n := &WebhookNotifier{Config: config.WebhookConfig{URL: srv.URL}}
+	if err := n.Notify(context.Background(), "ops", &msg); err != nil {
+		t.Fatal(err)
+	}
+	if !utf8.ValidString(payload.Content) || utf8.RuneCountInString(payload.Content) != discordContentLimit ||
+		!strings.HasSuffix(payload.Content, "é...") {
+		t.Errorf("content = %d runes, valid UTF-8 %v", utf8.RuneCountInString(payload.Content), utf8.ValidString(payload.Content))
+	}
+}
+
+func TestDispatch_WebhookGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	cfg := testConfig()
+	cfg.Contacts.SlackWebhook = srv.URL
+	cfg.Notifiers.Attempts = 2
+
+	d := NewDispatcher(cfg).Dispatch(context.Background(), []string{"slack"}, testMsg)[0]
+	if d.Err == nil || d.Attempts != 2 || calls.Load() != 2 {
+		t.Fatalf("delivery = %+v after %d calls, want failure after 2", d, calls.Load())
+	}
+	if rec := d.String(); !strings.Contains(rec, "slack to slack: failed after 2 attempts") || strings.Contains(rec, "\n") {
+		t.Errorf("record = %q", rec)
+	}
+}
+
+func TestDispatch_ExecSMS(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "sent")
+	script := filepath.Join(dir, "sms.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n{ echo \"$1 $GT_ESCALATION_ID $GT_ESCALATION_SEVERITY\"; cat; } > "+out+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := testConfig()
+	cfg.Contacts.HumanSMS = "+15550100"
+	cfg.Notifiers.SMS = &config.ExecNotifierConfig{Command: []string{script, "{to}"}}
+	cfg.Notifiers.Commands = map[string]config.ExecNotifierConfig{
+		"fail": {Command: []string{"sh", "-c", "echo gateway down >&2; exit 1"}},
+	}
+
+	deliveries := NewDispatcher(cfg).Dispatch(context.Background(), []string{"sms:human", "exec:fail"}, testMsg)
+	if d := deliveries[0]; d.Err != nil {
+		t.Fatalf("sms delivery = %+v", d)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); !strings.HasPrefix(got, "+15550100 hq-esc1 critical\n") || !strings.Contains(got, "Severity: critical") {
+		t.Errorf("command saw %q", got)
+	}
+
+	if d := deliveries[1]; d.Err == nil || !strings.Contains(d.Err.Error(), "gateway down") || d.Attempts != 3 {
+		t.Errorf("exec:fail delivery = %+v, want output in error after 3 attempts", d)
+	}
+}
+
+func TestDispatch_NotConfigured(t *testing.T) {
+	cfg := testConfig()
+	cfg.Contacts.HumanEmail = "overseer@example.com" // but no SMTP
+
+	deliveries := NewDispatcher(cfg).Dispatch(context.Background(), []string{"email:human", "sms:human", "slack", "webhook:x", "exec:y"}, testMsg)
+	if len(deliveries) != 5 {
+		t.Fatalf("deliveries = %d, want 5", len(deliveries))
+	}
+	for _, d := range deliveries {
+		if !d.Skipped() || d.Attempts != 0 {
+			t.Errorf("%s: delivery = %+v, want skipped", d.Action, d)
+		}
+	}
+}
+
+func TestDispatch_BadEmailAddress(t *testing.T) {
+	cfg := testConfig()
+	cfg.Contacts.HumanEmail = "overseer"
+
+	deliveries := NewDispatcher(cfg).Dispatch(context.Background(), []string{"email:human", "email:oncall"}, testMsg)
+	want := []string{`contacts.human_email "overseer"`, `"oncall" in action email:oncall`}
+	for i, d := range deliveries {
+		if !d.Skipped() || !strings.Contains(d.Err.Error(), want[i]) {
+			t.Errorf("%s: error = %v, want it to name %s", d.Action, d.Err, want[i])
+		}
+	}
+}
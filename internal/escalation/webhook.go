@@ -0,0 +1,96 @@
+package escalation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// webhookTimeout bounds a single webhook request.
+const webhookTimeout = 10 * time.Second
+
+// discordContentLimit is Discord's maximum message length.
+const discordContentLimit = 2000
+
+// WebhookNotifier posts a JSON payload to an incoming webhook.
+// The payload is understood by Slack and Mattermost ("text") and
+// Discord ("content"); generic receivers can read the "escalation" object.
+type WebhookNotifier struct {
+	Config config.WebhookConfig
+}
+
+// WebhookPayload is the JSON body posted by WebhookNotifier.
+type WebhookPayload struct {
+	Text       string          `json:"text"`
+	Content    string          `json:"content"`
+	Username   string          `json:"username"`
+	Escalation WebhookEnvelope `json:"escalation"`
+}
+
+// WebhookEnvelope carries the structured escalation for generic receivers.
+type WebhookEnvelope struct {
+	ID       string `json:"id"`
+	Severity string `json:"severity"`
+	Subject  string `json:"subject"`
+	Body     string `json:"body"`
+	From     string `json:"from"`
+}
+
+// Notify posts msg to the webhook. The recipient is informational only.
+func (w *WebhookNotifier) Notify(ctx context.Context, _ string, msg *Message) error {
+	text := msg.Subject
+	if msg.Body != "" {
+		text += "\n\n" + msg.Body
+	}
+	content := text
+	if utf8.RuneCountInString(content) > discordContentLimit {
+		// Discord counts characters; cutting bytes could split one
+		content = string([]rune(content)[:discordContentLimit-3]) + "..."
+	}
+
+	data, err := json.Marshal(WebhookPayload{
+		Text:     text,
+		Content:  content,
+		Username: "Gas Town",
+		Escalation: WebhookEnvelope{
+			ID:       msg.ID,
+			Severity: msg.Severity,
+			Subject:  msg.Subject,
+			Body:     msg.Body,
+			From:     msg.From,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.Config.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.Config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, oneLine(string(body)))
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 // Package events provides event logging for the gt activity feed.
 //
 // Events are written to ~/gt/.events.jsonl (raw audit log) and later
-// curated by the feed daemon into ~/.feed.jsonl (user-facing).
+// curated by the feed daemon into ~/.feed.jsonl (user-facing). The raw log
+// is rotated into compressed, indexed segments under ~/gt/.events/; use
+// Query to read across both.
 package events
 
 import (
@@ -119,10 +121,22 @@ func write(event Event) error {
 	}
 	data = append(data, '\n')
 
-	// Append to file with proper locking
+	// Append to file with proper locking. The file lock also keeps other
+	// gt processes from appending while the log is being rotated.
 	mutex.Lock()
 	defer mutex.Unlock()
 
+	lock, err := lockTown(townRoot, false)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	if needsRotation(eventsPath, time.Now()) {
+		// Best-effort: a failed rotation must not lose the event
+		_ = rotateLocked(townRoot)
+	}
+
 	f, err := os.OpenFile(eventsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: events file is non-sensitive operational data
 	if err != nil {
 		return fmt.Errorf("opening events file: %w", err)
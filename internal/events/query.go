@@ -0,0 +1,175 @@
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Filter selects events for Query. Zero-valued fields match everything.
+type Filter struct {
+	// Types restricts results to these event types.
+	Types []string
+
+	// Actor restricts results to a single actor (exact match).
+	Actor string
+
+	// Since and Until bound the event timestamp (inclusive).
+	Since time.Time
+	Until time.Time
+
+	// Limit keeps only the most recent N matches. 0 means no limit.
+	Limit int
+}
+
+// matches reports whether an event passes the filter.
+func (f *Filter) matches(e *Event, ts time.Time) bool {
+	if f.Actor != "" && e.Actor != f.Actor {
+		return false
+	}
+	if len(f.Types) > 0 && !contains(f.Types, e.Type) {
+		return false
+	}
+	if !f.Since.IsZero() && ts.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && ts.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// mayMatch reports whether a segment could contain matching events,
+// using only the index entry.
+func (f *Filter) mayMatch(seg *Segment) bool {
+	if !f.Since.IsZero() && seg.End.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && seg.Start.After(f.Until) {
+		return false
+	}
+	if f.Actor != "" && !contains(seg.Actors, f.Actor) {
+		return false
+	}
+	if len(f.Types) > 0 {
+		for _, t := range f.Types {
+			if contains(seg.Types, t) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Query returns events from the active log and rotated segments that match
+// the filter, oldest first. Segments ruled out by the index are not read, and
+// with a Limit, older segments are skipped once enough events are found.
+func Query(townRoot string, f Filter) ([]Event, error) {
+	lock, err := lockTown(townRoot, true)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	idx, err := LoadIndex(townRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	// Newest first: the active log, then segments in reverse order.
+	// Each chunk is collected oldest-first and prepended to the result.
+	result, err := queryFile(filepath.Join(townRoot, EventsFile), &f, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(idx.Segments) - 1; i >= 0; i-- {
+		if f.Limit > 0 && len(result) >= f.Limit {
+			break
+		}
+		seg := &idx.Segments[i]
+		if !f.mayMatch(seg) {
+			continue
+		}
+		chunk, err := queryFile(filepath.Join(segmentsDir(townRoot), seg.File), &f, true)
+		if err != nil {
+			return nil, err
+		}
+		result = append(chunk, result...)
+	}
+
+	return keepLast(result, f.Limit), nil
+}
+
+// keepLast trims events to the most recent limit entries (0 = all).
+func keepLast(events []Event, limit int) []Event {
+	if limit > 0 && len(events) > limit {
+		return events[len(events)-limit:]
+	}
+	return events
+}
+
+// queryFile reads matching events from one log file, sorted by timestamp.
+// A missing file yields no events.
+func queryFile(path string, f *Filter, compressed bool) ([]Event, error) {
+	var r io.ReadCloser
+	var err error
+	if compressed {
+		r, err = openSegment(path)
+	} else {
+		r, err = os.Open(path) //nolint:gosec // G304: path is constructed from town root
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer r.Close()
+
+	type stamped struct {
+		event Event
+		ts    time.Time
+	}
+	var found []stamped
+	err = scanLines(r, func(line []byte) error {
+		var e Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil // Skip malformed lines
+		}
+		ts, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil {
+			return nil
+		}
+		if f.matches(&e, ts) {
+			found = append(found, stamped{e, ts})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Appends from concurrent writers can land slightly out of order
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].ts.Before(found[j].ts)
+	})
+	events := make([]Event, len(found))
+	for i := range found {
+		events[i] = found[i].event
+	}
+	return events, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,464 @@
+package events
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// Rotated segments live under <town>/.events/ as gzip-compressed JSONL,
+// named by the timestamp of their first event:
+//
+//	.events/events-20260114T093000Z.jsonl.gz
+//	.events/index.json   (one Segment entry per file)
+//
+// The active log (.events.jsonl) is rotated once it exceeds RotateSize or its
+// first event is older than RotateAge. Compact drops segments past retention.
+const (
+	// SegmentsDir holds rotated segments and the index.
+	SegmentsDir = ".events"
+
+	// IndexFile is the sidecar index of rotated segments.
+	IndexFile = "index.json"
+
+	// lockFile serializes appends, rotation and compaction across processes.
+	lockFile = ".lock"
+
+	segmentTimeFormat = "20060102T150405Z"
+)
+
+// Rotation and retention thresholds. Variables so tests can shrink them.
+var (
+	// RotateSize is the active log size that triggers rotation.
+	RotateSize int64 = 16 << 20
+
+	// RotateAge is the age of the oldest active event that triggers rotation.
+	RotateAge = 24 * time.Hour
+
+	// DefaultRetention is how long rotated segments are kept by Compact.
+	DefaultRetention = 90 * 24 * time.Hour
+)
+
+// Segment describes one rotated, compressed chunk of the event log.
+// Actors and Types let queries skip segments that can't match.
+type Segment struct {
+	File   string    `json:"file"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Count  int       `json:"count"`
+	Actors []string  `json:"actors,omitempty"`
+	Types  []string  `json:"types,omitempty"`
+}
+
+// Index is the sidecar index of rotated segments, oldest first.
+type Index struct {
+	Segments []Segment `json:"segments"`
+}
+
+// segmentsDir returns the segments directory for a town.
+func segmentsDir(townRoot string) string {
+	return filepath.Join(townRoot, SegmentsDir)
+}
+
+// lockTown takes the cross-process events lock for a town. Writers take it
+// exclusively; readers take it shared so they never observe a rotation
+// halfway through (segment indexed but active log not yet removed).
+func lockTown(townRoot string, shared bool) (*flock.Flock, error) {
+	dir := segmentsDir(townRoot)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating segments dir: %w", err)
+	}
+	lock := flock.New(filepath.Join(dir, lockFile))
+	lockFn := lock.Lock
+	if shared {
+		lockFn = lock.RLock
+	}
+	if err := lockFn(); err != nil {
+		return nil, fmt.Errorf("locking events log: %w", err)
+	}
+	return lock, nil
+}
+
+// LoadIndex reads the segment index. A missing index is an empty index.
+func LoadIndex(townRoot string) (*Index, error) {
+	data, err := os.ReadFile(filepath.Join(segmentsDir(townRoot), IndexFile)) //nolint:gosec // G304: path is constructed from town root
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Index{}, nil
+		}
+		return nil, fmt.Errorf("reading events index: %w", err)
+	}
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parsing events index: %w", err)
+	}
+	return &idx, nil
+}
+
+// saveIndex writes the index atomically so readers never see a partial file.
+func saveIndex(townRoot string, idx *Index) error {
+	sort.Slice(idx.Segments, func(i, j int) bool {
+		return idx.Segments[i].Start.Before(idx.Segments[j].Start)
+	})
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding events index: %w", err)
+	}
+	path := filepath.Join(segmentsDir(townRoot), IndexFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil { //nolint:gosec // G306: index is non-sensitive operational data
+		return fmt.Errorf("writing events index: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Rotate moves the active log into a compressed segment, regardless of size.
+// It is a no-op if the active log is empty.
+func Rotate(townRoot string) error {
+	lock, err := lockTown(townRoot, false)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+	return rotateLocked(townRoot)
+}
+
+// needsRotation reports whether the active log has crossed a rotation threshold.
+func needsRotation(eventsPath string, now time.Time) bool {
+	info, err := os.Stat(eventsPath)
+	if err != nil || info.Size() == 0 {
+		return false
+	}
+	if info.Size() >= RotateSize {
+		return true
+	}
+
+	// Age is judged by the first event, so quiet towns still rotate daily
+	start, ok := activeLogStart(eventsPath, info)
+	return ok && now.Sub(start) >= RotateAge
+}
+
+// logStart is the first event time of an active log, read once per file:
+// appends never change the first line, and every write checks its age.
+type logStart struct {
+	info  os.FileInfo
+	start time.Time
+	ok    bool
+}
+
+var (
+	logStartsMu sync.Mutex
+	logStarts   = make(map[string]*logStart)
+)
+
+// activeLogStart returns the time of the first event in the active log, or
+// false if it has none that parses.
+func activeLogStart(eventsPath string, info os.FileInfo) (time.Time, bool) {
+	logStartsMu.Lock()
+	defer logStartsMu.Unlock()
+
+	// Same file, only grown: another process hasn't rotated it since
+	if c := logStarts[eventsPath]; c != nil && os.SameFile(c.info, info) && info.Size() >= c.info.Size() {
+		c.info = info
+		return c.start, c.ok
+	}
+
+	c := &logStart{info: info}
+	c.start, c.ok = readLogStart(eventsPath)
+	logStarts[eventsPath] = c
+	return c.start, c.ok
+}
+
+// forgetLogStart drops the cached start of a log that was rotated away.
+func forgetLogStart(eventsPath string) {
+	logStartsMu.Lock()
+	defer logStartsMu.Unlock()
+	delete(logStarts, eventsPath)
+}
+
+// readLogStart parses the timestamp of the first event in a log.
+func readLogStart(eventsPath string) (time.Time, bool) {
+	f, err := os.Open(eventsPath) //nolint:gosec // G304: path is constructed from town root
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return time.Time{}, false
+	}
+	var first Event
+	if err := json.Unmarshal(line, &first); err != nil {
+		return time.Time{}, false
+	}
+	ts, err := time.Parse(time.RFC3339, first.Timestamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts, true
+}
+
+// rotateLocked compresses the active log into a new segment and indexes it.
+// The caller must hold the events lock.
+func rotateLocked(townRoot string) error {
+	eventsPath := filepath.Join(townRoot, EventsFile)
+	defer forgetLogStart(eventsPath)
+	src, err := os.Open(eventsPath) //nolint:gosec // G304: path is constructed from town root
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("opening events file: %w", err)
+	}
+	defer src.Close()
+
+	dir := segmentsDir(townRoot)
+	tmp, err := os.CreateTemp(dir, "rotate-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating segment: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	zw := gzip.NewWriter(tmp)
+	seg, err := copySegment(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("writing segment: %w", err)
+	}
+	if seg.Count == 0 {
+		// Nothing worth keeping (empty or all malformed)
+		return os.Remove(eventsPath)
+	}
+
+	seg.File = segmentName(dir, seg.Start)
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, seg.File)); err != nil {
+		return fmt.Errorf("saving segment: %w", err)
+	}
+
+	idx, err := LoadIndex(townRoot)
+	if err != nil {
+		// A corrupt index shouldn't block rotation; rebuild from the files.
+		if idx, err = buildIndex(townRoot); err != nil {
+			return err
+		}
+	} else {
+		idx.Segments = append(idx.Segments, *seg)
+	}
+	if err := saveIndex(townRoot, idx); err != nil {
+		return err
+	}
+
+	return os.Remove(eventsPath)
+}
+
+// copySegment copies event lines from r to w, collecting index metadata.
+// Malformed lines are copied through but not indexed.
+func copySegment(w io.Writer, r io.Reader) (*Segment, error) {
+	seg := &Segment{}
+	actors := make(map[string]bool)
+	types := make(map[string]bool)
+
+	err := scanLines(r, func(line []byte) error {
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte{'\n'}); err != nil {
+			return err
+		}
+		var e Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil
+		}
+		ts, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil {
+			return nil
+		}
+		if seg.Count == 0 || ts.Before(seg.Start) {
+			seg.Start = ts
+		}
+		if ts.After(seg.End) {
+			seg.End = ts
+		}
+		seg.Count++
+		actors[e.Actor] = true
+		types[e.Type] = true
+		return nil
+	})
+	seg.Actors = sortedKeys(actors)
+	seg.Types = sortedKeys(types)
+	return seg, err
+}
+
+// segmentName picks an unused segment file name for a start time.
+func segmentName(dir string, start time.Time) string {
+	base := "events-" + start.UTC().Format(segmentTimeFormat)
+	name := base + ".jsonl.gz"
+	for i := 1; ; i++ {
+		if _, err := os.Stat(filepath.Join(dir, name)); os.IsNotExist(err) {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d.jsonl.gz", base, i)
+	}
+}
+
+// RebuildIndex regenerates the index by reading every segment.
+// Use after manual edits or if index.json is lost.
+func RebuildIndex(townRoot string) error {
+	lock, err := lockTown(townRoot, false)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	idx, err := buildIndex(townRoot)
+	if err != nil {
+		return err
+	}
+	return saveIndex(townRoot, idx)
+}
+
+// buildIndex scans all segment files into a fresh index.
+func buildIndex(townRoot string) (*Index, error) {
+	files, err := filepath.Glob(filepath.Join(segmentsDir(townRoot), "events-*.jsonl.gz"))
+	if err != nil {
+		return nil, err
+	}
+	idx := &Index{}
+	for _, path := range files {
+		rc, err := openSegment(path)
+		if err != nil {
+			return nil, err
+		}
+		seg, err := copySegment(io.Discard, rc)
+		_ = rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("indexing %s: %w", filepath.Base(path), err)
+		}
+		seg.File = filepath.Base(path)
+		idx.Segments = append(idx.Segments, *seg)
+	}
+	return idx, nil
+}
+
+// Compact deletes segments whose newest event is older than retention and
+// rotates the active log if it has crossed a threshold. It returns the number
+// of segments removed.
+func Compact(townRoot string, retention time.Duration) (int, error) {
+	lock, err := lockTown(townRoot, false)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	now := time.Now()
+	if needsRotation(filepath.Join(townRoot, EventsFile), now) {
+		if err := rotateLocked(townRoot); err != nil {
+			return 0, err
+		}
+	}
+
+	idx, err := LoadIndex(townRoot)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := now.Add(-retention)
+	kept := idx.Segments[:0]
+	removed := 0
+	for _, seg := range idx.Segments {
+		if seg.End.Before(cutoff) {
+			if err := os.Remove(filepath.Join(segmentsDir(townRoot), seg.File)); err != nil && !os.IsNotExist(err) {
+				return removed, fmt.Errorf("removing segment %s: %w", seg.File, err)
+			}
+			removed++
+			continue
+		}
+		kept = append(kept, seg)
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	idx.Segments = kept
+	return removed, saveIndex(townRoot, idx)
+}
+
+// IsRotated reports whether an open events log is no longer the active log,
+// i.e. it has been rotated away. Tailers should finish reading the old handle
+// and then reopen the path.
+func IsRotated(file *os.File) bool {
+	openInfo, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	pathInfo, err := os.Stat(file.Name())
+	if err != nil {
+		// Removed but not yet recreated; keep the old handle until it is
+		return false
+	}
+	return !os.SameFile(openInfo, pathInfo)
+}
+
+// openSegment opens a compressed segment for reading.
+func openSegment(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path comes from the segment index
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("reading segment %s: %w", filepath.Base(path), err)
+	}
+	return &segmentReader{Reader: zr, file: f}, nil
+}
+
+// segmentReader closes both the gzip stream and the underlying file.
+type segmentReader struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (r *segmentReader) Close() error {
+	_ = r.Reader.Close()
+	return r.file.Close()
+}
+
+// scanLines calls fn for each non-empty line in r.
+func scanLines(r io.Reader, fn func(line []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	// Payloads can be large (e.g., long mail subjects, session topics)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,296 @@
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// appendEvents writes events straight to the active log of a town.
+func appendEvents(t *testing.T, townRoot string, evs ...Event) {
+	t.Helper()
+	f, err := os.OpenFile(filepath.Join(townRoot, EventsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for _, e := range evs {
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func event(ts time.Time, typ, actor string) Event {
+	return Event{
+		Timestamp:  ts.UTC().Format(time.RFC3339),
+		Source:     "gt",
+		Type:       typ,
+		Actor:      actor,
+		Visibility: VisibilityFeed,
+	}
+}
+
+func TestRotate_IndexesSegment(t *testing.T) {
+	town := t.TempDir()
+	base := time.Date(2026, 1, 14, 9, 30, 0, 0, time.UTC)
+	appendEvents(t, town,
+		event(base, TypeSling, "mayor"),
+		event(base.Add(time.Minute), TypeHook, "gastown/Toast"),
+	)
+	// Malformed lines are kept in the segment but not indexed
+	f, _ := os.OpenFile(filepath.Join(town, EventsFile), os.O_APPEND|os.O_WRONLY, 0644)
+	_, _ = f.WriteString("not json\n")
+	_ = f.Close()
+
+	if err := Rotate(town); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(town, EventsFile)); !os.IsNotExist(err) {
+		t.Error("active log should be removed after rotation")
+	}
+	idx, err := LoadIndex(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Segments) != 1 {
+		t.Fatalf("segments = %d, want 1", len(idx.Segments))
+	}
+	seg := idx.Segments[0]
+	if seg.File != "events-20260114T093000Z.jsonl.gz" {
+		t.Errorf("File = %q", seg.File)
+	}
+	if seg.Count != 2 || !seg.Start.Equal(base) || !seg.End.Equal(base.Add(time.Minute)) {
+		t.Errorf("segment = %+v", seg)
+	}
+	if len(seg.Actors) != 2 || len(seg.Types) != 2 {
+		t.Errorf("Actors = %v, Types = %v", seg.Actors, seg.Types)
+	}
+
+	// Rotating an empty log is a no-op
+	if err := Rotate(town); err != nil {
+		t.Fatalf("Rotate (empty): %v", err)
+	}
+
+	// The index can be rebuilt from the segment files alone
+	if err := os.Remove(filepath.Join(town, SegmentsDir, IndexFile)); err != nil {
+		t.Fatal(err)
+	}
+	if err := RebuildIndex(town); err != nil {
+		t.Fatalf("RebuildIndex: %v", err)
+	}
+	if idx, _ := LoadIndex(town); len(idx.Segments) != 1 || idx.Segments[0].Count != 2 {
+		t.Errorf("rebuilt index = %+v", idx)
+	}
+}
+
+func TestQuery_AcrossSegments(t *testing.T) {
+	town := t.TempDir()
+	day1 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	day3 := day2.Add(24 * time.Hour)
+
+	appendEvents(t, town, event(day1, TypeSessionStart, "mayor"), event(day1.Add(time.Hour), TypeSling, "mayor"))
+	if err := Rotate(town); err != nil {
+		t.Fatal(err)
+	}
+	appendEvents(t, town, event(day2, TypeHook, "gastown/Toast"), event(day2.Add(time.Hour), TypeSessionStart, "deacon"))
+	if err := Rotate(town); err != nil {
+		t.Fatal(err)
+	}
+	appendEvents(t, town, event(day3, TypeSessionStart, "gastown/Toast"))
+
+	all, err := Query(town, Filter{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(all) != 5 {
+		t.Fatalf("Query all = %d events, want 5", len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].Timestamp < all[i-1].Timestamp {
+			t.Errorf("results not oldest-first at %d: %s < %s", i, all[i].Timestamp, all[i-1].Timestamp)
+		}
+	}
+
+	starts, _ := Query(town, Filter{Types: []string{TypeSessionStart}})
+	if len(starts) != 3 {
+		t.Errorf("session starts = %d, want 3", len(starts))
+	}
+
+	byActor, _ := Query(town, Filter{Actor: "gastown/Toast"})
+	if len(byActor) != 2 {
+		t.Errorf("Toast events = %d, want 2", len(byActor))
+	}
+
+	window, _ := Query(town, Filter{Since: day2, Until: day2.Add(2 * time.Hour)})
+	if len(window) != 2 || window[0].Type != TypeHook {
+		t.Errorf("day2 window = %+v", window)
+	}
+
+	last, _ := Query(town, Filter{Types: []string{TypeSessionStart}, Limit: 2})
+	if len(last) != 2 || last[0].Actor != "deacon" || last[1].Actor != "gastown/Toast" {
+		t.Errorf("Limit 2 = %+v, want the two most recent starts", last)
+	}
+}
+
+func TestQuery_SkipsSegmentsByIndex(t *testing.T) {
+	town := t.TempDir()
+	old := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	appendEvents(t, town, event(old, TypeSling, "mayor"))
+	if err := Rotate(town); err != nil {
+		t.Fatal(err)
+	}
+
+	// Corrupt the segment: a query that reads it would fail
+	idx, _ := LoadIndex(town)
+	if err := os.WriteFile(filepath.Join(town, SegmentsDir, idx.Segments[0].File), []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, f := range map[string]Filter{
+		"since": {Since: old.Add(time.Hour)},
+		"type":  {Types: []string{TypeHook}},
+		"actor": {Actor: "deacon"},
+	} {
+		if _, err := Query(town, f); err != nil {
+			t.Errorf("%s: segment should have been skipped via index, got %v", name, err)
+		}
+	}
+	if _, err := Query(town, Filter{Actor: "mayor"}); err == nil {
+		t.Error("expected error reading the corrupt segment when it may match")
+	}
+}
+
+func TestWrite_RotatesBySizeAndAge(t *testing.T) {
+	town := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(town, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(town, "mayor", "town.json"), []byte(`{"type":"town"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(town)
+
+	// Age: an active log whose first event is older than RotateAge
+	appendEvents(t, town, event(time.Now().Add(-2*RotateAge), TypeSling, "mayor"))
+	if err := LogFeed(TypeHook, "gastown/Toast", HookPayload("gt-1")); err != nil {
+		t.Fatal(err)
+	}
+	if idx, _ := LoadIndex(town); len(idx.Segments) != 1 {
+		t.Fatalf("segments after age rotation = %d, want 1", len(idx.Segments))
+	}
+
+	// Size: shrink the threshold so the next write rotates
+	oldSize := RotateSize
+	RotateSize = 1
+	defer func() { RotateSize = oldSize }()
+	if err := LogFeed(TypeUnhook, "gastown/Toast", UnhookPayload("gt-1")); err != nil {
+		t.Fatal(err)
+	}
+	if idx, _ := LoadIndex(town); len(idx.Segments) != 2 {
+		t.Fatalf("segments after size rotation = %d, want 2", len(idx.Segments))
+	}
+
+	all, err := Query(town, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Query after rotations = %d events, want 3", len(all))
+	}
+}
+
+func TestNeedsRotation_CachesLogStart(t *testing.T) {
+	town := t.TempDir()
+	eventsPath := filepath.Join(town, EventsFile)
+	now := time.Now()
+
+	appendEvents(t, town, event(now, TypeSling, "mayor"))
+	if needsRotation(eventsPath, now) {
+		t.Fatal("fresh log needs rotation")
+	}
+
+	// Appends keep the cached start; the first line isn't reread
+	appendEvents(t, town, event(now, TypeHook, "mayor"))
+	logStarts[eventsPath].start = now.Add(-2 * RotateAge)
+	if !needsRotation(eventsPath, now) {
+		t.Error("cached start not used after an append")
+	}
+
+	// A log replaced by another process is read afresh
+	if err := os.Remove(eventsPath); err != nil {
+		t.Fatal(err)
+	}
+	appendEvents(t, town, event(now, TypeSling, "mayor"))
+	if needsRotation(eventsPath, now) {
+		t.Error("replaced log judged by the old log's start")
+	}
+
+	// As is the log after our own rotation
+	appendEvents(t, town, event(now.Add(-2*RotateAge), TypeSling, "mayor"))
+	if err := Rotate(town); err != nil {
+		t.Fatal(err)
+	}
+	appendEvents(t, town, event(now.Add(-2*RotateAge), TypeSling, "mayor"))
+	if !needsRotation(eventsPath, now) {
+		t.Error("log after rotation judged by the rotated log's start")
+	}
+}
+
+func TestCompact_DropsExpiredSegments(t *testing.T) {
+	town := t.TempDir()
+	now := time.Now().UTC()
+	appendEvents(t, town, event(now.Add(-100*24*time.Hour), TypeSling, "mayor"))
+	if err := Rotate(town); err != nil {
+		t.Fatal(err)
+	}
+	appendEvents(t, town, event(now.Add(-2*time.Hour), TypeSling, "mayor"))
+	if err := Rotate(town); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := Compact(town, DefaultRetention)
+	if err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("removed = %d, want 1", removed)
+	}
+	idx, _ := LoadIndex(town)
+	if len(idx.Segments) != 1 {
+		t.Errorf("segments after compact = %d, want 1", len(idx.Segments))
+	}
+	files, _ := filepath.Glob(filepath.Join(town, SegmentsDir, "events-*.jsonl.gz"))
+	if len(files) != 1 {
+		t.Errorf("segment files after compact = %v", files)
+	}
+}
+
+func TestIsRotated(t *testing.T) {
+	town := t.TempDir()
+	appendEvents(t, town, event(time.Now(), TypeSling, "mayor"))
+	f, err := os.Open(filepath.Join(town, EventsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if IsRotated(f) {
+		t.Error("IsRotated = true before rotation")
+	}
+	if err := Rotate(town); err != nil {
+		t.Fatal(err)
+	}
+	appendEvents(t, town, event(time.Now(), TypeHook, "mayor"))
+	if !IsRotated(f) {
+		t.Error("IsRotated = false after rotation and recreate")
+	}
+}
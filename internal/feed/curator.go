@@ -1,7 +1,7 @@
 // Package feed provides the feed daemon that curates raw events into a user-facing feed.
 //
 // The curator:
-// 1. Tails ~/gt/.events.jsonl (raw events)
+// 1. Tails ~/gt/.events.jsonl (raw events), following rotation
 // 2. Filters by visibility tag (drops audit-only events)
 // 3. Deduplicates repeated updates (5 molecule updates → "agent active")
 // 4. Aggregates related events (3 issues closed → "batch complete")
@@ -103,7 +103,7 @@ func (c *Curator) Stop() {
 // ZFC: No in-memory state to clean up - state is derived from the events file.
 func (c *Curator) run(file *os.File) {
 	defer c.wg.Done()
-	defer file.Close()
+	defer func() { _ = file.Close() }()
 
 	reader := bufio.NewReader(file)
 	ticker := time.NewTicker(100 * time.Millisecond)
@@ -123,10 +123,21 @@ func (c *Curator) run(file *os.File) {
 				}
 				c.processLine(line)
 			}
+
+			// The events log was rotated: everything in the old file has been
+			// read, so follow the new active file from its start.
+			if events.IsRotated(file) {
+				if next, err := os.Open(file.Name()); err == nil {
+					_ = file.Close()
+					file = next
+					reader = bufio.NewReader(file)
+				}
+			}
 		}
 	}
 }
 
+
 // processLine processes a single line from the events file.
 func (c *Curator) processLine(line string) {
 	if line == "" || line == "\n" {
@@ -217,50 +228,15 @@ func (c *Curator) readRecentFeedEvents(window time.Duration) []FeedEvent {
 	return result
 }
 
-// readRecentEvents reads events from the events file within the given time window.
+// readRecentEvents reads events from the events log within the given time window.
 // ZFC: This is the observable state that replaces in-memory caching.
-// Uses tail-like reading for performance (reads last N lines).
+// events.Query only reads the active log and segments overlapping the window.
 func (c *Curator) readRecentEvents(window time.Duration) []events.Event {
-	eventsPath := filepath.Join(c.townRoot, events.EventsFile)
-
-	// Read the file (for small files, this is fine; for large files, consider tail-like reading)
-	data, err := os.ReadFile(eventsPath)
+	recent, err := events.Query(c.townRoot, events.Filter{Since: time.Now().Add(-window)})
 	if err != nil {
 		return nil
 	}
-
-	now := time.Now()
-	cutoff := now.Add(-window)
-	var result []events.Event
-
-	// Parse lines from the end (most recent first) for efficiency
-	lines := strings.Split(string(data), "\n")
-	for i := len(lines) - 1; i >= 0; i-- {
-		line := strings.TrimSpace(lines[i])
-		if line == "" {
-			continue
-		}
-
-		var event events.Event
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
-			continue
-		}
-
-		// Parse timestamp
-		ts, err := time.Parse(time.RFC3339, event.Timestamp)
-		if err != nil {
-			continue
-		}
-
-		// Stop if we've gone past the window
-		if ts.Before(cutoff) {
-			break
-		}
-
-		result = append(result, event)
-	}
-
-	return result
+	return recent
 }
 
 // countRecentSlings counts sling events from an actor within the given window.
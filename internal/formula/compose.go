@@ -0,0 +1,249 @@
+package formula
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// targets returns the step globs the advice applies to: its own target,
+// or else every pointcut of its formula.
+func (a Advice) targets(pointcuts []Pointcut) []string {
+	if a.Target != "" {
+		return []string{a.Target}
+	}
+	globs := make([]string, 0, len(pointcuts))
+	for _, pc := range pointcuts {
+		globs = append(globs, pc.Glob)
+	}
+	return globs
+}
+
+// steps returns the steps the advice adds before and after a step.
+func (a Advice) steps() (before, after []AdviceStep) {
+	before = append(before, a.Before...)
+	if a.Around != nil {
+		before = append(before, a.Around.Before...)
+		after = append(after, a.Around.After...)
+	}
+	after = append(after, a.After...)
+	return before, after
+}
+
+// mergeByID returns base with each item of over replacing the base item
+// with the same ID, or appended when there is none.
+func mergeByID[T any](base, over []T, id func(T) string) []T {
+	out := slices.Clone(base)
+	for _, item := range over {
+		i := slices.IndexFunc(out, func(b T) bool { return id(b) == id(item) })
+		if i >= 0 {
+			out[i] = item
+		} else {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func mergeMap[V any](base, over map[string]V) map[string]V {
+	if len(base) == 0 && len(over) == 0 {
+		return over
+	}
+	out := make(map[string]V, len(base)+len(over))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range over {
+		out[k] = v
+	}
+	return out
+}
+
+// inherit returns child laid over its (already merged) parent: child
+// fields win, and steps, legs, templates and aspects with the same ID
+// replace the parent's.
+func inherit(parent, child *Formula) *Formula {
+	out := *child
+	if out.Description == "" {
+		out.Description = parent.Description
+	}
+	if out.Type == "" {
+		out.Type = parent.Type
+	}
+	if out.Version == 0 {
+		out.Version = parent.Version
+	}
+	if out.Output == nil {
+		out.Output = parent.Output
+	}
+	if out.Synthesis == nil {
+		out.Synthesis = parent.Synthesis
+	}
+	out.Inputs = mergeMap(parent.Inputs, child.Inputs)
+	out.Prompts = mergeMap(parent.Prompts, child.Prompts)
+	out.Vars = mergeMap(parent.Vars, child.Vars)
+	out.Legs = mergeByID(parent.Legs, child.Legs, func(l Leg) string { return l.ID })
+	out.Steps = mergeByID(parent.Steps, child.Steps, func(s Step) string { return s.ID })
+	out.Template = mergeByID(parent.Template, child.Template, func(t Template) string { return t.ID })
+	out.Aspects = mergeByID(parent.Aspects, child.Aspects, func(a Aspect) string { return a.ID })
+	out.Advice = append(slices.Clone(parent.Advice), child.Advice...)
+	out.Pointcuts = append(slices.Clone(parent.Pointcuts), child.Pointcuts...)
+	return &out
+}
+
+// replaceNeed rewrites every step that needs old to need repl instead,
+// skipping the steps in except.
+func replaceNeed(steps []Step, old string, repl []string, except map[string]bool) {
+	for i := range steps {
+		if except[steps[i].ID] || !slices.Contains(steps[i].Needs, old) {
+			continue
+		}
+		var needs []string
+		for _, n := range steps[i].Needs {
+			if n != old {
+				needs = append(needs, n)
+				continue
+			}
+			for _, r := range repl {
+				if !slices.Contains(needs, r) {
+					needs = append(needs, r)
+				}
+			}
+		}
+		steps[i].Needs = needs
+	}
+}
+
+// expand replaces the rule's target step with the templates of exp. The
+// templates without needs inherit the target's needs, and the steps that
+// needed the target need the templates nothing else needs.
+func expand(f *Formula, rule ExpandRule, exp *Formula) error {
+	i := slices.IndexFunc(f.Steps, func(s Step) bool { return s.ID == rule.Target })
+	if i < 0 {
+		return fmt.Errorf("expand target %q is not a step", rule.Target)
+	}
+	if exp.Type != TypeExpansion {
+		return fmt.Errorf("%s is a %s formula, not an expansion", exp.Name, exp.Type)
+	}
+	target := f.Steps[i]
+
+	vars := map[string]string{
+		"target":             target.ID,
+		"target.title":       target.Title,
+		"target.description": target.Description,
+	}
+	for k, v := range rule.Vars {
+		vars[k] = v
+	}
+
+	needed := make(map[string]bool)
+	var added []Step
+	for _, t := range exp.Template {
+		s := Step{
+			ID:          Substitute(t.ID, vars),
+			Title:       Substitute(t.Title, vars),
+			Description: Substitute(t.Description, vars),
+		}
+		for _, n := range t.Needs {
+			n = Substitute(n, vars)
+			s.Needs = append(s.Needs, n)
+			needed[n] = true
+		}
+		if len(s.Needs) == 0 {
+			s.Needs = slices.Clone(target.Needs)
+		}
+		added = append(added, s)
+	}
+	var sinks []string
+	for _, s := range added {
+		if !needed[s.ID] {
+			sinks = append(sinks, s.ID)
+		}
+	}
+
+	f.Steps = slices.Concat(f.Steps[:i], added, f.Steps[i+1:])
+	replaceNeed(f.Steps, target.ID, sinks, nil)
+	return nil
+}
+
+// weave applies the advice of aspect formula a to the steps of f. Each
+// advised step needs its before-steps, which run in order after the step's
+// own needs; its after-steps run in order after it, and steps that needed
+// it need the last of them. Steps added by advice are not advised.
+func weave(f *Formula, a *Formula) error {
+	if a.Type != TypeAspect || len(a.Advice) == 0 {
+		return fmt.Errorf("%s has no advice to apply", a.Name)
+	}
+
+	original := slices.Clone(f.Steps)
+	var woven []Step
+	lastAfter := make(map[string]string)
+	afterOf := make(map[string]map[string]bool)
+	for _, step := range original {
+		vars := map[string]string{"step.id": step.ID, "step.title": step.Title}
+		var before, after []Step
+		for _, adv := range a.Advice {
+			if !matchesAny(adv.targets(a.Pointcuts), step.ID) {
+				continue
+			}
+			b, af := adv.steps()
+			before = append(before, instantiateAdvice(b, vars)...)
+			after = append(after, instantiateAdvice(af, vars)...)
+		}
+
+		needs := step.Needs
+		for i := range before {
+			before[i].Needs = slices.Clone(needs)
+			needs = []string{before[i].ID}
+		}
+		step.Needs = slices.Clone(needs)
+
+		prev := step.ID
+		afterOf[step.ID] = make(map[string]bool)
+		for i := range after {
+			after[i].Needs = []string{prev}
+			prev = after[i].ID
+			afterOf[step.ID][prev] = true
+		}
+		if len(after) > 0 {
+			lastAfter[step.ID] = prev
+		}
+
+		woven = append(woven, before...)
+		woven = append(woven, step)
+		woven = append(woven, after...)
+	}
+
+	for id, last := range lastAfter {
+		replaceNeed(woven, id, []string{last}, afterOf[id])
+	}
+	f.Steps = woven
+	return nil
+}
+
+func instantiateAdvice(steps []AdviceStep, vars map[string]string) []Step {
+	out := make([]Step, 0, len(steps))
+	for _, s := range steps {
+		out = append(out, Step{
+			ID:          Substitute(s.ID, vars),
+			Title:       Substitute(s.Title, vars),
+			Description: Substitute(s.Description, vars),
+		})
+	}
+	return out
+}
+
+func matchesAny(globs []string, id string) bool {
+	for _, g := range globs {
+		if ok, _ := path.Match(g, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// composeErr locates a composition error in the formula's source.
+func composeErr(field, format string, args ...any) Problem {
+	return Problem{Field: field, Message: strings.TrimSpace(fmt.Sprintf(format, args...))}
+}
@@ -0,0 +1,280 @@
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Lint finding severities. Errors make a formula unusable; warnings point
+// at likely mistakes.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Finding is one lint result for a formula file. Line is 0 when the
+// finding cannot be placed.
+type Finding struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func (f Finding) String() string {
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", f.Path, f.Line, f.Severity, f.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", f.Path, f.Severity, f.Message)
+}
+
+// Lint checks a formula file: TOML syntax and types, structure, variable
+// declarations, and (for formulas that use extends or compose) the
+// composed result. Findings are sorted by line.
+func (l *Loader) Lint(path string) []Finding {
+	var findings []Finding
+	add := func(line int, severity, msg string) {
+		findings = append(findings, Finding{Path: path, Line: line, Severity: severity, Message: msg})
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is from trusted formula directory
+	if err != nil {
+		add(0, SeverityError, err.Error())
+		return findings
+	}
+	f, err := DecodeFile(path)
+	if err != nil {
+		add(parseErrorLine(err), SeverityError, err.Error())
+		return findings
+	}
+	if strings.HasSuffix(path, ".json") {
+		data = nil // Lines refer to the TOML form
+	}
+	idx := indexSource(data)
+
+	if name := formulaFileName(path); f.Name != "" && name != "" && f.Name != name {
+		add(idx.line("formula"), SeverityWarning,
+			fmt.Sprintf("formula %q is in %s; it is found by file name, as %q", f.Name, filepath.Base(path), name))
+	}
+
+	checked := f
+	composed := len(f.Extends) > 0 || f.Compose != nil
+	if composed {
+		resolved, err := l.resolve(f, nil)
+		if err != nil {
+			var p Problem
+			field := "extends"
+			if errors.As(err, &p) {
+				field = p.Field
+			}
+			add(idx.line(field), SeverityError, err.Error())
+			checked = nil
+		} else {
+			checked = resolved
+		}
+	}
+	if checked != nil {
+		for _, p := range checked.Problems() {
+			add(idx.place(p, composed), SeverityError, p.Message)
+		}
+		for _, w := range checked.undeclaredVars() {
+			add(idx.idLine(w.ID), SeverityWarning, w.Message)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Line < findings[j].Line })
+	return findings
+}
+
+// undeclaredVars warns about {{name}} placeholders in the IDs and titles
+// of a formula that declares variables but not that one. Descriptions are
+// not checked: they often carry templates for the agent to fill in.
+// Formulas without declarations get their variables from the caller.
+func (f *Formula) undeclaredVars() []Problem {
+	if len(f.Vars) == 0 && len(f.Inputs) == 0 {
+		return nil
+	}
+	var problems []Problem
+	check := func(id string, texts ...string) {
+		seen := make(map[string]bool)
+		for _, text := range texts {
+			for _, m := range doubleBrace.FindAllStringSubmatch(text, -1) {
+				name := m[1]
+				if _, ok := f.Vars[name]; ok || seen[name] {
+					continue
+				}
+				if _, ok := f.Inputs[name]; ok {
+					continue
+				}
+				seen[name] = true
+				problems = append(problems, Problem{ID: id,
+					Message: fmt.Sprintf("%s uses undeclared variable {{%s}}", id, name)})
+			}
+		}
+	}
+	for _, s := range f.Steps {
+		check(s.ID, s.ID, s.Title)
+	}
+	for _, leg := range f.Legs {
+		check(leg.ID, leg.Title, leg.Focus)
+	}
+	for _, a := range f.Aspects {
+		check(a.ID, a.Title, a.Focus)
+	}
+	return problems
+}
+
+var doubleBrace = regexp.MustCompile(`\{\{\s*([A-Za-z_][\w.-]*)\s*\}\}`)
+
+// formulaFileName returns the formula name a file is found by.
+func formulaFileName(path string) string {
+	base := filepath.Base(path)
+	for _, ext := range fileExts {
+		if name, ok := strings.CutSuffix(base, ext); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// sourceIndex maps field paths such as "steps[2].needs" to the line they
+// are written on, and array-table IDs to the line of their id key. The
+// TOML decoder does not expose key positions, so this is a light scan of
+// table headers and keys that skips multi-line strings.
+type sourceIndex struct {
+	fields map[string]int
+	ids    map[string]int
+}
+
+var (
+	arrayHeader = regexp.MustCompile(`^\[\[\s*([^\]]+?)\s*\]\]`)
+	tableHeader = regexp.MustCompile(`^\[\s*([^\]]+?)\s*\]`)
+	keyLine     = regexp.MustCompile(`^("[^"]*"|'[^']*'|[A-Za-z0-9_-]+)\s*=\s*(.*)$`)
+)
+
+func indexSource(data []byte) *sourceIndex {
+	idx := &sourceIndex{fields: make(map[string]int), ids: make(map[string]int)}
+	counts := make(map[string]int)     // resolved array path -> elements so far
+	current := make(map[string]string) // array name -> path of its current element
+	prefix := ""
+	inString := ""
+
+	// resolve turns a header name into a path, putting the current element
+	// index after each enclosing array of tables.
+	resolve := func(name string) string {
+		parts := strings.Split(name, ".")
+		for i := len(parts) - 1; i > 0; i-- {
+			if p, ok := current[strings.Join(parts[:i], ".")]; ok {
+				return p + "." + strings.Join(parts[i:], ".")
+			}
+		}
+		return name
+	}
+
+	for n, raw := range strings.Split(string(data), "\n") {
+		lineNo := n + 1
+		line := strings.TrimSpace(raw)
+		if inString != "" {
+			if strings.Contains(line, inString) {
+				inString = ""
+			}
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if m := arrayHeader.FindStringSubmatch(line); m != nil {
+			base := resolve(m[1])
+			path := fmt.Sprintf("%s[%d]", base, counts[base])
+			counts[base]++
+			current[m[1]] = path
+			prefix = path
+			idx.record(path, lineNo)
+			idx.record(base, lineNo)
+			continue
+		}
+		if m := tableHeader.FindStringSubmatch(line); m != nil {
+			prefix = resolve(m[1])
+			idx.record(prefix, lineNo)
+			continue
+		}
+		m := keyLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		key := strings.Trim(m[1], `"'`)
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+		idx.record(field, lineNo)
+		if key == "id" {
+			if id := strings.Trim(strings.TrimSpace(m[2]), `"'`); id != "" {
+				if _, ok := idx.ids[id]; !ok {
+					idx.ids[id] = lineNo
+				}
+			}
+		}
+		for _, q := range []string{`"""`, `'''`} {
+			if strings.HasPrefix(m[2], q) && strings.Count(m[2], q) == 1 {
+				inString = q
+			}
+		}
+	}
+	return idx
+}
+
+func (idx *sourceIndex) record(field string, line int) {
+	if _, ok := idx.fields[field]; !ok {
+		idx.fields[field] = line
+	}
+}
+
+// line returns the line of field, or of the nearest enclosing field that
+// was written out, or 0.
+func (idx *sourceIndex) line(field string) int {
+	for field != "" {
+		if line, ok := idx.fields[field]; ok {
+			return line
+		}
+		cut := strings.LastIndexAny(field, ".[")
+		if cut < 0 {
+			break
+		}
+		field = field[:cut]
+	}
+	return 0
+}
+
+// place returns the line a problem is reported on. Problems with a
+// specific element are placed by its index; in a composed formula the
+// indexes do not match this file, so they are placed by ID, falling back
+// to the extends line.
+func (idx *sourceIndex) place(p Problem, composed bool) int {
+	if !composed && strings.Contains(p.Field, "[") {
+		return idx.line(p.Field)
+	}
+	if line := idx.idLine(p.ID); line > 0 {
+		return line
+	}
+	if composed && p.ID != "" {
+		return idx.line("extends")
+	}
+	if line := idx.line(p.Field); line > 0 {
+		return line
+	}
+	return idx.line("extends")
+}
+
+// idLine returns the line of the id key with the given value, or 0.
+func (idx *sourceIndex) idLine(id string) int {
+	if id == "" {
+		return 0
+	}
+	return idx.ids[id]
+}
@@ -0,0 +1,297 @@
+package formula
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Search path layers, in order of precedence.
+const (
+	LayerProject = "project" // <cwd>/.beads/formulas
+	LayerUser    = "user"    // ~/.beads/formulas
+	LayerTown    = "town"    // <town>/.beads/formulas
+)
+
+// Formula file extensions, in order of preference within a directory.
+var fileExts = []string{".formula.toml", ".formula.json"}
+
+// SearchDir is one directory on the formula search path.
+type SearchDir struct {
+	Layer string
+	Dir   string
+}
+
+// Source is a formula file found on the search path.
+type Source struct {
+	Name  string `json:"name"`
+	Layer string `json:"layer"`
+	Path  string `json:"path"`
+}
+
+// Entry is the formula a name resolves to, and the files of the same name
+// it shadows further down the search path.
+type Entry struct {
+	Source
+	Shadows []Source `json:"shadows,omitempty"`
+}
+
+// Loader finds formulas by name on a search path and loads them with their
+// composition (extends and compose) resolved.
+type Loader struct {
+	Dirs []SearchDir
+}
+
+// NewLoader returns a loader searching the project formulas under
+// projectDir, the user's ~/.beads/formulas, then the town's formulas.
+// An empty root is skipped, as is a directory already on the path (the
+// project is often the town root).
+func NewLoader(projectDir, townRoot string) *Loader {
+	l := &Loader{}
+	add := func(layer, root string) {
+		if root == "" {
+			return
+		}
+		dir := filepath.Join(root, ".beads", "formulas")
+		for _, d := range l.Dirs {
+			if d.Dir == dir {
+				return
+			}
+		}
+		l.Dirs = append(l.Dirs, SearchDir{Layer: layer, Dir: dir})
+	}
+	add(LayerProject, projectDir)
+	if home, err := os.UserHomeDir(); err == nil {
+		add(LayerUser, home)
+	}
+	add(LayerTown, townRoot)
+	return l
+}
+
+// Sources returns every file named name on the search path, in precedence
+// order: the first is the one that is used.
+func (l *Loader) Sources(name string) []Source {
+	var out []Source
+	for _, d := range l.Dirs {
+		for _, ext := range fileExts {
+			p := filepath.Join(d.Dir, name+ext)
+			if _, err := os.Stat(p); err == nil {
+				out = append(out, Source{Name: name, Layer: d.Layer, Path: p})
+			}
+		}
+	}
+	return out
+}
+
+// Find returns the file a formula name resolves to.
+func (l *Loader) Find(name string) (Source, error) {
+	sources := l.Sources(name)
+	if len(sources) == 0 {
+		return Source{}, fmt.Errorf("formula '%s' not found in search paths", name)
+	}
+	return sources[0], nil
+}
+
+// List returns every formula on the search path, sorted by name, with the
+// files each one shadows.
+func (l *Loader) List() []Entry {
+	byName := make(map[string]*Entry)
+	var names []string
+	for _, d := range l.Dirs {
+		files, err := os.ReadDir(d.Dir)
+		if err != nil {
+			continue
+		}
+		for _, ext := range fileExts {
+			for _, file := range files {
+				name, ok := strings.CutSuffix(file.Name(), ext)
+				if !ok || file.IsDir() {
+					continue
+				}
+				src := Source{Name: name, Layer: d.Layer, Path: filepath.Join(d.Dir, file.Name())}
+				if e, ok := byName[name]; ok {
+					e.Shadows = append(e.Shadows, src)
+					continue
+				}
+				byName[name] = &Entry{Source: src}
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	entries := make([]Entry, 0, len(names))
+	for _, name := range names {
+		entries = append(entries, *byName[name])
+	}
+	return entries
+}
+
+// Load finds a formula by name and loads it.
+func (l *Loader) Load(name string) (*Formula, error) {
+	return l.load(name, nil)
+}
+
+// LoadFile loads a formula file, resolving extends and compose through the
+// loader's search path, and validates the result.
+func (l *Loader) LoadFile(path string) (*Formula, error) {
+	f, err := DecodeFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return l.finish(f, nil)
+}
+
+func (l *Loader) load(name string, stack []string) (*Formula, error) {
+	if slices.Contains(stack, name) {
+		return nil, fmt.Errorf("formula composition cycle: %s -> %s", strings.Join(stack, " -> "), name)
+	}
+	src, err := l.Find(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := DecodeFile(src.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return l.finish(f, stack)
+}
+
+func (l *Loader) finish(f *Formula, stack []string) (*Formula, error) {
+	resolved, err := l.resolve(f, stack)
+	if err != nil {
+		return nil, err
+	}
+	if err := resolved.Validate(); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// resolve applies a decoded formula's extends and compose, returning the
+// composed formula without validating it. stack holds the formulas being
+// resolved, to catch cycles.
+func (l *Loader) resolve(f *Formula, stack []string) (*Formula, error) {
+	if len(f.Extends) == 0 && f.Compose == nil {
+		return f, nil
+	}
+	stack = append(slices.Clone(stack), f.Name)
+
+	out := f
+	if len(f.Extends) > 0 {
+		parent := &Formula{}
+		for _, name := range f.Extends {
+			p, err := l.load(name, stack)
+			if err != nil {
+				return nil, composeErr("extends", "extends %s: %v", name, err)
+			}
+			parent = inherit(parent, p)
+		}
+		out = inherit(parent, f)
+	} else {
+		copied := *f
+		out = &copied
+	}
+	out.Steps = slices.Clone(out.Steps)
+
+	if f.Compose == nil {
+		return out, nil
+	}
+	if len(f.Compose.Expand)+len(f.Compose.Aspects) > 0 && out.Type != TypeWorkflow {
+		return nil, composeErr("compose", "compose applies to workflow steps, but %s is a %s formula", f.Name, out.Type)
+	}
+	for i, rule := range f.Compose.Expand {
+		field := fmt.Sprintf("compose.expand[%d]", i)
+		exp, err := l.load(rule.With, stack)
+		if err != nil {
+			return nil, composeErr(field+".with", "expand %s with %s: %v", rule.Target, rule.With, err)
+		}
+		if err := expand(out, rule, exp); err != nil {
+			return nil, composeErr(field+".target", "expand %s with %s: %v", rule.Target, rule.With, err)
+		}
+	}
+	for _, name := range f.Compose.Aspects {
+		a, err := l.load(name, stack)
+		if err != nil {
+			return nil, composeErr("compose.aspects", "aspect %s: %v", name, err)
+		}
+		if err := weave(out, a); err != nil {
+			return nil, composeErr("compose.aspects", "aspect %s: %v", name, err)
+		}
+	}
+	return out, nil
+}
+
+// DecodeFile reads a .formula.toml or .formula.json file without validating
+// or resolving it.
+func DecodeFile(path string) (*Formula, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is from trusted formula directory
+	if err != nil {
+		return nil, fmt.Errorf("reading formula file: %w", err)
+	}
+	if strings.HasSuffix(path, ".json") {
+		if data, err = jsonToTOML(data); err != nil {
+			return nil, err
+		}
+	}
+	return Decode(data)
+}
+
+// jsonToTOML converts a JSON formula to TOML so that both formats decode
+// through the same struct tags. Whole numbers stay integers.
+func jsonToTOML(data []byte) ([]byte, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing JSON: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(wholeNumbers(doc)); err != nil {
+		return nil, fmt.Errorf("converting JSON: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func wholeNumbers(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = wholeNumbers(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = wholeNumbers(e)
+		}
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	}
+	return v
+}
+
+// decodeErrorLine matches the line the TOML decoder names in type errors,
+// which are not ParseErrors.
+var decodeErrorLine = regexp.MustCompile(`toml: line (\d+)`)
+
+// parseErrorLine returns the line of a TOML syntax or type error, or 0.
+func parseErrorLine(err error) int {
+	var perr toml.ParseError
+	if errors.As(err, &perr) {
+		return perr.Position.Line
+	}
+	if m := decodeErrorLine.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return line
+	}
+	return 0
+}
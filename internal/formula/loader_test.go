@@ -0,0 +1,295 @@
+package formula
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFormula(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name+".formula.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func stepIDs(f *Formula) []string {
+	var ids []string
+	for _, s := range f.Steps {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func TestLoaderShadowing(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "project", ".beads", "formulas")
+	town := filepath.Join(root, "town", ".beads", "formulas")
+	l := &Loader{Dirs: []SearchDir{
+		{Layer: LayerProject, Dir: project},
+		{Layer: LayerTown, Dir: town},
+	}}
+
+	writeFormula(t, town, "shared", "formula = \"shared\"\n[[steps]]\nid = \"town\"\n")
+	writeFormula(t, town, "town-only", "formula = \"town-only\"\n[[steps]]\nid = \"a\"\n")
+	projectPath := writeFormula(t, project, "shared", "formula = \"shared\"\n[[steps]]\nid = \"project\"\n")
+
+	src, err := l.Find("shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.Layer != LayerProject || src.Path != projectPath {
+		t.Errorf("Find(shared) = %+v, want the project file", src)
+	}
+	f, err := l.Load("shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := stepIDs(f); !reflect.DeepEqual(got, []string{"project"}) {
+		t.Errorf("loaded steps = %v, want the project formula", got)
+	}
+
+	entries := l.List()
+	if len(entries) != 2 || entries[0].Name != "shared" || entries[1].Name != "town-only" {
+		t.Fatalf("List() = %+v", entries)
+	}
+	if len(entries[0].Shadows) != 1 || entries[0].Shadows[0].Layer != LayerTown {
+		t.Errorf("shared shadows = %+v, want the town file", entries[0].Shadows)
+	}
+	if len(entries[1].Shadows) != 0 {
+		t.Errorf("town-only shadows = %+v", entries[1].Shadows)
+	}
+
+	if _, err := l.Find("missing"); err == nil {
+		t.Error("Find(missing) succeeded")
+	}
+}
+
+func TestNewLoaderSkipsDuplicateDirs(t *testing.T) {
+	root := t.TempDir()
+	l := NewLoader(root, root)
+	for _, d := range l.Dirs {
+		if d.Layer == LayerTown {
+			t.Errorf("town dir %s duplicates the project dir", d.Dir)
+		}
+	}
+}
+
+// The repo's own formulas exercise extends, compose.expand and
+// compose.aspects.
+func TestLoaderComposesRepoFormulas(t *testing.T) {
+	l := &Loader{Dirs: []SearchDir{{Layer: LayerTown, Dir: "formulas"}}}
+
+	enterprise, err := l.Load("shiny-enterprise")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"design",
+		"implement.draft", "implement.refine-1", "implement.refine-2", "implement.refine-3", "implement.refine-4",
+		"review", "test", "submit",
+	}
+	if got := stepIDs(enterprise); !reflect.DeepEqual(got, want) {
+		t.Errorf("shiny-enterprise steps = %v\nwant %v", got, want)
+	}
+	if got := enterprise.GetStep("implement.draft").Needs; !reflect.DeepEqual(got, []string{"design"}) {
+		t.Errorf("draft needs = %v, want the target's needs", got)
+	}
+	if got := enterprise.GetStep("review").Needs; !reflect.DeepEqual(got, []string{"implement.refine-4"}) {
+		t.Errorf("review needs = %v, want the last expansion step", got)
+	}
+	if !strings.Contains(enterprise.GetStep("implement.draft").Title, "Implement {{feature}}") {
+		t.Errorf("draft title = %q, want the target's title", enterprise.GetStep("implement.draft").Title)
+	}
+	if _, ok := enterprise.Vars["feature"]; !ok {
+		t.Error("vars not inherited from shiny")
+	}
+
+	secure, err := l.Load("shiny-secure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		"design",
+		"implement-security-prescan", "implement", "implement-security-postscan",
+		"review", "test",
+		"submit-security-prescan", "submit", "submit-security-postscan",
+	}
+	if got := stepIDs(secure); !reflect.DeepEqual(got, want) {
+		t.Errorf("shiny-secure steps = %v\nwant %v", got, want)
+	}
+	for id, needs := range map[string][]string{
+		"implement-security-prescan":  {"design"},
+		"implement":                   {"implement-security-prescan"},
+		"implement-security-postscan": {"implement"},
+		"review":                      {"implement-security-postscan"},
+		"submit-security-prescan":     {"test"},
+	} {
+		if got := secure.GetStep(id).Needs; !reflect.DeepEqual(got, needs) {
+			t.Errorf("%s needs = %v, want %v", id, got, needs)
+		}
+	}
+}
+
+func TestLoaderExtendsOverridesAndCycles(t *testing.T) {
+	dir := t.TempDir()
+	l := &Loader{Dirs: []SearchDir{{Layer: LayerProject, Dir: dir}}}
+	writeFormula(t, dir, "base", `
+formula = "base"
+type = "workflow"
+[[steps]]
+id = "a"
+title = "Base A"
+[[steps]]
+id = "b"
+needs = ["a"]
+[vars.x]
+default = "1"
+`)
+	writeFormula(t, dir, "child", `
+formula = "child"
+extends = ["base"]
+[[steps]]
+id = "a"
+title = "Child A"
+[[steps]]
+id = "c"
+needs = ["b"]
+[vars.x]
+default = "2"
+`)
+	f, err := l.Load("child")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := stepIDs(f); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("steps = %v", got)
+	}
+	if f.GetStep("a").Title != "Child A" || f.Vars["x"].Default != "2" || f.Type != TypeWorkflow {
+		t.Errorf("child did not override base: %+v", f)
+	}
+
+	writeFormula(t, dir, "loop-a", "formula = \"loop-a\"\nextends = [\"loop-b\"]\n")
+	writeFormula(t, dir, "loop-b", "formula = \"loop-b\"\nextends = [\"loop-a\"]\n")
+	if _, err := l.Load("loop-a"); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Load(loop-a) err = %v, want a composition cycle", err)
+	}
+}
+
+func TestResolveVars(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "typed"
+[[steps]]
+id = "a"
+[vars.count]
+type = "integer"
+default = "3"
+[vars.mode]
+enum = ["fast", "slow"]
+required = true
+[vars.ticket]
+pattern = "[A-Z]+-[0-9]+"
+[inputs.pr]
+type = "number"
+required = true
+required_unless = ["branch"]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vars, err := f.ResolveVars(map[string]string{"mode": "fast", "pr": "12", "extra": "kept"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["count"] != "3" || vars["extra"] != "kept" {
+		t.Errorf("vars = %v", vars)
+	}
+	if _, err := f.ResolveVars(map[string]string{"mode": "fast", "branch": "main"}); err != nil {
+		t.Errorf("required_unless not honoured: %v", err)
+	}
+
+	_, err = f.ResolveVars(map[string]string{"count": "many", "mode": "medium", "ticket": "gt-1"})
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{"missing required variable(s): pr", `"count" must be an integer`, `"mode" must be one of`, `"ticket" must match`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	if _, err := Parse([]byte("formula = \"bad\"\n[[steps]]\nid = \"a\"\n[vars.n]\ntype = \"number\"\ndefault = \"ten\"\n")); err == nil {
+		t.Error("default of the wrong type was accepted")
+	}
+}
+
+func TestSubstitute(t *testing.T) {
+	vars := map[string]string{"feature": "auth", "target.title": "Login"}
+	got := Substitute("{{feature}} / {{ feature }} / {target.title} / ${HOME} / {unknown}", vars)
+	if want := "auth / auth / Login / ${HOME} / {unknown}"; got != want {
+		t.Errorf("Substitute = %q, want %q", got, want)
+	}
+	if got := Placeholders("{b} {{a}} {b}"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Placeholders = %v", got)
+	}
+}
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	l := &Loader{Dirs: []SearchDir{{Layer: LayerProject, Dir: dir}}}
+
+	path := writeFormula(t, dir, "broken", `formula = "broken"
+type = "workflow"
+
+[[steps]]
+id = "a"
+description = """
+id = "not-a-step"
+"""
+
+[[steps]]
+id = "b"
+needs = ["missing"]
+title = "B {{undeclared}}"
+
+[[steps]]
+id = "a"
+
+[vars.n]
+type = "number"
+default = "ten"
+`)
+	var got []string
+	for _, f := range l.Lint(path) {
+		got = append(got, f.String())
+	}
+	want := []string{
+		path + ":11: warning: b uses undeclared variable {{undeclared}}",
+		path + ":12: error: step \"b\" needs unknown step: missing",
+		path + ":16: error: duplicate step id: a",
+		path + ":20: error: default: variable \"n\" must be a number, got \"ten\"",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	syntax := writeFormula(t, dir, "syntax", "formula = \"syntax\"\n\n[[steps]\nid = \"a\"\n")
+	findings := l.Lint(syntax)
+	if len(findings) != 1 || findings[0].Line == 0 || findings[0].Severity != SeverityError {
+		t.Errorf("Lint(syntax) = %+v, want one error with its line", findings)
+	}
+
+	composed := writeFormula(t, dir, "composed", "formula = \"composed\"\nversion = 1\nextends = [\"nowhere\"]\n")
+	findings = l.Lint(composed)
+	if len(findings) != 1 || findings[0].Line != 3 || !strings.Contains(findings[0].Message, "nowhere") {
+		t.Errorf("Lint(composed) = %+v, want the extends error on line 3", findings)
+	}
+}
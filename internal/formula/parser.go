@@ -1,8 +1,10 @@
 package formula
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path"
 
 	"github.com/BurntSushi/toml"
 )
@@ -17,7 +19,23 @@ func ParseFile(path string) (*Formula, error) {
 }
 
 // Parse parses formula.toml content from bytes.
+// Formulas that use extends or compose must be loaded through a Loader,
+// which can find the formulas they refer to.
 func Parse(data []byte) (*Formula, error) {
+	f, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Decode parses formula.toml content without validating it.
+func Decode(data []byte) (*Formula, error) {
 	var f Formula
 	if _, err := toml.Decode(string(data), &f); err != nil {
 		return nil, fmt.Errorf("parsing TOML: %w", err)
@@ -26,10 +44,6 @@ func Parse(data []byte) (*Formula, error) {
 	// Infer type from content if not explicitly set
 	f.inferType()
 
-	if err := f.Validate(); err != nil {
-		return nil, err
-	}
-
 	return &f, nil
 }
 
@@ -46,50 +60,79 @@ func (f *Formula) inferType() {
 		f.Type = TypeConvoy
 	} else if len(f.Template) > 0 {
 		f.Type = TypeExpansion
-	} else if len(f.Aspects) > 0 {
+	} else if len(f.Aspects) > 0 || len(f.Advice) > 0 {
 		f.Type = TypeAspect
 	}
 }
 
+// Problem is one way a formula is invalid. Field locates it in the
+// formula's source (for example "steps[2].needs"); ID is the step, leg,
+// template or aspect it concerns, if any.
+type Problem struct {
+	Field   string
+	ID      string
+	Message string
+}
+
+func (p Problem) Error() string {
+	return p.Message
+}
+
 // Validate checks that the formula has all required fields and valid structure.
+// It returns the first problem found; Problems returns them all.
 func (f *Formula) Validate() error {
+	if problems := f.Problems(); len(problems) > 0 {
+		return problems[0]
+	}
+	return nil
+}
+
+// Problems returns everything wrong with the formula's structure and
+// variable declarations.
+func (f *Formula) Problems() []Problem {
+	var problems []Problem
+
 	// Check required common fields
 	if f.Name == "" {
-		return fmt.Errorf("formula field is required")
+		problems = append(problems, Problem{Field: "formula", Message: "formula field is required"})
 	}
 
 	if !f.Type.IsValid() {
-		return fmt.Errorf("invalid formula type %q (must be convoy, workflow, expansion, or aspect)", f.Type)
+		return append(problems, Problem{Field: "type",
+			Message: fmt.Sprintf("invalid formula type %q (must be convoy, workflow, expansion, or aspect)", f.Type)})
 	}
 
 	// Type-specific validation
 	switch f.Type {
 	case TypeConvoy:
-		return f.validateConvoy()
+		problems = append(problems, f.validateConvoy()...)
 	case TypeWorkflow:
-		return f.validateWorkflow()
+		problems = append(problems, f.validateWorkflow()...)
 	case TypeExpansion:
-		return f.validateExpansion()
+		problems = append(problems, f.validateExpansion()...)
 	case TypeAspect:
-		return f.validateAspect()
+		problems = append(problems, f.validateAspect()...)
 	}
 
-	return nil
+	return append(problems, f.validateVars()...)
 }
 
-func (f *Formula) validateConvoy() error {
+func (f *Formula) validateConvoy() []Problem {
 	if len(f.Legs) == 0 {
-		return fmt.Errorf("convoy formula requires at least one leg")
+		return []Problem{{Field: "legs", Message: "convoy formula requires at least one leg"}}
 	}
 
 	// Check leg IDs are unique
+	var problems []Problem
 	seen := make(map[string]bool)
-	for _, leg := range f.Legs {
+	for i, leg := range f.Legs {
+		field := fmt.Sprintf("legs[%d]", i)
 		if leg.ID == "" {
-			return fmt.Errorf("leg missing required id field")
+			problems = append(problems, Problem{Field: field, Message: "leg missing required id field"})
+			continue
 		}
 		if seen[leg.ID] {
-			return fmt.Errorf("duplicate leg id: %s", leg.ID)
+			problems = append(problems, Problem{Field: field + ".id", ID: leg.ID, Message: fmt.Sprintf("duplicate leg id: %s", leg.ID)})
 		}
 		seen[leg.ID] = true
 	}
@@ -98,95 +141,136 @@ func (f *Formula) validateConvoy() error {
 	if f.Synthesis != nil {
 		for _, dep := range f.Synthesis.DependsOn {
 			if !seen[dep] {
-				return fmt.Errorf("synthesis depends_on references unknown leg: %s", dep)
+				problems = append(problems, Problem{Field: "synthesis.depends_on",
+					Message: fmt.Sprintf("synthesis depends_on references unknown leg: %s", dep)})
 			}
 		}
 	}
 
-	return nil
+	return problems
 }
 
-func (f *Formula) validateWorkflow() error {
+func (f *Formula) validateWorkflow() []Problem {
 	if len(f.Steps) == 0 {
-		return fmt.Errorf("workflow formula requires at least one step")
+		return []Problem{{Field: "steps", Message: "workflow formula requires at least one step"}}
 	}
 
 	// Check step IDs are unique
+	var problems []Problem
 	seen := make(map[string]bool)
-	for _, step := range f.Steps {
+	for i, step := range f.Steps {
+		field := fmt.Sprintf("steps[%d]", i)
 		if step.ID == "" {
-			return fmt.Errorf("step missing required id field")
+			problems = append(problems, Problem{Field: field, Message: "step missing required id field"})
+			continue
 		}
 		if seen[step.ID] {
-			return fmt.Errorf("duplicate step id: %s", step.ID)
+			problems = append(problems, Problem{Field: field + ".id", ID: step.ID, Message: fmt.Sprintf("duplicate step id: %s", step.ID)})
 		}
 		seen[step.ID] = true
 	}
 
 	// Validate step needs references
-	for _, step := range f.Steps {
+	for i, step := range f.Steps {
 		for _, need := range step.Needs {
 			if !seen[need] {
-				return fmt.Errorf("step %q needs unknown step: %s", step.ID, need)
+				problems = append(problems, Problem{Field: fmt.Sprintf("steps[%d].needs", i), ID: step.ID,
+					Message: fmt.Sprintf("step %q needs unknown step: %s", step.ID, need)})
 			}
 		}
 	}
+	if len(problems) > 0 {
+		return problems
+	}
 
 	// Check for cycles
 	if err := f.checkCycles(); err != nil {
-		return err
+		var p Problem
+		if errors.As(err, &p) {
+			return []Problem{p}
+		}
+		return []Problem{{Field: "steps", Message: err.Error()}}
 	}
 
 	return nil
 }
 
-func (f *Formula) validateExpansion() error {
+func (f *Formula) validateExpansion() []Problem {
 	if len(f.Template) == 0 {
-		return fmt.Errorf("expansion formula requires at least one template")
+		return []Problem{{Field: "template", Message: "expansion formula requires at least one template"}}
 	}
 
 	// Check template IDs are unique
+	var problems []Problem
 	seen := make(map[string]bool)
-	for _, tmpl := range f.Template {
+	for i, tmpl := range f.Template {
+		field := fmt.Sprintf("template[%d]", i)
 		if tmpl.ID == "" {
-			return fmt.Errorf("template missing required id field")
+			problems = append(problems, Problem{Field: field, Message: "template missing required id field"})
+			continue
 		}
 		if seen[tmpl.ID] {
-			return fmt.Errorf("duplicate template id: %s", tmpl.ID)
+			problems = append(problems, Problem{Field: field + ".id", ID: tmpl.ID, Message: fmt.Sprintf("duplicate template id: %s", tmpl.ID)})
 		}
 		seen[tmpl.ID] = true
 	}
 
 	// Validate template needs references
-	for _, tmpl := range f.Template {
+	for i, tmpl := range f.Template {
 		for _, need := range tmpl.Needs {
 			if !seen[need] {
-				return fmt.Errorf("template %q needs unknown template: %s", tmpl.ID, need)
+				problems = append(problems, Problem{Field: fmt.Sprintf("template[%d].needs", i), ID: tmpl.ID,
+					Message: fmt.Sprintf("template %q needs unknown template: %s", tmpl.ID, need)})
 			}
 		}
 	}
 
-	return nil
+	return problems
 }
 
-func (f *Formula) validateAspect() error {
-	if len(f.Aspects) == 0 {
-		return fmt.Errorf("aspect formula requires at least one aspect")
+func (f *Formula) validateAspect() []Problem {
+	if len(f.Aspects) == 0 && len(f.Advice) == 0 {
+		return []Problem{{Field: "aspects", Message: "aspect formula requires at least one aspect or advice"}}
 	}
 
 	// Check aspect IDs are unique
+	var problems []Problem
 	seen := make(map[string]bool)
-	for _, aspect := range f.Aspects {
+	for i, aspect := range f.Aspects {
+		field := fmt.Sprintf("aspects[%d]", i)
 		if aspect.ID == "" {
-			return fmt.Errorf("aspect missing required id field")
+			problems = append(problems, Problem{Field: field, Message: "aspect missing required id field"})
+			continue
 		}
 		if seen[aspect.ID] {
-			return fmt.Errorf("duplicate aspect id: %s", aspect.ID)
+			problems = append(problems, Problem{Field: field + ".id", ID: aspect.ID, Message: fmt.Sprintf("duplicate aspect id: %s", aspect.ID)})
 		}
 		seen[aspect.ID] = true
 	}
 
-	return nil
+	// Advice needs somewhere to apply and something to add
+	for i, adv := range f.Advice {
+		field := fmt.Sprintf("advice[%d]", i)
+		if adv.Target == "" && len(f.Pointcuts) == 0 {
+			problems = append(problems, Problem{Field: field, Message: "advice has no target and the formula has no pointcuts"})
+		}
+		for _, glob := range adv.targets(f.Pointcuts) {
+			if _, err := path.Match(glob, ""); err != nil {
+				problems = append(problems, Problem{Field: field + ".target", Message: fmt.Sprintf("invalid advice target %q: %v", glob, err)})
+			}
+		}
+		before, after := adv.steps()
+		if len(before)+len(after) == 0 {
+			problems = append(problems, Problem{Field: field, Message: "advice adds no steps"})
+		}
+		for _, s := range append(before, after...) {
+			if s.ID == "" {
+				problems = append(problems, Problem{Field: field, Message: "advice step missing required id field"})
+			}
+		}
+	}
+
+	return problems
 }
 
 // checkCycles detects circular dependencies in steps.
@@ -204,7 +288,7 @@ func (f *Formula) checkCycles() error {
 	var visit func(id string) error
 	visit = func(id string) error {
 		if inStack[id] {
-			return fmt.Errorf("cycle detected involving step: %s", id)
+			return Problem{Field: "steps", ID: id, Message: fmt.Sprintf("cycle detected involving step: %s", id)}
 		}
 		if visited[id] {
 			return nil
@@ -30,22 +30,75 @@ type Formula struct {
 	Type        FormulaType `toml:"type"`
 	Version     int         `toml:"version"`
 
+	// Composition: parent formulas whose contents this one inherits and
+	// overrides, and the expansions and aspects woven into the result.
+	Extends []string `toml:"extends"`
+	Compose *Compose `toml:"compose"`
+
 	// Convoy-specific
-	Inputs    map[string]Input `toml:"inputs"`
+	Inputs    map[string]Input  `toml:"inputs"`
 	Prompts   map[string]string `toml:"prompts"`
 	Output    *Output           `toml:"output"`
 	Legs      []Leg             `toml:"legs"`
 	Synthesis *Synthesis        `toml:"synthesis"`
 
 	// Workflow-specific
-	Steps []Step           `toml:"steps"`
-	Vars  map[string]Var   `toml:"vars"`
+	Steps []Step         `toml:"steps"`
+	Vars  map[string]Var `toml:"vars"`
 
 	// Expansion-specific
 	Template []Template `toml:"template"`
 
 	// Aspect-specific (similar to convoy but for analysis)
 	Aspects []Aspect `toml:"aspects"`
+
+	// Aspect-oriented: steps woven around the steps of a formula that
+	// composes this one (see Compose.Aspects).
+	Advice    []Advice   `toml:"advice"`
+	Pointcuts []Pointcut `toml:"pointcuts"`
+}
+
+// Compose describes what is woven into a formula after extends is applied.
+type Compose struct {
+	Expand  []ExpandRule `toml:"expand"`
+	Aspects []string     `toml:"aspects"`
+}
+
+// ExpandRule replaces the Target step with the templates of the expansion
+// formula With. Templates see the step as {target}, {target.title} and
+// {target.description}, plus any Vars.
+type ExpandRule struct {
+	Target string            `toml:"target"`
+	With   string            `toml:"with"`
+	Vars   map[string]string `toml:"vars"`
+}
+
+// Advice adds steps before and after each step whose ID matches Target
+// (a glob; empty means every pointcut). Advice steps see the advised step
+// as {step.id} and {step.title}.
+type Advice struct {
+	Target string       `toml:"target"`
+	Before []AdviceStep `toml:"before"`
+	After  []AdviceStep `toml:"after"`
+	Around *Around      `toml:"around"`
+}
+
+// Around is advice on both sides of a step.
+type Around struct {
+	Before []AdviceStep `toml:"before"`
+	After  []AdviceStep `toml:"after"`
+}
+
+// AdviceStep is a step inserted by advice.
+type AdviceStep struct {
+	ID          string `toml:"id"`
+	Title       string `toml:"title"`
+	Description string `toml:"description"`
+}
+
+// Pointcut selects the steps an aspect formula applies to.
+type Pointcut struct {
+	Glob string `toml:"glob"`
 }
 
 // Aspect represents a parallel analysis aspect in an aspect formula.
@@ -108,6 +161,12 @@ type Var struct {
 	Description string `toml:"description"`
 	Required    bool   `toml:"required"`
 	Default     string `toml:"default"`
+	// Type is string (default), number, integer or boolean.
+	Type string `toml:"type"`
+	// Enum lists the allowed values, if set.
+	Enum []string `toml:"enum"`
+	// Pattern is a regular expression the whole value must match, if set.
+	Pattern string `toml:"pattern"`
 }
 
 // IsValid returns true if the formula type is recognized.
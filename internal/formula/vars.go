@@ -0,0 +1,194 @@
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Variable types. An empty type is a string.
+const (
+	VarString  = "string"
+	VarNumber  = "number"
+	VarInteger = "integer"
+	VarBoolean = "boolean"
+)
+
+// placeholder matches {{name}} and {name} placeholders.
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][\w.-]*)\s*\}\}|\{([A-Za-z_][\w.-]*)\}`)
+
+// Substitute replaces {{name}} and {name} with the variable's value.
+// Unknown placeholders are left alone: descriptions often contain shell
+// snippets with braces.
+func Substitute(s string, vars map[string]string) string {
+	return placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		if v, ok := vars[placeholderName(m)]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+// Placeholders returns the names of the placeholders in s, sorted.
+func Placeholders(s string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range placeholder.FindAllString(s, -1) {
+		name := placeholderName(m)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func placeholderName(m string) string {
+	sub := placeholder.FindStringSubmatch(m)
+	if sub[1] != "" {
+		return sub[1]
+	}
+	return sub[2]
+}
+
+// varSpec is what Var and Input have in common.
+type varSpec struct {
+	field          string // "vars.<name>" or "inputs.<name>"
+	required       bool
+	requiredUnless []string
+	def            string
+	typ            string
+	enum           []string
+	pattern        string
+}
+
+// varSpecs returns the declared variables and inputs by name.
+func (f *Formula) varSpecs() map[string]varSpec {
+	specs := make(map[string]varSpec, len(f.Vars)+len(f.Inputs))
+	for name, v := range f.Vars {
+		specs[name] = varSpec{field: "vars." + name, required: v.Required, def: v.Default,
+			typ: v.Type, enum: v.Enum, pattern: v.Pattern}
+	}
+	for name, in := range f.Inputs {
+		specs[name] = varSpec{field: "inputs." + name, required: in.Required, requiredUnless: in.RequiredUnless,
+			def: in.Default, typ: in.Type}
+	}
+	return specs
+}
+
+func sortedNames(specs map[string]varSpec) []string {
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// check reports why value is not a valid value of the variable.
+func (s varSpec) check(name, value string) error {
+	switch s.typ {
+	case "", VarString:
+	case VarNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("variable %q must be a number, got %q", name, value)
+		}
+	case VarInteger, "int":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("variable %q must be an integer, got %q", name, value)
+		}
+	case VarBoolean, "bool":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("variable %q must be true or false, got %q", name, value)
+		}
+	}
+	if len(s.enum) > 0 && !slices.Contains(s.enum, value) {
+		return fmt.Errorf("variable %q must be one of %s, got %q", name, strings.Join(s.enum, ", "), value)
+	}
+	if s.pattern != "" {
+		if re, err := regexp.Compile(`^(?:` + s.pattern + `)$`); err == nil && !re.MatchString(value) {
+			return fmt.Errorf("variable %q must match %s, got %q", name, s.pattern, value)
+		}
+	}
+	return nil
+}
+
+// validateVars checks that declared types and patterns are valid and that
+// defaults satisfy them.
+func (f *Formula) validateVars() []Problem {
+	var problems []Problem
+	specs := f.varSpecs()
+	for _, name := range sortedNames(specs) {
+		s := specs[name]
+		switch s.typ {
+		case "", VarString, VarNumber, VarInteger, "int", VarBoolean, "bool":
+		default:
+			problems = append(problems, Problem{Field: s.field + ".type",
+				Message: fmt.Sprintf("variable %q has unknown type %q (must be string, number, integer or boolean)", name, s.typ)})
+			continue
+		}
+		if s.pattern != "" {
+			if _, err := regexp.Compile(s.pattern); err != nil {
+				problems = append(problems, Problem{Field: s.field + ".pattern",
+					Message: fmt.Sprintf("variable %q has an invalid pattern: %v", name, err)})
+				continue
+			}
+		}
+		if s.def != "" {
+			if err := s.check(name, s.def); err != nil {
+				problems = append(problems, Problem{Field: s.field + ".default", Message: "default: " + err.Error()})
+			}
+		}
+	}
+	return problems
+}
+
+// ResolveVars checks the given variable values against the formula's vars
+// and inputs and fills in defaults. It reports every required variable that
+// has no value and every value of the wrong type. Variables the formula
+// does not declare are passed through unchecked.
+func (f *Formula) ResolveVars(given map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(given))
+	for k, v := range given {
+		resolved[k] = v
+	}
+
+	specs := f.varSpecs()
+	var missing []string
+	var errs []error
+	for _, name := range sortedNames(specs) {
+		s := specs[name]
+		if v, ok := resolved[name]; ok {
+			if err := s.check(name, v); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if s.def != "" {
+			resolved[name] = s.def
+		} else if s.required && !anySet(given, s.requiredUnless) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		errs = append([]error{fmt.Errorf("missing required variable(s): %s", strings.Join(missing, ", "))}, errs...)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return resolved, nil
+}
+
+func anySet(vars map[string]string, names []string) bool {
+	for _, n := range names {
+		if vars[n] != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -35,8 +35,9 @@ func (e *GitError) Unwrap() error {
 
 // Git wraps git operations for a working directory.
 type Git struct {
-	workDir string
-	gitDir  string // Optional: explicit git directory (for bare repos)
+	workDir    string
+	gitDir     string // Optional: explicit git directory (for bare repos)
+	signingKey string // Optional: key that signs the commits this Git creates
 }
 
 // NewGit creates a new Git wrapper for the given directory.
@@ -51,6 +52,29 @@ func NewGitWithDir(gitDir, workDir string) *Git {
 	return &Git{gitDir: gitDir, workDir: workDir}
 }
 
+// WithSigningKey returns a copy of g that signs every commit it creates
+// (commits, merge commits, rebased commits) with key. A key that is an SSH
+// public key, or a path to a .pub file, uses SSH signing; anything else is
+// taken as a GPG key ID.
+func (g *Git) WithSigningKey(key string) *Git {
+	signed := *g
+	signed.signingKey = key
+	return &signed
+}
+
+// signingArgs returns the config overrides that turn on commit signing.
+func (g *Git) signingArgs() []string {
+	if g.signingKey == "" {
+		return nil
+	}
+	args := []string{"-c", "commit.gpgsign=true", "-c", "user.signingkey=" + g.signingKey}
+	if strings.HasPrefix(g.signingKey, "ssh-") || strings.HasPrefix(g.signingKey, "key::") ||
+		strings.HasSuffix(g.signingKey, ".pub") {
+		args = append(args, "-c", "gpg.format=ssh")
+	}
+	return args
+}
+
 // WorkDir returns the working directory for this Git instance.
 func (g *Git) WorkDir() string {
 	return g.workDir
@@ -69,7 +93,7 @@ func (g *Git) run(args ...string) (string, error) {
 		args = append([]string{"--git-dir=" + g.gitDir}, args...)
 	}
 
-	cmd := exec.Command("git", args...)
+	cmd := exec.Command("git", append(g.signingArgs(), args...)...)
 	if g.workDir != "" {
 		cmd.Dir = g.workDir
 	}
@@ -399,14 +423,34 @@ func (g *Git) MergeNoFF(branch, message string) error {
 	return err
 }
 
+// MergeSquash stages the changes of branch on the current branch without
+// committing them, so they can be committed as a single commit.
+func (g *Git) MergeSquash(branch string) error {
+	_, err := g.run("merge", "--squash", branch)
+	return err
+}
+
+// MergeFFOnly fast-forwards the current branch to ref, failing if that
+// would require a merge commit.
+func (g *Git) MergeFFOnly(ref string) error {
+	_, err := g.run("merge", "--ff-only", ref)
+	return err
+}
+
 // DeleteRemoteBranch deletes a branch on the remote.
 func (g *Git) DeleteRemoteBranch(remote, branch string) error {
 	_, err := g.run("push", remote, "--delete", branch)
 	return err
 }
 
-// Rebase rebases the current branch onto the given ref.
+// Rebase rebases the current branch onto the given ref. With a signing key,
+// every commit is rewritten, even ones that are already on top of onto, so
+// all of them carry a signature.
 func (g *Git) Rebase(onto string) error {
+	if g.signingKey != "" {
+		_, err := g.run("rebase", "--force-rebase", onto)
+		return err
+	}
 	_, err := g.run("rebase", onto)
 	return err
 }
@@ -578,6 +622,21 @@ func (g *Git) ResetBranch(name, ref string) error {
 	return err
 }
 
+// ResetHard resets the current branch and working tree to ref.
+func (g *Git) ResetHard(ref string) error {
+	_, err := g.run("reset", "--hard", ref)
+	return err
+}
+
+// UpdateBranchRef points a branch at a commit without touching any worktree.
+// Unlike ResetBranch, this works even if the branch is checked out in another
+// worktree (e.g., a polecat's), which is what the refinery needs after
+// rebasing a polecat branch in a scratch worktree.
+func (g *Git) UpdateBranchRef(name, sha string) error {
+	_, err := g.run("update-ref", "refs/heads/"+name, sha)
+	return err
+}
+
 // Rev returns the commit hash for the given ref.
 func (g *Git) Rev(ref string) (string, error) {
 	return g.run("rev-parse", ref)
@@ -872,6 +931,33 @@ func (g *Git) BranchCreatedDate(branch string) (string, error) {
 	return out, nil
 }
 
+// ChangedFiles returns the files changed on head since it diverged from base
+// (git diff --name-only base...head).
+func (g *Git) ChangedFiles(base, head string) ([]string, error) {
+	out, err := g.run("diff", "--name-only", base+"..."+head)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// SameContent reports whether paths are identical in commits a and b
+// (git diff --quiet a b -- paths). Paths missing from both are equal.
+func (g *Git) SameContent(a, b string, paths []string) (bool, error) {
+	args := append([]string{"diff", "--quiet", a, b, "--"}, paths...)
+	if _, err := g.run(args...); err != nil {
+		// Exit code 1 means the contents differ, not an error
+		if strings.Contains(err.Error(), "exit status 1") {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 // CommitsAhead returns the number of commits that branch has ahead of base.
 // For example, CommitsAhead("main", "feature") returns how many commits
 // are on feature that are not on main.
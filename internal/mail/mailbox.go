@@ -179,7 +179,22 @@ func (m *Mailbox) identityVariants() []string {
 }
 
 // queryMessages runs a bd list query with the given filter flag and value.
+// Reads the beads store in-process when possible, falling back to bd.
 func (m *Mailbox) queryMessages(beadsDir, filterFlag, filterValue, status string) ([]*Message, error) {
+	opts := beads.ListOptions{IssueType: "message", Status: status, Priority: -1}
+	if filterFlag == "--label" {
+		opts.Label = filterValue
+	} else {
+		opts.Assignee = filterValue
+	}
+	if issues, err := beads.OpenStore(beadsDir).List(opts); err == nil {
+		var messages []*Message
+		for _, issue := range issues {
+			messages = append(messages, beadsMessageFromIssue(issue).ToMessage())
+		}
+		return messages, nil
+	}
+
 	args := []string{"list",
 		"--type", "message",
 		filterFlag, filterValue,
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
 )
 
 // Priority levels for messages.
@@ -335,6 +337,26 @@ func (bm *BeadsMessage) IsCCRecipient(identity string) bool {
 	return false
 }
 
+// beadsMessageFromIssue converts an issue read from the beads store into
+// the shape bd list --json produces for messages.
+func beadsMessageFromIssue(issue *beads.Issue) *BeadsMessage {
+	bm := &BeadsMessage{
+		ID:          issue.ID,
+		Title:       issue.Title,
+		Description: issue.Description,
+		Assignee:    issue.Assignee,
+		Priority:    issue.Priority,
+		Status:      issue.Status,
+		Labels:      issue.Labels,
+		Pinned:      issue.Pinned,
+		Wisp:        issue.Ephemeral,
+	}
+	if t, err := time.Parse(time.RFC3339, issue.CreatedAt); err == nil {
+		bm.CreatedAt = t
+	}
+	return bm
+}
+
 // ToMessage converts a BeadsMessage to a GGT Message.
 func (bm *BeadsMessage) ToMessage() *Message {
 	// Parse labels to extract metadata
@@ -10,6 +10,7 @@ import (
 	"github.com/steveyegge/gastown/internal/claude"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
@@ -121,8 +122,9 @@ func (m *Manager) Start(agentOverride string) error {
 		return fmt.Errorf("waiting for mayor to start: %w", err)
 	}
 
-	// Accept bypass permissions warning dialog if it appears.
-	_ = t.AcceptBypassPermissionsWarning(sessionID)
+	// Accept startup dialogs (e.g. the bypass permissions warning) if they appear.
+	adapter := runtime.ForRole("mayor", m.townRoot, "", agentOverride)
+	_ = runtime.DismissDialogs(t, adapter, sessionID)
 
 	time.Sleep(constants.ShutdownNotifyDelay)
 
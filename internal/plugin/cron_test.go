@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		expr  string
+		after string
+		want  string
+	}{
+		{"0 9 * * *", "2026-03-02 08:59", "2026-03-02 09:00"},
+		{"0 9 * * *", "2026-03-02 09:00", "2026-03-03 09:00"},
+		{"*/15 * * * *", "2026-03-02 10:07", "2026-03-02 10:15"},
+		{"5/20 * * * *", "2026-03-02 10:46", "2026-03-02 11:05"},
+		{"0 0 1 * *", "2026-01-31 12:00", "2026-02-01 00:00"},
+		{"30 17 * * mon-fri", "2026-03-06 18:00", "2026-03-09 17:30"}, // Friday evening -> Monday
+		{"0 12 * * 7", "2026-03-02 00:00", "2026-03-08 12:00"},        // 7 is Sunday
+		{"0 0 13 * fri", "2026-03-02 00:00", "2026-03-06 00:00"},      // 13th or any Friday
+		{"0 0 1 jan,jul *", "2026-02-01 00:00", "2026-07-01 00:00"},
+		{"@hourly", "2026-03-02 10:00", "2026-03-02 11:00"},
+		{"0 0 29 2 *", "2026-03-01 00:00", "2028-02-29 00:00"},
+	}
+	for _, tt := range tests {
+		sched, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := sched.Next(at(tt.after)); !got.Equal(at(tt.want)) {
+			t.Errorf("%q after %s = %s, want %s", tt.expr, tt.after, got.Format("2006-01-02 15:04 Mon"), tt.want)
+		}
+	}
+
+	never, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := never.Next(at("2026-01-01 00:00")); !got.IsZero() {
+		t.Errorf("Feb 30 = %s, want zero time", got)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * * funday",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want an error", expr)
+		}
+	}
+}
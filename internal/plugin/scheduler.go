@@ -0,0 +1,415 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Events that event gates can wait for (the gate's on field).
+const (
+	// EventStartup fires when the daemon starts.
+	EventStartup = "startup"
+
+	// EventRigDocked fires when a rig is docked. Rig-level plugins only
+	// see their own rig being docked.
+	EventRigDocked = "rig-docked"
+
+	// EventConvoyLanded fires when a convoy closes.
+	EventConvoyLanded = "convoy-landed"
+)
+
+// Event is something that happened in the town, for event gates.
+type Event struct {
+	// Name is one of the Event* constants.
+	Name string
+
+	// Rig is the rig the event concerns, if any.
+	Rig string
+
+	// Subject identifies what the event is about (a rig name, a convoy ID).
+	Subject string
+}
+
+func (e Event) String() string {
+	if e.Subject != "" {
+		return e.Name + " " + e.Subject
+	}
+	return e.Name
+}
+
+const (
+	// DefaultCooldown is the cooldown of a cooldown gate without a duration.
+	DefaultCooldown = time.Hour
+
+	// DefaultCheckTimeout bounds a condition gate's check command.
+	DefaultCheckTimeout = 30 * time.Second
+
+	// defaultRunTimeout is how long a dispatched plugin counts as in flight
+	// when it has no execution timeout and no run has been recorded.
+	defaultRunTimeout = 10 * time.Minute
+)
+
+// Decision is the outcome of evaluating one plugin whose gate opened, or
+// whose gate could not be evaluated.
+type Decision struct {
+	Plugin     string
+	Reason     string // why the gate opened
+	Dispatched bool
+	Err        error
+}
+
+// Scheduler evaluates plugin gates deterministically and dispatches the
+// plugins that are due. The daemon runs Tick on every heartbeat and feeds
+// town events in with Fire, so plugins no longer depend on the Deacon
+// remembering to check them.
+//
+// Gates are evaluated as follows:
+//   - cooldown: due when the duration has passed since the last recorded
+//     run or dispatch (or it has never run)
+//   - cron: due when the schedule has a time between the last run or
+//     dispatch (or when the plugin was first seen) and now
+//   - condition: due when the check command exits 0 within the timeout
+//   - event: due after a matching event fired, until it is dispatched
+//   - manual (or no gate): never
+//
+// A plugin is not dispatched again while a previous dispatch is in flight:
+// until a run is recorded after it or its execution timeout passes.
+// Dispatch times and pending events are kept in daemon/plugin-scheduler.json
+// so they survive daemon restarts.
+type Scheduler struct {
+	townRoot  string
+	statePath string
+
+	discover func() ([]*Plugin, error)
+	dispatch func(p *Plugin) error
+	lastRun  func(name string) (time.Time, error)
+	check    func(ctx context.Context, p *Plugin) error
+	now      func() time.Time
+
+	// CheckTimeout bounds each condition check (DefaultCheckTimeout if zero).
+	CheckTimeout time.Duration
+
+	mu      sync.Mutex
+	pending []Event
+	watched map[string]bool
+}
+
+// NewScheduler creates a scheduler for a town. discover returns the current
+// plugins; dispatch hands a due plugin to a dog and returns an error if it
+// could not (for example when no dog is idle), leaving it due.
+func NewScheduler(townRoot string, discover func() ([]*Plugin, error), dispatch func(p *Plugin) error) *Scheduler {
+	s := &Scheduler{
+		townRoot:  townRoot,
+		statePath: filepath.Join(townRoot, "daemon", "plugin-scheduler.json"),
+		discover:  discover,
+		dispatch:  dispatch,
+		now:       time.Now,
+	}
+	recorder := NewRecorder(townRoot)
+	s.lastRun = func(name string) (time.Time, error) {
+		run, err := recorder.GetLastRun(name)
+		if err != nil || run == nil {
+			return time.Time{}, err
+		}
+		return run.CreatedAt, nil
+	}
+	s.check = s.runCheck
+	return s
+}
+
+// Fire queues an event for the next Tick. It is safe to call from any
+// goroutine.
+func (s *Scheduler) Fire(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, e)
+}
+
+// Watches reports whether any plugin seen by the last Tick has an event
+// gate on the named event, so callers can skip detecting events nobody
+// waits for. Before the first Tick it reports true.
+func (s *Scheduler) Watches(event string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.watched == nil || s.watched[event]
+}
+
+// schedulerState is persisted between ticks and daemon restarts.
+type schedulerState struct {
+	Plugins map[string]*gateState `json:"plugins"`
+}
+
+// gateState is what the scheduler remembers about one plugin.
+type gateState struct {
+	// FirstSeen is when the scheduler first discovered the plugin; a cron
+	// plugin that has never run is due at its first scheduled time after it.
+	FirstSeen time.Time `json:"first_seen"`
+
+	// LastDispatch is when the plugin was last handed to a dog.
+	LastDispatch time.Time `json:"last_dispatch,omitempty"`
+
+	// Triggered is the event an event gate is waiting to dispatch for.
+	Triggered string `json:"triggered,omitempty"`
+}
+
+func (s *Scheduler) loadState() *schedulerState {
+	state := &schedulerState{}
+	if data, err := os.ReadFile(s.statePath); err == nil {
+		_ = json.Unmarshal(data, state) // A corrupt file starts afresh
+	}
+	if state.Plugins == nil {
+		state.Plugins = make(map[string]*gateState)
+	}
+	return state
+}
+
+// Tick evaluates every plugin's gate and dispatches the due ones. It
+// returns a decision for each plugin that was due or could not be
+// evaluated, in name order. Condition checks run in parallel, so a Tick
+// waits at most about CheckTimeout for them however many there are.
+func (s *Scheduler) Tick(ctx context.Context) ([]Decision, error) {
+	plugins, err := s.discover()
+	if err != nil {
+		return nil, fmt.Errorf("discovering plugins: %w", err)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+
+	now := s.now()
+	state := s.loadState()
+
+	s.mu.Lock()
+	events := s.pending
+	s.pending = nil
+	s.watched = make(map[string]bool)
+	for _, p := range plugins {
+		if p.Gate != nil && p.Gate.Type == GateEvent {
+			s.watched[p.Gate.On] = true
+		}
+	}
+	s.mu.Unlock()
+
+	seen := make(map[string]bool, len(plugins))
+	gates := make([]*gateState, len(plugins))
+	for i, p := range plugins {
+		seen[p.Name] = true
+		st := state.Plugins[p.Name]
+		if st == nil {
+			st = &gateState{FirstSeen: now}
+			state.Plugins[p.Name] = st
+		}
+		for _, e := range events {
+			if eventMatches(p, e) {
+				st.Triggered = e.String()
+			}
+		}
+		gates[i] = st
+	}
+
+	// Gates are evaluated concurrently, so slow condition checks share one
+	// CheckTimeout instead of holding up the caller one after another
+	type result struct {
+		d   Decision
+		due bool
+	}
+	results := make([]result, len(plugins))
+	var wg sync.WaitGroup
+	for i, p := range plugins {
+		wg.Add(1)
+		go func(i int, p *Plugin) {
+			defer wg.Done()
+			results[i].d, results[i].due = s.evaluate(ctx, p, gates[i], now)
+		}(i, p)
+	}
+	wg.Wait()
+
+	var decisions []Decision
+	for i, p := range plugins {
+		st := gates[i]
+		d, due := results[i].d, results[i].due
+		if !due {
+			if d.Err != nil {
+				decisions = append(decisions, d)
+			}
+			continue
+		}
+		if err := s.dispatch(p); err != nil {
+			d.Err = err
+		} else {
+			d.Dispatched = true
+			st.LastDispatch = now
+			st.Triggered = ""
+		}
+		decisions = append(decisions, d)
+	}
+
+	for name := range state.Plugins {
+		if !seen[name] {
+			delete(state.Plugins, name)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(s.statePath), 0755); err != nil {
+		return decisions, err
+	}
+	return decisions, util.AtomicWriteJSON(s.statePath, state)
+}
+
+// eventMatches reports whether an event opens a plugin's event gate.
+func eventMatches(p *Plugin, e Event) bool {
+	if p.Gate == nil || p.Gate.Type != GateEvent || p.Gate.On != e.Name {
+		return false
+	}
+	return p.RigName == "" || e.Rig == "" || p.RigName == e.Rig
+}
+
+// evaluate decides whether a plugin is due now.
+func (s *Scheduler) evaluate(ctx context.Context, p *Plugin, st *gateState, now time.Time) (Decision, bool) {
+	d := Decision{Plugin: p.Name}
+	if p.Gate == nil || p.Gate.Type == GateManual {
+		return d, false
+	}
+
+	// The ledger is only consulted when a gate needs it
+	var lastRun time.Time
+	var lastRunErr error
+	fetched := false
+	last := func() (time.Time, error) {
+		if !fetched {
+			lastRun, lastRunErr = s.lastRun(p.Name)
+			fetched = true
+		}
+		return lastRun, lastRunErr
+	}
+
+	if !st.LastDispatch.IsZero() && now.Before(st.LastDispatch.Add(runTimeout(p))) {
+		ran, err := last()
+		if err != nil {
+			d.Err = fmt.Errorf("reading last run: %w", err)
+			return d, false
+		}
+		if ran.Before(st.LastDispatch) {
+			return d, false // Still in flight
+		}
+	}
+
+	switch p.Gate.Type {
+	case GateCooldown:
+		cooldown := DefaultCooldown
+		if p.Gate.Duration != "" {
+			parsed, err := time.ParseDuration(p.Gate.Duration)
+			if err != nil {
+				d.Err = fmt.Errorf("cooldown gate: %w", err)
+				return d, false
+			}
+			cooldown = parsed
+		}
+		ran, err := last()
+		if err != nil {
+			d.Err = fmt.Errorf("reading last run: %w", err)
+			return d, false
+		}
+		latest := laterOf(ran, st.LastDispatch)
+		if latest.IsZero() {
+			d.Reason = "cooldown: never run"
+			return d, true
+		}
+		if now.Sub(latest) >= cooldown {
+			d.Reason = fmt.Sprintf("cooldown: %s since last run", cooldown)
+			return d, true
+		}
+
+	case GateCron:
+		sched, err := ParseCron(p.Gate.Schedule)
+		if err != nil {
+			d.Err = fmt.Errorf("cron gate: %w", err)
+			return d, false
+		}
+		ran, err := last()
+		if err != nil {
+			d.Err = fmt.Errorf("reading last run: %w", err)
+			return d, false
+		}
+		base := laterOf(ran, st.LastDispatch)
+		if base.IsZero() {
+			base = st.FirstSeen
+		}
+		if next := sched.Next(base); !next.IsZero() && !next.After(now) {
+			d.Reason = fmt.Sprintf("cron: %q was due at %s", p.Gate.Schedule, next.Format("2006-01-02 15:04"))
+			return d, true
+		}
+
+	case GateCondition:
+		if p.Gate.Check == "" {
+			d.Err = errors.New("condition gate has no check command")
+			return d, false
+		}
+		timeout := s.CheckTimeout
+		if timeout <= 0 {
+			timeout = DefaultCheckTimeout
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := s.check(checkCtx, p)
+		timedOut := checkCtx.Err() == context.DeadlineExceeded
+		cancel()
+		var exitErr *exec.ExitError
+		switch {
+		case timedOut:
+			d.Err = fmt.Errorf("condition check timed out after %s", timeout)
+		case errors.As(err, &exitErr):
+			// Non-zero exit: the condition does not hold
+		case err != nil:
+			d.Err = fmt.Errorf("condition check: %w", err)
+		default:
+			d.Reason = fmt.Sprintf("condition: %q passed", p.Gate.Check)
+			return d, true
+		}
+
+	case GateEvent:
+		if st.Triggered != "" {
+			d.Reason = "event: " + st.Triggered
+			return d, true
+		}
+
+	default:
+		d.Err = fmt.Errorf("unknown gate type %q", p.Gate.Type)
+	}
+	return d, false
+}
+
+// runCheck runs a condition gate's check command through the shell, from
+// the rig directory for rig plugins and the town root otherwise.
+func (s *Scheduler) runCheck(ctx context.Context, p *Plugin) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", p.Gate.Check) //nolint:gosec // G204: check comes from the plugin definition
+	cmd.Dir = s.townRoot
+	if p.RigName != "" {
+		cmd.Dir = filepath.Join(s.townRoot, p.RigName)
+	}
+	cmd.Env = append(os.Environ(), "GT_ROOT="+s.townRoot, "GT_PLUGIN="+p.Name)
+	return cmd.Run()
+}
+
+// runTimeout is how long a dispatch of p counts as in flight.
+func runTimeout(p *Plugin) time.Duration {
+	if p.Execution != nil && p.Execution.Timeout != "" {
+		if d, err := time.ParseDuration(p.Execution.Timeout); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultRunTimeout
+}
+
+func laterOf(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
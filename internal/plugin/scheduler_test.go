@@ -0,0 +1,218 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testScheduler returns a scheduler over fixed plugins with a fake clock,
+// ledger and dispatcher. dispatched collects the dispatched plugin names.
+func testScheduler(t *testing.T, plugins []*Plugin) (s *Scheduler, clock *time.Time, runs map[string]time.Time, dispatched *[]string) {
+	t.Helper()
+	start := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	clock = &start
+	runs = make(map[string]time.Time)
+	dispatched = &[]string{}
+
+	s = NewScheduler(t.TempDir(),
+		func() ([]*Plugin, error) { return plugins, nil },
+		func(p *Plugin) error {
+			*dispatched = append(*dispatched, p.Name)
+			return nil
+		})
+	s.now = func() time.Time { return *clock }
+	s.lastRun = func(name string) (time.Time, error) { return runs[name], nil }
+	return s, clock, runs, dispatched
+}
+
+func tick(t *testing.T, s *Scheduler) []Decision {
+	t.Helper()
+	decisions, err := s.Tick(context.Background())
+	if err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	return decisions
+}
+
+func TestSchedulerCooldown(t *testing.T) {
+	s, clock, runs, dispatched := testScheduler(t, []*Plugin{
+		{Name: "hourly", Gate: &Gate{Type: GateCooldown, Duration: "1h"}, Execution: &Execution{Timeout: "5m"}},
+		{Name: "manual", Gate: &Gate{Type: GateManual}},
+		{Name: "ungated"},
+	})
+
+	tick(t, s) // Never run: due
+	if !reflect.DeepEqual(*dispatched, []string{"hourly"}) {
+		t.Fatalf("dispatched = %v, want [hourly]", *dispatched)
+	}
+
+	// The dog records its run a few minutes later; the cooldown counts from
+	// the dispatch, so nothing is due within the hour
+	runs["hourly"] = clock.Add(3 * time.Minute)
+	*clock = clock.Add(30 * time.Minute)
+	tick(t, s)
+	if len(*dispatched) != 1 {
+		t.Fatalf("dispatched within cooldown: %v", *dispatched)
+	}
+
+	*clock = clock.Add(35 * time.Minute)
+	decisions := tick(t, s)
+	if len(*dispatched) != 2 || len(decisions) != 1 || !decisions[0].Dispatched {
+		t.Fatalf("after cooldown: dispatched = %v, decisions = %+v", *dispatched, decisions)
+	}
+}
+
+func TestSchedulerCron(t *testing.T) {
+	s, clock, _, dispatched := testScheduler(t, []*Plugin{
+		{Name: "morning", Gate: &Gate{Type: GateCron, Schedule: "0 9 * * *"}},
+	})
+
+	tick(t, s) // 08:00, first seen: not due
+	*clock = clock.Add(59 * time.Minute)
+	tick(t, s)
+	if len(*dispatched) != 0 {
+		t.Fatalf("dispatched before 09:00: %v", *dispatched)
+	}
+
+	*clock = clock.Add(2 * time.Minute) // 09:01
+	tick(t, s)
+	*clock = clock.Add(time.Hour) // Still in the same schedule window
+	tick(t, s)
+	if !reflect.DeepEqual(*dispatched, []string{"morning"}) {
+		t.Fatalf("dispatched = %v, want one morning run", *dispatched)
+	}
+
+	// A daemon down across 09:00 the next day catches up once
+	*clock = time.Date(2026, 3, 3, 13, 0, 0, 0, time.UTC)
+	tick(t, s)
+	tick(t, s)
+	if len(*dispatched) != 2 {
+		t.Fatalf("dispatched = %v, want a single catch-up run", *dispatched)
+	}
+}
+
+func TestSchedulerCondition(t *testing.T) {
+	s, clock, runs, dispatched := testScheduler(t, []*Plugin{
+		{Name: "when-true", Gate: &Gate{Type: GateCondition, Check: "true"}},
+		{Name: "when-false", Gate: &Gate{Type: GateCondition, Check: "false"}},
+		{Name: "slow", Gate: &Gate{Type: GateCondition, Check: "sleep 5"}},
+	})
+	s.CheckTimeout = 100 * time.Millisecond
+
+	decisions := tick(t, s)
+	if !reflect.DeepEqual(*dispatched, []string{"when-true"}) {
+		t.Fatalf("dispatched = %v, want [when-true]", *dispatched)
+	}
+	var slowErr error
+	for _, d := range decisions {
+		if d.Plugin == "slow" {
+			slowErr = d.Err
+		}
+	}
+	if slowErr == nil {
+		t.Errorf("slow check did not time out: %+v", decisions)
+	}
+
+	// In flight until a run is recorded after the dispatch
+	*clock = clock.Add(3 * time.Minute)
+	tick(t, s)
+	if len(*dispatched) != 1 {
+		t.Fatalf("redispatched while in flight: %v", *dispatched)
+	}
+	runs["when-true"] = clock.Add(time.Minute)
+	*clock = clock.Add(3 * time.Minute)
+	tick(t, s)
+	if len(*dispatched) != 2 {
+		t.Fatalf("not redispatched after the run finished: %v", *dispatched)
+	}
+}
+
+func TestSchedulerConditionChecksRunConcurrently(t *testing.T) {
+	s, _, _, dispatched := testScheduler(t, []*Plugin{
+		{Name: "a", Gate: &Gate{Type: GateCondition, Check: "a"}},
+		{Name: "b", Gate: &Gate{Type: GateCondition, Check: "b"}},
+		{Name: "c", Gate: &Gate{Type: GateCondition, Check: "c"}},
+	})
+	s.CheckTimeout = 5 * time.Second
+
+	// Each check passes only once all three are running at the same time
+	var started sync.WaitGroup
+	started.Add(3)
+	s.check = func(ctx context.Context, p *Plugin) error {
+		started.Done()
+		done := make(chan struct{})
+		go func() { started.Wait(); close(done) }()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	start := time.Now()
+	tick(t, s)
+	if !reflect.DeepEqual(*dispatched, []string{"a", "b", "c"}) {
+		t.Fatalf("dispatched = %v, want [a b c]", *dispatched)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Tick took %s; checks ran one after another", elapsed)
+	}
+}
+
+func TestSchedulerEvents(t *testing.T) {
+	var fail bool
+	plugins := []*Plugin{
+		{Name: "on-startup", Gate: &Gate{Type: GateEvent, On: EventStartup}},
+		{Name: "on-dock", RigName: "gastown", Gate: &Gate{Type: GateEvent, On: EventRigDocked}},
+		{Name: "on-landed", Gate: &Gate{Type: GateEvent, On: EventConvoyLanded}},
+	}
+	s, _, _, dispatched := testScheduler(t, plugins)
+	s.dispatch = func(p *Plugin) error {
+		if fail {
+			return errors.New("no idle dogs")
+		}
+		*dispatched = append(*dispatched, p.Name)
+		return nil
+	}
+
+	if !s.Watches(EventRigDocked) {
+		t.Error("Watches before the first tick should be true")
+	}
+	s.Fire(Event{Name: EventStartup})
+	s.Fire(Event{Name: EventRigDocked, Rig: "beads", Subject: "beads"})
+	tick(t, s)
+	if !reflect.DeepEqual(*dispatched, []string{"on-startup"}) {
+		t.Fatalf("dispatched = %v, want [on-startup]", *dispatched)
+	}
+	if !s.Watches(EventConvoyLanded) || s.Watches("heartbeat") {
+		t.Error("Watches does not reflect the event gates")
+	}
+
+	// A failed dispatch leaves the event pending for the next tick, even
+	// across a restart (a new scheduler reading the same state)
+	fail = true
+	s.Fire(Event{Name: EventConvoyLanded, Subject: "hq-cv-1"})
+	decisions := tick(t, s)
+	if len(decisions) != 1 || decisions[0].Err == nil || decisions[0].Reason != "event: convoy-landed hq-cv-1" {
+		t.Fatalf("decisions = %+v", decisions)
+	}
+
+	restarted, _, _, _ := testScheduler(t, plugins)
+	restarted.statePath = s.statePath
+	restarted.dispatch = s.dispatch
+	fail = false
+	tick(t, restarted)
+	tick(t, restarted)
+	if !reflect.DeepEqual(*dispatched, []string{"on-startup", "on-landed"}) {
+		t.Fatalf("dispatched = %v, want on-landed once after the retry", *dispatched)
+	}
+	if filepath.Base(s.statePath) != "plugin-scheduler.json" {
+		t.Errorf("state path = %s", s.statePath)
+	}
+}
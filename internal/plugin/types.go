@@ -70,7 +70,7 @@ type Gate struct {
 	// Check is for condition gates (command that returns exit 0 to run).
 	Check string `json:"check,omitempty" toml:"check,omitempty"`
 
-	// On is for event gates (e.g., "startup").
+	// On is for event gates: "startup", "rig-docked" or "convoy-landed".
 	On string `json:"on,omitempty" toml:"on,omitempty"`
 }
 
@@ -87,7 +87,7 @@ const (
 	// GateCondition runs if a check command returns exit 0.
 	GateCondition GateType = "condition"
 
-	// GateEvent runs on specific events (startup, etc).
+	// GateEvent runs on specific events (startup, rig-docked, convoy-landed).
 	GateEvent GateType = "event"
 
 	// GateManual never auto-runs, must be triggered explicitly.
@@ -221,7 +221,8 @@ func (m *Manager) exists(name string) bool {
 
 // AddOptions configures polecat creation.
 type AddOptions struct {
-	HookBead string // Bead ID to set as hook_bead at spawn time (atomic assignment)
+	HookBead   string // Bead ID to set as hook_bead at spawn time (atomic assignment)
+	StartPoint string // Commit or ref to start the worktree from (default: origin/<default-branch>)
 }
 
 // Add creates a new polecat as a git worktree from the repo base.
@@ -286,6 +287,10 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 		defaultBranch = rigCfg.DefaultBranch
 	}
 	startPoint := fmt.Sprintf("origin/%s", defaultBranch)
+	if opts.StartPoint != "" {
+		// e.g. a checkpoint's last commit, when restoring crashed work
+		startPoint = opts.StartPoint
+	}
 
 	// Always create fresh branch - unique name guarantees no collision
 	// git worktree add -b polecat/<name>-<timestamp> <path> <startpoint>
@@ -327,9 +332,9 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 		fmt.Printf("Warning: could not provision PRIME.md: %v\n", err)
 	}
 
-	// Copy overlay files from .runtime/overlay/ to polecat root.
+	// Apply overlay files from .runtime/overlay/ to polecat root.
 	// This allows services to have .env and other config files at their root.
-	if err := rig.CopyOverlay(m.rig.Path, clonePath); err != nil {
+	if err := rig.ApplyOverlay(m.rig.Path, clonePath, rig.OverlayScope{Role: "polecat", Name: name}); err != nil {
 		// Non-fatal - log warning but continue
 		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
 	}
@@ -634,8 +639,8 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 		fmt.Printf("Warning: could not set up shared beads: %v\n", err)
 	}
 
-	// Copy overlay files from .runtime/overlay/ to polecat root.
-	if err := rig.CopyOverlay(m.rig.Path, newClonePath); err != nil {
+	// Apply overlay files from .runtime/overlay/ to polecat root.
+	if err := rig.ApplyOverlay(m.rig.Path, newClonePath, rig.OverlayScope{Role: "polecat", Name: name}); err != nil {
 		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
 	}
 
@@ -7,8 +7,8 @@ import (
 	"strings"
 	"time"
 
-	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
 
@@ -140,9 +140,8 @@ func TriggerPendingSpawns(townRoot string, timeout time.Duration) ([]TriggerResu
 		}
 
 		// Check if runtime is ready (non-blocking poll)
-		rigPath := filepath.Join(townRoot, ps.Rig)
-		runtimeConfig := config.LoadRuntimeConfig(rigPath)
-		err = t.WaitForRuntimeReady(ps.Session, runtimeConfig, timeout)
+		adapter := runtime.ForRole("polecat", townRoot, filepath.Join(townRoot, ps.Rig), "")
+		err = runtime.WaitReady(t, adapter, ps.Session, timeout)
 		if err != nil {
 			// Not ready yet - leave mail in inbox for next poll
 			continue
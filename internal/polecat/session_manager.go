@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/connection"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/runtime"
@@ -35,8 +36,9 @@ var (
 
 // SessionManager handles polecat session lifecycle.
 type SessionManager struct {
-	tmux *tmux.Tmux
-	rig  *rig.Rig
+	tmux   *tmux.Tmux
+	rig    *rig.Rig
+	remote *connection.Remote // Set for rigs hosted on another machine
 }
 
 // NewSessionManager creates a new polecat session manager for a rig.
@@ -47,6 +49,45 @@ func NewSessionManager(t *tmux.Tmux, r *rig.Rig) *SessionManager {
 	}
 }
 
+// NewRemoteSessionManager creates a session manager for a rig hosted on
+// another machine. Session queries go through the connection's tmux
+// methods; start and stop run "gt session" in the remote town so the
+// remote side does its own environment and beads setup.
+func NewRemoteSessionManager(remote *connection.Remote, r *rig.Rig) *SessionManager {
+	return &SessionManager{
+		rig:    r,
+		remote: remote,
+	}
+}
+
+// hasSession checks whether a tmux session exists on the rig's machine.
+func (m *SessionManager) hasSession(sessionID string) (bool, error) {
+	if m.remote != nil {
+		return m.remote.TmuxHasSession(sessionID)
+	}
+	return m.tmux.HasSession(sessionID)
+}
+
+// capturePane captures pane output on the rig's machine.
+func (m *SessionManager) capturePane(sessionID string, lines int) (string, error) {
+	if m.remote != nil {
+		return m.remote.TmuxCapturePane(sessionID, lines)
+	}
+	return m.tmux.CapturePane(sessionID, lines)
+}
+
+// remoteGT runs a gt command in the rig's remote town.
+func (m *SessionManager) remoteGT(args ...string) error {
+	out, err := m.remote.GT(args...)
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %s", m.remote.Name(), msg)
+		}
+		return fmt.Errorf("%s: %w", m.remote.Name(), err)
+	}
+	return nil
+}
+
 // SessionStartOptions configures polecat session startup.
 type SessionStartOptions struct {
 	// WorkDir overrides the default working directory (polecat clone dir).
@@ -140,6 +181,14 @@ func (m *SessionManager) hasPolecat(polecat string) bool {
 
 // Start creates and starts a new session for a polecat.
 func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
+	if m.remote != nil {
+		args := []string{"session", "start", m.rig.Name + "/" + polecat}
+		if opts.Issue != "" {
+			args = append(args, "--issue", opts.Issue)
+		}
+		return m.remoteGT(args...)
+	}
+
 	if !m.hasPolecat(polecat) {
 		return fmt.Errorf("%w: %s", ErrPolecatNotFound, polecat)
 	}
@@ -176,7 +225,9 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	// Ensure runtime settings exist in polecats/ (not polecats/<name>/) so we don't
 	// write into the source repo. Runtime walks up the tree to find settings.
 	polecatsDir := filepath.Join(m.rig.Path, "polecats")
-	if err := runtime.EnsureSettingsForRole(polecatsDir, "polecat", runtimeConfig); err != nil {
+	townRoot := filepath.Dir(m.rig.Path)
+	adapter := runtime.ForRole("polecat", townRoot, m.rig.Path, "")
+	if err := adapter.InstallHooks(polecatsDir, "polecat", runtimeConfig); err != nil {
 		return fmt.Errorf("ensuring runtime settings: %w", err)
 	}
 
@@ -198,7 +249,6 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 
 	// Set environment (non-fatal: session works without these)
 	// Use centralized AgentEnv for consistency across all role startup paths
-	townRoot := filepath.Dir(m.rig.Path)
 	envVars := config.AgentEnv(config.AgentEnvConfig{
 		Role:             "polecat",
 		Rig:              m.rig.Name,
@@ -230,12 +280,12 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	// Wait for Claude to start (non-fatal)
 	debugSession("WaitForCommand", m.tmux.WaitForCommand(sessionID, constants.SupportedShells, constants.ClaudeStartTimeout))
 
-	// Accept bypass permissions warning dialog if it appears
-	debugSession("AcceptBypassPermissionsWarning", m.tmux.AcceptBypassPermissionsWarning(sessionID))
+	// Accept startup dialogs (e.g. the bypass permissions warning) if they appear
+	debugSession("DismissDialogs", runtime.DismissDialogs(m.tmux, adapter, sessionID))
 
 	// Wait for runtime to be fully ready at the prompt (not just started)
 	runtime.SleepForReadyDelay(runtimeConfig)
-	_ = runtime.RunStartupFallback(m.tmux, sessionID, "polecat", runtimeConfig)
+	_ = runtime.RunStartupFallback(m.tmux, adapter, sessionID, "polecat", runtimeConfig)
 
 	// Inject startup nudge for predecessor discovery via /resume
 	address := fmt.Sprintf("%s/polecats/%s", m.rig.Name, polecat)
@@ -247,11 +297,11 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	}))
 
 	// GUPP: Send propulsion nudge to trigger autonomous work execution
-	// Wait for Claude UI to be ready before sending propulsion nudge.
-	if err := m.tmux.WaitForClaudeUI(sessionID, 30*time.Second); err != nil {
-		return fmt.Errorf("waiting for Claude UI: %w", err)
+	// Wait for the agent to be ready before sending propulsion nudge.
+	if err := runtime.WaitReady(m.tmux, adapter, sessionID, 30*time.Second); err != nil {
+		return fmt.Errorf("waiting for agent UI: %w", err)
 	}
-	debugSession("NudgeSession PropulsionNudge", m.tmux.NudgeSession(sessionID, session.PropulsionNudge()))
+	debugSession("InjectPrompt PropulsionNudge", adapter.InjectPrompt(m.tmux, sessionID, session.PropulsionNudge()))
 
 	// Verify session survived startup - if the command crashed, the session may have died.
 	// Without this check, Start() would return success even if the pane died during initialization.
@@ -270,7 +320,7 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 func (m *SessionManager) Stop(polecat string, force bool) error {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.hasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -278,6 +328,14 @@ func (m *SessionManager) Stop(polecat string, force bool) error {
 		return ErrSessionNotFound
 	}
 
+	if m.remote != nil {
+		args := []string{"session", "stop", m.rig.Name + "/" + polecat}
+		if force {
+			args = append(args, "--force")
+		}
+		return m.remoteGT(args...)
+	}
+
 	// Sync beads before shutdown (non-fatal)
 	if !force {
 		polecatDir := m.polecatDir(polecat)
@@ -309,14 +367,14 @@ func (m *SessionManager) syncBeads(workDir string) error {
 // IsRunning checks if a polecat session is active.
 func (m *SessionManager) IsRunning(polecat string) (bool, error) {
 	sessionID := m.SessionName(polecat)
-	return m.tmux.HasSession(sessionID)
+	return m.hasSession(sessionID)
 }
 
 // Status returns detailed status for a polecat session.
 func (m *SessionManager) Status(polecat string) (*SessionInfo, error) {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.hasSession(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("checking session: %w", err)
 	}
@@ -328,7 +386,8 @@ func (m *SessionManager) Status(polecat string) (*SessionInfo, error) {
 		RigName:   m.rig.Name,
 	}
 
-	if !running {
+	// Detailed tmux info is only available for local sessions
+	if !running || m.remote != nil {
 		return info, nil
 	}
 
@@ -367,7 +426,13 @@ func (m *SessionManager) Status(polecat string) (*SessionInfo, error) {
 
 // List returns information about all polecat sessions for this rig.
 func (m *SessionManager) List() ([]SessionInfo, error) {
-	sessions, err := m.tmux.ListSessions()
+	var sessions []string
+	var err error
+	if m.remote != nil {
+		sessions, err = m.remote.TmuxListSessions()
+	} else {
+		sessions, err = m.tmux.ListSessions()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -396,6 +461,10 @@ func (m *SessionManager) List() ([]SessionInfo, error) {
 func (m *SessionManager) Attach(polecat string) error {
 	sessionID := m.SessionName(polecat)
 
+	if m.remote != nil {
+		return fmt.Errorf("cannot attach to %s on machine %s; ssh there and run: tmux attach -t %s", sessionID, m.remote.Name(), sessionID)
+	}
+
 	running, err := m.tmux.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
@@ -411,7 +480,7 @@ func (m *SessionManager) Attach(polecat string) error {
 func (m *SessionManager) Capture(polecat string, lines int) (string, error) {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.hasSession(sessionID)
 	if err != nil {
 		return "", fmt.Errorf("checking session: %w", err)
 	}
@@ -419,12 +488,12 @@ func (m *SessionManager) Capture(polecat string, lines int) (string, error) {
 		return "", ErrSessionNotFound
 	}
 
-	return m.tmux.CapturePane(sessionID, lines)
+	return m.capturePane(sessionID, lines)
 }
 
 // CaptureSession returns the recent output from a session by raw session ID.
 func (m *SessionManager) CaptureSession(sessionID string, lines int) (string, error) {
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.hasSession(sessionID)
 	if err != nil {
 		return "", fmt.Errorf("checking session: %w", err)
 	}
@@ -432,14 +501,14 @@ func (m *SessionManager) CaptureSession(sessionID string, lines int) (string, er
 		return "", ErrSessionNotFound
 	}
 
-	return m.tmux.CapturePane(sessionID, lines)
+	return m.capturePane(sessionID, lines)
 }
 
 // Inject sends a message to a polecat session.
 func (m *SessionManager) Inject(polecat, message string) error {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.hasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -447,6 +516,10 @@ func (m *SessionManager) Inject(polecat, message string) error {
 		return ErrSessionNotFound
 	}
 
+	if m.remote != nil {
+		return m.remote.TmuxSendKeys(sessionID, message)
+	}
+
 	debounceMs := 200 + (len(message)/1024)*100
 	if debounceMs > 1500 {
 		debounceMs = 1500
@@ -1,11 +1,15 @@
 package polecat
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/steveyegge/gastown/internal/connection"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
@@ -190,3 +194,43 @@ func TestPolecatCommandFormat(t *testing.T) {
 		t.Error("GT_ROLE must be 'polecat', not 'mayor' or 'crew'")
 	}
 }
+
+func TestRemoteSessionManager_QueriesThroughConnection(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+
+	// A LocalConnection stands in for the remote machine: the manager must
+	// only touch sessions through the Connection interface.
+	remote := &connection.Remote{Connection: connection.NewLocalConnection(), TownPath: t.TempDir()}
+	rigName := fmt.Sprintf("remote%d", time.Now().UnixNano())
+	r := &rig.Rig{Name: rigName, Path: remote.RigPath(rigName), Machine: "buildbox"}
+	m := NewRemoteSessionManager(remote, r)
+
+	if running, err := m.IsRunning("Toast"); err != nil || running {
+		t.Fatalf("IsRunning before start = %v, %v", running, err)
+	}
+	if _, err := m.Capture("Toast", 10); err != ErrSessionNotFound {
+		t.Errorf("Capture = %v, want ErrSessionNotFound", err)
+	}
+
+	sessionID := m.SessionName("Toast")
+	if err := remote.TmuxNewSession(sessionID, ""); err != nil {
+		t.Fatalf("TmuxNewSession: %v", err)
+	}
+	defer func() { _ = remote.TmuxKillSession(sessionID) }()
+
+	if running, err := m.IsRunning("Toast"); err != nil || !running {
+		t.Errorf("IsRunning after start = %v, %v", running, err)
+	}
+	infos, err := m.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Polecat != "Toast" {
+		t.Errorf("List = %+v, want [Toast]", infos)
+	}
+	if err := m.Attach("Toast"); err == nil {
+		t.Error("Attach should refuse remote sessions")
+	}
+}
@@ -0,0 +1,76 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol/wire"
+)
+
+// EnvelopeVersion is the payload version this build sends.
+const EnvelopeVersion = wire.Version
+
+// Envelope is the typed, versioned form of a protocol message. See the
+// wire package for its format.
+type Envelope = wire.Envelope
+
+var (
+	// ErrNoEnvelope is returned by ParseEnvelope for a legacy message.
+	ErrNoEnvelope = wire.ErrNoEnvelope
+
+	// ErrUnparseable marks a protocol message that can't be understood; the
+	// handler registry dead-letters it.
+	ErrUnparseable = wire.ErrUnparseable
+)
+
+// ParseEnvelope extracts the envelope from a message body.
+func ParseEnvelope(body string) (*Envelope, error) {
+	return wire.Parse(body)
+}
+
+// Open returns the envelope of a protocol message. A legacy message gets a
+// version 0 envelope, with its type from the subject and the mail ID as its
+// ID; its handlers parse the body themselves. Only a malformed envelope is
+// ErrUnparseable: a message that isn't a protocol message at all is just
+// an error.
+func Open(msg *mail.Message) (*Envelope, error) {
+	subjectType := ParseMessageType(msg.Subject)
+	env, err := wire.Parse(msg.Body)
+	if errors.Is(err, wire.ErrNoEnvelope) {
+		if subjectType == "" {
+			return nil, fmt.Errorf("unknown message type for subject: %s", msg.Subject)
+		}
+		return &Envelope{Type: string(subjectType), ID: msg.ID, SentAt: msg.Timestamp}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subjectType != "" && string(subjectType) != env.Type {
+		return nil, fmt.Errorf("%w: subject says %s but envelope says %s", ErrUnparseable, subjectType, env.Type)
+	}
+	return env, nil
+}
+
+// seal appends an envelope carrying payload to a message built by one of
+// the New*Message constructors. The envelope takes the message's ID.
+func seal(msg *mail.Message, msgType MessageType, correlationID string, payload any) *mail.Message {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		// Payloads are plain structs; leave the message as a legacy one
+		return msg
+	}
+	body, err := wire.Append(msg.Body, &Envelope{
+		Type:          string(msgType),
+		Version:       EnvelopeVersion,
+		ID:            msg.ID,
+		CorrelationID: correlationID,
+		SentAt:        msg.Timestamp,
+		Payload:       data,
+	})
+	if err == nil {
+		msg.Body = body
+	}
+	return msg
+}
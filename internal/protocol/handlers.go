@@ -1,56 +1,155 @@
 package protocol
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/steveyegge/gastown/internal/mail"
 )
 
-// Handler processes a protocol message and returns an error if processing failed.
+// ErrDeadLettered is wrapped by the errors of messages Handle moved to the
+// dead-letter queue.
+var ErrDeadLettered = errors.New("dead-lettered")
+
+// Handler processes a legacy protocol message (one without an envelope)
+// and returns an error if processing failed.
 type Handler func(msg *mail.Message) error
 
-// HandlerRegistry maps message types to their handlers.
+// EnvelopeHandler processes a protocol message of one payload version.
+// Returning an ErrUnparseable error (e.g. from Envelope.Decode) sends the
+// message to the dead-letter queue.
+type EnvelopeHandler func(env *Envelope, msg *mail.Message) error
+
+// handlerKey identifies the handler for a message type at a payload version.
+type handlerKey struct {
+	msgType MessageType
+	version int
+}
+
+// HandlerRegistry maps message types and payload versions to their
+// handlers. With a ledger it skips messages it has already handled; with a
+// dead-letter queue it keeps the messages it can't parse or has no handler
+// for.
 type HandlerRegistry struct {
-	handlers map[MessageType]Handler
+	handlers    map[handlerKey]EnvelopeHandler
+	ledger      *Ledger
+	deadLetters *DeadLetterQueue
 }
 
 // NewHandlerRegistry creates a new handler registry.
 func NewHandlerRegistry() *HandlerRegistry {
 	return &HandlerRegistry{
-		handlers: make(map[MessageType]Handler),
+		handlers: make(map[handlerKey]EnvelopeHandler),
 	}
 }
 
-// Register adds a handler for a specific message type.
+// Register adds a handler for legacy messages of a type, which carry no
+// envelope (version 0).
 func (r *HandlerRegistry) Register(msgType MessageType, handler Handler) {
-	r.handlers[msgType] = handler
+	r.RegisterVersion(msgType, 0, func(_ *Envelope, msg *mail.Message) error {
+		return handler(msg)
+	})
 }
 
-// Handle dispatches a message to the appropriate handler.
-// Returns an error if no handler is registered for the message type.
+// RegisterVersion adds a handler for a message type at a payload version.
+func (r *HandlerRegistry) RegisterVersion(msgType MessageType, version int, handler EnvelopeHandler) {
+	r.handlers[handlerKey{msgType, version}] = handler
+}
+
+// SetLedger makes the registry record the messages it handles in l and
+// skip those already recorded.
+func (r *HandlerRegistry) SetLedger(l *Ledger) {
+	r.ledger = l
+}
+
+// SetDeadLetterQueue makes the registry keep the messages it can't handle
+// in q.
+func (r *HandlerRegistry) SetDeadLetterQueue(q *DeadLetterQueue) {
+	r.deadLetters = q
+}
+
+// UseRigState gives the registry an agent's ledger and the rig's
+// dead-letter queue, under the rig's protocol state dir.
+func (r *HandlerRegistry) UseRigState(rigPath, agent string) {
+	r.SetLedger(NewLedger(LedgerPath(rigPath, agent)))
+	r.SetDeadLetterQueue(NewDeadLetterQueue(DeadLetterPath(rigPath)))
+}
+
+// Handle dispatches a message to the handler for its type and version.
+// A message already in the ledger is skipped. A message that can't be
+// parsed, or has a version no handler is registered for, goes to the
+// dead-letter queue and its error is returned. Handler errors are returned
+// without recording the message, so it can be retried.
 func (r *HandlerRegistry) Handle(msg *mail.Message) error {
-	msgType := ParseMessageType(msg.Subject)
-	if msgType == "" {
-		return fmt.Errorf("unknown message type for subject: %s", msg.Subject)
+	env, err := Open(msg)
+	if errors.Is(err, ErrUnparseable) {
+		return r.deadLetter(msg, err)
+	} else if err != nil {
+		return err
 	}
 
-	handler, ok := r.handlers[msgType]
+	if r.ledger != nil && env.ID != "" && r.ledger.Seen(env.ID) {
+		return nil
+	}
+
+	msgType := MessageType(env.Type)
+	handler, ok := r.handlers[handlerKey{msgType, env.Version}]
 	if !ok {
-		return fmt.Errorf("no handler registered for message type: %s", msgType)
+		if !r.handlesType(msgType) {
+			return fmt.Errorf("no handler registered for message type: %s", env.Type)
+		}
+		return r.deadLetter(msg, fmt.Errorf("no handler registered for %s version %d", env.Type, env.Version))
+	}
+
+	if err := handler(env, msg); err != nil {
+		if errors.Is(err, ErrUnparseable) {
+			return r.deadLetter(msg, err)
+		}
+		return err
 	}
 
-	return handler(msg)
+	if r.ledger != nil && env.ID != "" {
+		if err := r.ledger.Mark(env.ID); err != nil {
+			return fmt.Errorf("recording handled message %s: %w", env.ID, err)
+		}
+	}
+	return nil
+}
+
+// deadLetter queues a message that can't be handled and returns why.
+func (r *HandlerRegistry) deadLetter(msg *mail.Message, reason error) error {
+	if r.deadLetters == nil {
+		return reason
+	}
+	if err := r.deadLetters.Add(msg, reason); err != nil {
+		return fmt.Errorf("%w (dead-lettering failed: %v)", reason, err)
+	}
+	return fmt.Errorf("%w: %w", ErrDeadLettered, reason)
+}
+
+// handlesType reports whether a handler is registered for any version of
+// a message type.
+func (r *HandlerRegistry) handlesType(msgType MessageType) bool {
+	for key := range r.handlers {
+		if key.msgType == msgType {
+			return true
+		}
+	}
+	return false
 }
 
 // CanHandle returns true if a handler is registered for the message's type.
+// Any version counts: a message of an unknown version is still this
+// registry's to dead-letter.
 func (r *HandlerRegistry) CanHandle(msg *mail.Message) bool {
 	msgType := ParseMessageType(msg.Subject)
+	if env, err := ParseEnvelope(msg.Body); err == nil {
+		msgType = MessageType(env.Type)
+	}
 	if msgType == "" {
 		return false
 	}
-
-	_, ok := r.handlers[msgType]
-	return ok
+	return r.handlesType(msgType)
 }
 
 // WitnessHandler defines the interface for Witness protocol handlers.
@@ -73,7 +172,8 @@ type RefineryHandler interface {
 	HandleMergeReady(payload *MergeReadyPayload) error
 }
 
-// WrapWitnessHandlers creates mail handlers from a WitnessHandler.
+// WrapWitnessHandlers creates mail handlers from a WitnessHandler. Both
+// legacy and current payload versions are handled.
 func WrapWitnessHandlers(h WitnessHandler) *HandlerRegistry {
 	registry := NewHandlerRegistry()
 
@@ -81,21 +181,43 @@ func WrapWitnessHandlers(h WitnessHandler) *HandlerRegistry {
 		payload := ParseMergedPayload(msg.Body)
 		return h.HandleMerged(payload)
 	})
+	registry.RegisterVersion(TypeMerged, 1, func(env *Envelope, _ *mail.Message) error {
+		payload := &MergedPayload{}
+		if err := env.Decode(payload); err != nil {
+			return err
+		}
+		return h.HandleMerged(payload)
+	})
 
 	registry.Register(TypeMergeFailed, func(msg *mail.Message) error {
 		payload := ParseMergeFailedPayload(msg.Body)
 		return h.HandleMergeFailed(payload)
 	})
+	registry.RegisterVersion(TypeMergeFailed, 1, func(env *Envelope, _ *mail.Message) error {
+		payload := &MergeFailedPayload{}
+		if err := env.Decode(payload); err != nil {
+			return err
+		}
+		return h.HandleMergeFailed(payload)
+	})
 
 	registry.Register(TypeReworkRequest, func(msg *mail.Message) error {
 		payload := ParseReworkRequestPayload(msg.Body)
 		return h.HandleReworkRequest(payload)
 	})
+	registry.RegisterVersion(TypeReworkRequest, 1, func(env *Envelope, _ *mail.Message) error {
+		payload := &ReworkRequestPayload{}
+		if err := env.Decode(payload); err != nil {
+			return err
+		}
+		return h.HandleReworkRequest(payload)
+	})
 
 	return registry
 }
 
-// WrapRefineryHandlers creates mail handlers from a RefineryHandler.
+// WrapRefineryHandlers creates mail handlers from a RefineryHandler. Both
+// legacy and current payload versions are handled.
 func WrapRefineryHandlers(h RefineryHandler) *HandlerRegistry {
 	registry := NewHandlerRegistry()
 
@@ -103,6 +225,13 @@ func WrapRefineryHandlers(h RefineryHandler) *HandlerRegistry {
 		payload := ParseMergeReadyPayload(msg.Body)
 		return h.HandleMergeReady(payload)
 	})
+	registry.RegisterVersion(TypeMergeReady, 1, func(env *Envelope, _ *mail.Message) error {
+		payload := &MergeReadyPayload{}
+		if err := env.Decode(payload); err != nil {
+			return err
+		}
+		return h.HandleMergeReady(payload)
+	})
 
 	return registry
 }
@@ -0,0 +1,76 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/witness"
+)
+
+// NewWitnessRegistry returns the registry a rig's witness handles its
+// protocol mail with: POLECAT_DONE from polecats and the merge outcomes
+// from the refinery, recorded in the witness's ledger and dead-lettered to
+// the rig's queue.
+func NewWitnessRegistry(rigName, rigPath string) *HandlerRegistry {
+	registry := WrapWitnessHandlers(NewWitnessHandler(rigName, rigPath))
+
+	// The witness package parses both versions of POLECAT_DONE itself
+	polecatDone := func(_ *Envelope, msg *mail.Message) error {
+		result := witness.HandlePolecatDone(rigPath, rigName, msg)
+		if !result.Handled {
+			return result.Error
+		}
+		return nil
+	}
+	registry.RegisterVersion(TypePolecatDone, 0, polecatDone)
+	registry.RegisterVersion(TypePolecatDone, 1, polecatDone)
+
+	registry.UseRigState(rigPath, "witness")
+	return registry
+}
+
+// NewRefineryRegistry returns the registry a rig's refinery handles its
+// protocol mail (MERGE_READY) with.
+func NewRefineryRegistry(rigName, rigPath string) *HandlerRegistry {
+	registry := WrapRefineryHandlers(NewRefineryHandler(rigName, rigPath))
+	registry.UseRigState(rigPath, "refinery")
+	return registry
+}
+
+// InboxResult is the outcome of one message in ProcessInbox.
+type InboxResult struct {
+	Message *mail.Message
+
+	// Err is nil when the message was handled or had been already. It
+	// wraps ErrDeadLettered when the message was moved to the dead-letter
+	// queue; other errors leave the message in the inbox for a retry.
+	Err error
+}
+
+// ProcessInbox runs the protocol messages in a mailbox that the registry
+// has handlers for through Handle. Dead-lettered messages are archived, and
+// so are handled ones if archiveHandled is set; otherwise the ledger keeps
+// them from being handled again. The rest of the inbox is left for the
+// agent to read.
+func (r *HandlerRegistry) ProcessInbox(mb *mail.Mailbox, archiveHandled bool) ([]InboxResult, error) {
+	messages, err := mb.List()
+	if err != nil {
+		return nil, fmt.Errorf("listing messages: %w", err)
+	}
+
+	var results []InboxResult
+	for _, msg := range messages {
+		if !IsProtocolMessage(msg.Subject) || !r.CanHandle(msg) {
+			continue
+		}
+		err := r.Handle(msg)
+		if (err == nil && archiveHandled) || errors.Is(err, ErrDeadLettered) {
+			if archiveErr := mb.Archive(msg.ID); archiveErr != nil {
+				err = errors.Join(err, fmt.Errorf("archiving %s: %w", msg.ID, archiveErr))
+			}
+		}
+		results = append(results, InboxResult{Message: msg, Err: err})
+	}
+	return results, nil
+}
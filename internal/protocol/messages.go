@@ -8,6 +8,35 @@ import (
 	"github.com/steveyegge/gastown/internal/mail"
 )
 
+// NewPolecatDoneMessage creates a POLECAT_DONE protocol message.
+// Sent by a polecat to its Witness when it finishes (gt done).
+func NewPolecatDoneMessage(from string, p PolecatDonePayload) *mail.Message {
+	msg := mail.NewMessage(
+		from,
+		fmt.Sprintf("%s/witness", p.Rig),
+		fmt.Sprintf("POLECAT_DONE %s", p.Polecat),
+		formatPolecatDoneBody(p),
+	)
+	return seal(msg, TypePolecatDone, p.Branch, p)
+}
+
+// formatPolecatDoneBody formats the body of a POLECAT_DONE message.
+func formatPolecatDoneBody(p PolecatDonePayload) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Exit: %s\n", p.Exit))
+	if p.Issue != "" {
+		sb.WriteString(fmt.Sprintf("Issue: %s\n", p.Issue))
+	}
+	if p.MR != "" {
+		sb.WriteString(fmt.Sprintf("MR: %s\n", p.MR))
+	}
+	if p.Gate != "" {
+		sb.WriteString(fmt.Sprintf("Gate: %s\n", p.Gate))
+	}
+	sb.WriteString(fmt.Sprintf("Branch: %s\n", p.Branch))
+	return sb.String()
+}
+
 // NewMergeReadyMessage creates a MERGE_READY protocol message.
 // Sent by Witness to Refinery when a polecat's work is verified and ready.
 func NewMergeReadyMessage(rig, polecat, branch, issue string) *mail.Message {
@@ -31,7 +60,7 @@ func NewMergeReadyMessage(rig, polecat, branch, issue string) *mail.Message {
 	msg.Priority = mail.PriorityHigh
 	msg.Type = mail.TypeTask
 
-	return msg
+	return seal(msg, TypeMergeReady, branch, payload)
 }
 
 // formatMergeReadyBody formats the body of a MERGE_READY message.
@@ -71,7 +100,7 @@ func NewMergedMessage(rig, polecat, branch, issue, targetBranch, mergeCommit str
 	msg.Priority = mail.PriorityHigh
 	msg.Type = mail.TypeNotification
 
-	return msg
+	return seal(msg, TypeMerged, branch, payload)
 }
 
 // formatMergedBody formats the body of a MERGED message.
@@ -92,7 +121,7 @@ func formatMergedBody(p MergedPayload) string {
 // NewMergeFailedMessage creates a MERGE_FAILED protocol message.
 // Sent by Refinery to Witness when merge fails (tests, build, etc.).
 func NewMergeFailedMessage(rig, polecat, branch, issue, targetBranch, failureType, errorMsg string) *mail.Message {
-	payload := MergeFailedPayload{
+	return NewMergeFailedMessageFromPayload(MergeFailedPayload{
 		Branch:       branch,
 		Issue:        issue,
 		Polecat:      polecat,
@@ -101,23 +130,32 @@ func NewMergeFailedMessage(rig, polecat, branch, issue, targetBranch, failureTyp
 		FailureType:  failureType,
 		Error:        errorMsg,
 		TargetBranch: targetBranch,
-	}
+	})
+}
 
-	body := formatMergeFailedBody(payload)
+// NewMergeFailedMessageFromPayload creates a MERGE_FAILED protocol message
+// from a full payload, including the MR and failure excerpt.
+func NewMergeFailedMessageFromPayload(p MergeFailedPayload) *mail.Message {
+	if p.FailedAt.IsZero() {
+		p.FailedAt = time.Now()
+	}
+	body := formatMergeFailedBody(p)
 
 	msg := mail.NewMessage(
-		fmt.Sprintf("%s/refinery", rig),
-		fmt.Sprintf("%s/witness", rig),
-		fmt.Sprintf("MERGE_FAILED %s", polecat),
+		fmt.Sprintf("%s/refinery", p.Rig),
+		fmt.Sprintf("%s/witness", p.Rig),
+		fmt.Sprintf("MERGE_FAILED %s", p.Polecat),
 		body,
 	)
 	msg.Priority = mail.PriorityHigh
 	msg.Type = mail.TypeTask
 
-	return msg
+	return seal(msg, TypeMergeFailed, p.Branch, p)
 }
 
-// formatMergeFailedBody formats the body of a MERGE_FAILED message.
+// formatMergeFailedBody formats the body of a MERGE_FAILED message. The
+// excerpt goes last, one indented line per output line, so its contents
+// can't be mistaken for the fields above it.
 func formatMergeFailedBody(p MergeFailedPayload) string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("Branch: %s\n", p.Branch))
@@ -128,6 +166,15 @@ func formatMergeFailedBody(p MergeFailedPayload) string {
 	sb.WriteString(fmt.Sprintf("Failed-At: %s\n", p.FailedAt.Format(time.RFC3339)))
 	sb.WriteString(fmt.Sprintf("Failure-Type: %s\n", p.FailureType))
 	sb.WriteString(fmt.Sprintf("Error: %s\n", p.Error))
+	if p.MR != "" {
+		sb.WriteString(fmt.Sprintf("MR: %s\n", p.MR))
+	}
+	if len(p.Excerpt) > 0 {
+		sb.WriteString("Excerpt:\n")
+		for _, line := range p.Excerpt {
+			sb.WriteString("  " + line + "\n")
+		}
+	}
 	return sb.String()
 }
 
@@ -156,7 +203,7 @@ func NewReworkRequestMessage(rig, polecat, branch, issue, targetBranch string, c
 	msg.Priority = mail.PriorityHigh
 	msg.Type = mail.TypeTask
 
-	return msg
+	return seal(msg, TypeReworkRequest, branch, payload)
 }
 
 // formatReworkRequestBody formats the body of a REWORK_REQUEST message.
@@ -226,18 +273,22 @@ func ParseMergedPayload(body string) *MergedPayload {
 
 // ParseMergeFailedPayload parses a MERGE_FAILED message body into a payload.
 func ParseMergeFailedPayload(body string) *MergeFailedPayload {
+	// Fields come before the excerpt, whose lines could look like fields
+	fields, _, _ := strings.Cut(body, "\nExcerpt:\n")
 	payload := &MergeFailedPayload{
-		Branch:       parseField(body, "Branch"),
-		Issue:        parseField(body, "Issue"),
-		Polecat:      parseField(body, "Polecat"),
-		Rig:          parseField(body, "Rig"),
-		TargetBranch: parseField(body, "Target"),
-		FailureType:  parseField(body, "Failure-Type"),
-		Error:        parseField(body, "Error"),
+		Branch:       parseField(fields, "Branch"),
+		Issue:        parseField(fields, "Issue"),
+		Polecat:      parseField(fields, "Polecat"),
+		Rig:          parseField(fields, "Rig"),
+		TargetBranch: parseField(fields, "Target"),
+		FailureType:  parseField(fields, "Failure-Type"),
+		Error:        parseField(fields, "Error"),
+		MR:           parseField(fields, "MR"),
+		Excerpt:      parseExcerpt(body),
 	}
 
 	// Parse timestamp
-	if ts := parseField(body, "Failed-At"); ts != "" {
+	if ts := parseField(fields, "Failed-At"); ts != "" {
 		if t, err := time.Parse(time.RFC3339, ts); err == nil {
 			payload.FailedAt = t
 		}
@@ -286,3 +337,20 @@ func parseField(body, key string) string {
 
 	return ""
 }
+
+// parseExcerpt extracts the indented lines following "Excerpt:".
+func parseExcerpt(body string) []string {
+	_, after, ok := strings.Cut(body, "\nExcerpt:\n")
+	if !ok {
+		return nil
+	}
+	var excerpt []string
+	for _, line := range strings.Split(after, "\n") {
+		text, ok := strings.CutPrefix(line, "  ")
+		if !ok {
+			break
+		}
+		excerpt = append(excerpt, text)
+	}
+	return excerpt
+}
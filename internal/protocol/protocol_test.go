@@ -2,11 +2,18 @@ package protocol
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol/wire"
+	"github.com/steveyegge/gastown/internal/witness"
 )
 
 func TestParseMessageType(t *testing.T) {
@@ -197,6 +204,37 @@ Merge-Commit: abc123`
 	}
 }
 
+func TestMergeFailedPayloadExcerpt(t *testing.T) {
+	msg := NewMergeFailedMessageFromPayload(MergeFailedPayload{
+		Branch:       "polecat/nux/gt-abc",
+		Issue:        "gt-abc",
+		Polecat:      "nux",
+		Rig:          "gastown",
+		TargetBranch: "main",
+		FailureType:  "tests",
+		Error:        "check unit failed: exit status 1",
+		MR:           "gt-mr1",
+		Excerpt:      []string{"[unit] parse_test.go:42: got 1, want 2", "[unit] Error: boom"},
+	})
+	if msg.Subject != "MERGE_FAILED nux" || msg.To != "gastown/witness" {
+		t.Errorf("message = %s to %s", msg.Subject, msg.To)
+	}
+
+	payload := ParseMergeFailedPayload(msg.Body)
+	if payload.MR != "gt-mr1" || payload.Error != "check unit failed: exit status 1" || payload.FailedAt.IsZero() {
+		t.Errorf("payload = %+v", payload)
+	}
+	if len(payload.Excerpt) != 2 || payload.Excerpt[1] != "[unit] Error: boom" {
+		t.Errorf("Excerpt = %q", payload.Excerpt)
+	}
+
+	// Messages without an excerpt still parse
+	payload = ParseMergeFailedPayload(NewMergeFailedMessage("gastown", "nux", "b", "gt-abc", "main", "build", "boom").Body)
+	if payload.MR != "" || payload.Excerpt != nil || payload.Error != "boom" {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
 func TestHandlerRegistry(t *testing.T) {
 	registry := NewHandlerRegistry()
 
@@ -285,6 +323,225 @@ func TestWrapRefineryHandlers(t *testing.T) {
 	}
 }
 
+func TestEnvelope(t *testing.T) {
+	msg := NewMergeFailedMessageFromPayload(MergeFailedPayload{
+		Branch:      "polecat/nux",
+		Issue:       "gt-abc",
+		Polecat:     "nux",
+		Rig:         "gastown",
+		FailureType: "tests",
+		Error:       "unit failed",
+		Excerpt:     []string{"[unit] FAIL: TestX"},
+	})
+
+	env, err := Open(msg)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if env.Type != string(TypeMergeFailed) || env.Version != EnvelopeVersion || env.ID != msg.ID || env.CorrelationID != "polecat/nux" {
+		t.Errorf("envelope = %+v", env)
+	}
+	var payload MergeFailedPayload
+	if err := env.Decode(&payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.FailureType != "tests" || len(payload.Excerpt) != 1 {
+		t.Errorf("payload = %+v", payload)
+	}
+
+	// Agents that predate the envelope still read the fields
+	legacy := ParseMergeFailedPayload(msg.Body)
+	if legacy.FailureType != "tests" || legacy.Error != "unit failed" || len(legacy.Excerpt) != 1 {
+		t.Errorf("legacy payload = %+v", legacy)
+	}
+
+	// A legacy message is version 0 with the mail ID
+	env, err = Open(&mail.Message{ID: "hq-1", Subject: "MERGED nux", Body: "Branch: b\n"})
+	if err != nil || env.Version != 0 || env.ID != "hq-1" || env.Type != string(TypeMerged) {
+		t.Errorf("legacy envelope = %+v, %v", env, err)
+	}
+
+	for name, body := range map[string]string{
+		"bad json":     "Branch: b\n\n" + wire.Marker + "\n{not json\n",
+		"no version":   "Branch: b\n\n" + wire.Marker + "\n" + `{"type":"MERGED","id":"x"}` + "\n",
+		"missing line": "Branch: b\n\n" + wire.Marker + "\n",
+	} {
+		if _, err := ParseEnvelope(body); !errors.Is(err, ErrUnparseable) {
+			t.Errorf("%s: ParseEnvelope() error = %v, want ErrUnparseable", name, err)
+		}
+	}
+	if _, err := Open(&mail.Message{Subject: "MERGE_READY nux", Body: msg.Body}); !errors.Is(err, ErrUnparseable) {
+		t.Errorf("Open() with mismatched subject error = %v", err)
+	}
+}
+
+func TestHandlerRegistry_VersionsLedgerDeadLetters(t *testing.T) {
+	rigPath := t.TempDir()
+	handler := &mockRefineryHandler{}
+	registry := WrapRefineryHandlers(handler)
+	registry.UseRigState(rigPath, "refinery")
+	queue := NewDeadLetterQueue(DeadLetterPath(rigPath))
+
+	// A current message is handled once, however often it is delivered
+	msg := NewMergeReadyMessage("gastown", "nux", "polecat/nux", "gt-abc")
+	for i := 0; i < 2; i++ {
+		handler.readyCalled = false
+		if err := registry.Handle(msg); err != nil {
+			t.Fatalf("Handle #%d: %v", i+1, err)
+		}
+		if handler.readyCalled != (i == 0) {
+			t.Errorf("delivery #%d: handler called = %v", i+1, handler.readyCalled)
+		}
+	}
+
+	// A version no handler knows is dead-lettered, not misread
+	body, err := wire.Append("Branch: polecat/ace\n", &Envelope{Type: string(TypeMergeReady), Version: 2, ID: "msg-v2", Payload: []byte(`{}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newer := &mail.Message{ID: "hq-2", From: "gastown/witness", To: "gastown/refinery", Subject: "MERGE_READY ace", Body: body}
+	handler.readyCalled = false
+	if ok, err := registry.ProcessProtocolMessage(newer); !ok || err == nil || !strings.Contains(err.Error(), "version 2") {
+		t.Errorf("ProcessProtocolMessage(v2) = %v, %v", ok, err)
+	}
+	if handler.readyCalled {
+		t.Error("v2 message reached the v1 handler")
+	}
+
+	// So is a payload that doesn't decode
+	body, _ = wire.Append("", &Envelope{Type: string(TypeMergeReady), Version: 1, ID: "msg-bad", Payload: []byte(`"not an object"`)})
+	if err := registry.Handle(&mail.Message{ID: "hq-3", Subject: "MERGE_READY ace", Body: body}); !errors.Is(err, ErrUnparseable) {
+		t.Errorf("Handle(bad payload) error = %v", err)
+	}
+	// Redelivery doesn't queue it twice
+	_ = registry.Handle(&mail.Message{ID: "hq-3", Subject: "MERGE_READY ace", Body: body})
+
+	letters, err := queue.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 2 || letters[0].ID != "hq-2" || letters[0].To != "gastown/refinery" || letters[1].ID != "hq-3" {
+		t.Fatalf("dead letters = %+v", letters)
+	}
+	if err := queue.Remove("hq-2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Remove("hq-2"); err == nil {
+		t.Error("expected an error removing a missing dead letter")
+	}
+	if letters, _ := queue.List(); len(letters) != 1 {
+		t.Errorf("dead letters after remove = %+v", letters)
+	}
+
+	// Messages that aren't for this registry aren't dead-lettered
+	if err := registry.Handle(&mail.Message{ID: "hq-4", Subject: "MERGED nux"}); err == nil {
+		t.Error("expected an error for a type with no handler")
+	}
+	if letters, _ := queue.List(); len(letters) != 1 {
+		t.Errorf("dead letters = %+v", letters)
+	}
+}
+
+func TestMergeReady_RetriedUntilMRBeadExists(t *testing.T) {
+	rigPath := t.TempDir()
+	beadsDir := filepath.Join(rigPath, ".beads")
+	if err := os.MkdirAll(beadsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	jsonl := filepath.Join(beadsDir, "issues.jsonl")
+	if err := os.WriteFile(jsonl, []byte(`{"id":"gt-abc","title":"Work","status":"closed","priority":0}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewRefineryHandler("gastown", rigPath)
+	handler.SetOutput(io.Discard)
+	registry := WrapRefineryHandlers(handler)
+	registry.UseRigState(rigPath, "refinery")
+
+	// The mail can arrive before gt done has created the MR bead
+	msg := NewMergeReadyMessage("gastown", "nux", "polecat/nux", "gt-abc")
+	if err := registry.Handle(msg); err == nil || errors.Is(err, ErrUnparseable) {
+		t.Fatalf("Handle() before the MR bead exists = %v, want a retryable error", err)
+	}
+	if letters, _ := NewDeadLetterQueue(DeadLetterPath(rigPath)).List(); len(letters) != 0 {
+		t.Fatalf("dead letters = %+v, want none", letters)
+	}
+
+	mr := `{"id":"gt-mr1","title":"Merge","status":"open","priority":0,"labels":["gt:merge-request"],"description":"branch: polecat/nux\ntarget: main\nsource_issue: gt-abc\n"}`
+	if err := os.WriteFile(jsonl, []byte(mr+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Handle(msg); err != nil {
+		t.Errorf("Handle() once the MR bead exists = %v", err)
+	}
+}
+
+func TestHandlerRegistry_FailedHandlerRetries(t *testing.T) {
+	registry := NewHandlerRegistry()
+	registry.UseRigState(t.TempDir(), "witness")
+	calls := 0
+	registry.Register(TypeMerged, func(msg *mail.Message) error {
+		calls++
+		if calls == 1 {
+			return errors.New("beads unavailable")
+		}
+		return nil
+	})
+
+	msg := &mail.Message{ID: "hq-1", Subject: "MERGED nux"}
+	if err := registry.Handle(msg); err == nil {
+		t.Fatal("expected the handler error")
+	}
+	if err := registry.Handle(msg); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if err := registry.Handle(msg); err != nil || calls != 2 {
+		t.Errorf("after success: calls = %d, err = %v", calls, err)
+	}
+}
+
+func TestWitnessParsersReadEnvelope(t *testing.T) {
+	done := NewPolecatDoneMessage("gastown/polecats/nux", PolecatDonePayload{
+		Polecat: "nux", Rig: "gastown", Exit: "COMPLETED", Issue: "gt-abc", MR: "gt-mr1", Branch: "polecat/nux",
+	})
+	if done.To != "gastown/witness" || ParseMessageType(done.Subject) != TypePolecatDone {
+		t.Errorf("message = %+v", done)
+	}
+	p, err := witness.ParsePolecatDone(done.Subject, done.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.PolecatName != "nux" || p.Exit != "COMPLETED" || p.IssueID != "gt-abc" || p.MRID != "gt-mr1" || p.Branch != "polecat/nux" {
+		t.Errorf("POLECAT_DONE payload = %+v", p)
+	}
+
+	merged := NewMergedMessage("gastown", "nux", "polecat/nux", "gt-abc", "main", "abc123")
+	m, err := witness.ParseMerged(merged.Subject, merged.Body)
+	if err != nil || m.Branch != "polecat/nux" || m.IssueID != "gt-abc" || m.MergedAt.IsZero() || m.MergeCommit != "abc123" {
+		t.Errorf("MERGED payload = %+v, %v", m, err)
+	}
+
+	// A newer version is refused rather than misread
+	body, _ := wire.Append("Exit: COMPLETED\n", &Envelope{Type: "POLECAT_DONE", Version: 2, ID: "x", Payload: []byte(`{}`)})
+	if _, err := witness.ParsePolecatDone("POLECAT_DONE nux", body); !errors.Is(err, ErrUnparseable) {
+		t.Errorf("ParsePolecatDone(v2) error = %v", err)
+	}
+}
+
+func TestLedger_Compacts(t *testing.T) {
+	defer func(n int) { maxLedgerEntries = n }(maxLedgerEntries)
+	maxLedgerEntries = 10
+	ledger := NewLedger(filepath.Join(t.TempDir(), "processed.log"))
+	for i := 0; i <= maxLedgerEntries; i++ {
+		if err := ledger.Mark(fmt.Sprintf("msg-%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ledger.Seen("msg-0") || !ledger.Seen(fmt.Sprintf("msg-%d", maxLedgerEntries)) {
+		t.Error("ledger should drop its oldest entries and keep the newest")
+	}
+}
+
 func TestDefaultWitnessHandler(t *testing.T) {
 	tmpDir := t.TempDir()
 	handler := NewWitnessHandler("gastown", tmpDir)
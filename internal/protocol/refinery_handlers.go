@@ -79,12 +79,13 @@ func (h *DefaultRefineryHandler) HandleMergeReady(payload *MergeReadyPayload) er
 	}
 	_, _ = fmt.Fprintf(h.Output, "  Verified: %s\n", payload.Verified)
 
-	// Validate required fields
+	// Validate required fields. An invalid message is unparseable: handling
+	// it again won't help, so it goes to the dead-letter queue
 	if payload.Branch == "" {
-		return fmt.Errorf("missing branch in MERGE_READY payload")
+		return fmt.Errorf("%w: missing branch in MERGE_READY payload", ErrUnparseable)
 	}
 	if payload.Polecat == "" {
-		return fmt.Errorf("missing polecat in MERGE_READY payload")
+		return fmt.Errorf("%w: missing polecat in MERGE_READY payload", ErrUnparseable)
 	}
 	if payload.Issue == "" || strings.EqualFold(payload.Issue, "none") {
 		reason := "missing issue in MERGE_READY payload"
@@ -94,14 +95,12 @@ func (h *DefaultRefineryHandler) HandleMergeReady(payload *MergeReadyPayload) er
 		if err := h.notifyInvalidMergeReady(payload, reason, mrID); err != nil {
 			return fmt.Errorf("sending merge-ready failure notice: %w", err)
 		}
-		return fmt.Errorf("invalid MERGE_READY: %s (branch=%s)", reason, payload.Branch)
+		return fmt.Errorf("%w: invalid MERGE_READY: %s (branch=%s)", ErrUnparseable, reason, payload.Branch)
 	}
 	if mr == nil {
-		reason := "no merge-request bead found for branch"
-		if err := h.notifyInvalidMergeReady(payload, reason, mrID); err != nil {
-			return fmt.Errorf("sending merge-ready failure notice: %w", err)
-		}
-		return fmt.Errorf("invalid MERGE_READY: %s (branch=%s)", reason, payload.Branch)
+		// gt done may not have created the bead yet when the mail arrives,
+		// so this isn't a bad message: leave it to be handled again later
+		return fmt.Errorf("no merge-request bead found for branch %s yet", payload.Branch)
 	}
 
 	// The merge-request bead is created by `gt done` with gt:merge-request label.
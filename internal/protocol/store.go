@@ -0,0 +1,255 @@
+package protocol
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// maxLedgerEntries bounds a processed-message ledger. When it grows past
+// this the older half is dropped: redelivery happens within minutes, not
+// thousands of messages later. A variable so tests can lower it.
+var maxLedgerEntries = 10000
+
+// StateDir returns where a rig keeps protocol state: the agents'
+// processed-message ledgers and the dead-letter queue.
+func StateDir(rigPath string) string {
+	return filepath.Join(rigPath, constants.DirRuntime, "protocol")
+}
+
+// LedgerPath returns the processed-message ledger of a rig agent
+// ("witness" or "refinery").
+func LedgerPath(rigPath, agent string) string {
+	return filepath.Join(StateDir(rigPath), "processed-"+agent+".log")
+}
+
+// DeadLetterPath returns a rig's dead-letter queue.
+func DeadLetterPath(rigPath string) string {
+	return filepath.Join(StateDir(rigPath), "dead-letters.jsonl")
+}
+
+// Ledger records the IDs of the protocol messages an agent has handled, so
+// that a message delivered twice is only acted on once.
+type Ledger struct {
+	path string
+}
+
+// NewLedger returns the ledger stored at path.
+func NewLedger(path string) *Ledger {
+	return &Ledger{path: path}
+}
+
+// Seen reports whether a message ID has been handled.
+func (l *Ledger) Seen(id string) bool {
+	ids, err := readLines(l.path)
+	if err != nil {
+		return false
+	}
+	for _, seen := range ids {
+		if seen == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Mark records a message ID as handled.
+func (l *Ledger) Mark(id string) error {
+	return withFileLock(l.path, func() error {
+		ids, err := readLines(l.path)
+		if err != nil {
+			return err
+		}
+		if len(ids) >= maxLedgerEntries {
+			ids = append(ids[len(ids)-maxLedgerEntries/2:], id)
+			return writeFileAtomic(l.path, []byte(strings.Join(ids, "\n")+"\n"))
+		}
+		f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("opening ledger: %w", err)
+		}
+		defer f.Close()
+		_, err = fmt.Fprintln(f, id)
+		return err
+	})
+}
+
+// DeadLetter is a protocol message no handler could process.
+type DeadLetter struct {
+	ID       string    `json:"id"`
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Subject  string    `json:"subject"`
+	Body     string    `json:"body"`
+	Reason   string    `json:"reason"`
+	Received time.Time `json:"received"`
+}
+
+// Message rebuilds the mail message, for requeueing.
+func (d *DeadLetter) Message() *mail.Message {
+	msg := mail.NewMessage(d.From, d.To, d.Subject, d.Body)
+	msg.Priority = mail.PriorityHigh
+	return msg
+}
+
+// DeadLetterQueue keeps the protocol messages a rig's agents couldn't
+// parse or had no handler for, so they can be inspected and requeued
+// instead of being lost.
+type DeadLetterQueue struct {
+	path string
+}
+
+// NewDeadLetterQueue returns the queue stored at path.
+func NewDeadLetterQueue(path string) *DeadLetterQueue {
+	return &DeadLetterQueue{path: path}
+}
+
+// Add puts a message on the queue. A message already queued is not added
+// again.
+func (q *DeadLetterQueue) Add(msg *mail.Message, reason error) error {
+	return withFileLock(q.path, func() error {
+		letters, err := q.List()
+		if err != nil {
+			return err
+		}
+		for _, d := range letters {
+			if d.ID == msg.ID && d.Body == msg.Body {
+				return nil
+			}
+		}
+		data, err := json.Marshal(DeadLetter{
+			ID:       msg.ID,
+			From:     msg.From,
+			To:       msg.To,
+			Subject:  msg.Subject,
+			Body:     msg.Body,
+			Reason:   reason.Error(),
+			Received: time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+		f, err := os.OpenFile(q.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("opening dead-letter queue: %w", err)
+		}
+		defer f.Close()
+		_, err = fmt.Fprintln(f, string(data))
+		return err
+	})
+}
+
+// List returns the queued messages, oldest first.
+func (q *DeadLetterQueue) List() ([]DeadLetter, error) {
+	lines, err := readLines(q.path)
+	if err != nil {
+		return nil, err
+	}
+	var letters []DeadLetter
+	for _, line := range lines {
+		var d DeadLetter
+		if err := json.Unmarshal([]byte(line), &d); err != nil {
+			continue
+		}
+		letters = append(letters, d)
+	}
+	return letters, nil
+}
+
+// Get returns a queued message.
+func (q *DeadLetterQueue) Get(id string) (*DeadLetter, error) {
+	letters, err := q.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range letters {
+		if letters[i].ID == id {
+			return &letters[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no dead letter %s", id)
+}
+
+// Remove takes a message off the queue.
+func (q *DeadLetterQueue) Remove(id string) error {
+	return withFileLock(q.path, func() error {
+		letters, err := q.List()
+		if err != nil {
+			return err
+		}
+		var kept []string
+		removed := false
+		for i := range letters {
+			if !removed && letters[i].ID == id {
+				removed = true
+				continue
+			}
+			data, err := json.Marshal(letters[i])
+			if err != nil {
+				return err
+			}
+			kept = append(kept, string(data))
+		}
+		if !removed {
+			return fmt.Errorf("no dead letter %s", id)
+		}
+		content := ""
+		if len(kept) > 0 {
+			content = strings.Join(kept, "\n") + "\n"
+		}
+		return writeFileAtomic(q.path, []byte(content))
+	})
+}
+
+// readLines returns the non-empty lines of a file. A missing file has none.
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// withFileLock runs fn holding the lock file next to path, creating the
+// directory first.
+func withFileLock(path string, fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating protocol state dir: %w", err)
+	}
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking %s: %w", filepath.Base(path), err)
+	}
+	defer func() { _ = lock.Unlock() }()
+	return fn()
+}
+
+// writeFileAtomic replaces a file through a temporary file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -4,21 +4,35 @@
 // and provides handlers for processing these messages.
 //
 // Protocol Message Types:
+//   - POLECAT_DONE: Polecat → Witness (work finished)
 //   - MERGE_READY: Witness → Refinery (branch ready for merge)
 //   - MERGED: Refinery → Witness (merge succeeded, cleanup ok)
 //   - MERGE_FAILED: Refinery → Witness (merge failed, needs rework)
 //   - REWORK_REQUEST: Refinery → Witness (rebase needed)
+//
+// Messages carry their type in the subject and their fields as "Key: value"
+// lines in the body. Since version 1 the body also ends with an Envelope: a
+// JSON line with the type, payload version, message ID, correlation ID and
+// the typed payload. Handlers are registered per type and version, so an
+// agent receiving a version it doesn't know dead-letters the message
+// instead of misreading it.
 package protocol
 
 import (
 	"strings"
-	"time"
+
+	"github.com/steveyegge/gastown/internal/protocol/wire"
 )
 
 // MessageType identifies the protocol message type.
 type MessageType string
 
 const (
+	// TypePolecatDone is sent from a polecat to its Witness when it finishes
+	// its work, whatever the outcome.
+	// Subject format: "POLECAT_DONE <polecat-name>"
+	TypePolecatDone MessageType = "POLECAT_DONE"
+
 	// TypeMergeReady is sent from Witness to Refinery when a polecat's work
 	// is verified and ready for merge queue processing.
 	// Subject format: "MERGE_READY <polecat-name>"
@@ -47,6 +61,7 @@ func ParseMessageType(subject string) MessageType {
 
 	// Check each known prefix
 	prefixes := []MessageType{
+		TypePolecatDone,
 		TypeMergeReady,
 		TypeMerged,
 		TypeMergeFailed,
@@ -62,108 +77,15 @@ func ParseMessageType(subject string) MessageType {
 	return ""
 }
 
-// MergeReadyPayload contains the data for a MERGE_READY message.
-// Sent by Witness after verifying polecat work is complete.
-type MergeReadyPayload struct {
-	// Branch is the polecat's work branch (e.g., "polecat/Toast/gt-abc").
-	Branch string `json:"branch"`
-
-	// Issue is the beads issue ID the polecat completed.
-	Issue string `json:"issue"`
-
-	// Polecat is the worker name.
-	Polecat string `json:"polecat"`
-
-	// Rig is the rig name containing the polecat.
-	Rig string `json:"rig"`
-
-	// Verified contains verification notes.
-	Verified string `json:"verified,omitempty"`
-
-	// Timestamp is when the message was created.
-	Timestamp time.Time `json:"timestamp"`
-}
-
-// MergedPayload contains the data for a MERGED message.
-// Sent by Refinery after successful merge to target branch.
-type MergedPayload struct {
-	// Branch is the source branch that was merged.
-	Branch string `json:"branch"`
-
-	// Issue is the beads issue ID.
-	Issue string `json:"issue"`
-
-	// Polecat is the worker name.
-	Polecat string `json:"polecat"`
-
-	// Rig is the rig name.
-	Rig string `json:"rig"`
-
-	// MergedAt is when the merge completed.
-	MergedAt time.Time `json:"merged_at"`
-
-	// MergeCommit is the SHA of the merge commit.
-	MergeCommit string `json:"merge_commit,omitempty"`
-
-	// TargetBranch is the branch merged into (e.g., "main").
-	TargetBranch string `json:"target_branch"`
-}
-
-// MergeFailedPayload contains the data for a MERGE_FAILED message.
-// Sent by Refinery when merge fails due to tests, build, or other errors.
-type MergeFailedPayload struct {
-	// Branch is the source branch that failed to merge.
-	Branch string `json:"branch"`
-
-	// Issue is the beads issue ID.
-	Issue string `json:"issue"`
-
-	// Polecat is the worker name.
-	Polecat string `json:"polecat"`
-
-	// Rig is the rig name.
-	Rig string `json:"rig"`
-
-	// FailedAt is when the failure occurred.
-	FailedAt time.Time `json:"failed_at"`
-
-	// FailureType categorizes the failure (tests, build, push, etc.).
-	FailureType string `json:"failure_type"`
-
-	// Error is the error message.
-	Error string `json:"error"`
-
-	// TargetBranch is the branch we tried to merge into.
-	TargetBranch string `json:"target_branch"`
-}
-
-// ReworkRequestPayload contains the data for a REWORK_REQUEST message.
-// Sent by Refinery when a polecat's branch has conflicts requiring rebase.
-type ReworkRequestPayload struct {
-	// Branch is the source branch that needs rebasing.
-	Branch string `json:"branch"`
-
-	// Issue is the beads issue ID.
-	Issue string `json:"issue"`
-
-	// Polecat is the worker name.
-	Polecat string `json:"polecat"`
-
-	// Rig is the rig name.
-	Rig string `json:"rig"`
-
-	// RequestedAt is when the rework was requested.
-	RequestedAt time.Time `json:"requested_at"`
-
-	// TargetBranch is the branch to rebase onto.
-	TargetBranch string `json:"target_branch"`
-
-	// ConflictFiles lists files with conflicts (if known).
-	ConflictFiles []string `json:"conflict_files,omitempty"`
-
-	// Instructions provides specific rebase instructions.
-	Instructions string `json:"instructions,omitempty"`
-}
+// The payloads of the protocol messages live in wire, so that packages
+// this one depends on, like witness, can decode them too.
+type (
+	PolecatDonePayload   = wire.PolecatDonePayload
+	MergeReadyPayload    = wire.MergeReadyPayload
+	MergedPayload        = wire.MergedPayload
+	MergeFailedPayload   = wire.MergeFailedPayload
+	ReworkRequestPayload = wire.ReworkRequestPayload
+)
 
 // IsProtocolMessage returns true if the subject matches a known protocol type.
 func IsProtocolMessage(subject string) bool {
@@ -0,0 +1,140 @@
+package wire
+
+import "time"
+
+// PolecatDonePayload contains the data for a POLECAT_DONE message.
+// Sent by a polecat from gt done.
+type PolecatDonePayload struct {
+	// Polecat is the worker name.
+	Polecat string `json:"polecat"`
+
+	// Rig is the rig name containing the polecat.
+	Rig string `json:"rig"`
+
+	// Exit is how the work ended: COMPLETED, ESCALATED, DEFERRED or
+	// PHASE_COMPLETE.
+	Exit string `json:"exit"`
+
+	// Issue is the beads issue ID the polecat worked on.
+	Issue string `json:"issue,omitempty"`
+
+	// MR is the merge request bead ID, when one was submitted.
+	MR string `json:"mr,omitempty"`
+
+	// Gate is the gate the polecat waits on when Exit is PHASE_COMPLETE.
+	Gate string `json:"gate,omitempty"`
+
+	// Branch is the polecat's work branch.
+	Branch string `json:"branch"`
+}
+
+// MergeReadyPayload contains the data for a MERGE_READY message.
+// Sent by Witness after verifying polecat work is complete.
+type MergeReadyPayload struct {
+	// Branch is the polecat's work branch (e.g., "polecat/Toast/gt-abc").
+	Branch string `json:"branch"`
+
+	// Issue is the beads issue ID the polecat completed.
+	Issue string `json:"issue"`
+
+	// Polecat is the worker name.
+	Polecat string `json:"polecat"`
+
+	// Rig is the rig name containing the polecat.
+	Rig string `json:"rig"`
+
+	// Verified contains verification notes.
+	Verified string `json:"verified,omitempty"`
+
+	// Timestamp is when the message was created.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// MergedPayload contains the data for a MERGED message.
+// Sent by Refinery after successful merge to target branch.
+type MergedPayload struct {
+	// Branch is the source branch that was merged.
+	Branch string `json:"branch"`
+
+	// Issue is the beads issue ID.
+	Issue string `json:"issue"`
+
+	// Polecat is the worker name.
+	Polecat string `json:"polecat"`
+
+	// Rig is the rig name.
+	Rig string `json:"rig"`
+
+	// MergedAt is when the merge completed.
+	MergedAt time.Time `json:"merged_at"`
+
+	// MergeCommit is the SHA of the merge commit.
+	MergeCommit string `json:"merge_commit,omitempty"`
+
+	// TargetBranch is the branch merged into (e.g., "main").
+	TargetBranch string `json:"target_branch"`
+}
+
+// MergeFailedPayload contains the data for a MERGE_FAILED message.
+// Sent by Refinery when merge fails due to tests, build, or other errors.
+type MergeFailedPayload struct {
+	// Branch is the source branch that failed to merge.
+	Branch string `json:"branch"`
+
+	// Issue is the beads issue ID.
+	Issue string `json:"issue"`
+
+	// Polecat is the worker name.
+	Polecat string `json:"polecat"`
+
+	// Rig is the rig name.
+	Rig string `json:"rig"`
+
+	// FailedAt is when the failure occurred.
+	FailedAt time.Time `json:"failed_at"`
+
+	// FailureType categorizes the failure (tests, build, push, etc.).
+	FailureType string `json:"failure_type"`
+
+	// Error is the error message.
+	Error string `json:"error"`
+
+	// TargetBranch is the branch we tried to merge into.
+	TargetBranch string `json:"target_branch"`
+
+	// MR is the merge request bead ID, for looking up the full check logs
+	// with gt mq log.
+	MR string `json:"mr,omitempty"`
+
+	// Excerpt is a summary of the failed checks' output: the lines that say
+	// what broke, prefixed with the check name.
+	Excerpt []string `json:"excerpt,omitempty"`
+}
+
+// ReworkRequestPayload contains the data for a REWORK_REQUEST message.
+// Sent by Refinery when a polecat's branch has conflicts requiring rebase.
+type ReworkRequestPayload struct {
+	// Branch is the source branch that needs rebasing.
+	Branch string `json:"branch"`
+
+	// Issue is the beads issue ID.
+	Issue string `json:"issue"`
+
+	// Polecat is the worker name.
+	Polecat string `json:"polecat"`
+
+	// Rig is the rig name.
+	Rig string `json:"rig"`
+
+	// RequestedAt is when the rework was requested.
+	RequestedAt time.Time `json:"requested_at"`
+
+	// TargetBranch is the branch to rebase onto.
+	TargetBranch string `json:"target_branch"`
+
+	// ConflictFiles lists files with conflicts (if known).
+	ConflictFiles []string `json:"conflict_files,omitempty"`
+
+	// Instructions provides specific rebase instructions.
+	Instructions string `json:"instructions,omitempty"`
+}
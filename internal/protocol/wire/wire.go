@@ -0,0 +1,135 @@
+// Package wire defines the envelope protocol messages carry in their mail
+// body and the payloads inside it. It has no dependencies so that every
+// agent package, including those the protocol package itself depends on,
+// can read envelopes.
+package wire
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Version is the payload version this build sends. Bump it when a payload
+// changes incompatibly, and keep the handlers for the previous version
+// registered until every agent in the town has been upgraded.
+const Version = 1
+
+// Marker introduces the envelope in a message body. The envelope follows
+// the human-readable "Key: value" fields, so agents that predate it (and
+// people reading their mail) still see the body they expect.
+const Marker = "--- gt-protocol envelope ---"
+
+var (
+	// ErrNoEnvelope is returned by Parse for a body without an envelope: a
+	// legacy message.
+	ErrNoEnvelope = errors.New("no protocol envelope")
+
+	// ErrUnparseable marks a protocol message that can't be understood.
+	// Handlers move such messages to the dead-letter queue instead of
+	// failing them over and over.
+	ErrUnparseable = errors.New("unparseable protocol message")
+)
+
+// Envelope is the typed, versioned form of a protocol message, carried as
+// one JSON line at the end of the mail body.
+type Envelope struct {
+	// Type is the message type; it matches the subject prefix.
+	Type string `json:"type"`
+
+	// Version is the payload version. Legacy messages, which have no
+	// envelope, are version 0.
+	Version int `json:"version"`
+
+	// ID identifies the message for idempotent handling. It survives mail
+	// redelivery, unlike the ID the mail store assigns.
+	ID string `json:"id"`
+
+	// CorrelationID ties together the messages about one piece of work,
+	// from POLECAT_DONE through MERGED or MERGE_FAILED: the polecat's branch.
+	CorrelationID string `json:"correlation_id,omitempty"`
+
+	// SentAt is when the message was created.
+	SentAt time.Time `json:"sent_at"`
+
+	// Payload is the type's payload, e.g. a MergeFailedPayload.
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Decode unmarshals the payload into v. A payload that doesn't decode is
+// reported as ErrUnparseable.
+func (e *Envelope) Decode(v any) error {
+	if len(e.Payload) == 0 {
+		return fmt.Errorf("%w: %s v%d has no payload", ErrUnparseable, e.Type, e.Version)
+	}
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("%w: decoding %s v%d payload: %v", ErrUnparseable, e.Type, e.Version, err)
+	}
+	return nil
+}
+
+// Parse extracts the envelope from a message body. It returns ErrNoEnvelope
+// when the body has none, and an ErrUnparseable error when the envelope is
+// malformed.
+func Parse(body string) (*Envelope, error) {
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	found := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !found {
+			found = line == Marker
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		env := &Envelope{}
+		if err := json.Unmarshal([]byte(line), env); err != nil {
+			return nil, fmt.Errorf("%w: decoding envelope: %v", ErrUnparseable, err)
+		}
+		if env.Type == "" || env.Version < 1 || env.ID == "" {
+			return nil, fmt.Errorf("%w: envelope needs a type, version and id", ErrUnparseable)
+		}
+		return env, nil
+	}
+	if found {
+		return nil, fmt.Errorf("%w: envelope marker without an envelope", ErrUnparseable)
+	}
+	return nil, ErrNoEnvelope
+}
+
+// Expect parses the envelope of a message body that should be of type
+// msgType at Version. It returns nil for a legacy message, whose fields the
+// caller parses from the body itself.
+func Expect(body, msgType string) (*Envelope, error) {
+	env, err := Parse(body)
+	if errors.Is(err, ErrNoEnvelope) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if env.Type != msgType {
+		return nil, fmt.Errorf("%w: %s envelope in a %s message", ErrUnparseable, env.Type, msgType)
+	}
+	if env.Version != Version {
+		return nil, fmt.Errorf("%w: unsupported %s version %d", ErrUnparseable, msgType, env.Version)
+	}
+	return env, nil
+}
+
+// Append adds env to a message body, after its fields.
+func Append(body string, env *Envelope) (string, error) {
+	line, err := json.Marshal(env)
+	if err != nil {
+		return body, err
+	}
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return body + "\n" + Marker + "\n" + string(line) + "\n", nil
+}
@@ -62,7 +62,7 @@ func (h *DefaultWitnessHandler) HandleMerged(payload *MergedPayload) error {
 
 	// Initiate polecat cleanup using AutoNukeIfClean
 	// This verifies cleanup_status before nuking to prevent work loss.
-	nukeResult := witness.AutoNukeIfClean(h.WorkDir, h.Rig, payload.Polecat)
+	nukeResult := witness.AutoNukeIfClean(h.WorkDir, h.Rig, payload.Polecat, payload.MergeCommit)
 	if nukeResult.Nuked {
 		fmt.Fprintf(h.Output, "[Witness] ✓ Auto-nuked polecat %s: %s\n", payload.Polecat, nukeResult.Reason)
 	} else if nukeResult.Skipped {
@@ -150,6 +150,17 @@ Thank you for your contribution! Your worktree will be cleaned up shortly.`,
 
 // notifyPolecatFailed sends a merge failure notification to a polecat.
 func (h *DefaultWitnessHandler) notifyPolecatFailed(payload *MergeFailedPayload) error {
+	details := ""
+	if len(payload.Excerpt) > 0 {
+		details += "\nWhat failed:\n"
+		for _, line := range payload.Excerpt {
+			details += fmt.Sprintf("  %s\n", line)
+		}
+	}
+	if payload.MR != "" {
+		details += fmt.Sprintf("\nFull log: gt mq log %s\n", payload.MR)
+	}
+
 	msg := mail.NewMessage(
 		fmt.Sprintf("%s/witness", h.Rig),
 		fmt.Sprintf("%s/%s", h.Rig, payload.Polecat),
@@ -160,12 +171,13 @@ Branch: %s
 Issue: %s
 Failure: %s
 Error: %s
-
+%s
 Please fix the issue and resubmit your work with 'gt done'.`,
 			payload.Branch,
 			payload.Issue,
 			payload.FailureType,
 			payload.Error,
+			details,
 		),
 	)
 	msg.Priority = mail.PriorityHigh
@@ -0,0 +1,261 @@
+package refinery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// defaultCheckLogRetention is how long check logs are kept by default.
+const defaultCheckLogRetention = 7 * 24 * time.Hour
+
+// checkRunLayout names a run directory: the UTC start time, so runs sort
+// chronologically by name.
+const checkRunLayout = "20060102T150405.000Z"
+
+// maxMergeFailedExcerpt caps the excerpt sent to the Witness with
+// MERGE_FAILED, across all failed checks.
+const maxMergeFailedExcerpt = 30
+
+// CheckRun is one run of the pre-merge checks for an MR.
+type CheckRun struct {
+	ID   string     // Run directory name (its start time)
+	Time time.Time  // When the run started
+	Dir  string     // Directory holding the logs
+	Logs []CheckLog // One per check attempt, by check then attempt
+}
+
+// CheckLog is the full output of one attempt of one check.
+type CheckLog struct {
+	Check   string // Check name
+	Attempt int    // 1-based attempt number
+	Status  string // beads.MRCheckPassed, MRCheckFailed or MRCheckTimeout
+	Path    string // Log file
+}
+
+// CheckLogsDir returns the directory holding the check logs of a rig:
+// <rig>/.runtime/check-logs/<mr>/<run>/<check>.<attempt>.<status>.log.
+func CheckLogsDir(rigPath string) string {
+	return filepath.Join(rigPath, constants.DirRuntime, "check-logs")
+}
+
+// checkLogKey names the log directory of an MR: its bead ID, or its branch
+// when the MR has no bead.
+func checkLogKey(mr *MRInfo) string {
+	if mr == nil {
+		return ""
+	}
+	key := mr.ID
+	if key == "" {
+		key = mr.Branch
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ' ' || r == ':' {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// newCheckRunDir creates the directory for a new run of mr's checks. It
+// returns "" when logs can't be kept, which only costs the full output.
+func (e *Engineer) newCheckRunDir(mr *MRInfo) string {
+	key := checkLogKey(mr)
+	if e.rig == nil || e.rig.Path == "" || key == "" {
+		return ""
+	}
+	root := CheckLogsDir(e.rig.Path)
+	e.pruneCheckLogs(root)
+
+	dir := filepath.Join(root, key, time.Now().UTC().Format(checkRunLayout))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: creating check log dir: %v\n", err)
+		return ""
+	}
+	return dir
+}
+
+// writeCheckLog saves the output of one check attempt in a run directory.
+func (e *Engineer) writeCheckLog(runDir, check string, attempt int, status string, output []byte) {
+	if runDir == "" {
+		return
+	}
+	name := fmt.Sprintf("%s.%d.%s.log", check, attempt, status)
+	if err := os.WriteFile(filepath.Join(runDir, name), output, 0644); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: saving log of check %s: %v\n", check, err)
+	}
+}
+
+// pruneCheckLogs removes runs older than LogRetention, and MR directories
+// left empty by that.
+func (e *Engineer) pruneCheckLogs(root string) {
+	if e.config.LogRetention <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-e.config.LogRetention)
+	mrDirs, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	for _, mrDir := range mrDirs {
+		if !mrDir.IsDir() {
+			continue
+		}
+		mrPath := filepath.Join(root, mrDir.Name())
+		runs, err := os.ReadDir(mrPath)
+		if err != nil {
+			continue
+		}
+		kept := 0
+		for _, run := range runs {
+			started, err := time.Parse(checkRunLayout, run.Name())
+			if err != nil || !started.Before(cutoff) {
+				kept++
+				continue
+			}
+			_ = os.RemoveAll(filepath.Join(mrPath, run.Name()))
+		}
+		if kept == 0 {
+			_ = os.Remove(mrPath)
+		}
+	}
+}
+
+// ListCheckRuns returns the saved check runs of an MR (bead ID, or branch
+// for MRs without a bead), newest first.
+func ListCheckRuns(rigPath, mr string) ([]CheckRun, error) {
+	mrDir := filepath.Join(CheckLogsDir(rigPath), checkLogKey(&MRInfo{ID: mr}))
+	entries, err := os.ReadDir(mrDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var runs []CheckRun
+	for _, entry := range entries {
+		started, err := time.Parse(checkRunLayout, entry.Name())
+		if !entry.IsDir() || err != nil {
+			continue
+		}
+		run := CheckRun{ID: entry.Name(), Time: started, Dir: filepath.Join(mrDir, entry.Name())}
+		files, err := os.ReadDir(run.Dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if log, ok := parseCheckLogName(f.Name()); ok {
+				log.Path = filepath.Join(run.Dir, f.Name())
+				run.Logs = append(run.Logs, log)
+			}
+		}
+		sort.Slice(run.Logs, func(i, j int) bool {
+			if run.Logs[i].Check != run.Logs[j].Check {
+				return run.Logs[i].Check < run.Logs[j].Check
+			}
+			return run.Logs[i].Attempt < run.Logs[j].Attempt
+		})
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].ID > runs[j].ID })
+	return runs, nil
+}
+
+// parseCheckLogName parses "<check>.<attempt>.<status>.log". Check names
+// may contain dots, so the name is split from the right.
+func parseCheckLogName(name string) (CheckLog, bool) {
+	base, ok := strings.CutSuffix(name, ".log")
+	if !ok {
+		return CheckLog{}, false
+	}
+	parts := strings.Split(base, ".")
+	if len(parts) < 3 {
+		return CheckLog{}, false
+	}
+	attempt, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil {
+		return CheckLog{}, false
+	}
+	return CheckLog{
+		Check:   strings.Join(parts[:len(parts)-2], "."),
+		Attempt: attempt,
+		Status:  parts[len(parts)-1],
+	}, true
+}
+
+// LastAttempts returns the final attempt of each check in the run.
+func (r CheckRun) LastAttempts() []CheckLog {
+	var last []CheckLog
+	for _, log := range r.Logs {
+		if n := len(last); n > 0 && last[n-1].Check == log.Check {
+			last[n-1] = log
+			continue
+		}
+		last = append(last, log)
+	}
+	return last
+}
+
+// failureLine matches the lines of test and build output that say what
+// broke: go test failures, compiler errors, panics and generic errors.
+var failureLine = regexp.MustCompile(`^\s*(--- FAIL|FAIL\b|panic:|fatal error:)|\.go:\d+:|(?i)\berror\b`)
+
+// failureContextLines is how many lines after a panic are kept with it,
+// for the top of the stack.
+const failureContextLines = 4
+
+// failureExcerpt summarizes failed output for the MR bead: the lines that
+// say what broke, or the last lines of output when none stand out.
+func failureExcerpt(output string, n int) []string {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return nil
+	}
+	lines := strings.Split(output, "\n")
+
+	var excerpt []string
+	for i := 0; i < len(lines) && len(excerpt) < n; i++ {
+		line := lines[i]
+		if !failureLine.MatchString(line) {
+			continue
+		}
+		excerpt = append(excerpt, line)
+		if strings.HasPrefix(strings.TrimSpace(line), "panic:") {
+			for j := i + 1; j < len(lines) && j <= i+failureContextLines && len(excerpt) < n; j++ {
+				excerpt = append(excerpt, lines[j])
+			}
+			i += failureContextLines
+		}
+	}
+	if len(excerpt) == 0 {
+		return logTail(output, n)
+	}
+	return excerpt
+}
+
+// mergeFailedExcerpt combines the excerpts of the failed checks for the
+// MERGE_FAILED message, each line prefixed with its check's name.
+func mergeFailedExcerpt(checks []beads.MRCheck) []string {
+	var excerpt []string
+	for _, c := range checks {
+		if c.Status != beads.MRCheckFailed && c.Status != beads.MRCheckTimeout {
+			continue
+		}
+		for _, line := range c.LogTail {
+			if len(excerpt) == maxMergeFailedExcerpt {
+				return excerpt
+			}
+			excerpt = append(excerpt, "["+c.Name+"] "+line)
+		}
+	}
+	return excerpt
+}
@@ -0,0 +1,427 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// checkLogTailLines is how many lines of a failed check's output are kept
+// on the MR bead. The full output is kept under CheckLogsDir.
+const checkLogTailLines = 20
+
+// checkWaitDelay is how long a check that timed out gets to release its
+// output after it is killed, in case it left children running.
+const checkWaitDelay = 2 * time.Second
+
+// Check is a named pre-merge check from merge_queue.checks. Every check must
+// pass (or be skipped) before an MR lands.
+type Check struct {
+	// Name identifies the check in output and on the MR bead (e.g. "lint").
+	Name string `json:"name"`
+
+	// Command is run with sh -c.
+	Command string `json:"command"`
+
+	// Dir is the working directory, relative to the repository root.
+	Dir string `json:"dir,omitempty"`
+
+	// Env is added to the refinery's environment.
+	Env map[string]string `json:"env,omitempty"`
+
+	// Timeout stops the check after this long. Zero means no limit.
+	Timeout time.Duration `json:"timeout,omitempty"`
+
+	// Paths limits the check to MRs that change a matching file. Patterns
+	// are relative to the repository root; * matches within a path segment
+	// and ** across segments (e.g. "internal/web/**"). Empty always runs.
+	Paths []string `json:"paths,omitempty"`
+
+	// Format is how the check reports individual tests: "go-test-json" (its
+	// output is go test -json) or "junit" (it writes a JUnit XML file to
+	// Report). With a format, tests that fail and then pass on retry are
+	// tracked in the rig's flaky test ledger.
+	Format string `json:"format,omitempty"`
+
+	// Report is the JUnit XML file the check writes, relative to Dir.
+	Report string `json:"report,omitempty"`
+
+	// RetryCommand, when set, replaces Command on retries and reruns only
+	// the failed tests. It is a Go template over RetryCommandData, e.g.
+	// "go test -json -run '{{.Run}}' {{.Packages}}".
+	RetryCommand string `json:"retry_command,omitempty"`
+}
+
+// checkRaw is a check as written in config.json, with a string timeout.
+type checkRaw struct {
+	Name    string            `json:"name"`
+	Command string            `json:"command"`
+	Dir     string            `json:"dir"`
+	Env     map[string]string `json:"env"`
+	Timeout string            `json:"timeout"`
+	Paths   []string          `json:"paths"`
+	Format  string            `json:"format"`
+	Report  string            `json:"report"`
+	Retry   string            `json:"retry_command"`
+}
+
+// checkName is the form of a check name. Names are used in log file names
+// (<check>.<attempt>.<status>.log), so they can't contain path separators.
+var checkName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// parseChecks validates the merge_queue.checks section.
+func parseChecks(raw []checkRaw) ([]Check, error) {
+	seen := make(map[string]bool)
+	checks := make([]Check, 0, len(raw))
+	for i, r := range raw {
+		if !checkName.MatchString(r.Name) || r.Name == "." || r.Name == ".." {
+			return nil, fmt.Errorf("checks[%d]: invalid name %q (letters, digits, '.', '_' and '-' only)", i, r.Name)
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("checks[%d]: duplicate name %q", i, r.Name)
+		}
+		seen[r.Name] = true
+		if r.Command == "" {
+			return nil, fmt.Errorf("check %s: command is required", r.Name)
+		}
+		if filepath.IsAbs(r.Dir) || strings.HasPrefix(filepath.Clean(r.Dir), "..") {
+			return nil, fmt.Errorf("check %s: dir must be inside the repository", r.Name)
+		}
+		if !validReportFormat(r.Format) {
+			return nil, fmt.Errorf("check %s: invalid format %q (want %s or %s)", r.Name, r.Format, ReportGoTestJSON, ReportJUnit)
+		}
+		if r.Format == ReportJUnit && r.Report == "" {
+			return nil, fmt.Errorf("check %s: format junit needs a report file", r.Name)
+		}
+		if r.Retry != "" {
+			if r.Format == "" {
+				return nil, fmt.Errorf("check %s: retry_command needs a format to know which tests failed", r.Name)
+			}
+			if _, err := parseRetryTemplate(r.Retry); err != nil {
+				return nil, fmt.Errorf("check %s: invalid retry_command: %w", r.Name, err)
+			}
+		}
+		c := Check{Name: r.Name, Command: r.Command, Dir: r.Dir, Env: r.Env, Paths: r.Paths,
+			Format: r.Format, Report: r.Report, RetryCommand: r.Retry}
+		if r.Timeout != "" {
+			d, err := time.ParseDuration(r.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("check %s: invalid timeout %q: %w", r.Name, r.Timeout, err)
+			}
+			c.Timeout = d
+		}
+		for _, p := range r.Paths {
+			if _, err := path.Match(strings.ReplaceAll(p, "**", "*"), ""); err != nil {
+				return nil, fmt.Errorf("check %s: invalid path pattern %q", r.Name, p)
+			}
+		}
+		checks = append(checks, c)
+	}
+	return checks, nil
+}
+
+// checks returns the checks to run before merging: the configured list, or
+// the legacy test_command as a single "test" check.
+func (e *Engineer) checks() []Check {
+	if !e.config.RunTests {
+		return nil
+	}
+	if len(e.config.Checks) > 0 {
+		return e.config.Checks
+	}
+	if e.config.TestCommand != "" {
+		return []Check{{Name: "test", Command: e.config.TestCommand}}
+	}
+	return nil
+}
+
+// hasChecks reports whether MRs have to pass checks before merging.
+func (e *Engineer) hasChecks() bool {
+	return len(e.checks()) > 0
+}
+
+// checksSummary describes the checks for log output.
+func (e *Engineer) checksSummary() string {
+	checks := e.checks()
+	if len(checks) == 1 {
+		return checks[0].Command
+	}
+	names := make([]string, len(checks))
+	for i, c := range checks {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// runChecksIn runs mr's pre-merge checks in dir, a checkout of head. Path
+// filters compare head with base. Up to ParallelChecks run at once, and a
+// failing check is retried RetryFlakyTests times. The output of every
+// attempt is saved under the rig's check logs.
+func (e *Engineer) runChecksIn(ctx context.Context, dir, base, head string, mr *MRInfo) ProcessResult {
+	checks := e.checks()
+	if len(checks) == 0 {
+		return ProcessResult{Success: true}
+	}
+	runDir := e.newCheckRunDir(mr)
+
+	var changed []string
+	var changedErr error
+	for _, c := range checks {
+		if len(c.Paths) > 0 {
+			changed, changedErr = git.NewGit(dir).ChangedFiles(base, head)
+			break
+		}
+	}
+
+	parallel := e.config.ParallelChecks
+	if parallel < 1 {
+		parallel = 1
+	}
+	results := make([]beads.MRCheck, len(checks))
+	flaky := make([][]TestResult, len(checks))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		// If the changed files are unknown, run filtered checks rather than skip them
+		if len(c.Paths) > 0 && changedErr == nil && !anyPathMatches(c.Paths, changed) {
+			results[i] = beads.MRCheck{
+				Name:   c.Name,
+				Status: beads.MRCheckSkipped,
+				Detail: "no changes in " + strings.Join(c.Paths, ", "),
+			}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, c Check) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i], flaky[i] = e.runCheck(ctx, dir, c, runDir)
+		}(i, c)
+	}
+	wg.Wait()
+
+	var flakes []flakyObservation
+	for i, tests := range flaky {
+		for _, t := range tests {
+			flakes = append(flakes, flakyObservation{check: checks[i].Name, test: t})
+		}
+	}
+	e.recordFlakes(mr, flakes)
+
+	var failed []string
+	var firstErr string
+	for _, r := range results {
+		switch r.Status {
+		case beads.MRCheckPassed:
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Check %s passed (%s)\n", r.Name, r.Duration.Round(100*time.Millisecond))
+		case beads.MRCheckSkipped:
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Check %s skipped: %s\n", r.Name, r.Detail)
+		default:
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Check %s %s: %s\n", r.Name, r.Status, r.Detail)
+			failed = append(failed, r.Name)
+			if firstErr == "" {
+				firstErr = r.Detail
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   "test run canceled",
+			Checks:  results,
+		}
+	}
+	if len(failed) > 0 {
+		msg := fmt.Sprintf("check %s failed: %s", failed[0], firstErr)
+		if len(failed) > 1 {
+			msg = fmt.Sprintf("checks failed: %s", strings.Join(failed, ", "))
+		}
+		return ProcessResult{
+			Success:     false,
+			TestsFailed: true,
+			Error:       msg,
+			Checks:      results,
+		}
+	}
+	return ProcessResult{Success: true, Checks: results}
+}
+
+// runCheck runs one check, retrying a failure up to RetryFlakyTests times.
+// Each attempt's output is saved in runDir when it is set. It also returns
+// the tests that failed and then passed on a retry: the check's flakes.
+func (e *Engineer) runCheck(ctx context.Context, dir string, c Check, runDir string) (beads.MRCheck, []TestResult) {
+	maxAttempts := 1 + e.config.RetryFlakyTests
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	result := beads.MRCheck{Name: c.Name}
+	var failing, flaky []TestResult
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		command := c.Command
+		if attempt > 1 && c.RetryCommand != "" && onlyTestFailures(failing) {
+			if retry, err := renderRetryCommand(c.RetryCommand, failing); err != nil {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: check %s retry_command: %v\n", c.Name, err)
+			} else {
+				command = retry
+			}
+		}
+		if attempt > 1 {
+			if command != c.Command {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Retrying %d failed test(s) of check %s (attempt %d/%d)...\n", len(failing), c.Name, attempt, maxAttempts)
+			} else {
+				_, _ = fmt.Fprintf(e.output, "[Engineer] Retrying check %s (attempt %d/%d)...\n", c.Name, attempt, maxAttempts)
+			}
+		}
+		if c.Report != "" {
+			// Don't read a stale report if this attempt fails to write one
+			_ = os.Remove(filepath.Join(dir, c.Dir, c.Report))
+		}
+
+		runCtx, cancel := ctx, context.CancelFunc(func() {})
+		if c.Timeout > 0 {
+			runCtx, cancel = context.WithTimeout(ctx, c.Timeout)
+		}
+		// Note: check commands come from rig's config.json (trusted infrastructure config),
+		// not from PR branches. Shell execution is intentional for flexibility (pipes, etc).
+		cmd := exec.CommandContext(runCtx, "sh", "-c", command) //nolint:gosec // G204: check commands are from trusted rig config
+		cmd.Dir = filepath.Join(dir, c.Dir)
+		cmd.Env = os.Environ()
+		for k, v := range c.Env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		cmd.WaitDelay = checkWaitDelay
+
+		start := time.Now()
+		err := cmd.Run()
+		result.Duration = time.Since(start)
+		timedOut := errors.Is(runCtx.Err(), context.DeadlineExceeded)
+		cancel()
+
+		tests, text := checkReport(c, dir, out.Bytes())
+		if attempt > 1 {
+			flaky = append(flaky, recoveredTests(failing, tests, err == nil)...)
+		}
+
+		if err == nil {
+			e.writeCheckLog(runDir, c.Name, attempt, beads.MRCheckPassed, []byte(text))
+			if attempt > 1 && len(flaky) == 0 {
+				// Without a test report the check itself is the flake
+				flaky = []TestResult{{}}
+			}
+			result.Status = beads.MRCheckPassed
+			result.Detail = ""
+			if attempt > 1 {
+				result.Detail = flakyDetail(flaky)
+			}
+			result.LogTail = nil
+			return result, flaky
+		}
+		result.Status = beads.MRCheckFailed
+		result.Detail = err.Error()
+		if timedOut {
+			result.Status = beads.MRCheckTimeout
+			result.Detail = fmt.Sprintf("timed out after %s", c.Timeout)
+		}
+		e.writeCheckLog(runDir, c.Name, attempt, result.Status, []byte(text))
+		result.LogTail = failureExcerpt(text, checkLogTailLines)
+		if attempt > 1 {
+			result.Detail += fmt.Sprintf(", %d attempts", attempt)
+		}
+
+		if ctx.Err() != nil {
+			result.Detail = "canceled"
+			return result, nil
+		}
+		if failed := failedTests(tests); len(failed) > 0 || command == c.Command {
+			// A narrowed retry only reports the tests it reran
+			failing = failed
+		}
+	}
+
+	if result.Status == beads.MRCheckFailed {
+		if q := e.quarantined(c.Name, failing); q != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Check %s failed only in quarantined flaky tests: %s\n", c.Name, testNames(q))
+			result.Status = beads.MRCheckPassed
+			result.Detail = "quarantined failures: " + testNames(q)
+		}
+	}
+	return result, flaky
+}
+
+// flakyDetail describes the flakes of a check that passed on retry.
+func flakyDetail(flaky []TestResult) string {
+	if len(flaky) == 1 && flaky[0].Name == "" {
+		return "flaky: passed on retry"
+	}
+	return "flaky: " + testNames(flaky)
+}
+
+// logTail returns the last n lines of output.
+func logTail(output string, n int) []string {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return nil
+	}
+	lines := strings.Split(output, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// anyPathMatches reports whether any changed file matches any pattern.
+func anyPathMatches(patterns, files []string) bool {
+	for _, f := range files {
+		for _, p := range patterns {
+			if matchPathPattern(p, f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchPathPattern matches a slash-separated path against a pattern where
+// ** stands for any number of path segments.
+func matchPathPattern(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
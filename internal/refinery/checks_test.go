@@ -0,0 +1,251 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestMatchPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"internal/web/**", "internal/web/gui.go", true},
+		{"internal/web/**", "internal/web/static/app.js", true},
+		{"internal/web/**", "internal/webhook/x.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "internal/cmd/feed.go", true},
+		{"**/*.go", "docs/reference.md", false},
+		{"docs/*.md", "docs/reference.md", true},
+		{"docs/*.md", "docs/design/federation.md", false},
+		{"go.mod", "go.mod", true},
+	}
+	for _, tt := range tests {
+		if got := matchPathPattern(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchPathPattern(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEngineer_LoadConfig_Checks(t *testing.T) {
+	tests := []struct {
+		name    string
+		checks  string
+		wantErr string
+	}{
+		{"valid", `[{"name": "build", "command": "go build ./...", "timeout": "5m"}, {"name": "web", "command": "npm test", "dir": "web", "env": {"CI": "1"}, "paths": ["internal/web/**"]}]`, ""},
+		{"missing command", `[{"name": "build"}]`, "command is required"},
+		{"duplicate", `[{"name": "a", "command": "true"}, {"name": "a", "command": "true"}]`, "duplicate name"},
+		{"bad timeout", `[{"name": "a", "command": "true", "timeout": "soon"}]`, "invalid timeout"},
+		{"name with slash", `[{"name": "../../etc/x", "command": "true"}]`, "invalid name"},
+		{"dot name", `[{"name": "..", "command": "true"}]`, "invalid name"},
+		{"dir outside repo", `[{"name": "a", "command": "true", "dir": "../other"}]`, "inside the repository"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			data := `{"type": "rig", "merge_queue": {"parallel_checks": 2, "log_retention": "72h", "checks": ` + tt.checks + `}}`
+			if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			e := NewEngineer(&rig.Rig{Name: "test-rig", Path: dir})
+			err := e.LoadConfig()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			cfg := e.Config()
+			if len(cfg.Checks) != 2 || cfg.ParallelChecks != 2 || cfg.LogRetention != 72*time.Hour || cfg.Checks[0].Timeout != 5*time.Minute ||
+				cfg.Checks[1].Dir != "web" || cfg.Checks[1].Env["CI"] != "1" || cfg.Checks[1].Paths[0] != "internal/web/**" {
+				t.Errorf("config = %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestEngineer_RunChecks(t *testing.T) {
+	e, dir := newTrainTestEngineer(t, map[string][2]string{"polecat/nux": {"nux.txt", "nux\n"}})
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	e.config.ParallelChecks = 3
+	e.config.RetryFlakyTests = 1
+	e.config.Checks = []Check{
+		{Name: "build", Command: "true"},
+		{Name: "env", Command: `test "$CHECK_MODE" = strict && test "$(basename "$PWD")" = sub`, Dir: "sub", Env: map[string]string{"CHECK_MODE": "strict"}},
+		{Name: "web", Command: "false", Paths: []string{"internal/web/**"}},
+		{Name: "nux", Command: "true", Paths: []string{"*.txt"}},
+	}
+
+	mr := &MRInfo{ID: "gt-mr1", Branch: "polecat/nux", Target: "main"}
+	result := e.runChecksIn(context.Background(), dir, "main", "polecat/nux", mr)
+	if !result.Success {
+		t.Fatalf("runChecksIn: %s\n%+v", result.Error, result.Checks)
+	}
+	want := []string{beads.MRCheckPassed, beads.MRCheckPassed, beads.MRCheckSkipped, beads.MRCheckPassed}
+	for i, c := range result.Checks {
+		if c.Status != want[i] {
+			t.Errorf("check %s = %s (%s), want %s", c.Name, c.Status, c.Detail, want[i])
+		}
+	}
+
+	// A failing check is retried, then reported with an excerpt of its log
+	e.config.Checks = []Check{
+		{Name: "build", Command: "true"},
+		{Name: "unit", Command: "echo running; echo FAIL: TestMerge >&2; exit 3"},
+		{Name: "slow", Command: "sleep 5", Timeout: 200 * time.Millisecond},
+	}
+	out := &bytes.Buffer{}
+	e.output = out
+	result = e.runChecksIn(context.Background(), dir, "main", "polecat/nux", mr)
+	if result.Success || !result.TestsFailed || result.Error != "checks failed: unit, slow" {
+		t.Fatalf("result = %+v", result)
+	}
+	unit := result.Checks[1]
+	if unit.Status != beads.MRCheckFailed || !strings.Contains(unit.Detail, "exit status 3") ||
+		strings.Join(unit.LogTail, "|") != "FAIL: TestMerge" {
+		t.Errorf("unit = %+v", unit)
+	}
+	if slow := result.Checks[2]; slow.Status != beads.MRCheckTimeout || slow.Duration > 4*time.Second {
+		t.Errorf("slow = %+v", slow)
+	}
+	if !strings.Contains(out.String(), "Retrying check unit (attempt 2/2)") {
+		t.Errorf("expected a retry in output:\n%s", out)
+	}
+
+	// Every attempt's full output is kept, newest run first
+	runs, err := ListCheckRuns(e.rig.Path, "gt-mr1")
+	if err != nil || len(runs) != 2 {
+		t.Fatalf("ListCheckRuns = %d runs, %v", len(runs), err)
+	}
+	var names []string
+	for _, log := range runs[0].Logs {
+		names = append(names, fmt.Sprintf("%s.%d.%s", log.Check, log.Attempt, log.Status))
+	}
+	if got := strings.Join(names, " "); got != "build.1.passed slow.1.timeout slow.2.timeout unit.1.failed unit.2.failed" {
+		t.Errorf("logs = %s", got)
+	}
+	if last := runs[0].LastAttempts(); len(last) != 3 || last[2].Attempt != 2 {
+		t.Errorf("LastAttempts() = %+v", last)
+	}
+	if data, _ := os.ReadFile(runs[0].Logs[4].Path); string(data) != "running\nFAIL: TestMerge\n" {
+		t.Errorf("unit log = %q", data)
+	}
+}
+
+func TestEngineer_PruneCheckLogs(t *testing.T) {
+	root := t.TempDir()
+	e := &Engineer{rig: &rig.Rig{Name: "test-rig", Path: root}, config: DefaultMergeQueueConfig(), output: &bytes.Buffer{}}
+	logs := CheckLogsDir(root)
+	old := time.Now().Add(-8 * 24 * time.Hour).UTC().Format(checkRunLayout)
+	for _, dir := range []string{"gt-old/" + old, "gt-mixed/" + old, "gt-mixed/not-a-run"} {
+		if err := os.MkdirAll(filepath.Join(logs, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := e.newCheckRunDir(&MRInfo{Branch: "polecat/nux"})
+	if filepath.Base(filepath.Dir(run)) != "polecat_nux" {
+		t.Errorf("run dir = %s, want it under polecat_nux", run)
+	}
+	if _, err := os.Stat(filepath.Join(logs, "gt-old")); !os.IsNotExist(err) {
+		t.Error("expired MR logs were not removed")
+	}
+	if _, err := os.Stat(filepath.Join(logs, "gt-mixed", old)); !os.IsNotExist(err) {
+		t.Error("expired run was not removed")
+	}
+	if _, err := os.Stat(filepath.Join(logs, "gt-mixed", "not-a-run")); err != nil {
+		t.Error("unrecognized directory was removed")
+	}
+}
+
+func TestFailureExcerpt(t *testing.T) {
+	goTest := `=== RUN   TestParse
+    parse_test.go:42: got 1, want 2
+--- FAIL: TestParse (0.00s)
+=== RUN   TestOther
+--- PASS: TestOther (0.00s)
+FAIL
+FAIL	example.com/pkg	0.012s
+`
+	want := []string{
+		"    parse_test.go:42: got 1, want 2",
+		"--- FAIL: TestParse (0.00s)",
+		"FAIL",
+		"FAIL\texample.com/pkg\t0.012s",
+	}
+	if got := failureExcerpt(goTest, 20); !reflect.DeepEqual(got, want) {
+		t.Errorf("go test excerpt = %q", got)
+	}
+
+	panicOut := "ok\npanic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/src/main.go:5 +0x1d\nexit status 2"
+	if got := failureExcerpt(panicOut, 20); len(got) != 5 || got[0] != "panic: boom" || got[4] != "\t/src/main.go:5 +0x1d" {
+		t.Errorf("panic excerpt = %q", got)
+	}
+
+	if got := failureExcerpt("one\ntwo\nthree\n", 2); !reflect.DeepEqual(got, []string{"two", "three"}) {
+		t.Errorf("fallback excerpt = %q", got)
+	}
+
+	checks := []beads.MRCheck{
+		{Name: "lint", Status: beads.MRCheckPassed},
+		{Name: "unit", Status: beads.MRCheckFailed, LogTail: []string{"--- FAIL: TestParse"}},
+	}
+	if got := mergeFailedExcerpt(checks); !reflect.DeepEqual(got, []string{"[unit] --- FAIL: TestParse"}) {
+		t.Errorf("mergeFailedExcerpt() = %q", got)
+	}
+}
+
+func TestEngineer_DoMerge_RecordsChecks(t *testing.T) {
+	e, _ := newTrainTestEngineer(t, map[string][2]string{"polecat/nux": {"nux.txt", "nux\n"}})
+	e.config.Checks = []Check{{Name: "lint", Command: "true"}, {Name: "unit", Command: "exit 1"}}
+
+	result := e.doMerge(context.Background(), &MRInfo{Branch: "polecat/nux", Target: "main"})
+	if result.Success || !result.TestsFailed || len(result.Checks) != 2 {
+		t.Fatalf("result = %+v", result)
+	}
+	desc := beads.SetMRChecks("branch: polecat/nux\ntarget: main", result.Checks)
+	if got := beads.ParseMRChecks(desc); len(got) != 2 || got[1].Status != beads.MRCheckFailed {
+		t.Errorf("recorded checks = %+v", got)
+	}
+
+	e.config.Checks = e.config.Checks[:1]
+	result = e.doMerge(context.Background(), &MRInfo{Branch: "polecat/nux", Target: "main"})
+	if !result.Success || len(result.Checks) != 1 || result.Checks[0].Status != beads.MRCheckPassed {
+		t.Fatalf("result = %+v", result)
+	}
+}
+
+func TestEngineer_DoMerge_ChecksSeeBranch(t *testing.T) {
+	e, dir := newTrainTestEngineer(t, map[string][2]string{"polecat/nux": {"nux.txt", "BROKEN\n"}})
+	before := runGit(t, dir, "rev-parse", "main")
+
+	result := e.doMerge(context.Background(), &MRInfo{Branch: "polecat/nux", Target: "main"})
+	if result.Success || !result.TestsFailed {
+		t.Fatalf("doMerge with a branch that breaks the tests = %+v, want rejected", result)
+	}
+	if after := runGit(t, dir, "rev-parse", "main"); after != before {
+		t.Errorf("main moved despite failing checks: %s -> %s", before, after)
+	}
+	if origin := runGit(t, dir, "rev-parse", "origin/main"); origin != before {
+		t.Errorf("origin/main = %s, want %s", origin, before)
+	}
+	if wts := runGit(t, dir, "worktree", "list"); strings.Count(wts, "\n") != 0 {
+		t.Errorf("expected only the main worktree, got:\n%s", wts)
+	}
+}
@@ -2,13 +2,11 @@
 package refinery
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -32,25 +30,67 @@ type MergeQueueConfig struct {
 	IntegrationBranches bool `json:"integration_branches"`
 
 	// OnConflict is the strategy for handling conflicts: "assign_back" or "auto_rebase".
+	// With auto_rebase the refinery rebases the branch itself and only assigns
+	// the MR back when the rebase also conflicts.
 	OnConflict string `json:"on_conflict"`
 
 	// RunTests controls whether to run tests before merging.
 	RunTests bool `json:"run_tests"`
 
-	// TestCommand is the command to run for testing.
+	// TestCommand is the command to run for testing. It is ignored when
+	// Checks are configured.
 	TestCommand string `json:"test_command"`
 
+	// Checks are the named pre-merge checks (build, lint, unit, ...) every
+	// MR has to pass. Results are recorded on the MR bead.
+	Checks []Check `json:"checks"`
+
+	// ParallelChecks is how many checks run at once.
+	ParallelChecks int `json:"parallel_checks"`
+
 	// DeleteMergedBranches controls whether to delete branches after merge.
 	DeleteMergedBranches bool `json:"delete_merged_branches"`
 
-	// RetryFlakyTests is the number of times to retry flaky tests.
+	// RetryFlakyTests is how many times a failing check is retried before
+	// it fails. A test that fails and then passes on a retry is a flake, so
+	// zero also turns off flaky test detection.
 	RetryFlakyTests int `json:"retry_flaky_tests"`
 
+	// FlakyThreshold is how many times a test may fail and then pass on
+	// retry before the refinery files a bead for it. Zero never files.
+	FlakyThreshold int `json:"flaky_threshold"`
+
+	// QuarantineFlaky lets a check pass when its only failures are tests
+	// with an open flaky test bead.
+	QuarantineFlaky bool `json:"quarantine_flaky"`
+
+	// LogRetention is how long the full output of each check attempt is
+	// kept under the rig's runtime directory. Zero keeps logs forever.
+	LogRetention time.Duration `json:"log_retention"`
+
 	// PollInterval is how often to check for new MRs.
 	PollInterval time.Duration `json:"poll_interval"`
 
 	// MaxConcurrent is the maximum number of MRs to process concurrently.
 	MaxConcurrent int `json:"max_concurrent"`
+
+	// MergeStrategy is how a branch lands on the target: "merge" (a merge
+	// commit), "squash" (one commit per MR) or "rebase-ff" (rebase onto the
+	// target and fast-forward, for linear history).
+	MergeStrategy string `json:"merge_strategy"`
+
+	// CommitMessageTemplate is a Go text/template for the commit the merge
+	// and squash strategies create, over CommitMessageData (e.g.
+	// "{{.Title}} ({{.SourceIssue}})"). Empty uses the strategy's default.
+	CommitMessageTemplate string `json:"commit_message_template"`
+
+	// CoAuthorTrailers adds a Co-authored-by trailer for the polecat that did
+	// the work to merge and squash commits.
+	CoAuthorTrailers bool `json:"co_author_trailers"`
+
+	// SigningKey signs the commits the refinery creates when set: a GPG key
+	// ID, or an SSH public key (or path to a .pub file) for SSH signing.
+	SigningKey string `json:"signing_key"`
 }
 
 // DefaultMergeQueueConfig returns sensible defaults for merge queue configuration.
@@ -64,8 +104,13 @@ func DefaultMergeQueueConfig() *MergeQueueConfig {
 		TestCommand:          "",
 		DeleteMergedBranches: true,
 		RetryFlakyTests:      1,
+		ParallelChecks:       1,
+		LogRetention:         defaultCheckLogRetention,
+		FlakyThreshold:       defaultFlakyThreshold,
 		PollInterval:         30 * time.Second,
 		MaxConcurrent:        1,
+		MergeStrategy:        MergeStrategyMerge,
+		CoAuthorTrailers:     true,
 	}
 }
 
@@ -163,16 +208,25 @@ func (e *Engineer) LoadConfig() error {
 	// Parse merge_queue section into our config struct
 	// We need special handling for poll_interval (string -> Duration)
 	var mqRaw struct {
-		Enabled              *bool   `json:"enabled"`
-		TargetBranch         *string `json:"target_branch"`
-		IntegrationBranches  *bool   `json:"integration_branches"`
-		OnConflict           *string `json:"on_conflict"`
-		RunTests             *bool   `json:"run_tests"`
-		TestCommand          *string `json:"test_command"`
-		DeleteMergedBranches *bool   `json:"delete_merged_branches"`
-		RetryFlakyTests      *int    `json:"retry_flaky_tests"`
-		PollInterval         *string `json:"poll_interval"`
-		MaxConcurrent        *int    `json:"max_concurrent"`
+		Enabled              *bool      `json:"enabled"`
+		TargetBranch         *string    `json:"target_branch"`
+		IntegrationBranches  *bool      `json:"integration_branches"`
+		OnConflict           *string    `json:"on_conflict"`
+		RunTests             *bool      `json:"run_tests"`
+		TestCommand          *string    `json:"test_command"`
+		Checks               []checkRaw `json:"checks"`
+		ParallelChecks       *int       `json:"parallel_checks"`
+		DeleteMergedBranches *bool      `json:"delete_merged_branches"`
+		RetryFlakyTests      *int       `json:"retry_flaky_tests"`
+		LogRetention         *string    `json:"log_retention"`
+		FlakyThreshold       *int       `json:"flaky_threshold"`
+		QuarantineFlaky      *bool      `json:"quarantine_flaky"`
+		PollInterval         *string    `json:"poll_interval"`
+		MaxConcurrent        *int       `json:"max_concurrent"`
+		MergeStrategy        *string    `json:"merge_strategy"`
+		CommitMessage        *string    `json:"commit_message_template"`
+		CoAuthorTrailers     *bool      `json:"co_author_trailers"`
+		SigningKey           *string    `json:"signing_key"`
 	}
 
 	if err := json.Unmarshal(rawConfig.MergeQueue, &mqRaw); err != nil {
@@ -198,12 +252,35 @@ func (e *Engineer) LoadConfig() error {
 	if mqRaw.TestCommand != nil {
 		e.config.TestCommand = *mqRaw.TestCommand
 	}
+	if mqRaw.Checks != nil {
+		checks, err := parseChecks(mqRaw.Checks)
+		if err != nil {
+			return fmt.Errorf("invalid merge_queue checks: %w", err)
+		}
+		e.config.Checks = checks
+	}
+	if mqRaw.ParallelChecks != nil {
+		e.config.ParallelChecks = *mqRaw.ParallelChecks
+	}
 	if mqRaw.DeleteMergedBranches != nil {
 		e.config.DeleteMergedBranches = *mqRaw.DeleteMergedBranches
 	}
 	if mqRaw.RetryFlakyTests != nil {
 		e.config.RetryFlakyTests = *mqRaw.RetryFlakyTests
 	}
+	if mqRaw.LogRetention != nil {
+		dur, err := time.ParseDuration(*mqRaw.LogRetention)
+		if err != nil || dur < 0 {
+			return fmt.Errorf("invalid log_retention %q: want a duration such as \"168h\"", *mqRaw.LogRetention)
+		}
+		e.config.LogRetention = dur
+	}
+	if mqRaw.FlakyThreshold != nil {
+		e.config.FlakyThreshold = *mqRaw.FlakyThreshold
+	}
+	if mqRaw.QuarantineFlaky != nil {
+		e.config.QuarantineFlaky = *mqRaw.QuarantineFlaky
+	}
 	if mqRaw.MaxConcurrent != nil {
 		e.config.MaxConcurrent = *mqRaw.MaxConcurrent
 	}
@@ -214,6 +291,25 @@ func (e *Engineer) LoadConfig() error {
 		}
 		e.config.PollInterval = dur
 	}
+	if mqRaw.MergeStrategy != nil {
+		if !validMergeStrategy(*mqRaw.MergeStrategy) {
+			return fmt.Errorf("invalid merge_strategy %q (want %s, %s or %s)", *mqRaw.MergeStrategy,
+				MergeStrategyMerge, MergeStrategySquash, MergeStrategyRebaseFF)
+		}
+		e.config.MergeStrategy = *mqRaw.MergeStrategy
+	}
+	if mqRaw.CommitMessage != nil {
+		if _, err := parseCommitTemplate(*mqRaw.CommitMessage); err != nil {
+			return fmt.Errorf("invalid commit_message_template: %w", err)
+		}
+		e.config.CommitMessageTemplate = *mqRaw.CommitMessage
+	}
+	if mqRaw.CoAuthorTrailers != nil {
+		e.config.CoAuthorTrailers = *mqRaw.CoAuthorTrailers
+	}
+	if mqRaw.SigningKey != nil {
+		e.config.SigningKey = *mqRaw.SigningKey
+	}
 
 	return nil
 }
@@ -230,6 +326,7 @@ type ProcessResult struct {
 	Error       string
 	Conflict    bool
 	TestsFailed bool
+	Checks      []beads.MRCheck // Results of the pre-merge checks that ran
 }
 
 // ProcessMR processes a single merge request from a beads issue.
@@ -249,12 +346,22 @@ func (e *Engineer) ProcessMR(ctx context.Context, mr *beads.Issue) ProcessResult
 	_, _ = fmt.Fprintf(e.output, "  Target: %s\n", mrFields.Target)
 	_, _ = fmt.Fprintf(e.output, "  Worker: %s\n", mrFields.Worker)
 
-	return e.doMerge(ctx, mrFields.Branch, mrFields.Target, mrFields.SourceIssue)
+	return e.doMerge(ctx, &MRInfo{
+		ID:          mr.ID,
+		Branch:      mrFields.Branch,
+		Target:      mrFields.Target,
+		SourceIssue: mrFields.SourceIssue,
+		Worker:      mrFields.Worker,
+		Rig:         mrFields.Rig,
+		Title:       mr.Title,
+	})
 }
 
 // doMerge performs the actual git merge operation.
 // This is the core merge logic shared by ProcessMR and ProcessMRFromQueue.
-func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue string) ProcessResult {
+func (e *Engineer) doMerge(ctx context.Context, mr *MRInfo) ProcessResult {
+	branch, target := mr.Branch, mr.Target
+
 	// Step 1: Verify source branch exists locally (shared .repo.git with polecats)
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Checking local branch %s...\n", branch)
 	exists, err := e.git.BranchExists(branch)
@@ -296,40 +403,83 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 			Error:    fmt.Sprintf("conflict check failed: %v", err),
 		}
 	}
+	testsPassed := false
+	var checks []beads.MRCheck
 	if len(conflicts) > 0 {
-		return ProcessResult{
-			Success:  false,
-			Conflict: true,
-			Error:    fmt.Sprintf("merge conflicts in: %v", conflicts),
+		if e.config.OnConflict != "auto_rebase" {
+			return ProcessResult{
+				Success:  false,
+				Conflict: true,
+				Error:    fmt.Sprintf("merge conflicts in: %v", conflicts),
+			}
+		}
+
+		// Step 3b: auto_rebase - try to rebase the branch onto target ourselves
+		// instead of round-tripping the conflict through a polecat.
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Merge conflicts in %v, attempting auto-rebase...\n", conflicts)
+		result := e.autoRebase(ctx, mr)
+		if !result.Success {
+			return result
+		}
+		// autoRebase already ran the checks against the rebased branch
+		testsPassed = true
+		checks = result.Checks
+	} else if e.config.MergeStrategy == MergeStrategyRebaseFF {
+		// Step 3c: rebase-ff needs the branch on top of target to fast-forward.
+		// With a signing key the branch is always rebased so every commit
+		// landing on target is signed.
+		upToDate, err := e.git.IsAncestor(target, branch)
+		if err != nil {
+			return ProcessResult{
+				Success: false,
+				Error:   fmt.Sprintf("failed to compare %s with %s: %v", branch, target, err),
+			}
+		}
+		if !upToDate || e.config.SigningKey != "" {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Rebasing %s onto %s...\n", branch, target)
+			result := e.autoRebase(ctx, mr)
+			if !result.Success {
+				return result
+			}
+			testsPassed = true
+			checks = result.Checks
 		}
 	}
 
-	// Step 4: Run tests if configured
-	if e.config.RunTests && e.config.TestCommand != "" {
-		_, _ = fmt.Fprintf(e.output, "[Engineer] Running tests: %s\n", e.config.TestCommand)
-		result := e.runTests(ctx)
+	// Step 4: Run the pre-merge checks if configured
+	if e.hasChecks() && !testsPassed {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Running checks: %s\n", e.checksSummary())
+		result := e.runChecks(ctx, mr)
 		if !result.Success {
 			return ProcessResult{
 				Success:     false,
 				TestsFailed: true,
 				Error:       result.Error,
+				Checks:      result.Checks,
 			}
 		}
-		_, _ = fmt.Fprintln(e.output, "[Engineer] Tests passed")
+		checks = result.Checks
+		_, _ = fmt.Fprintln(e.output, "[Engineer] Checks passed")
 	}
 
-	// Step 5: Perform the actual merge
-	mergeMsg := fmt.Sprintf("Merge %s into %s", branch, target)
-	if sourceIssue != "" {
-		mergeMsg = fmt.Sprintf("Merge %s into %s (%s)", branch, target, sourceIssue)
+	// Step 5: Perform the actual merge with the configured strategy
+	var mergeMsg string
+	if e.config.MergeStrategy == MergeStrategyRebaseFF {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Fast-forwarding %s to %s\n", target, branch)
+	} else {
+		mergeMsg, err = e.commitMessage(mr)
+		if err != nil {
+			return ProcessResult{
+				Success: false,
+				Error:   err.Error(),
+			}
+		}
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Merging (%s) with message: %s\n", e.config.MergeStrategy, firstLine(mergeMsg))
 	}
-	_, _ = fmt.Fprintf(e.output, "[Engineer] Merging with message: %s\n", mergeMsg)
-	if err := e.git.MergeNoFF(branch, mergeMsg); err != nil {
+	if conflicts, err := e.land(e.git, branch, mergeMsg); err != nil {
 		// ZFC: Use git's porcelain output to detect conflicts instead of parsing stderr.
 		// GetConflictingFiles() uses `git diff --diff-filter=U` which is proper.
-		conflicts, conflictErr := e.git.GetConflictingFiles()
-		if conflictErr == nil && len(conflicts) > 0 {
-			_ = e.git.AbortMerge()
+		if len(conflicts) > 0 {
 			return ProcessResult{
 				Success:  false,
 				Conflict: true,
@@ -364,55 +514,145 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 	return ProcessResult{
 		Success:     true,
 		MergeCommit: mergeCommit,
+		Checks:      checks,
 	}
 }
 
-// runTests runs the configured test command and returns the result.
-func (e *Engineer) runTests(ctx context.Context) ProcessResult {
-	if e.config.TestCommand == "" {
-		return ProcessResult{Success: true}
+// autoRebase rebases branch onto target in a scratch worktree, runs the
+// configured tests there, and on success moves the branch to the rebased
+// commit so the normal merge path can land it.
+//
+// Only a conflicting rebase is reported as Conflict, so the caller falls
+// back to assign-back exactly when a human (or polecat) is actually needed.
+// The refinery's own working tree is left on the target branch throughout.
+func (e *Engineer) autoRebase(ctx context.Context, mr *MRInfo) ProcessResult {
+	branch, target := mr.Branch, mr.Target
+	scratchDir, err := os.MkdirTemp("", "gt-rebase-*")
+	if err != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("creating rebase worktree dir: %v", err),
+		}
 	}
+	defer func() { _ = os.RemoveAll(scratchDir) }()
 
-	// Run the test command with retries for flaky tests
-	maxRetries := e.config.RetryFlakyTests
-	if maxRetries < 1 {
-		maxRetries = 1
+	// Detached so we never fight a polecat worktree that still has the branch checked out
+	if err := e.git.WorktreeAddDetached(scratchDir, branch); err != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("creating rebase worktree for %s: %v", branch, err),
+		}
 	}
-
-	var lastErr error
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		if attempt > 1 {
-			_, _ = fmt.Fprintf(e.output, "[Engineer] Retrying tests (attempt %d/%d)...\n", attempt, maxRetries)
+	defer func() {
+		_ = e.git.WorktreeRemove(scratchDir, true)
+		_ = e.git.WorktreePrune()
+	}()
+
+	scratch := e.signing(git.NewGit(scratchDir))
+	if err := scratch.Rebase(target); err != nil {
+		conflicts, _ := scratch.GetConflictingFiles()
+		_ = scratch.AbortRebase()
+		if len(conflicts) == 0 {
+			return ProcessResult{
+				Success:  false,
+				Conflict: true,
+				Error:    fmt.Sprintf("auto-rebase onto %s failed: %v", target, err),
+			}
 		}
-
-		// Note: TestCommand comes from rig's config.json (trusted infrastructure config),
-		// not from PR branches. Shell execution is intentional for flexibility (pipes, etc).
-		cmd := exec.CommandContext(ctx, "sh", "-c", e.config.TestCommand) //nolint:gosec // G204: TestCommand is from trusted rig config
-		cmd.Dir = e.workDir
-		var stdout, stderr bytes.Buffer
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
-
-		err := cmd.Run()
-		if err == nil {
-			return ProcessResult{Success: true}
+		return ProcessResult{
+			Success:  false,
+			Conflict: true,
+			Error:    fmt.Sprintf("auto-rebase onto %s conflicts in: %v", target, conflicts),
 		}
-		lastErr = err
+	}
 
-		// Check if context was canceled
-		if ctx.Err() != nil {
+	var checks []beads.MRCheck
+	if e.hasChecks() {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Running checks on rebased branch: %s\n", e.checksSummary())
+		result := e.runChecksIn(ctx, scratchDir, target, "HEAD", mr)
+		if !result.Success {
 			return ProcessResult{
-				Success: false,
-				Error:   "test run canceled",
+				Success:     false,
+				TestsFailed: true,
+				Error:       fmt.Sprintf("tests failed after auto-rebase: %s", result.Error),
+				Checks:      result.Checks,
 			}
 		}
+		checks = result.Checks
+		_, _ = fmt.Fprintln(e.output, "[Engineer] Checks passed on rebased branch")
 	}
 
-	return ProcessResult{
-		Success:     false,
-		TestsFailed: true,
-		Error:       fmt.Sprintf("tests failed after %d attempts: %v", maxRetries, lastErr),
+	rebased, err := scratch.Rev("HEAD")
+	if err != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("failed to get rebased commit SHA: %v", err),
+		}
+	}
+	if err := e.git.UpdateBranchRef(branch, rebased); err != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("failed to update %s to rebased commit: %v", branch, err),
+		}
+	}
+
+	// Keep origin in sync with what we are about to merge. Non-fatal: the merge
+	// itself only needs the local branch, and the remote branch is deleted
+	// after merge anyway when DeleteMergedBranches is set.
+	if err := e.git.Push("origin", branch, true); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to push rebased %s to origin: %v\n", branch, err)
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Auto-rebased %s onto %s: %s\n", branch, target, rebased[:8])
+	return ProcessResult{Success: true, Checks: checks}
+}
+
+// mergeMessage returns the commit message for merging branch into target.
+func mergeMessage(branch, target, sourceIssue string) string {
+	if sourceIssue != "" {
+		return fmt.Sprintf("Merge %s into %s (%s)", branch, target, sourceIssue)
 	}
+	return fmt.Sprintf("Merge %s into %s", branch, target)
+}
+
+// runChecks merges mr's branch into its target in a scratch worktree and
+// runs the pre-merge checks against the result, so they see the code that
+// would land. The refinery's own working tree stays on the target branch.
+func (e *Engineer) runChecks(ctx context.Context, mr *MRInfo) ProcessResult {
+	branch, target := mr.Branch, mr.Target
+	scratchDir, err := os.MkdirTemp("", "gt-checks-*")
+	if err != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("creating checks worktree dir: %v", err),
+		}
+	}
+	defer func() { _ = os.RemoveAll(scratchDir) }()
+
+	if err := e.git.WorktreeAddDetached(scratchDir, target); err != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("creating checks worktree for %s: %v", target, err),
+		}
+	}
+	defer func() {
+		_ = e.git.WorktreeRemove(scratchDir, true)
+		_ = e.git.WorktreePrune()
+	}()
+
+	// The merge commit is only for testing and is thrown away with the
+	// worktree, so it is never signed
+	scratch := git.NewGit(scratchDir)
+	if err := scratch.MergeNoFF(branch, mergeMessage(branch, target, mr.SourceIssue)); err != nil {
+		conflicts, _ := scratch.GetConflictingFiles()
+		return ProcessResult{
+			Success:  false,
+			Conflict: len(conflicts) > 0,
+			Error:    fmt.Sprintf("merging %s for checks: %v", branch, err),
+		}
+	}
+
+	return e.runChecksIn(ctx, scratchDir, target, "HEAD", mr)
 }
 
 // handleSuccess handles a successful merge completion.
@@ -433,6 +673,9 @@ func (e *Engineer) handleSuccess(mr *beads.Issue, result ProcessResult) {
 	mrFields.MergeCommit = result.MergeCommit
 	mrFields.CloseReason = "merged"
 	newDesc := beads.SetMRFields(mr, mrFields)
+	if len(result.Checks) > 0 {
+		newDesc = beads.SetMRChecks(newDesc, result.Checks)
+	}
 	if err := e.beads.Update(mr.ID, beads.UpdateOptions{Description: &newDesc}); err != nil {
 		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to update MR %s with merge commit: %v\n", mr.ID, err)
 	}
@@ -483,9 +726,15 @@ func (e *Engineer) handleSuccess(mr *beads.Issue, result ProcessResult) {
 // handleFailure handles a failed merge request.
 // Reopens the MR for rework and logs the failure.
 func (e *Engineer) handleFailure(mr *beads.Issue, result ProcessResult) {
-	// Reopen the MR (back to open status for rework)
+	// Reopen the MR (back to open status for rework), with the check results
+	// that explain why
 	open := "open"
-	if err := e.beads.Update(mr.ID, beads.UpdateOptions{Status: &open}); err != nil {
+	opts := beads.UpdateOptions{Status: &open}
+	if len(result.Checks) > 0 {
+		desc := beads.SetMRChecks(mr.Description, result.Checks)
+		opts.Description = &desc
+	}
+	if err := e.beads.Update(mr.ID, opts); err != nil {
 		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to reopen MR %s: %v\n", mr.ID, err)
 	}
 
@@ -503,7 +752,7 @@ func (e *Engineer) ProcessMRInfo(ctx context.Context, mr *MRInfo) ProcessResult
 	_, _ = fmt.Fprintf(e.output, "  Source: %s\n", mr.SourceIssue)
 
 	// Use the shared merge logic
-	return e.doMerge(ctx, mr.Branch, mr.Target, mr.SourceIssue)
+	return e.doMerge(ctx, mr)
 }
 
 // HandleMRInfoSuccess handles a successful merge from MRInfo.
@@ -537,6 +786,9 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 			mrFields.MergeCommit = result.MergeCommit
 			mrFields.CloseReason = "merged"
 			newDesc := beads.SetMRFields(mrBead, mrFields)
+			if len(result.Checks) > 0 {
+				newDesc = beads.SetMRChecks(newDesc, result.Checks)
+			}
 			if err := e.beads.Update(mr.ID, beads.UpdateOptions{Description: &newDesc}); err != nil {
 				_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to update MR %s with merge commit: %v\n", mr.ID, err)
 			}
@@ -584,6 +836,9 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 // For conflicts, creates a resolution task and blocks the MR until resolved.
 // This enables non-blocking delegation: the queue continues to the next MR.
 func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
+	// Record which checks failed so gt mq status can show them
+	e.recordChecks(mr.ID, result.Checks)
+
 	// Notify Witness of the failure so polecat can be alerted
 	// Determine failure type from result
 	failureType := "build"
@@ -592,7 +847,17 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 	} else if result.TestsFailed {
 		failureType = "tests"
 	}
-	msg := protocol.NewMergeFailedMessage(e.rig.Name, mr.Worker, mr.Branch, mr.SourceIssue, mr.Target, failureType, result.Error)
+	msg := protocol.NewMergeFailedMessageFromPayload(protocol.MergeFailedPayload{
+		Branch:       mr.Branch,
+		Issue:        mr.SourceIssue,
+		Polecat:      mr.Worker,
+		Rig:          e.rig.Name,
+		FailureType:  failureType,
+		Error:        result.Error,
+		TargetBranch: mr.Target,
+		MR:           mr.ID,
+		Excerpt:      mergeFailedExcerpt(result.Checks),
+	})
 	if err := e.router.Send(msg); err != nil {
 		fmt.Fprintf(e.output, "[Engineer] Warning: failed to send MERGE_FAILED to witness: %v\n", err)
 	} else {
@@ -625,6 +890,22 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 	}
 }
 
+// recordChecks stores pre-merge check results on the MR bead.
+func (e *Engineer) recordChecks(mrID string, checks []beads.MRCheck) {
+	if mrID == "" || len(checks) == 0 {
+		return
+	}
+	mrBead, err := e.beads.Show(mrID)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to fetch MR bead %s: %v\n", mrID, err)
+		return
+	}
+	desc := beads.SetMRChecks(mrBead.Description, checks)
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &desc}); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to record checks on MR %s: %v\n", mrID, err)
+	}
+}
+
 // createConflictResolutionTaskForMR creates a dispatchable task for resolving merge conflicts.
 // This task will be picked up by bd ready and can be slung to a fresh polecat (spawned on demand).
 // Returns the created task's ID for blocking the MR until resolution.
@@ -1,12 +1,17 @@
 package refinery
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/rig"
 )
 
@@ -215,3 +220,122 @@ func TestEngineer_DeleteMergedBranchesConfig(t *testing.T) {
 		t.Error("expected DeleteMergedBranches to be true by default")
 	}
 }
+
+// runGit runs a git command in dir and fails the test on error.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// newRebaseTestEngineer creates a repo where polecat/nux conflicts with main
+// under a three-way merge but rebases cleanly: the branch carries a
+// cherry-pick of a commit that already landed on main, followed by a change
+// on top of it.
+func newRebaseTestEngineer(t *testing.T) (*Engineer, string) {
+	t.Helper()
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-b", "main")
+	runGit(t, dir, "config", "user.email", "test@test.com")
+	runGit(t, dir, "config", "user.name", "Test User")
+
+	file := filepath.Join(dir, "version.txt")
+	write := func(content string) {
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("v1\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "initial")
+
+	runGit(t, dir, "checkout", "-b", "polecat/nux")
+	runGit(t, dir, "checkout", "main")
+	write("v2\n")
+	runGit(t, dir, "commit", "-am", "bump to v2")
+	bump := runGit(t, dir, "rev-parse", "HEAD")
+
+	runGit(t, dir, "checkout", "polecat/nux")
+	runGit(t, dir, "cherry-pick", "-x", bump)
+	write("v3\n")
+	runGit(t, dir, "commit", "-am", "bump to v3")
+	runGit(t, dir, "checkout", "main")
+
+	e := &Engineer{
+		rig:     &rig.Rig{Name: "test-rig", Path: dir},
+		git:     git.NewGit(dir),
+		config:  DefaultMergeQueueConfig(),
+		workDir: dir,
+		output:  &bytes.Buffer{},
+	}
+	return e, dir
+}
+
+func TestEngineer_AutoRebase_ResolvesConflict(t *testing.T) {
+	e, dir := newRebaseTestEngineer(t)
+	e.config.TestCommand = "grep -q v3 version.txt"
+
+	conflicts, err := e.git.CheckConflicts("polecat/nux", "main")
+	if err != nil {
+		t.Fatalf("CheckConflicts: %v", err)
+	}
+	if len(conflicts) == 0 {
+		t.Fatal("expected a merge conflict before rebasing")
+	}
+
+	result := e.autoRebase(context.Background(), &MRInfo{Branch: "polecat/nux", Target: "main"})
+	if !result.Success {
+		t.Fatalf("autoRebase failed: %s", result.Error)
+	}
+
+	// Branch now sits directly on top of main
+	runGit(t, dir, "merge-base", "--is-ancestor", "main", "polecat/nux")
+	conflicts, err = e.git.CheckConflicts("polecat/nux", "main")
+	if err != nil {
+		t.Fatalf("CheckConflicts after rebase: %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("expected no conflicts after rebase, got %v", conflicts)
+	}
+
+	// Scratch worktree is cleaned up
+	if wts := runGit(t, dir, "worktree", "list"); strings.Count(wts, "\n") != 0 {
+		t.Errorf("expected only the main worktree, got:\n%s", wts)
+	}
+}
+
+func TestEngineer_AutoRebase_TestsFail(t *testing.T) {
+	e, dir := newRebaseTestEngineer(t)
+	e.config.TestCommand = "false"
+	before := runGit(t, dir, "rev-parse", "polecat/nux")
+
+	result := e.autoRebase(context.Background(), &MRInfo{Branch: "polecat/nux", Target: "main"})
+	if result.Success || !result.TestsFailed {
+		t.Fatalf("expected TestsFailed, got %+v", result)
+	}
+	if result.Conflict {
+		t.Error("test failure should not be reported as a conflict")
+	}
+	if after := runGit(t, dir, "rev-parse", "polecat/nux"); after != before {
+		t.Errorf("branch moved despite failing tests: %s -> %s", before, after)
+	}
+}
+
+func TestEngineer_AutoRebase_RealConflict(t *testing.T) {
+	e, dir := newRebaseTestEngineer(t)
+	// Diverge main so the cherry-picked commit no longer applies cleanly
+	if err := os.WriteFile(filepath.Join(dir, "version.txt"), []byte("v9\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "commit", "-am", "bump to v9")
+
+	result := e.autoRebase(context.Background(), &MRInfo{Branch: "polecat/nux", Target: "main"})
+	if result.Success || !result.Conflict {
+		t.Fatalf("expected Conflict, got %+v", result)
+	}
+}
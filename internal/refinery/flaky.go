@@ -0,0 +1,304 @@
+package refinery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// defaultFlakyThreshold is how many times a test has to flake before the
+// refinery files a bead for it.
+const defaultFlakyThreshold = 3
+
+// maxFlakyMRs caps how many affected MRs a ledger entry remembers.
+const maxFlakyMRs = 20
+
+// flakyLedgerMutex serializes ledger updates within a process (train cars
+// run their checks in parallel); the file lock covers other processes.
+var flakyLedgerMutex sync.Mutex
+
+// FlakyTest is a ledger entry: a test that failed and then passed on retry.
+// An entry with no Test is a check that flaked without a test report.
+type FlakyTest struct {
+	Check     string    `json:"check"`
+	Package   string    `json:"package,omitempty"`
+	Test      string    `json:"test,omitempty"`
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	MRs       []string  `json:"mrs,omitempty"`  // Most recent last
+	Bead      string    `json:"bead,omitempty"` // Bead filed once the test became chronic
+}
+
+// Name describes the flaky test for output.
+func (f *FlakyTest) Name() string {
+	if f.Test == "" {
+		return "check " + f.Check
+	}
+	return TestResult{Package: f.Package, Name: f.Test}.ID()
+}
+
+// FlakyLedger tracks the flaky tests of a rig's merge queue.
+type FlakyLedger struct {
+	Tests map[string]*FlakyTest `json:"tests"`
+}
+
+// FlakyLedgerPath returns the path of a rig's flaky test ledger.
+func FlakyLedgerPath(rigPath string) string {
+	return filepath.Join(rigPath, constants.DirRuntime, "flaky-tests.json")
+}
+
+// LoadFlakyLedger reads a rig's flaky test ledger. A missing ledger is empty.
+func LoadFlakyLedger(rigPath string) (*FlakyLedger, error) {
+	ledger := &FlakyLedger{Tests: make(map[string]*FlakyTest)}
+	data, err := os.ReadFile(FlakyLedgerPath(rigPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ledger, nil
+		}
+		return nil, fmt.Errorf("reading flaky test ledger: %w", err)
+	}
+	if err := json.Unmarshal(data, ledger); err != nil {
+		return nil, fmt.Errorf("parsing flaky test ledger: %w", err)
+	}
+	if ledger.Tests == nil {
+		ledger.Tests = make(map[string]*FlakyTest)
+	}
+	return ledger, nil
+}
+
+// Sorted returns the ledger's entries, most frequent first.
+func (l *FlakyLedger) Sorted() []*FlakyTest {
+	tests := make([]*FlakyTest, 0, len(l.Tests))
+	for _, t := range l.Tests {
+		tests = append(tests, t)
+	}
+	sort.Slice(tests, func(i, j int) bool {
+		if tests[i].Count != tests[j].Count {
+			return tests[i].Count > tests[j].Count
+		}
+		return tests[i].LastSeen.After(tests[j].LastSeen)
+	})
+	return tests
+}
+
+// flakyKey identifies a test in the ledger.
+func flakyKey(check string, t TestResult) string {
+	if t.Name == "" {
+		return check
+	}
+	return check + " " + t.ID()
+}
+
+// updateFlakyLedger applies fn to a rig's ledger under its lock and saves
+// the result.
+func updateFlakyLedger(rigPath string, fn func(*FlakyLedger)) error {
+	path := FlakyLedgerPath(rigPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating runtime dir: %w", err)
+	}
+
+	flakyLedgerMutex.Lock()
+	defer flakyLedgerMutex.Unlock()
+
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking flaky test ledger: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	ledger, err := LoadFlakyLedger(rigPath)
+	if err != nil {
+		return err
+	}
+	fn(ledger)
+
+	data, err := json.MarshalIndent(ledger, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding flaky test ledger: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing flaky test ledger: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// ForgetFlakyTest removes an entry from a rig's ledger, e.g. once a test
+// has been deleted.
+func ForgetFlakyTest(rigPath, key string) error {
+	found := false
+	err := updateFlakyLedger(rigPath, func(l *FlakyLedger) {
+		_, found = l.Tests[key]
+		delete(l.Tests, key)
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("no flaky test %q in the ledger", key)
+	}
+	return nil
+}
+
+// flakyObservation is a test that flaked in one check run.
+type flakyObservation struct {
+	check string
+	test  TestResult
+}
+
+// recordFlakes adds the tests that flaked in a run of mr's checks to the
+// rig's ledger, and files a bead for each test that has now flaked
+// FlakyThreshold times. bd runs outside the ledger lock, so a slow bd
+// doesn't hold up the other refineries' checks.
+func (e *Engineer) recordFlakes(mr *MRInfo, flakes []flakyObservation) {
+	if len(flakes) == 0 || e.rig == nil || e.rig.Path == "" {
+		return
+	}
+	mrRef := ""
+	if mr != nil {
+		mrRef = mr.ID
+		if mrRef == "" {
+			mrRef = mr.Branch
+		}
+	}
+
+	// Beads of these tests that were closed, i.e. fixed
+	closed := make(map[string]bool)
+	if ledger, err := LoadFlakyLedger(e.rig.Path); err == nil {
+		for _, f := range flakes {
+			if entry := ledger.Tests[flakyKey(f.check, f.test)]; entry != nil && entry.Bead != "" {
+				closed[entry.Bead] = e.beadClosed(entry.Bead)
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	chronic := make(map[string]FlakyTest)
+	err := updateFlakyLedger(e.rig.Path, func(l *FlakyLedger) {
+		for _, f := range flakes {
+			key := flakyKey(f.check, f.test)
+			entry := l.Tests[key]
+			if entry != nil && entry.Bead != "" && closed[entry.Bead] {
+				// The bead was fixed: count the test afresh
+				entry = nil
+			}
+			if entry == nil {
+				entry = &FlakyTest{Check: f.check, Package: f.test.Package, Test: f.test.Name, FirstSeen: now}
+				l.Tests[key] = entry
+			}
+			entry.Count++
+			entry.LastSeen = now
+			if mrRef != "" && (len(entry.MRs) == 0 || entry.MRs[len(entry.MRs)-1] != mrRef) {
+				entry.MRs = append(entry.MRs, mrRef)
+				if len(entry.MRs) > maxFlakyMRs {
+					entry.MRs = entry.MRs[len(entry.MRs)-maxFlakyMRs:]
+				}
+			}
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Flaky: %s failed then passed on retry (seen %d times)\n", entry.Name(), entry.Count)
+
+			if entry.Bead == "" && e.config.FlakyThreshold > 0 && entry.Count >= e.config.FlakyThreshold {
+				chronic[key] = *entry
+			}
+		}
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to update flaky test ledger: %v\n", err)
+		return
+	}
+
+	filed := make(map[string]string)
+	for key, entry := range chronic {
+		id, err := e.fileFlakyBead(&entry)
+		if err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to file bead for flaky %s: %v\n", entry.Name(), err)
+			continue
+		}
+		if id != "" {
+			filed[key] = id
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Filed %s for chronic flaky %s\n", id, entry.Name())
+		}
+	}
+	if len(filed) == 0 {
+		return
+	}
+	err = updateFlakyLedger(e.rig.Path, func(l *FlakyLedger) {
+		for key, id := range filed {
+			if entry := l.Tests[key]; entry != nil && entry.Bead == "" {
+				entry.Bead = id
+			}
+		}
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to record flaky test beads: %v\n", err)
+	}
+}
+
+// fileFlakyBead files a bug for a chronic flaky test.
+func (e *Engineer) fileFlakyBead(f *FlakyTest) (string, error) {
+	if e.beads == nil {
+		return "", nil
+	}
+	var desc strings.Builder
+	fmt.Fprintf(&desc, "%s in check %s has failed and then passed on retry %d times in the merge queue.\n\n",
+		f.Name(), f.Check, f.Count)
+	fmt.Fprintf(&desc, "First seen: %s\nLast seen: %s\n", f.FirstSeen.Format(time.RFC3339), f.LastSeen.Format(time.RFC3339))
+	if len(f.MRs) > 0 {
+		fmt.Fprintf(&desc, "MRs affected: %s\n", strings.Join(f.MRs, ", "))
+	}
+	desc.WriteString("\nEvery flake costs the queue a retry. Fix the test and close this bead;\n")
+	desc.WriteString("if it flakes again the refinery starts counting afresh.\n")
+	if e.config.QuarantineFlaky {
+		desc.WriteString("\nThe test is quarantined: while this bead is open its failures don't block merges.\n")
+	}
+
+	issue, err := e.beads.Create(beads.CreateOptions{
+		Title:       "Flaky test: " + f.Name(),
+		Type:        "bug",
+		Priority:    2,
+		Description: desc.String(),
+		Actor:       e.rig.Name + "/refinery",
+	})
+	if err != nil {
+		return "", err
+	}
+	return issue.ID, nil
+}
+
+// beadClosed reports whether a bead is known to be closed.
+func (e *Engineer) beadClosed(id string) bool {
+	if e.beads == nil {
+		return false
+	}
+	issue, err := e.beads.Show(id)
+	return err == nil && issue.Status == "closed"
+}
+
+// quarantined returns the failed tests of a check that are chronic flakes
+// with an open bead, which don't block merges when QuarantineFlaky is set.
+// It returns nil unless every failure is quarantined.
+func (e *Engineer) quarantined(check string, failed []TestResult) []TestResult {
+	if !e.config.QuarantineFlaky || !onlyTestFailures(failed) || e.rig == nil || e.rig.Path == "" {
+		return nil
+	}
+	ledger, err := LoadFlakyLedger(e.rig.Path)
+	if err != nil {
+		return nil
+	}
+	for _, t := range failed {
+		entry := ledger.Tests[flakyKey(check, t)]
+		if entry == nil || entry.Bead == "" || e.beadClosed(entry.Bead) {
+			return nil
+		}
+	}
+	return failed
+}
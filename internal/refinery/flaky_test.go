@@ -0,0 +1,273 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestParseGoTestJSON(t *testing.T) {
+	output := `{"Action":"run","Package":"example.com/a","Test":"TestOK"}
+{"Action":"output","Package":"example.com/a","Test":"TestOK","Output":"=== RUN   TestOK\n"}
+{"Action":"pass","Package":"example.com/a","Test":"TestOK"}
+{"Action":"output","Package":"example.com/a","Test":"TestBad/sub","Output":"    a_test.go:9: boom\n"}
+{"Action":"fail","Package":"example.com/a","Test":"TestBad/sub"}
+{"Action":"fail","Package":"example.com/a","Test":"TestBad"}
+{"Action":"fail","Package":"example.com/a"}
+# example.com/b
+b.go:3:1: syntax error
+{"Action":"fail","Package":"example.com/b"}
+{"Action":"skip","Package":"example.com/c","Test":"TestSkip"}
+`
+	results, text := parseGoTestJSON([]byte(output))
+	want := []TestResult{
+		{"example.com/a", "TestOK", testPassed},
+		{"example.com/a", "TestBad/sub", testFailed},
+		{"example.com/a", "TestBad", testFailed},
+		{"example.com/b", "", testFailed},
+		{"example.com/c", "TestSkip", testSkipped},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("results = %+v", results)
+	}
+	if text != "=== RUN   TestOK\n    a_test.go:9: boom\n# example.com/b\nb.go:3:1: syntax error\n" {
+		t.Errorf("text = %q", text)
+	}
+	if onlyTestFailures(failedTests(results)) {
+		t.Error("a package failure must not count as test failures only")
+	}
+}
+
+func TestParseJUnit(t *testing.T) {
+	report := `<?xml version="1.0"?>
+<testsuites>
+  <testsuite name="api">
+    <testcase classname="api.Users" name="creates"/>
+    <testcase classname="api.Users" name="deletes"><failure message="expected 204">trace</failure></testcase>
+    <testcase name="pending"><skipped/></testcase>
+  </testsuite>
+</testsuites>`
+	results, err := parseJUnit([]byte(report))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TestResult{
+		{"api.Users", "creates", testPassed},
+		{"api.Users", "deletes", testFailed},
+		{"api", "pending", testSkipped},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("results = %+v", results)
+	}
+	if _, err := parseJUnit([]byte("<testsuite")); err == nil {
+		t.Error("expected an error for a truncated report")
+	}
+}
+
+func TestRenderRetryCommand(t *testing.T) {
+	failed := []TestResult{
+		{"example.com/b", "TestMerge/conflict", testFailed},
+		{"example.com/a", "TestParse", testFailed},
+		{"example.com/b", "TestMerge/clean", testFailed},
+	}
+	got, err := renderRetryCommand("go test -json -run '{{.Run}}' {{.Packages}}", failed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "go test -json -run '^(TestMerge|TestParse)$' example.com/a example.com/b"; got != want {
+		t.Errorf("renderRetryCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestEngineer_LoadConfig_FlakyChecks(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   string
+		wantErr string
+	}{
+		{"valid", `{"name": "unit", "command": "go test -json ./...", "format": "go-test-json", "retry_command": "go test -json -run '{{.Run}}' {{.Packages}}"}`, ""},
+		{"unknown format", `{"name": "unit", "command": "true", "format": "tap"}`, "invalid format"},
+		{"junit without report", `{"name": "unit", "command": "true", "format": "junit"}`, "needs a report"},
+		{"retry without format", `{"name": "unit", "command": "true", "retry_command": "true"}`, "needs a format"},
+		{"bad retry template", `{"name": "unit", "command": "true", "format": "go-test-json", "retry_command": "{{.Run"}`, "invalid retry_command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			data := `{"type": "rig", "merge_queue": {"flaky_threshold": 5, "quarantine_flaky": true, "checks": [` + tt.check + `]}}`
+			if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			e := NewEngineer(&rig.Rig{Name: "test-rig", Path: dir})
+			err := e.LoadConfig()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			cfg := e.Config()
+			if cfg.FlakyThreshold != 5 || !cfg.QuarantineFlaky || cfg.Checks[0].Format != ReportGoTestJSON || cfg.Checks[0].RetryCommand == "" {
+				t.Errorf("config = %+v", cfg)
+			}
+		})
+	}
+}
+
+// flakyCheckCommand fails TestFlaky on its first run and passes after,
+// reporting in go test -json.
+const flakyCheckCommand = `if [ -f ran ]; then
+  echo '{"Action":"pass","Package":"p","Test":"TestFlaky"}'
+  echo '{"Action":"pass","Package":"p","Test":"TestSolid"}'
+else
+  touch ran
+  printf '%s\n' '{"Action":"output","Package":"p","Test":"TestFlaky","Output":"    p_test.go:7: timing\n"}'
+  echo '{"Action":"fail","Package":"p","Test":"TestFlaky"}'
+  echo '{"Action":"pass","Package":"p","Test":"TestSolid"}'
+  exit 1
+fi`
+
+func TestEngineer_RunChecks_RecordsFlakes(t *testing.T) {
+	e, dir := newTrainTestEngineer(t, map[string][2]string{"polecat/nux": {"nux.txt", "nux\n"}})
+	e.config.RetryFlakyTests = 1
+	e.config.FlakyThreshold = 2
+	e.config.Checks = []Check{{Name: "unit", Command: flakyCheckCommand, Format: ReportGoTestJSON}}
+
+	for i, id := range []string{"gt-mr1", "gt-mr2"} {
+		_ = os.Remove(filepath.Join(dir, "ran"))
+		result := e.runChecksIn(context.Background(), dir, "main", "polecat/nux", &MRInfo{ID: id})
+		if !result.Success || result.Checks[0].Detail != "flaky: p.TestFlaky" {
+			t.Fatalf("run %d: result = %+v", i, result)
+		}
+	}
+
+	ledger, err := LoadFlakyLedger(e.rig.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := ledger.Tests["unit p.TestFlaky"]
+	if len(ledger.Tests) != 1 || entry == nil || entry.Count != 2 || !reflect.DeepEqual(entry.MRs, []string{"gt-mr1", "gt-mr2"}) {
+		t.Fatalf("ledger = %+v", ledger.Tests)
+	}
+	if entry.LastSeen.Before(entry.FirstSeen) {
+		t.Errorf("entry = %+v", entry)
+	}
+
+	// A check that passes on retry without a report is itself the flake
+	e.config.Checks = []Check{{Name: "lint", Command: "test -f ran2 || { touch ran2; exit 1; }"}}
+	result := e.runChecksIn(context.Background(), dir, "main", "polecat/nux", &MRInfo{ID: "gt-mr3"})
+	if !result.Success || result.Checks[0].Detail != "flaky: passed on retry" {
+		t.Fatalf("result = %+v", result)
+	}
+	if ledger, _ := LoadFlakyLedger(e.rig.Path); ledger.Tests["lint"] == nil || ledger.Tests["lint"].Name() != "check lint" {
+		t.Errorf("ledger = %+v", ledger.Tests)
+	}
+
+	if err := ForgetFlakyTest(e.rig.Path, "lint"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ForgetFlakyTest(e.rig.Path, "lint"); err == nil {
+		t.Error("expected an error forgetting an unknown entry")
+	}
+	if ledger, _ := LoadFlakyLedger(e.rig.Path); len(ledger.Sorted()) != 1 {
+		t.Errorf("ledger after forget = %+v", ledger.Tests)
+	}
+}
+
+func TestEngineer_RunCheck_RetriesFailedTestsOnly(t *testing.T) {
+	e, dir := newTrainTestEngineer(t, map[string][2]string{"polecat/nux": {"nux.txt", "nux\n"}})
+	out := &bytes.Buffer{}
+	e.output = out
+	e.config.RetryFlakyTests = 1
+	c := Check{
+		Name:         "unit",
+		Command:      flakyCheckCommand,
+		Format:       ReportGoTestJSON,
+		RetryCommand: `echo '{{.Run}} {{.Packages}}' > retried; echo '{"Action":"pass","Package":"p","Test":"TestFlaky"}'`,
+	}
+
+	result, flaky := e.runCheck(context.Background(), dir, c, "")
+	if result.Status != beads.MRCheckPassed || len(flaky) != 1 || flaky[0].Name != "TestFlaky" {
+		t.Fatalf("runCheck = %+v, %+v", result, flaky)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "retried")); string(data) != "^(TestFlaky)$ p\n" {
+		t.Errorf("retry ran with %q", data)
+	}
+	if !strings.Contains(out.String(), "Retrying 1 failed test(s) of check unit (attempt 2/2)") {
+		t.Errorf("output:\n%s", out)
+	}
+}
+
+func TestEngineer_RunCheck_Quarantine(t *testing.T) {
+	e, dir := newTrainTestEngineer(t, map[string][2]string{"polecat/nux": {"nux.txt", "nux\n"}})
+	e.config.QuarantineFlaky = true
+	e.config.RetryFlakyTests = 0
+	c := Check{Name: "unit", Command: flakyCheckCommand, Format: ReportGoTestJSON}
+
+	// Not quarantined until the test has a bead
+	result, _ := e.runCheck(context.Background(), dir, c, "")
+	if result.Status != beads.MRCheckFailed || !reflect.DeepEqual(result.LogTail, []string{"    p_test.go:7: timing"}) {
+		t.Fatalf("result = %+v", result)
+	}
+
+	err := updateFlakyLedger(e.rig.Path, func(l *FlakyLedger) {
+		l.Tests["unit p.TestFlaky"] = &FlakyTest{Check: "unit", Package: "p", Test: "TestFlaky", Count: 3, Bead: "gt-flake1"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = os.Remove(filepath.Join(dir, "ran"))
+	result, _ = e.runCheck(context.Background(), dir, c, "")
+	if result.Status != beads.MRCheckPassed || result.Detail != "quarantined failures: p.TestFlaky" {
+		t.Fatalf("result = %+v", result)
+	}
+
+	e.config.QuarantineFlaky = false
+	_ = os.Remove(filepath.Join(dir, "ran"))
+	if result, _ = e.runCheck(context.Background(), dir, c, ""); result.Status != beads.MRCheckFailed {
+		t.Fatalf("result without quarantine = %+v", result)
+	}
+}
+
+func TestEngineer_RecordFlakes_FilesBeadOutsideLock(t *testing.T) {
+	root := t.TempDir()
+	e := &Engineer{
+		rig:    &rig.Rig{Name: "test-rig", Path: root},
+		beads:  beads.New(root),
+		config: DefaultMergeQueueConfig(),
+		output: &bytes.Buffer{},
+	}
+	e.config.FlakyThreshold = 2
+
+	// bd fails the create if the ledger is still locked
+	bin := t.TempDir()
+	lockPath := FlakyLedgerPath(root) + ".lock"
+	script := "#!/bin/sh\nflock -n " + lockPath + " true || { echo ledger locked >&2; exit 1; }\n" +
+		"echo '{\"id\":\"gt-flake1\",\"title\":\"Flaky test\"}'\n"
+	if err := os.WriteFile(filepath.Join(bin, "bd"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	flake := []flakyObservation{{check: "unit", test: TestResult{Package: "p", Name: "TestFlaky"}}}
+	e.recordFlakes(&MRInfo{ID: "gt-mr1"}, flake)
+	e.recordFlakes(&MRInfo{ID: "gt-mr2"}, flake)
+
+	ledger, err := LoadFlakyLedger(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry := ledger.Tests["unit p.TestFlaky"]; entry == nil || entry.Count != 2 || entry.Bead != "gt-flake1" {
+		t.Fatalf("ledger = %+v\n%s", ledger.Tests, e.output)
+	}
+}
@@ -223,20 +223,18 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 		return fmt.Errorf("saving state: %w", err)
 	}
 
-	// Wait for Claude to start and show its prompt
-	// WaitForRuntimeReady waits for the runtime to be ready
-	if err := t.WaitForRuntimeReady(sessionID, runtimeConfig, constants.ClaudeStartTimeout); err != nil {
+	// Wait for the agent to start and show its prompt, accepting startup
+	// dialogs (e.g. the bypass permissions warning) on the way
+	adapter := runtime.ForRole("refinery", townRoot, m.rig.Path, agentOverride)
+	if err := runtime.WaitReady(t, adapter, sessionID, constants.ClaudeStartTimeout); err != nil {
 		// Log warning but continue - the session may still be viable
 		// Don't kill the session; let it continue initializing
 		fmt.Fprintf(os.Stderr, "warning: %v (continuing with startup)\n", err)
 	}
 
-	// Accept bypass permissions warning dialog if it appears.
-	_ = t.AcceptBypassPermissionsWarning(sessionID)
-
 	// Wait for runtime to be fully ready
 	runtime.SleepForReadyDelay(runtimeConfig)
-	_ = runtime.RunStartupFallback(t, sessionID, "refinery", runtimeConfig)
+	_ = runtime.RunStartupFallback(t, adapter, sessionID, "refinery", runtimeConfig)
 
 	// Inject startup nudge for predecessor discovery via /resume
 	address := fmt.Sprintf("%s/refinery", m.rig.Name)
@@ -248,11 +246,11 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 
 	// GUPP: Gas Town Universal Propulsion Principle
 	// Send the propulsion nudge to trigger autonomous patrol execution.
-	// Wait for Claude UI to be ready before sending propulsion nudge.
-	if err := t.WaitForClaudeUI(sessionID, 30*time.Second); err != nil {
-		return fmt.Errorf("waiting for Claude UI: %w", err)
+	// Wait for the agent to be ready before sending propulsion nudge.
+	if err := runtime.WaitReady(t, adapter, sessionID, 30*time.Second); err != nil {
+		return fmt.Errorf("waiting for agent UI: %w", err)
 	}
-	_ = t.NudgeSession(sessionID, session.PropulsionNudgeForRole("refinery", refineryRigDir)) // Non-fatal
+	_ = adapter.InjectPrompt(t, sessionID, session.PropulsionNudgeForRole("refinery", refineryRigDir)) // Non-fatal
 
 	return nil
 }
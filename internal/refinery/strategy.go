@@ -0,0 +1,210 @@
+package refinery
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// Merge strategies for MergeQueueConfig.MergeStrategy.
+const (
+	// MergeStrategyMerge lands a branch with a merge commit (git merge --no-ff).
+	MergeStrategyMerge = "merge"
+
+	// MergeStrategySquash lands a branch as a single commit on the target.
+	MergeStrategySquash = "squash"
+
+	// MergeStrategyRebaseFF rebases the branch onto the target and
+	// fast-forwards the target to it, keeping the branch's own commits.
+	MergeStrategyRebaseFF = "rebase-ff"
+)
+
+// defaultAgentEmailDomain is the email domain of agent identities when the
+// town settings don't set agent_email_domain (see gt commit).
+const defaultAgentEmailDomain = "gastown.local"
+
+// defaultSquashMessage is the commit message template for squash merges.
+const defaultSquashMessage = `{{if .Title}}{{.Title}}{{else}}Merge {{.Branch}} into {{.Target}}{{end}}{{with .SourceIssue}} ({{.}}){{end}}`
+
+// CommitMessageData is what a commit_message_template can refer to.
+type CommitMessageData struct {
+	MR          string // MR bead ID
+	SourceIssue string // Bead ID of the work being merged
+	Title       string // Title of the source issue
+	Description string // Description of the source issue
+	Branch      string // Source branch
+	Target      string // Target branch
+	Worker      string // Polecat that did the work
+	Rig         string // Rig name
+}
+
+// validMergeStrategy reports whether s names a merge strategy.
+func validMergeStrategy(s string) bool {
+	switch s {
+	case MergeStrategyMerge, MergeStrategySquash, MergeStrategyRebaseFF:
+		return true
+	}
+	return false
+}
+
+// parseCommitTemplate parses a commit message template. Unknown fields are
+// an error rather than an empty string.
+func parseCommitTemplate(text string) (*template.Template, error) {
+	return template.New("commit_message").Option("missingkey=error").Parse(text)
+}
+
+// commitMessage returns the message of the commit that lands mr with the
+// configured strategy: the rendered template (or the strategy's default),
+// followed by a Co-authored-by trailer for the polecat that did the work.
+func (e *Engineer) commitMessage(mr *MRInfo) (string, error) {
+	data := CommitMessageData{
+		MR:          mr.ID,
+		SourceIssue: mr.SourceIssue,
+		Branch:      mr.Branch,
+		Target:      mr.Target,
+		Worker:      mr.Worker,
+		Rig:         mr.Rig,
+	}
+	if data.Rig == "" && e.rig != nil {
+		data.Rig = e.rig.Name
+	}
+	if mr.SourceIssue != "" && e.beads != nil {
+		if issue, err := e.beads.Show(mr.SourceIssue); err == nil {
+			data.Title = issue.Title
+			data.Description = issue.Description
+		}
+	}
+
+	var msg string
+	switch {
+	case e.config.CommitMessageTemplate != "":
+		rendered, err := renderCommitMessage(e.config.CommitMessageTemplate, data)
+		if err != nil {
+			return "", err
+		}
+		msg = rendered
+	case e.config.MergeStrategy == MergeStrategySquash:
+		rendered, err := renderCommitMessage(defaultSquashMessage, data)
+		if err != nil {
+			return "", err
+		}
+		msg = rendered
+	default:
+		msg = mergeMessage(mr.Branch, mr.Target, mr.SourceIssue)
+	}
+
+	if e.config.CoAuthorTrailers {
+		if name := polecatIdentity(data.Rig, mr.Worker); name != "" {
+			msg = addCoAuthor(msg, name, identityEmail(name, e.agentEmailDomain()))
+		}
+	}
+	return msg, nil
+}
+
+// renderCommitMessage executes a commit message template.
+func renderCommitMessage(text string, data CommitMessageData) (string, error) {
+	tmpl, err := parseCommitTemplate(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid commit_message_template: %w", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("rendering commit message: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// polecatIdentity returns the agent address of the polecat that worked on
+// an MR. Worker is usually the bare polecat name from the branch.
+func polecatIdentity(rigName, worker string) string {
+	if worker == "" || strings.Contains(worker, "/") || rigName == "" {
+		return worker
+	}
+	return rigName + "/polecats/" + worker
+}
+
+// identityEmail converts an agent address to the git email the agent
+// commits with: "gastown/polecats/nux" → "gastown.polecats.nux@domain".
+func identityEmail(identity, domain string) string {
+	identity = strings.TrimSuffix(identity, "/")
+	return strings.ReplaceAll(identity, "/", ".") + "@" + domain
+}
+
+// addCoAuthor appends a Co-authored-by trailer to msg unless it already
+// credits that email.
+func addCoAuthor(msg, name, email string) string {
+	trailer := fmt.Sprintf("Co-authored-by: %s <%s>", name, email)
+	if strings.Contains(msg, "<"+email+">") {
+		return msg
+	}
+	lines := strings.Split(msg, "\n")
+	if last := lines[len(lines)-1]; len(lines) > 1 && strings.HasPrefix(last, "Co-authored-by:") {
+		// Join an existing trailer block
+		return msg + "\n" + trailer
+	}
+	return msg + "\n\n" + trailer
+}
+
+// agentEmailDomain returns the town's agent email domain.
+func (e *Engineer) agentEmailDomain() string {
+	if e.rig == nil || e.rig.Path == "" {
+		return defaultAgentEmailDomain
+	}
+	townRoot := filepath.Dir(e.rig.Path)
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil || settings.AgentEmailDomain == "" {
+		return defaultAgentEmailDomain
+	}
+	return settings.AgentEmailDomain
+}
+
+// signing returns g set up to sign its commits when a signing key is
+// configured.
+func (e *Engineer) signing(g *git.Git) *git.Git {
+	if e.config.SigningKey == "" {
+		return g
+	}
+	return g.WithSigningKey(e.config.SigningKey)
+}
+
+// land applies branch to the branch checked out in g with the configured
+// strategy. On a conflict the working tree is cleaned up and the
+// conflicting files are returned with the error.
+func (e *Engineer) land(g *git.Git, branch, message string) ([]string, error) {
+	g = e.signing(g)
+	switch e.config.MergeStrategy {
+	case MergeStrategySquash:
+		if err := g.MergeSquash(branch); err != nil {
+			conflicts, _ := g.GetConflictingFiles()
+			_ = g.ResetHard("HEAD")
+			return conflicts, err
+		}
+		if err := g.Commit(message); err != nil {
+			_ = g.ResetHard("HEAD")
+			return nil, fmt.Errorf("committing squash of %s: %w", branch, err)
+		}
+		return nil, nil
+	case MergeStrategyRebaseFF:
+		// The branch has already been rebased onto the target
+		return nil, g.MergeFFOnly(branch)
+	default:
+		if err := g.MergeNoFF(branch, message); err != nil {
+			conflicts, _ := g.GetConflictingFiles()
+			if len(conflicts) > 0 {
+				_ = g.AbortMerge()
+			}
+			return conflicts, err
+		}
+		return nil, nil
+	}
+}
+
+// firstLine returns the subject line of a commit message.
+func firstLine(msg string) string {
+	subject, _, _ := strings.Cut(msg, "\n")
+	return subject
+}
@@ -0,0 +1,187 @@
+package refinery
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestEngineer_LoadConfig_MergeStrategy(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"squash", `{"merge_strategy": "squash", "commit_message_template": "{{.Title}} ({{.SourceIssue}})", "co_author_trailers": false, "signing_key": "ABCD1234"}`, ""},
+		{"unknown strategy", `{"merge_strategy": "octopus"}`, "invalid merge_strategy"},
+		{"bad template", `{"commit_message_template": "{{.Title"}`, "invalid commit_message_template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			data := `{"type": "rig", "merge_queue": ` + tt.config + `}`
+			if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			e := NewEngineer(&rig.Rig{Name: "test-rig", Path: dir})
+			err := e.LoadConfig()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			cfg := e.Config()
+			if cfg.MergeStrategy != MergeStrategySquash || cfg.CoAuthorTrailers || cfg.SigningKey != "ABCD1234" ||
+				cfg.CommitMessageTemplate != "{{.Title}} ({{.SourceIssue}})" {
+				t.Errorf("config = %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestCommitMessage(t *testing.T) {
+	town := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(town, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	settings := `{"type": "town-settings", "version": 1, "agent_email_domain": "agents.example.com"}`
+	if err := os.WriteFile(filepath.Join(town, "settings", "config.json"), []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e := &Engineer{
+		rig:    &rig.Rig{Name: "gastown", Path: filepath.Join(town, "gastown")},
+		config: DefaultMergeQueueConfig(),
+	}
+	mr := &MRInfo{ID: "gt-mr1", Branch: "polecat/nux/gt-1", Target: "main", SourceIssue: "gt-1", Worker: "nux"}
+	trailer := "\n\nCo-authored-by: gastown/polecats/nux <gastown.polecats.nux@agents.example.com>"
+
+	tests := []struct {
+		name     string
+		strategy string
+		template string
+		want     string
+	}{
+		{"merge default", MergeStrategyMerge, "", "Merge polecat/nux/gt-1 into main (gt-1)" + trailer},
+		{"squash default", MergeStrategySquash, "", "Merge polecat/nux/gt-1 into main (gt-1)" + trailer},
+		{"template", MergeStrategySquash, "{{.SourceIssue}}: work by {{.Worker}} in {{.Rig}}\n\nMR {{.MR}}", "gt-1: work by nux in gastown\n\nMR gt-mr1" + trailer},
+		{"existing trailer", MergeStrategyMerge, "{{.SourceIssue}}\n\nCo-authored-by: mayor <mayor@agents.example.com>",
+			"gt-1\n\nCo-authored-by: mayor <mayor@agents.example.com>\nCo-authored-by: gastown/polecats/nux <gastown.polecats.nux@agents.example.com>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e.config.MergeStrategy = tt.strategy
+			e.config.CommitMessageTemplate = tt.template
+			got, err := e.commitMessage(mr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("commitMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	e.config.CoAuthorTrailers = false
+	e.config.CommitMessageTemplate = "{{.Nope}}"
+	if _, err := e.commitMessage(mr); err == nil {
+		t.Error("expected an error for an unknown template field")
+	}
+	e.config.CommitMessageTemplate = "{{.Title}}"
+	if got, _ := e.commitMessage(&MRInfo{Worker: "gastown/crew/joe"}); got != "" {
+		t.Errorf("commitMessage() without trailers = %q", got)
+	}
+}
+
+// newStrategyTestEngineer creates a clone with polecat/nux one commit ahead
+// of where main was, and main one unrelated commit further along.
+func newStrategyTestEngineer(t *testing.T, strategy string) (*Engineer, string) {
+	t.Helper()
+	e, dir := newTrainTestEngineer(t, map[string][2]string{"polecat/nux": {"nux.txt", "nux\n"}})
+	if err := os.WriteFile(filepath.Join(dir, "main.txt"), []byte("main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "advance main")
+	runGit(t, dir, "push", "origin", "main")
+	e.config.MergeStrategy = strategy
+	e.config.TestCommand = ""
+	return e, dir
+}
+
+func TestEngineer_DoMerge_Strategies(t *testing.T) {
+	mr := &MRInfo{ID: "gt-mr1", Branch: "polecat/nux", Target: "main", SourceIssue: "gt-1", Worker: "nux"}
+
+	t.Run("merge", func(t *testing.T) {
+		e, dir := newStrategyTestEngineer(t, MergeStrategyMerge)
+		result := e.doMerge(context.Background(), mr)
+		if !result.Success {
+			t.Fatalf("doMerge: %s", result.Error)
+		}
+		if parents := strings.Fields(runGit(t, dir, "log", "-1", "--format=%P", "main")); len(parents) != 2 {
+			t.Errorf("merge commit has parents %v, want 2", parents)
+		}
+	})
+
+	t.Run("squash", func(t *testing.T) {
+		e, dir := newStrategyTestEngineer(t, MergeStrategySquash)
+		result := e.doMerge(context.Background(), mr)
+		if !result.Success {
+			t.Fatalf("doMerge: %s", result.Error)
+		}
+		if parents := strings.Fields(runGit(t, dir, "log", "-1", "--format=%P", "main")); len(parents) != 1 {
+			t.Errorf("squash commit has parents %v, want 1", parents)
+		}
+		msg := runGit(t, dir, "log", "-1", "--format=%B", "main")
+		if !strings.HasPrefix(msg, "Merge polecat/nux into main (gt-1)") || !strings.Contains(msg, "Co-authored-by: test-rig/polecats/nux <test-rig.polecats.nux@gastown.local>") {
+			t.Errorf("squash message = %q", msg)
+		}
+		runGit(t, dir, "cat-file", "-e", "main:nux.txt")
+		if origin := runGit(t, dir, "rev-parse", "origin/main"); origin != result.MergeCommit {
+			t.Errorf("origin/main = %s, want %s", origin, result.MergeCommit)
+		}
+	})
+
+	t.Run("rebase-ff", func(t *testing.T) {
+		e, dir := newStrategyTestEngineer(t, MergeStrategyRebaseFF)
+		result := e.doMerge(context.Background(), mr)
+		if !result.Success {
+			t.Fatalf("doMerge: %s", result.Error)
+		}
+		log := runGit(t, dir, "log", "--format=%s", "main")
+		if log != "work on polecat/nux\nadvance main\ninitial" {
+			t.Errorf("main history = %q, want linear", log)
+		}
+		if tip := runGit(t, dir, "rev-parse", "polecat/nux"); tip != result.MergeCommit {
+			t.Errorf("main = %s, want rebased branch %s", result.MergeCommit, tip)
+		}
+	})
+}
+
+func TestEngineer_DoMerge_SignsCommits(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	e, dir := newStrategyTestEngineer(t, MergeStrategySquash)
+	key := filepath.Join(t.TempDir(), "refinery_key")
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen: %v\n%s", err, out)
+	}
+	e.config.SigningKey = key + ".pub"
+
+	result := e.doMerge(context.Background(), &MRInfo{Branch: "polecat/nux", Target: "main"})
+	if !result.Success {
+		t.Fatalf("doMerge: %s", result.Error)
+	}
+	if commit := runGit(t, dir, "cat-file", "commit", "main"); !strings.Contains(commit, "gpgsig") {
+		t.Errorf("merged commit is not signed:\n%s", commit)
+	}
+}
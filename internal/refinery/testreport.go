@@ -0,0 +1,300 @@
+package refinery
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Test report formats for Check.Format.
+const (
+	// ReportGoTestJSON reads the check's output as go test -json events.
+	ReportGoTestJSON = "go-test-json"
+
+	// ReportJUnit reads a JUnit XML file the check writes to Check.Report.
+	ReportJUnit = "junit"
+)
+
+// Test outcomes in a TestResult.
+const (
+	testPassed  = "pass"
+	testFailed  = "fail"
+	testSkipped = "skip"
+)
+
+// TestResult is the outcome of one test in a check's report. A failed
+// result with no Name is a package that failed outside any test (e.g. a
+// build error).
+type TestResult struct {
+	Package string
+	Name    string
+	Status  string
+}
+
+// ID names the test for output and the flaky test ledger.
+func (t TestResult) ID() string {
+	switch {
+	case t.Package == "":
+		return t.Name
+	case t.Name == "":
+		return t.Package
+	}
+	return t.Package + "." + t.Name
+}
+
+// RetryCommandData is what a check's retry_command template can refer to.
+type RetryCommandData struct {
+	Run      string // go test -run pattern matching the failed tests, e.g. ^(TestA|TestB)$
+	Tests    string // Failed test names, space-separated
+	Packages string // Packages of the failed tests, space-separated
+}
+
+// validReportFormat reports whether f names a test report format.
+func validReportFormat(f string) bool {
+	switch f {
+	case "", ReportGoTestJSON, ReportJUnit:
+		return true
+	}
+	return false
+}
+
+// parseRetryTemplate parses a retry_command template.
+func parseRetryTemplate(text string) (*template.Template, error) {
+	return template.New("retry_command").Option("missingkey=error").Parse(text)
+}
+
+// testEvent is one line of go test -json output.
+type testEvent struct {
+	Action  string `json:"Action"`
+	Package string `json:"Package"`
+	Test    string `json:"Test"`
+	Output  string `json:"Output"`
+}
+
+// parseGoTestJSON reads go test -json output. It returns the final result
+// of every test, in the order they started, and the plain text output the
+// events carried. Lines that aren't events (build errors printed by the
+// go command) are kept in the text as they are.
+func parseGoTestJSON(output []byte) ([]TestResult, string) {
+	var text strings.Builder
+	index := make(map[string]int)
+	var results []TestResult
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var ev testEvent
+		if len(line) == 0 || line[0] != '{' || json.Unmarshal(line, &ev) != nil || ev.Action == "" {
+			text.Write(line)
+			text.WriteByte('\n')
+			continue
+		}
+		switch ev.Action {
+		case "output", "build-output":
+			text.WriteString(ev.Output)
+		case testPassed, testFailed, testSkipped:
+			// A package's own pass is implied by its tests; a package
+			// failure is kept because it may not belong to any test
+			if ev.Test == "" && ev.Action != testFailed {
+				continue
+			}
+			key := ev.Package + "\x00" + ev.Test
+			r := TestResult{Package: ev.Package, Name: ev.Test, Status: ev.Action}
+			if i, ok := index[key]; ok {
+				results[i] = r
+			} else {
+				index[key] = len(results)
+				results = append(results, r)
+			}
+		}
+	}
+	return dropExplainedPackageFailures(results), text.String()
+}
+
+// dropExplainedPackageFailures removes package failures that are accounted
+// for by a failed test in the same package, leaving only those that say
+// something else broke.
+func dropExplainedPackageFailures(results []TestResult) []TestResult {
+	failedIn := make(map[string]bool)
+	for _, r := range results {
+		if r.Name != "" && r.Status == testFailed {
+			failedIn[r.Package] = true
+		}
+	}
+	kept := results[:0]
+	for _, r := range results {
+		if r.Name == "" && failedIn[r.Package] {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept
+}
+
+// junitSuite is a <testsuite> or <testsuites> element.
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+// junitCase is a <testcase> element.
+type junitCase struct {
+	Name      string    `xml:"name,attr"`
+	Classname string    `xml:"classname,attr"`
+	Failure   *struct{} `xml:"failure"`
+	Error     *struct{} `xml:"error"`
+	Skipped   *struct{} `xml:"skipped"`
+}
+
+// parseJUnit reads a JUnit XML report, rooted at <testsuites> or a single
+// <testsuite>.
+func parseJUnit(data []byte) ([]TestResult, error) {
+	var root junitSuite
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parsing JUnit report: %w", err)
+	}
+	var results []TestResult
+	var walk func(s junitSuite)
+	walk = func(s junitSuite) {
+		for _, c := range s.Cases {
+			r := TestResult{Package: c.Classname, Name: c.Name, Status: testPassed}
+			if r.Package == "" {
+				r.Package = s.Name
+			}
+			switch {
+			case c.Failure != nil || c.Error != nil:
+				r.Status = testFailed
+			case c.Skipped != nil:
+				r.Status = testSkipped
+			}
+			results = append(results, r)
+		}
+		for _, child := range s.Suites {
+			walk(child)
+		}
+	}
+	walk(root)
+	return results, nil
+}
+
+// checkReport reads the test results of a check attempt from its output or
+// report file. It also returns the output to save and excerpt: go test
+// -json events are turned back into the text go test would have printed.
+// Results are nil when the check has no format or the report is missing.
+func checkReport(c Check, dir string, output []byte) ([]TestResult, string) {
+	switch c.Format {
+	case ReportGoTestJSON:
+		return parseGoTestJSON(output)
+	case ReportJUnit:
+		data, err := os.ReadFile(filepath.Join(dir, c.Dir, c.Report))
+		if err != nil {
+			return nil, string(output)
+		}
+		results, err := parseJUnit(data)
+		if err != nil {
+			return nil, string(output)
+		}
+		return results, string(output)
+	}
+	return nil, string(output)
+}
+
+// failedTests returns the failed results of a report.
+func failedTests(results []TestResult) []TestResult {
+	var failed []TestResult
+	for _, r := range results {
+		if r.Status == testFailed {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// onlyTestFailures reports whether every failure is a named test, so that
+// rerunning those tests covers everything that went wrong.
+func onlyTestFailures(failed []TestResult) bool {
+	if len(failed) == 0 {
+		return false
+	}
+	for _, r := range failed {
+		if r.Name == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// recoveredTests returns the tests that failed before and passed in a
+// later attempt. When the later attempt passed without reporting a test,
+// the test is taken to have passed too.
+func recoveredTests(before, now []TestResult, passed bool) []TestResult {
+	status := make(map[string]string, len(now))
+	for _, r := range now {
+		status[r.Package+"\x00"+r.Name] = r.Status
+	}
+	var recovered []TestResult
+	for _, r := range before {
+		if r.Name == "" {
+			continue
+		}
+		s, ok := status[r.Package+"\x00"+r.Name]
+		if s == testPassed || (!ok && passed) {
+			recovered = append(recovered, r)
+		}
+	}
+	return recovered
+}
+
+// renderRetryCommand renders a retry_command for the failed tests.
+func renderRetryCommand(text string, failed []TestResult) (string, error) {
+	tmpl, err := parseRetryTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var names, top, pkgs []string
+	seenTop := make(map[string]bool)
+	seenPkg := make(map[string]bool)
+	for _, r := range failed {
+		names = append(names, r.Name)
+		// -run matches subtests level by level, so rerun their parent
+		parent, _, _ := strings.Cut(r.Name, "/")
+		if !seenTop[parent] {
+			seenTop[parent] = true
+			top = append(top, regexp.QuoteMeta(parent))
+		}
+		if r.Package != "" && !seenPkg[r.Package] {
+			seenPkg[r.Package] = true
+			pkgs = append(pkgs, r.Package)
+		}
+	}
+	sort.Strings(pkgs)
+	data := RetryCommandData{
+		Run:      "^(" + strings.Join(top, "|") + ")$",
+		Tests:    strings.Join(names, " "),
+		Packages: strings.Join(pkgs, " "),
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// testNames lists test IDs for output.
+func testNames(tests []TestResult) string {
+	names := make([]string, len(tests))
+	for i, t := range tests {
+		names[i] = t.ID()
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,403 @@
+// Package refinery provides the merge queue processing agent.
+// This file contains the speculative merge train used when MaxConcurrent > 1.
+package refinery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// TrainCar is one MR in a speculative merge train.
+// Each car's Commit is the target branch plus every stacked car up to and
+// including this one, so a passing car proves its whole prefix is mergeable.
+type TrainCar struct {
+	MR     *MRInfo
+	Commit string        // Speculative merge commit for this prefix
+	Result ProcessResult // Outcome for this car's MR
+
+	dir string // Scratch worktree holding Commit
+}
+
+// TrainResult summarizes a merge train run.
+type TrainResult struct {
+	Target      string
+	MergeCommit string      // Commit the target was advanced to ("" if nothing landed)
+	Landed      []*TrainCar // Longest passing prefix, merged and pushed
+	Failed      []*TrainCar // Cars rejected on their own merits (conflict with target, failing tests)
+	Requeued    []*MRInfo   // MRs whose outcome is unknown and should be retried
+}
+
+// SelectTrain picks up to MaxConcurrent MRs for one train from a
+// priority-ordered list. All cars share the target of the first MR, since a
+// train can only advance one branch.
+func (e *Engineer) SelectTrain(mrs []*MRInfo) []*MRInfo {
+	limit := e.config.MaxConcurrent
+	if limit < 1 {
+		limit = 1
+	}
+	if len(mrs) == 0 {
+		return nil
+	}
+
+	target := mrs[0].Target
+	var cars []*MRInfo
+	for _, mr := range mrs {
+		if mr.Target != target {
+			continue
+		}
+		cars = append(cars, mr)
+		if len(cars) == limit {
+			break
+		}
+	}
+	return cars
+}
+
+// RunTrain runs the next train from the ready queue, for refineries with
+// MaxConcurrent > 1. The top MRs by score that SelectTrain picks are claimed
+// for workerID and processed with ProcessTrain. Landed MRs are then closed
+// and announced as in the serial flow, the failing car is handled like a
+// failed merge, and every MR that didn't land is released back to the
+// queue. The result is nil when no MRs are ready.
+func (e *Engineer) RunTrain(ctx context.Context, workerID string) (*TrainResult, error) {
+	ready, err := e.ListReadyMRs()
+	if err != nil {
+		return nil, fmt.Errorf("listing ready MRs: %w", err)
+	}
+	if len(ready) == 0 {
+		return nil, nil
+	}
+
+	// Highest score first, matching gt mq next
+	sort.SliceStable(ready, func(i, j int) bool {
+		return ready[i].Score() > ready[j].Score()
+	})
+
+	// Claim each car so parallel refinery workers don't double-process
+	var cars []*MRInfo
+	for _, mr := range e.SelectTrain(ready) {
+		if err := e.ClaimMR(mr.ID, workerID); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not claim %s: %v\n", mr.ID, err)
+			continue
+		}
+		cars = append(cars, mr)
+	}
+	if len(cars) == 0 {
+		return nil, fmt.Errorf("could not claim any MRs")
+	}
+
+	result := e.ProcessTrain(ctx, cars)
+
+	for _, car := range result.Landed {
+		e.HandleMRInfoSuccess(car.MR, car.Result)
+	}
+	for _, car := range result.Failed {
+		e.HandleMRInfoFailure(car.MR, car.Result)
+		e.releaseTrainMR(car.MR)
+	}
+	for _, mr := range result.Requeued {
+		e.releaseTrainMR(mr)
+	}
+	return result, nil
+}
+
+// releaseTrainMR returns an MR that didn't land to the queue.
+func (e *Engineer) releaseTrainMR(mr *MRInfo) {
+	if err := e.ReleaseMR(mr.ID); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not release %s: %v\n", mr.ID, err)
+	}
+}
+
+// ProcessTrain runs a speculative (Bors-style) merge train over mrs, which
+// must all target the same branch and be in queue order.
+//
+// Candidate merge commits are built stacked on each other in scratch
+// worktrees (car N = target + cars 1..N). The whole train is tested first;
+// if it fails, the train is bisected to find the first failing car, so a
+// train of N cars costs at most 1 + log2(N) check runs rather than N. The
+// target is fast-forwarded to the longest passing prefix, the first failing
+// car is failed, and everything stacked after it is requeued for the next
+// train.
+//
+// A car that conflicts only with other cars (not with the target itself) is
+// requeued rather than failed, since the conflict is not its fault. With
+// on_conflict auto_rebase a conflicting car is first rebased onto the
+// stack, as the serial path rebases onto the target.
+func (e *Engineer) ProcessTrain(ctx context.Context, mrs []*MRInfo) *TrainResult {
+	result := &TrainResult{}
+	if len(mrs) == 0 {
+		return result
+	}
+	target := mrs[0].Target
+	result.Target = target
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Building merge train of %d MR(s) into %s\n", len(mrs), target)
+
+	// Refresh the target exactly as the serial path does
+	if err := e.git.Checkout(target); err != nil {
+		for _, mr := range mrs {
+			result.Failed = append(result.Failed, &TrainCar{MR: mr, Result: ProcessResult{
+				Error: fmt.Sprintf("failed to checkout target %s: %v", target, err),
+			}})
+		}
+		return result
+	}
+	if err := e.git.Pull("origin", target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: pull from origin/%s: %v (continuing)\n", target, err)
+	}
+
+	base, err := e.git.Rev(target)
+	if err != nil {
+		for _, mr := range mrs {
+			result.Failed = append(result.Failed, &TrainCar{MR: mr, Result: ProcessResult{
+				Error: fmt.Sprintf("failed to resolve %s: %v", target, err),
+			}})
+		}
+		return result
+	}
+
+	// Step 1: Build stacked candidates sequentially (each depends on the last)
+	var stacked []*TrainCar
+	var worktrees []string
+	defer func() {
+		for _, wt := range worktrees {
+			_ = e.git.WorktreeRemove(wt, true)
+			_ = os.RemoveAll(wt)
+		}
+		_ = e.git.WorktreePrune()
+	}()
+
+	for _, mr := range mrs {
+		if mr.Target != target {
+			result.Requeued = append(result.Requeued, mr)
+			continue
+		}
+
+		wt, err := os.MkdirTemp("", "gt-train-*")
+		if err != nil {
+			result.Requeued = append(result.Requeued, mr)
+			continue
+		}
+		worktrees = append(worktrees, wt)
+
+		car, ok := e.buildCar(mr, wt, base)
+		if !ok {
+			if car.Result.Conflict {
+				e.classifyTrainConflict(car, target, result)
+			} else {
+				result.Failed = append(result.Failed, car)
+			}
+			continue
+		}
+		stacked = append(stacked, car)
+		base = car.Commit
+	}
+
+	if len(stacked) == 0 {
+		return result
+	}
+
+	// Step 2: Find the longest passing prefix, testing the whole train
+	// first and bisecting on failure
+	landed := len(stacked)
+	var checks []beads.MRCheck
+	if e.hasChecks() {
+		var culprit *TrainCar
+		landed, checks, culprit = e.bisectTrain(ctx, stacked, target)
+		rest := stacked[landed:]
+		if culprit != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Train broken at %s: %s\n", culprit.MR.ID, culprit.Result.Error)
+			result.Failed = append(result.Failed, culprit)
+			rest = rest[1:]
+		}
+		for _, car := range rest {
+			result.Requeued = append(result.Requeued, car.MR)
+		}
+	}
+	if landed == 0 {
+		return result
+	}
+
+	// Step 3: Land the prefix by fast-forwarding the target
+	tip := stacked[landed-1].Commit
+	if err := e.git.MergeFFOnly(tip); err != nil {
+		for _, car := range stacked[:landed] {
+			result.Requeued = append(result.Requeued, car.MR)
+		}
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: fast-forward %s to %s failed: %v\n", target, shortSHA(tip), err)
+		return result
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Pushing to origin/%s...\n", target)
+	if err := e.git.Push("origin", target, false); err != nil {
+		// Roll back the local fast-forward so the next run starts from origin
+		_ = e.git.ResetHard("origin/" + target)
+		for _, car := range stacked[:landed] {
+			result.Requeued = append(result.Requeued, car.MR)
+		}
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to push to origin: %v\n", err)
+		return result
+	}
+
+	for _, car := range stacked[:landed] {
+		// Every landed car is covered by the run that proved the prefix
+		car.Result = ProcessResult{Success: true, MergeCommit: car.Commit, Checks: checks}
+		result.Landed = append(result.Landed, car)
+	}
+	result.MergeCommit = tip
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Train landed %d MR(s): %s\n", landed, shortSHA(tip))
+	return result
+}
+
+// bisectTrain returns how many leading cars of stacked pass their checks,
+// the checks of that prefix's last car and the first car that fails, whose
+// Result it sets. culprit is nil when the whole train passes, or when ctx
+// is canceled, which leaves the outcome unknown and lands nothing. Prefixes
+// are tested one at a time: a passing prefix proves every shorter one.
+func (e *Engineer) bisectTrain(ctx context.Context, stacked []*TrainCar, target string) (int, []beads.MRCheck, *TrainCar) {
+	test := func(n int) ProcessResult {
+		car := stacked[n-1]
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Testing train through %s (%d/%d): %s\n", car.MR.ID, n, len(stacked), e.checksSummary())
+		return e.runChecksIn(ctx, car.dir, target, "HEAD", car.MR)
+	}
+
+	res := test(len(stacked))
+	if res.Success {
+		return len(stacked), res.Checks, nil
+	}
+
+	// Invariant: prefix lo passes (the empty one trivially), prefix hi fails
+	lo, hi := 0, len(stacked)
+	failed := res
+	var passed []beads.MRCheck
+	for hi-lo > 1 && ctx.Err() == nil {
+		mid := (lo + hi) / 2
+		if res := test(mid); res.Success {
+			lo, passed = mid, res.Checks
+		} else {
+			hi, failed = mid, res
+		}
+	}
+	if ctx.Err() != nil {
+		return 0, nil, nil
+	}
+	culprit := stacked[hi-1]
+	culprit.Result = ProcessResult{TestsFailed: true, Error: failed.Error, Checks: failed.Checks}
+	return lo, passed, culprit
+}
+
+// buildCar creates a worktree at base and merges mr's branch into it.
+func (e *Engineer) buildCar(mr *MRInfo, wt, base string) (*TrainCar, bool) {
+	car := &TrainCar{MR: mr, dir: wt}
+
+	exists, err := e.git.BranchExists(mr.Branch)
+	if err != nil || !exists {
+		car.Result = ProcessResult{Error: fmt.Sprintf("branch %s not found locally", mr.Branch)}
+		return car, false
+	}
+
+	if err := e.git.WorktreeAddDetached(wt, base); err != nil {
+		car.Result = ProcessResult{Error: fmt.Sprintf("creating train worktree: %v", err)}
+		return car, false
+	}
+
+	scratch := git.NewGit(wt)
+	var conflicts []string
+	if e.config.MergeStrategy == MergeStrategyRebaseFF {
+		conflicts, err = e.replayCar(scratch, mr.Branch)
+	} else {
+		var msg string
+		if msg, err = e.commitMessage(mr); err != nil {
+			car.Result = ProcessResult{Error: err.Error()}
+			return car, false
+		}
+		conflicts, err = e.land(scratch, mr.Branch, msg)
+		if len(conflicts) > 0 && e.config.OnConflict == "auto_rebase" {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] %s conflicts in %v, attempting auto-rebase...\n", mr.ID, conflicts)
+			conflicts, err = e.landRebased(scratch, mr.Branch, msg)
+		}
+	}
+	if err != nil {
+		if len(conflicts) > 0 {
+			car.Result = ProcessResult{Conflict: true, Error: fmt.Sprintf("merge conflicts in: %v", conflicts)}
+		} else {
+			car.Result = ProcessResult{Error: fmt.Sprintf("merge failed: %v", err)}
+		}
+		return car, false
+	}
+
+	commit, err := scratch.Rev("HEAD")
+	if err != nil {
+		car.Result = ProcessResult{Error: fmt.Sprintf("failed to get merge commit SHA: %v", err)}
+		return car, false
+	}
+	car.Commit = commit
+	return car, true
+}
+
+// landRebased lands a copy of branch rebased onto the worktree's HEAD, for
+// auto_rebase when merging the branch itself conflicts.
+func (e *Engineer) landRebased(scratch *git.Git, branch, msg string) ([]string, error) {
+	base, err := scratch.Rev("HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if conflicts, err := e.replayCar(scratch, branch); err != nil {
+		return conflicts, err
+	}
+	rebased, err := scratch.Rev("HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if err := scratch.Checkout(base); err != nil {
+		return nil, err
+	}
+	return e.land(scratch, rebased, msg)
+}
+
+// replayCar rebases a copy of branch onto the car's base, leaving the
+// worktree detached at the rebased tip. The branch itself is not moved.
+func (e *Engineer) replayCar(scratch *git.Git, branch string) ([]string, error) {
+	scratch = e.signing(scratch)
+	base, err := scratch.Rev("HEAD")
+	if err != nil {
+		return nil, err
+	}
+	tip, err := e.git.Rev(branch)
+	if err != nil {
+		return nil, err
+	}
+	if err := scratch.Checkout(tip); err != nil {
+		return nil, err
+	}
+	if err := scratch.Rebase(base); err != nil {
+		conflicts, _ := scratch.GetConflictingFiles()
+		_ = scratch.AbortRebase()
+		return conflicts, err
+	}
+	return nil, nil
+}
+
+// classifyTrainConflict decides whether a car that conflicted with the stack
+// is at fault (conflicts with the target itself) or just unlucky.
+func (e *Engineer) classifyTrainConflict(car *TrainCar, target string, result *TrainResult) {
+	conflicts, err := e.git.CheckConflicts(car.MR.Branch, target)
+	if err == nil && len(conflicts) == 0 {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] %s conflicts with an earlier car, requeueing\n", car.MR.ID)
+		result.Requeued = append(result.Requeued, car.MR)
+		return
+	}
+	result.Failed = append(result.Failed, car)
+}
+
+// shortSHA abbreviates a commit SHA for log output.
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -0,0 +1,276 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// newTrainTestEngineer creates a clone of a bare origin with one polecat
+// branch per entry in branches. Each branch adds its own file with the given
+// content, so branches only conflict when they share a file name.
+func newTrainTestEngineer(t *testing.T, branches map[string][2]string) (*Engineer, string) {
+	t.Helper()
+	root := t.TempDir()
+	origin := filepath.Join(root, "origin.git")
+	runGit(t, root, "init", "--bare", "-b", "main", origin)
+
+	dir := filepath.Join(root, "work")
+	runGit(t, root, "clone", origin, dir)
+	runGit(t, dir, "config", "user.email", "test@test.com")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "checkout", "-b", "main")
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "initial")
+	runGit(t, dir, "push", "origin", "main")
+
+	for branch, file := range branches {
+		runGit(t, dir, "checkout", "-b", branch, "main")
+		if err := os.WriteFile(filepath.Join(dir, file[0]), []byte(file[1]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, dir, "add", ".")
+		runGit(t, dir, "commit", "-m", "work on "+branch)
+	}
+	runGit(t, dir, "checkout", "main")
+
+	cfg := DefaultMergeQueueConfig()
+	cfg.MaxConcurrent = 3
+	// Any file containing BROKEN fails the suite
+	cfg.TestCommand = "! grep -rq --exclude-dir=.git BROKEN ."
+	e := &Engineer{
+		rig:     &rig.Rig{Name: "test-rig", Path: root},
+		git:     git.NewGit(dir),
+		config:  cfg,
+		workDir: dir,
+		output:  &bytes.Buffer{},
+	}
+	return e, dir
+}
+
+func trainMRs(branches ...string) []*MRInfo {
+	var mrs []*MRInfo
+	for _, b := range branches {
+		mrs = append(mrs, &MRInfo{ID: "mr-" + filepath.Base(b), Branch: b, Target: "main"})
+	}
+	return mrs
+}
+
+func mrIDs(cars []*TrainCar) []string {
+	var ids []string
+	for _, c := range cars {
+		ids = append(ids, c.MR.ID)
+	}
+	return ids
+}
+
+func TestSelectTrain(t *testing.T) {
+	e := &Engineer{config: &MergeQueueConfig{MaxConcurrent: 2}}
+	mrs := []*MRInfo{
+		{ID: "a", Target: "main"},
+		{ID: "b", Target: "integration/x"},
+		{ID: "c", Target: "main"},
+		{ID: "d", Target: "main"},
+	}
+
+	cars := e.SelectTrain(mrs)
+	if len(cars) != 2 || cars[0].ID != "a" || cars[1].ID != "c" {
+		t.Errorf("SelectTrain = %v, want [a c]", cars)
+	}
+
+	e.config.MaxConcurrent = 0
+	if cars := e.SelectTrain(mrs); len(cars) != 1 {
+		t.Errorf("MaxConcurrent 0 should select 1 car, got %d", len(cars))
+	}
+}
+
+func TestProcessTrain_AllPass(t *testing.T) {
+	e, dir := newTrainTestEngineer(t, map[string][2]string{
+		"polecat/a": {"a.txt", "a\n"},
+		"polecat/b": {"b.txt", "b\n"},
+		"polecat/c": {"c.txt", "c\n"},
+	})
+
+	result := e.ProcessTrain(context.Background(), trainMRs("polecat/a", "polecat/b", "polecat/c"))
+	if len(result.Landed) != 3 {
+		t.Fatalf("Landed = %v, want all 3 (failed=%v requeued=%d)", mrIDs(result.Landed), mrIDs(result.Failed), len(result.Requeued))
+	}
+	if got := runGit(t, dir, "rev-parse", "origin/main"); got != result.MergeCommit {
+		t.Errorf("origin/main = %s, want train tip %s", got, result.MergeCommit)
+	}
+	for _, f := range []string{"a.txt", "b.txt", "c.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Errorf("expected %s on main: %v", f, err)
+		}
+	}
+}
+
+func TestProcessTrain_LandsPassingPrefix(t *testing.T) {
+	e, dir := newTrainTestEngineer(t, map[string][2]string{
+		"polecat/a": {"a.txt", "a\n"},
+		"polecat/b": {"b.txt", "BROKEN\n"},
+		"polecat/c": {"c.txt", "c\n"},
+	})
+
+	result := e.ProcessTrain(context.Background(), trainMRs("polecat/a", "polecat/b", "polecat/c"))
+	if ids := mrIDs(result.Landed); len(ids) != 1 || ids[0] != "mr-a" {
+		t.Errorf("Landed = %v, want [mr-a]", ids)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].MR.ID != "mr-b" || !result.Failed[0].Result.TestsFailed {
+		t.Errorf("Failed = %v, want mr-b with TestsFailed", mrIDs(result.Failed))
+	}
+	if len(result.Requeued) != 1 || result.Requeued[0].ID != "mr-c" {
+		t.Errorf("Requeued = %v, want [mr-c]", result.Requeued)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b.txt")); !os.IsNotExist(err) {
+		t.Error("failing car should not have landed")
+	}
+	if wts, _ := e.git.WorktreeList(); len(wts) != 1 {
+		t.Errorf("expected train worktrees to be cleaned up, got %d", len(wts))
+	}
+}
+
+func TestProcessTrain_BisectsFailingTrain(t *testing.T) {
+	e, _ := newTrainTestEngineer(t, map[string][2]string{
+		"polecat/a": {"a.txt", "a\n"},
+		"polecat/b": {"b.txt", "BROKEN\n"},
+		"polecat/c": {"c.txt", "c\n"},
+		"polecat/d": {"d.txt", "d\n"},
+		"polecat/e": {"e.txt", "e\n"},
+	})
+	runs := filepath.Join(t.TempDir(), "runs")
+	e.config.Checks = []Check{{Name: "test", Command: "echo run >> " + runs + "; ! grep -rq --exclude-dir=.git BROKEN ."}}
+	e.config.RetryFlakyTests = 0
+
+	result := e.ProcessTrain(context.Background(), trainMRs("polecat/a", "polecat/b", "polecat/c", "polecat/d", "polecat/e"))
+	if ids := mrIDs(result.Landed); len(ids) != 1 || ids[0] != "mr-a" {
+		t.Errorf("Landed = %v, want [mr-a]", ids)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].MR.ID != "mr-b" || !result.Failed[0].Result.TestsFailed {
+		t.Errorf("Failed = %v, want mr-b with TestsFailed", mrIDs(result.Failed))
+	}
+	if len(result.Requeued) != 3 {
+		t.Errorf("Requeued = %v, want mr-c, mr-d and mr-e", result.Requeued)
+	}
+
+	// The whole train, then prefixes of 2 and 1: not one run per car
+	data, _ := os.ReadFile(runs)
+	if got := strings.Count(string(data), "run"); got != 3 {
+		t.Errorf("checks ran %d times, want 3", got)
+	}
+}
+
+func TestProcessTrain_AutoRebase(t *testing.T) {
+	e, dir := newTrainTestEngineer(t, map[string][2]string{
+		"polecat/a": {"a.txt", "a\n"},
+		"polecat/b": {"shared.txt", "x\n"},
+	})
+	// a picks up b's change and builds on it, so merging b onto a
+	// conflicts but rebasing b drops the change a already has
+	runGit(t, dir, "checkout", "polecat/a")
+	runGit(t, dir, "cherry-pick", "polecat/b")
+	if err := os.WriteFile(filepath.Join(dir, "shared.txt"), []byte("y\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "commit", "-am", "build on b")
+	runGit(t, dir, "checkout", "main")
+	e.config.OnConflict = "auto_rebase"
+
+	result := e.ProcessTrain(context.Background(), trainMRs("polecat/a", "polecat/b"))
+	if ids := mrIDs(result.Landed); len(ids) != 2 {
+		t.Errorf("Landed = %v, want both cars (failed=%v requeued=%v)", ids, mrIDs(result.Failed), result.Requeued)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "shared.txt")); string(data) != "y\n" {
+		t.Errorf("shared.txt on main = %q, want a's version", data)
+	}
+}
+
+func TestProcessTrain_ConflictBetweenCarsRequeues(t *testing.T) {
+	e, _ := newTrainTestEngineer(t, map[string][2]string{
+		"polecat/a": {"shared.txt", "from a\n"},
+		"polecat/b": {"shared.txt", "from b\n"},
+	})
+
+	result := e.ProcessTrain(context.Background(), trainMRs("polecat/a", "polecat/b"))
+	if ids := mrIDs(result.Landed); len(ids) != 1 || ids[0] != "mr-a" {
+		t.Errorf("Landed = %v, want [mr-a]", ids)
+	}
+	if len(result.Failed) != 0 {
+		t.Errorf("car conflicting only with the train should not fail, got %v", mrIDs(result.Failed))
+	}
+	if len(result.Requeued) != 1 || result.Requeued[0].ID != "mr-b" {
+		t.Errorf("Requeued = %v, want [mr-b]", result.Requeued)
+	}
+}
+
+func TestRunTrain_ClaimsLandsAndReleases(t *testing.T) {
+	e, dir := newTrainTestEngineer(t, map[string][2]string{
+		"polecat/a": {"a.txt", "a\n"},
+		"polecat/b": {"b.txt", "BROKEN\n"},
+		"polecat/c": {"c.txt", "c\n"},
+	})
+
+	// The queue is read from the store; bd only records the writes
+	beadsDir := filepath.Join(e.rig.Path, ".beads")
+	if err := os.MkdirAll(beadsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	var jsonl strings.Builder
+	for i, name := range []string{"a", "b", "c"} {
+		fmt.Fprintf(&jsonl, `{"id":"mr-%s","title":"Merge %s","status":"open","priority":2,"issue_type":"merge-request","labels":["gt:merge-request"],"created_at":"2026-01-0%dT00:00:00Z","description":"branch: polecat/%s\ntarget: main\n"}`+"\n", name, name, i+1, name)
+	}
+	if err := os.WriteFile(filepath.Join(beadsDir, "issues.jsonl"), []byte(jsonl.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bin := t.TempDir()
+	bdLog := filepath.Join(bin, "bd.log")
+	script := "#!/bin/sh\necho \"$*\" >> " + bdLog + "\necho '[]'\n"
+	if err := os.WriteFile(filepath.Join(bin, "bd"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	e.beads = beads.NewWithBeadsDir(e.rig.Path, beadsDir)
+	e.router = mail.NewRouter(e.rig.Path)
+
+	result, err := e.RunTrain(context.Background(), "test-rig/refinery")
+	if err != nil {
+		t.Fatalf("RunTrain() error = %v", err)
+	}
+	if got := mrIDs(result.Landed); strings.Join(got, ",") != "mr-a" {
+		t.Errorf("Landed = %v, want [mr-a]", got)
+	}
+	if got := mrIDs(result.Failed); strings.Join(got, ",") != "mr-b" {
+		t.Errorf("Failed = %v, want [mr-b]", got)
+	}
+	if got := runGit(t, dir, "rev-parse", "origin/main"); got != result.MergeCommit {
+		t.Errorf("origin/main = %s, want %s", got, result.MergeCommit)
+	}
+
+	data, _ := os.ReadFile(bdLog)
+	log := string(data)
+	for _, id := range []string{"mr-a", "mr-b", "mr-c"} {
+		if !strings.Contains(log, "update "+id+" --assignee=test-rig/refinery") {
+			t.Errorf("%s not claimed; bd calls:\n%s", id, log)
+		}
+	}
+	if !strings.Contains(log, "close mr-a") {
+		t.Errorf("landed MR not closed; bd calls:\n%s", log)
+	}
+	for _, id := range []string{"mr-b", "mr-c"} {
+		if !strings.Contains(log, "update "+id+" --assignee=\n") && !strings.Contains(log, "update "+id+" --assignee= ") {
+			t.Errorf("%s not released; bd calls:\n%s", id, log)
+		}
+	}
+}
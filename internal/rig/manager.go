@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/steveyegge/gastown/internal/claude"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/connection"
 	"github.com/steveyegge/gastown/internal/git"
 )
 
@@ -96,6 +98,7 @@ type Manager struct {
 	townRoot string
 	config   *config.RigsConfig
 	git      *git.Git
+	machines *connection.MachineRegistry // Loaded lazily; only needed for remote rigs
 }
 
 // NewManager creates a new rig manager.
@@ -140,12 +143,51 @@ func (m *Manager) RigExists(name string) bool {
 	return ok
 }
 
-// loadRig loads rig details from the filesystem.
+// MachineRegistry returns the town's machine registry, loading it on first use.
+func (m *Manager) MachineRegistry() (*connection.MachineRegistry, error) {
+	if m.machines == nil {
+		reg, err := connection.NewMachineRegistry(connection.RegistryPath(m.townRoot))
+		if err != nil {
+			return nil, err
+		}
+		m.machines = reg
+	}
+	return m.machines, nil
+}
+
+// Remote returns the Remote hosting a rig on another machine.
+func (m *Manager) Remote(r *Rig) (*connection.Remote, error) {
+	if r.Address().IsLocal() {
+		return nil, fmt.Errorf("rig %s is local", r.Name)
+	}
+	reg, err := m.MachineRegistry()
+	if err != nil {
+		return nil, err
+	}
+	return reg.Remote(r.Machine)
+}
+
+// loadRig loads rig details from the filesystem of the machine hosting it.
 func (m *Manager) loadRig(name string, entry config.RigEntry) (*Rig, error) {
+	addr := &connection.Address{Machine: entry.Machine, Rig: name}
+
+	var conn connection.Connection = connection.NewLocalConnection()
 	rigPath := filepath.Join(m.townRoot, name)
+	if !addr.IsLocal() {
+		reg, err := m.MachineRegistry()
+		if err != nil {
+			return nil, err
+		}
+		remote, err := reg.Remote(entry.Machine)
+		if err != nil {
+			return nil, fmt.Errorf("rig machine: %w", err)
+		}
+		conn = remote
+		rigPath = remote.RigPath(name)
+	}
 
 	// Verify directory exists
-	info, err := os.Stat(rigPath)
+	info, err := conn.Stat(rigPath)
 	if err != nil {
 		return nil, fmt.Errorf("rig directory: %w", err)
 	}
@@ -159,54 +201,74 @@ func (m *Manager) loadRig(name string, entry config.RigEntry) (*Rig, error) {
 		GitURL:    entry.GitURL,
 		LocalRepo: entry.LocalRepo,
 		Config:    entry.BeadsConfig,
+		Machine:   entry.Machine,
 	}
-
-	// Scan for polecats
-	polecatsDir := filepath.Join(rigPath, "polecats")
-	if entries, err := os.ReadDir(polecatsDir); err == nil {
-		for _, e := range entries {
-			if !e.IsDir() {
-				continue
-			}
-			name := e.Name()
-			if strings.HasPrefix(name, ".") {
-				continue
-			}
-			rig.Polecats = append(rig.Polecats, name)
-		}
+	if addr.IsLocal() {
+		rig.Machine = ""
 	}
 
+	// Scan for polecats (skipping hidden directories, e.g. .claude settings)
+	rig.Polecats = listDirs(conn, filepath.Join(rigPath, "polecats"), true)
+
 	// Scan for crew workers
-	crewDir := filepath.Join(rigPath, "crew")
-	if entries, err := os.ReadDir(crewDir); err == nil {
-		for _, e := range entries {
-			if e.IsDir() {
-				rig.Crew = append(rig.Crew, e.Name())
-			}
-		}
-	}
+	rig.Crew = listDirs(conn, filepath.Join(rigPath, "crew"), false)
 
 	// Check for witness (witnesses don't have clones, just the witness directory)
 	witnessPath := filepath.Join(rigPath, "witness")
-	if info, err := os.Stat(witnessPath); err == nil && info.IsDir() {
+	if info, err := conn.Stat(witnessPath); err == nil && info.IsDir() {
 		rig.HasWitness = true
 	}
 
 	// Check for refinery
 	refineryPath := filepath.Join(rigPath, "refinery", "rig")
-	if _, err := os.Stat(refineryPath); err == nil {
+	if ok, _ := conn.Exists(refineryPath); ok {
 		rig.HasRefinery = true
 	}
 
 	// Check for mayor clone
 	mayorPath := filepath.Join(rigPath, "mayor", "rig")
-	if _, err := os.Stat(mayorPath); err == nil {
+	if ok, _ := conn.Exists(mayorPath); ok {
 		rig.HasMayor = true
 	}
 
 	return rig, nil
 }
 
+// listDirs returns the names of the subdirectories of dir, sorted. Hidden
+// directories are skipped when skipHidden is set, and always on remote
+// machines, where the listing is a single find over SSH.
+func listDirs(conn connection.Connection, dir string, skipHidden bool) []string {
+	var names []string
+	if conn.IsLocal() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				continue
+			}
+			if skipHidden && strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			names = append(names, e.Name())
+		}
+		return names
+	}
+
+	out, err := conn.Exec("find", dir, "-mindepth", "1", "-maxdepth", "1", "-type", "d", "!", "-name", ".*")
+	if err != nil {
+		return nil
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			names = append(names, filepath.Base(line))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // AddRigOptions configures rig creation.
 type AddRigOptions struct {
 	Name          string // Rig name (directory name)
@@ -214,6 +276,7 @@ type AddRigOptions struct {
 	BeadsPrefix   string // Beads issue prefix (defaults to derived from name)
 	LocalRepo     string // Optional local repo for reference clones
 	DefaultBranch string // Default branch (defaults to auto-detected from remote)
+	Machine       string // Federation machine to host the rig (defaults to local)
 }
 
 func resolveLocalRepo(path, gitURL string) (string, string) {
@@ -271,6 +334,10 @@ func (m *Manager) AddRig(opts AddRigOptions) (*Rig, error) {
 		return nil, fmt.Errorf("rig name %q contains invalid characters; hyphens, dots, and spaces are reserved for agent ID parsing. Try %q instead (underscores are allowed)", opts.Name, sanitized)
 	}
 
+	if addr := (&connection.Address{Machine: opts.Machine}); !addr.IsLocal() {
+		return m.addRemoteRig(opts)
+	}
+
 	rigPath := filepath.Join(m.townRoot, opts.Name)
 
 	// Check if directory already exists
@@ -582,6 +649,44 @@ Use crew for your own workspace. Polecats are for batch work dispatch.
 	return m.loadRig(opts.Name, m.config.Rigs[opts.Name])
 }
 
+// addRemoteRig creates a rig on another machine by running "gt rig add" in
+// that machine's town, then registers it here with the machine recorded so
+// lifecycle commands are routed through the connection.
+func (m *Manager) addRemoteRig(opts AddRigOptions) (*Rig, error) {
+	reg, err := m.MachineRegistry()
+	if err != nil {
+		return nil, err
+	}
+	remote, err := reg.Remote(opts.Machine)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.BeadsPrefix == "" {
+		opts.BeadsPrefix = deriveBeadsPrefix(opts.Name)
+	}
+
+	args := []string{"rig", "add", opts.Name, opts.GitURL, "--prefix", opts.BeadsPrefix}
+	if opts.DefaultBranch != "" {
+		args = append(args, "--branch", opts.DefaultBranch)
+	}
+	fmt.Printf("  Creating rig on %s (this may take a moment)...\n", opts.Machine)
+	if out, err := remote.GT(args...); err != nil {
+		return nil, fmt.Errorf("remote rig add on %s: %w\n%s", opts.Machine, err, strings.TrimSpace(string(out)))
+	}
+
+	m.config.Rigs[opts.Name] = config.RigEntry{
+		GitURL:  opts.GitURL,
+		AddedAt: time.Now(),
+		BeadsConfig: &config.BeadsConfig{
+			Prefix: opts.BeadsPrefix,
+		},
+		Machine: opts.Machine,
+	}
+
+	return m.loadRig(opts.Name, m.config.Rigs[opts.Name])
+}
+
 // saveRigConfig writes the rig configuration to config.json.
 func (m *Manager) saveRigConfig(rigPath string, cfg *RigConfig) error {
 	configPath := filepath.Join(rigPath, "config.json")
@@ -137,6 +137,27 @@ func TestGetRig(t *testing.T) {
 	}
 }
 
+func TestGetRig_LocalCrewKeepsHiddenDirs(t *testing.T) {
+	root, rigsConfig := setupTestTown(t)
+	createTestRig(t, root, "gastown")
+	rigsConfig.Rigs["gastown"] = config.RigEntry{}
+	for _, name := range []string{"max", ".joe"} {
+		if err := os.MkdirAll(filepath.Join(root, "gastown", "crew", name), 0755); err != nil {
+			t.Fatalf("mkdir crew: %v", err)
+		}
+	}
+
+	manager := NewManager(root, rigsConfig, git.NewGit(root))
+
+	r, err := manager.GetRig("gastown")
+	if err != nil {
+		t.Fatalf("GetRig: %v", err)
+	}
+	if !slices.Equal(r.Crew, []string{".joe", "max"}) {
+		t.Errorf("Crew = %v, want [.joe max]", r.Crew)
+	}
+}
+
 func TestGetRigNotFound(t *testing.T) {
 	root, rigsConfig := setupTestTown(t)
 	manager := NewManager(root, rigsConfig, git.NewGit(root))
@@ -147,6 +168,39 @@ func TestGetRigNotFound(t *testing.T) {
 	}
 }
 
+func TestGetRig_RemoteMachineNotRegistered(t *testing.T) {
+	root, rigsConfig := setupTestTown(t)
+	rigsConfig.Rigs["faraway"] = config.RigEntry{
+		GitURL:  "git@github.com:test/faraway.git",
+		Machine: "buildbox",
+	}
+
+	manager := NewManager(root, rigsConfig, git.NewGit(root))
+
+	if _, err := manager.GetRig("faraway"); err == nil || !strings.Contains(err.Error(), "buildbox") {
+		t.Errorf("GetRig = %v, want error naming the unknown machine", err)
+	}
+}
+
+func TestGetRig_LocalMachineIsLocal(t *testing.T) {
+	root, rigsConfig := setupTestTown(t)
+	createTestRig(t, root, "gastown")
+	rigsConfig.Rigs["gastown"] = config.RigEntry{Machine: "local"}
+
+	manager := NewManager(root, rigsConfig, git.NewGit(root))
+
+	r, err := manager.GetRig("gastown")
+	if err != nil {
+		t.Fatalf("GetRig: %v", err)
+	}
+	if !r.Address().IsLocal() || r.Machine != "" {
+		t.Errorf("Address = %s, Machine = %q; want local", r.Address(), r.Machine)
+	}
+	if r.Path != filepath.Join(root, "gastown") {
+		t.Errorf("Path = %q", r.Path)
+	}
+}
+
 func TestRigExists(t *testing.T) {
 	root, rigsConfig := setupTestTown(t)
 	rigsConfig.Rigs["exists"] = config.RigEntry{}
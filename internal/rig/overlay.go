@@ -1,33 +1,118 @@
 package rig
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
 )
 
-// CopyOverlay copies files from <rigPath>/.runtime/overlay/ to the destination path.
-// This allows storing gitignored files (like .env) that services need at their root.
-// The overlay is copied non-recursively - only files, not subdirectories.
-// File permissions from the source are preserved.
+// Overlay layout under <rig>/.runtime/overlay/.
+const (
+	// overlayRoleDir holds per-role layers: _role/<role>/ is applied to
+	// every workspace of that role (polecat, crew).
+	overlayRoleDir = "_role"
+
+	// overlayAgentDir holds per-agent layers: _agent/<role>/<name>/ is
+	// applied to one polecat or crew member.
+	overlayAgentDir = "_agent"
+
+	// overlayTemplateExt marks a file rendered with text/template; the
+	// extension is dropped from the destination name.
+	overlayTemplateExt = ".tmpl"
+
+	// overlayManifestFile lists the files an overlay wrote, in the
+	// worktree's git dir.
+	overlayManifestFile = "gt-overlay.json"
+
+	// overlayOriginalsDir keeps, in the worktree's git dir, the files an
+	// overlay overwrote, so they can be put back.
+	overlayOriginalsDir = "gt-overlay-originals"
+)
+
+// Markers around the overlay's block in the repo's info/exclude.
+const (
+	overlayExcludeBegin = "# gt overlay files (managed by gt, do not edit)"
+	overlayExcludeEnd   = "# end gt overlay files"
+)
+
+// OverlayScope says whose workspace an overlay is applied to. Role and Name
+// select the _role/<role>/ and _agent/<role>/<name>/ layers; the zero scope
+// applies only the base layer.
+type OverlayScope struct {
+	Role string // "polecat" or "crew"
+	Name string // Polecat or crew member name
+}
+
+// OverlayData is what overlay templates can refer to, e.g.
+// {{.Name}} or {{.Rig}}. Templates can also call {{secret "NAME"}} to read
+// the rig's secret store and {{env "NAME"}} for the environment.
+type OverlayData struct {
+	Rig      string // Rig name
+	RigPath  string // Rig directory
+	TownRoot string // Town directory
+	Role     string // Workspace role, empty for the base layer only
+	Name     string // Polecat or crew member name
+	Path     string // Workspace the overlay is applied to
+}
+
+// OverlayFile is a file an overlay wrote to a workspace.
+type OverlayFile struct {
+	Path    string `json:"path"`              // Relative to the worktree root, slash-separated
+	Secret  bool   `json:"secret,omitempty"`  // Rendered with a secret
+	Existed bool   `json:"existed,omitempty"` // Overwrote a file already there, which is restored on removal
+}
+
+// overlayManifest is the record of an applied overlay.
+type overlayManifest struct {
+	Files []OverlayFile `json:"files"`
+}
+
+// CopyOverlay copies the base layer of <rigPath>/.runtime/overlay/ to the
+// destination path. See ApplyOverlay.
+func CopyOverlay(rigPath, destPath string) error {
+	return ApplyOverlay(rigPath, destPath, OverlayScope{})
+}
+
+// ApplyOverlay copies files from <rigPath>/.runtime/overlay/ to the
+// destination path. This allows storing gitignored files (like .env) that
+// services need in their workspace. Subdirectories are copied recursively
+// and file permissions from the source are preserved.
 //
 // Structure:
 //
 //	rig/
 //	  .runtime/
 //	    overlay/
-//	      .env          <- Copied to destPath
-//	      config.json   <- Copied to destPath
+//	      .env.tmpl            <- Rendered to destPath/.env
+//	      config/local.json    <- Copied to destPath/config/local.json
+//	      _role/
+//	        polecat/           <- Only for polecats (overrides the base)
+//	      _agent/
+//	        polecat/nux/       <- Only for polecat nux (overrides the role)
+//
+// Files ending in .tmpl are Go templates rendered with OverlayData; their
+// {{secret "NAME"}} calls read the rig's secret store (see OpenSecretStore),
+// and files that use a secret are written readable by their owner only.
+//
+// In a git worktree the written files are listed in the repo's
+// info/exclude, so they can't be committed by accident, and recorded so
+// RemoveOverlay can delete them again. A file the overlay overwrites may be
+// tracked, which exclude can't hide, so its original is kept in the git dir
+// for RemoveOverlay to restore.
 //
 // Returns nil if the overlay directory doesn't exist (nothing to copy).
-// Individual file copy failures are logged as warnings but don't stop the process.
-func CopyOverlay(rigPath, destPath string) error {
+// Individual file failures are logged as warnings but don't stop the process.
+func ApplyOverlay(rigPath, destPath string, scope OverlayScope) error {
 	overlayDir := filepath.Join(rigPath, ".runtime", "overlay")
 
 	// Check if overlay directory exists
-	entries, err := os.ReadDir(overlayDir)
-	if err != nil {
+	if _, err := os.Stat(overlayDir); err != nil {
 		if os.IsNotExist(err) {
 			// No overlay directory - not an error, just nothing to copy
 			return nil
@@ -35,26 +120,422 @@ func CopyOverlay(rigPath, destPath string) error {
 		return fmt.Errorf("reading overlay dir: %w", err)
 	}
 
-	// Copy each file (not directories) from overlay to destination
-	for _, entry := range entries {
-		if entry.IsDir() {
-			// Skip subdirectories - only copy files at overlay root
-			continue
+	layers := []string{overlayDir}
+	if scope.Role != "" {
+		layers = append(layers, filepath.Join(overlayDir, overlayRoleDir, scope.Role))
+		if scope.Name != "" {
+			layers = append(layers, filepath.Join(overlayDir, overlayAgentDir, scope.Role, scope.Name))
 		}
+	}
+	files := collectOverlayFiles(layers)
+	if len(files) == 0 {
+		return nil
+	}
 
-		srcPath := filepath.Join(overlayDir, entry.Name())
-		dstPath := filepath.Join(destPath, entry.Name())
+	root, gitDir, commonDir := findWorktree(destPath)
+	previous := make(map[string]OverlayFile)
+	if gitDir != "" {
+		if m, err := loadOverlayManifest(gitDir); err == nil {
+			for _, f := range m.Files {
+				previous[f.Path] = f
+			}
+		}
+	}
+
+	data := OverlayData{
+		Rig:      filepath.Base(rigPath),
+		RigPath:  rigPath,
+		TownRoot: filepath.Dir(rigPath),
+		Role:     scope.Role,
+		Name:     scope.Name,
+		Path:     destPath,
+	}
+	secrets := OpenSecretStore(rigPath)
 
-		if err := copyFilePreserveMode(srcPath, dstPath); err != nil {
+	targets := make([]string, 0, len(files))
+	for target := range files {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	var written []OverlayFile
+	for _, target := range targets {
+		srcPath := files[target]
+		dstPath := filepath.Join(destPath, target)
+
+		record := OverlayFile{Path: target}
+		if root != "" {
+			if rel, err := filepath.Rel(root, dstPath); err == nil {
+				record.Path = filepath.ToSlash(rel)
+			}
+		}
+		if prev, ok := previous[record.Path]; ok {
+			// The original was saved when it was first overwritten
+			record.Existed = prev.Existed
+		} else if _, err := os.Lstat(dstPath); err == nil {
+			record.Existed = true
+			if gitDir != "" {
+				if err := saveOverlayOriginal(gitDir, record.Path, dstPath); err != nil {
+					fmt.Printf("Warning: could not save %s before overwriting it, skipping overlay file: %v\n", target, err)
+					continue
+				}
+			}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+			fmt.Printf("Warning: could not create overlay dir for %s: %v\n", target, err)
+			continue
+		}
+		if strings.HasSuffix(srcPath, overlayTemplateExt) {
+			secret, err := renderOverlayTemplate(srcPath, dstPath, data, secrets)
+			if err != nil {
+				// Log warning but continue - don't fail spawn for overlay issues
+				fmt.Printf("Warning: could not render overlay file %s: %v\n", target, err)
+				continue
+			}
+			record.Secret = secret
+		} else if err := copyFilePreserveMode(srcPath, dstPath); err != nil {
 			// Log warning but continue - don't fail spawn for overlay issues
-			fmt.Printf("Warning: could not copy overlay file %s: %v\n", entry.Name(), err)
+			fmt.Printf("Warning: could not copy overlay file %s: %v\n", target, err)
 			continue
 		}
+		written = append(written, record)
 	}
 
+	if gitDir == "" {
+		// Not a worktree: nothing can be committed, nothing to record
+		return nil
+	}
+	// Files from an earlier apply that are still there stay recorded
+	seen := make(map[string]bool, len(written))
+	for _, f := range written {
+		seen[f.Path] = true
+	}
+	for _, f := range previous {
+		if _, err := os.Lstat(filepath.Join(root, filepath.FromSlash(f.Path))); !seen[f.Path] && err == nil {
+			written = append(written, f)
+		}
+	}
+	if err := saveOverlayManifest(gitDir, overlayManifest{Files: written}); err != nil {
+		return fmt.Errorf("recording overlay files: %w", err)
+	}
+	if err := excludeOverlayFiles(commonDir, written); err != nil {
+		return fmt.Errorf("excluding overlay files from git: %w", err)
+	}
 	return nil
 }
 
+// collectOverlayFiles maps destination paths (relative, template extension
+// dropped) to their source in the last layer that has them. The layer
+// directories are skipped when walking the base layer.
+func collectOverlayFiles(layers []string) map[string]string {
+	files := make(map[string]string)
+	for i, layer := range layers {
+		_ = filepath.WalkDir(layer, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				if path != layer {
+					fmt.Printf("Warning: could not read overlay %s: %v\n", path, err)
+				}
+				return nil
+			}
+			if d.IsDir() {
+				if i == 0 && (path == filepath.Join(layer, overlayRoleDir) || path == filepath.Join(layer, overlayAgentDir)) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			rel, err := filepath.Rel(layer, path)
+			if err != nil {
+				return nil
+			}
+			files[strings.TrimSuffix(rel, overlayTemplateExt)] = path
+			return nil
+		})
+	}
+	return files
+}
+
+// renderOverlayTemplate renders a template file to dst. It reports whether
+// the template read a secret, in which case dst is made private.
+func renderOverlayTemplate(src, dst string, data OverlayData, secrets SecretStore) (bool, error) {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return false, fmt.Errorf("stat source: %w", err)
+	}
+	text, err := os.ReadFile(src)
+	if err != nil {
+		return false, fmt.Errorf("read source: %w", err)
+	}
+
+	usedSecret := false
+	funcs := template.FuncMap{
+		"secret": func(name string) (string, error) {
+			usedSecret = true
+			return secrets.Get(name)
+		},
+		"env": os.Getenv,
+	}
+	tmpl, err := template.New(filepath.Base(src)).Option("missingkey=error").Funcs(funcs).Parse(string(text))
+	if err != nil {
+		return false, err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return false, err
+	}
+
+	mode := srcInfo.Mode().Perm()
+	if usedSecret {
+		mode = 0600
+	}
+	// Remove first: WriteFile keeps the mode of an existing file
+	_ = os.Remove(dst)
+	if err := os.WriteFile(dst, out.Bytes(), mode); err != nil {
+		return false, fmt.Errorf("write destination: %w", err)
+	}
+	return usedSecret, nil
+}
+
+// RemoveOverlay deletes the overlay files ApplyOverlay wrote to the
+// worktree containing path, along with directories left empty, and puts
+// back the files they overwrote. It is a no-op outside a worktree or when
+// no overlay was applied.
+func RemoveOverlay(path string) error {
+	root, gitDir, _ := findWorktree(path)
+	if gitDir == "" {
+		return nil
+	}
+	manifest, err := loadOverlayManifest(gitDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, f := range manifest.Files {
+		target := filepath.Join(root, filepath.FromSlash(f.Path))
+		if f.Existed {
+			if err := restoreOverlayOriginal(gitDir, f.Path, target); err != nil {
+				return fmt.Errorf("restoring %s: %w", f.Path, err)
+			}
+			continue
+		}
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing overlay file %s: %w", f.Path, err)
+		}
+		// Remove directories the overlay created, up to the root
+		for dir := filepath.Dir(target); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+	if err := os.RemoveAll(filepath.Join(gitDir, overlayOriginalsDir)); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(gitDir, overlayManifestFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// saveOverlayOriginal copies the file at path, about to be overwritten by
+// the overlay file rel, into the git dir.
+func saveOverlayOriginal(gitDir, rel, path string) error {
+	saved := filepath.Join(gitDir, overlayOriginalsDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(saved), 0755); err != nil {
+		return err
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		_ = os.Remove(saved)
+		return os.Symlink(link, saved)
+	}
+	return copyFilePreserveMode(path, saved)
+}
+
+// restoreOverlayOriginal moves the saved original of the overlay file rel
+// back to path. Records from before originals were saved have none; their
+// file is left alone.
+func restoreOverlayOriginal(gitDir, rel, path string) error {
+	saved := filepath.Join(gitDir, overlayOriginalsDir, filepath.FromSlash(rel))
+	if _, err := os.Lstat(saved); os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.Rename(saved, path)
+}
+
+// OverlayFiles returns the overlay files applied to the worktree containing
+// path, or nil when there are none.
+func OverlayFiles(path string) []OverlayFile {
+	_, gitDir, _ := findWorktree(path)
+	if gitDir == "" {
+		return nil
+	}
+	manifest, err := loadOverlayManifest(gitDir)
+	if err != nil {
+		return nil
+	}
+	return manifest.Files
+}
+
+// CommittedOverlayFiles returns the overlay files of the worktree
+// containing path that appear among changed (paths relative to the
+// worktree root, as git reports them), i.e. that a branch would commit.
+// A file that overwrote one already there only counts if it holds a
+// secret: otherwise the branch may be changing the original.
+func CommittedOverlayFiles(path string, changed []string) []string {
+	overlay := make(map[string]bool)
+	for _, f := range OverlayFiles(path) {
+		if !f.Existed || f.Secret {
+			overlay[f.Path] = true
+		}
+	}
+	var committed []string
+	for _, c := range changed {
+		if overlay[c] {
+			committed = append(committed, c)
+		}
+	}
+	return committed
+}
+
+// findWorktree returns the root and git dir of the git worktree containing
+// path, and the common dir its worktrees share (where info/exclude lives).
+// All are empty when path isn't in a worktree.
+func findWorktree(path string) (root, gitDir, commonDir string) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", ""
+	}
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		info, err := os.Stat(dotGit)
+		if err == nil {
+			if info.IsDir() {
+				return dir, dotGit, dotGit
+			}
+			data, err := os.ReadFile(dotGit)
+			if err != nil {
+				return "", "", ""
+			}
+			gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+			if !ok {
+				return "", "", ""
+			}
+			gitDir = strings.TrimSpace(gitDir)
+			if !filepath.IsAbs(gitDir) {
+				gitDir = filepath.Join(dir, gitDir)
+			}
+			commonDir := gitDir
+			if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+				commonDir = strings.TrimSpace(string(data))
+				if !filepath.IsAbs(commonDir) {
+					commonDir = filepath.Join(gitDir, commonDir)
+				}
+			}
+			return dir, gitDir, filepath.Clean(commonDir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", ""
+		}
+		dir = parent
+	}
+}
+
+// loadOverlayManifest reads the overlay record in a git dir.
+func loadOverlayManifest(gitDir string) (*overlayManifest, error) {
+	data, err := os.ReadFile(filepath.Join(gitDir, overlayManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var m overlayManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing overlay manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// saveOverlayManifest writes the overlay record to a git dir.
+func saveOverlayManifest(gitDir string, m overlayManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(gitDir, overlayManifestFile), data, 0644)
+}
+
+// excludeOverlayFiles adds the overlay files to the managed block of the
+// repo's info/exclude, which all its worktrees share. Files that overwrote
+// an existing file may be tracked, which exclude can't change, and are
+// left out.
+func excludeOverlayFiles(commonDir string, files []OverlayFile) error {
+	path := filepath.Join(commonDir, "info", "exclude")
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// Keep the patterns already in the block: other worktrees' overlays
+	patterns := make(map[string]bool)
+	var kept []string
+	inBlock := false
+	for _, line := range strings.Split(string(existing), "\n") {
+		switch {
+		case line == overlayExcludeBegin:
+			inBlock = true
+		case line == overlayExcludeEnd:
+			inBlock = false
+		case inBlock:
+			if line != "" {
+				patterns[line] = true
+			}
+		default:
+			kept = append(kept, line)
+		}
+	}
+	for _, f := range files {
+		if !f.Existed {
+			patterns["/"+f.Path] = true
+		}
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	content := strings.TrimRight(strings.Join(kept, "\n"), "\n")
+	if content != "" {
+		content += "\n"
+	}
+	content += overlayExcludeBegin + "\n"
+	sorted := make([]string, 0, len(patterns))
+	for p := range patterns {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+	content += strings.Join(sorted, "\n") + "\n" + overlayExcludeEnd + "\n"
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
 // copyFilePreserveMode copies a file from src to dst, preserving the source file's permissions.
 func copyFilePreserveMode(src, dst string) error {
 	// Get source file info for permissions
@@ -3,6 +3,7 @@ package rig
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -98,51 +99,179 @@ func TestCopyOverlay_PreservesPermissions(t *testing.T) {
 	}
 }
 
-func TestCopyOverlay_SkipsSubdirectories(t *testing.T) {
+func TestCopyOverlay_CopiesSubdirectories(t *testing.T) {
 	rigDir := t.TempDir()
 	destDir := t.TempDir()
 
 	// Create overlay directory with a subdirectory
 	overlayDir := filepath.Join(rigDir, ".runtime", "overlay")
-	if err := os.MkdirAll(overlayDir, 0755); err != nil {
-		t.Fatalf("Failed to create overlay dir: %v", err)
-	}
-
-	// Create a subdirectory
 	subDir := filepath.Join(overlayDir, "subdir")
 	if err := os.MkdirAll(subDir, 0755); err != nil {
 		t.Fatalf("Failed to create subdirectory: %v", err)
 	}
+	if err := os.WriteFile(filepath.Join(subDir, "sub.txt"), []byte("subcontent"), 0644); err != nil {
+		t.Fatalf("Failed to create sub file: %v", err)
+	}
 
-	// Create a file in the overlay root
-	testFile := filepath.Join(overlayDir, "test.txt")
-	if err := os.WriteFile(testFile, []byte("content"), 0644); err != nil {
-		t.Fatalf("Failed to create test file: %v", err)
+	// Layer directories are only applied for their scope
+	roleDir := filepath.Join(overlayDir, "_role", "polecat")
+	if err := os.MkdirAll(roleDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(roleDir, "role.txt"), []byte("polecat"), 0644); err != nil {
+		t.Fatal(err)
 	}
 
-	// Create a file in the subdirectory
-	subFile := filepath.Join(subDir, "sub.txt")
-	if err := os.WriteFile(subFile, []byte("subcontent"), 0644); err != nil {
-		t.Fatalf("Failed to create sub file: %v", err)
+	if err := CopyOverlay(rigDir, destDir); err != nil {
+		t.Fatalf("CopyOverlay() error = %v", err)
 	}
 
-	// Copy overlay
-	err := CopyOverlay(rigDir, destDir)
+	content, err := os.ReadFile(filepath.Join(destDir, "subdir", "sub.txt"))
+	if err != nil || string(content) != "subcontent" {
+		t.Errorf("File in subdirectory should be copied: %q, %v", content, err)
+	}
+	for _, name := range []string{"_role", "role.txt"} {
+		if _, err := os.Stat(filepath.Join(destDir, name)); err == nil {
+			t.Errorf("%s should not be copied without a role", name)
+		}
+	}
+}
+
+func TestApplyOverlay_LayersAndTemplates(t *testing.T) {
+	t.Setenv("GT_SECRETS_KEY", filepath.Join(t.TempDir(), "secrets.key"))
+	rigDir := filepath.Join(t.TempDir(), "gastown")
+	destDir := t.TempDir()
+	overlayDir := filepath.Join(rigDir, ".runtime", "overlay")
+
+	files := map[string]string{
+		"shared.txt":                         "base",
+		"role.txt":                           "base",
+		"agent.txt":                          "base",
+		"_role/polecat/role.txt":             "role",
+		"_role/polecat/agent.txt":            "role",
+		"_role/crew/role.txt":                "crew",
+		"_agent/polecat/nux/agent.txt":       "nux",
+		"_agent/polecat/toast/agent.txt":     "toast",
+		".env.tmpl":                          "RIG={{.Rig}}\nWORKER={{.Role}}/{{.Name}}\nTOKEN={{secret \"API_TOKEN\"}}\n",
+		"config/app.yaml.tmpl":               "name: {{.Name}}\n",
+		"_agent/polecat/nux/broken.txt.tmpl": "{{secret \"MISSING\"}}",
+	}
+	for name, content := range files {
+		path := filepath.Join(overlayDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := NewLocalSecretStore(rigDir).Set("API_TOKEN", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ApplyOverlay(rigDir, destDir, OverlayScope{Role: "polecat", Name: "nux"}); err != nil {
+		t.Fatalf("ApplyOverlay() error = %v", err)
+	}
+
+	want := map[string]string{
+		"shared.txt":      "base",
+		"role.txt":        "role",
+		"agent.txt":       "nux",
+		".env":            "RIG=gastown\nWORKER=polecat/nux\nTOKEN=s3cret\n",
+		"config/app.yaml": "name: nux\n",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(destDir, filepath.FromSlash(name)))
+		if err != nil || string(got) != content {
+			t.Errorf("%s = %q, %v; want %q", name, got, err, content)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(destDir, ".env")); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf(".env rendered with a secret should be private: %v, %v", info.Mode(), err)
+	}
+	// A template that fails to render is skipped
+	if _, err := os.Stat(filepath.Join(destDir, "broken.txt")); err == nil {
+		t.Error("broken.txt should not be written")
+	}
+}
+
+func TestApplyOverlay_GitWorktree(t *testing.T) {
+	t.Setenv("GT_SECRETS_KEY", filepath.Join(t.TempDir(), "secrets.key"))
+	rigDir := t.TempDir()
+	overlayDir := filepath.Join(rigDir, ".runtime", "overlay")
+	if err := os.MkdirAll(filepath.Join(overlayDir, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{".env", "README.md", "config/local.json"} {
+		if err := os.WriteFile(filepath.Join(overlayDir, filepath.FromSlash(name)), []byte("overlay"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(overlayDir, "settings.json.tmpl"), []byte(`{"token": "{{secret "API_TOKEN"}}"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewLocalSecretStore(rigDir).Set("API_TOKEN", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A linked worktree: .git is a file pointing into the repo's git dir
+	repo := t.TempDir()
+	gitDir := filepath.Join(repo, ".git", "worktrees", "nux")
+	if err := os.MkdirAll(gitDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(gitDir, "commondir"), []byte("../..\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worktree := t.TempDir()
+	if err := os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: "+gitDir+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// README.md and settings.json are tracked: the overlay overwrites them
+	// but must put them back
+	for _, name := range []string{"README.md", "settings.json"} {
+		if err := os.WriteFile(filepath.Join(worktree, name), []byte("tracked"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ApplyOverlay(rigDir, worktree, OverlayScope{Role: "polecat", Name: "nux"}); err != nil {
+		t.Fatalf("ApplyOverlay() error = %v", err)
+	}
+
+	exclude, err := os.ReadFile(filepath.Join(repo, ".git", "info", "exclude"))
 	if err != nil {
-		t.Fatalf("CopyOverlay() error = %v", err)
+		t.Fatalf("reading info/exclude: %v", err)
+	}
+	if !strings.Contains(string(exclude), "/.env\n/config/local.json\n") || strings.Contains(string(exclude), "README") {
+		t.Errorf("info/exclude = %q", exclude)
 	}
 
-	// Verify root file was copied
-	if _, err := os.Stat(filepath.Join(destDir, "test.txt")); err != nil {
-		t.Error("Root file should be copied")
+	// The tracked file rendered with a secret can't be committed either
+	committed := CommittedOverlayFiles(filepath.Join(worktree, "config"), []string{"main.go", ".env", "README.md", "settings.json"})
+	if strings.Join(committed, ",") != ".env,settings.json" {
+		t.Errorf("CommittedOverlayFiles() = %v", committed)
 	}
 
-	// Verify subdirectory was NOT copied
-	if _, err := os.Stat(filepath.Join(destDir, "subdir")); err == nil {
-		t.Error("Subdirectory should not be copied")
+	if err := RemoveOverlay(worktree); err != nil {
+		t.Fatalf("RemoveOverlay() error = %v", err)
+	}
+	for _, name := range []string{".env", "config"} {
+		if _, err := os.Stat(filepath.Join(worktree, name)); err == nil {
+			t.Errorf("%s should be removed", name)
+		}
+	}
+	for _, name := range []string{"README.md", "settings.json"} {
+		data, err := os.ReadFile(filepath.Join(worktree, name))
+		if err != nil || string(data) != "tracked" {
+			t.Errorf("%s = %q, %v; want the original restored", name, data, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(gitDir, overlayOriginalsDir)); err == nil {
+		t.Error("saved originals should be removed")
 	}
-	if _, err := os.Stat(filepath.Join(destDir, "subdir", "sub.txt")); err == nil {
-		t.Error("File in subdirectory should not be copied")
+	if files := OverlayFiles(worktree); files != nil {
+		t.Errorf("OverlayFiles() after removal = %v", files)
 	}
 }
 
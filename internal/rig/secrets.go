@@ -0,0 +1,331 @@
+package rig
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Secret store files under <rig>/.runtime/.
+const (
+	// secretsFile is the local store, encrypted with the key in
+	// SecretsKeyPath.
+	secretsFile = "secrets.enc"
+
+	// secretsAgeFile is an age-encrypted dotenv file (NAME=value lines),
+	// managed with the age tool and decrypted with the identity in
+	// $GT_AGE_IDENTITY (default ~/.config/age/keys.txt).
+	secretsAgeFile = "secrets.age"
+)
+
+// secretNamePattern is what secret names may look like: environment
+// variable style, so they can be rendered into .env files as they are.
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SecretStore provides the secrets overlay templates render with
+// {{secret "NAME"}}.
+type SecretStore interface {
+	// Get returns a secret's value.
+	Get(name string) (string, error)
+
+	// Names lists the stored secrets, sorted.
+	Names() ([]string, error)
+}
+
+// OpenSecretStore returns a rig's secret store: the age file when there is
+// one, else the local store (which may be empty). Nothing is decrypted
+// until a secret is read.
+func OpenSecretStore(rigPath string) SecretStore {
+	agePath := filepath.Join(rigPath, constants.DirRuntime, secretsAgeFile)
+	if _, err := os.Stat(agePath); err == nil {
+		return &ageSecretStore{path: agePath}
+	}
+	return NewLocalSecretStore(rigPath)
+}
+
+// SecretsKeyPath returns the key the local secret stores are encrypted
+// with: $GT_SECRETS_KEY, or ~/.config/gastown/secrets.key. The key lives
+// outside the town so that a copy of the town doesn't carry its secrets.
+func SecretsKeyPath() string {
+	if path := os.Getenv("GT_SECRETS_KEY"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, ".config", "gastown", "secrets.key")
+}
+
+// LocalSecretStore keeps a rig's secrets in <rig>/.runtime/secrets.enc,
+// encrypted with XChaCha20-Poly1305 under a key file readable only by its
+// owner. It stands in for an OS keyring.
+type LocalSecretStore struct {
+	path    string
+	keyPath string
+}
+
+// NewLocalSecretStore returns a rig's local secret store.
+func NewLocalSecretStore(rigPath string) *LocalSecretStore {
+	return &LocalSecretStore{
+		path:    filepath.Join(rigPath, constants.DirRuntime, secretsFile),
+		keyPath: SecretsKeyPath(),
+	}
+}
+
+// localSecretsFile is the on-disk form of a local store.
+type localSecretsFile struct {
+	Nonce string `json:"nonce"`
+	Data  string `json:"data"`
+}
+
+// Get decrypts secrets.enc with the key file on every call, so values
+// set by another gt process are seen at once.
+func (s *LocalSecretStore) Get(name string) (string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[name]
+	if !ok {
+		return "", fmt.Errorf("secret %s not set", name)
+	}
+	return value, nil
+}
+
+// Names reads the names from secrets.enc; a store with no file is empty.
+func (s *LocalSecretStore) Names() ([]string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return sortedKeys(secrets), nil
+}
+
+// Set stores a secret, creating the key on first use.
+func (s *LocalSecretStore) Set(name, value string) error {
+	if !secretNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q: use letters, digits and underscores", name)
+	}
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[name] = value
+	return s.save(secrets)
+}
+
+// Delete removes a secret.
+func (s *LocalSecretStore) Delete(name string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[name]; !ok {
+		return fmt.Errorf("secret %s not set", name)
+	}
+	delete(secrets, name)
+	return s.save(secrets)
+}
+
+// load decrypts the store. A missing store is empty.
+func (s *LocalSecretStore) load() (map[string]string, error) {
+	secrets := make(map[string]string)
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return secrets, nil
+		}
+		return nil, fmt.Errorf("reading secret store: %w", err)
+	}
+
+	var file localSecretsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing secret store: %w", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(file.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("parsing secret store: %w", err)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(file.Data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing secret store: %w", err)
+	}
+
+	key, err := s.key(false)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("parsing secret store: bad nonce")
+	}
+	plain, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting secret store (wrong key %s?): %w", s.keyPath, err)
+	}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("parsing secret store: %w", err)
+	}
+	return secrets, nil
+}
+
+// save encrypts and writes the store.
+func (s *LocalSecretStore) save(secrets map[string]string) error {
+	key, err := s.key(true)
+	if err != nil {
+		return err
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(localSecretsFile{
+		Nonce: base64.StdEncoding.EncodeToString(nonce),
+		Data:  base64.StdEncoding.EncodeToString(aead.Seal(nil, nonce, plain, nil)),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("creating runtime dir: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("writing secret store: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// key reads the encryption key, generating it if create is set.
+func (s *LocalSecretStore) key(create bool) ([]byte, error) {
+	data, err := os.ReadFile(s.keyPath)
+	if err == nil {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) != chacha20poly1305.KeySize {
+			return nil, fmt.Errorf("invalid secrets key %s", s.keyPath)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) || !create {
+		return nil, fmt.Errorf("reading secrets key: %w", err)
+	}
+
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.keyPath), 0700); err != nil {
+		return nil, fmt.Errorf("creating secrets key dir: %w", err)
+	}
+	if err := os.WriteFile(s.keyPath, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("writing secrets key: %w", err)
+	}
+	return key, nil
+}
+
+// ageSecretStore reads an age-encrypted dotenv file with the age CLI.
+type ageSecretStore struct {
+	path    string
+	secrets map[string]string
+}
+
+// Get runs age on the first call and answers later ones from memory.
+func (s *ageSecretStore) Get(name string) (string, error) {
+	if err := s.decrypt(); err != nil {
+		return "", err
+	}
+	value, ok := s.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("secret %s not in %s", name, secretsAgeFile)
+	}
+	return value, nil
+}
+
+// Names lists the variables of the decrypted dotenv file.
+func (s *ageSecretStore) Names() ([]string, error) {
+	if err := s.decrypt(); err != nil {
+		return nil, err
+	}
+	return sortedKeys(s.secrets), nil
+}
+
+// decrypt runs age once and caches the result.
+func (s *ageSecretStore) decrypt() error {
+	if s.secrets != nil {
+		return nil
+	}
+	identity := os.Getenv("GT_AGE_IDENTITY")
+	if identity == "" {
+		home, _ := os.UserHomeDir()
+		identity = filepath.Join(home, ".config", "age", "keys.txt")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("age", "--decrypt", "-i", identity, s.path)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("decrypting %s with age: %v: %s", secretsAgeFile, err, strings.TrimSpace(stderr.String()))
+	}
+	s.secrets = parseDotenv(stdout.String())
+	return nil
+}
+
+// parseDotenv reads NAME=value lines, skipping blanks and comments and
+// unquoting quoted values.
+func parseDotenv(text string) map[string]string {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[strings.TrimSpace(name)] = value
+	}
+	return values
+}
+
+// sortedKeys returns a map's keys, sorted.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,69 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLocalSecretStore(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "secrets.key")
+	t.Setenv("GT_SECRETS_KEY", keyPath)
+	rigDir := t.TempDir()
+	store := NewLocalSecretStore(rigDir)
+
+	if names, err := store.Names(); err != nil || len(names) != 0 {
+		t.Fatalf("empty store Names() = %v, %v", names, err)
+	}
+	if err := store.Set("bad-name", "x"); err == nil {
+		t.Error("expected an error for an invalid name")
+	}
+	if err := store.Set("DB_URL", "postgres://u:p@h/db"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("API_TOKEN", "tok"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing is stored in plaintext, and the key is private
+	data, err := os.ReadFile(filepath.Join(rigDir, ".runtime", "secrets.enc"))
+	if err != nil || strings.Contains(string(data), "postgres") {
+		t.Errorf("secret store = %q, %v", data, err)
+	}
+	if info, err := os.Stat(keyPath); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("key file mode = %v, %v", info.Mode(), err)
+	}
+
+	store = OpenSecretStore(rigDir).(*LocalSecretStore)
+	if v, err := store.Get("DB_URL"); err != nil || v != "postgres://u:p@h/db" {
+		t.Errorf("Get() = %q, %v", v, err)
+	}
+	if names, _ := store.Names(); !reflect.DeepEqual(names, []string{"API_TOKEN", "DB_URL"}) {
+		t.Errorf("Names() = %v", names)
+	}
+	if err := store.Delete("API_TOKEN"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("API_TOKEN"); err == nil {
+		t.Error("expected an error for a deleted secret")
+	}
+
+	// Another key can't read the store
+	t.Setenv("GT_SECRETS_KEY", filepath.Join(t.TempDir(), "other.key"))
+	if err := NewLocalSecretStore(t.TempDir()).Set("X", "y"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewLocalSecretStore(rigDir).Get("DB_URL"); err == nil {
+		t.Error("expected an error decrypting with the wrong key")
+	}
+}
+
+func TestParseDotenv(t *testing.T) {
+	got := parseDotenv("# comment\nA=1\nexport B=\"two words\"\n\nC='x=y'\nnot a pair\n")
+	want := map[string]string{"A": "1", "B": "two words", "C": "x=y"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDotenv() = %v", got)
+	}
+}
@@ -3,6 +3,7 @@ package rig
 
 import (
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/connection"
 )
 
 // Rig represents a managed repository in the workspace.
@@ -37,6 +38,16 @@ type Rig struct {
 
 	// HasMayor indicates if the rig has a mayor clone.
 	HasMayor bool `json:"has_mayor"`
+
+	// Machine is the federation machine hosting this rig ("" for local).
+	// For remote rigs, Path is the rig's path on that machine.
+	Machine string `json:"machine,omitempty"`
+}
+
+// Address returns the rig's federation address (machine:rig/).
+// Use Address().IsLocal() to decide between local and remote code paths.
+func (r *Rig) Address() *connection.Address {
+	return &connection.Address{Machine: r.Machine, Rig: r.Name}
 }
 
 // AgentDirs are the standard agent directories in a rig.
@@ -0,0 +1,228 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/costs"
+)
+
+// ErrUnsupported is returned by adapters for operations their agent CLI
+// doesn't have, e.g. parsing usage for an agent without transcripts.
+var ErrUnsupported = errors.New("not supported by this agent")
+
+// State is what an agent is doing, as seen in its pane.
+type State string
+
+// Agent states reported by Adapter.Observe.
+const (
+	StateUnknown     State = "unknown"
+	StateIdle        State = "idle"         // At its prompt, waiting for input
+	StateBusy        State = "busy"         // Working on a turn
+	StateRateLimited State = "rate-limited" // Showing a rate-limit or quota banner
+)
+
+// Observation is an adapter's reading of an agent's pane.
+type Observation struct {
+	State State `json:"state"`
+
+	// ResetAt is when a rate limit resets, if the agent says. Zero if unknown.
+	ResetAt time.Time `json:"reset_at,omitempty"`
+
+	// Detail is the line the state was read from (e.g. the rate-limit banner).
+	Detail string `json:"detail,omitempty"`
+}
+
+// Readiness is an adapter's reading of a starting agent's pane.
+type Readiness struct {
+	// Ready is set once the agent accepts input.
+	Ready bool `json:"ready"`
+
+	// Keys are tmux keys to send to get past a startup dialog, such as
+	// Claude Code's bypass permissions warning.
+	Keys []string `json:"keys,omitempty"`
+}
+
+// Pane is the tmux access adapters need; *tmux.Tmux implements it.
+type Pane interface {
+	CapturePane(session string, lines int) (string, error)
+	SendKeysRaw(session, keys string) error
+	NudgeSession(session, message string) error
+}
+
+// Adapter is everything Gas Town needs to know about one agent CLI.
+// Built-in adapters cover the presets in config; other CLIs can be added
+// with Register, or without recompiling by pointing an agents.json entry's
+// "adapter" at an executable that speaks the JSON protocol of
+// ExternalAdapter.
+type Adapter interface {
+	// Name is the agent name the adapter is registered under.
+	Name() string
+
+	// BuildCommand returns the command line that starts the agent with an
+	// optional initial prompt.
+	BuildCommand(rc *config.RuntimeConfig, prompt string) string
+
+	// ResumeCommand returns the command line that resumes a session, or ""
+	// if the agent can't resume.
+	ResumeCommand(rc *config.RuntimeConfig, sessionID string) string
+
+	// Readiness reads a starting agent's pane.
+	Readiness(pane string) Readiness
+
+	// InjectPrompt types a prompt into a running agent and submits it.
+	InjectPrompt(p Pane, session, prompt string) error
+
+	// Observe reads what a running agent is doing from its pane.
+	Observe(pane string) Observation
+
+	// ParseUsage returns the token usage of the agent's latest session in
+	// workDir, or ErrUnsupported.
+	ParseUsage(workDir string) (*costs.SessionUsage, error)
+
+	// InstallHooks installs the agent's hook or plugin settings for a role
+	// in workDir. Agents without hooks do nothing.
+	InstallHooks(workDir, role string, rc *config.RuntimeConfig) error
+
+	// StartupCommands returns the commands to nudge into a freshly started
+	// agent for a role, doing what its SessionStart hook would (gt prime,
+	// the mail check), or nil when its hooks already do.
+	StartupCommands(role string, rc *config.RuntimeConfig) []string
+
+	// Models lists the model IDs the agent can run, if it can tell.
+	Models() []string
+}
+
+var (
+	adaptersMu sync.RWMutex
+
+	// registered are adapters added with Register.
+	registered = make(map[string]Adapter)
+
+	// external caches adapters of agents.json entries with an "adapter",
+	// by agent name and executable, so capabilities are asked for once.
+	external = make(map[string]*ExternalAdapter)
+)
+
+// Register adds an adapter for an agent name, replacing a built-in one.
+func Register(name string, a Adapter) {
+	adaptersMu.Lock()
+	defer adaptersMu.Unlock()
+	registered[name] = a
+}
+
+// For returns the adapter of an agent: a registered adapter, then the
+// external adapter named by its agents.json entry, then the built-in adapter
+// of its preset. Unknown agents get a generic adapter, and an empty name is
+// Claude Code.
+func For(agentName string) Adapter {
+	if agentName == "" {
+		agentName = string(config.DefaultAgentPreset())
+	}
+
+	adaptersMu.RLock()
+	a := registered[agentName]
+	adaptersMu.RUnlock()
+	if a != nil {
+		return a
+	}
+
+	info := config.GetAgentPresetByName(agentName)
+	if info == nil {
+		info = &config.AgentPresetInfo{Name: config.AgentPreset(agentName), Command: agentName}
+	}
+	builtin := newPresetAdapter(agentName, info)
+	if info.Adapter == "" {
+		return builtin
+	}
+
+	key := agentName + "\x00" + info.Adapter
+	adaptersMu.Lock()
+	defer adaptersMu.Unlock()
+	ext := external[key]
+	if ext == nil {
+		ext = NewExternalAdapter(agentName, info.Adapter, builtin)
+		external[key] = ext
+	}
+	return ext
+}
+
+// ForRole returns the adapter of the agent a role runs, loading the town's
+// and rig's agents.json first. rigPath is empty for town-level roles;
+// agentOverride, if set, is the agent picked with --agent.
+func ForRole(role, townRoot, rigPath, agentOverride string) Adapter {
+	_ = config.LoadAgentRegistry(config.DefaultAgentRegistryPath(townRoot))
+	if rigPath != "" {
+		_ = config.LoadRigAgentRegistry(config.RigAgentRegistryPath(rigPath))
+	}
+	if agentOverride != "" {
+		return For(agentOverride)
+	}
+	agentName, _ := config.ResolveRoleAgentName(role, townRoot, rigPath)
+	return For(agentName)
+}
+
+// readyPaneLines is how much of a pane readiness and state are read from.
+const readyPaneLines = 30
+
+// WaitReady polls a starting agent's pane until its adapter says it is
+// ready for input, sending the keys the adapter asks for to dismiss
+// startup dialogs on the way.
+func WaitReady(p Pane, a Adapter, session string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		pane, err := p.CapturePane(session, readyPaneLines)
+		if err == nil {
+			r := a.Readiness(pane)
+			if r.Ready {
+				return nil
+			}
+			if len(r.Keys) > 0 {
+				for _, key := range r.Keys {
+					if err := p.SendKeysRaw(session, key); err != nil {
+						return err
+					}
+					time.Sleep(200 * time.Millisecond)
+				}
+				// Let the dialog go away before looking again
+				time.Sleep(time.Second)
+				continue
+			}
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return fmt.Errorf("timeout waiting for %s to be ready", a.Name())
+}
+
+// DismissDialogs looks once for a startup dialog in a freshly started
+// agent's pane and sends the keys its adapter gives to accept it. It is
+// the non-blocking part of WaitReady, for callers that can't wait on the
+// agent's prompt.
+func DismissDialogs(p Pane, a Adapter, session string) error {
+	// Give the dialog a moment to render
+	time.Sleep(time.Second)
+
+	pane, err := p.CapturePane(session, readyPaneLines)
+	if err != nil {
+		return err
+	}
+	for _, key := range a.Readiness(pane).Keys {
+		if err := p.SendKeysRaw(session, key); err != nil {
+			return err
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return nil
+}
+
+// ObserveSession reads what the agent in a session is doing.
+func ObserveSession(p Pane, a Adapter, session string) (Observation, error) {
+	pane, err := p.CapturePane(session, readyPaneLines)
+	if err != nil {
+		return Observation{State: StateUnknown}, err
+	}
+	return a.Observe(pane), nil
+}
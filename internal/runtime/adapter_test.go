@@ -0,0 +1,264 @@
+package runtime
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// fakePane replays captured panes and records what is sent.
+type fakePane struct {
+	captures []string
+	keys     []string
+	nudges   []string
+}
+
+func (f *fakePane) CapturePane(session string, lines int) (string, error) {
+	if len(f.captures) == 0 {
+		return "", errors.New("no more captures")
+	}
+	pane := f.captures[0]
+	if len(f.captures) > 1 {
+		f.captures = f.captures[1:]
+	}
+	return pane, nil
+}
+
+func (f *fakePane) SendKeysRaw(session, keys string) error {
+	f.keys = append(f.keys, keys)
+	return nil
+}
+
+func (f *fakePane) NudgeSession(session, message string) error {
+	f.nudges = append(f.nudges, message)
+	return nil
+}
+
+func TestPresetAdapter_Readiness(t *testing.T) {
+	claude := For("claude")
+
+	dialog := "WARNING: Claude Code running in Bypass Permissions mode\n❯ 1. No, exit\n  2. Yes, I accept\n"
+	if r := claude.Readiness(dialog); r.Ready || !reflect.DeepEqual(r.Keys, []string{"Down", "Enter"}) {
+		t.Errorf("Readiness(dialog) = %+v, want Down, Enter", r)
+	}
+	if r := claude.Readiness("Loading...\n"); r.Ready || len(r.Keys) > 0 {
+		t.Errorf("Readiness(loading) = %+v, want not ready", r)
+	}
+	if r := claude.Readiness("╭──────╮\n│ > Try \"fix lint errors\" │\n"); r.Ready {
+		t.Errorf("Readiness(boxed prompt) = %+v, want the prompt line to start with >", r)
+	}
+	if r := claude.Readiness("Welcome\n> \n? for shortcuts\n"); !r.Ready {
+		t.Errorf("Readiness(prompt) = %+v, want ready", r)
+	}
+
+	// Codex has no prompt markers; its ready delay covers startup
+	if r := For("codex").Readiness(""); !r.Ready {
+		t.Errorf("codex Readiness() = %+v, want ready", r)
+	}
+	if r := For("gemini").Readiness("> Type your message or @path/to/file\n"); !r.Ready {
+		t.Errorf("gemini Readiness() = %+v, want ready", r)
+	}
+}
+
+func TestPresetAdapter_Observe(t *testing.T) {
+	tests := []struct {
+		agent string
+		pane  string
+		want  State
+	}{
+		{"claude", "⏺ Reading files\n✻ Thinking… (12s · esc to interrupt)\n> \n", StateBusy},
+		{"claude", "⏺ Done.\n\n> \n", StateIdle},
+		{"claude", "⎿  Claude AI usage limit reached|4102444800\n> \n", StateRateLimited},
+		{"claude", "starting\n", StateUnknown},
+		{"gemini", "✕ [API Error: Quota exceeded for quota metric]\n", StateRateLimited},
+		{"codex", "• Working (5s • esc to interrupt)\n", StateBusy},
+		{"codex", "› \n", StateUnknown},
+		{"amp", "Error: 429 Too Many Requests\n", StateRateLimited},
+	}
+	for _, tt := range tests {
+		got := For(tt.agent).Observe(tt.pane)
+		if got.State != tt.want {
+			t.Errorf("%s Observe(%q) = %+v, want %s", tt.agent, tt.pane, got, tt.want)
+		}
+	}
+
+	obs := For("claude").Observe("Claude AI usage limit reached|4102444800\n")
+	if !obs.ResetAt.Equal(time.Unix(4102444800, 0)) {
+		t.Errorf("ResetAt = %v, want the banner's reset time", obs.ResetAt)
+	}
+}
+
+func TestPresetAdapter_ResumeCommand(t *testing.T) {
+	tests := []struct {
+		agent string
+		rc    *config.RuntimeConfig
+		id    string
+		want  string
+	}{
+		{"claude", config.RuntimeConfigFromPreset(config.AgentClaude), "abc", "claude --dangerously-skip-permissions --resume abc"},
+		{"codex", config.RuntimeConfigFromPreset(config.AgentCodex), "abc", "codex resume abc --yolo"},
+		{"amp", config.RuntimeConfigFromPreset(config.AgentAmp), "T-1", "amp threads continue T-1 --dangerously-allow-all --no-ide"},
+		{"claude", config.RuntimeConfigFromPreset(config.AgentClaude), "", ""},
+		{"unknown-agent", &config.RuntimeConfig{Command: "unknown-agent", Args: []string{}}, "abc", ""},
+	}
+	for _, tt := range tests {
+		if got := For(tt.agent).ResumeCommand(tt.rc, tt.id); got != tt.want {
+			t.Errorf("%s ResumeCommand(%q) = %q, want %q", tt.agent, tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestWaitReady_AcceptsDialog(t *testing.T) {
+	pane := &fakePane{captures: []string{
+		"Loading...",
+		"Bypass Permissions mode\n❯ 1. No, exit\n  2. Yes, I accept",
+		"> ",
+	}}
+	if err := WaitReady(pane, For("claude"), "gt-test", 5*time.Second); err != nil {
+		t.Fatalf("WaitReady() error = %v", err)
+	}
+	if !reflect.DeepEqual(pane.keys, []string{"Down", "Enter"}) {
+		t.Errorf("keys sent = %v, want Down, Enter", pane.keys)
+	}
+}
+
+func TestWaitReady_Timeout(t *testing.T) {
+	pane := &fakePane{captures: []string{"Loading..."}}
+	if err := WaitReady(pane, For("claude"), "gt-test", 300*time.Millisecond); err == nil {
+		t.Error("WaitReady() should time out when the prompt never shows")
+	}
+}
+
+// writeAdapter writes an external adapter script.
+func writeAdapter(t *testing.T, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "adapter.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nreq=$(cat)\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExternalAdapter(t *testing.T) {
+	path := writeAdapter(t, `case "$req" in
+  *'"method":"describe"'*) echo '{"result":{"methods":["build_command","observe","inject_prompt","parse_usage","startup_commands"]}}' ;;
+  *'"method":"startup_commands"'*) echo '{"result":{"commands":["my-agent-prime"]}}' ;;
+  *'"method":"build_command"'*) echo '{"result":{"command":"my-agent --auto"}}' ;;
+  *'"method":"observe"'*) echo '{"result":{"state":"rate-limited","reset_at":"2030-01-01T00:00:00Z","detail":"slow down"}}' ;;
+  *'"method":"inject_prompt"'*) echo '{"result":{"text":"/run hello","keys":["Escape"]}}' ;;
+  *'"method":"parse_usage"'*) echo '{"result":{"session_id":"s1","models":{"m1":{"input_tokens":10,"output_tokens":5}}}}' ;;
+  *) echo "unexpected request: $req" >&2; exit 1 ;;
+esac
+`)
+	info := &config.AgentPresetInfo{Name: "my-agent", Command: "my-agent", ResumeFlag: "--continue", ResumeStyle: "flag"}
+	a := NewExternalAdapter("my-agent", path, newPresetAdapter("my-agent", info))
+	rc := &config.RuntimeConfig{Provider: "generic", Command: "my-agent", Args: []string{}}
+
+	if got := a.BuildCommand(rc, ""); got != "my-agent --auto" {
+		t.Errorf("BuildCommand() = %q", got)
+	}
+
+	obs := a.Observe("anything")
+	if obs.State != StateRateLimited || obs.Detail != "slow down" || obs.ResetAt.Year() != 2030 {
+		t.Errorf("Observe() = %+v", obs)
+	}
+
+	pane := &fakePane{}
+	if err := a.InjectPrompt(pane, "gt-test", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pane.keys, []string{"Escape"}) || !reflect.DeepEqual(pane.nudges, []string{"/run hello"}) {
+		t.Errorf("InjectPrompt sent keys %v and nudges %v", pane.keys, pane.nudges)
+	}
+
+	usage, err := a.ParseUsage("/work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Runtime != "my-agent" || usage.Models["m1"].Model != "m1" || usage.Models["m1"].Total() != 15 {
+		t.Errorf("ParseUsage() = %+v", usage)
+	}
+
+	if got := a.StartupCommands("polecat", rc); !reflect.DeepEqual(got, []string{"my-agent-prime"}) {
+		t.Errorf("StartupCommands() = %v", got)
+	}
+
+	// Methods the adapter didn't describe use the preset
+	if got := a.ResumeCommand(rc, "s1"); got != "my-agent --continue s1" {
+		t.Errorf("ResumeCommand() = %q, want the preset's", got)
+	}
+	if r := a.Readiness(""); !r.Ready {
+		t.Errorf("Readiness() = %+v, want the preset's", r)
+	}
+}
+
+func TestExternalAdapter_UnsupportedAndErrors(t *testing.T) {
+	path := writeAdapter(t, `case "$req" in
+  *'"method":"observe"'*) echo '{"error":"pane unreadable"}' ;;
+  *'"method":"install_hooks"'*) echo '{"error":"no settings dir"}' ;;
+  *) echo '{"unsupported":true}' ;;
+esac
+`)
+	a := NewExternalAdapter("my-agent", path, newPresetAdapter("my-agent", &config.AgentPresetInfo{Name: "my-agent"}))
+
+	// Observation falls back to the preset when the adapter fails
+	if obs := a.Observe("Error: 429 Too Many Requests"); obs.State != StateRateLimited {
+		t.Errorf("Observe() = %+v, want the preset's reading", obs)
+	}
+	if err := a.InstallHooks(t.TempDir(), "polecat", nil); err == nil {
+		t.Error("InstallHooks() should return the adapter's error")
+	}
+	if _, err := a.ParseUsage("/work"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("ParseUsage() error = %v, want ErrUnsupported from the preset", err)
+	}
+	rc := &config.RuntimeConfig{Hooks: &config.RuntimeHooksConfig{Provider: "none"}}
+	if got := a.StartupCommands("polecat", rc); !reflect.DeepEqual(got, StartupFallbackCommands("polecat", rc)) {
+		t.Errorf("StartupCommands() = %v, want the preset's", got)
+	}
+}
+
+func TestFor_RegistryAndAgentsJSON(t *testing.T) {
+	config.ResetRegistryForTesting()
+	t.Cleanup(config.ResetRegistryForTesting)
+
+	registry := filepath.Join(t.TempDir(), "agents.json")
+	data, _ := json.Marshal(map[string]any{
+		"version": 1,
+		"agents": map[string]any{
+			"my-agent": map[string]any{"command": "my-agent", "args": []string{}, "adapter": "/opt/my-agent-adapter"},
+		},
+	})
+	if err := os.WriteFile(registry, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.LoadAgentRegistry(registry); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := For("my-agent").(*ExternalAdapter); !ok {
+		t.Errorf("For(my-agent) = %T, want *ExternalAdapter", For("my-agent"))
+	}
+	if For("my-agent") != For("my-agent") {
+		t.Error("For() should reuse an external adapter")
+	}
+	if got := For("").Name(); got != "claude" {
+		t.Errorf("For(\"\").Name() = %q, want claude", got)
+	}
+
+	custom := newPresetAdapter("custom", &config.AgentPresetInfo{Name: "custom"})
+	Register("claude", custom)
+	t.Cleanup(func() {
+		adaptersMu.Lock()
+		delete(registered, "claude")
+		adaptersMu.Unlock()
+	})
+	if For("claude") != Adapter(custom) {
+		t.Error("Register() should replace the built-in adapter")
+	}
+}
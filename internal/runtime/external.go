@@ -0,0 +1,272 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/costs"
+)
+
+// ProtocolVersion is the version of the external adapter protocol.
+const ProtocolVersion = 1
+
+// externalCallTimeout bounds one call to an external adapter.
+const externalCallTimeout = 10 * time.Second
+
+// External adapter methods.
+const (
+	methodDescribe      = "describe"
+	methodBuildCommand  = "build_command"
+	methodResumeCommand = "resume_command"
+	methodReadiness     = "readiness"
+	methodInjectPrompt  = "inject_prompt"
+	methodObserve       = "observe"
+	methodParseUsage    = "parse_usage"
+	methodInstallHooks  = "install_hooks"
+	methodStartup       = "startup_commands"
+	methodModels        = "models"
+)
+
+// ExternalRequest is what an external adapter reads from stdin.
+type ExternalRequest struct {
+	Version int            `json:"version"`
+	Method  string         `json:"method"`
+	Agent   string         `json:"agent"`
+	Params  map[string]any `json:"params,omitempty"`
+}
+
+// ExternalResponse is what an external adapter writes to stdout.
+type ExternalResponse struct {
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	Unsupported bool            `json:"unsupported,omitempty"`
+}
+
+// ExternalAdapter runs an executable to adapt an agent CLI Gas Town doesn't
+// know. The executable is run once per call with one ExternalRequest as JSON
+// on stdin, and answers with one ExternalResponse on stdout:
+//
+//	{"version":1,"method":"observe","agent":"my-agent","params":{"pane":"..."}}
+//	{"result":{"state":"busy"}}
+//
+// Methods, their params and results:
+//
+//	describe         {}                           {"methods":["observe",...]}
+//	build_command    {command,args,prompt}        {"command":"..."}
+//	resume_command   {command,args,session_id}    {"command":"..."} ("" if it can't resume)
+//	readiness        {pane}                       {"ready":bool,"keys":["Enter"]}
+//	inject_prompt    {prompt}                     {"text":"...","keys":["Escape"]}
+//	observe          {pane}                       {"state":"idle|busy|rate-limited|unknown","reset_at":"RFC 3339","detail":"..."}
+//	parse_usage      {work_dir}                   {"session_id":"...","models":{"<model>":{"input_tokens":1,"output_tokens":2}}}
+//	install_hooks    {work_dir,role}              {}
+//	startup_commands {role}                       {"commands":["gt prime"]}
+//	models           {}                           {"models":["..."]}
+//
+// inject_prompt returns the text to submit and keys to press before typing
+// it. An adapter answers {"unsupported":true} for methods it leaves to Gas
+// Town, which then uses the agent's preset (its command, args and resume
+// flag from agents.json); describe lets it list the methods it implements
+// so the others aren't run at all. Errors are reported as {"error":"..."}
+// or a non-zero exit.
+type ExternalAdapter struct {
+	name     string
+	path     string
+	fallback Adapter
+
+	describeOnce sync.Once
+	methods      map[string]bool // nil if the adapter didn't describe itself
+}
+
+// NewExternalAdapter returns an adapter that runs the executable at path,
+// using fallback for the methods it doesn't implement.
+func NewExternalAdapter(name, path string, fallback Adapter) *ExternalAdapter {
+	return &ExternalAdapter{name: name, path: path, fallback: fallback}
+}
+
+// call runs one method. Methods the adapter doesn't implement return
+// ErrUnsupported.
+func (e *ExternalAdapter) call(method string, params map[string]any, result any) error {
+	if method != methodDescribe {
+		e.describeOnce.Do(e.describe)
+		if e.methods != nil && !e.methods[method] {
+			return ErrUnsupported
+		}
+	}
+
+	req, err := json.Marshal(ExternalRequest{Version: ProtocolVersion, Method: method, Agent: e.name, Params: params})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), externalCallTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, e.path) //nolint:gosec // G204: adapter path comes from agents.json
+	cmd.Stdin = bytes.NewReader(req)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("adapter %s %s: %w: %s", e.name, method, err, msg)
+		}
+		return fmt.Errorf("adapter %s %s: %w", e.name, method, err)
+	}
+
+	var resp ExternalResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return fmt.Errorf("adapter %s %s: parsing response: %w", e.name, method, err)
+	}
+	if resp.Unsupported {
+		return ErrUnsupported
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("adapter %s %s: %s", e.name, method, resp.Error)
+	}
+	if result != nil && len(resp.Result) > 0 {
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("adapter %s %s: parsing result: %w", e.name, method, err)
+		}
+	}
+	return nil
+}
+
+// describe asks the adapter which methods it implements. Adapters that
+// can't say are asked for every method.
+func (e *ExternalAdapter) describe() {
+	var result struct {
+		Methods []string `json:"methods"`
+	}
+	if err := e.call(methodDescribe, nil, &result); err != nil || result.Methods == nil {
+		return
+	}
+	e.methods = make(map[string]bool, len(result.Methods))
+	for _, m := range result.Methods {
+		e.methods[m] = true
+	}
+}
+
+func (e *ExternalAdapter) Name() string { return e.name }
+
+func (e *ExternalAdapter) BuildCommand(rc *config.RuntimeConfig, prompt string) string {
+	var result struct {
+		Command string `json:"command"`
+	}
+	params := commandParams(rc)
+	params["prompt"] = prompt
+	if err := e.call(methodBuildCommand, params, &result); err != nil || result.Command == "" {
+		return e.fallback.BuildCommand(rc, prompt)
+	}
+	return result.Command
+}
+
+func (e *ExternalAdapter) ResumeCommand(rc *config.RuntimeConfig, sessionID string) string {
+	var result struct {
+		Command string `json:"command"`
+	}
+	params := commandParams(rc)
+	params["session_id"] = sessionID
+	if err := e.call(methodResumeCommand, params, &result); err != nil {
+		return e.fallback.ResumeCommand(rc, sessionID)
+	}
+	return result.Command
+}
+
+func (e *ExternalAdapter) Readiness(pane string) Readiness {
+	var result Readiness
+	if err := e.call(methodReadiness, map[string]any{"pane": pane}, &result); err != nil {
+		return e.fallback.Readiness(pane)
+	}
+	return result
+}
+
+func (e *ExternalAdapter) InjectPrompt(p Pane, session, prompt string) error {
+	var result struct {
+		Text string   `json:"text"`
+		Keys []string `json:"keys"`
+	}
+	if err := e.call(methodInjectPrompt, map[string]any{"prompt": prompt}, &result); err != nil {
+		if errors.Is(err, ErrUnsupported) {
+			return e.fallback.InjectPrompt(p, session, prompt)
+		}
+		return err
+	}
+	for _, key := range result.Keys {
+		if err := p.SendKeysRaw(session, key); err != nil {
+			return err
+		}
+	}
+	if result.Text == "" {
+		result.Text = prompt
+	}
+	return p.NudgeSession(session, result.Text)
+}
+
+func (e *ExternalAdapter) Observe(pane string) Observation {
+	var result Observation
+	if err := e.call(methodObserve, map[string]any{"pane": pane}, &result); err != nil || result.State == "" {
+		return e.fallback.Observe(pane)
+	}
+	return result
+}
+
+func (e *ExternalAdapter) ParseUsage(workDir string) (*costs.SessionUsage, error) {
+	var result costs.SessionUsage
+	if err := e.call(methodParseUsage, map[string]any{"work_dir": workDir}, &result); err != nil {
+		if errors.Is(err, ErrUnsupported) {
+			return e.fallback.ParseUsage(workDir)
+		}
+		return nil, err
+	}
+	if result.Runtime == "" {
+		result.Runtime = e.name
+	}
+	for model, u := range result.Models {
+		u.Model = model
+		result.Models[model] = u
+	}
+	return &result, nil
+}
+
+func (e *ExternalAdapter) InstallHooks(workDir, role string, rc *config.RuntimeConfig) error {
+	err := e.call(methodInstallHooks, map[string]any{"work_dir": workDir, "role": role}, nil)
+	if errors.Is(err, ErrUnsupported) {
+		return e.fallback.InstallHooks(workDir, role, rc)
+	}
+	return err
+}
+
+func (e *ExternalAdapter) StartupCommands(role string, rc *config.RuntimeConfig) []string {
+	var result struct {
+		Commands []string `json:"commands"`
+	}
+	if err := e.call(methodStartup, map[string]any{"role": role}, &result); err != nil {
+		return e.fallback.StartupCommands(role, rc)
+	}
+	return result.Commands
+}
+
+func (e *ExternalAdapter) Models() []string {
+	var result struct {
+		Models []string `json:"models"`
+	}
+	if err := e.call(methodModels, nil, &result); err != nil {
+		return e.fallback.Models()
+	}
+	return result.Models
+}
+
+// commandParams are the params of the command-building methods.
+func commandParams(rc *config.RuntimeConfig) map[string]any {
+	if rc == nil {
+		rc = config.DefaultRuntimeConfig()
+	}
+	return map[string]any{"command": rc.Command, "args": rc.Args}
+}
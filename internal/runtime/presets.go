@@ -0,0 +1,215 @@
+package runtime
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/account"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/costs"
+	"github.com/steveyegge/gastown/internal/models"
+)
+
+// dialog is a startup dialog an agent shows before it accepts input.
+type dialog struct {
+	marker string   // Text that identifies the dialog
+	keys   []string // Keys that accept it
+}
+
+// traits are the pane conventions and files of an agent CLI.
+type traits struct {
+	// promptPrefixes start the agent's input line; readyMarkers appear
+	// anywhere once it accepts input. Agents with neither are taken to be
+	// ready at once (the runtime's ready delay covers their startup).
+	promptPrefixes []string
+	readyMarkers   []string
+
+	// busyMarkers appear while the agent is working on a turn.
+	busyMarkers []string
+
+	dialogs []dialog
+
+	// detectRateLimit finds a rate-limit banner; nil uses
+	// genericRateLimitPatterns.
+	detectRateLimit func(pane string, now time.Time) *account.RateLimit
+
+	// usageRuntime is the costs runtime whose transcripts the agent writes,
+	// or "" if its usage can't be read.
+	usageRuntime string
+}
+
+// builtinTraits are the traits of the built-in presets. Agents missing
+// here are handled by their preset alone.
+var builtinTraits = map[config.AgentPreset]traits{
+	config.AgentClaude: {
+		promptPrefixes:  []string{">"},
+		readyMarkers:    []string{"Recent activity"},
+		busyMarkers:     []string{"esc to interrupt"},
+		dialogs:         []dialog{{marker: "Bypass Permissions mode", keys: []string{"Down", "Enter"}}},
+		detectRateLimit: account.DetectRateLimit,
+		usageRuntime:    costs.RuntimeClaude,
+	},
+	config.AgentCodex: {
+		busyMarkers:  []string{"esc to interrupt"},
+		usageRuntime: costs.RuntimeCodex,
+	},
+	config.AgentGemini: {
+		readyMarkers: []string{"Type your message"},
+		busyMarkers:  []string{"esc to cancel"},
+	},
+}
+
+// genericRateLimitPatterns match the rate-limit and quota errors agent
+// CLIs commonly print.
+var genericRateLimitPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)usage limit`),
+	regexp.MustCompile(`(?i)quota exceeded|RESOURCE_EXHAUSTED`),
+	regexp.MustCompile(`(?i)rate[ _-]?limit(ed)?\b.*\b(exceeded|reached|hit)\b`),
+	regexp.MustCompile(`(?i)\b429\b.*too many requests`),
+}
+
+// presetAdapter is the built-in adapter of an agents.json preset.
+type presetAdapter struct {
+	name   string
+	info   *config.AgentPresetInfo
+	traits traits
+}
+
+func newPresetAdapter(name string, info *config.AgentPresetInfo) *presetAdapter {
+	return &presetAdapter{name: name, info: info, traits: builtinTraits[config.AgentPreset(name)]}
+}
+
+func (a *presetAdapter) Name() string { return a.name }
+
+func (a *presetAdapter) BuildCommand(rc *config.RuntimeConfig, prompt string) string {
+	if prompt == "" {
+		return rc.BuildCommand()
+	}
+	return rc.BuildCommandWithPrompt(prompt)
+}
+
+// ResumeCommand resumes in the preset's style, keeping the runtime's
+// command and args: "claude <args> --resume <id>" or
+// "codex resume <id> <args>".
+func (a *presetAdapter) ResumeCommand(rc *config.RuntimeConfig, sessionID string) string {
+	if sessionID == "" || a.info.ResumeFlag == "" {
+		return ""
+	}
+	command := rc.BuildCommand()
+	if a.info.ResumeStyle == "subcommand" {
+		name, args, _ := strings.Cut(command, " ")
+		command = name + " " + a.info.ResumeFlag + " " + sessionID
+		if args != "" {
+			command += " " + args
+		}
+		return command
+	}
+	return command + " " + a.info.ResumeFlag + " " + sessionID
+}
+
+func (a *presetAdapter) Readiness(pane string) Readiness {
+	for _, d := range a.traits.dialogs {
+		if strings.Contains(pane, d.marker) {
+			return Readiness{Keys: d.keys}
+		}
+	}
+	if len(a.traits.promptPrefixes) == 0 && len(a.traits.readyMarkers) == 0 {
+		return Readiness{Ready: true}
+	}
+	return Readiness{Ready: a.atPrompt(pane)}
+}
+
+// atPrompt reports whether the pane shows the agent's input prompt.
+func (a *presetAdapter) atPrompt(pane string) bool {
+	for _, line := range strings.Split(pane, "\n") {
+		for _, marker := range a.traits.readyMarkers {
+			if strings.Contains(line, marker) {
+				return true
+			}
+		}
+		trimmed := strings.TrimSpace(line)
+		for _, prefix := range a.traits.promptPrefixes {
+			if trimmed == prefix || strings.HasPrefix(trimmed, prefix+" ") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a *presetAdapter) InjectPrompt(p Pane, session, prompt string) error {
+	return p.NudgeSession(session, prompt)
+}
+
+func (a *presetAdapter) Observe(pane string) Observation {
+	now := time.Now()
+	if limit := a.rateLimit(pane, now); limit != nil {
+		return Observation{State: StateRateLimited, ResetAt: limit.ResetAt, Detail: limit.Banner}
+	}
+
+	lines := lastLines(pane, rateLimitLines)
+	for _, line := range lines {
+		for _, marker := range a.traits.busyMarkers {
+			if strings.Contains(strings.ToLower(line), marker) {
+				return Observation{State: StateBusy, Detail: strings.TrimSpace(line)}
+			}
+		}
+	}
+	if (len(a.traits.promptPrefixes) > 0 || len(a.traits.readyMarkers) > 0) &&
+		a.atPrompt(strings.Join(lines, "\n")) {
+		return Observation{State: StateIdle}
+	}
+	return Observation{State: StateUnknown}
+}
+
+// rateLimitLines is how many of a pane's last lines are read for its state.
+const rateLimitLines = 15
+
+func (a *presetAdapter) rateLimit(pane string, now time.Time) *account.RateLimit {
+	if a.traits.detectRateLimit != nil {
+		return a.traits.detectRateLimit(pane, now)
+	}
+	lines := lastLines(pane, rateLimitLines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		for _, pattern := range genericRateLimitPatterns {
+			if pattern.MatchString(line) {
+				return &account.RateLimit{Banner: line, ResetAt: now.Add(account.DefaultRateLimitCooldown)}
+			}
+		}
+	}
+	return nil
+}
+
+func (a *presetAdapter) ParseUsage(workDir string) (*costs.SessionUsage, error) {
+	if a.traits.usageRuntime == "" {
+		return nil, ErrUnsupported
+	}
+	path, err := costs.FindTranscript(a.traits.usageRuntime, workDir)
+	if err != nil {
+		return nil, err
+	}
+	return costs.ParseFile(a.traits.usageRuntime, path)
+}
+
+func (a *presetAdapter) InstallHooks(workDir, role string, rc *config.RuntimeConfig) error {
+	return EnsureSettingsForRole(workDir, role, rc)
+}
+
+func (a *presetAdapter) StartupCommands(role string, rc *config.RuntimeConfig) []string {
+	return StartupFallbackCommands(role, rc)
+}
+
+func (a *presetAdapter) Models() []string {
+	return models.Discover(a.name)
+}
+
+// lastLines returns the last n lines of pane output.
+func lastLines(pane string, n int) []string {
+	lines := strings.Split(strings.TrimRight(pane, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/steveyegge/gastown/internal/claude"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/opencode"
-	"github.com/steveyegge/gastown/internal/tmux"
 )
 
 // EnsureSettingsForRole installs runtime hook settings when supported.
@@ -55,6 +54,7 @@ func SleepForReadyDelay(rc *config.RuntimeConfig) {
 }
 
 // StartupFallbackCommands returns commands that approximate Claude hooks when hooks are unavailable.
+// It is the built-in adapters' StartupCommands.
 func StartupFallbackCommands(role string, rc *config.RuntimeConfig) []string {
 	if rc == nil {
 		rc = config.DefaultRuntimeConfig()
@@ -73,11 +73,10 @@ func StartupFallbackCommands(role string, rc *config.RuntimeConfig) []string {
 	return []string{command}
 }
 
-// RunStartupFallback sends the startup fallback commands via tmux.
-func RunStartupFallback(t *tmux.Tmux, sessionID, role string, rc *config.RuntimeConfig) error {
-	commands := StartupFallbackCommands(role, rc)
-	for _, cmd := range commands {
-		if err := t.NudgeSession(sessionID, cmd); err != nil {
+// RunStartupFallback sends the adapter's startup commands via tmux.
+func RunStartupFallback(p Pane, a Adapter, sessionID, role string, rc *config.RuntimeConfig) error {
+	for _, cmd := range a.StartupCommands(role, rc) {
+		if err := p.NudgeSession(sessionID, cmd); err != nil {
 			return err
 		}
 	}
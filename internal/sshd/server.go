@@ -27,6 +27,7 @@ type Server struct {
 	workDir    string
 	hostKey    ssh.Signer
 	authorizedKeys map[string]bool
+	execShell  bool
 	mu         sync.RWMutex
 	running    bool
 }
@@ -39,6 +40,12 @@ type Config struct {
 	WorkDir        string
 	HostKeyPath    string
 	AuthorizedKeys []string
+
+	// ExecShell makes exec requests behave like OpenSSH: the command string
+	// runs through "sh -c" with stdin, stderr and the exit status forwarded,
+	// instead of going through the built-in command set. This lets the server
+	// stand in for a real sshd behind connection.SSHConnection.
+	ExecShell bool
 }
 
 // New creates a new SSH server for an agent.
@@ -56,6 +63,7 @@ func New(cfg Config) (*Server, error) {
 		workDir:        cfg.WorkDir,
 		hostKey:        hostKey,
 		authorizedKeys: make(map[string]bool),
+		execShell:      cfg.ExecShell,
 	}
 
 	// Parse authorized keys
@@ -113,7 +121,16 @@ func (s *Server) Stop() error {
 }
 
 // Port returns the server's port.
+// If the server was configured with port 0, this is the port chosen by the OS
+// once the server has started.
 func (s *Server) Port() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.listener != nil {
+		if addr, ok := s.listener.Addr().(*net.TCPAddr); ok {
+			return addr.Port
+		}
+	}
 	return s.port
 }
 
@@ -188,7 +205,11 @@ func (s *Server) handleSession(channel ssh.Channel, requests <-chan *ssh.Request
 			req.Reply(true, nil)
 			var payload struct{ Command string }
 			ssh.Unmarshal(req.Payload, &payload)
-			s.handleExec(channel, payload.Command)
+			if s.execShell {
+				s.handleShellExec(channel, payload.Command)
+			} else {
+				s.handleExec(channel, payload.Command)
+			}
 			return
 
 		case "pty-req":
@@ -279,6 +300,26 @@ func (s *Server) handleExec(channel ssh.Channel, command string) {
 	channel.SendRequest("exit-status", false, exitStatus)
 }
 
+// handleShellExec runs command through sh -c, forwarding stdio and the
+// real exit status (ExecShell mode).
+func (s *Server) handleShellExec(channel ssh.Channel, command string) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = s.workDir
+	cmd.Stdin = channel
+	cmd.Stdout = channel
+	cmd.Stderr = channel.Stderr()
+
+	var status uint32
+	if err := cmd.Run(); err != nil {
+		status = 1
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() > 0 {
+			status = uint32(exitErr.ExitCode())
+		}
+	}
+
+	channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+}
+
 func (s *Server) executeCommand(command string) string {
 	parts := strings.Fields(command)
 	if len(parts) == 0 {
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
 )
 
 // EventSource represents a source of events
@@ -231,7 +232,7 @@ type GtEvent struct {
 
 // NewGtEventsSource creates a source that tails ~/gt/.events.jsonl
 func NewGtEventsSource(townRoot string) (*GtEventsSource, error) {
-	eventsPath := filepath.Join(townRoot, ".events.jsonl")
+	eventsPath := filepath.Join(townRoot, events.EventsFile)
 	file, err := os.Open(eventsPath)
 	if err != nil {
 		return nil, err
@@ -250,14 +251,16 @@ func NewGtEventsSource(townRoot string) (*GtEventsSource, error) {
 	return source, nil
 }
 
-// tail follows the file and sends events
+// tail follows the file and sends events.
+// The tail goroutine owns s.file and closes it on exit.
 func (s *GtEventsSource) tail(ctx context.Context) {
 	defer close(s.events)
+	defer func() { _ = s.file.Close() }()
 
 	// Seek to end for live tailing
 	_, _ = s.file.Seek(0, 2)
 
-	scanner := bufio.NewScanner(s.file)
+	reader := bufio.NewReader(s.file)
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
@@ -266,8 +269,11 @@ func (s *GtEventsSource) tail(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			for scanner.Scan() {
-				line := scanner.Text()
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					break // No more data available
+				}
 				if event := parseGtEventLine(line); event != nil {
 					select {
 					case s.events <- *event:
@@ -275,6 +281,15 @@ func (s *GtEventsSource) tail(ctx context.Context) {
 					}
 				}
 			}
+
+			// Follow the events log across rotation
+			if events.IsRotated(s.file) {
+				if next, err := os.Open(s.file.Name()); err == nil {
+					_ = s.file.Close()
+					s.file = next
+					reader = bufio.NewReader(s.file)
+				}
+			}
 		}
 	}
 }
@@ -287,7 +302,7 @@ func (s *GtEventsSource) Events() <-chan Event {
 // Close stops the source
 func (s *GtEventsSource) Close() error {
 	s.cancel()
-	return s.file.Close()
+	return nil
 }
 
 // parseGtEventLine parses a line from .events.jsonl
@@ -300,7 +315,12 @@ func parseGtEventLine(line string) *Event {
 	if err := json.Unmarshal([]byte(line), &ge); err != nil {
 		return nil
 	}
+	return gtEventToEvent(ge, line)
+}
 
+// gtEventToEvent converts a gt event to a feed event, or returns nil for
+// events that aren't feed-visible.
+func gtEventToEvent(ge GtEvent, line string) *Event {
 	// Only show feed-visible events
 	if ge.Visibility != "feed" && ge.Visibility != "both" {
 		return nil
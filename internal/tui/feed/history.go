@@ -0,0 +1,131 @@
+package feed
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	curated "github.com/steveyegge/gastown/internal/feed"
+)
+
+// historyPageSize is how many raw events each step back in time reads.
+const historyPageSize = 500
+
+// HistoryPage is a batch of past events loaded for time-travel scrolling.
+type HistoryPage struct {
+	// Events are the feed-visible events found, oldest first.
+	Events []Event
+
+	// Before is where the next page starts: the time of the oldest event
+	// read, visible or not.
+	Before time.Time
+
+	// Exhausted is set when there is nothing older to load.
+	Exhausted bool
+}
+
+// HistoryLoader loads the page of events before a time.
+type HistoryLoader func(before time.Time) (HistoryPage, error)
+
+// NewHistoryLoader returns a loader that reads the town's events log,
+// including rotated segments, and falls back to the curated feed
+// (.feed.jsonl) once the raw log has nothing older.
+func NewHistoryLoader(townRoot string) HistoryLoader {
+	return func(before time.Time) (HistoryPage, error) {
+		return LoadHistory(townRoot, before, historyPageSize)
+	}
+}
+
+// LoadHistory returns up to limit events from before a time (inclusive, as
+// log timestamps only have second precision; the model drops repeats).
+func LoadHistory(townRoot string, before time.Time, limit int) (HistoryPage, error) {
+	raw, err := events.Query(townRoot, events.Filter{Until: before, Limit: limit})
+	if err != nil {
+		return HistoryPage{}, err
+	}
+	if len(raw) == 0 {
+		return loadCuratedHistory(townRoot, before, limit)
+	}
+
+	page := HistoryPage{Before: before, Exhausted: len(raw) < limit}
+	for i, e := range raw {
+		ts, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil {
+			continue
+		}
+		if i == 0 || ts.Before(page.Before) {
+			page.Before = ts
+		}
+		line, _ := json.Marshal(e)
+		if ev := gtEventToEvent(GtEvent(e), string(line)); ev != nil {
+			page.Events = append(page.Events, *ev)
+		}
+	}
+	if page.Exhausted {
+		// The raw log may have been pruned: carry on into the curated feed
+		older, err := loadCuratedHistory(townRoot, page.Before.Add(-time.Second), limit)
+		if err == nil && len(older.Events) > 0 {
+			page.Events = append(older.Events, page.Events...)
+			page.Before = older.Before
+		}
+	}
+	return page, nil
+}
+
+// loadCuratedHistory reads the last limit curated feed events before a time.
+func loadCuratedHistory(townRoot string, before time.Time, limit int) (HistoryPage, error) {
+	page := HistoryPage{Before: before, Exhausted: true}
+	f, err := os.Open(filepath.Join(townRoot, curated.FeedFile))
+	if os.IsNotExist(err) {
+		return page, nil
+	}
+	if err != nil {
+		return page, err
+	}
+	defer f.Close()
+
+	var found []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var fe curated.FeedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &fe); err != nil {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339, fe.Timestamp)
+		if err != nil || ts.After(before) {
+			continue
+		}
+		ev := gtEventToEvent(GtEvent{
+			Timestamp:  fe.Timestamp,
+			Source:     fe.Source,
+			Type:       fe.Type,
+			Actor:      fe.Actor,
+			Payload:    fe.Payload,
+			Visibility: events.VisibilityFeed,
+		}, scanner.Text())
+		if ev == nil {
+			continue
+		}
+		if fe.Summary != "" {
+			ev.Message = fe.Summary
+		}
+		found = append(found, *ev)
+	}
+	if err := scanner.Err(); err != nil {
+		return page, err
+	}
+
+	if len(found) > limit {
+		found = found[len(found)-limit:]
+		page.Exhausted = false
+	}
+	if len(found) > 0 {
+		page.Before = found[0].Time
+	}
+	page.Events = found
+	return page, nil
+}
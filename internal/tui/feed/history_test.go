@@ -0,0 +1,138 @@
+package feed
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	curated "github.com/steveyegge/gastown/internal/feed"
+)
+
+func TestLoadHistory(t *testing.T) {
+	townRoot := t.TempDir()
+	raw := `{"ts":"2026-01-01T10:00:00Z","type":"sling","actor":"mayor","payload":{"bead":"gt-1","target":"gastown/nux"},"visibility":"feed"}
+{"ts":"2026-01-01T10:01:00Z","type":"session_start","actor":"gastown/nux","visibility":"audit"}
+{"ts":"2026-01-01T10:02:00Z","type":"done","actor":"gastown/nux","payload":{"bead":"gt-1"},"visibility":"feed"}
+{"ts":"2026-01-01T10:03:00Z","type":"merged","actor":"gastown/refinery","visibility":"both"}
+`
+	if err := os.WriteFile(filepath.Join(townRoot, events.EventsFile), []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The curated feed reaches back before the raw log, which was pruned
+	feedLog := `{"ts":"2025-12-31T09:00:00Z","type":"sling","actor":"mayor","summary":"mayor dispatching work to 3 agents"}
+{"ts":"2026-01-01T10:00:00Z","type":"sling","actor":"mayor","summary":"duplicate of the raw log"}
+`
+	if err := os.WriteFile(filepath.Join(townRoot, curated.FeedFile), []byte(feedLog), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A page of two raw events: the audit-only one is read but not shown
+	page, err := LoadHistory(townRoot, time.Date(2026, 1, 1, 10, 2, 30, 0, time.UTC), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Events) != 1 || page.Events[0].Type != "done" || page.Exhausted ||
+		!page.Before.Equal(time.Date(2026, 1, 1, 10, 1, 0, 0, time.UTC)) {
+		t.Fatalf("page = %+v", page)
+	}
+
+	// The next page ends on the sling; the one after runs out of raw events
+	// and continues into the curated feed, skipping what the raw log had
+	page, err = LoadHistory(townRoot, page.Before, 2)
+	if err != nil || len(page.Events) != 1 || page.Events[0].Message != "slung gt-1 to gastown/nux" || page.Exhausted {
+		t.Fatalf("page = %+v, %v", page, err)
+	}
+	page, err = LoadHistory(townRoot, page.Before, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range page.Events {
+		got = append(got, e.Time.UTC().Format("01-02 15:04")+" "+e.Message)
+	}
+	want := "12-31 09:00 mayor dispatching work to 3 agents|01-01 10:00 slung gt-1 to gastown/nux"
+	if strings.Join(got, "|") != want || !page.Exhausted {
+		t.Errorf("page = %q (exhausted %v), want %q", got, page.Exhausted, want)
+	}
+}
+
+func TestModelTimeTravel(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var calls []time.Time
+	m := NewModel()
+	m.SetHistoryLoader(func(before time.Time) (HistoryPage, error) {
+		calls = append(calls, before)
+		if len(calls) == 1 {
+			return HistoryPage{Before: base.Add(-2 * time.Hour), Events: []Event{
+				{Time: base.Add(-2 * time.Hour), Type: "sling", Rig: "gastown", Message: "old sling"},
+				{Time: base.Add(-time.Hour), Type: "done", Rig: "beads", Message: "old done"},
+			}}, nil
+		}
+		// Same second again, with a repeat: nothing new, so history ends
+		return HistoryPage{Before: base.Add(-2 * time.Hour), Events: []Event{
+			{Time: base.Add(-2 * time.Hour), Type: "sling", Rig: "gastown", Message: "old sling"},
+		}}, nil
+	})
+	m.addEvent(Event{Time: base, Type: "merged", Rig: "gastown", Message: "live merge"})
+
+	for i := 0; i < 3; i++ {
+		if cmd := m.fetchHistory(); cmd != nil {
+			m.addHistory(cmd().(historyMsg))
+		}
+	}
+	if len(calls) != 2 || !m.historyDone || len(m.history) != 2 {
+		t.Fatalf("calls = %v, done = %v, history = %+v", calls, m.historyDone, m.history)
+	}
+
+	if err := m.SetQuery("rig:gastown"); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range m.visibleEvents() {
+		got = append(got, e.Message)
+	}
+	if strings.Join(got, ",") != "live merge,old sling" {
+		t.Errorf("visible = %v, want the gastown events newest first", got)
+	}
+
+	m.backToLive()
+	if len(m.history) != 0 || m.historyDone {
+		t.Errorf("history not dropped: %+v", m.history)
+	}
+}
+
+func TestModelSavedViews(t *testing.T) {
+	saved := map[string]string{}
+	m := NewModel()
+	m.SetViews(map[string]string{"refinery": "actor:refinery"}, func(name, query string) error {
+		saved[name] = query
+		return nil
+	})
+
+	if err := m.SetQuery("@refinery"); err != nil || m.viewName != "refinery" || m.query.String() != "actor:refinery" {
+		t.Fatalf("@refinery: %v, view %q, query %q", err, m.viewName, m.query)
+	}
+	if err := m.SetQuery("@nope"); err == nil {
+		t.Error("unknown view applied")
+	}
+
+	if err := m.SetQuery("type:merged"); err != nil {
+		t.Fatal(err)
+	}
+	if err := submitViewName(m, "merges"); err != nil || saved["merges"] != "type:merged" {
+		t.Fatalf("save: %v, saved %v", err, saved)
+	}
+
+	// v cycles merges -> refinery -> unfiltered
+	m.nextView()
+	if m.viewName != "refinery" {
+		t.Errorf("after v, view = %q", m.viewName)
+	}
+	m.nextView()
+	if m.viewName != "" || !m.query.IsEmpty() {
+		t.Errorf("after v at the last view, view = %q query %q", m.viewName, m.query)
+	}
+}
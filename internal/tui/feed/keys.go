@@ -28,6 +28,12 @@ type KeyMap struct {
 	Search      key.Binding
 	Filter      key.Binding
 	ClearFilter key.Binding
+	NextView    key.Binding
+	SaveView    key.Binding
+
+	// Time travel
+	Earlier key.Binding
+	Live    key.Binding
 
 	// General
 	Help key.Binding
@@ -105,6 +111,22 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "clear"),
 		),
+		NextView: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "next view"),
+		),
+		SaveView: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "save view"),
+		),
+		Earlier: key.NewBinding(
+			key.WithKeys("["),
+			key.WithHelp("[", "earlier"),
+		),
+		Live: key.NewBinding(
+			key.WithKeys("]"),
+			key.WithHelp("]", "back to live"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "help"),
@@ -126,7 +148,8 @@ func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.PageUp, k.PageDown, k.Top, k.Bottom},
 		{k.Tab, k.FocusTree, k.FocusConvoy, k.FocusFeed, k.Enter, k.Expand},
-		{k.Search, k.Filter, k.ClearFilter, k.Refresh},
+		{k.Search, k.Filter, k.ClearFilter, k.NextView, k.SaveView, k.Refresh},
+		{k.Earlier, k.Live},
 		{k.Help, k.Quit},
 	}
 }
@@ -70,10 +70,26 @@ type Model struct {
 	townRoot    string
 
 	// UI state
-	keys     KeyMap
-	help     help.Model
-	showHelp bool
-	filter   string
+	keys      KeyMap
+	help      help.Model
+	showHelp  bool
+	input     *queryInput // the query bar, while open
+	statusMsg string      // shown in the status bar until the next key
+
+	// Filtering and saved views
+	query    Query
+	viewName string            // saved view the query came from, if any
+	views    map[string]string // saved views by name
+	saveView func(name, query string) error
+
+	// Time travel: past events loaded from the logs, oldest first
+	loadHistory    HistoryLoader
+	history        []Event
+	historySeen    map[string]bool
+	historyBefore  time.Time
+	historyDone    bool
+	historyLoading bool
+	started        time.Time
 
 	// Event source
 	eventChan <-chan Event
@@ -96,6 +112,8 @@ func NewModel() *Model {
 		keys:           DefaultKeyMap(),
 		help:           h,
 		done:           make(chan struct{}),
+		started:        time.Now(),
+		historyBefore:  time.Now(),
 	}
 }
 
@@ -199,6 +217,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, m.fetchConvoys())
 		}
 
+	case historyMsg:
+		m.addHistory(msg)
+
 	case tickMsg:
 		cmds = append(cmds, tick())
 	}
@@ -220,6 +241,11 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // handleKey processes key presses
 func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.input != nil {
+		return m.handleInputKey(msg)
+	}
+	m.statusMsg = ""
+
 	switch {
 	case key.Matches(msg, m.keys.Quit):
 		m.closeOnce.Do(func() { close(m.done) })
@@ -257,6 +283,40 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.Refresh):
 		m.updateViewContent()
 		return m, nil
+
+	case key.Matches(msg, m.keys.Search), key.Matches(msg, m.keys.Filter):
+		m.openInput("Filter", m.query.String(), submitQuery)
+		return m, nil
+
+	case key.Matches(msg, m.keys.ClearFilter):
+		m.query = Query{}
+		m.viewName = ""
+		m.updateViewContent()
+		return m, nil
+
+	case key.Matches(msg, m.keys.NextView):
+		m.nextView()
+		return m, nil
+
+	case key.Matches(msg, m.keys.SaveView):
+		m.openInput("Save view as", m.viewName, submitViewName)
+		return m, nil
+
+	case key.Matches(msg, m.keys.Earlier):
+		m.focusedPanel = PanelFeed
+		return m, m.fetchHistory()
+
+	case key.Matches(msg, m.keys.Live):
+		m.backToLive()
+		return m, nil
+	}
+
+	// Scrolling past the oldest event in the feed travels back in time
+	if m.focusedPanel == PanelFeed && m.feedViewport.AtBottom() &&
+		(key.Matches(msg, m.keys.Down) || key.Matches(msg, m.keys.PageDown) || key.Matches(msg, m.keys.Bottom)) {
+		if cmd := m.fetchHistory(); cmd != nil {
+			return m, cmd
+		}
 	}
 
 	// Pass to focused viewport
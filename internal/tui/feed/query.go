@@ -0,0 +1,138 @@
+package feed
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Query filters feed events. It is parsed from the text typed in the query
+// bar or saved as a view, a space-separated list of terms that must all
+// match:
+//
+//	rig:gastown          events in a rig
+//	actor:refinery       events by actors whose address contains "refinery"
+//	type:merged,done     events of any of the listed types
+//	bead:gt-abc          events about beads whose ID starts with "gt-abc"
+//	timeout              events whose text contains "timeout"
+//
+// A term can list several values separated by commas, any of which may
+// match, and is negated with a leading "-" (-type:update hides updates).
+// Matching ignores case.
+type Query struct {
+	text  string
+	terms []queryTerm
+}
+
+// queryKeys are the fields a term can filter on.
+var queryKeys = []string{"rig", "actor", "type", "bead"}
+
+type queryTerm struct {
+	key    string // one of queryKeys, or "" for free text
+	values []string
+	negate bool
+}
+
+// ParseQuery parses query bar text. An empty query matches everything.
+func ParseQuery(text string) (Query, error) {
+	q := Query{text: strings.TrimSpace(text)}
+	for _, word := range strings.Fields(text) {
+		term := queryTerm{}
+		if strings.HasPrefix(word, "-") && len(word) > 1 {
+			term.negate = true
+			word = word[1:]
+		}
+		value := word
+		if key, rest, ok := strings.Cut(word, ":"); ok && isQueryKeyword(key) {
+			if !isQueryKey(key) {
+				return Query{}, fmt.Errorf("unknown filter %q (want %s)", key+":", strings.Join(queryKeys, ":, ")+":")
+			}
+			if rest == "" {
+				return Query{}, fmt.Errorf("%s: needs a value", key)
+			}
+			term.key = strings.ToLower(key)
+			value = rest
+		}
+		for _, v := range strings.Split(value, ",") {
+			if v != "" {
+				term.values = append(term.values, strings.ToLower(v))
+			}
+		}
+		if len(term.values) > 0 {
+			q.terms = append(q.terms, term)
+		}
+	}
+	return q, nil
+}
+
+// isQueryKeyword reports whether the text before a colon looks like a
+// filter key rather than part of a search word (a URL, a time).
+func isQueryKeyword(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return s != "http" && s != "https"
+}
+
+func isQueryKey(s string) bool {
+	for _, k := range queryKeys {
+		if strings.EqualFold(s, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the query as typed.
+func (q Query) String() string {
+	return q.text
+}
+
+// IsEmpty reports whether the query matches everything.
+func (q Query) IsEmpty() bool {
+	return len(q.terms) == 0
+}
+
+// Match reports whether an event passes the query.
+func (q Query) Match(e Event) bool {
+	for _, t := range q.terms {
+		if t.matches(e) == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (t queryTerm) matches(e Event) bool {
+	for _, v := range t.values {
+		if t.matchesValue(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t queryTerm) matchesValue(e Event, v string) bool {
+	switch t.key {
+	case "rig":
+		return strings.EqualFold(e.Rig, v)
+	case "actor":
+		return strings.Contains(strings.ToLower(e.Actor), v)
+	case "type":
+		return strings.EqualFold(e.Type, v)
+	case "bead":
+		return strings.HasPrefix(strings.ToLower(e.Target), v) ||
+			strings.Contains(strings.ToLower(e.Message), v)
+	default:
+		for _, s := range []string{e.Message, e.Target, e.Actor, e.Raw} {
+			if strings.Contains(strings.ToLower(s), v) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,56 @@
+package feed
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestQueryMatch(t *testing.T) {
+	events := []Event{
+		{Type: "merged", Actor: "gastown/refinery", Rig: "gastown", Target: "gt-abc1", Message: "merged work from nux"},
+		{Type: "update", Actor: "gastown/crew/joe", Rig: "gastown", Target: "gt-abc2", Message: "in_progress"},
+		{Type: "sling", Actor: "mayor", Target: "bd-xyz", Message: "slung bd-xyz to beads/toast"},
+		{Type: "merge_failed", Actor: "beads/refinery", Rig: "beads", Message: "merge failed: timeout"},
+	}
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"", []int{0, 1, 2, 3}},
+		{"rig:gastown", []int{0, 1}},
+		{"actor:refinery", []int{0, 3}},
+		{"type:merged,merge_failed", []int{0, 3}},
+		{"bead:gt-abc", []int{0, 1}},
+		{"bead:bd-xyz", []int{2}},
+		{"-type:update", []int{0, 2, 3}},
+		{"actor:refinery rig:beads", []int{3}},
+		{"TIMEOUT", []int{3}},
+		{"Rig:GASTOWN -actor:crew", []int{0}},
+		{"http://example.com", nil},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", tt.query, err)
+			continue
+		}
+		var got []int
+		for i, e := range events {
+			if q.Match(e) {
+				got = append(got, i)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q matched %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, text := range []string{"tpye:merged", "rig:", "-actor:"} {
+		if _, err := ParseQuery(text); err == nil {
+			t.Errorf("ParseQuery(%q) succeeded, want an error", text)
+		}
+	}
+}
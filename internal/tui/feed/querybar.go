@@ -0,0 +1,236 @@
+package feed
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxHistoryEvents caps how many past events time travel keeps loaded.
+const maxHistoryEvents = 5000
+
+// queryInput is the query bar while it is open.
+type queryInput struct {
+	prompt string
+	text   []rune
+	submit func(m *Model, text string) error
+}
+
+// historyMsg carries a page of past events.
+type historyMsg struct {
+	page HistoryPage
+	err  error
+}
+
+// SetQuery sets the feed's filter from query text, or a saved view when
+// the text is @name.
+func (m *Model) SetQuery(text string) error {
+	text = strings.TrimSpace(text)
+	if name, ok := strings.CutPrefix(text, "@"); ok {
+		return m.ApplyView(name)
+	}
+	q, err := ParseQuery(text)
+	if err != nil {
+		return err
+	}
+	m.query = q
+	m.viewName = ""
+	m.updateViewContent()
+	return nil
+}
+
+// SetViews sets the saved views and how to save new ones.
+func (m *Model) SetViews(views map[string]string, save func(name, query string) error) {
+	m.views = views
+	m.saveView = save
+}
+
+// ApplyView filters the feed with a saved view.
+func (m *Model) ApplyView(name string) error {
+	text, ok := m.views[name]
+	if !ok {
+		return fmt.Errorf("no saved view %q", name)
+	}
+	q, err := ParseQuery(text)
+	if err != nil {
+		return fmt.Errorf("view %s: %w", name, err)
+	}
+	m.query = q
+	m.viewName = name
+	m.updateViewContent()
+	return nil
+}
+
+// nextView switches to the saved view after the current one, then back to
+// the unfiltered feed.
+func (m *Model) nextView() {
+	names := make([]string, 0, len(m.views))
+	for name := range m.views {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		m.statusMsg = "no saved views (press s to save the current filter)"
+		return
+	}
+	sort.Strings(names)
+
+	next := names[0]
+	if m.viewName != "" {
+		i := sort.SearchStrings(names, m.viewName)
+		switch {
+		case i+1 < len(names):
+			next = names[i+1]
+		default:
+			m.query = Query{}
+			m.viewName = ""
+			m.updateViewContent()
+			return
+		}
+	}
+	if err := m.ApplyView(next); err != nil {
+		m.statusMsg = err.Error()
+	}
+}
+
+// SetHistoryLoader enables time travel: scrolling past the oldest event
+// loads earlier ones.
+func (m *Model) SetHistoryLoader(load HistoryLoader) {
+	m.loadHistory = load
+}
+
+// openInput opens the query bar.
+func (m *Model) openInput(prompt, initial string, submit func(m *Model, text string) error) {
+	m.input = &queryInput{prompt: prompt, text: []rune(initial), submit: submit}
+	m.statusMsg = ""
+}
+
+// handleInputKey edits the query bar. Enter submits, esc cancels.
+func (m *Model) handleInputKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	in := m.input
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.closeOnce.Do(func() { close(m.done) })
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.input = nil
+	case tea.KeyEnter:
+		m.input = nil
+		if err := in.submit(m, string(in.text)); err != nil {
+			m.statusMsg = err.Error()
+		}
+	case tea.KeyBackspace:
+		if len(in.text) > 0 {
+			in.text = in.text[:len(in.text)-1]
+		}
+	case tea.KeyCtrlU:
+		in.text = nil
+	case tea.KeySpace:
+		in.text = append(in.text, ' ')
+	case tea.KeyRunes:
+		in.text = append(in.text, msg.Runes...)
+	}
+	return m, nil
+}
+
+// submitQuery applies the query bar's text.
+func submitQuery(m *Model, text string) error {
+	return m.SetQuery(text)
+}
+
+// submitViewName saves the current filter under the entered name.
+func submitViewName(m *Model, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, " \t@") {
+		return fmt.Errorf("invalid view name %q", name)
+	}
+	if m.query.IsEmpty() {
+		return fmt.Errorf("nothing to save: set a filter with / first")
+	}
+	if m.saveView == nil {
+		return fmt.Errorf("views can't be saved outside a town")
+	}
+	if err := m.saveView(name, m.query.String()); err != nil {
+		return fmt.Errorf("saving view: %w", err)
+	}
+	if m.views == nil {
+		m.views = make(map[string]string)
+	}
+	m.views[name] = m.query.String()
+	m.viewName = name
+	m.statusMsg = "saved view " + name
+	return nil
+}
+
+// fetchHistory returns a command that loads the page of events before
+// the oldest one loaded so far.
+func (m *Model) fetchHistory() tea.Cmd {
+	if m.loadHistory == nil || m.historyDone || m.historyLoading {
+		return nil
+	}
+	m.historyLoading = true
+	load, before := m.loadHistory, m.historyBefore
+	return func() tea.Msg {
+		page, err := load(before)
+		return historyMsg{page: page, err: err}
+	}
+}
+
+// addHistory merges a page of past events into the feed.
+func (m *Model) addHistory(msg historyMsg) {
+	m.historyLoading = false
+	if msg.err != nil {
+		m.statusMsg = "loading history: " + msg.err.Error()
+		return
+	}
+	if m.historySeen == nil {
+		m.historySeen = make(map[string]bool)
+	}
+
+	var fresh []Event
+	for _, e := range msg.page.Events {
+		key := e.Time.Format(time.RFC3339) + "|" + e.Type + "|" + e.Actor + "|" + e.Message
+		if !m.historySeen[key] {
+			m.historySeen[key] = true
+			fresh = append(fresh, e)
+		}
+	}
+	m.history = append(fresh, m.history...)
+
+	// Stop when the logs run out, when enough is loaded, or when a page
+	// brought nothing new and didn't move back (every event in it shares
+	// one second)
+	if msg.page.Exhausted || len(m.history) >= maxHistoryEvents ||
+		(len(fresh) == 0 && !msg.page.Before.Before(m.historyBefore)) {
+		m.historyDone = true
+	}
+	m.historyBefore = msg.page.Before
+	m.updateViewContent()
+}
+
+// backToLive drops loaded history and returns to the newest events.
+func (m *Model) backToLive() {
+	m.history = nil
+	m.historySeen = nil
+	m.historyDone = false
+	m.historyBefore = m.started
+	m.updateViewContent()
+	m.feedViewport.GotoTop()
+}
+
+// visibleEvents returns the events that pass the query, newest first: live
+// events, then loaded history.
+func (m *Model) visibleEvents() []Event {
+	limit := maxRenderedEvents + len(m.history)
+	var out []Event
+	for _, list := range [][]Event{m.events, m.history} {
+		for i := len(list) - 1; i >= 0 && len(out) < limit; i-- {
+			if m.query.Match(list[i]) {
+				out = append(out, list[i])
+			}
+		}
+	}
+	return out
+}
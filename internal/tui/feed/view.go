@@ -47,10 +47,13 @@ func (m *Model) render() string {
 func (m *Model) renderHeader() string {
 	title := TitleStyle.Render("GT Feed")
 
-	filter := ""
-	if m.filter != "" {
-		filter = FilterStyle.Render(fmt.Sprintf("Filter: %s", m.filter))
-	} else {
+	var filter string
+	switch {
+	case m.viewName != "":
+		filter = FilterStyle.Render(fmt.Sprintf("View: %s (%s)", m.viewName, m.query))
+	case !m.query.IsEmpty():
+		filter = FilterStyle.Render(fmt.Sprintf("Filter: %s", m.query))
+	default:
 		filter = FilterStyle.Render("Filter: all")
 	}
 
@@ -213,32 +216,45 @@ func (m *Model) renderAgent(icon string, agent *Agent, indent int) string {
 	return line
 }
 
+// maxRenderedEvents caps the live events shown in the feed panel. Loaded
+// history is shown in full.
+const maxRenderedEvents = 100
+
 // renderFeed renders the event feed content
 func (m *Model) renderFeed() string {
-	if len(m.events) == 0 {
+	if len(m.events) == 0 && len(m.history) == 0 {
 		return AgentIdleStyle.Render("No events yet")
 	}
 
-	var lines []string
-
-	// Show most recent events first (reversed)
-	start := 0
-	if len(m.events) > 100 {
-		start = len(m.events) - 100
+	// Most recent events first
+	visible := m.visibleEvents()
+	if len(visible) == 0 {
+		return AgentIdleStyle.Render("No events match the filter")
 	}
 
-	for i := len(m.events) - 1; i >= start; i-- {
-		event := m.events[i]
+	lines := make([]string, 0, len(visible)+1)
+	for _, event := range visible {
 		lines = append(lines, m.renderEvent(event))
 	}
 
+	switch {
+	case m.historyLoading:
+		lines = append(lines, TimestampStyle.Render("  loading earlier events..."))
+	case m.historyDone && len(m.history) > 0:
+		lines = append(lines, TimestampStyle.Render("  — start of the event log —"))
+	}
+
 	return strings.Join(lines, "\n")
 }
 
 // renderEvent renders a single event line
 func (m *Model) renderEvent(e Event) string {
-	// Timestamp - compact HH:MM format, no brackets
-	ts := TimestampStyle.Render(e.Time.Format("15:04"))
+	// Timestamp - compact HH:MM format, no brackets; older events get a date
+	layout := "15:04"
+	if !sameDay(e.Time, m.started) {
+		layout = "Jan 2 15:04"
+	}
+	ts := TimestampStyle.Render(e.Time.Format(layout))
 
 	// Symbol based on event type
 	symbol := EventSymbols[e.Type]
@@ -299,8 +315,19 @@ func (m *Model) renderEvent(e Event) string {
 	return fmt.Sprintf("%s %s %s%s", ts, styledSymbol, actor, msg)
 }
 
-// renderStatusBar renders the bottom status bar
+// renderStatusBar renders the bottom status bar, or the query bar while
+// it is open
 func (m *Model) renderStatusBar() string {
+	if m.input != nil {
+		prompt := HelpKeyStyle.Render(m.input.prompt+": ") + string(m.input.text) + "█"
+		hint := HelpDescStyle.Render("enter:apply  esc:cancel  @name:view")
+		gap := m.width - lipgloss.Width(prompt) - lipgloss.Width(hint) - 4
+		if gap < 1 {
+			gap = 1
+		}
+		return StatusBarStyle.Width(m.width).Render(prompt + strings.Repeat(" ", gap) + hint)
+	}
+
 	// Panel indicator
 	var panelName string
 	switch m.focusedPanel {
@@ -313,8 +340,18 @@ func (m *Model) renderStatusBar() string {
 	}
 	panel := fmt.Sprintf("[%s]", panelName)
 
-	// Event count
-	count := fmt.Sprintf("%d events", len(m.events))
+	// Event count, and how far back history goes
+	total := len(m.events) + len(m.history)
+	count := fmt.Sprintf("%d events", total)
+	if !m.query.IsEmpty() {
+		count = fmt.Sprintf("%d/%d events", len(m.visibleEvents()), total)
+	}
+	if len(m.history) > 0 {
+		count += " since " + m.history[0].Time.Format("Jan 2 15:04")
+	}
+	if m.statusMsg != "" {
+		count += "  " + FilterStyle.Render(m.statusMsg)
+	}
 
 	// Short help
 	help := m.renderShortHelp()
@@ -334,13 +371,22 @@ func (m *Model) renderShortHelp() string {
 	hints := []string{
 		HelpKeyStyle.Render("j/k") + HelpDescStyle.Render(":scroll"),
 		HelpKeyStyle.Render("tab") + HelpDescStyle.Render(":switch"),
-		HelpKeyStyle.Render("/") + HelpDescStyle.Render(":search"),
+		HelpKeyStyle.Render("/") + HelpDescStyle.Render(":filter"),
+		HelpKeyStyle.Render("v") + HelpDescStyle.Render(":view"),
+		HelpKeyStyle.Render("[") + HelpDescStyle.Render(":earlier"),
 		HelpKeyStyle.Render("q") + HelpDescStyle.Render(":quit"),
 		HelpKeyStyle.Render("?") + HelpDescStyle.Render(":help"),
 	}
 	return strings.Join(hints, "  ")
 }
 
+// sameDay reports whether two times fall on the same local date.
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
 // formatAge formats a duration as a short age string
 func formatAge(d time.Duration) string {
 	if d < time.Minute {
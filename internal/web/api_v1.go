@@ -0,0 +1,754 @@
+package web
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// The /api/v1 surface is the stable, documented JSON API for scripts and
+// integrations. Unlike the GUI's own /api endpoints, which change with the
+// pages that use them, every v1 response has the same shape:
+//
+//	{"data": ...}                                  a single resource
+//	{"data": [...], "page": {...}}                 a list
+//	{"error": {"code": "...", "message": "..."}}   a failure, with a 4xx/5xx status
+//
+// Lists are paginated with ?limit= and the opaque ?cursor= from the previous
+// page's next_cursor. Event cursors mark a point in the log, so new events
+// don't shift the pages after it. The API is described by an OpenAPI document served at
+// /api/v1/openapi.json; TestAPIV1OpenAPICoversRoutes keeps the two in step.
+
+//go:embed openapi.json
+var openAPIDocument []byte
+
+const apiV1Prefix = "/api/v1"
+
+// Page size limits for list endpoints.
+const (
+	apiDefaultLimit = 50
+	apiMaxLimit     = 500
+)
+
+// API error codes.
+const (
+	apiErrBadRequest       = "bad_request"
+	apiErrUnauthorized     = "unauthorized"
+	apiErrForbidden        = "forbidden"
+	apiErrNotFound         = "not_found"
+	apiErrMethodNotAllowed = "method_not_allowed"
+	apiErrUnavailable      = "unavailable"
+	apiErrInternal         = "internal"
+)
+
+// apiError is the error envelope's body.
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// apiPage describes a page of a list.
+type apiPage struct {
+	Limit      int    `json:"limit"`
+	Total      *int   `json:"total,omitempty"` // Absent where counting means reading the whole list
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// apiRoute is a v1 endpoint. Path segments in braces are parameters.
+type apiRoute struct {
+	method  string
+	path    string
+	handler func(h *GUIHandler, w http.ResponseWriter, r *http.Request, params map[string]string)
+}
+
+// apiV1Routes lists every v1 endpoint. Each must be described in openapi.json.
+var apiV1Routes = []apiRoute{
+	{http.MethodGet, "/openapi.json", (*GUIHandler).handleAPIV1OpenAPI},
+	{http.MethodGet, "/rigs", (*GUIHandler).handleAPIV1Rigs},
+	{http.MethodGet, "/rigs/{name}", (*GUIHandler).handleAPIV1Rig},
+	{http.MethodGet, "/agents", (*GUIHandler).handleAPIV1Agents},
+	{http.MethodGet, "/beads", (*GUIHandler).handleAPIV1Beads},
+	{http.MethodPost, "/beads", (*GUIHandler).handleAPIV1CreateBead},
+	{http.MethodGet, "/beads/{id}", (*GUIHandler).handleAPIV1Bead},
+	{http.MethodGet, "/convoys", (*GUIHandler).handleAPIV1Convoys},
+	{http.MethodGet, "/convoys/{id}", (*GUIHandler).handleAPIV1Convoy},
+	{http.MethodGet, "/mrs", (*GUIHandler).handleAPIV1MergeRequests},
+	{http.MethodGet, "/mail", (*GUIHandler).handleAPIV1Mail},
+	{http.MethodPost, "/mail", (*GUIHandler).handleAPIV1SendMail},
+	{http.MethodGet, "/events", (*GUIHandler).handleAPIV1Events},
+}
+
+// matchAPIRoute matches a path (without the /api/v1 prefix) against a
+// route path, returning the path parameters.
+func matchAPIRoute(pattern, path string) (map[string]string, bool) {
+	want := strings.Split(strings.Trim(pattern, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, seg := range want {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			if got[i] == "" {
+				return nil, false
+			}
+			params[seg[1:len(seg)-1]] = got[i]
+			continue
+		}
+		if seg != got[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// handleAPIV1 routes /api/v1 requests.
+func (h *GUIHandler) handleAPIV1(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, apiV1Prefix)
+	var allowed []string
+	for _, route := range apiV1Routes {
+		params, ok := matchAPIRoute(route.path, path)
+		if !ok {
+			continue
+		}
+		if route.method == r.Method || (route.method == http.MethodGet && r.Method == http.MethodHead) {
+			route.handler(h, w, r, params)
+			return
+		}
+		allowed = append(allowed, route.method)
+	}
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeAPIError(w, http.StatusMethodNotAllowed, apiErrMethodNotAllowed, r.Method+" is not supported on "+r.URL.Path)
+		return
+	}
+	writeAPIError(w, http.StatusNotFound, apiErrNotFound, "no such endpoint: "+r.URL.Path)
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeAPIData writes a single resource.
+func writeAPIData(w http.ResponseWriter, status int, data interface{}) {
+	writeAPIJSON(w, status, map[string]interface{}{"data": data})
+}
+
+// writeAPIError writes the error envelope.
+func writeAPIError(w http.ResponseWriter, status int, code, message string) {
+	writeAPIJSON(w, status, map[string]interface{}{"error": apiError{Code: code, Message: message}})
+}
+
+// Cursor kinds: a position in a list, or a point in the event log.
+const (
+	cursorOffset = "o"
+	cursorEvent  = "e"
+)
+
+// apiPagination is a parsed ?limit= and ?cursor=.
+type apiPagination struct {
+	limit int
+	kind  string // Kind of the cursor, "" on the first page
+
+	// offset is the position of an offset cursor.
+	offset int
+
+	// before and skip are an event cursor: the next page starts at before,
+	// less the skip events at that time the previous pages returned.
+	before time.Time
+	skip   int
+}
+
+// httpError replies like http.Error, but with the error envelope for /api/v1
+// requests so API clients see one error shape whether a request was refused
+// by auth or by a handler.
+func httpError(w http.ResponseWriter, r *http.Request, message string, status int) {
+	if !strings.HasPrefix(r.URL.Path, apiV1Prefix+"/") {
+		http.Error(w, message, status)
+		return
+	}
+	code := apiErrInternal
+	switch status {
+	case http.StatusUnauthorized:
+		code = apiErrUnauthorized
+	case http.StatusForbidden:
+		code = apiErrForbidden
+	}
+	writeAPIError(w, status, code, message)
+}
+
+// parseAPIPagination reads the page size and position of a list request.
+func parseAPIPagination(r *http.Request) (apiPagination, error) {
+	p := apiPagination{limit: apiDefaultLimit}
+	q := r.URL.Query()
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return p, errors.New("limit must be a positive integer")
+		}
+		p.limit = min(n, apiMaxLimit)
+	}
+	if s := q.Get("cursor"); s != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return p, errors.New("invalid cursor")
+		}
+		parts := strings.Split(string(raw), ":")
+		switch {
+		case len(parts) == 2 && parts[0] == cursorOffset:
+			p.offset, err = strconv.Atoi(parts[1])
+			if err != nil || p.offset < 0 {
+				return p, errors.New("invalid cursor")
+			}
+		case len(parts) == 3 && parts[0] == cursorEvent:
+			nanos, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil {
+				return p, errors.New("invalid cursor")
+			}
+			p.before = time.Unix(0, nanos).UTC()
+			p.skip, err = strconv.Atoi(parts[2])
+			if err != nil || p.skip < 0 {
+				return p, errors.New("invalid cursor")
+			}
+		default:
+			return p, errors.New("invalid cursor")
+		}
+		p.kind = parts[0]
+	}
+	return p, nil
+}
+
+func apiCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorOffset + ":" + strconv.Itoa(offset)))
+}
+
+// apiEventCursor returns the cursor of the events before ts, skipping the
+// skip events at ts already returned.
+func apiEventCursor(ts time.Time, skip int) string {
+	raw := cursorEvent + ":" + strconv.FormatInt(ts.UnixNano(), 10) + ":" + strconv.Itoa(skip)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// writeAPIList writes the requested page of items. Handlers filter the
+// full list and leave paging to this.
+func writeAPIList[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	p, err := parseAPIPagination(r)
+	if err == nil && p.kind == cursorEvent {
+		err = errors.New("invalid cursor")
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, err.Error())
+		return
+	}
+	total := len(items)
+	start := min(p.offset, total)
+	end := min(start+p.limit, total)
+	page := apiPage{Limit: p.limit, Total: &total}
+	if end < total {
+		page.NextCursor = apiCursor(end)
+	}
+	data := items[start:end]
+	if data == nil {
+		data = []T{}
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]interface{}{"data": data, "page": page})
+}
+
+// apiTownRoot returns the town the API serves.
+func apiTownRoot() (string, error) {
+	if root := os.Getenv("GT_ROOT"); root != "" {
+		return root, nil
+	}
+	return workspace.FindFromCwdOrError()
+}
+
+// apiRigs returns the rigs of the town the API serves.
+func apiRigs() ([]*rig.Rig, error) {
+	townRoot, err := apiTownRoot()
+	if err != nil {
+		return nil, err
+	}
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return nil, err
+	}
+	return rig.NewManager(townRoot, rigsConfig, git.NewGit(townRoot)).DiscoverRigs()
+}
+
+// handleAPIV1OpenAPI serves the OpenAPI document.
+func (h *GUIHandler) handleAPIV1OpenAPI(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPIDocument)
+}
+
+// handleAPIV1Rigs lists the town's rigs.
+func (h *GUIHandler) handleAPIV1Rigs(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	writeAPIList(w, r, h.getRigs())
+}
+
+// handleAPIV1Rig returns a rig by name.
+func (h *GUIHandler) handleAPIV1Rig(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	for _, rig := range h.getRigs() {
+		if rig.Name == params["name"] {
+			writeAPIData(w, http.StatusOK, rig)
+			return
+		}
+	}
+	writeAPIError(w, http.StatusNotFound, apiErrNotFound, "no rig "+params["name"])
+}
+
+// apiAgent is an agent session in the v1 API.
+type apiAgent struct {
+	Name         string     `json:"name"`
+	Rig          string     `json:"rig"`
+	Type         string     `json:"type"`
+	Session      string     `json:"session"`
+	LastActivity *time.Time `json:"last_activity,omitempty"`
+	StatusHint   string     `json:"status_hint,omitempty"`
+}
+
+// handleAPIV1Agents lists running agents, optionally by ?rig= and ?type=.
+func (h *GUIHandler) handleAPIV1Agents(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	rows, err := h.fetcher.FetchAgents()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, apiErrInternal, err.Error())
+		return
+	}
+	q := r.URL.Query()
+	agents := make([]apiAgent, 0, len(rows))
+	for _, row := range rows {
+		if (q.Get("rig") != "" && row.Rig != q.Get("rig")) || (q.Get("type") != "" && row.AgentType != q.Get("type")) {
+			continue
+		}
+		agent := apiAgent{
+			Name:       row.Name,
+			Rig:        row.Rig,
+			Type:       row.AgentType,
+			Session:    row.SessionID,
+			StatusHint: row.StatusHint,
+		}
+		if !row.LastActivity.LastActivity.IsZero() {
+			ts := row.LastActivity.LastActivity
+			agent.LastActivity = &ts
+		}
+		agents = append(agents, agent)
+	}
+	writeAPIList(w, r, agents)
+}
+
+// handleAPIV1Beads lists beads filtered by ?status=, ?type= and ?assignee=.
+// Agent, molecule, gate, event and message beads are left out unless a
+// type is asked for or ?include_system=true.
+func (h *GUIHandler) handleAPIV1Beads(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	q := r.URL.Query()
+	filter := BeadFilter{
+		Status:           q.Get("status"),
+		Type:             q.Get("type"),
+		Assignee:         q.Get("assignee"),
+		IncludeEphemeral: q.Get("ephemeral") == "true",
+	}
+	if filter.Type == "" && q.Get("include_system") != "true" {
+		filter.ExcludeTypes = []string{"agent", "molecule", "gate", "event", "message"}
+	}
+
+	reader, err := NewBeadsReader("")
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, apiErrUnavailable, err.Error())
+		return
+	}
+	beads, err := reader.ListBeads(filter)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, apiErrInternal, err.Error())
+		return
+	}
+	writeAPIList(w, r, beads)
+}
+
+// handleAPIV1Bead returns a bead with its dependencies.
+func (h *GUIHandler) handleAPIV1Bead(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	reader, err := NewBeadsReader("")
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, apiErrUnavailable, err.Error())
+		return
+	}
+	bead, err := reader.GetBead(params["id"])
+	if errors.Is(err, beads.ErrNotFound) {
+		writeAPIError(w, http.StatusNotFound, apiErrNotFound, "no bead "+params["id"])
+		return
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, apiErrInternal, err.Error())
+		return
+	}
+	if len(bead.Dependencies) == 0 {
+		if deps, err := reader.GetBeadDependencies(bead.ID); err == nil {
+			bead.Dependencies = deps
+		}
+	}
+	writeAPIData(w, http.StatusOK, bead)
+}
+
+// handleAPIV1CreateBead creates a bead.
+func (h *GUIHandler) handleAPIV1CreateBead(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	var req beadCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "title is required")
+		return
+	}
+
+	output, id, err := createBead(req)
+	if err != nil || id == "" {
+		msg := output
+		if msg == "" && err != nil {
+			msg = err.Error()
+		}
+		writeAPIError(w, http.StatusInternalServerError, apiErrInternal, "creating bead: "+msg)
+		return
+	}
+	bead := &Bead{ID: id, Title: req.Title, Status: "open"}
+	if reader, err := NewBeadsReader(""); err == nil {
+		if created, err := reader.GetBead(id); err == nil && created != nil {
+			bead = created
+		}
+	}
+	writeAPIData(w, http.StatusCreated, bead)
+}
+
+// apiConvoy is a convoy in the v1 API.
+type apiConvoy struct {
+	ID            string           `json:"id"`
+	Title         string           `json:"title"`
+	Status        string           `json:"status"`
+	WorkStatus    string           `json:"work_status"`
+	Completed     int              `json:"completed"`
+	Total         int              `json:"total"`
+	LastActivity  *time.Time       `json:"last_activity,omitempty"`
+	TrackedIssues []apiTrackedBead `json:"tracked_issues"`
+}
+
+// apiTrackedBead is an issue tracked by a convoy.
+type apiTrackedBead struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Assignee string `json:"assignee,omitempty"`
+}
+
+func apiConvoyFromRow(row ConvoyRow) apiConvoy {
+	c := apiConvoy{
+		ID:            row.ID,
+		Title:         row.Title,
+		Status:        row.Status,
+		WorkStatus:    row.WorkStatus,
+		Completed:     row.Completed,
+		Total:         row.Total,
+		TrackedIssues: make([]apiTrackedBead, 0, len(row.TrackedIssues)),
+	}
+	if !row.LastActivity.LastActivity.IsZero() {
+		ts := row.LastActivity.LastActivity
+		c.LastActivity = &ts
+	}
+	for _, issue := range row.TrackedIssues {
+		c.TrackedIssues = append(c.TrackedIssues, apiTrackedBead(issue))
+	}
+	return c
+}
+
+// handleAPIV1Convoys lists open convoys, optionally by ?work_status=.
+func (h *GUIHandler) handleAPIV1Convoys(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	rows, err := h.fetcher.FetchConvoys()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, apiErrInternal, err.Error())
+		return
+	}
+	workStatus := r.URL.Query().Get("work_status")
+	convoys := make([]apiConvoy, 0, len(rows))
+	for _, row := range rows {
+		if workStatus == "" || row.WorkStatus == workStatus {
+			convoys = append(convoys, apiConvoyFromRow(row))
+		}
+	}
+	writeAPIList(w, r, convoys)
+}
+
+// handleAPIV1Convoy returns a convoy and the issues it tracks.
+func (h *GUIHandler) handleAPIV1Convoy(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	rows, err := h.fetcher.FetchConvoys()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, apiErrInternal, err.Error())
+		return
+	}
+	for _, row := range rows {
+		if row.ID == params["id"] {
+			writeAPIData(w, http.StatusOK, apiConvoyFromRow(row))
+			return
+		}
+	}
+	writeAPIError(w, http.StatusNotFound, apiErrNotFound, "no open convoy "+params["id"])
+}
+
+// apiMergeRequest is a merge-request bead in the v1 API.
+type apiMergeRequest struct {
+	ID          string `json:"id"`
+	Rig         string `json:"rig"`
+	Title       string `json:"title"`
+	Status      string `json:"status"`
+	Priority    int    `json:"priority"`
+	Branch      string `json:"branch,omitempty"`
+	Target      string `json:"target,omitempty"`
+	SourceIssue string `json:"source_issue,omitempty"`
+	Worker      string `json:"worker,omitempty"`
+	MergeCommit string `json:"merge_commit,omitempty"`
+	CloseReason string `json:"close_reason,omitempty"`
+	CreatedAt   string `json:"created_at,omitempty"`
+}
+
+// handleAPIV1MergeRequests lists the merge-request beads of the town's rigs,
+// optionally by ?rig= and ?status= (open by default, or closed or all).
+func (h *GUIHandler) handleAPIV1MergeRequests(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	q := r.URL.Query()
+	status := q.Get("status")
+	switch status {
+	case "":
+		status = "open"
+	case "open", "closed", "all":
+	default:
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "status must be open, closed or all")
+		return
+	}
+
+	rigs, err := apiRigs()
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, apiErrUnavailable, err.Error())
+		return
+	}
+	if name := q.Get("rig"); name != "" {
+		var kept []*rig.Rig
+		for _, rg := range rigs {
+			if rg.Name == name {
+				kept = append(kept, rg)
+			}
+		}
+		if len(kept) == 0 {
+			writeAPIError(w, http.StatusNotFound, apiErrNotFound, "no rig "+name)
+			return
+		}
+		rigs = kept
+	}
+
+	mrs := []apiMergeRequest{}
+	for _, rg := range rigs {
+		issues, err := beads.New(rg.BeadsPath()).List(beads.ListOptions{
+			Status:   status,
+			Type:     "merge-request",
+			Priority: -1,
+		})
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, apiErrInternal, "listing merge requests of "+rg.Name+": "+err.Error())
+			return
+		}
+		for _, issue := range issues {
+			mr := apiMergeRequest{
+				ID:        issue.ID,
+				Rig:       rg.Name,
+				Title:     issue.Title,
+				Status:    issue.Status,
+				Priority:  issue.Priority,
+				CreatedAt: issue.CreatedAt,
+			}
+			if fields := beads.ParseMRFields(issue); fields != nil {
+				mr.Branch = fields.Branch
+				mr.Target = fields.Target
+				mr.SourceIssue = fields.SourceIssue
+				mr.Worker = fields.Worker
+				mr.MergeCommit = fields.MergeCommit
+				mr.CloseReason = fields.CloseReason
+			}
+			mrs = append(mrs, mr)
+		}
+	}
+	sort.SliceStable(mrs, func(i, j int) bool {
+		if mrs[i].Rig != mrs[j].Rig {
+			return mrs[i].Rig < mrs[j].Rig
+		}
+		return mrs[i].ID < mrs[j].ID
+	})
+	writeAPIList(w, r, mrs)
+}
+
+// handleAPIV1Mail lists an agent's inbox (?agent=, default mayor/), newest
+// first, optionally only unread messages with ?unread=true.
+func (h *GUIHandler) handleAPIV1Mail(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	agent := r.URL.Query().Get("agent")
+	if agent == "" {
+		agent = "mayor/"
+	}
+	mailbox, err := h.mailboxForAgent(agent)
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, apiErrUnavailable, err.Error())
+		return
+	}
+	messages, err := mailbox.List()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, apiErrInternal, err.Error())
+		return
+	}
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+	kept := make([]*mail.Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg != nil && (!unreadOnly || !msg.Read) {
+			kept = append(kept, msg)
+		}
+	}
+	writeAPIList(w, r, kept)
+}
+
+// handleAPIV1SendMail sends a message from the overseer.
+func (h *GUIHandler) handleAPIV1SendMail(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	var req struct {
+		To       string `json:"to"`
+		Subject  string `json:"subject"`
+		Body     string `json:"body"`
+		Priority string `json:"priority"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if strings.TrimSpace(req.To) == "" || strings.TrimSpace(req.Subject) == "" {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, "to and subject are required")
+		return
+	}
+
+	router, err := h.mailRouter()
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, apiErrUnavailable, err.Error())
+		return
+	}
+	msg := &mail.Message{
+		From:     "overseer",
+		To:       req.To,
+		Subject:  req.Subject,
+		Body:     req.Body,
+		Priority: mail.ParsePriority(req.Priority),
+		Type:     mail.TypeNotification,
+	}
+	if err := router.Send(msg); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, apiErrInternal, err.Error())
+		return
+	}
+	writeAPIData(w, http.StatusCreated, msg)
+}
+
+// handleAPIV1Events lists town events, newest first, filtered by ?type=
+// (repeatable or comma-separated), ?actor=, and RFC 3339 ?since= / ?until=.
+// Each page reads back from its cursor only as far as it needs to, so the
+// total isn't counted.
+func (h *GUIHandler) handleAPIV1Events(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	p, err := parseAPIPagination(r)
+	if err == nil && p.kind == cursorOffset {
+		err = errors.New("invalid cursor")
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, err.Error())
+		return
+	}
+
+	q := r.URL.Query()
+	var filter events.Filter
+	for _, t := range q["type"] {
+		for _, part := range strings.Split(t, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				filter.Types = append(filter.Types, part)
+			}
+		}
+	}
+	filter.Actor = q.Get("actor")
+	for _, bound := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		if s := q.Get(bound.name); s != "" {
+			ts, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				writeAPIError(w, http.StatusBadRequest, apiErrBadRequest, bound.name+" must be an RFC 3339 time")
+				return
+			}
+			*bound.dst = ts
+		}
+	}
+	if p.kind == cursorEvent && (filter.Until.IsZero() || p.before.Before(filter.Until)) {
+		filter.Until = p.before
+	}
+	// One more than the page, to know whether there is another
+	filter.Limit = p.skip + p.limit + 1
+
+	townRoot, err := apiTownRoot()
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, apiErrUnavailable, err.Error())
+		return
+	}
+	found, err := events.Query(townRoot, filter)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, apiErrInternal, err.Error())
+		return
+	}
+	for i, j := 0, len(found)-1; i < j; i, j = i+1, j-1 {
+		found[i], found[j] = found[j], found[i]
+	}
+
+	// Drop the events at the cursor's time that earlier pages returned
+	for skipped := 0; skipped < p.skip && len(found) > 0 && eventTime(found[0]).Equal(p.before); skipped++ {
+		found = found[1:]
+	}
+
+	page := apiPage{Limit: p.limit}
+	if len(found) > p.limit {
+		found = found[:p.limit]
+		last := eventTime(found[len(found)-1])
+		skip := 0
+		for _, e := range found {
+			if eventTime(e).Equal(last) {
+				skip++
+			}
+		}
+		if last.Equal(p.before) {
+			skip += p.skip
+		}
+		page.NextCursor = apiEventCursor(last, skip)
+	}
+	if found == nil {
+		found = []events.Event{}
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]interface{}{"data": found, "page": page})
+}
+
+// eventTime returns when an event happened. Query only returns events with
+// valid timestamps.
+func eventTime(e events.Event) time.Time {
+	ts, _ := time.Parse(time.RFC3339, e.Timestamp)
+	return ts
+}
@@ -0,0 +1,322 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// apiResponse is a decoded v1 response envelope.
+type apiResponse struct {
+	Data  json.RawMessage `json:"data"`
+	Page  *apiPage        `json:"page"`
+	Error *apiError       `json:"error"`
+}
+
+func serveAPI(t *testing.T, h http.Handler, method, target string) (int, apiResponse) {
+	t.Helper()
+	r := httptest.NewRequest(method, target, nil)
+	r.RemoteAddr = "127.0.0.1:5555"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%s %s: Content-Type = %q", method, target, ct)
+	}
+	var resp apiResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: %v in %s", method, target, err, w.Body.String())
+	}
+	return w.Code, resp
+}
+
+func TestAPIV1OpenAPICoversRoutes(t *testing.T) {
+	var doc struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openAPIDocument, &doc); err != nil {
+		t.Fatalf("openapi.json: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Errorf("openapi = %q, want 3.x", doc.OpenAPI)
+	}
+
+	documented := make(map[string]bool)
+	for path, ops := range doc.Paths {
+		for method := range ops {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+	for _, route := range apiV1Routes {
+		key := route.method + " " + route.path
+		if !documented[key] {
+			t.Errorf("%s is not in openapi.json", key)
+		}
+		delete(documented, key)
+	}
+	for key := range documented {
+		t.Errorf("openapi.json documents %s, which has no route", key)
+	}
+}
+
+func TestAPIV1ConvoysAndPagination(t *testing.T) {
+	h, err := NewGUIHandler(&MockConvoyFetcher{
+		Convoys: []ConvoyRow{
+			{ID: "hq-cv-1", Title: "One", Status: "open", WorkStatus: "active", Completed: 1, Total: 2,
+				TrackedIssues: []TrackedIssue{{ID: "gt-a", Title: "A", Status: "closed"}, {ID: "gt-b", Title: "B", Status: "open", Assignee: "gastown/nux"}}},
+			{ID: "hq-cv-2", Title: "Two", Status: "open", WorkStatus: "stuck"},
+			{ID: "hq-cv-3", Title: "Three", Status: "open", WorkStatus: "active"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Walk the convoys a page at a time
+	var ids []string
+	target := "/api/v1/convoys?limit=2"
+	for pages := 0; target != ""; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination does not end")
+		}
+		code, resp := serveAPI(t, h, http.MethodGet, target)
+		if code != http.StatusOK || resp.Page == nil || resp.Page.Total == nil || *resp.Page.Total != 3 || resp.Page.Limit != 2 {
+			t.Fatalf("GET %s = %d %+v", target, code, resp.Page)
+		}
+		var convoys []apiConvoy
+		if err := json.Unmarshal(resp.Data, &convoys); err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range convoys {
+			ids = append(ids, c.ID)
+		}
+		target = ""
+		if resp.Page.NextCursor != "" {
+			target = "/api/v1/convoys?limit=2&cursor=" + resp.Page.NextCursor
+		}
+	}
+	if strings.Join(ids, ",") != "hq-cv-1,hq-cv-2,hq-cv-3" {
+		t.Errorf("paged convoys = %v", ids)
+	}
+
+	// Filter, then fetch one with its tracked issues
+	_, resp := serveAPI(t, h, http.MethodGet, "/api/v1/convoys?work_status=stuck")
+	if *resp.Page.Total != 1 || !strings.Contains(string(resp.Data), "hq-cv-2") {
+		t.Errorf("stuck convoys = %s", resp.Data)
+	}
+	code, resp := serveAPI(t, h, http.MethodGet, "/api/v1/convoys/hq-cv-1")
+	var convoy apiConvoy
+	if err := json.Unmarshal(resp.Data, &convoy); err != nil || code != http.StatusOK ||
+		len(convoy.TrackedIssues) != 2 || convoy.TrackedIssues[1].Assignee != "gastown/nux" {
+		t.Errorf("convoy = %d %+v (%v)", code, convoy, err)
+	}
+
+	// Errors share one envelope
+	for _, tt := range []struct {
+		method, target string
+		status         int
+		code           string
+	}{
+		{http.MethodGet, "/api/v1/convoys/hq-cv-404", http.StatusNotFound, apiErrNotFound},
+		{http.MethodGet, "/api/v1/nope", http.StatusNotFound, apiErrNotFound},
+		{http.MethodDelete, "/api/v1/convoys", http.StatusMethodNotAllowed, apiErrMethodNotAllowed},
+		{http.MethodGet, "/api/v1/convoys?cursor=%21%21", http.StatusBadRequest, apiErrBadRequest},
+		{http.MethodGet, "/api/v1/convoys?limit=0", http.StatusBadRequest, apiErrBadRequest},
+		{http.MethodGet, "/api/v1/events?since=yesterday", http.StatusBadRequest, apiErrBadRequest},
+	} {
+		code, resp := serveAPI(t, h, tt.method, tt.target)
+		if code != tt.status || resp.Error == nil || resp.Error.Code != tt.code || resp.Error.Message == "" {
+			t.Errorf("%s %s = %d %+v, want %d %s", tt.method, tt.target, code, resp.Error, tt.status, tt.code)
+		}
+	}
+}
+
+func TestAPIV1Events(t *testing.T) {
+	townRoot := t.TempDir()
+	t.Setenv("GT_ROOT", townRoot)
+	log := `{"ts":"2026-01-01T10:00:00Z","type":"sling","actor":"mayor","visibility":"feed"}
+{"ts":"2026-01-01T11:00:00Z","type":"done","actor":"gastown/nux","visibility":"feed"}
+{"ts":"2026-01-01T12:00:00Z","type":"sling","actor":"mayor","visibility":"feed"}
+`
+	if err := os.WriteFile(filepath.Join(townRoot, events.EventsFile), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewGUIHandler(&MockConvoyFetcher{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, resp := serveAPI(t, h, http.MethodGet, "/api/v1/events?type=sling&since=2026-01-01T09:00:00Z")
+	var got []events.Event
+	if err := json.Unmarshal(resp.Data, &got); err != nil || code != http.StatusOK {
+		t.Fatalf("events = %d %s", code, resp.Data)
+	}
+	if len(got) != 2 || got[0].Timestamp != "2026-01-01T12:00:00Z" || got[1].Timestamp != "2026-01-01T10:00:00Z" {
+		t.Errorf("events = %+v, want both slings newest first", got)
+	}
+
+	// List cursors aren't event cursors
+	code, resp = serveAPI(t, h, http.MethodGet, "/api/v1/events?cursor="+apiCursor(2))
+	if code != http.StatusBadRequest || resp.Error == nil {
+		t.Errorf("events with an offset cursor = %d %+v, want 400", code, resp.Error)
+	}
+}
+
+func TestAPIV1EventsCursorIsStable(t *testing.T) {
+	townRoot := t.TempDir()
+	t.Setenv("GT_ROOT", townRoot)
+	path := filepath.Join(townRoot, events.EventsFile)
+	appendEvent := func(ts, actor string) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(`{"ts":"` + ts + `","type":"nudge","actor":"` + actor + `","visibility":"feed"}` + "\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Several events share a second, as they do in busy towns
+	appendEvent("2026-01-01T10:00:00Z", "a")
+	appendEvent("2026-01-01T10:00:01Z", "b")
+	appendEvent("2026-01-01T10:00:01Z", "c")
+	appendEvent("2026-01-01T10:00:01Z", "d")
+	appendEvent("2026-01-01T10:00:02Z", "e")
+	h, err := NewGUIHandler(&MockConvoyFetcher{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actors []string
+	target := "/api/v1/events?limit=2"
+	for pages := 0; target != ""; pages++ {
+		if pages > 4 {
+			t.Fatal("pagination does not end")
+		}
+		code, resp := serveAPI(t, h, http.MethodGet, target)
+		var page []events.Event
+		if err := json.Unmarshal(resp.Data, &page); err != nil || code != http.StatusOK {
+			t.Fatalf("GET %s = %d %s", target, code, resp.Data)
+		}
+		if resp.Page.Total != nil {
+			t.Errorf("events page has a total: %d", *resp.Page.Total)
+		}
+		for _, e := range page {
+			actors = append(actors, e.Actor)
+		}
+		// New events arriving between pages don't shift the pages
+		appendEvent("2026-01-01T11:00:00Z", "new")
+		target = ""
+		if resp.Page.NextCursor != "" {
+			target = "/api/v1/events?limit=2&cursor=" + resp.Page.NextCursor
+		}
+	}
+	if got := strings.Join(actors, ","); got != "e,d,c,b,a" {
+		t.Errorf("paged actors = %s, want e,d,c,b,a", got)
+	}
+}
+
+func TestAPIV1MergeRequests(t *testing.T) {
+	townRoot := t.TempDir()
+	t.Setenv("GT_ROOT", townRoot)
+	rigsJSON := `{"version":1,"rigs":{"gastown":{"git_url":"x"},"beads":{"git_url":"y"}}}`
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "rigs.json"), []byte(rigsJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"gastown", "beads"} {
+		if err := os.MkdirAll(filepath.Join(townRoot, name, ".beads"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mr := `[{"id":"gt-mr1","title":"Merge polecat/nux","status":"open","priority":1,` +
+		`"description":"branch: polecat/nux\ntarget: main\nsource_issue: gt-1\nworker: nux"}]`
+	if err := os.WriteFile(filepath.Join(townRoot, "gastown", ".beads", "mrs.json"), []byte(mr), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// bd answers from mrs.json in the rig's beads dir, and fails without one
+	bin := t.TempDir()
+	script := "#!/bin/sh\ncase \"$*\" in\n  *list*) cat \"$BEADS_DIR/mrs.json\" 2>/dev/null && exit 0; echo 'database is locked' >&2; exit 1 ;;\nesac\n"
+	if err := os.WriteFile(filepath.Join(bin, "bd"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	h, err := NewGUIHandler(&MockConvoyFetcher{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, resp := serveAPI(t, h, http.MethodGet, "/api/v1/mrs?rig=gastown")
+	var mrs []apiMergeRequest
+	if err := json.Unmarshal(resp.Data, &mrs); err != nil || code != http.StatusOK {
+		t.Fatalf("mrs = %d %s", code, resp.Data)
+	}
+	if len(mrs) != 1 || mrs[0].ID != "gt-mr1" || mrs[0].Rig != "gastown" || mrs[0].Branch != "polecat/nux" ||
+		mrs[0].Target != "main" || mrs[0].SourceIssue != "gt-1" || mrs[0].Worker != "nux" {
+		t.Errorf("mrs = %+v", mrs)
+	}
+
+	// A rig whose beads can't be read fails the request rather than hiding its MRs
+	code, resp = serveAPI(t, h, http.MethodGet, "/api/v1/mrs")
+	if code != http.StatusInternalServerError || resp.Error == nil || resp.Error.Code != apiErrInternal {
+		t.Errorf("mrs with a broken rig = %d %+v, want 500", code, resp.Error)
+	}
+	code, _ = serveAPI(t, h, http.MethodGet, "/api/v1/mrs?rig=nope")
+	if code != http.StatusNotFound {
+		t.Errorf("mrs of an unknown rig = %d, want 404", code)
+	}
+}
+
+func TestAPIV1BeadErrors(t *testing.T) {
+	bin := t.TempDir()
+	script := "#!/bin/sh\ncase \"$*\" in\n  *list*) echo '[]' ;;\n  *gt-missing*) echo 'Error: no issue found matching \"gt-missing\"' >&2; exit 1 ;;\n  *) echo 'database is locked' >&2; exit 1 ;;\nesac\n"
+	if err := os.WriteFile(filepath.Join(bin, "bd"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("GT_ROOT", t.TempDir())
+
+	h, err := NewGUIHandler(&MockConvoyFetcher{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, resp := serveAPI(t, h, http.MethodGet, "/api/v1/beads/gt-missing"); code != http.StatusNotFound || resp.Error.Code != apiErrNotFound {
+		t.Errorf("missing bead = %d %+v, want 404", code, resp.Error)
+	}
+	if code, resp := serveAPI(t, h, http.MethodGet, "/api/v1/beads/gt-abc"); code != http.StatusInternalServerError || resp.Error.Code != apiErrInternal {
+		t.Errorf("bd failure = %d %+v, want 500", code, resp.Error)
+	}
+}
+
+func TestAPIV1AuthErrorsUseEnvelope(t *testing.T) {
+	origToken := authConfig.token
+	authConfig.token = "s3cret"
+	t.Cleanup(func() { authConfig.token = origToken })
+
+	h, err := NewGUIHandler(&MockConvoyFetcher{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, resp := serveAPI(t, h, http.MethodGet, "/api/v1/convoys")
+	if code != http.StatusUnauthorized || resp.Error == nil || resp.Error.Code != apiErrUnauthorized {
+		t.Errorf("anonymous = %d %+v, want 401 unauthorized", code, resp.Error)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/convoys", nil)
+	r.RemoteAddr = "127.0.0.1:5555"
+	r.Header.Set("Authorization", "Bearer s3cret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("bearer = %d %s", w.Code, w.Body.String())
+	}
+}
@@ -1,9 +1,9 @@
 package web
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -15,7 +15,8 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 )
 
-// BeadsReader provides access to beads via bd CLI commands.
+// BeadsReader provides access to beads, reading the store in-process and
+// falling back to bd CLI commands.
 type BeadsReader struct {
 	townRoot string
 	workDir  string
@@ -131,8 +132,8 @@ func (r *BeadsReader) ListBeads(filter BeadFilter) ([]Bead, error) {
 	}
 
 	var beads []Bead
-	if jsonl, err := r.readIssuesJSONL(); err == nil {
-		beads = jsonl
+	if stored, err := r.readStore(); err == nil {
+		beads = stored
 	} else {
 		cmd, cancel := r.beadsCommand(args...)
 		defer cancel()
@@ -186,15 +187,15 @@ func (r *BeadsReader) ListBeads(filter BeadFilter) ([]Bead, error) {
 
 // ReadyBeads returns open beads that are not blocked by dependencies.
 func (r *BeadsReader) ReadyBeads() ([]Bead, error) {
-	if jsonl, err := r.readIssuesJSONL(); err == nil {
-		statusByID := make(map[string]string, len(jsonl))
-		for _, b := range jsonl {
+	if stored, err := r.readStore(); err == nil {
+		statusByID := make(map[string]string, len(stored))
+		for _, b := range stored {
 			statusByID[b.ID] = b.Status
 		}
 
 		now := time.Now()
-		ready := make([]Bead, 0, len(jsonl))
-		for _, b := range jsonl {
+		ready := make([]Bead, 0, len(stored))
+		for _, b := range stored {
 			if b.Status != "open" || b.Ephemeral {
 				continue
 			}
@@ -292,8 +293,8 @@ func parseDeferredTime(primary, fallback *string) *time.Time {
 
 // GetBead returns a single bead by ID using bd show --json.
 func (r *BeadsReader) GetBead(id string) (*Bead, error) {
-	if jsonl, err := r.readIssuesJSONL(); err == nil {
-		for _, b := range jsonl {
+	if stored, err := r.readStore(); err == nil {
+		for _, b := range stored {
 			if b.ID == id {
 				bead := b
 				return &bead, nil
@@ -305,26 +306,35 @@ func (r *BeadsReader) GetBead(id string) (*Bead, error) {
 	defer cancel()
 	output, err := cmd.Output()
 	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && isNotFoundOutput(string(exitErr.Stderr)) {
+			return nil, fmt.Errorf("%w: %s", beads.ErrNotFound, id)
+		}
 		return nil, fmt.Errorf("bd show failed: %w", err)
 	}
 
-	var beads []Bead
-	if err := json.Unmarshal(output, &beads); err != nil {
+	var found []Bead
+	if err := json.Unmarshal(output, &found); err != nil {
 		return nil, fmt.Errorf("parse error: %w", err)
 	}
-	if len(beads) == 0 {
-		return nil, fmt.Errorf("bead not found: %s", id)
+	if len(found) == 0 {
+		return nil, fmt.Errorf("%w: %s", beads.ErrNotFound, id)
 	}
 
-	return &beads[0], nil
+	return &found[0], nil
+}
+
+// isNotFoundOutput reports whether bd's stderr says an issue doesn't exist.
+func isNotFoundOutput(stderr string) bool {
+	return strings.Contains(stderr, "not found") || strings.Contains(stderr, "no issue found")
 }
 
 // GetConvoyTrackedIssues returns the issues tracked by a convoy.
 // Convoys use dependency type "tracks" to link to their issues.
 func (r *BeadsReader) GetConvoyTrackedIssues(convoyID string) ([]Bead, error) {
-	if jsonl, err := r.readIssuesJSONL(); err == nil {
-		beadsByID := make(map[string]Bead, len(jsonl))
-		for _, b := range jsonl {
+	if stored, err := r.readStore(); err == nil {
+		beadsByID := make(map[string]Bead, len(stored))
+		for _, b := range stored {
 			beadsByID[b.ID] = b
 		}
 
@@ -548,8 +558,8 @@ func (r *BeadsReader) getAgentHook(agent, role string) AgentHook {
 
 // GetBeadDependencies returns all dependencies for a bead.
 func (r *BeadsReader) GetBeadDependencies(beadID string) ([]BeadDependency, error) {
-	if jsonl, err := r.readIssuesJSONL(); err == nil {
-		for _, bead := range jsonl {
+	if stored, err := r.readStore(); err == nil {
+		for _, bead := range stored {
 			if bead.ID == beadID {
 				return bead.Dependencies, nil
 			}
@@ -597,8 +607,8 @@ func (r *BeadsReader) GetBeadStats() (map[string]int, error) {
 	stats := make(map[string]int)
 
 	var beads []Bead
-	if jsonl, err := r.readIssuesJSONL(); err == nil {
-		beads = jsonl
+	if stored, err := r.readStore(); err == nil {
+		beads = stored
 	} else {
 		// Get all beads with JSON
 		cmd, cancel := r.beadsCommand("list", "--json", "--limit=0")
@@ -627,10 +637,10 @@ func (r *BeadsReader) GetBeadStats() (map[string]int, error) {
 
 // SearchBeads searches beads by text in title and description.
 func (r *BeadsReader) SearchBeads(searchQuery string, limit int) ([]Bead, error) {
-	if jsonl, err := r.readIssuesJSONL(); err == nil {
+	if stored, err := r.readStore(); err == nil {
 		needle := strings.ToLower(searchQuery)
 		matches := make([]Bead, 0)
-		for _, b := range jsonl {
+		for _, b := range stored {
 			if needle == "" {
 				continue
 			}
@@ -667,93 +677,56 @@ func (r *BeadsReader) SearchBeads(searchQuery string, limit int) ([]Bead, error)
 	return beads, nil
 }
 
-type issueJSONL struct {
-	ID            string           `json:"id"`
-	Title         string           `json:"title"`
-	Description   string           `json:"description"`
-	Status        string           `json:"status"`
-	Priority      int              `json:"priority"`
-	Type          string           `json:"issue_type"`
-	Owner         string           `json:"owner"`
-	Assignee      string           `json:"assignee"`
-	Labels        []string         `json:"labels"`
-	CreatedAt     string           `json:"created_at"`
-	UpdatedAt     string           `json:"updated_at"`
-	ClosedAt      *string          `json:"closed_at"`
-	DeferUntil    *string          `json:"defer_until"`
-	DeferredUntil *string          `json:"deferred_until"`
-	Ephemeral     bool             `json:"ephemeral"`
-	Wisp          bool             `json:"wisp"`
-	Dependencies  []BeadDependency `json:"dependencies"`
-}
-
-func (r *BeadsReader) readIssuesJSONL() ([]Bead, error) {
-	issuesPath := filepath.Join(r.beadsDir, "issues.jsonl")
-	file, err := os.Open(issuesPath)
+// readStore loads all beads in-process from the beads store
+// (SQLite or issues.jsonl), avoiding a bd fork per dashboard query.
+func (r *BeadsReader) readStore() ([]Bead, error) {
+	issues, err := beads.OpenStore(r.beadsDir).Issues()
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
+	if len(issues) == 0 {
+		return nil, fmt.Errorf("no issues loaded from %s", r.beadsDir)
+	}
 
-	beadsOut := make([]Bead, 0, 128)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-		var entry issueJSONL
-		if err := json.Unmarshal([]byte(line), &entry); err != nil {
-			return nil, err
-		}
-		if entry.ID == "" {
-			continue
+	beadsOut := make([]Bead, 0, len(issues))
+	for _, issue := range issues {
+		bead := Bead{
+			ID:          issue.ID,
+			Title:       issue.Title,
+			Description: issue.Description,
+			Status:      issue.Status,
+			Priority:    issue.Priority,
+			Type:        issue.Type,
+			Owner:       issue.Owner,
+			Assignee:    issue.Assignee,
+			Labels:      issue.Labels,
+			Ephemeral:   issue.Ephemeral,
+		}
+		for _, dep := range issue.Dependencies {
+			bead.Dependencies = append(bead.Dependencies, BeadDependency{
+				IssueID:     issue.ID,
+				DependsOnID: dep.ID,
+				Type:        dep.DependencyType,
+			})
 		}
 
-		bead := Bead{
-			ID:           entry.ID,
-			Title:        entry.Title,
-			Description:  entry.Description,
-			Status:       entry.Status,
-			Priority:     entry.Priority,
-			Type:         entry.Type,
-			Owner:        entry.Owner,
-			Assignee:     entry.Assignee,
-			Labels:       entry.Labels,
-			Ephemeral:    entry.Ephemeral || entry.Wisp,
-			Dependencies: entry.Dependencies,
-		}
-
-		if entry.CreatedAt != "" {
-			if t, err := time.Parse(time.RFC3339, entry.CreatedAt); err == nil {
-				bead.CreatedAt = t
-			}
+		if t, err := time.Parse(time.RFC3339, issue.CreatedAt); err == nil {
+			bead.CreatedAt = t
 		}
-		if entry.UpdatedAt != "" {
-			if t, err := time.Parse(time.RFC3339, entry.UpdatedAt); err == nil {
-				bead.UpdatedAt = t
-			}
+		if t, err := time.Parse(time.RFC3339, issue.UpdatedAt); err == nil {
+			bead.UpdatedAt = t
 		}
-		if entry.ClosedAt != nil && *entry.ClosedAt != "" {
-			if t, err := time.Parse(time.RFC3339, *entry.ClosedAt); err == nil {
+		if issue.ClosedAt != "" {
+			if t, err := time.Parse(time.RFC3339, issue.ClosedAt); err == nil {
 				bead.ClosedAt = &t
 			}
 		}
-		if deferUntil := parseDeferredTime(entry.DeferUntil, entry.DeferredUntil); deferUntil != nil {
+		if deferUntil := parseDeferredTime(&issue.DeferUntil, nil); deferUntil != nil {
 			bead.DeferUntil = deferUntil
 		}
 
 		beadsOut = append(beadsOut, bead)
 	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-	if len(beadsOut) == 0 {
-		return nil, fmt.Errorf("no issues loaded from %s", issuesPath)
-	}
 	return beadsOut, nil
 }
 
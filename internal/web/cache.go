@@ -247,6 +247,12 @@ type StatusCache struct {
 	cache *Cache
 	ttl   time.Duration
 	key   string
+
+	// Live pushes: the newest status known to include a change sequence.
+	buildMu sync.Mutex
+	liveMu  sync.Mutex
+	liveSeq uint64
+	live    *StatusResponse
 }
 
 // NewStatusCache creates a status cache.
@@ -285,6 +291,41 @@ func (s *StatusCache) GetOrBuild(build func() StatusResponse) StatusResponse {
 	return status
 }
 
+// BuildAfter returns a status built after change seq was published, so it
+// reflects that change. Concurrent callers waiting on the same or an older
+// change share one build instead of each rebuilding.
+func (s *StatusCache) BuildAfter(seq uint64, build func() StatusResponse) StatusResponse {
+	if status, ok := s.liveAfter(seq); ok {
+		return status
+	}
+
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+	if status, ok := s.liveAfter(seq); ok {
+		return status
+	}
+
+	status := build()
+	s.cache.Set(s.key, statusToMap(status), s.ttl)
+
+	s.liveMu.Lock()
+	if seq >= s.liveSeq {
+		s.liveSeq = seq
+		s.live = &status
+	}
+	s.liveMu.Unlock()
+	return status
+}
+
+func (s *StatusCache) liveAfter(seq uint64) (StatusResponse, bool) {
+	s.liveMu.Lock()
+	defer s.liveMu.Unlock()
+	if s.live != nil && s.liveSeq >= seq {
+		return *s.live, true
+	}
+	return StatusResponse{}, false
+}
+
 // statusToMap converts StatusResponse to a map for JSON storage.
 func statusToMap(s StatusResponse) map[string]interface{} {
 	data, _ := json.Marshal(s)
@@ -73,6 +73,12 @@ func (f *LiveConvoyFetcher) FetchConvoys() ([]ConvoyRow, error) {
 	return rows, nil
 }
 
+// InvalidateCache drops cached convoy rows so the next fetch is fresh.
+// Called when the change stream reports bead updates.
+func (f *LiveConvoyFetcher) InvalidateCache() {
+	f.cache.Invalidate("convoys")
+}
+
 // fetchConvoysUncached does the actual convoy fetching without caching.
 func (f *LiveConvoyFetcher) fetchConvoysUncached() ([]ConvoyRow, error) {
 	reader, err := NewBeadsReaderWithBeadsDir(filepath.Dir(f.townBeads), f.townBeads)
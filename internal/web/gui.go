@@ -12,6 +12,9 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 //go:embed templates/*.html
@@ -31,12 +34,15 @@ type GUIHandler struct {
 	statusCache       *StatusCache
 	cache             *Cache
 	historyMu         sync.Mutex
+	changes           *changeHub // nil outside a town: live views fall back to polling
+	users             *userStore // named accounts; empty when no users file exists
 }
 
 // authConfig controls authentication behavior.
 // By default, only localhost connections are allowed.
 // Set GT_WEB_AUTH_TOKEN env var to require token auth for all requests.
-// Set GT_WEB_ALLOW_REMOTE=1 to allow non-localhost (REQUIRES GT_WEB_AUTH_TOKEN).
+// Add users to the users file (see UsersFile) to require named logins with roles.
+// Set GT_WEB_ALLOW_REMOTE=1 to allow non-localhost (REQUIRES a token or users).
 var authConfig = struct {
 	token       string
 	allowRemote bool
@@ -45,14 +51,17 @@ var authConfig = struct {
 	allowRemote: os.Getenv("GT_WEB_ALLOW_REMOTE") == "1",
 }
 
-// ErrInsecureRemoteConfig is returned when GT_WEB_ALLOW_REMOTE=1 is set without GT_WEB_AUTH_TOKEN.
-var ErrInsecureRemoteConfig = errors.New("SECURITY: GT_WEB_ALLOW_REMOTE=1 requires GT_WEB_AUTH_TOKEN to be set")
+// ErrInsecureRemoteConfig is returned when GT_WEB_ALLOW_REMOTE=1 is set without GT_WEB_AUTH_TOKEN or web users.
+var ErrInsecureRemoteConfig = errors.New("SECURITY: GT_WEB_ALLOW_REMOTE=1 requires GT_WEB_AUTH_TOKEN to be set or web users to be configured")
 
 // NewGUIHandler creates a new GUI handler with all routes.
-// Returns ErrInsecureRemoteConfig if GT_WEB_ALLOW_REMOTE=1 is set without GT_WEB_AUTH_TOKEN.
+// Returns ErrInsecureRemoteConfig if GT_WEB_ALLOW_REMOTE=1 is set without GT_WEB_AUTH_TOKEN or web users.
 func NewGUIHandler(fetcher ConvoyFetcher) (*GUIHandler, error) {
+	townRoot, _ := workspace.FindFromCwd()
+	users := newUserStore(UsersFile(townRoot))
+
 	// SECURITY: Reject insecure remote configuration
-	if authConfig.allowRemote && authConfig.token == "" {
+	if authConfig.allowRemote && authConfig.token == "" && !users.configured() {
 		return nil, ErrInsecureRemoteConfig
 	}
 
@@ -61,6 +70,11 @@ func NewGUIHandler(fetcher ConvoyFetcher) (*GUIHandler, error) {
 		mux:         http.NewServeMux(),
 		statusCache: NewStatusCache(StatusCacheTTL),
 		cache:       NewCache(),
+		users:       users,
+	}
+	if townRoot != "" {
+		h.changes = newChangeHub(townRoot)
+		h.changes.onBatch = h.invalidateForChanges
 	}
 
 	// Static files (CSS, JS)
@@ -73,6 +87,7 @@ func NewGUIHandler(fetcher ConvoyFetcher) (*GUIHandler, error) {
 	// Auth routes (these bypass the auth middleware)
 	h.mux.HandleFunc("/login", h.handleLogin)
 	h.mux.HandleFunc("/logout", h.handleLogout)
+	h.mux.HandleFunc("/api/auth/me", h.handleAPIAuthMe)
 
 	// Page routes
 	h.mux.HandleFunc("/", h.handleDashboard)
@@ -97,6 +112,8 @@ func NewGUIHandler(fetcher ConvoyFetcher) (*GUIHandler, error) {
 	// Dashboard API routes
 	h.mux.HandleFunc("/api/status", h.handleAPIStatus)
 	h.mux.HandleFunc("/ws/status", h.handleStatusWS)
+	h.mux.HandleFunc("/ws/changes", h.handleChangesWS)
+	h.mux.HandleFunc("/api/stream", h.handleAPIStream)
 	h.mux.HandleFunc("/api/issues", h.handleAPIIssues)
 	h.mux.HandleFunc("/api/agents", h.handleAPIRoleBeads)
 
@@ -194,14 +211,21 @@ func NewGUIHandler(fetcher ConvoyFetcher) (*GUIHandler, error) {
 	h.mux.HandleFunc("/api/rigs", h.handleAPIRigs)
 	h.mux.HandleFunc("/api/convoys", h.handleAPIConvoys)
 
+	// Versioned REST API for scripts and integrations (see api_v1.go)
+	h.mux.HandleFunc(apiV1Prefix+"/", h.handleAPIV1)
+
 	return h, nil
 }
 
 // ServeHTTP implements http.Handler with authentication middleware.
 // Authentication requirements:
-// - If GT_WEB_AUTH_TOKEN is set: requires valid session cookie OR Authorization: Bearer <token> header
-// - If GT_WEB_ALLOW_REMOTE is not set: only allows localhost connections
-// - Fails closed: rejects requests that don't meet auth requirements
+//   - If GT_WEB_AUTH_TOKEN is set or web users exist: requires a valid session cookie,
+//     Authorization: Bearer <token>, or HTTP basic auth as a user
+//   - If GT_WEB_ALLOW_REMOTE is not set: only allows localhost connections
+//   - Fails closed: rejects requests that don't meet auth requirements
+//
+// Each request then needs the role its route requires (see routePolicies),
+// and state-changing requests are written to the audit log with who made them.
 func (h *GUIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Login/logout pages and static files bypass auth
 	if r.URL.Path == "/login" || r.URL.Path == "/logout" || strings.HasPrefix(r.URL.Path, "/static/") {
@@ -209,9 +233,12 @@ func (h *GUIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check token auth if configured
-	if authConfig.token != "" {
-		if !h.isAuthenticated(r) {
+	// Without any auth configured, the (localhost) user can do everything
+	principal := Principal{Name: localPrincipalName, Role: RoleAdmin}
+
+	if h.authRequired() {
+		p, ok := h.authenticate(r)
+		if !ok {
 			// For browser page requests, redirect to login page
 			// Only redirect for explicit HTML requests, not API/WebSocket
 			if isPageRequest(r) {
@@ -219,48 +246,80 @@ func (h *GUIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			// For API/WebSocket requests, return 401
-			log.Printf("Auth failed: invalid or missing token from %s for %s", r.RemoteAddr, r.URL.Path)
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			log.Printf("Auth failed: invalid or missing credentials from %s for %s", r.RemoteAddr, r.URL.Path)
+			httpError(w, r, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
-		// CSRF check: exempt Bearer-auth API calls (token proves intent)
-		isBearerAuth := r.Header.Get("Authorization") == "Bearer "+authConfig.token
-		if isStateChangingMethod(r.Method) && !isBearerAuth && !validateCSRF(r) {
+		// CSRF check: exempt header-auth API calls (credentials prove intent)
+		if isStateChangingMethod(r.Method) && !p.header && !validateCSRF(r) {
 			log.Printf("CSRF failed: missing or invalid token from %s for %s", r.RemoteAddr, r.URL.Path)
-			http.Error(w, "Forbidden: invalid CSRF token", http.StatusForbidden)
+			httpError(w, r, "Forbidden: invalid CSRF token", http.StatusForbidden)
 			return
 		}
 
 		ensureCSRFCookie(w, r)
+		principal = p
 	}
 
 	// Check localhost unless remote explicitly allowed
 	if !authConfig.allowRemote && !isLocalhost(r) {
 		log.Printf("Auth failed: non-localhost request from %s (set GT_WEB_ALLOW_REMOTE=1 to allow)", r.RemoteAddr)
-		http.Error(w, "Forbidden: localhost only", http.StatusForbidden)
+		httpError(w, r, "Forbidden: localhost only", http.StatusForbidden)
+		return
+	}
+
+	// Role check
+	if required := requiredRole(r); !principal.Role.Allows(required) {
+		log.Printf("Forbidden: %s (%s) needs %s for %s %s", principal.Name, principal.Role, required, r.Method, r.URL.Path)
+		auditDenied(r, principal, required)
+		httpError(w, r, "Forbidden: requires "+string(required)+" role", http.StatusForbidden)
 		return
 	}
 
+	r = withPrincipal(r, principal)
+	if isStateChangingMethod(r.Method) {
+		h.serveAudited(w, r, principal)
+		return
+	}
 	h.mux.ServeHTTP(w, r)
 }
 
-// isAuthenticated checks if the request has valid authentication.
-// Supports both cookie-based and header-based authentication.
-func (h *GUIHandler) isAuthenticated(r *http.Request) bool {
+// authRequired reports whether requests must authenticate: when the shared
+// token is set or the users file has users.
+func (h *GUIHandler) authRequired() bool {
+	return authConfig.token != "" || h.users.configured()
+}
+
+// authenticate returns who a request is from.
+// Supports bearer token and basic auth headers, and session cookies for
+// both the shared token and named users.
+func (h *GUIHandler) authenticate(r *http.Request) (Principal, bool) {
+	token := authConfig.token
+
 	// Check Authorization header
-	auth := r.Header.Get("Authorization")
-	if auth == "Bearer "+authConfig.token {
-		return true
+	if token != "" && r.Header.Get("Authorization") == "Bearer "+token {
+		return Principal{Name: tokenPrincipalName, Role: RoleAdmin, header: true}, true
+	}
+	if name, password, ok := r.BasicAuth(); ok {
+		if u, ok := h.users.verify(name, password); ok {
+			return Principal{Name: u.Name, Role: u.Role, header: true}, true
+		}
+		return Principal{}, false
 	}
 
 	// Check session cookie
 	cookie, err := r.Cookie(sessionCookieName)
-	if err == nil && cookie.Value == generateSessionToken(authConfig.token) {
-		return true
+	if err != nil {
+		return Principal{}, false
 	}
-
-	return false
+	if token != "" && cookie.Value == generateSessionToken(token) {
+		return Principal{Name: tokenPrincipalName, Role: RoleAdmin}, true
+	}
+	if u, ok := h.users.session(cookie.Value, time.Now()); ok {
+		return Principal{Name: u.Name, Role: u.Role}, true
+	}
+	return Principal{}, false
 }
 
 // isHTMLRequest checks if the request expects an HTML response.
@@ -295,24 +354,28 @@ func generateSessionToken(token string) string {
 }
 
 // handleLogin serves the login page and handles login form submission.
+// The form takes a user name and password when web users are configured,
+// or the shared token.
 func (h *GUIHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodPost {
+		if name := r.FormValue("username"); name != "" {
+			u, ok := h.users.verify(name, r.FormValue("password"))
+			auditLogin(r, name, ok)
+			if ok {
+				h.startSession(w, r, h.users.newSession(u.Name, time.Now()))
+				return
+			}
+			h.serveLoginPage(w, "Invalid user name or password")
+			return
+		}
+
 		token := r.FormValue("token")
-		if token == authConfig.token {
-			// Set session cookie
-			http.SetCookie(w, &http.Cookie{
-				Name:     sessionCookieName,
-				Value:    generateSessionToken(token),
-				Path:     "/",
-				HttpOnly: true,
-				Secure:   r.TLS != nil,
-				SameSite: http.SameSiteLaxMode,
-				MaxAge:   86400 * 30, // 30 days
-			})
-			ensureCSRFCookie(w, r)
-			http.Redirect(w, r, "/", http.StatusFound)
+		if authConfig.token != "" && token == authConfig.token {
+			auditLogin(r, tokenPrincipalName, true)
+			h.startSession(w, r, generateSessionToken(token))
 			return
 		}
+		auditLogin(r, tokenPrincipalName, false)
 		// Show login page with error
 		h.serveLoginPage(w, "Invalid token")
 		return
@@ -321,20 +384,12 @@ func (h *GUIHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
 		token := r.URL.Query().Get("token")
 		if token != "" {
-			if token == authConfig.token {
-				http.SetCookie(w, &http.Cookie{
-					Name:     sessionCookieName,
-					Value:    generateSessionToken(token),
-					Path:     "/",
-					HttpOnly: true,
-					Secure:   r.TLS != nil,
-					SameSite: http.SameSiteLaxMode,
-					MaxAge:   86400 * 30, // 30 days
-				})
-				ensureCSRFCookie(w, r)
-				http.Redirect(w, r, "/", http.StatusFound)
+			if authConfig.token != "" && token == authConfig.token {
+				auditLogin(r, tokenPrincipalName, true)
+				h.startSession(w, r, generateSessionToken(token))
 				return
 			}
+			auditLogin(r, tokenPrincipalName, false)
 			h.serveLoginPage(w, "Invalid token")
 			return
 		}
@@ -344,6 +399,21 @@ func (h *GUIHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 	h.serveLoginPage(w, "")
 }
 
+// startSession sets the session cookie and sends the browser home.
+func (h *GUIHandler) startSession(w http.ResponseWriter, r *http.Request, value string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   86400 * 30, // 30 days
+	})
+	ensureCSRFCookie(w, r)
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
 // handleLogout clears the session cookie.
 func (h *GUIHandler) handleLogout(w http.ResponseWriter, r *http.Request) {
 	http.SetCookie(w, &http.Cookie{
@@ -365,6 +435,39 @@ func (h *GUIHandler) serveLoginPage(w http.ResponseWriter, errorMsg string) {
 		errorHTML = `<div class="error">` + errorMsg + `</div>`
 	}
 
+	// Named users sign in with a password; otherwise the shared token is
+	// entered, with a login link that carries it
+	fieldsHTML := `
+            <div class="form-group">
+                <label for="token">Access Token</label>
+                <input type="password" id="token" name="token" placeholder="Enter GT_WEB_AUTH_TOKEN" required autofocus>
+            </div>`
+	linkHTML := `
+        <div class="divider"></div>
+        <div class="link-card">
+            <div class="link-title">Mobile Login Link</div>
+            <input type="text" id="login-link" class="link-input" readonly placeholder="Enter token to generate link">
+            <div class="link-actions">
+                <button type="button" class="secondary" id="copy-link" disabled>Copy Link</button>
+                <button type="button" id="share-link" disabled>Share Link</button>
+            </div>
+            <div class="link-note">This link contains your token. Share only with trusted devices.</div>
+        </div>`
+	hint := "Token is set via GT_WEB_AUTH_TOKEN environment variable"
+	if h.users.configured() {
+		fieldsHTML = `
+            <div class="form-group">
+                <label for="username">User</label>
+                <input type="text" id="username" name="username" autocomplete="username" required autofocus>
+            </div>
+            <div class="form-group">
+                <label for="password">Password</label>
+                <input type="password" id="password" name="password" autocomplete="current-password" required>
+            </div>`
+		linkHTML = ""
+		hint = "Accounts are managed with gt gui user"
+	}
+
 	html := `<!DOCTYPE html>
 <html>
 <head>
@@ -413,7 +516,7 @@ func (h *GUIHandler) serveLoginPage(w http.ResponseWriter, errorMsg string) {
             margin-bottom: 8px;
             font-size: 14px;
         }
-        input[type="password"] {
+        input[type="password"], input[type="text"]:not(.link-input) {
             width: 100%;
             padding: 14px 16px;
             border: 1px solid rgba(255, 255, 255, 0.2);
@@ -520,29 +623,16 @@ func (h *GUIHandler) serveLoginPage(w http.ResponseWriter, errorMsg string) {
         <h1>Gas Town</h1>
         <p class="subtitle">Multi-Agent Workspace Manager</p>
         ` + errorHTML + `
-        <form method="POST" action="/login">
-            <div class="form-group">
-                <label for="token">Access Token</label>
-                <input type="password" id="token" name="token" placeholder="Enter GT_WEB_AUTH_TOKEN" required autofocus>
-            </div>
+        <form method="POST" action="/login">` + fieldsHTML + `
             <button type="submit">Sign In</button>
-        </form>
-        <div class="divider"></div>
-        <div class="link-card">
-            <div class="link-title">Mobile Login Link</div>
-            <input type="text" id="login-link" class="link-input" readonly placeholder="Enter token to generate link">
-            <div class="link-actions">
-                <button type="button" class="secondary" id="copy-link" disabled>Copy Link</button>
-                <button type="button" id="share-link" disabled>Share Link</button>
-            </div>
-            <div class="link-note">This link contains your token. Share only with trusted devices.</div>
-        </div>
-        <p class="hint">Token is set via GT_WEB_AUTH_TOKEN environment variable</p>
+        </form>` + linkHTML + `
+        <p class="hint">` + hint + `</p>
     </div>
     <script>
         (function() {
             const tokenInput = document.getElementById('token');
             const linkInput = document.getElementById('login-link');
+            if (!tokenInput || !linkInput) return;
             const copyBtn = document.getElementById('copy-link');
             const shareBtn = document.getElementById('share-link');
 
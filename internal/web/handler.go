@@ -3,6 +3,9 @@ package web
 import (
 	"html/template"
 	"net/http"
+
+	"github.com/steveyegge/gastown/internal/changes"
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 // ConvoyFetcher defines the interface for fetching convoy data.
@@ -16,6 +19,7 @@ type ConvoyFetcher interface {
 type ConvoyHandler struct {
 	fetcher  ConvoyFetcher
 	template *template.Template
+	changes  *changeHub // nil outside a town: the page falls back to polling
 }
 
 // NewConvoyHandler creates a new convoy handler with the given fetcher.
@@ -25,14 +29,31 @@ func NewConvoyHandler(fetcher ConvoyFetcher) (*ConvoyHandler, error) {
 		return nil, err
 	}
 
-	return &ConvoyHandler{
+	h := &ConvoyHandler{
 		fetcher:  fetcher,
 		template: tmpl,
-	}, nil
+	}
+	if townRoot, err := workspace.FindFromCwd(); err == nil && townRoot != "" {
+		h.changes = newChangeHub(townRoot)
+		if inv, ok := fetcher.(cacheInvalidator); ok {
+			h.changes.onBatch = func([]changes.Change) { inv.InvalidateCache() }
+		}
+	}
+	return h, nil
 }
 
 // ServeHTTP handles GET / requests and renders the convoy dashboard.
+// GET /api/stream serves the change stream the page listens on to refresh.
 func (h *ConvoyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/stream" {
+		if h.changes == nil {
+			http.Error(w, "Change stream unavailable: not in a Gas Town workspace", http.StatusServiceUnavailable)
+			return
+		}
+		h.changes.serveSSE(w, r)
+		return
+	}
+
 	convoys, err := h.fetcher.FetchConvoys()
 	if err != nil {
 		http.Error(w, "Failed to fetch convoys", http.StatusInternalServerError)
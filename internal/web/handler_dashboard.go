@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/steveyegge/gastown/internal/changes"
 	"github.com/steveyegge/gastown/internal/daemon"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -49,7 +50,9 @@ var statusWSUpgrader = websocket.Upgrader{
 	},
 }
 
-// handleStatusWS provides WebSocket status updates.
+// handleStatusWS provides WebSocket status updates. Inside a town it pushes
+// a fresh snapshot shortly after each daemon change, with a slow fallback
+// refresh; otherwise it polls every 5 seconds.
 func (h *GUIHandler) handleStatusWS(w http.ResponseWriter, r *http.Request) {
 	conn, err := statusWSUpgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -76,10 +79,27 @@ func (h *GUIHandler) handleStatusWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ticker := time.NewTicker(5 * time.Second)
+	interval := 5 * time.Second
+	var sub *changeSub
+	if h.changes != nil {
+		sub = h.changes.subscribe()
+		defer func() { h.changes.unsubscribe(sub) }()
+		interval = statusFallbackInterval
+	}
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	// Debounce: a burst of changes yields one rebuild after it settles
+	var pending uint64
+	debounce := time.NewTimer(statusPushDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
 	for {
+		var changeCh <-chan changes.Change
+		if sub != nil {
+			changeCh = sub.ch
+		}
 		select {
 		case <-done:
 			return
@@ -87,6 +107,24 @@ func (h *GUIHandler) handleStatusWS(w http.ResponseWriter, r *http.Request) {
 			if err := sendStatus(); err != nil {
 				return
 			}
+		case c, ok := <-changeCh:
+			if !ok {
+				// Dropped by the hub (lagging or log reset): resubscribe and
+				// push a full snapshot to resync
+				sub = h.changes.subscribe()
+				if err := sendStatus(); err != nil {
+					return
+				}
+				continue
+			}
+			pending = c.Seq
+			debounce.Reset(statusPushDebounce)
+		case <-debounce.C:
+			status := h.statusCache.BuildAfter(pending, h.buildStatusUncached)
+			if err := conn.WriteJSON(status); err != nil {
+				return
+			}
+			ticker.Reset(interval)
 		}
 	}
 }
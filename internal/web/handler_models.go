@@ -6,7 +6,7 @@ import (
 	"net/http"
 
 	"github.com/steveyegge/gastown/internal/config"
-	"github.com/steveyegge/gastown/internal/models"
+	"github.com/steveyegge/gastown/internal/runtime"
 )
 
 // handleAPIModelsList returns all available models from CLI discovery.
@@ -36,7 +36,7 @@ func (h *GUIHandler) fetchModelsList() map[string][]string {
 	result := make(map[string][]string, len(agents))
 
 	for _, agent := range agents {
-		models := models.Discover(agent)
+		models := runtime.For(agent).Models()
 		if len(models) > 0 {
 			result[agent] = models
 		} else {
@@ -686,7 +686,8 @@ func TestE2E_Server_FullDashboard(t *testing.T) {
 		{"Agents section", "Active Agents"},
 		{"Agent name", "furiosa"},
 		{"Agent status", "Running E2E tests"},
-		{"HTMX auto-refresh", `hx-trigger="every 10s"`},
+		{"HTMX auto-refresh", `hx-trigger="every 10s [!gtStreamLive()], gt-change from:body"`},
+		{"Change stream", `new EventSource('/api/stream')`},
 	}
 
 	for _, check := range checks {
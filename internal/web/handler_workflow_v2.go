@@ -375,15 +375,7 @@ func (h *GUIHandler) handleAPICreateBeadV2(w http.ResponseWriter, r *http.Reques
 
 	w.Header().Set("Content-Type", "application/json")
 
-	var req struct {
-		Title       string   `json:"title"`
-		Description string   `json:"description,omitempty"`
-		Type        string   `json:"type,omitempty"` // task, bug, feature, etc.
-		Priority    int      `json:"priority,omitempty"`
-		Assignee    string   `json:"assignee,omitempty"`
-		Labels      []string `json:"labels,omitempty"`
-	}
-
+	var req beadCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
@@ -401,6 +393,34 @@ func (h *GUIHandler) handleAPICreateBeadV2(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	outMsg, beadID, err := createBead(req)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": err == nil,
+		"output":  outMsg,
+		"bead_id": beadID,
+		"error": func() string {
+			if err == nil {
+				return ""
+			}
+			return err.Error()
+		}(),
+	})
+}
+
+// beadCreateRequest is the body of a bead creation request.
+type beadCreateRequest struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Type        string   `json:"type,omitempty"` // task, bug, feature, etc.
+	Priority    int      `json:"priority,omitempty"`
+	Assignee    string   `json:"assignee,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+// createBead creates a bead with bd create and returns bd's message and the
+// new bead's ID.
+func createBead(req beadCreateRequest) (string, string, error) {
 	workDir := webWorkDir()
 	args := webBeadsArgs("create", "--json", "--title="+req.Title)
 
@@ -436,18 +456,7 @@ func (h *GUIHandler) handleAPICreateBeadV2(w http.ResponseWriter, r *http.Reques
 	output, err := cmd.CombinedOutput()
 
 	outMsg, beadID := parseCreateOutput(output)
-
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": err == nil,
-		"output":  outMsg,
-		"bead_id": beadID,
-		"error": func() string {
-			if err == nil {
-				return ""
-			}
-			return err.Error()
-		}(),
-	})
+	return outMsg, beadID, err
 }
 
 // handleAPIBeadDetailFast returns detailed bead info using direct DB access.
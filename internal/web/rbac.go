@@ -0,0 +1,263 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Role is what a web GUI user may do. Each role can do everything the
+// roles below it can.
+type Role string
+
+const (
+	// RoleViewer can see every page, status view and stream.
+	RoleViewer Role = "viewer"
+
+	// RoleOperator can also drive the town: send to terminals, run crew
+	// actions and commands, send mail, create beads and convoys.
+	RoleOperator Role = "operator"
+
+	// RoleAdmin can also change accounts, config and prompts.
+	RoleAdmin Role = "admin"
+)
+
+var roleRank = map[Role]int{RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// ParseRole parses a role name.
+func ParseRole(s string) (Role, error) {
+	r := Role(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := roleRank[r]; !ok {
+		return "", fmt.Errorf("unknown role %q (want viewer, operator or admin)", s)
+	}
+	return r, nil
+}
+
+// Allows reports whether the role includes the required one.
+func (r Role) Allows(required Role) bool {
+	return roleRank[r] >= roleRank[required]
+}
+
+// routePolicy is the role a route needs for reads (GET, HEAD) and for
+// state-changing methods. A path ending in / matches everything under it.
+type routePolicy struct {
+	path        string
+	read, write Role
+}
+
+// routePolicies override the default of viewer for reads and operator for
+// writes. The most specific (longest) matching path wins.
+var routePolicies = []routePolicy{
+	{path: "/api/accounts", read: RoleOperator, write: RoleAdmin},
+	{path: "/api/accounts/", read: RoleOperator, write: RoleAdmin},
+	{path: "/api/terminal/send", read: RoleOperator, write: RoleOperator},
+	{path: "/api/crew/action", read: RoleOperator, write: RoleOperator},
+	{path: "/api/command", read: RoleOperator, write: RoleOperator},
+	{path: "/api/config", read: RoleViewer, write: RoleAdmin},
+	{path: "/api/prompts/", read: RoleViewer, write: RoleAdmin},
+}
+
+// requiredRole returns the role a request needs.
+func requiredRole(r *http.Request) Role {
+	write := isStateChangingMethod(r.Method)
+	best := -1
+	role := RoleViewer
+	if write {
+		role = RoleOperator
+	}
+	for _, p := range routePolicies {
+		matches := r.URL.Path == p.path ||
+			(strings.HasSuffix(p.path, "/") && strings.HasPrefix(r.URL.Path, p.path))
+		if !matches || len(p.path) <= best {
+			continue
+		}
+		best = len(p.path)
+		role = p.read
+		if write {
+			role = p.write
+		}
+	}
+	return role
+}
+
+// Principal is who a request is from: a named user, the holder of the
+// shared GT_WEB_AUTH_TOKEN (an admin), or the local user when no auth is
+// configured (also an admin).
+type Principal struct {
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+
+	// header is set when the credentials came in a request header rather
+	// than a cookie; such requests don't need a CSRF token.
+	header bool
+}
+
+// Identities of requests that don't come from a named user.
+const (
+	tokenPrincipalName = "token"
+	localPrincipalName = "local"
+)
+
+type principalKey struct{}
+
+// PrincipalFrom returns the principal an authenticated request is from.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+func withPrincipal(r *http.Request, p Principal) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), principalKey{}, p))
+}
+
+// Audit event types written for the web GUI.
+const (
+	auditWebRequest = "web_request"
+	auditWebDenied  = "web_denied"
+	auditWebLogin   = "web_login"
+)
+
+// auditLog writes an audit event. A variable so tests can capture events.
+var auditLog = events.LogAudit
+
+// maxAuditBody caps how much of a request body is kept in the audit log.
+const maxAuditBody = 4096
+
+// sensitiveParams are request parameters left out of the audit log.
+var sensitiveParams = []string{"password", "token", "secret", "key", "credential"}
+
+// auditParams returns the parameters of a JSON or form request body for
+// the audit log, without sensitive values, and restores the body for the
+// handler. Large or unparseable bodies are summarised by size.
+func auditParams(r *http.Request) map[string]interface{} {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	head, err := io.ReadAll(io.LimitReader(r.Body, maxAuditBody+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+	if err != nil || len(head) == 0 {
+		return nil
+	}
+	if len(head) > maxAuditBody {
+		return map[string]interface{}{"body_bytes": fmt.Sprintf(">%d", maxAuditBody)}
+	}
+
+	params := make(map[string]interface{})
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.Contains(contentType, "application/x-www-form-urlencoded"):
+		values, err := url.ParseQuery(string(head))
+		if err != nil {
+			return map[string]interface{}{"body_bytes": len(head)}
+		}
+		for k, v := range values {
+			params[k] = strings.Join(v, ",")
+		}
+	default:
+		if err := json.Unmarshal(head, &params); err != nil {
+			return map[string]interface{}{"body_bytes": len(head)}
+		}
+	}
+	for k := range params {
+		lower := strings.ToLower(k)
+		for _, s := range sensitiveParams {
+			if strings.Contains(lower, s) {
+				params[k] = "[redacted]"
+			}
+		}
+	}
+	return params
+}
+
+// statusRecorder captures the status a handler writes, for the audit log.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// serveAudited serves a state-changing request and records who made it,
+// what they asked for and how it went.
+func (h *GUIHandler) serveAudited(w http.ResponseWriter, r *http.Request, p Principal) {
+	params := auditParams(r)
+	rec := &statusRecorder{ResponseWriter: w}
+	h.mux.ServeHTTP(rec, r)
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+
+	payload := map[string]interface{}{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"role":   string(p.Role),
+		"status": rec.status,
+		"remote": r.RemoteAddr,
+	}
+	if r.URL.RawQuery != "" {
+		payload["query"] = r.URL.RawQuery
+	}
+	if len(params) > 0 {
+		payload["params"] = params
+	}
+	_ = auditLog(auditWebRequest, "web/"+p.Name, payload)
+}
+
+// auditDenied records a request refused for lack of role.
+func auditDenied(r *http.Request, p Principal, required Role) {
+	_ = auditLog(auditWebDenied, "web/"+p.Name, map[string]interface{}{
+		"method":   r.Method,
+		"path":     r.URL.Path,
+		"role":     string(p.Role),
+		"required": string(required),
+		"remote":   r.RemoteAddr,
+	})
+}
+
+// auditLogin records a login attempt.
+func auditLogin(r *http.Request, name string, ok bool) {
+	_ = auditLog(auditWebLogin, "web/"+name, map[string]interface{}{
+		"success": ok,
+		"remote":  r.RemoteAddr,
+	})
+}
+
+// handleAPIAuthMe returns who the request is from and their role, so pages
+// can hide actions the user can't take.
+func (h *GUIHandler) handleAPIAuthMe(w http.ResponseWriter, r *http.Request) {
+	p, ok := PrincipalFrom(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(p)
+}
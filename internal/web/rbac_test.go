@@ -0,0 +1,181 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequiredRole(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         Role
+	}{
+		{http.MethodGet, "/dashboard", RoleViewer},
+		{http.MethodGet, "/api/status", RoleViewer},
+		{http.MethodPost, "/api/mail/send", RoleOperator},
+		{http.MethodPost, "/api/terminal/send", RoleOperator},
+		{http.MethodPost, "/api/crew/action", RoleOperator},
+		{http.MethodPost, "/api/command", RoleOperator},
+		{http.MethodGet, "/api/accounts", RoleOperator},
+		{http.MethodPost, "/api/accounts/switch", RoleAdmin},
+		{http.MethodGet, "/api/config", RoleViewer},
+		{http.MethodPost, "/api/config", RoleAdmin},
+		{http.MethodPost, "/api/prompts/mayor", RoleAdmin},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.path, nil)
+		if got := requiredRole(r); got != tt.want {
+			t.Errorf("%s %s needs %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseUsers(t *testing.T) {
+	records, err := parseUsers([]byte(`# comment
+alice:$2a$10$abcdefghijklmnopqrstuu5z0J3D4c0o3P9v4iQ1r3v8Gf2dXy4y.:admin
+bob:$2y$10$abcdefghijklmnopqrstuu5z0J3D4c0o3P9v4iQ1r3v8Gf2dXy4y.
+carol:plaintext:viewer
+dave:$2a$10$abcdefghijklmnopqrstuu5z0J3D4c0o3P9v4iQ1r3v8Gf2dXy4y.:root
+`))
+	if len(records) != 2 || records[0].Role != RoleAdmin || records[1].Role != RoleViewer {
+		t.Errorf("records = %+v, want alice (admin) and bob (viewer)", records)
+	}
+	if err == nil || !strings.Contains(err.Error(), "line 4") || !strings.Contains(err.Error(), "line 5") {
+		t.Errorf("err = %v, want the bad lines reported", err)
+	}
+}
+
+// auditCapture replaces the audit log for a test and returns the events
+// written to it.
+func auditCapture(t *testing.T) *[]map[string]interface{} {
+	t.Helper()
+	var logged []map[string]interface{}
+	orig := auditLog
+	auditLog = func(eventType, actor string, payload map[string]interface{}) error {
+		payload["type"] = eventType
+		payload["actor"] = actor
+		logged = append(logged, payload)
+		return nil
+	}
+	t.Cleanup(func() { auditLog = orig })
+	return &logged
+}
+
+func TestGUIHandlerUsersAndRoles(t *testing.T) {
+	origToken := authConfig.token
+	authConfig.token = ""
+	t.Cleanup(func() { authConfig.token = origToken })
+
+	usersFile := filepath.Join(t.TempDir(), "web-users")
+	t.Setenv("GT_WEB_USERS_FILE", usersFile)
+	if err := SetUser(usersFile, "vic", "viewer-pw", RoleViewer); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetUser(usersFile, "olga", "operator-pw", RoleOperator); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(usersFile); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("users file mode = %v, %v; want 0600", info.Mode(), err)
+	}
+
+	h, err := NewGUIHandler(&MockConvoyFetcher{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logged := auditCapture(t)
+
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		r.RemoteAddr = "127.0.0.1:5555"
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// No credentials: users exist, so auth is required
+	if w := serve(httptest.NewRequest(http.MethodGet, "/api/auth/me", nil)); w.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous = %d, want 401", w.Code)
+	}
+
+	// A viewer may read but not send to terminals
+	r := httptest.NewRequest(http.MethodGet, "/api/auth/me", nil)
+	r.SetBasicAuth("vic", "viewer-pw")
+	w := serve(r)
+	var me Principal
+	if err := json.Unmarshal(w.Body.Bytes(), &me); err != nil || me.Name != "vic" || me.Role != RoleViewer {
+		t.Errorf("me = %+v (%v), body %s", me, err, w.Body.String())
+	}
+
+	send := `{"session":"","text":"ls","password":"hunter2"}`
+	r = httptest.NewRequest(http.MethodPost, "/api/terminal/send", strings.NewReader(send))
+	r.SetBasicAuth("vic", "viewer-pw")
+	if w := serve(r); w.Code != http.StatusForbidden {
+		t.Errorf("viewer send = %d, want 403", w.Code)
+	}
+	if len(*logged) != 1 || (*logged)[0]["type"] != auditWebDenied || (*logged)[0]["actor"] != "web/vic" {
+		t.Fatalf("audit = %+v, want one denial for vic", *logged)
+	}
+
+	// An operator gets through to the handler, and the request is audited
+	// with its parameters, minus secrets
+	r = httptest.NewRequest(http.MethodPost, "/api/terminal/send", strings.NewReader(send))
+	r.Header.Set("Content-Type", "application/json")
+	r.SetBasicAuth("olga", "operator-pw")
+	if w := serve(r); w.Code != http.StatusBadRequest {
+		t.Errorf("operator send = %d, want the handler's 400", w.Code)
+	}
+	if len(*logged) != 2 {
+		t.Fatalf("audit = %+v", *logged)
+	}
+	entry := (*logged)[1]
+	params, _ := entry["params"].(map[string]interface{})
+	if entry["type"] != auditWebRequest || entry["actor"] != "web/olga" || entry["status"] != http.StatusBadRequest ||
+		params["text"] != "ls" || params["password"] != "[redacted]" {
+		t.Errorf("audit entry = %+v", entry)
+	}
+
+	// Wrong password
+	r = httptest.NewRequest(http.MethodGet, "/api/status", nil)
+	r.SetBasicAuth("olga", "wrong")
+	if w := serve(r); w.Code != http.StatusUnauthorized {
+		t.Errorf("bad password = %d, want 401", w.Code)
+	}
+
+	// Form login sets a user session cookie that authenticates later requests
+	form := url.Values{"username": {"olga"}, "password": {"operator-pw"}}
+	r = httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = serve(r)
+	var session *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == sessionCookieName {
+			session = c
+		}
+	}
+	if w.Code != http.StatusFound || session == nil || !strings.HasPrefix(session.Value, userSessionPrefix) {
+		t.Fatalf("login = %d, cookies %v", w.Code, w.Result().Cookies())
+	}
+	r = httptest.NewRequest(http.MethodGet, "/api/auth/me", nil)
+	r.AddCookie(session)
+	if w := serve(r); !strings.Contains(w.Body.String(), `"olga"`) {
+		t.Errorf("session me = %d %s", w.Code, w.Body.String())
+	}
+	if last := (*logged)[len(*logged)-1]; last["type"] != auditWebLogin || last["success"] != true {
+		t.Errorf("login not audited: %+v", last)
+	}
+
+	// Removing the user ends the session
+	if _, err := RemoveUser(usersFile, "olga"); err != nil {
+		t.Fatal(err)
+	}
+	r = httptest.NewRequest(http.MethodGet, "/api/auth/me", nil)
+	r.AddCookie(session)
+	if w := serve(r); w.Code != http.StatusUnauthorized {
+		t.Errorf("removed user's session = %d, want 401", w.Code)
+	}
+}
@@ -287,6 +287,42 @@ class ReconnectingEventSource {
     }
 }
 
+/**
+ * Subscribe to the daemon change stream (/api/stream).
+ * handlers maps change kinds ('bead.updated', 'agent.state', 'mr.state',
+ * 'mail.arrived') to callbacks, plus 'reset' for when changes were missed and
+ * the page should reload everything. Bursts are debounced per kind. The
+ * browser resumes from the last event id (the resume token) on reconnect.
+ * Returns the EventSource; use isChangeStreamLive() to decide whether
+ * fallback polling is still needed.
+ */
+function subscribeChanges(handlers, options = {}) {
+    if (!window.EventSource) return null;
+    const debounceMs = options.debounceMs ?? 500;
+    const source = new EventSource(options.url || '/api/stream');
+    const timers = {};
+
+    ['bead.updated', 'agent.state', 'mr.state', 'mail.arrived', 'reset'].forEach(kind => {
+        source.addEventListener(kind, (e) => {
+            const fn = handlers[kind];
+            if (!fn) return;
+            let change = null;
+            try {
+                change = JSON.parse(e.data);
+            } catch (err) {
+                // Keep the notification even if the payload is unreadable
+            }
+            clearTimeout(timers[kind]);
+            timers[kind] = setTimeout(() => fn(change), debounceMs);
+        });
+    });
+    return source;
+}
+
+function isChangeStreamLive(source) {
+    return !!source && source.readyState === EventSource.OPEN;
+}
+
 // ============================================================================
 // API Utilities
 // ============================================================================
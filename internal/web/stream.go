@@ -0,0 +1,396 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/steveyegge/gastown/internal/changes"
+)
+
+// Live update tuning. The change log is a local file, so polling its size
+// is cheap; it replaces polling bd and tmux for every connected client.
+const (
+	// changePollInterval is how often the hub checks the change log.
+	changePollInterval = 500 * time.Millisecond
+
+	// changeSubBuffer is how many changes a subscriber may fall behind
+	// before it is disconnected and left to resume from its token.
+	changeSubBuffer = 256
+
+	// streamKeepalive is the idle interval for SSE comments and WS pings.
+	streamKeepalive = 25 * time.Second
+
+	// statusPushDebounce coalesces bursts of changes into one status push.
+	statusPushDebounce = 750 * time.Millisecond
+
+	// statusFallbackInterval refreshes /ws/status even without changes, for
+	// data the daemon does not publish (CI, rig config, daemon liveness).
+	statusFallbackInterval = 30 * time.Second
+)
+
+// changeHub follows the daemon's change log (see internal/changes) and fans
+// new changes out to WebSocket and SSE subscribers. It polls only while
+// someone is subscribed.
+type changeHub struct {
+	townRoot string
+	interval time.Duration
+
+	// onBatch is called from the poll loop with each batch of new changes
+	// (used to invalidate response caches).
+	onBatch func([]changes.Change)
+
+	mu      sync.Mutex
+	subs    map[*changeSub]struct{}
+	last    uint64
+	size    int64
+	modTime time.Time
+	running bool
+}
+
+// changeSub is one subscriber. The hub closes ch when the subscriber falls
+// behind or the log is reset; the subscriber should then resync.
+type changeSub struct {
+	ch chan changes.Change
+}
+
+func newChangeHub(townRoot string) *changeHub {
+	return &changeHub{
+		townRoot: townRoot,
+		interval: changePollInterval,
+		subs:     make(map[*changeSub]struct{}),
+	}
+}
+
+// subscribe registers a subscriber and starts the poll loop if needed.
+func (h *changeHub) subscribe() *changeSub {
+	sub := &changeSub{ch: make(chan changes.Change, changeSubBuffer)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subs[sub] = struct{}{}
+	if !h.running {
+		h.running = true
+		h.last, _ = changes.Latest(h.townRoot)
+		h.size, h.modTime = h.stat()
+		go h.run()
+	}
+	return sub
+}
+
+// unsubscribe removes a subscriber. Safe to call after the hub dropped it.
+func (h *changeHub) unsubscribe(sub *changeSub) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.ch)
+	}
+}
+
+func (h *changeHub) run() {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		h.mu.Lock()
+		if len(h.subs) == 0 {
+			h.running = false
+			h.mu.Unlock()
+			return
+		}
+		h.mu.Unlock()
+		h.poll()
+	}
+}
+
+func (h *changeHub) stat() (int64, time.Time) {
+	info, err := os.Stat(changes.Path(h.townRoot))
+	if err != nil {
+		return 0, time.Time{}
+	}
+	return info.Size(), info.ModTime()
+}
+
+// poll reads changes published since the last poll and broadcasts them.
+func (h *changeHub) poll() {
+	size, modTime := h.stat()
+
+	h.mu.Lock()
+	if size == h.size && modTime.Equal(h.modTime) {
+		h.mu.Unlock()
+		return
+	}
+	h.size, h.modTime = size, modTime
+	after := h.last
+	h.mu.Unlock()
+
+	batch, reset, err := changes.Since(h.townRoot, after)
+	if err != nil || (len(batch) == 0 && !reset) {
+		return
+	}
+
+	h.mu.Lock()
+	if len(batch) > 0 {
+		h.last = batch[len(batch)-1].Seq
+	} else {
+		h.last = 0
+	}
+	for sub := range h.subs {
+		if reset {
+			// Subscribers can't tell what they missed; make them resync
+			delete(h.subs, sub)
+			close(sub.ch)
+			continue
+		}
+		for _, c := range batch {
+			select {
+			case sub.ch <- c:
+			default:
+				// Too far behind: drop it, the client resumes from its token
+				delete(h.subs, sub)
+				close(sub.ch)
+			}
+			if _, ok := h.subs[sub]; !ok {
+				break
+			}
+		}
+	}
+	h.mu.Unlock()
+
+	if h.onBatch != nil && len(batch) > 0 {
+		h.onBatch(batch)
+	}
+}
+
+// replay returns what a subscriber resuming from token must see before live
+// changes: the retained backlog, whether it must reset first, and the
+// sequence it is caught up to. An empty token means "live changes only".
+func (h *changeHub) replay(token string) (backlog []changes.Change, reset bool, caughtUp uint64, err error) {
+	if token == "" {
+		caughtUp, err = changes.Latest(h.townRoot)
+		return nil, false, caughtUp, err
+	}
+	after, err := changes.ParseToken(token)
+	if err != nil {
+		return nil, false, 0, err
+	}
+	backlog, reset, err = changes.Since(h.townRoot, after)
+	if err != nil {
+		return nil, false, 0, err
+	}
+	caughtUp = after
+	if reset {
+		caughtUp = 0
+	}
+	if len(backlog) > 0 {
+		caughtUp = backlog[len(backlog)-1].Seq
+	}
+	return backlog, reset, caughtUp, nil
+}
+
+// streamMessage is the WebSocket framing for the change stream. Type is
+// "change" or "reset"; Token is the resume token to reconnect with.
+type streamMessage struct {
+	Type   string          `json:"type"`
+	Token  string          `json:"token"`
+	Change *changes.Change `json:"change,omitempty"`
+}
+
+// serveSSE streams changes as server-sent events. The event id is the
+// resume token, so a reconnecting EventSource resumes via Last-Event-ID;
+// ?since=<token> does the same for clients that manage tokens themselves.
+// A "reset" event means changes were lost and the client should reload.
+func (h *changeHub) serveSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	token := r.Header.Get("Last-Event-ID")
+	if token == "" {
+		token = r.URL.Query().Get("since")
+	}
+
+	sub := h.subscribe()
+	defer h.unsubscribe(sub)
+
+	backlog, reset, caughtUp, err := h.replay(token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	send := func(c changes.Change) {
+		data, _ := json.Marshal(c)
+		fmt.Fprintf(w, "id: %s\n", c.Token())
+		writeSSE(w, string(c.Kind), string(data))
+	}
+
+	if reset {
+		fmt.Fprintf(w, "id: %s\n", changes.FormatToken(caughtUp))
+		writeSSE(w, "reset", `{"token":"`+changes.FormatToken(caughtUp)+`"}`)
+	}
+	for _, c := range backlog {
+		send(c)
+	}
+	// Tell the client where it stands even if nothing is pending
+	fmt.Fprintf(w, "id: %s\n", changes.FormatToken(caughtUp))
+	writeSSE(w, "ready", `{"token":"`+changes.FormatToken(caughtUp)+`"}`)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(streamKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		case c, ok := <-sub.ch:
+			if !ok {
+				return // Dropped by the hub; the browser reconnects and resumes
+			}
+			if c.Seq <= caughtUp {
+				continue // Already sent from the backlog
+			}
+			caughtUp = c.Seq
+			send(c)
+			flusher.Flush()
+		}
+	}
+}
+
+// serveWS streams changes over a WebSocket as streamMessage frames,
+// resuming from ?since=<token>.
+func (h *changeHub) serveWS(w http.ResponseWriter, r *http.Request) {
+	backlogToken := r.URL.Query().Get("since")
+	if _, err := changes.ParseToken(backlogToken); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	conn, err := statusWSUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sub := h.subscribe()
+	defer h.unsubscribe(sub)
+
+	backlog, reset, caughtUp, err := h.replay(backlogToken)
+	if err != nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(c changes.Change) error {
+		return conn.WriteJSON(streamMessage{Type: "change", Token: c.Token(), Change: &c})
+	}
+
+	if reset {
+		if err := conn.WriteJSON(streamMessage{Type: "reset", Token: changes.FormatToken(caughtUp)}); err != nil {
+			return
+		}
+	}
+	for _, c := range backlog {
+		if err := send(c); err != nil {
+			return
+		}
+	}
+
+	keepalive := time.NewTicker(streamKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-keepalive.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)); err != nil {
+				return
+			}
+		case c, ok := <-sub.ch:
+			if !ok {
+				return
+			}
+			if c.Seq <= caughtUp {
+				continue
+			}
+			caughtUp = c.Seq
+			if err := send(c); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// cacheInvalidator is implemented by fetchers that keep their own caches.
+type cacheInvalidator interface {
+	InvalidateCache()
+}
+
+// invalidateForChanges drops the cached API responses a batch of changes
+// made stale, so the next request after a push reads fresh data instead of
+// waiting out the TTL.
+func (h *GUIHandler) invalidateForChanges(batch []changes.Change) {
+	kinds := make(map[changes.Kind]bool)
+	for _, c := range batch {
+		kinds[c.Kind] = true
+	}
+
+	if kinds[changes.KindBeadUpdated] || kinds[changes.KindMRState] || kinds[changes.KindAgentState] {
+		h.cache.InvalidatePrefix("issues_")
+		h.cache.InvalidatePrefix("workflow_")
+		h.cache.Invalidate("agent_hooks")
+		if inv, ok := h.fetcher.(cacheInvalidator); ok {
+			inv.InvalidateCache()
+		}
+	}
+	if kinds[changes.KindAgentState] {
+		h.cache.Invalidate("role_beads")
+		h.cache.Invalidate("mail_agents")
+		h.cache.InvalidatePrefix("crew_list_")
+	}
+	if kinds[changes.KindMailArrived] {
+		h.cache.InvalidatePrefix("mail_")
+	}
+}
+
+// handleAPIStream serves the change stream over SSE.
+func (h *GUIHandler) handleAPIStream(w http.ResponseWriter, r *http.Request) {
+	if h.changes == nil {
+		http.Error(w, "Change stream unavailable: not in a Gas Town workspace", http.StatusServiceUnavailable)
+		return
+	}
+	h.changes.serveSSE(w, r)
+}
+
+// handleChangesWS serves the change stream over WebSocket.
+func (h *GUIHandler) handleChangesWS(w http.ResponseWriter, r *http.Request) {
+	if h.changes == nil {
+		http.Error(w, "Change stream unavailable: not in a Gas Town workspace", http.StatusServiceUnavailable)
+		return
+	}
+	h.changes.serveWS(w, r)
+}
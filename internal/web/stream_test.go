@@ -0,0 +1,228 @@
+package web
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/steveyegge/gastown/internal/changes"
+)
+
+type sseEvent struct {
+	id, event, data string
+}
+
+// readSSE reads one event from an SSE stream, skipping comments.
+func readSSE(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var ev sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading SSE: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			if ev.event != "" || ev.data != "" {
+				return ev
+			}
+		case strings.HasPrefix(line, "id: "):
+			ev.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			ev.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			ev.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func newTestHub(t *testing.T) (*changeHub, string) {
+	t.Helper()
+	town := t.TempDir()
+	hub := newChangeHub(town)
+	hub.interval = 10 * time.Millisecond
+	return hub, town
+}
+
+func publish(t *testing.T, town string, kind changes.Kind, id string) {
+	t.Helper()
+	if _, err := changes.Publish(town, changes.Change{Kind: kind, ID: id}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func openSSE(t *testing.T, url, lastEventID string) *bufio.Reader {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+func TestChangeHub_SSEResumeAndLive(t *testing.T) {
+	hub, town := newTestHub(t)
+	publish(t, town, changes.KindBeadUpdated, "gt-1")
+	publish(t, town, changes.KindMailArrived, "hq-msg")
+
+	server := httptest.NewServer(http.HandlerFunc(hub.serveSSE))
+	t.Cleanup(server.Close) // after the stream body is closed
+
+	r := openSSE(t, server.URL, "1")
+	if ev := readSSE(t, r); ev.event != "mail.arrived" || ev.id != "2" || !strings.Contains(ev.data, `"id":"hq-msg"`) {
+		t.Errorf("backlog event = %+v", ev)
+	}
+	if ev := readSSE(t, r); ev.event != "ready" || ev.id != "2" {
+		t.Errorf("ready event = %+v", ev)
+	}
+
+	publish(t, town, changes.KindAgentState, "gastown/witness")
+	if ev := readSSE(t, r); ev.event != "agent.state" || ev.id != "3" {
+		t.Errorf("live event = %+v", ev)
+	}
+}
+
+func TestChangeHub_SSEWithoutTokenIsLiveOnly(t *testing.T) {
+	hub, town := newTestHub(t)
+	publish(t, town, changes.KindBeadUpdated, "gt-old")
+
+	server := httptest.NewServer(http.HandlerFunc(hub.serveSSE))
+	t.Cleanup(server.Close) // after the stream body is closed
+
+	r := openSSE(t, server.URL, "")
+	if ev := readSSE(t, r); ev.event != "ready" || ev.id != "1" {
+		t.Fatalf("first event = %+v, want ready at 1", ev)
+	}
+	publish(t, town, changes.KindMRState, "gt-mr")
+	if ev := readSSE(t, r); ev.event != "mr.state" || ev.id != "2" {
+		t.Errorf("live event = %+v", ev)
+	}
+}
+
+func TestChangeHub_SSEResetOnLostToken(t *testing.T) {
+	hub, town := newTestHub(t)
+	publish(t, town, changes.KindBeadUpdated, "gt-1")
+
+	server := httptest.NewServer(http.HandlerFunc(hub.serveSSE))
+	t.Cleanup(server.Close) // after the stream body is closed
+
+	r := openSSE(t, server.URL+"?since=42", "")
+	if ev := readSSE(t, r); ev.event != "reset" {
+		t.Fatalf("first event = %+v, want reset", ev)
+	}
+	if ev := readSSE(t, r); ev.event != "bead.updated" || ev.id != "1" {
+		t.Errorf("replayed event = %+v", ev)
+	}
+}
+
+func TestChangeHub_WebSocket(t *testing.T) {
+	hub, town := newTestHub(t)
+	publish(t, town, changes.KindBeadUpdated, "gt-1")
+
+	server := httptest.NewServer(http.HandlerFunc(hub.serveWS))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "?since=0"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Dial error = %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var msg streamMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != "change" || msg.Token != "1" || msg.Change.ID != "gt-1" {
+		t.Errorf("backlog message = %+v", msg)
+	}
+
+	publish(t, town, changes.KindMailArrived, "hq-msg")
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Token != "2" || msg.Change.Kind != changes.KindMailArrived {
+		t.Errorf("live message = %+v", msg)
+	}
+}
+
+func TestChangeHub_DropsLaggingSubscriber(t *testing.T) {
+	hub, town := newTestHub(t)
+	sub := hub.subscribe()
+	defer hub.unsubscribe(sub)
+
+	for i := 0; i < changeSubBuffer+1; i++ {
+		publish(t, town, changes.KindBeadUpdated, "gt-1")
+	}
+	hub.poll()
+
+	n := 0
+	for range sub.ch {
+		n++
+	}
+	if n != changeSubBuffer {
+		t.Errorf("received %d changes before drop, want %d", n, changeSubBuffer)
+	}
+}
+
+func TestGUIHandler_InvalidateForChanges(t *testing.T) {
+	t.Setenv("GT_CACHE_DIR", t.TempDir())
+	h := &GUIHandler{cache: NewCache(), fetcher: &MockConvoyFetcher{}}
+	for _, key := range []string{"issues_open", "role_beads", "mail_inbox_200", "system"} {
+		h.cache.Set(key, "cached", time.Minute)
+	}
+
+	h.invalidateForChanges([]changes.Change{{Kind: changes.KindMailArrived}})
+	if h.cache.Get("mail_inbox_200") != nil {
+		t.Error("mail cache should be invalidated by mail.arrived")
+	}
+	if h.cache.Get("issues_open") == nil || h.cache.Get("role_beads") == nil {
+		t.Error("mail.arrived should not touch issue or agent caches")
+	}
+
+	h.invalidateForChanges([]changes.Change{{Kind: changes.KindAgentState}})
+	if h.cache.Get("issues_open") != nil || h.cache.Get("role_beads") != nil {
+		t.Error("agent.state should invalidate issue and agent caches")
+	}
+	if h.cache.Get("system") == nil {
+		t.Error("unrelated caches should survive")
+	}
+}
+
+func TestStatusCache_BuildAfterSharesBuilds(t *testing.T) {
+	t.Setenv("GT_CACHE_DIR", t.TempDir())
+	sc := NewStatusCache(StatusCacheTTL)
+	var builds int32
+	build := func() StatusResponse {
+		atomic.AddInt32(&builds, 1)
+		return StatusResponse{Mail: MailStatus{Unread: int(atomic.LoadInt32(&builds))}}
+	}
+
+	sc.BuildAfter(5, build)
+	sc.BuildAfter(5, build)
+	sc.BuildAfter(3, build)
+	if builds != 1 {
+		t.Errorf("builds = %d, want 1 for changes already covered", builds)
+	}
+	if got := sc.BuildAfter(6, build); builds != 2 || got.Mail.Unread != 2 {
+		t.Errorf("newer change should rebuild: builds = %d, status = %+v", builds, got)
+	}
+}
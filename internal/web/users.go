@@ -0,0 +1,338 @@
+package web
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UsersFile returns the web GUI users file for a town:
+// $GT_WEB_USERS_FILE if set, otherwise <town>/mayor/web-users.
+//
+// The file is htpasswd-style, one user per line as name:bcrypt-hash:role.
+// The role may be left off (htpasswd files work as is) and defaults to
+// viewer. Blank lines and lines starting with # are ignored.
+func UsersFile(townRoot string) string {
+	if path := os.Getenv("GT_WEB_USERS_FILE"); path != "" {
+		return path
+	}
+	if townRoot == "" {
+		return ""
+	}
+	return filepath.Join(townRoot, "mayor", "web-users")
+}
+
+// User is a named web GUI account.
+type User struct {
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+// userRecord is a line of the users file.
+type userRecord struct {
+	User
+	hash string
+}
+
+// parseUsers parses a users file. Malformed lines are reported with their
+// line numbers.
+func parseUsers(data []byte) ([]userRecord, error) {
+	var records []userRecord
+	var problems []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Split(line, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			problems = append(problems, fmt.Sprintf("line %d: want name:hash[:role]", n))
+			continue
+		}
+		if _, err := bcrypt.Cost([]byte(parts[1])); err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %s: password must be a bcrypt hash", n, parts[0]))
+			continue
+		}
+		role := RoleViewer
+		if len(parts) == 3 && parts[2] != "" {
+			r, err := ParseRole(parts[2])
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("line %d: %v", n, err))
+				continue
+			}
+			role = r
+		}
+		records = append(records, userRecord{User: User{Name: parts[0], Role: role}, hash: parts[1]})
+	}
+	if len(problems) > 0 {
+		return records, fmt.Errorf("users file: %s", strings.Join(problems, "; "))
+	}
+	return records, nil
+}
+
+func readUsers(path string) ([]userRecord, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is the configured users file
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseUsers(data)
+}
+
+func writeUsers(path string, records []userRecord) error {
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	var buf bytes.Buffer
+	buf.WriteString("# Gas Town web GUI users (name:bcrypt-hash:role). Manage with gt gui user.\n")
+	for _, rec := range records {
+		fmt.Fprintf(&buf, "%s:%s:%s\n", rec.Name, rec.hash, rec.Role)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ListUsers returns the users in a users file, sorted by name.
+func ListUsers(path string) ([]User, error) {
+	records, err := readUsers(path)
+	users := make([]User, 0, len(records))
+	for _, rec := range records {
+		users = append(users, rec.User)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	return users, err
+}
+
+// SetUser adds a user to a users file, or replaces its password and role.
+func SetUser(path, name, password string, role Role) error {
+	if name == "" || strings.ContainsAny(name, ": \t") {
+		return fmt.Errorf("invalid user name %q", name)
+	}
+	if _, err := ParseRole(string(role)); err != nil {
+		return err
+	}
+	if password == "" {
+		return fmt.Errorf("password is empty")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("hashing password: %w", err)
+	}
+
+	records, err := readUsers(path)
+	if err != nil {
+		return err
+	}
+	rec := userRecord{User: User{Name: name, Role: role}, hash: string(hash)}
+	replaced := false
+	for i := range records {
+		if records[i].Name == name {
+			records[i] = rec
+			replaced = true
+		}
+	}
+	if !replaced {
+		records = append(records, rec)
+	}
+	return writeUsers(path, records)
+}
+
+// RemoveUser removes a user from a users file. It reports whether the user
+// was there.
+func RemoveUser(path, name string) (bool, error) {
+	records, err := readUsers(path)
+	if err != nil {
+		return false, err
+	}
+	kept := records[:0]
+	for _, rec := range records {
+		if rec.Name != name {
+			kept = append(kept, rec)
+		}
+	}
+	if len(kept) == len(records) {
+		return false, nil
+	}
+	return true, writeUsers(path, kept)
+}
+
+// userStore serves lookups from the users file, reloading it when it
+// changes so users added with gt gui user take effect without a restart.
+type userStore struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	users   map[string]userRecord
+	secret  []byte
+}
+
+func newUserStore(path string) *userStore {
+	return &userStore{path: path}
+}
+
+// refresh reloads the file if it changed. Must be called with mu held.
+func (s *userStore) refresh() {
+	if s.path == "" {
+		return
+	}
+	info, err := os.Stat(s.path)
+	if err != nil {
+		s.users = nil
+		s.modTime = time.Time{}
+		return
+	}
+	if s.users != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return
+	}
+	records, err := readUsers(s.path)
+	if err != nil {
+		log.Printf("Warning: %s: %v", s.path, err)
+	}
+	s.users = make(map[string]userRecord, len(records))
+	for _, rec := range records {
+		s.users[rec.Name] = rec
+	}
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+}
+
+// configured reports whether any users are defined.
+func (s *userStore) configured() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh()
+	return len(s.users) > 0
+}
+
+func (s *userStore) lookup(name string) (userRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh()
+	rec, ok := s.users[name]
+	return rec, ok
+}
+
+// dummyHash is compared against for unknown users so that a failed login
+// takes as long whether or not the name exists. It is made on first use.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("gt-dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// verify checks a name and password.
+func (s *userStore) verify(name, password string) (User, bool) {
+	if s == nil {
+		return User{}, false
+	}
+	rec, ok := s.lookup(name)
+	if !ok {
+		_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return User{}, false
+	}
+	if bcrypt.CompareHashAndPassword([]byte(rec.hash), []byte(password)) != nil {
+		return User{}, false
+	}
+	return rec.User, true
+}
+
+// userSessionTTL is how long a user's login lasts.
+const userSessionTTL = 30 * 24 * time.Hour
+
+// userSessionPrefix distinguishes user session cookies from the shared
+// token's session cookie.
+const userSessionPrefix = "u."
+
+// sessionKey returns the key user session cookies are signed with. It is
+// kept next to the users file so sessions survive GUI restarts.
+func (s *userStore) sessionKey() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.secret != nil {
+		return s.secret
+	}
+	keyPath := s.path + ".key"
+	if data, err := os.ReadFile(keyPath); err == nil && len(data) >= 32 { //nolint:gosec // G304: next to the configured users file
+		s.secret = data
+		return s.secret
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Sprintf("generating session key: %v", err))
+	}
+	if err := os.WriteFile(keyPath, secret, 0600); err != nil {
+		log.Printf("Warning: saving session key: %v (sessions will end on restart)", err)
+	}
+	s.secret = secret
+	return s.secret
+}
+
+// newSession returns a signed session cookie value for a user. The
+// signature covers the user's password hash, so changing the password or
+// removing the user ends their sessions.
+func (s *userStore) newSession(name string, now time.Time) string {
+	rec, _ := s.lookup(name)
+	expires := strconv.FormatInt(now.Add(userSessionTTL).Unix(), 10)
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(name))
+	return userSessionPrefix + encoded + "." + expires + "." + s.sign(encoded, expires, rec.hash)
+}
+
+// session returns the user a session cookie value belongs to.
+func (s *userStore) session(value string, now time.Time) (User, bool) {
+	if s == nil || !strings.HasPrefix(value, userSessionPrefix) {
+		return User{}, false
+	}
+	parts := strings.Split(strings.TrimPrefix(value, userSessionPrefix), ".")
+	if len(parts) != 3 {
+		return User{}, false
+	}
+	nameBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return User{}, false
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() > expires {
+		return User{}, false
+	}
+	rec, ok := s.lookup(string(nameBytes))
+	if !ok {
+		return User{}, false
+	}
+	want := s.sign(parts[0], parts[1], rec.hash)
+	if !hmac.Equal([]byte(want), []byte(parts[2])) {
+		return User{}, false
+	}
+	return rec.User, true
+}
+
+func (s *userStore) sign(name, expires, hash string) string {
+	mac := hmac.New(sha256.New, s.sessionKey())
+	mac.Write([]byte(name + "|" + expires + "|" + hash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
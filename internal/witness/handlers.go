@@ -92,7 +92,7 @@ func HandlePolecatDone(workDir, rigName string, msg *mail.Message) *HandlerResul
 	}
 
 	// No pending MR - try to auto-nuke immediately
-	nukeResult := AutoNukeIfClean(workDir, rigName, payload.PolecatName)
+	nukeResult := AutoNukeIfClean(workDir, rigName, payload.PolecatName, "")
 	if nukeResult.Nuked {
 		result.Handled = true
 		result.Action = fmt.Sprintf("auto-nuked %s (exit=%s, no MR): %s", payload.PolecatName, payload.Exit, nukeResult.Reason)
@@ -135,7 +135,7 @@ func HandleLifecycleShutdown(workDir, rigName string, msg *mail.Message) *Handle
 	polecatName := matches[1]
 
 	// Shutdown means no pending work - try to auto-nuke immediately
-	nukeResult := AutoNukeIfClean(workDir, rigName, polecatName)
+	nukeResult := AutoNukeIfClean(workDir, rigName, polecatName, "")
 	if nukeResult.Nuked {
 		result.Handled = true
 		result.Action = fmt.Sprintf("auto-nuked %s (shutdown): %s", polecatName, nukeResult.Reason)
@@ -232,7 +232,7 @@ func HandleMerged(workDir, rigName string, msg *mail.Message) *HandlerResult {
 
 	// Verify the polecat's commit is actually on main before allowing nuke.
 	// This prevents work loss when MERGED signal is for a stale MR or the merge failed.
-	onMain, err := verifyCommitOnMain(workDir, rigName, payload.PolecatName)
+	onMain, err := verifyCommitOnMain(workDir, rigName, payload.PolecatName, payload.MergeCommit)
 	if err != nil {
 		// Couldn't verify - log warning but continue with other checks
 		// The polecat may not exist anymore (already nuked) which is fine
@@ -305,56 +305,6 @@ func HandleMerged(workDir, rigName string, msg *mail.Message) *HandlerResult {
 	return result
 }
 
-// HandleMergeFailed processes a MERGE_FAILED message from the Refinery.
-// Notifies the polecat that their merge was rejected and rework is needed.
-func HandleMergeFailed(workDir, rigName string, msg *mail.Message, router *mail.Router) *HandlerResult {
-	result := &HandlerResult{
-		MessageID:    msg.ID,
-		ProtocolType: ProtoMergeFailed,
-	}
-
-	// Parse the message
-	payload, err := ParseMergeFailed(msg.Subject, msg.Body)
-	if err != nil {
-		result.Error = fmt.Errorf("parsing MERGE_FAILED: %w", err)
-		return result
-	}
-
-	// Notify the polecat about the failure
-	polecatAddr := fmt.Sprintf("%s/polecats/%s", rigName, payload.PolecatName)
-	notification := &mail.Message{
-		From:     fmt.Sprintf("%s/witness", rigName),
-		To:       polecatAddr,
-		Subject:  fmt.Sprintf("Merge failed: %s", payload.FailureType),
-		Priority: mail.PriorityHigh,
-		Type:     mail.TypeTask,
-		Body: fmt.Sprintf(`Your merge request was rejected.
-
-Branch: %s
-Issue: %s
-Failure: %s
-Error: %s
-
-Please fix the issue and resubmit with 'gt done'.`,
-			payload.Branch,
-			payload.IssueID,
-			payload.FailureType,
-			payload.Error,
-		),
-	}
-
-	if err := router.Send(notification); err != nil {
-		result.Error = fmt.Errorf("sending failure notification: %w", err)
-		return result
-	}
-
-	result.Handled = true
-	result.MailSent = notification.ID
-	result.Action = fmt.Sprintf("notified %s of merge failure: %s - %s", payload.PolecatName, payload.FailureType, payload.Error)
-
-	return result
-}
-
 // HandleSwarmStart processes a SWARM_START message from the Mayor.
 // Creates a swarm tracking wisp to monitor batch polecat work.
 func HandleSwarmStart(workDir string, msg *mail.Message) *HandlerResult {
@@ -690,8 +640,10 @@ type NukePolecatResult struct {
 // This is used for orphaned polecats (no hooked work, no pending MR).
 // With the self-cleaning model, polecats should self-nuke on completion.
 // An orphan is likely from a crash before gt done completed.
+// mergeCommit is the commit that landed the polecat's work when a MERGED
+// message names one, so squash-merged work is recognized; otherwise "".
 // Returns whether the nuke was performed and any error.
-func AutoNukeIfClean(workDir, rigName, polecatName string) *NukePolecatResult {
+func AutoNukeIfClean(workDir, rigName, polecatName, mergeCommit string) *NukePolecatResult {
 	result := &NukePolecatResult{}
 
 	// Check cleanup_status from agent bead
@@ -715,7 +667,7 @@ func AutoNukeIfClean(workDir, rigName, polecatName string) *NukePolecatResult {
 
 	default:
 		// Unknown status - check git state directly as fallback
-		onMain, err := verifyCommitOnMain(workDir, rigName, polecatName)
+		onMain, err := verifyCommitOnMain(workDir, rigName, polecatName, mergeCommit)
 		if err != nil {
 			// Can't verify - skip (polecat may not exist)
 			result.Skipped = true
@@ -746,11 +698,16 @@ func AutoNukeIfClean(workDir, rigName, polecatName string) *NukePolecatResult {
 // (e.g., "gastown" for gastown.git). This function checks ALL remotes to find
 // the one containing the default branch with the merged commit.
 //
+// Squash merges land the polecat's work as a new commit, so its HEAD never
+// reaches the default branch. When the refinery names the commit that landed
+// the branch (mergeCommit), the work also counts as merged if that commit is
+// on the default branch and has the polecat's version of every file it changed.
+//
 // Returns:
 //   - true, nil: commit is verified on default branch
 //   - false, nil: commit is NOT on default branch (don't nuke!)
 //   - false, error: couldn't verify (treat as unsafe)
-func verifyCommitOnMain(workDir, rigName, polecatName string) (bool, error) {
+func verifyCommitOnMain(workDir, rigName, polecatName, mergeCommit string) (bool, error) {
 	// Find town root from workDir
 	townRoot, err := workspace.Find(workDir)
 	if err != nil || townRoot == "" {
@@ -781,12 +738,39 @@ func verifyCommitOnMain(workDir, rigName, polecatName string) (bool, error) {
 		return false, fmt.Errorf("getting polecat HEAD: %w", err)
 	}
 
+	onMain, err := onDefaultBranch(g, commitSHA, defaultBranch)
+	if err != nil || onMain || mergeCommit == "" {
+		return onMain, err
+	}
+
+	// Squash or rebase merge: check the commit that landed the branch instead
+	landed, err := onDefaultBranch(g, mergeCommit, defaultBranch)
+	if err != nil || !landed {
+		return false, err
+	}
+	changed, err := g.ChangedFiles(mergeCommit, commitSHA)
+	if err != nil {
+		return false, fmt.Errorf("listing polecat changes: %w", err)
+	}
+	if len(changed) == 0 {
+		return true, nil
+	}
+	same, err := g.SameContent(commitSHA, mergeCommit, changed)
+	if err != nil {
+		return false, fmt.Errorf("comparing polecat changes with %s: %w", mergeCommit, err)
+	}
+	return same, nil
+}
+
+// onDefaultBranch reports whether commit is on defaultBranch of any remote,
+// or on the local defaultBranch.
+func onDefaultBranch(g *git.Git, commit, defaultBranch string) (bool, error) {
 	// Get all configured remotes and check each one for the commit
 	// This handles multi-remote setups where code may be on a remote other than "origin"
 	remotes, err := g.Remotes()
 	if err != nil {
 		// If we can't list remotes, fall back to checking just the local branch
-		isOnDefaultBranch, err := g.IsAncestor(commitSHA, defaultBranch)
+		isOnDefaultBranch, err := g.IsAncestor(commit, defaultBranch)
 		if err != nil {
 			return false, fmt.Errorf("checking if commit is on %s: %w", defaultBranch, err)
 		}
@@ -796,14 +780,14 @@ func verifyCommitOnMain(workDir, rigName, polecatName string) (bool, error) {
 	// Try each remote/<defaultBranch> until we find one where commit is an ancestor
 	for _, remote := range remotes {
 		remoteBranch := remote + "/" + defaultBranch
-		isOnRemote, err := g.IsAncestor(commitSHA, remoteBranch)
+		isOnRemote, err := g.IsAncestor(commit, remoteBranch)
 		if err == nil && isOnRemote {
 			return true, nil
 		}
 	}
 
 	// Also try the local default branch (in case we're not tracking a remote)
-	isOnDefaultBranch, err := g.IsAncestor(commitSHA, defaultBranch)
+	isOnDefaultBranch, err := g.IsAncestor(commit, defaultBranch)
 	if err == nil && isOnDefaultBranch {
 		return true, nil
 	}
@@ -0,0 +1,141 @@
+package witness
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// runGit runs a git command in dir and fails the test on error.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// writeScript writes an executable shell script named name into dir.
+func writeScript(t *testing.T, dir, name, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newSquashMergedTown creates a town whose rig gastown has a polecat nux
+// with one commit, squash-merged onto main by a refinery clone. It returns
+// the rig path, the squash commit, and the file gt invocations are logged to.
+// bd reports a cleanup wisp and no cleanup_status for the polecat.
+func newSquashMergedTown(t *testing.T) (string, string, string) {
+	t.Helper()
+	town := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(town, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(town, "mayor", "town.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	origin := filepath.Join(town, "origin.git")
+	runGit(t, town, "init", "--bare", "-b", "main", origin)
+	rigPath := filepath.Join(town, "gastown")
+	clone := func(dir string) {
+		runGit(t, town, "clone", "-q", origin, dir)
+		runGit(t, dir, "config", "user.email", "test@test.com")
+		runGit(t, dir, "config", "user.name", "Test User")
+	}
+	write := func(dir, name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	refinery := filepath.Join(rigPath, "refinery", "rig")
+	clone(refinery)
+	runGit(t, refinery, "checkout", "-q", "-b", "main")
+	write(refinery, "README.md", "# Test\n")
+	runGit(t, refinery, "add", ".")
+	runGit(t, refinery, "commit", "-q", "-m", "initial")
+	runGit(t, refinery, "push", "-q", "origin", "main")
+
+	polecat := filepath.Join(rigPath, "polecats", "nux", "gastown")
+	clone(polecat)
+	runGit(t, polecat, "checkout", "-q", "-b", "polecat/nux")
+	write(polecat, "nux.txt", "nux\n")
+	runGit(t, polecat, "add", ".")
+	runGit(t, polecat, "commit", "-q", "-m", "work on nux")
+	runGit(t, polecat, "push", "-q", "origin", "polecat/nux")
+
+	// Main moves on, then the refinery squashes the branch onto it
+	write(refinery, "main.txt", "main\n")
+	runGit(t, refinery, "add", ".")
+	runGit(t, refinery, "commit", "-q", "-m", "advance main")
+	runGit(t, refinery, "fetch", "-q", "origin")
+	runGit(t, refinery, "merge", "--squash", "origin/polecat/nux")
+	runGit(t, refinery, "commit", "-q", "-m", "Merge polecat/nux into main (gt-1)")
+	runGit(t, refinery, "push", "-q", "origin", "main")
+	squash := runGit(t, refinery, "rev-parse", "HEAD")
+	runGit(t, polecat, "fetch", "-q", "origin")
+
+	bin := t.TempDir()
+	gtLog := filepath.Join(bin, "gt.log")
+	writeScript(t, bin, "bd", `case "$1" in
+  list) echo '[{"id":"gt-wisp-1"}]' ;;
+  show) echo '{"description":""}' ;;
+esac
+`)
+	writeScript(t, bin, "gt", `echo "$@" >> `+gtLog+"\n")
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return rigPath, squash, gtLog
+}
+
+func TestHandleMerged_SquashMerge(t *testing.T) {
+	rigPath, squash, gtLog := newSquashMergedTown(t)
+
+	// Without the merge commit the squashed work can't be found on main
+	msg := &mail.Message{ID: "m1", Subject: "MERGED nux", Body: "Branch: polecat/nux\nIssue: gt-1\n"}
+	result := HandleMerged(rigPath, "gastown", msg)
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "NOT on main") {
+		t.Fatalf("HandleMerged() without merge commit = %+v, want blocked", result)
+	}
+	if _, err := os.Stat(gtLog); err == nil {
+		t.Fatal("polecat was nuked without verification")
+	}
+
+	msg.Body += "Merge-Commit: " + squash + "\n"
+	result = HandleMerged(rigPath, "gastown", msg)
+	if result.Error != nil {
+		t.Fatalf("HandleMerged() with squash commit: %v (%s)", result.Error, result.Action)
+	}
+	if data, _ := os.ReadFile(gtLog); strings.TrimSpace(string(data)) != "polecat nuke gastown/nux" {
+		t.Errorf("gt calls = %q, want the polecat nuked", data)
+	}
+}
+
+func TestVerifyCommitOnMain_SquashMissingWork(t *testing.T) {
+	rigPath, squash, _ := newSquashMergedTown(t)
+	polecat := filepath.Join(rigPath, "polecats", "nux", "gastown")
+
+	// Work committed after the MR was squashed must not be nuked
+	if err := os.WriteFile(filepath.Join(polecat, "nux.txt"), []byte("more\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, polecat, "commit", "-q", "-am", "more work")
+
+	onMain, err := verifyCommitOnMain(rigPath, "gastown", "nux", squash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if onMain {
+		t.Error("verifyCommitOnMain() = true for work missing from the squash commit")
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -220,8 +221,9 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 		return fmt.Errorf("waiting for witness to start: %w", err)
 	}
 
-	// Accept bypass permissions warning dialog if it appears.
-	_ = t.AcceptBypassPermissionsWarning(sessionID)
+	// Accept startup dialogs (e.g. the bypass permissions warning) if they appear.
+	adapter := runtime.ForRole("witness", townRoot, m.rig.Path, agentOverride)
+	_ = runtime.DismissDialogs(t, adapter, sessionID)
 
 	time.Sleep(constants.ShutdownNotifyDelay)
 
@@ -235,11 +237,11 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 
 	// GUPP: Gas Town Universal Propulsion Principle
 	// Send the propulsion nudge to trigger autonomous patrol execution.
-	// Wait for Claude UI to be ready before sending propulsion nudge.
-	if err := t.WaitForClaudeUI(sessionID, 30*time.Second); err != nil {
-		return fmt.Errorf("waiting for Claude UI: %w", err)
+	// Wait for the agent to be ready before sending propulsion nudge.
+	if err := runtime.WaitReady(t, adapter, sessionID, 30*time.Second); err != nil {
+		return fmt.Errorf("waiting for agent UI: %w", err)
 	}
-	_ = t.NudgeSession(sessionID, session.PropulsionNudgeForRole("witness", witnessDir)) // Non-fatal
+	_ = adapter.InjectPrompt(t, sessionID, session.PropulsionNudgeForRole("witness", witnessDir)) // Non-fatal
 
 	// Ensure patrol molecule is attached
 	agentAddr := fmt.Sprintf("%s/witness", m.rig.Name)
@@ -1,4 +1,9 @@
 // Package witness provides the polecat monitoring agent.
+//
+// The witness's protocol parsers read a message's typed payload from its
+// protocol envelope (see protocol/wire) when it has one, and otherwise parse
+// the "Key: value" fields of the body, as sent by agents that predate
+// envelopes.
 package witness
 
 import (
@@ -6,6 +11,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/protocol/wire"
 )
 
 // Protocol message patterns for Witness inbox routing.
@@ -72,16 +79,7 @@ type MergedPayload struct {
 	Branch      string
 	IssueID     string
 	MergedAt    time.Time
-}
-
-// MergeFailedPayload contains parsed data from a MERGE_FAILED message.
-type MergeFailedPayload struct {
-	PolecatName string
-	Branch      string
-	IssueID     string
-	FailureType string // "build", "test", "lint", etc.
-	Error       string
-	FailedAt    time.Time
+	MergeCommit string // Commit that landed the branch, if the refinery says
 }
 
 // SwarmStartPayload contains parsed data from a SWARM_START message.
@@ -133,6 +131,23 @@ func ParsePolecatDone(subject, body string) (*PolecatDonePayload, error) {
 		PolecatName: matches[1],
 	}
 
+	env, err := wire.Expect(body, "POLECAT_DONE")
+	if err != nil {
+		return nil, err
+	}
+	if env != nil {
+		var p wire.PolecatDonePayload
+		if err := env.Decode(&p); err != nil {
+			return nil, err
+		}
+		payload.Exit = p.Exit
+		payload.IssueID = p.Issue
+		payload.MRID = p.MR
+		payload.Gate = p.Gate
+		payload.Branch = p.Branch
+		return payload, nil
+	}
+
 	// Parse body for structured fields
 	for _, line := range strings.Split(body, "\n") {
 		line = strings.TrimSpace(line)
@@ -195,6 +210,7 @@ func ParseHelp(subject, body string) (*HelpPayload, error) {
 //	Branch: <branch>
 //	Issue: <issue-id>
 //	Merged-At: <timestamp>
+//	Merge-Commit: <sha>
 func ParseMerged(subject, body string) (*MergedPayload, error) {
 	matches := PatternMerged.FindStringSubmatch(subject)
 	if len(matches) < 2 {
@@ -205,6 +221,22 @@ func ParseMerged(subject, body string) (*MergedPayload, error) {
 		PolecatName: matches[1],
 	}
 
+	env, err := wire.Expect(body, "MERGED")
+	if err != nil {
+		return nil, err
+	}
+	if env != nil {
+		var p wire.MergedPayload
+		if err := env.Decode(&p); err != nil {
+			return nil, err
+		}
+		payload.Branch = p.Branch
+		payload.IssueID = p.Issue
+		payload.MergedAt = p.MergedAt
+		payload.MergeCommit = p.MergeCommit
+		return payload, nil
+	}
+
 	// Parse body for structured fields
 	for _, line := range strings.Split(body, "\n") {
 		line = strings.TrimSpace(line)
@@ -217,43 +249,8 @@ func ParseMerged(subject, body string) (*MergedPayload, error) {
 			if t, err := time.Parse(time.RFC3339, ts); err == nil {
 				payload.MergedAt = t
 			}
-		}
-	}
-
-	return payload, nil
-}
-
-// ParseMergeFailed extracts payload from a MERGE_FAILED message.
-// Subject format: MERGE_FAILED <polecat-name>
-// Body format:
-//
-//	Branch: <branch>
-//	Issue: <issue-id>
-//	FailureType: <type>
-//	Error: <error-message>
-func ParseMergeFailed(subject, body string) (*MergeFailedPayload, error) {
-	matches := PatternMergeFailed.FindStringSubmatch(subject)
-	if len(matches) < 2 {
-		return nil, fmt.Errorf("invalid MERGE_FAILED subject: %s", subject)
-	}
-
-	payload := &MergeFailedPayload{
-		PolecatName: matches[1],
-		FailedAt:    time.Now(),
-	}
-
-	// Parse body for structured fields
-	for _, line := range strings.Split(body, "\n") {
-		line = strings.TrimSpace(line)
-		switch {
-		case strings.HasPrefix(line, "Branch:"):
-			payload.Branch = strings.TrimSpace(strings.TrimPrefix(line, "Branch:"))
-		case strings.HasPrefix(line, "Issue:"):
-			payload.IssueID = strings.TrimSpace(strings.TrimPrefix(line, "Issue:"))
-		case strings.HasPrefix(line, "FailureType:"):
-			payload.FailureType = strings.TrimSpace(strings.TrimPrefix(line, "FailureType:"))
-		case strings.HasPrefix(line, "Error:"):
-			payload.Error = strings.TrimSpace(strings.TrimPrefix(line, "Error:"))
+		} else if strings.HasPrefix(line, "Merge-Commit:") {
+			payload.MergeCommit = strings.TrimSpace(strings.TrimPrefix(line, "Merge-Commit:"))
 		}
 	}
 